@@ -0,0 +1,61 @@
+package portal_handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
+)
+
+// AuthAuditRecorder stores authentication audit events
+type AuthAuditRecorder interface {
+	Record(ctx context.Context, event *portal_repositories.AuthAuditEvent) error
+}
+
+// authAudit is a package-level variable to store the audit recorder. When it
+// is nil, audit events only go to the service log.
+var authAudit AuthAuditRecorder
+
+// SetAuthAuditRecorder sets where authentication audit events are stored
+func SetAuthAuditRecorder(recorder AuthAuditRecorder) {
+	authAudit = recorder
+}
+
+// auditAuthEvent fills in the client's IP and user agent and records event.
+// Every event is also logged, which ships it to the logs service. Auditing
+// never blocks authentication, so a failed insert is only logged.
+func auditAuthEvent(c *gin.Context, event *portal_repositories.AuthAuditEvent) {
+	event.IPAddress = c.ClientIP()
+	event.UserAgent = c.Request.UserAgent()
+
+	log.Printf("[AUDIT] event=%s outcome=%s user_id=%d github_id=%d ip=%s reason=%q",
+		event.EventType, event.Outcome, event.UserID, event.GitHubID, event.IPAddress, event.Reason)
+
+	if authAudit == nil {
+		return
+	}
+	// The insert should land even if the client has gone away
+	if err := authAudit.Record(context.WithoutCancel(c.Request.Context()), event); err != nil {
+		log.Printf("[WARN] Failed to record auth audit event: %v", err)
+	}
+}
+
+// auditAuthFailure records a failed event with the reason
+func auditAuthFailure(c *gin.Context, eventType, reason string) {
+	auditAuthEvent(c, &portal_repositories.AuthAuditEvent{
+		EventType: eventType,
+		Outcome:   portal_repositories.AuthOutcomeFailure,
+		Reason:    reason,
+	})
+}
+
+// auditLoginFailure records a login that failed after GitHub identified the user
+func auditLoginFailure(c *gin.Context, githubID int64, reason string) {
+	auditAuthEvent(c, &portal_repositories.AuthAuditEvent{
+		EventType: portal_repositories.AuthEventLogin,
+		Outcome:   portal_repositories.AuthOutcomeFailure,
+		GitHubID:  githubID,
+		Reason:    reason,
+	})
+}
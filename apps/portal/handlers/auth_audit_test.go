@@ -0,0 +1,185 @@
+package portal_handlers
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryAuditRecorder struct {
+	mu     sync.Mutex
+	events []portal_repositories.AuthAuditEvent
+}
+
+func (m *memoryAuditRecorder) Record(_ context.Context, event *portal_repositories.AuthAuditEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, *event)
+	return nil
+}
+
+func (m *memoryAuditRecorder) byType(eventType string) []portal_repositories.AuthAuditEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var matched []portal_repositories.AuthAuditEvent
+	for _, e := range m.events {
+		if e.EventType == eventType {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// useAuditRecorder swaps in an in-memory recorder for the test
+func useAuditRecorder(t *testing.T) *memoryAuditRecorder {
+	t.Helper()
+	recorder := &memoryAuditRecorder{}
+	previous := authAudit
+	SetAuthAuditRecorder(recorder)
+	t.Cleanup(func() { authAudit = previous })
+	return recorder
+}
+
+// useMiniredisSessions points the package session store at an in-memory Redis
+func useMiniredisSessions(t *testing.T) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	store, err := session.NewRedisStore(mr.Addr(), time.Hour)
+	require.NoError(t, err)
+	previous := sessionStore
+	sessionStore = store
+	t.Cleanup(func() {
+		sessionStore = previous
+		_ = store.Close()
+	})
+}
+
+func TestOAuthCallback_InvalidStateIsAudited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := useAuditRecorder(t)
+	useMiniredisSessions(t)
+
+	router := gin.New()
+	router.GET("/auth/github/callback", HandleGitHubOAuthCallbackWithSession)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/github/callback?code=abc&state=never-issued", http.NoBody)
+	req.Header.Set("User-Agent", "audit-test/1.0")
+	req.RemoteAddr = "203.0.113.7:51000"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	events := recorder.byType(portal_repositories.AuthEventStateValidation)
+	require.Len(t, events, 1)
+	assert.Equal(t, portal_repositories.AuthOutcomeFailure, events[0].Outcome)
+	assert.Equal(t, "state not found or expired", events[0].Reason)
+	assert.Equal(t, "203.0.113.7", events[0].IPAddress)
+	assert.Equal(t, "audit-test/1.0", events[0].UserAgent)
+	assert.Zero(t, events[0].UserID)
+}
+
+func TestHandleTokenExchange_SuccessIsAudited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := useAuditRecorder(t)
+	useMiniredisSessions(t)
+	t.Setenv("GITHUB_CLIENT_ID", "test-client-id")
+	t.Setenv("GITHUB_CLIENT_SECRET", "test-client-secret")
+	t.Setenv("REDIRECT_URI", "http://localhost:3000/callback")
+
+	originalClient := http.DefaultClient
+	http.DefaultClient = &http.Client{Transport: &mockRoundTripper{
+		handler: func(req *http.Request) (*http.Response, error) {
+			body := `{"login":"octocat","id":583231,"email":"octocat@example.com"}`
+			if strings.Contains(req.URL.String(), "login/oauth/access_token") {
+				body = `{"access_token":"gho_audit","token_type":"bearer","scope":"read:user"}`
+			}
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Body:          io.NopCloser(strings.NewReader(body)),
+				ContentLength: int64(len(body)),
+				Header:        http.Header{"Content-Type": []string{"application/json"}},
+			}, nil
+		},
+	}}
+	t.Cleanup(func() { http.DefaultClient = originalClient })
+
+	// Nothing listens here, so the exchange succeeds and persisting the user
+	// fails, which must not hide the successful exchange
+	db, err := sql.Open("postgres", "postgres://devsmith@127.0.0.1:1/devsmith?sslmode=disable&connect_timeout=1")
+	require.NoError(t, err)
+	previousDB := dbConn
+	dbConn = db
+	t.Cleanup(func() {
+		dbConn = previousDB
+		_ = db.Close()
+	})
+
+	router := gin.New()
+	router.POST("/api/portal/auth/token", HandleTokenExchange)
+	req := httptest.NewRequest(http.MethodPost, "/api/portal/auth/token",
+		strings.NewReader(`{"code":"abc","state":"s","code_verifier":"v"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "audit-test/1.0")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	exchanges := recorder.byType(portal_repositories.AuthEventTokenExchange)
+	require.Len(t, exchanges, 1)
+	assert.Equal(t, portal_repositories.AuthOutcomeSuccess, exchanges[0].Outcome)
+	assert.Equal(t, int64(583231), exchanges[0].GitHubID)
+	assert.Equal(t, "audit-test/1.0", exchanges[0].UserAgent)
+	assert.Empty(t, exchanges[0].Reason)
+
+	logins := recorder.byType(portal_repositories.AuthEventLogin)
+	require.Len(t, logins, 1)
+	assert.Equal(t, portal_repositories.AuthOutcomeFailure, logins[0].Outcome)
+	assert.Equal(t, "failed to persist user", logins[0].Reason)
+}
+
+func TestHandleTokenExchange_FailureIsAudited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := useAuditRecorder(t)
+	t.Setenv("GITHUB_CLIENT_ID", "test-client-id")
+	t.Setenv("GITHUB_CLIENT_SECRET", "test-client-secret")
+	t.Setenv("REDIRECT_URI", "http://localhost:3000/callback")
+
+	originalClient := http.DefaultClient
+	http.DefaultClient = &http.Client{Transport: &mockRoundTripper{
+		handler: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"error":"bad_verification_code","error_description":"The code passed is incorrect or expired."}`)),
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+			}, nil
+		},
+	}}
+	t.Cleanup(func() { http.DefaultClient = originalClient })
+
+	router := gin.New()
+	router.POST("/api/portal/auth/token", HandleTokenExchange)
+	req := httptest.NewRequest(http.MethodPost, "/api/portal/auth/token",
+		strings.NewReader(`{"code":"stale","state":"s","code_verifier":"v"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	exchanges := recorder.byType(portal_repositories.AuthEventTokenExchange)
+	require.Len(t, exchanges, 1)
+	assert.Equal(t, portal_repositories.AuthOutcomeFailure, exchanges[0].Outcome)
+	assert.Contains(t, exchanges[0].Reason, "bad_verification_code")
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/middleware"
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/security"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
 )
@@ -121,11 +123,16 @@ func storeOAuthState(state string) {
 	}
 }
 
-// validateOAuthState validates the state parameter from Redis and removes it
-func validateOAuthState(state string) bool {
+// errOAuthStateInvalid is returned for a state that was never issued, has
+// expired, or was already used
+var errOAuthStateInvalid = errors.New("state not found or expired")
+
+// validateOAuthState validates the state parameter from Redis and removes it.
+// Returns nil when the state is valid, otherwise why it is not.
+func validateOAuthState(state string) error {
 	if sessionStore == nil {
 		log.Println("[WARN] Session store not initialized, OAuth state validation will fail")
-		return false
+		return errors.New("session store not initialized")
 	}
 
 	ctx := context.Background()
@@ -134,16 +141,16 @@ func validateOAuthState(state string) bool {
 	valid, err := sessionStore.ValidateOAuthState(ctx, state)
 	if err != nil {
 		log.Printf("[ERROR] OAuth state validation error: %v", err)
-		return false
+		return fmt.Errorf("state lookup failed: %w", err)
 	}
 
-	if valid {
-		log.Printf("[OAUTH] State validated and removed from Redis: %s", state)
-	} else {
+	if !valid {
 		log.Printf("[WARN] OAuth state validation failed: state not found or expired: %s", state)
+		return errOAuthStateInvalid
 	}
 
-	return valid
+	log.Printf("[OAUTH] State validated and removed from Redis: %s", state)
+	return nil
 }
 
 // HandleOAuthHealthCheck checks if OAuth is properly configured and services are accessible
@@ -259,6 +266,7 @@ func HandleTestLogin(c *gin.Context) {
 			"avatar_url": req.AvatarURL,
 			"github_id":  req.GitHubID,
 			"is_test":    true,
+			"user_agent": c.Request.UserAgent(),
 		},
 	}
 
@@ -367,12 +375,13 @@ func HandleGitHubOAuthCallback(c *gin.Context) {
 	log.Printf("[DEBUG] Step 2: Exchanging code for token...")
 
 	// Legacy non-PKCE flow (no code_verifier)
-	accessToken, err := exchangeCodeForToken(code, "")
+	ghToken, err := exchangeCodeForToken(code, "")
 	if err != nil {
 		log.Printf("[ERROR] Failed to exchange code for token: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
 		return
 	}
+	accessToken := ghToken.AccessToken
 
 	log.Printf("[DEBUG] Step 3: Got access token, fetching user...")
 	user, err := FetchUserInfo(accessToken)
@@ -534,22 +543,30 @@ func HandleTokenExchange(c *gin.Context) {
 	// Exchange code for access token with PKCE code_verifier
 	// RFC 7636: code_verifier MUST be sent to token endpoint
 	// NOTE: Frontend validates encrypted state client-side - we only validate PKCE here
-	accessToken, err := exchangeCodeForToken(req.Code, req.CodeVerifier)
+	ghToken, err := exchangeCodeForToken(req.Code, req.CodeVerifier)
 	if err != nil {
 		log.Printf("[ERROR] Failed to exchange code: %v", err)
+		auditAuthFailure(c, portal_repositories.AuthEventTokenExchange, err.Error())
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to authenticate"})
 		return
 	}
+	accessToken := ghToken.AccessToken
 
 	// Fetch user info
 	user, err := FetchUserInfo(accessToken)
 	if err != nil {
 		log.Printf("[ERROR] Failed to fetch user: %v", err)
+		auditAuthFailure(c, portal_repositories.AuthEventTokenExchange, "failed to fetch GitHub user: "+err.Error())
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to fetch user info"})
 		return
 	}
 
 	log.Printf("[DEBUG] User authenticated: %s (ID: %d)", user.Login, user.ID)
+	auditAuthEvent(c, &portal_repositories.AuthAuditEvent{
+		EventType: portal_repositories.AuthEventTokenExchange,
+		Outcome:   portal_repositories.AuthOutcomeSuccess,
+		GitHubID:  user.ID,
+	})
 
 	// Step 7.5: Persist user to database (if not exists, create; if exists, update)
 	log.Printf("[OAUTH] Step 7.5: Persisting user to database")
@@ -577,6 +594,7 @@ func HandleTokenExchange(c *gin.Context) {
 
 	if err != nil {
 		log.Printf("[ERROR] Failed to persist user to database: %v", err)
+		auditLoginFailure(c, user.ID, "failed to persist user")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user account"})
 		return
 	}
@@ -589,16 +607,21 @@ func HandleTokenExchange(c *gin.Context) {
 		UserID:         userID, // <--- DATABASE ID (1, 2, 3...), NOT GitHub ID
 		GitHubUsername: user.Login,
 		GitHubToken:    accessToken,
+		// Only set when GitHub issues expiring tokens; review refreshes them
+		GitHubRefreshToken:   ghToken.RefreshToken,
+		GitHubTokenExpiresAt: ghToken.ExpiresAt,
 		Metadata: map[string]interface{}{
 			"email":      user.Email,
 			"avatar_url": user.AvatarURL,
 			"name":       user.Name,
 			"github_id":  int(user.ID), // Keep GitHub ID in metadata for reference
+			"user_agent": c.Request.UserAgent(),
 		},
 	}
 
 	if sessionStore == nil {
 		log.Printf("[ERROR] Session store not initialized")
+		auditLoginFailure(c, user.ID, "session store not initialized")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Session management error"})
 		return
 	}
@@ -606,6 +629,7 @@ func HandleTokenExchange(c *gin.Context) {
 	sessionID, err := sessionStore.Create(c.Request.Context(), sess)
 	if err != nil {
 		log.Printf("[ERROR] Failed to create session: %v", err)
+		auditLoginFailure(c, user.ID, "failed to create session")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
 		return
 	}
@@ -622,14 +646,22 @@ func HandleTokenExchange(c *gin.Context) {
 	tokenString, err := token.SignedString(jwtSecret)
 	if err != nil {
 		log.Printf("[ERROR] JWT generation failed: %v", err)
+		auditLoginFailure(c, user.ID, "failed to issue JWT")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue token"})
 		return
 	}
 
 	log.Printf("[DEBUG] Token exchange successful, session: %s", sessionID)
+	auditAuthEvent(c, &portal_repositories.AuthAuditEvent{
+		EventType: portal_repositories.AuthEventLogin,
+		Outcome:   portal_repositories.AuthOutcomeSuccess,
+		UserID:    userID,
+		GitHubID:  user.ID,
+	})
 
-	// Set httpOnly cookie
+	// Set httpOnly cookie, plus the CSRF token state-changing requests echo
 	SetSecureJWTCookie(c, tokenString)
+	middleware.SetCSRFCookie(c, sessionID)
 
 	// Return token to frontend (for localStorage)
 	c.JSON(http.StatusOK, gin.H{
@@ -732,9 +764,17 @@ func validateOAuthConfig() error {
 	return nil
 }
 
+// oauthToken is the result of a token exchange. RefreshToken and ExpiresAt
+// are only set for GitHub Apps with expiring user tokens.
+type oauthToken struct {
+	ExpiresAt    time.Time
+	AccessToken  string
+	RefreshToken string
+}
+
 // exchangeCodeForToken exchanges the authorization code for an access token
 // RFC 7636: For PKCE flow, code_verifier MUST be included
-func exchangeCodeForToken(code string, codeVerifier string) (string, error) {
+func exchangeCodeForToken(code string, codeVerifier string) (*oauthToken, error) {
 	log.Printf("[TOKEN_EXCHANGE] Step 1: Preparing token exchange request")
 
 	clientID := os.Getenv("GITHUB_CLIENT_ID")
@@ -742,14 +782,14 @@ func exchangeCodeForToken(code string, codeVerifier string) (string, error) {
 	redirectURI := os.Getenv("REDIRECT_URI")
 
 	if clientID == "" || clientSecret == "" {
-		return "", fmt.Errorf("OAuth credentials not configured")
+		return nil, fmt.Errorf("OAuth credentials not configured")
 	}
 
 	// Exchange code for access token
 	tokenReq, err := http.NewRequest("POST", "https://github.com/login/oauth/access_token", http.NoBody)
 	if err != nil {
 		log.Printf("[TOKEN_EXCHANGE] ERROR: Failed to create request: %v", err)
-		return "", fmt.Errorf("failed to create token request: %w", err)
+		return nil, fmt.Errorf("failed to create token request: %w", err)
 	}
 
 	q := tokenReq.URL.Query()
@@ -773,7 +813,7 @@ func exchangeCodeForToken(code string, codeVerifier string) (string, error) {
 	resp, err := http.DefaultClient.Do(tokenReq)
 	if err != nil {
 		log.Printf("[TOKEN_EXCHANGE] ERROR: Request failed: %v", err)
-		return "", fmt.Errorf("failed to send token request: %w", err)
+		return nil, fmt.Errorf("failed to send token request: %w", err)
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
@@ -785,7 +825,7 @@ func exchangeCodeForToken(code string, codeVerifier string) (string, error) {
 	bodyBytes, readErr := io.ReadAll(resp.Body)
 	if readErr != nil {
 		log.Printf("[TOKEN_EXCHANGE] ERROR: Failed to read response body: %v", readErr)
-		return "", fmt.Errorf("failed to read response body: %w", readErr)
+		return nil, fmt.Errorf("failed to read response body: %w", readErr)
 	}
 
 	log.Printf("[TOKEN_EXCHANGE] Step 3: Response received - status=%d, body_length=%d",
@@ -797,41 +837,47 @@ func exchangeCodeForToken(code string, codeVerifier string) (string, error) {
 	}
 
 	var tokenResp struct {
-		AccessToken string `json:"access_token"`
-		TokenType   string `json:"token_type"`
-		Scope       string `json:"scope"`
-		Error       string `json:"error"`
-		ErrorDesc   string `json:"error_description"`
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		Scope        string `json:"scope"`
+		Error        string `json:"error"`
+		ErrorDesc    string `json:"error_description"`
+		ExpiresIn    int    `json:"expires_in"`
 	}
 
 	if decodeErr := json.Unmarshal(bodyBytes, &tokenResp); decodeErr != nil {
 		log.Printf("[TOKEN_EXCHANGE] ERROR: Failed to decode JSON: %v", decodeErr)
-		return "", fmt.Errorf("failed to decode response: %w", decodeErr)
+		return nil, fmt.Errorf("failed to decode response: %w", decodeErr)
 	}
 
 	// Check for OAuth errors from GitHub
 	if tokenResp.Error != "" {
 		log.Printf("[TOKEN_EXCHANGE] ERROR: GitHub returned error: %s - %s",
 			tokenResp.Error, tokenResp.ErrorDesc)
-		return "", fmt.Errorf("github oauth error: %s - %s", tokenResp.Error, tokenResp.ErrorDesc)
+		return nil, fmt.Errorf("github oauth error: %s - %s", tokenResp.Error, tokenResp.ErrorDesc)
 	}
 
 	// Check HTTP status
 	if resp.StatusCode != http.StatusOK {
 		log.Printf("[TOKEN_EXCHANGE] ERROR: Unexpected status code: %d", resp.StatusCode)
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	// Validate access token
 	if tokenResp.AccessToken == "" {
 		log.Printf("[TOKEN_EXCHANGE] ERROR: Access token is empty")
-		return "", fmt.Errorf("access token is empty")
+		return nil, fmt.Errorf("access token is empty")
 	}
 
 	log.Printf("[TOKEN_EXCHANGE] Step 4: Token exchange successful - token_type=%s, scope=%s",
 		tokenResp.TokenType, tokenResp.Scope)
 
-	return tokenResp.AccessToken, nil
+	token := &oauthToken{AccessToken: tokenResp.AccessToken, RefreshToken: tokenResp.RefreshToken}
+	if tokenResp.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	}
+	return token, nil
 }
 
 var githubAPI = "https://api.github.com/user"
@@ -1027,6 +1073,7 @@ func HandleGitHubOAuthCallbackWithSession(c *gin.Context) {
 	// Validate state parameter (CSRF protection)
 	if state == "" {
 		log.Println("[ERROR] Missing state parameter in callback")
+		auditAuthFailure(c, portal_repositories.AuthEventStateValidation, "missing state parameter")
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Missing state parameter",
 			"details": "Security validation failed. This may indicate a CSRF attack or configuration issue.",
@@ -1035,8 +1082,9 @@ func HandleGitHubOAuthCallbackWithSession(c *gin.Context) {
 		return
 	}
 
-	if !validateOAuthState(state) {
+	if err := validateOAuthState(state); err != nil {
 		log.Printf("[WARN] OAuth state validation failed: received=%s", state)
+		auditAuthFailure(c, portal_repositories.AuthEventStateValidation, err.Error())
 
 		// Check if this might be from a cached GitHub authorization (passkey logins)
 		log.Println("[INFO] State validation failed - this may be from a cached GitHub authorization.")
@@ -1068,9 +1116,10 @@ func HandleGitHubOAuthCallbackWithSession(c *gin.Context) {
 	log.Println("[OAUTH] Step 5: Exchanging authorization code for access token")
 
 	// Exchange code for access token (legacy non-PKCE flow)
-	accessToken, err := exchangeCodeForToken(code, "")
+	ghToken, err := exchangeCodeForToken(code, "")
 	if err != nil {
 		log.Printf("[ERROR] Failed to exchange code for token: %v", err)
+		auditAuthFailure(c, portal_repositories.AuthEventTokenExchange, err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":             "Failed to exchange code for token",
 			"details":           "GitHub API error during token exchange.",
@@ -1080,6 +1129,7 @@ func HandleGitHubOAuthCallbackWithSession(c *gin.Context) {
 		})
 		return
 	}
+	accessToken := ghToken.AccessToken
 
 	log.Println("[OAUTH] Step 6: Token received, fetching user information from GitHub")
 
@@ -1087,6 +1137,7 @@ func HandleGitHubOAuthCallbackWithSession(c *gin.Context) {
 	user, err := FetchUserInfo(accessToken)
 	if err != nil {
 		log.Printf("[ERROR] Failed to fetch user info: %v", err)
+		auditAuthFailure(c, portal_repositories.AuthEventTokenExchange, "failed to fetch GitHub user: "+err.Error())
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":             "Failed to fetch user info from GitHub",
 			"details":           "Authenticated with GitHub, but could not retrieve user profile.",
@@ -1098,6 +1149,11 @@ func HandleGitHubOAuthCallbackWithSession(c *gin.Context) {
 	}
 
 	log.Printf("[OAUTH] Step 7: User authenticated: %s (GitHub ID: %d)", user.Login, user.ID)
+	auditAuthEvent(c, &portal_repositories.AuthAuditEvent{
+		EventType: portal_repositories.AuthEventTokenExchange,
+		Outcome:   portal_repositories.AuthOutcomeSuccess,
+		GitHubID:  user.ID,
+	})
 
 	// Persist user to database (insert or update if already exists)
 	// This ensures user exists in portal.users for foreign key relationships (e.g., llm_configs)
@@ -1126,6 +1182,7 @@ func HandleGitHubOAuthCallbackWithSession(c *gin.Context) {
 
 	if err != nil {
 		log.Printf("[ERROR] Failed to persist user to database: %v", err)
+		auditLoginFailure(c, user.ID, "failed to persist user")
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":             "Failed to create user account",
 			"details":           "Authentication succeeded, but could not create user record in database.",
@@ -1155,12 +1212,16 @@ func HandleGitHubOAuthCallbackWithSession(c *gin.Context) {
 		UserID:         userID, // Use database ID (portal.users.id)
 		GitHubUsername: user.Login,
 		GitHubToken:    accessToken,
+		// Only set when GitHub issues expiring tokens; review refreshes them
+		GitHubRefreshToken:   ghToken.RefreshToken,
+		GitHubTokenExpiresAt: ghToken.ExpiresAt,
 		Metadata: map[string]interface{}{
 			"email":        user.Email,
 			"avatar_url":   user.AvatarURL,
 			"name":         user.Name,
 			"github_id":    user.ID, // Store GitHub ID for reference
 			"logged_in_at": time.Now().Format(time.RFC3339),
+			"user_agent":   c.Request.UserAgent(),
 		},
 	}
 
@@ -1169,6 +1230,7 @@ func HandleGitHubOAuthCallbackWithSession(c *gin.Context) {
 	sessionID, err := sessionStore.Create(c.Request.Context(), sess)
 	if err != nil {
 		log.Printf("[ERROR] Failed to create session: %v", err)
+		auditLoginFailure(c, user.ID, "failed to create session")
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":             "Failed to create user session",
 			"details":           "Authentication succeeded, but could not create session in Redis.",
@@ -1205,14 +1267,22 @@ func HandleGitHubOAuthCallbackWithSession(c *gin.Context) {
 
 	log.Println("[OAUTH] Step 10: JWT created, setting secure cookie")
 
-	// Set httpOnly cookie for security
+	// Set httpOnly cookie for security, plus the CSRF token state-changing
+	// requests echo
 	SetSecureJWTCookie(c, tokenString)
+	middleware.SetCSRFCookie(c, sessionID)
 
 	// Redirect to React frontend callback route with token in URL
 	// This allows React to store token in localStorage for API calls
 	redirectURL := config.GetGatewayURL() + "/auth/callback?token=" + tokenString
 	log.Printf("[OAUTH] Step 11: Authentication complete! Redirecting to: %s", redirectURL)
 	log.Printf("[OAUTH] User %s (ID: %d) successfully authenticated", user.Login, user.ID)
+	auditAuthEvent(c, &portal_repositories.AuthAuditEvent{
+		EventType: portal_repositories.AuthEventLogin,
+		Outcome:   portal_repositories.AuthOutcomeSuccess,
+		UserID:    userID,
+		GitHubID:  user.ID,
+	})
 
 	c.Redirect(http.StatusFound, redirectURL)
 }
@@ -1253,10 +1323,25 @@ func HandleLogout(c *gin.Context) {
 		return
 	}
 
+	// Look up the owner for the audit trail before the session is gone
+	event := &portal_repositories.AuthAuditEvent{
+		EventType: portal_repositories.AuthEventLogout,
+		Outcome:   portal_repositories.AuthOutcomeSuccess,
+	}
+	if sess, err := sessionStore.Get(c.Request.Context(), sessionID); err == nil && sess != nil {
+		event.UserID = sess.UserID
+		if githubID, ok := sess.Metadata["github_id"].(float64); ok {
+			event.GitHubID = int64(githubID)
+		}
+	}
+
 	// Delete session from Redis
 	if err := sessionStore.Delete(c.Request.Context(), sessionID); err != nil {
 		log.Printf("[WARN] Failed to delete session from Redis: %v", err)
+		event.Outcome = portal_repositories.AuthOutcomeFailure
+		event.Reason = "failed to delete session"
 	}
+	auditAuthEvent(c, event)
 
 	// Clear JWT cookie
 	c.SetCookie("devsmith_token", "", -1, "/", "", false, true)
@@ -318,14 +318,17 @@ func TestExchangeCodeForToken(t *testing.T) {
 	log.Printf("Mock response body: %s", `{"access_token":"test-access-token","token_type":"Bearer","scope":"repo"}`)
 
 	// Call the function (updated for PKCE - now requires code_verifier)
-	accessToken, err := exchangeCodeForToken("test-code", "test-code-verifier")
+	token, err := exchangeCodeForToken("test-code", "test-code-verifier")
 
 	// Assertions
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
-	if accessToken != "test-access-token" {
-		t.Errorf("Expected access token 'test-access-token', got '%s'", accessToken)
+	if token.AccessToken != "test-access-token" {
+		t.Errorf("Expected access token 'test-access-token', got '%s'", token.AccessToken)
+	}
+	if token.RefreshToken != "" || !token.ExpiresAt.IsZero() {
+		t.Errorf("Expected an OAuth App token without expiry, got %+v", token)
 	}
 }
 
@@ -0,0 +1,95 @@
+package review_handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	templates "github.com/mikejsmith1985/devsmith-modular-platform/apps/review/templates"
+)
+
+// DefaultMaxConcurrentAnalyses is how many AI analyses one user may have in
+// flight at once unless configured otherwise.
+const DefaultMaxConcurrentAnalyses = 2
+
+// analysisLimiter is a keyed semaphore capping concurrent analyses per user,
+// so one user running every mode at once cannot exhaust the model backend
+// and trip the circuit breaker for everyone. The zero value uses
+// DefaultMaxConcurrentAnalyses.
+type analysisLimiter struct {
+	active map[string]int
+	max    int
+	mu     sync.Mutex
+}
+
+// acquire takes n slots for key and returns the current limit. It reports
+// false when taking them would put key over the limit; otherwise release
+// must be called to free the slots (further calls are no-ops).
+func (l *analysisLimiter) acquire(key string, n int) (release func(), limit int, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit = l.limit()
+	if l.active[key]+n > limit {
+		return nil, limit, false
+	}
+	if l.active == nil {
+		l.active = make(map[string]int)
+	}
+	l.active[key] += n
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.active[key] -= n; l.active[key] <= 0 {
+				delete(l.active, key)
+			}
+		})
+	}, limit, true
+}
+
+// limit must be called with mu held.
+func (l *analysisLimiter) limit() int {
+	if l.max <= 0 {
+		return DefaultMaxConcurrentAnalyses
+	}
+	return l.max
+}
+
+// SetMaxConcurrentAnalyses sets how many analyses each user may run at once.
+// Values below 1 restore the default.
+func (h *UIHandler) SetMaxConcurrentAnalyses(n int) {
+	h.analysisSlots.mu.Lock()
+	defer h.analysisSlots.mu.Unlock()
+	h.analysisSlots.max = n
+}
+
+// acquireAnalysisSlot reserves one of the caller's concurrent analysis slots,
+// keyed by user_id (or client IP when unauthenticated). When none is free it
+// writes a 429 with an HTMX message and reports false; otherwise the caller
+// must defer the returned release func.
+func (h *UIHandler) acquireAnalysisSlot(c *gin.Context) (func(), bool) {
+	return h.acquireAnalysisSlots(c, 1)
+}
+
+// acquireAnalysisSlots is acquireAnalysisSlot for a request that makes n
+// model calls at once; it takes all n slots or none.
+func (h *UIHandler) acquireAnalysisSlots(c *gin.Context, n int) (func(), bool) {
+	key := "ip:" + c.ClientIP()
+	if userID, ok := c.Get("user_id"); ok {
+		key = fmt.Sprintf("user:%v", userID)
+	}
+
+	release, limit, ok := h.analysisSlots.acquire(key, n)
+	if !ok {
+		h.logger.Warn("Concurrent analysis limit reached", "key", key, "limit", limit, "requested", n, "path", c.Request.URL.Path)
+		c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusTooManyRequests)
+		templates.AnalysisLimitReached(limit).Render(c.Request.Context(), c.Writer)
+		return nil, false
+	}
+	return release, true
+}
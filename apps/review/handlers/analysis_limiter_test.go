@@ -0,0 +1,172 @@
+package review_handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingOllama holds every call until unblock is closed and records the
+// highest number of calls in flight.
+type blockingOllama struct {
+	started  chan struct{}
+	unblock  chan struct{}
+	err      error
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func newBlockingOllama() *blockingOllama {
+	return &blockingOllama{started: make(chan struct{}, 16), unblock: make(chan struct{})}
+}
+
+func (m *blockingOllama) Generate(ctx context.Context, _ string) (string, error) {
+	n := m.inFlight.Add(1)
+	defer m.inFlight.Add(-1)
+	for {
+		peak := m.peak.Load()
+		if n <= peak || m.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	select {
+	case m.started <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-m.unblock:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	if m.err != nil {
+		return "", m.err
+	}
+	return `{"summary":"ok","bounded_contexts":[],"tech_stack":[],"file_tree":[]}`, nil
+}
+
+func setupLimitedPreview(t *testing.T, ollama *blockingOllama) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	handler := createTestHandler(t)
+	handler.previewService = review_services.NewPreviewService(ollama, &testutils.MockLogger{})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-User"))
+	})
+	router.POST("/api/review/modes/preview", handler.HandlePreviewMode)
+	return router
+}
+
+func postPreviewAs(router *gin.Engine, user string) *httptest.ResponseRecorder {
+	form := url.Values{"pasted_code": {"package main\nfunc main() {}"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/review/modes/preview", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Test-User", user)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func waitStarted(t *testing.T, ollama *blockingOllama, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-ollama.started:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("only %d of %d analyses started", i, n)
+		}
+	}
+}
+
+func TestModeHandlers_CapConcurrentAnalysesPerUser(t *testing.T) {
+	ollama := newBlockingOllama()
+	router := setupLimitedPreview(t, ollama)
+
+	// Five parallel requests from one user: only the default cap reach the model
+	const fired = 5
+	codes := make(chan int, fired)
+	var wg sync.WaitGroup
+	for i := 0; i < fired; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- postPreviewAs(router, "alice").Code
+		}()
+	}
+	waitStarted(t, ollama, DefaultMaxConcurrentAnalyses)
+
+	// Rejections return immediately while the admitted requests are still running
+	for i := 0; i < fired-DefaultMaxConcurrentAnalyses; i++ {
+		select {
+		case code := <-codes:
+			assert.Equal(t, http.StatusTooManyRequests, code)
+		case <-time.After(2 * time.Second):
+			t.Fatal("excess request was not rejected")
+		}
+	}
+	rejected := postPreviewAs(router, "alice")
+	assert.Equal(t, http.StatusTooManyRequests, rejected.Code)
+	assert.Contains(t, rejected.Body.String(), "Analyses Already Running")
+
+	// Another user is not affected
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		codes <- postPreviewAs(router, "bob").Code
+	}()
+	waitStarted(t, ollama, 1)
+
+	close(ollama.unblock)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+	assert.Equal(t, int32(DefaultMaxConcurrentAnalyses+1), ollama.peak.Load())
+
+	// Slots were released
+	assert.Equal(t, http.StatusOK, postPreviewAs(router, "alice").Code)
+}
+
+func TestModeHandlers_ReleaseSlotOnError(t *testing.T) {
+	ollama := newBlockingOllama()
+	ollama.err = errors.New("context deadline exceeded")
+	close(ollama.unblock)
+	router := setupLimitedPreview(t, ollama)
+
+	for i := 0; i < 2*DefaultMaxConcurrentAnalyses+1; i++ {
+		w := postPreviewAs(router, "alice")
+		assert.NotEqual(t, http.StatusTooManyRequests, w.Code, "request %d", i)
+	}
+}
+
+func TestSetMaxConcurrentAnalyses(t *testing.T) {
+	handler := createTestHandler(t)
+	handler.SetMaxConcurrentAnalyses(1)
+
+	release, limit, ok := handler.analysisSlots.acquire("user:1", 1)
+	require.True(t, ok)
+	assert.Equal(t, 1, limit)
+	_, _, ok = handler.analysisSlots.acquire("user:1", 1)
+	assert.False(t, ok)
+	release()
+	release()
+	_, _, ok = handler.analysisSlots.acquire("user:1", 1)
+	assert.True(t, ok, "release is idempotent and frees the slot")
+}
@@ -0,0 +1,151 @@
+package review_handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// AnalysisSearcher searches a user's stored mode results.
+type AnalysisSearcher interface {
+	SearchAnalyses(ctx context.Context, userID int64, filter review_models.AnalysisSearchFilter) ([]review_models.AnalysisSearchResult, error)
+}
+
+// SetAnalysisSearcher enables the analysis history search endpoint.
+func (h *UIHandler) SetAnalysisSearcher(searcher AnalysisSearcher) {
+	h.analysisSearcher = searcher
+}
+
+// analysisSearchQuery is the query string accepted by SearchAnalysesHandler.
+// Dates are RFC 3339 timestamps or YYYY-MM-DD days; a day in "to" includes
+// the whole day.
+type analysisSearchQuery struct {
+	Mode     string `form:"mode"`
+	Repo     string `form:"repo"`
+	MinGrade string `form:"min_grade"`
+	MaxGrade string `form:"max_grade"`
+	From     string `form:"from"`
+	To       string `form:"to"`
+	Limit    int    `form:"limit"`
+	Offset   int    `form:"offset"`
+}
+
+// SearchAnalysesHandler handles GET /api/review/analyses/search, returning a
+// page of the authenticated user's analysis history filtered by mode, grade,
+// repository and date range, newest first.
+func (h *UIHandler) SearchAnalysesHandler(c *gin.Context) {
+	if h.analysisSearcher == nil {
+		h.logger.Warn("Analysis searcher not configured")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Analysis history is unavailable"})
+		return
+	}
+	userID := requestUserID(c)
+	if userID <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var query analysisSearchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	filter, err := query.filter()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := h.analysisSearcher.SearchAnalyses(c.Request.Context(), userID, filter)
+	if err != nil {
+		h.logger.Error("Failed to search analyses", "error", err.Error(), "user_id", userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search analyses"})
+		return
+	}
+
+	response := gin.H{"results": results, "limit": filter.Limit, "offset": filter.Offset}
+	// A full page may have more behind it
+	if len(results) == filter.Limit {
+		response["next_offset"] = filter.Offset + filter.Limit
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// filter validates the query and converts it to a search filter.
+func (q analysisSearchQuery) filter() (review_models.AnalysisSearchFilter, error) {
+	filter := review_models.AnalysisSearchFilter{
+		Mode:     q.Mode,
+		Repo:     q.Repo,
+		MinGrade: q.MinGrade,
+		MaxGrade: q.MaxGrade,
+		Limit:    defaultSessionPageSize,
+	}
+	if _, known := modeIcons[q.Mode]; q.Mode != "" && !known {
+		return filter, fmt.Errorf("unknown mode %q", q.Mode)
+	}
+	if q.MinGrade != "" && review_models.GradeRank(q.MinGrade) == 0 {
+		return filter, fmt.Errorf("min_grade must be a letter grade A-F")
+	}
+	if q.MaxGrade != "" && review_models.GradeRank(q.MaxGrade) == 0 {
+		return filter, fmt.Errorf("max_grade must be a letter grade A-F")
+	}
+	if q.MinGrade != "" && q.MaxGrade != "" && review_models.GradeRank(q.MinGrade) > review_models.GradeRank(q.MaxGrade) {
+		return filter, fmt.Errorf("min_grade %s is better than max_grade %s", q.MinGrade, q.MaxGrade)
+	}
+
+	var err error
+	if filter.DateFrom, err = parseSearchDate(q.From, false); err != nil {
+		return filter, fmt.Errorf("from: %w", err)
+	}
+	if filter.DateTo, err = parseSearchDate(q.To, true); err != nil {
+		return filter, fmt.Errorf("to: %w", err)
+	}
+	if !filter.DateFrom.IsZero() && !filter.DateTo.IsZero() && !filter.DateFrom.Before(filter.DateTo) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+
+	if q.Limit < 0 || q.Offset < 0 {
+		return filter, fmt.Errorf("limit and offset must not be negative")
+	}
+	if q.Limit > 0 {
+		filter.Limit = min(q.Limit, maxSessionPageSize)
+	}
+	filter.Offset = q.Offset
+	return filter, nil
+}
+
+// parseSearchDate parses an RFC 3339 timestamp or a YYYY-MM-DD day. With
+// endOfDay, a day means the start of the following day, so it can be used as
+// an exclusive upper bound.
+func parseSearchDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date (YYYY-MM-DD) or RFC 3339 timestamp", value)
+	}
+	if endOfDay {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+// requestUserID returns the authenticated user's ID, or 0 if there is none.
+func requestUserID(c *gin.Context) int64 {
+	value, _ := c.Get("user_id")
+	switch id := value.(type) {
+	case int:
+		return int64(id)
+	case int64:
+		return id
+	}
+	return 0
+}
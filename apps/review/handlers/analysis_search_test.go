@@ -0,0 +1,186 @@
+package review_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ownedResult is a stored result and the user whose session it belongs to.
+type ownedResult struct {
+	review_models.AnalysisSearchResult
+	userID int64
+}
+
+// memoryAnalysisSearcher applies a search filter the way the repository does.
+type memoryAnalysisSearcher struct {
+	results []ownedResult
+	filter  review_models.AnalysisSearchFilter
+}
+
+func (m *memoryAnalysisSearcher) SearchAnalyses(_ context.Context, userID int64, filter review_models.AnalysisSearchFilter) ([]review_models.AnalysisSearchResult, error) {
+	m.filter = filter
+	matched := []review_models.AnalysisSearchResult{}
+	for _, r := range m.results {
+		rank := review_models.GradeRank(r.Grade)
+		switch {
+		case r.userID != userID,
+			filter.Mode != "" && r.Mode != filter.Mode,
+			filter.Repo != "" && !strings.Contains(strings.ToLower(r.GithubRepo), strings.ToLower(filter.Repo)),
+			filter.MinGrade != "" && rank < review_models.GradeRank(filter.MinGrade),
+			filter.MaxGrade != "" && (rank == 0 || rank > review_models.GradeRank(filter.MaxGrade)),
+			!filter.DateFrom.IsZero() && r.CreatedAt.Before(filter.DateFrom),
+			!filter.DateTo.IsZero() && !r.CreatedAt.Before(filter.DateTo):
+			continue
+		}
+		matched = append(matched, r.AnalysisSearchResult)
+	}
+	if filter.Offset >= len(matched) {
+		return []review_models.AnalysisSearchResult{}, nil
+	}
+	return matched[filter.Offset:min(filter.Offset+filter.Limit, len(matched))], nil
+}
+
+func seededAnalysisSearcher() *memoryAnalysisSearcher {
+	day := func(d int) time.Time { return time.Date(2025, 11, d, 12, 0, 0, 0, time.UTC) }
+	result := func(id, userID int64, mode, repo, grade string, created time.Time) ownedResult {
+		return ownedResult{userID: userID, AnalysisSearchResult: review_models.AnalysisSearchResult{
+			ID: id, SessionID: id, Mode: mode, GithubRepo: repo, Grade: grade, CreatedAt: created,
+		}}
+	}
+	// Newest first, as the repository returns them
+	return &memoryAnalysisSearcher{results: []ownedResult{
+		result(6, 2, review_models.CriticalMode, "acme/shop", "A", day(20)),
+		result(5, 1, review_models.CriticalMode, "acme/shop", "A", day(18)),
+		result(4, 1, review_models.CriticalMode, "acme/billing", "C", day(15)),
+		result(3, 1, review_models.CriticalMode, "acme/shop", "F", day(12)),
+		result(2, 1, review_models.SkimMode, "Acme/Shop", "", day(10)),
+		result(1, 1, review_models.PreviewMode, "other/tool", "", day(5)),
+	}}
+}
+
+func setupAnalysisSearchRoute(t *testing.T, searcher AnalysisSearcher, userID interface{}) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	handler := createTestHandler(t)
+	if searcher != nil {
+		handler.SetAnalysisSearcher(searcher)
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID != nil {
+			c.Set("user_id", userID)
+		}
+	})
+	router.GET("/api/review/analyses/search", handler.SearchAnalysesHandler)
+	return router
+}
+
+type analysisSearchResponse struct {
+	Results    []review_models.AnalysisSearchResult `json:"results"`
+	NextOffset *int                                 `json:"next_offset"`
+	Limit      int                                  `json:"limit"`
+	Offset     int                                  `json:"offset"`
+}
+
+func searchAnalyses(t *testing.T, router *gin.Engine, query string) (int, analysisSearchResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/review/analyses/search"+query, http.NoBody))
+	var resp analysisSearchResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp
+}
+
+func resultIDs(results []review_models.AnalysisSearchResult) []int64 {
+	ids := make([]int64, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestSearchAnalyses_FiltersNarrowResults(t *testing.T) {
+	router := setupAnalysisSearchRoute(t, seededAnalysisSearcher(), int64(1))
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int64
+	}{
+		{"no filters returns only the user's analyses", "", []int64{5, 4, 3, 2, 1}},
+		{"mode", "?mode=critical", []int64{5, 4, 3}},
+		{"repo substring ignores case", "?repo=SHOP", []int64{5, 3, 2}},
+		{"min grade", "?min_grade=C", []int64{5, 4}},
+		{"max grade excludes ungraded", "?max_grade=c", []int64{4, 3}},
+		{"grade range", "?min_grade=D&max_grade=B", []int64{4}},
+		{"date range with whole days", "?from=2025-11-10&to=2025-11-15", []int64{4, 3, 2}},
+		{"timestamp bounds", "?from=2025-11-12T12:00:00Z&to=2025-11-18T12:00:00Z", []int64{4, 3}},
+		{"combined", "?mode=critical&repo=shop&min_grade=B", []int64{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, resp := searchAnalyses(t, router, tt.query)
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, tt.want, resultIDs(resp.Results))
+		})
+	}
+}
+
+func TestSearchAnalyses_Pagination(t *testing.T) {
+	searcher := seededAnalysisSearcher()
+	router := setupAnalysisSearchRoute(t, searcher, 1)
+
+	code, resp := searchAnalyses(t, router, "?limit=2")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int64{5, 4}, resultIDs(resp.Results))
+	require.NotNil(t, resp.NextOffset)
+	assert.Equal(t, 2, *resp.NextOffset)
+
+	code, resp = searchAnalyses(t, router, "?limit=2&offset=4")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int64{1}, resultIDs(resp.Results))
+	assert.Nil(t, resp.NextOffset, "a short page is the last one")
+
+	searchAnalyses(t, router, "?limit=1000")
+	assert.Equal(t, maxSessionPageSize, searcher.filter.Limit)
+}
+
+func TestSearchAnalyses_InvalidFilters(t *testing.T) {
+	router := setupAnalysisSearchRoute(t, seededAnalysisSearcher(), int64(1))
+
+	for _, query := range []string{
+		"?mode=unknown",
+		"?min_grade=E",
+		"?max_grade=excellent",
+		"?min_grade=A&max_grade=C",
+		"?from=yesterday",
+		"?from=2025-11-15&to=2025-11-10",
+		"?limit=-1",
+		"?offset=abc",
+	} {
+		code, _ := searchAnalyses(t, router, query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
+
+func TestSearchAnalyses_RequiresAuthAndStore(t *testing.T) {
+	code, _ := searchAnalyses(t, setupAnalysisSearchRoute(t, seededAnalysisSearcher(), nil), "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	code, _ = searchAnalyses(t, setupAnalysisSearchRoute(t, nil, int64(1)), "")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
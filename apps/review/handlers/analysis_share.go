@@ -0,0 +1,246 @@
+package review_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+)
+
+// AnalysisSharer mints, resolves and revokes read-only share links.
+type AnalysisSharer interface {
+	Create(ctx context.Context, userID, analysisID int64, ttl time.Duration) (*review_models.AnalysisShare, string, error)
+	Resolve(ctx context.Context, token string) (*review_models.AnalysisDetail, error)
+	Revoke(ctx context.Context, userID int64, shareID string) error
+}
+
+// SetAnalysisSharer enables share links for analysis results.
+func (h *UIHandler) SetAnalysisSharer(sharer AnalysisSharer) {
+	h.analysisSharer = sharer
+}
+
+// shareAnalysisRequest is the optional body of POST /api/review/analyses/:id/share.
+type shareAnalysisRequest struct {
+	ExpiresInHours int `json:"expires_in_hours"`
+}
+
+// ShareAnalysisHandler handles POST /api/review/analyses/:id/share, minting
+// an expiring read-only link to one of the user's analysis results.
+func (h *UIHandler) ShareAnalysisHandler(c *gin.Context) {
+	userID, ok := h.sharingUser(c)
+	if !ok {
+		return
+	}
+	analysisID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || analysisID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid analysis ID"})
+		return
+	}
+	var req shareAnalysisRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil || req.ExpiresInHours < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_hours must be a positive number of hours"})
+			return
+		}
+	}
+
+	share, token, err := h.analysisSharer.Create(c.Request.Context(), userID, analysisID, time.Duration(req.ExpiresInHours)*time.Hour)
+	if errors.Is(err, review_services.ErrAnalysisNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Analysis not found"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to share analysis", "error", err.Error(), "analysis_id", analysisID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share analysis"})
+		return
+	}
+
+	h.logger.Info("Analysis shared", "analysis_id", analysisID, "share_id", share.ID, "expires_at", share.ExpiresAt)
+	c.JSON(http.StatusCreated, gin.H{
+		"share": share,
+		"token": token,
+		"url":   "/api/review/shared/" + token,
+	})
+}
+
+// RevokeShareHandler handles DELETE /api/review/shares/:id. The link stops
+// working immediately.
+func (h *UIHandler) RevokeShareHandler(c *gin.Context) {
+	userID, ok := h.sharingUser(c)
+	if !ok {
+		return
+	}
+	shareID := c.Param("id")
+	err := h.analysisSharer.Revoke(c.Request.Context(), userID, shareID)
+	if errors.Is(err, review_services.ErrShareNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found"})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to revoke share", "error", err.Error(), "share_id", shareID)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share"})
+		return
+	}
+	h.logger.Info("Share revoked", "share_id", shareID)
+	c.Status(http.StatusNoContent)
+}
+
+// SharedAnalysisHandler handles GET /api/review/shared/:token. It is public:
+// the signed token is the credential. Tampered tokens are rejected with 401,
+// expired or revoked links with 410.
+func (h *UIHandler) SharedAnalysisHandler(c *gin.Context) {
+	// The token is in the URL; keep it out of caches, indexes and referrers
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Robots-Tag", "noindex")
+
+	if h.analysisSharer == nil {
+		h.logger.Warn("Analysis sharing not configured")
+		renderSharedError(c, http.StatusServiceUnavailable, "Shared analyses are unavailable right now.")
+		return
+	}
+
+	detail, err := h.analysisSharer.Resolve(c.Request.Context(), c.Param("token"))
+	switch {
+	case errors.Is(err, review_services.ErrShareTokenInvalid):
+		renderSharedError(c, http.StatusUnauthorized, "This share link is not valid.")
+		return
+	case errors.Is(err, review_services.ErrShareExpired):
+		renderSharedError(c, http.StatusGone, "This share link has expired.")
+		return
+	case errors.Is(err, review_services.ErrShareRevoked):
+		renderSharedError(c, http.StatusGone, "This share link has been revoked.")
+		return
+	case errors.Is(err, review_services.ErrAnalysisNotFound):
+		renderSharedError(c, http.StatusNotFound, "The shared analysis no longer exists.")
+		return
+	case err != nil:
+		h.logger.Error("Failed to load shared analysis", "error", err.Error())
+		renderSharedError(c, http.StatusInternalServerError, "Failed to load the shared analysis.")
+		return
+	}
+
+	view := sharedAnalysisView{
+		Title:     sessionInfo(&review_models.SessionSummary{ID: detail.ReviewID, Title: detail.SessionTitle, GithubRepo: detail.GithubRepo}).Title,
+		Repo:      detail.GithubRepo,
+		Mode:      modeTitle(detail.Mode) + " Mode",
+		ModelUsed: detail.ModelUsed,
+		CreatedAt: detail.CreatedAt.UTC().Format(sessionTimeLayout + " MST"),
+		Summary:   detail.Summary,
+	}
+	if detail.Mode == review_models.CriticalMode {
+		var out review_models.CriticalModeOutput
+		if json.Unmarshal([]byte(detail.Output), &out) == nil {
+			view.Critical = true
+			view.Grade = out.OverallGrade
+			view.Summary = firstNonEmpty(out.Summary, detail.Summary)
+			view.Groups = groupIssuesBySeverity(out.Issues)
+		}
+	} else if summary := outputSummary(detail.Output); summary != "" {
+		view.Summary = summary
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := sharedAnalysisTemplate.Execute(c.Writer, view); err != nil {
+		h.logger.Error("Failed to render shared analysis", "error", err.Error())
+	}
+}
+
+// sharingUser returns the authenticated user for the share endpoints,
+// writing an error response and reporting false if sharing is disabled or
+// there is no user.
+func (h *UIHandler) sharingUser(c *gin.Context) (int64, bool) {
+	if h.analysisSharer == nil {
+		h.logger.Warn("Analysis sharing not configured")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Sharing is unavailable"})
+		return 0, false
+	}
+	userID := requestUserID(c)
+	if userID <= 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// outputSummary returns the summary field of a stored mode output.
+func outputSummary(output string) string {
+	var out struct {
+		Summary string `json:"summary"`
+	}
+	_ = json.Unmarshal([]byte(output), &out)
+	return out.Summary
+}
+
+// sharedAnalysisView is the data behind the shared analysis page.
+type sharedAnalysisView struct {
+	Title     string
+	Repo      string
+	Mode      string
+	ModelUsed string
+	CreatedAt string
+	Summary   string
+	Grade     string
+	Groups    []issueGroup
+	Critical  bool
+	Error     string
+}
+
+func renderSharedError(c *gin.Context, status int, message string) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	_ = sharedAnalysisTemplate.Execute(c.Writer, sharedAnalysisView{Title: "Shared analysis", Error: message})
+}
+
+// sharedAnalysisTemplate renders a standalone read-only page. html/template
+// escapes the model output, which anyone holding the link can view.
+var sharedAnalysisTemplate = template.Must(template.New("shared").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}} · DevSmith Review</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:52rem;margin:2rem auto;padding:0 1rem;color:#1f2937;line-height:1.5}
+header{border-bottom:1px solid #e5e7eb;margin-bottom:1.5rem}
+.meta{color:#6b7280;font-size:.875rem}
+.grade{font-size:2rem;font-weight:700;color:#dc2626}
+.issue{border:1px solid #e5e7eb;border-radius:.5rem;padding:.75rem 1rem;margin:.75rem 0}
+.issue code{font-size:.8rem;color:#4b5563}
+.fix{background:#f0fdf4;border-radius:.25rem;padding:.5rem;margin-top:.5rem}
+.sev-critical{border-color:#fca5a5}.sev-high{border-color:#fdba74}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Error}}</header>
+<p>{{.Error}}</p>
+{{else}}<p class="meta">{{.Mode}}{{if .Repo}} · {{.Repo}}{{end}} · {{.CreatedAt}}{{if .ModelUsed}} · {{.ModelUsed}}{{end}}</p>
+</header>
+{{if .Grade}}<p>Overall grade: <span class="grade">{{.Grade}}</span></p>{{end}}
+{{if .Summary}}<p>{{.Summary}}</p>{{end}}
+{{if .Critical}}{{if .Groups}}{{range $group := .Groups}}
+<section>
+<h2>{{$group.Title}} ({{len $group.Issues}})</h2>
+{{range $group.Issues}}<div class="issue sev-{{$group.Severity}}">
+<strong>{{.Category}}</strong>{{if .File}} <code>{{.File}}{{if gt .Line 0}}:{{.Line}}{{end}}</code>{{end}}
+<p>{{.Description}}</p>
+{{if .Impact}}<p><em>Impact:</em> {{.Impact}}</p>{{end}}
+{{if .FixSuggestion}}<div class="fix">Fix: {{.FixSuggestion}}</div>{{end}}
+</div>
+{{end}}</section>
+{{end}}{{else}}<p>No issues found.</p>{{end}}{{end}}
+{{end}}<p class="meta">Shared read-only from DevSmith Review.</p>
+</body>
+</html>
+`))
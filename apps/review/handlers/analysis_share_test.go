@@ -0,0 +1,207 @@
+package review_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryShareRepo stores shares and serves one user's analyses.
+type memoryShareRepo struct {
+	shares   map[string]*review_models.AnalysisShare
+	analyses map[int64]*review_models.AnalysisDetail
+}
+
+func (m *memoryShareRepo) CreateShare(_ context.Context, share *review_models.AnalysisShare) error {
+	stored := *share
+	m.shares[share.ID] = &stored
+	return nil
+}
+
+func (m *memoryShareRepo) GetShare(_ context.Context, id string) (*review_models.AnalysisShare, error) {
+	return m.shares[id], nil
+}
+
+func (m *memoryShareRepo) RevokeShare(_ context.Context, userID int64, id string) (bool, error) {
+	share, ok := m.shares[id]
+	if !ok || share.UserID != userID {
+		return false, nil
+	}
+	now := time.Now()
+	share.RevokedAt = &now
+	return true, nil
+}
+
+func (m *memoryShareRepo) GetAnalysisDetail(_ context.Context, id int64) (*review_models.AnalysisDetail, error) {
+	return m.analyses[id], nil
+}
+
+// expiredSharer reports every link as expired.
+type expiredSharer struct{ AnalysisSharer }
+
+func (expiredSharer) Resolve(context.Context, string) (*review_models.AnalysisDetail, error) {
+	return nil, review_services.ErrShareExpired
+}
+
+func newShareTestRepo(t *testing.T) *memoryShareRepo {
+	t.Helper()
+	output := mustJSON(t, review_models.CriticalModeOutput{
+		OverallGrade: "D",
+		Summary:      "Unsafe query construction",
+		Issues: []review_models.CodeIssue{
+			{Severity: "critical", Category: "security", File: "orders/repo.go", Line: 88, Description: "SQL injection via <order_id>", FixSuggestion: "Use a parameterized query"},
+			{Severity: "low", Category: "style", Description: "Inconsistent receiver names"},
+		},
+	})
+	return &memoryShareRepo{
+		shares: map[string]*review_models.AnalysisShare{},
+		analyses: map[int64]*review_models.AnalysisDetail{11: {
+			ID:           11,
+			UserID:       7,
+			SessionTitle: "Checkout service",
+			GithubRepo:   "acme/shop",
+			AnalysisResult: review_models.AnalysisResult{
+				ReviewID:  3,
+				Mode:      review_models.CriticalMode,
+				ModelUsed: "qwen2.5-coder:7b",
+				Output:    output,
+				CreatedAt: time.Date(2025, 11, 22, 10, 0, 0, 0, time.UTC),
+			},
+		}},
+	}
+}
+
+// setupShareRoutes serves the share endpoints; protected routes see userID.
+func setupShareRoutes(t *testing.T, sharer AnalysisSharer, userID interface{}) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	handler := createTestHandler(t)
+	handler.SetAnalysisSharer(sharer)
+
+	router := gin.New()
+	router.GET("/api/review/shared/:token", handler.SharedAnalysisHandler)
+	protected := router.Group("/")
+	protected.Use(func(c *gin.Context) {
+		if userID != nil {
+			c.Set("user_id", userID)
+		}
+	})
+	protected.POST("/api/review/analyses/:id/share", handler.ShareAnalysisHandler)
+	protected.DELETE("/api/review/shares/:id", handler.RevokeShareHandler)
+	return router
+}
+
+func serveShare(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+type shareResponse struct {
+	Share review_models.AnalysisShare `json:"share"`
+	Token string                      `json:"token"`
+	URL   string                      `json:"url"`
+}
+
+func createShare(t *testing.T, router *gin.Engine, body string) shareResponse {
+	t.Helper()
+	w := serveShare(router, http.MethodPost, "/api/review/analyses/11/share", body)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp shareResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestSharedAnalysis_ValidTokenRenders(t *testing.T) {
+	repo := newShareTestRepo(t)
+	router := setupShareRoutes(t, review_services.NewShareService(repo, repo, []byte("test-secret")), int64(7))
+
+	share := createShare(t, router, `{"expires_in_hours": 48}`)
+	assert.Equal(t, "/api/review/shared/"+share.Token, share.URL)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), share.Share.ExpiresAt, time.Minute)
+
+	// Viewed by someone without a session
+	w := serveShare(router, http.MethodGet, share.URL, "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	page := w.Body.String()
+	assert.Contains(t, page, "<h1>Checkout service</h1>")
+	assert.Contains(t, page, "Critical Mode · acme/shop")
+	assert.Contains(t, page, `<span class="grade">D</span>`)
+	assert.Contains(t, page, "Critical (1)")
+	assert.Contains(t, page, "<code>orders/repo.go:88</code>")
+	assert.Contains(t, page, "SQL injection via &lt;order_id&gt;", "model output is escaped")
+	assert.Contains(t, page, "Fix: Use a parameterized query")
+	assert.Less(t, strings.Index(page, "Critical (1)"), strings.Index(page, "Low (1)"))
+}
+
+func TestSharedAnalysis_TamperedTokenIsUnauthorized(t *testing.T) {
+	repo := newShareTestRepo(t)
+	router := setupShareRoutes(t, review_services.NewShareService(repo, repo, []byte("test-secret")), int64(7))
+	share := createShare(t, router, "")
+
+	// Point the token at another analysis, keeping the signature
+	parts := strings.Split(share.Token, ".")
+	parts[1] = "12"
+	w := serveShare(router, http.MethodGet, "/api/review/shared/"+strings.Join(parts, "."), "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotContains(t, w.Body.String(), "Checkout service")
+
+	w = serveShare(router, http.MethodGet, "/api/review/shared/garbage", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSharedAnalysis_ExpiredTokenIsGone(t *testing.T) {
+	router := setupShareRoutes(t, expiredSharer{}, nil)
+
+	w := serveShare(router, http.MethodGet, "/api/review/shared/abc.11.1700000000.sig", "")
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.Contains(t, w.Body.String(), "expired")
+}
+
+func TestSharedAnalysis_RevokedTokenIsGone(t *testing.T) {
+	repo := newShareTestRepo(t)
+	router := setupShareRoutes(t, review_services.NewShareService(repo, repo, []byte("test-secret")), int64(7))
+	share := createShare(t, router, "")
+
+	w := serveShare(router, http.MethodDelete, "/api/review/shares/"+share.Share.ID, "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = serveShare(router, http.MethodGet, share.URL, "")
+	assert.Equal(t, http.StatusGone, w.Code)
+	assert.Contains(t, w.Body.String(), "revoked")
+}
+
+func TestShareAnalysis_OwnerOnly(t *testing.T) {
+	repo := newShareTestRepo(t)
+	service := review_services.NewShareService(repo, repo, []byte("test-secret"))
+
+	w := serveShare(setupShareRoutes(t, service, int64(8)), http.MethodPost, "/api/review/analyses/11/share", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveShare(setupShareRoutes(t, service, nil), http.MethodPost, "/api/review/analyses/11/share", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	owner := setupShareRoutes(t, service, int64(7))
+	w = serveShare(owner, http.MethodPost, "/api/review/analyses/11/share", `{"expires_in_hours": -1}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	share := createShare(t, owner, "")
+	w = serveShare(setupShareRoutes(t, service, int64(8)), http.MethodDelete, "/api/review/shares/"+share.Share.ID, "")
+	assert.Equal(t, http.StatusNotFound, w.Code, "only the owner can revoke")
+}
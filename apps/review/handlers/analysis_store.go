@@ -0,0 +1,222 @@
+package review_handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	templates "github.com/mikejsmith1985/devsmith-modular-platform/apps/review/templates"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// AnalysisStore persists mode results per review session. Results are keyed
+// by a hash of their inputs, so repeating a request for the same code is
+// answered from storage instead of calling the model again.
+type AnalysisStore interface {
+	FindLatest(ctx context.Context, reviewID int64, mode, inputHash string) (*review_models.AnalysisResult, error)
+	ListLatestByReview(ctx context.Context, reviewID int64) ([]review_models.AnalysisResult, error)
+	Create(ctx context.Context, result *review_models.AnalysisResult) error
+}
+
+// storedAnalysisMetadata is kept alongside a stored result so the workspace
+// can restore the code it was produced from.
+type storedAnalysisMetadata struct {
+	Code       string `json:"code"`
+	UserMode   string `json:"user_mode,omitempty"`
+	OutputMode string `json:"output_mode,omitempty"`
+	Query      string `json:"query,omitempty"`
+}
+
+// SetAnalysisStore enables persisting mode results and serving repeated
+// requests for a session from storage.
+func (h *UIHandler) SetAnalysisStore(store AnalysisStore) {
+	h.analysisStore = store
+}
+
+// sessionIDParam is a workspace session ID bound leniently: a value that
+// isn't a positive integer, such as a client's own opaque session string, is
+// ignored instead of failing the request.
+type sessionIDParam int64
+
+// parseSessionIDParam returns the session ID in s, or 0 if there is none.
+func parseSessionIDParam(s string) sessionIDParam {
+	id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return sessionIDParam(id)
+}
+
+// UnmarshalJSON accepts a number or a numeric string.
+func (p *sessionIDParam) UnmarshalJSON(data []byte) error {
+	*p = parseSessionIDParam(strings.Trim(string(data), `"`))
+	return nil
+}
+
+// UnmarshalParam implements binding.BindUnmarshaler for form values.
+func (p *sessionIDParam) UnmarshalParam(param string) error {
+	*p = parseSessionIDParam(param)
+	return nil
+}
+
+// ownedSession reports whether sessionID is one of the requesting user's
+// review sessions. Stored results are only read or written for those.
+func (h *UIHandler) ownedSession(c *gin.Context, sessionID int64) bool {
+	if h.sessionStore == nil || sessionID <= 0 {
+		return false
+	}
+	userID := requestUserID(c)
+	if userID <= 0 {
+		return false
+	}
+	session, err := h.sessionStore.GetSessionByUser(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		h.logger.Warn("Failed to check session owner", "error", err.Error(), "session_id", sessionID)
+		return false
+	}
+	return session != nil
+}
+
+// analysisInputHash identifies everything that determines a mode's output.
+// extra carries mode-specific inputs such as the scan query.
+func analysisInputHash(mode string, req *CodeRequest, extra ...string) string {
+	parts := append([]string{mode, req.PastedCode, req.Model, req.UserMode, req.OutputMode, strconv.FormatBool(req.Diff), req.Language}, extra...)
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadStoredResult decodes the stored result for this session and input into
+// out. It reports false when no store or session is set, nothing matches, or
+// the stored output cannot be decoded; the caller then runs the analysis.
+func (h *UIHandler) loadStoredResult(ctx context.Context, req *CodeRequest, mode, inputHash string, out interface{}) bool {
+	if h.analysisStore == nil || req.SessionID <= 0 {
+		return false
+	}
+	stored, err := h.analysisStore.FindLatest(ctx, int64(req.SessionID), mode, inputHash)
+	if err != nil {
+		h.logger.Warn("Failed to look up stored analysis", "error", err.Error(), "session_id", int64(req.SessionID), "mode", mode)
+		return false
+	}
+	if stored == nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(stored.Output), out); err != nil {
+		h.logger.Warn("Ignoring unreadable stored analysis", "error", err.Error(), "session_id", int64(req.SessionID), "mode", mode)
+		return false
+	}
+	h.logger.Info("Serving stored analysis", "session_id", int64(req.SessionID), "mode", mode)
+	return true
+}
+
+// saveResult stores a mode result for the session. Errors are only logged:
+// the analysis already succeeded and is returned to the user either way.
+func (h *UIHandler) saveResult(ctx context.Context, req *CodeRequest, mode, inputHash, query string, result interface{}) {
+	if h.analysisStore == nil || req.SessionID <= 0 {
+		return
+	}
+	output, err := json.Marshal(result)
+	if err != nil {
+		h.logger.Warn("Failed to encode analysis for storage", "error", err.Error(), "mode", mode)
+		return
+	}
+	metadata, err := json.Marshal(storedAnalysisMetadata{
+		Code:       req.PastedCode,
+		UserMode:   req.UserMode,
+		OutputMode: req.OutputMode,
+		Query:      query,
+	})
+	if err != nil {
+		h.logger.Warn("Failed to encode analysis metadata", "error", err.Error(), "mode", mode)
+		return
+	}
+
+	err = h.analysisStore.Create(ctx, &review_models.AnalysisResult{
+		ReviewID:  int64(req.SessionID),
+		Mode:      mode,
+		ModelUsed: req.Model,
+		InputHash: inputHash,
+		Output:    string(output),
+		Metadata:  string(metadata),
+	})
+	if err != nil {
+		h.logger.Warn("Failed to store analysis", "error", err.Error(), "session_id", int64(req.SessionID), "mode", mode)
+	}
+}
+
+// renderStoredResult renders a stored mode output as the HTML the mode
+// endpoint would have returned.
+func (h *UIHandler) renderStoredResult(result review_models.AnalysisResult) (string, bool) {
+	var b strings.Builder
+	var err error
+	switch result.Mode {
+	case review_models.PreviewMode:
+		var out review_models.PreviewModeOutput
+		if err = json.Unmarshal([]byte(result.Output), &out); err == nil {
+			h.renderPreviewHTML(&b, &out)
+		}
+	case review_models.SkimMode:
+		var out review_models.SkimModeOutput
+		if err = json.Unmarshal([]byte(result.Output), &out); err == nil {
+			h.renderSkimHTML(&b, &out)
+		}
+	case review_models.ScanMode:
+		var out review_models.ScanModeOutput
+		if err = json.Unmarshal([]byte(result.Output), &out); err == nil {
+			h.renderScanHTML(&b, &out)
+		}
+	case review_models.DetailedMode:
+		var out review_models.DetailedModeOutput
+		if err = json.Unmarshal([]byte(result.Output), &out); err == nil {
+			h.renderDetailedHTML(&b, &out)
+		}
+	case review_models.CriticalMode:
+		var out review_models.CriticalModeOutput
+		if err = json.Unmarshal([]byte(result.Output), &out); err == nil {
+			h.renderCriticalHTML(&b, &out)
+		}
+	default:
+		return "", false
+	}
+	if err != nil {
+		h.logger.Warn("Ignoring unreadable stored analysis", "error", err.Error(), "session_id", result.ReviewID, "mode", result.Mode)
+		return "", false
+	}
+	return b.String(), true
+}
+
+// loadWorkspaceResults fills props with the session's stored results: the
+// rendered HTML per mode, plus the code and mode of the most recent run.
+// props is left as is when nothing has been stored.
+func (h *UIHandler) loadWorkspaceResults(ctx context.Context, sessionID int64, props *templates.WorkspaceProps) {
+	if h.analysisStore == nil || sessionID <= 0 {
+		return
+	}
+	results, err := h.analysisStore.ListLatestByReview(ctx, sessionID)
+	if err != nil {
+		h.logger.Warn("Failed to load stored analyses", "error", err.Error(), "session_id", sessionID)
+		return
+	}
+
+	restored := false
+	props.StoredResults = make(map[string]string, len(results))
+	for _, result := range results {
+		html, ok := h.renderStoredResult(result)
+		if !ok {
+			continue
+		}
+		props.StoredResults[result.Mode] = html
+		if !restored {
+			// Results are newest first; the latest run decides what is shown
+			var meta storedAnalysisMetadata
+			if json.Unmarshal([]byte(result.Metadata), &meta) == nil && meta.Code != "" {
+				props.Code = meta.Code
+				props.CurrentMode = result.Mode
+				restored = true
+			}
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package review_handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	review_cache "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/cache"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/testutils"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAnalysisStore keeps stored results in insertion order.
+type memoryAnalysisStore struct {
+	results []review_models.AnalysisResult
+}
+
+func (m *memoryAnalysisStore) FindLatest(_ context.Context, reviewID int64, mode, inputHash string) (*review_models.AnalysisResult, error) {
+	for i := len(m.results) - 1; i >= 0; i-- {
+		r := m.results[i]
+		if r.ReviewID == reviewID && r.Mode == mode && r.InputHash == inputHash {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryAnalysisStore) ListLatestByReview(_ context.Context, reviewID int64) ([]review_models.AnalysisResult, error) {
+	seen := map[string]bool{}
+	var latest []review_models.AnalysisResult
+	for i := len(m.results) - 1; i >= 0; i-- {
+		r := m.results[i]
+		if r.ReviewID == reviewID && !seen[r.Mode] {
+			seen[r.Mode] = true
+			latest = append(latest, r)
+		}
+	}
+	return latest, nil
+}
+
+func (m *memoryAnalysisStore) Create(_ context.Context, result *review_models.AnalysisResult) error {
+	stored := *result
+	stored.CreatedAt = time.Now()
+	m.results = append(m.results, stored)
+	return nil
+}
+
+// countingOllama returns a fixed response and counts calls.
+type countingOllama struct {
+	resp  string
+	calls int
+}
+
+func (m *countingOllama) Generate(_ context.Context, _ string) (string, error) {
+	m.calls++
+	return m.resp, nil
+}
+
+func setupStoredPreview(t *testing.T) (*gin.Engine, *countingOllama, *memoryAnalysisStore) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	ollama := &countingOllama{resp: `{"summary":"HTTP handlers for users","bounded_contexts":["users"],"tech_stack":["Go"],"file_tree":[]}`}
+	handler := createTestHandler(t)
+	handler.previewService = review_services.NewPreviewService(ollama, &testutils.MockLogger{})
+	store := &memoryAnalysisStore{}
+	handler.SetAnalysisStore(store)
+	// User 1 owns sessions 7 and 8; session 9 belongs to someone else
+	handler.SetSessionStore(&memorySessionStore{
+		sessions: []*review_models.SessionSummary{{ID: 7}, {ID: 8}},
+		userID:   1,
+	})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", 1) })
+	router.POST("/api/review/modes/preview", handler.HandlePreviewMode)
+	router.GET("/review/workspace/:session_id", handler.ShowWorkspace)
+	return router, ollama, store
+}
+
+func postPreview(router *gin.Engine, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/review/modes/preview", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandlePreviewMode_ReusesStoredResult(t *testing.T) {
+	router, ollama, store := setupStoredPreview(t)
+	form := url.Values{"pasted_code": {"package main\nfunc main() {}"}, "session_id": {"7"}}
+
+	first := postPreview(router, form)
+	require.Equal(t, http.StatusOK, first.Code)
+	second := postPreview(router, form)
+	require.Equal(t, http.StatusOK, second.Code)
+
+	assert.Equal(t, 1, ollama.calls, "second run with the same code is served from storage")
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	require.Len(t, store.results, 1)
+	assert.Equal(t, review_models.PreviewMode, store.results[0].Mode)
+
+	// Different code misses the stored result
+	form.Set("pasted_code", "package main\nfunc other() {}")
+	postPreview(router, form)
+	assert.Equal(t, 2, ollama.calls)
+}
+
+func TestHandlePreviewMode_NoSessionIsNotStored(t *testing.T) {
+	router, ollama, store := setupStoredPreview(t)
+	form := url.Values{"pasted_code": {"package main\nfunc main() {}"}}
+
+	postPreview(router, form)
+	postPreview(router, form)
+
+	assert.Equal(t, 2, ollama.calls)
+	assert.Empty(t, store.results)
+}
+
+func TestHandlePreviewMode_ForeignSessionIsNotStored(t *testing.T) {
+	router, ollama, store := setupStoredPreview(t)
+	form := url.Values{"pasted_code": {"package main\nfunc main() {}"}, "session_id": {"9"}}
+
+	require.Equal(t, http.StatusOK, postPreview(router, form).Code)
+	postPreview(router, form)
+
+	assert.Equal(t, 2, ollama.calls)
+	assert.Empty(t, store.results, "results aren't written into another user's session")
+}
+
+func TestHandlePreviewMode_NonNumericSessionIDIsIgnored(t *testing.T) {
+	router, ollama, store := setupStoredPreview(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/review/modes/preview",
+		strings.NewReader(`{"pasted_code":"package main\nfunc main() {}","session_id":"test123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, ollama.calls)
+	assert.Empty(t, store.results)
+}
+
+// fixedModel resolves every analysis to the same provider and model
+type fixedModel struct{}
+
+func (fixedModel) ResolveModel(context.Context) (provider, model string, err error) {
+	return "ollama", "mistral:7b", nil
+}
+
+func TestHandlePreviewMode_ResponseCacheHit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ollama := &countingOllama{resp: `{"summary":"HTTP handlers for users","bounded_contexts":["users"],"tech_stack":["Go"],"file_tree":[]}`}
+	handler := createTestHandler(t)
+	responseCache := review_cache.NewResponseCache(client, time.Hour, fixedModel{}, &testutils.MockLogger{})
+	handler.previewService = responseCache.Preview(review_services.NewPreviewService(ollama, &testutils.MockLogger{}))
+	router := gin.New()
+	router.POST("/api/review/modes/preview", handler.HandlePreviewMode)
+
+	// No session, so only the response cache can spare the second AI call
+	form := url.Values{"pasted_code": {"package main\nfunc main() {}"}}
+	first := postPreview(router, form)
+	require.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "miss", first.Header().Get(CacheStatusHeader))
+
+	second := postPreview(router, form)
+	require.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "hit", second.Header().Get(CacheStatusHeader))
+	assert.Equal(t, 1, ollama.calls)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+}
+
+func TestHandlePreviewMode_NoCacheStatusWithoutCache(t *testing.T) {
+	router, _, _ := setupStoredPreview(t)
+	w := postPreview(router, url.Values{"pasted_code": {"package main\nfunc main() {}"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(CacheStatusHeader))
+}
+
+func TestShowWorkspace_RestoresStoredResult(t *testing.T) {
+	router, _, _ := setupStoredPreview(t)
+	postPreview(router, url.Values{"pasted_code": {"package users\nfunc GetUser() {}"}, "session_id": {"7"}})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/review/workspace/7", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "package users")
+	assert.NotContains(t, body, "GetUser retrieves a user by ID", "sample code is replaced")
+	assert.Contains(t, body, "HTTP handlers for users")
+	assert.Contains(t, body, `id="stored-result-preview"`)
+
+	// A session with nothing stored still shows the sample
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/review/workspace/8", http.NoBody))
+	assert.Contains(t, w.Body.String(), "GetUser retrieves a user by ID")
+}
+
+func TestShowWorkspace_HidesForeignSession(t *testing.T) {
+	router, _, store := setupStoredPreview(t)
+	store.results = append(store.results, review_models.AnalysisResult{
+		ReviewID: 9,
+		Mode:     review_models.PreviewMode,
+		Output:   `{"summary":"someone else's code"}`,
+		Metadata: `{"code":"package secret"}`,
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/review/workspace/9", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "package secret")
+	assert.NotContains(t, w.Body.String(), "someone else's code")
+}
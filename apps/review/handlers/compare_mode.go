@@ -0,0 +1,161 @@
+package review_handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	templates "github.com/mikejsmith1985/devsmith-modular-platform/apps/review/templates"
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+)
+
+// DefaultCompareTimeout bounds both model runs of a comparison together.
+const DefaultCompareTimeout = 3 * time.Minute
+
+// CompareRequest is the input for POST /api/review/modes/compare: the usual
+// code request plus the two models to compare and the mode to run.
+type CompareRequest struct {
+	CodeRequest
+	ModelA   string `form:"model_a" json:"model_a" binding:"required"`
+	ModelB   string `form:"model_b" json:"model_b" binding:"required"`
+	Mode     string `form:"mode" json:"mode"` // defaults to critical
+	Query    string `form:"query" json:"query"`
+	Filename string `form:"filename" json:"filename"`
+}
+
+// ModelComparison is one model's side of a comparison. Result is nil and
+// Error set when the model failed.
+type ModelComparison struct {
+	Model      string      `json:"model"`
+	Result     interface{} `json:"result,omitempty"`
+	IssueCount int         `json:"issue_count"`
+	Grade      string      `json:"grade,omitempty"`
+	LatencyMs  int64       `json:"latency_ms"`
+	Error      string      `json:"error,omitempty"`
+	ErrorCode  string      `json:"error_code,omitempty"`
+}
+
+// CompareResponse holds both sides of a comparison in request order.
+type CompareResponse struct {
+	Mode    string            `json:"mode"`
+	Results []ModelComparison `json:"results"`
+}
+
+// HandleCompareMode handles POST /api/review/modes/compare.
+// It runs one reading mode (critical by default) against the same code with
+// two models concurrently and returns the results side by side, with issue
+// counts, grades and latency for each. Both runs share one timeout. If one
+// model fails its error is reported next to the other model's result; only
+// when both fail does the request fail. The comparison takes two of the
+// caller's concurrent analysis slots, one per model.
+func (h *UIHandler) HandleCompareMode(c *gin.Context) {
+	var req CompareRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.logger.Warn("Failed to bind compare request", "error", err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pasted_code, model_a and model_b are required"})
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = review_models.CriticalMode
+	}
+	if !isReviewMode(req.Mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown review mode: " + req.Mode})
+		return
+	}
+	if !h.modeConfigured(req.Mode) {
+		h.logger.Warn("Mode service not initialized", "mode", req.Mode)
+		c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusServiceUnavailable)
+		templates.AIServiceUnavailable().Render(c.Request.Context(), c.Writer)
+		return
+	}
+	if req.Mode != review_models.PreviewMode && !looksLikeCode(req.PastedCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errNotCode.Error()})
+		return
+	}
+
+	if req.UserMode == "" {
+		req.UserMode = "intermediate"
+	}
+	if req.OutputMode == "" {
+		req.OutputMode = "quick"
+	}
+	if req.Query == "" {
+		req.Query = "find issues and improvements"
+	}
+	if req.Filename == "" {
+		req.Filename = "main.go"
+	}
+	if !req.Diff {
+		req.Diff = review_services.IsUnifiedDiff(req.PastedCode)
+	}
+	req.setLanguage(req.Filename)
+
+	models := []string{req.ModelA, req.ModelB}
+
+	// Each model call counts against the caller's concurrent analyses
+	release, ok := h.acquireAnalysisSlots(c, len(models))
+	if !ok {
+		return
+	}
+	defer release()
+
+	timeout := h.compareTimeout
+	if timeout <= 0 {
+		timeout = DefaultCompareTimeout
+	}
+	ctx, cancel := context.WithTimeout(analysisContext(c, &req.CodeRequest), timeout)
+	defer cancel()
+
+	results := make([]ModelComparison, len(models))
+	var wg sync.WaitGroup
+	for i, model := range models {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.runComparison(ctx, &req, model)
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if results[0].Error != "" && results[1].Error != "" {
+		status = http.StatusBadGateway
+	}
+	h.logger.Info("Model comparison completed",
+		"mode", req.Mode,
+		"model_a", req.ModelA, "latency_a_ms", results[0].LatencyMs, "error_a", results[0].Error,
+		"model_b", req.ModelB, "latency_b_ms", results[1].LatencyMs, "error_b", results[1].Error)
+	c.JSON(status, CompareResponse{Mode: req.Mode, Results: results})
+}
+
+// runComparison runs the requested mode with model and summarizes the outcome.
+func (h *UIHandler) runComparison(ctx context.Context, req *CompareRequest, model string) ModelComparison {
+	modelReq := req.CodeRequest
+	modelReq.Model = model
+	ctx = context.WithValue(ctx, reviewcontext.ModelContextKey, model)
+
+	start := time.Now()
+	result, err := h.analyzeMode(ctx, req.Mode, &modelReq, req.Query, req.Filename)
+	comparison := ModelComparison{Model: model, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		h.logger.Warn("Model comparison run failed", "mode", req.Mode, "model", model, "error", err.Error())
+		comparison.Error = err.Error()
+		comparison.ErrorCode = streamErrorCode(err)
+		return comparison
+	}
+
+	comparison.Result = result
+	switch out := result.(type) {
+	case *review_models.CriticalModeOutput:
+		comparison.IssueCount = len(out.Issues)
+		comparison.Grade = out.OverallGrade
+	case *review_models.ScanModeOutput:
+		comparison.IssueCount = len(out.Matches)
+	}
+	return comparison
+}
@@ -0,0 +1,172 @@
+package review_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// perModelOllama answers with the response configured for the model in the
+// request context. Models listed in slow block until the context ends.
+type perModelOllama struct {
+	responses map[string]string
+	errs      map[string]error
+	slow      map[string]bool
+}
+
+func (m *perModelOllama) Generate(ctx context.Context, _ string) (string, error) {
+	model, _ := ctx.Value(reviewcontext.ModelContextKey).(string)
+	if m.slow[model] {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	if err := m.errs[model]; err != nil {
+		return "", err
+	}
+	return m.responses[model], nil
+}
+
+func setupCompare(t *testing.T, client review_services.OllamaClientInterface) (*UIHandler, *gin.Engine) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	handler := createTestHandler(t)
+	handler.criticalService = review_services.NewCriticalService(client, nil, handler.logger)
+
+	router := gin.New()
+	router.POST("/api/review/modes/compare", handler.HandleCompareMode)
+	router.POST("/api/review/modes/:mode/stream", handler.HandleModeStream)
+	return handler, router
+}
+
+func postCompare(router *gin.Engine, body string) (*httptest.ResponseRecorder, CompareResponse) {
+	req := httptest.NewRequest(http.MethodPost, "/api/review/modes/compare", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp CompareResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+const compareBody = `{"pasted_code":"package main\nfunc main() {}","model_a":"mistral:7b-instruct","model_b":"qwen2.5-coder:7b"}`
+
+func TestHandleCompareMode_ReturnsBothModels(t *testing.T) {
+	_, router := setupCompare(t, &perModelOllama{responses: map[string]string{
+		"mistral:7b-instruct": `{"overall_grade":"A","summary":"Looks fine","issues":[]}`,
+		"qwen2.5-coder:7b": `{"overall_grade":"C","summary":"Unchecked error","issues":[
+			{"severity":"high","category":"error_handling","line":2,"description":"Error ignored"},
+			{"severity":"low","category":"style","line":1,"description":"Missing doc comment"}]}`,
+	}})
+
+	w, resp := postCompare(router, compareBody)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "critical", resp.Mode)
+	require.Len(t, resp.Results, 2)
+
+	a, b := resp.Results[0], resp.Results[1]
+	assert.Equal(t, "mistral:7b-instruct", a.Model)
+	assert.Equal(t, 0, a.IssueCount)
+	assert.Empty(t, a.Error)
+	assert.Contains(t, w.Body.String(), "Looks fine")
+
+	assert.Equal(t, "qwen2.5-coder:7b", b.Model)
+	assert.Equal(t, 2, b.IssueCount)
+	assert.NotEmpty(t, b.Grade)
+	assert.NotEqual(t, a.Grade, b.Grade)
+	assert.Contains(t, w.Body.String(), "Error ignored")
+	assert.GreaterOrEqual(t, b.LatencyMs, int64(0))
+}
+
+func TestHandleCompareMode_OneModelFails(t *testing.T) {
+	_, router := setupCompare(t, &perModelOllama{
+		responses: map[string]string{"mistral:7b-instruct": `{"overall_grade":"B","summary":"One issue","issues":[]}`},
+		errs:      map[string]error{"qwen2.5-coder:7b": errors.New("connection refused")},
+	})
+
+	w, resp := postCompare(router, compareBody)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, resp.Results, 2)
+	assert.Empty(t, resp.Results[0].Error)
+	assert.NotNil(t, resp.Results[0].Result)
+	assert.NotEmpty(t, resp.Results[1].Error)
+	assert.Nil(t, resp.Results[1].Result)
+}
+
+func TestHandleCompareMode_TakesASlotPerModel(t *testing.T) {
+	handler, router := setupCompare(t, &perModelOllama{responses: map[string]string{
+		"mistral:7b-instruct": `{"overall_grade":"A","summary":"Looks fine","issues":[]}`,
+		"qwen2.5-coder:7b":    `{"overall_grade":"B","summary":"Looks fine","issues":[]}`,
+	}})
+
+	// With one of the caller's two slots busy, both model calls don't fit
+	release, _, ok := handler.analysisSlots.acquire("ip:192.0.2.1", 1)
+	require.True(t, ok)
+	w, _ := postCompare(router, compareBody)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	release()
+	w, _ = postCompare(router, compareBody)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	_, _, ok = handler.analysisSlots.acquire("ip:192.0.2.1", DefaultMaxConcurrentAnalyses)
+	assert.True(t, ok, "both slots are released after the comparison")
+}
+
+func TestHandleCompareMode_SharedTimeout(t *testing.T) {
+	handler, router := setupCompare(t, &perModelOllama{
+		responses: map[string]string{"mistral:7b-instruct": `{"overall_grade":"A","summary":"Fast","issues":[]}`},
+		slow:      map[string]bool{"qwen2.5-coder:7b": true},
+	})
+	handler.compareTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	w, resp := postCompare(router, compareBody)
+
+	assert.Less(t, time.Since(start), 2*time.Second)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, resp.Results, 2)
+	assert.Empty(t, resp.Results[0].Error)
+	assert.NotEmpty(t, resp.Results[1].Error)
+}
+
+func TestHandleCompareMode_BothModelsFail(t *testing.T) {
+	_, router := setupCompare(t, &perModelOllama{errs: map[string]error{
+		"mistral:7b-instruct": errors.New("connection refused"),
+		"qwen2.5-coder:7b":    errors.New("connection refused"),
+	}})
+
+	w, resp := postCompare(router, compareBody)
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	require.Len(t, resp.Results, 2)
+	assert.NotEmpty(t, resp.Results[0].Error)
+	assert.NotEmpty(t, resp.Results[1].Error)
+}
+
+func TestHandleCompareMode_Validation(t *testing.T) {
+	_, router := setupCompare(t, &perModelOllama{})
+
+	w, _ := postCompare(router, `{"pasted_code":"package main","model_a":"mistral:7b-instruct"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "both models are required")
+
+	w, _ = postCompare(router, `{"pasted_code":"package main","model_a":"a","model_b":"b","mode":"bogus"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = postCompare(router, `{"pasted_code":"package main","model_a":"a","model_b":"b","mode":"skim"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,26 @@
+package review_handlers
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// injectionWarningHTML renders a result's prompt injection warning as a
+// banner above the findings, or "" when there is none.
+func injectionWarningHTML(warning *review_models.InjectionWarning) string {
+	if warning == nil {
+		return ""
+	}
+	var directives strings.Builder
+	for _, directive := range warning.Directives {
+		fmt.Fprintf(&directives, `<li><code>%s</code></li>`, html.EscapeString(directive))
+	}
+	return fmt.Sprintf(`<div class="p-4 rounded-lg bg-amber-50 dark:bg-amber-900 border border-amber-300 dark:border-amber-700" role="alert" data-warning="prompt-injection">
+			<p class="font-semibold text-amber-900 dark:text-amber-50">⚠️ Possible prompt injection</p>
+			<p class="text-sm text-amber-800 dark:text-amber-100">%s</p>
+			<ul class="mt-2 text-sm list-disc list-inside text-amber-800 dark:text-amber-100">%s</ul>
+		</div>`, html.EscapeString(warning.Message), directives.String())
+}
@@ -0,0 +1,30 @@
+package review_handlers
+
+import (
+	"strings"
+	"testing"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderCriticalHTML_ShowsInjectionWarning(t *testing.T) {
+	handler := createTestHandler(t)
+
+	var b strings.Builder
+	handler.renderCriticalHTML(&b, &review_models.CriticalModeOutput{
+		OverallGrade: "F",
+		InjectionWarning: &review_models.InjectionWarning{
+			Message:    "The input contains text that addresses the AI reviewer.",
+			Directives: []string{"<|im_start|>", "Ignore all previous instructions"},
+		},
+	})
+	page := b.String()
+	assert.Contains(t, page, `data-warning="prompt-injection"`)
+	assert.Contains(t, page, "<code>&lt;|im_start|&gt;</code>")
+	assert.Contains(t, page, "<code>Ignore all previous instructions</code>")
+
+	b.Reset()
+	handler.renderCriticalHTML(&b, &review_models.CriticalModeOutput{OverallGrade: "A"})
+	assert.NotContains(t, b.String(), "prompt-injection")
+}
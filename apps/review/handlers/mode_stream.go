@@ -0,0 +1,236 @@
+package review_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	templates "github.com/mikejsmith1985/devsmith-modular-platform/apps/review/templates"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_errors "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/errors"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+)
+
+// Stream error codes sent in the "error" event, mirroring renderError.
+const (
+	StreamErrCircuitOpen    = "circuit_open"
+	StreamErrTimeout        = "timeout"
+	StreamErrUnavailable    = "ai_unavailable"
+	StreamErrNotCode        = "not_code"
+	StreamErrBudgetExceeded = "budget_exceeded"
+	StreamErrInputTooLarge  = "input_too_large"
+	StreamErrAnalysisFailed = "analysis_failed"
+)
+
+// errNotCode is returned for modes that need source code when given prose.
+var errNotCode = errors.New("the pasted content looks like natural language text, not source code")
+
+// HandleModeStream handles POST /api/review/modes/:mode/stream (SSE).
+// It accepts the same input as the per-mode endpoints and streams model
+// output as it is generated:
+//
+//	data: {"text":"<chunk>"}        one frame per chunk, in order
+//	event: done                     the complete structured result
+//	event: error                    {"error": "...", "code": "..."}; also sent
+//	                                when the circuit breaker trips mid-stream
+func (h *UIHandler) HandleModeStream(c *gin.Context) {
+	mode := c.Param("mode")
+	if !h.modeConfigured(mode) {
+		if !isReviewMode(mode) {
+			c.String(http.StatusNotFound, "Unknown review mode: %s", mode)
+			return
+		}
+		h.logger.Warn("Mode service not initialized", "mode", mode)
+		c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusServiceUnavailable)
+		templates.AIServiceUnavailable().Render(c.Request.Context(), c.Writer)
+		return
+	}
+
+	req, ok := h.bindCodeRequest(c)
+	if !ok {
+		return
+	}
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		h.logger.Error("SSE unsupported by writer")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	query := c.DefaultQuery("query", "find issues and improvements")
+	filename := c.DefaultQuery("filename", "main.go")
+	var inputHash, storedQuery string
+	switch mode {
+	case review_models.ScanMode:
+		inputHash, storedQuery = analysisInputHash(mode, req, query), query
+	case review_models.DetailedMode:
+		inputHash = analysisInputHash(mode, req, filename)
+	default:
+		inputHash = analysisInputHash(mode, req)
+	}
+
+	ctx := analysisContext(c, req)
+	if stored := newModeOutput(mode); h.loadStoredResult(ctx, req, mode, inputHash, stored) {
+		h.writeStreamEvent(c, flusher, "done", stored)
+		return
+	}
+
+	release, ok := h.acquireAnalysisSlot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
+	// Chunks are written from the goroutine running the analysis, which is
+	// this handler's, so no locking is needed around the writer
+	ctx = reviewcontext.WithChunkFunc(ctx, func(chunk string) error {
+		if err := c.Request.Context().Err(); err != nil {
+			return err
+		}
+		return h.writeStreamEvent(c, flusher, "", gin.H{"text": chunk})
+	})
+
+	result, err := h.analyzeMode(ctx, mode, req, query, filename)
+	if err != nil {
+		if c.Request.Context().Err() != nil {
+			h.logger.Info("Stream client disconnected", "mode", mode)
+			return
+		}
+		h.logger.Error("Streamed analysis failed", "error", err.Error(), "mode", mode, "model", req.Model)
+		h.writeStreamEvent(c, flusher, "error", gin.H{"error": err.Error(), "code": streamErrorCode(err)})
+		return
+	}
+
+	h.saveResult(ctx, req, mode, inputHash, storedQuery, result)
+	h.writeStreamEvent(c, flusher, "done", result)
+}
+
+// isReviewMode reports whether mode names one of the five reading modes.
+func isReviewMode(mode string) bool {
+	switch mode {
+	case review_models.PreviewMode, review_models.SkimMode, review_models.ScanMode,
+		review_models.DetailedMode, review_models.CriticalMode:
+		return true
+	}
+	return false
+}
+
+// modeConfigured reports whether the service for mode is available.
+func (h *UIHandler) modeConfigured(mode string) bool {
+	switch mode {
+	case review_models.PreviewMode:
+		return h.previewService != nil
+	case review_models.SkimMode:
+		return h.skimService != nil
+	case review_models.ScanMode:
+		return h.scanService != nil
+	case review_models.DetailedMode:
+		return h.detailedService != nil
+	case review_models.CriticalMode:
+		return h.criticalService != nil
+	}
+	return false
+}
+
+// newModeOutput returns an empty output value of mode's result type.
+func newModeOutput(mode string) interface{} {
+	switch mode {
+	case review_models.PreviewMode:
+		return &review_models.PreviewModeOutput{}
+	case review_models.SkimMode:
+		return &review_models.SkimModeOutput{}
+	case review_models.ScanMode:
+		return &review_models.ScanModeOutput{}
+	case review_models.DetailedMode:
+		return &review_models.DetailedModeOutput{}
+	default:
+		return &review_models.CriticalModeOutput{}
+	}
+}
+
+// analyzeMode runs the analysis for mode. Only Preview accepts input that
+// does not look like code; the other modes would hallucinate structure.
+func (h *UIHandler) analyzeMode(ctx context.Context, mode string, req *CodeRequest, query, filename string) (interface{}, error) {
+	if mode != review_models.PreviewMode && !looksLikeCode(req.PastedCode) {
+		return nil, errNotCode
+	}
+
+	switch mode {
+	case review_models.PreviewMode:
+		return h.previewService.AnalyzePreview(ctx, req.PastedCode, req.UserMode, req.OutputMode)
+	case review_models.SkimMode:
+		return h.skimService.AnalyzeSkim(ctx, req.PastedCode, req.UserMode, req.OutputMode)
+	case review_models.ScanMode:
+		return h.scanService.AnalyzeScan(ctx, query, req.PastedCode, req.UserMode, req.OutputMode)
+	case review_models.DetailedMode:
+		return h.detailedService.AnalyzeDetailed(ctx, req.PastedCode, filename, req.UserMode, req.OutputMode)
+	default:
+		analyze := h.criticalService.AnalyzeCritical
+		if req.Diff {
+			analyze = h.criticalService.AnalyzeCriticalDiff
+		}
+		result, err := analyze(ctx, req.PastedCode)
+		if err != nil {
+			return nil, err
+		}
+		h.normalizeCriticalGrade(result)
+		return result, nil
+	}
+}
+
+// writeStreamEvent writes one SSE frame with data encoded as JSON and
+// flushes it. An empty event name writes a plain data frame.
+func (h *UIHandler) writeStreamEvent(c *gin.Context, flusher http.Flusher, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode SSE event: %w", err)
+	}
+
+	var frame strings.Builder
+	if event != "" {
+		fmt.Fprintf(&frame, "event: %s\n", event)
+	}
+	fmt.Fprintf(&frame, "data: %s\n\n", payload)
+	if _, err := c.Writer.WriteString(frame.String()); err != nil {
+		h.logger.Error("failed to write SSE event", "error", err)
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+// streamErrorCode classifies an analysis error the same way renderError does.
+func streamErrorCode(err error) string {
+	errMsg := err.Error()
+	var businessErr *review_errors.BusinessError
+	switch {
+	case errors.Is(err, errNotCode):
+		return StreamErrNotCode
+	case errors.Is(err, ai.ErrBudgetExceeded):
+		return StreamErrBudgetExceeded
+	case errors.As(err, &businessErr) && businessErr.Code == review_services.ErrCodeInputTooLarge:
+		return StreamErrInputTooLarge
+	case strings.Contains(errMsg, "circuit breaker is open") || strings.Contains(errMsg, "ErrOpenState") ||
+		strings.Contains(errMsg, "too many requests"):
+		return StreamErrCircuitOpen
+	case strings.Contains(errMsg, "context deadline exceeded") || strings.Contains(errMsg, "timeout"):
+		return StreamErrTimeout
+	case strings.Contains(errMsg, "connection refused") || strings.Contains(errMsg, "no such host") ||
+		(strings.Contains(errMsg, "ollama") && strings.Contains(errMsg, "unavailable")):
+		return StreamErrUnavailable
+	default:
+		return StreamErrAnalysisFailed
+	}
+}
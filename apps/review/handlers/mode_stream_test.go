@@ -0,0 +1,192 @@
+package review_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/review/circuit"
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamingOllama delivers its response through the context's ChunkFunc in
+// the given pieces, then fails with err if set.
+type streamingOllama struct {
+	err    error
+	chunks []string
+}
+
+func (m *streamingOllama) Generate(ctx context.Context, _ string) (string, error) {
+	onChunk := reviewcontext.ChunkFuncFrom(ctx)
+	for _, chunk := range m.chunks {
+		if onChunk != nil {
+			if err := onChunk(chunk); err != nil {
+				return "", err
+			}
+		}
+	}
+	if m.err != nil {
+		return "", m.err
+	}
+	return strings.Join(m.chunks, ""), nil
+}
+
+type sseFrame struct {
+	event string
+	data  string
+}
+
+func parseSSE(t *testing.T, body string) []sseFrame {
+	t.Helper()
+	var frames []sseFrame
+	for _, raw := range strings.Split(strings.TrimSpace(body), "\n\n") {
+		var f sseFrame
+		for _, line := range strings.Split(raw, "\n") {
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				f.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				f.data = strings.TrimPrefix(line, "data: ")
+			default:
+				t.Fatalf("unexpected SSE line %q", line)
+			}
+		}
+		frames = append(frames, f)
+	}
+	return frames
+}
+
+func setupModeStream(t *testing.T, client review_services.OllamaClientInterface) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	handler := createTestHandler(t)
+	handler.criticalService = review_services.NewCriticalService(client, nil, handler.logger)
+
+	router := gin.New()
+	router.POST("/api/review/modes/critical", handler.HandleCriticalMode)
+	router.POST("/api/review/modes/:mode/stream", handler.HandleModeStream)
+	return router
+}
+
+func postStream(router *gin.Engine, mode string) *httptest.ResponseRecorder {
+	form := url.Values{"pasted_code": {"package main\nfunc main() { db.Query(input) }"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/review/modes/"+mode+"/stream", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandleModeStream_DataFramesThenDone(t *testing.T) {
+	client := &streamingOllama{chunks: []string{
+		`{"overall_grade":"C","summary":"Unsafe query",`,
+		`"issues":[{"severity":"high","category":"security","file":"main.go","line":2,`,
+		`"description":"query built from input","impact":"SQL injection","fix_suggestion":"use placeholders"}]}`,
+	}}
+	w := postStream(setupModeStream(t, client), review_models.CriticalMode)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	frames := parseSSE(t, w.Body.String())
+	require.Len(t, frames, 4)
+	for i, chunk := range client.chunks {
+		assert.Empty(t, frames[i].event)
+		var data struct {
+			Text string `json:"text"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(frames[i].data), &data))
+		assert.Equal(t, chunk, data.Text)
+	}
+
+	done := frames[3]
+	assert.Equal(t, "done", done.event)
+	var result review_models.CriticalModeOutput
+	require.NoError(t, json.Unmarshal([]byte(done.data), &result))
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, "security", result.Issues[0].Category)
+	assert.Equal(t, review_services.DefaultGradePolicy().GradeFromIssues(result.Issues), result.OverallGrade, "grade is normalized as in the HTML endpoint")
+}
+
+func TestHandleModeStream_CircuitBreakerTripSendsError(t *testing.T) {
+	breakerLogger, err := logger.NewLogger(&logger.Config{ServiceName: "review-test", LogLevel: "error"})
+	require.NoError(t, err)
+	client := &streamingOllama{chunks: []string{`{"overall_grade":`}, err: errors.New("connection reset by peer")}
+	router := setupModeStream(t, circuit.NewOllamaCircuitBreaker(client, breakerLogger, config.DefaultCircuitBreaker))
+
+	// A failure mid-stream ends the stream with an error event after the partial output
+	frames := parseSSE(t, postStream(router, review_models.CriticalMode).Body.String())
+	require.Len(t, frames, 2)
+	assert.Empty(t, frames[0].event)
+	assert.Equal(t, "error", frames[1].event)
+	assert.Contains(t, frames[1].data, `"code":"analysis_failed"`)
+
+	// Keep failing until the breaker opens; then requests fail fast
+	for i := 0; i < 4; i++ {
+		postStream(router, review_models.CriticalMode)
+	}
+	frames = parseSSE(t, postStream(router, review_models.CriticalMode).Body.String())
+	require.Len(t, frames, 1, "an open breaker sends no data frames")
+	assert.Equal(t, "error", frames[0].event)
+	assert.Contains(t, frames[0].data, `"code":"circuit_open"`)
+}
+
+func TestHandleModeStream_BudgetExceededDoesNotTripBreaker(t *testing.T) {
+	breakerLogger, err := logger.NewLogger(&logger.Config{ServiceName: "review-test", LogLevel: "error"})
+	require.NoError(t, err)
+	client := &streamingOllama{err: fmt.Errorf("%w: $10.01 spent of $10.00 this month", ai.ErrBudgetExceeded)}
+	breaker := circuit.NewOllamaCircuitBreaker(client, breakerLogger, config.DefaultCircuitBreaker)
+	router := setupModeStream(t, breaker)
+
+	for i := 0; i < 6; i++ {
+		frames := parseSSE(t, postStream(router, review_models.CriticalMode).Body.String())
+		require.Len(t, frames, 1)
+		assert.Equal(t, "error", frames[0].event)
+		assert.Contains(t, frames[0].data, `"code":"budget_exceeded"`)
+	}
+	assert.Equal(t, gobreaker.StateClosed, breaker.State())
+}
+
+func TestHandleModeStream_UnknownAndUnconfiguredModes(t *testing.T) {
+	router := setupModeStream(t, &streamingOllama{})
+
+	assert.Equal(t, http.StatusNotFound, postStream(router, "summarize").Code)
+	assert.Equal(t, http.StatusServiceUnavailable, postStream(router, review_models.SkimMode).Code)
+}
+
+func TestHandleModeStream_UsesConfiguredGradePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createTestHandler(t)
+	handler.criticalService = review_services.NewCriticalService(&streamingOllama{chunks: []string{
+		`{"overall_grade":"B","summary":"One problem","issues":[{"severity":"high","category":"security","description":"d"}]}`,
+	}}, nil, handler.logger)
+	handler.SetGradePolicy(&review_services.GradePolicy{
+		PassGrade: "A",
+		Rules:     []review_services.GradeRule{{Grade: "F", Critical: 1, High: 1}},
+	})
+	router := gin.New()
+	router.POST("/api/review/modes/:mode/stream", handler.HandleModeStream)
+
+	frames := parseSSE(t, postStream(router, review_models.CriticalMode).Body.String())
+	done := frames[len(frames)-1]
+	require.Equal(t, "done", done.event)
+	var result review_models.CriticalModeOutput
+	require.NoError(t, json.Unmarshal([]byte(done.data), &result))
+	assert.Equal(t, "F", result.OverallGrade)
+}
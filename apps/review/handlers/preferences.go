@@ -0,0 +1,161 @@
+package review_handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// Built-in defaults for options neither the request nor the user's
+// preferences set.
+const (
+	defaultModel      = "mistral:7b-instruct"
+	defaultUserMode   = "intermediate"
+	defaultOutputMode = "quick"
+	defaultMode       = "preview"
+)
+
+// maxPreferredModelLength matches the model names Portal stores.
+const maxPreferredModelLength = 100
+
+// PreferenceStore loads and saves a user's review preferences. Calls carry
+// the user's session token, which the store uses to authenticate.
+type PreferenceStore interface {
+	GetPreferences(ctx context.Context, sessionToken string) (*review_models.ReviewPreferences, error)
+	SavePreferences(ctx context.Context, sessionToken string, prefs *review_models.ReviewPreferences) error
+}
+
+// SetPreferenceStore enables per-user review defaults.
+func (h *UIHandler) SetPreferenceStore(store PreferenceStore) {
+	h.preferenceStore = store
+}
+
+// GetPreferencesHandler handles GET /api/review/preferences.
+func (h *UIHandler) GetPreferencesHandler(c *gin.Context) {
+	token, ok := h.preferencesUser(c)
+	if !ok {
+		return
+	}
+	prefs, err := h.preferenceStore.GetPreferences(c.Request.Context(), token)
+	if err != nil {
+		h.logger.Error("Failed to load preferences", "error", err.Error())
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to load preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// PutPreferencesHandler handles PUT /api/review/preferences, replacing the
+// user's preferences. Empty fields clear a preference.
+func (h *UIHandler) PutPreferencesHandler(c *gin.Context) {
+	token, ok := h.preferencesUser(c)
+	if !ok {
+		return
+	}
+	var prefs review_models.ReviewPreferences
+	if err := c.ShouldBindJSON(&prefs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preferences: " + err.Error()})
+		return
+	}
+	if err := validatePreferences(&prefs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.preferenceStore.SavePreferences(c.Request.Context(), token, &prefs); err != nil {
+		h.logger.Error("Failed to save preferences", "error", err.Error())
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to save preferences"})
+		return
+	}
+	h.logger.Info("Preferences saved",
+		"default_mode", prefs.DefaultMode,
+		"user_mode", prefs.UserMode,
+		"output_mode", prefs.OutputMode,
+		"model", prefs.Model)
+	c.JSON(http.StatusOK, prefs)
+}
+
+// validatePreferences trims prefs and checks each set value is one the
+// review endpoints accept.
+func validatePreferences(prefs *review_models.ReviewPreferences) error {
+	prefs.DefaultMode = strings.TrimSpace(prefs.DefaultMode)
+	prefs.UserMode = strings.TrimSpace(prefs.UserMode)
+	prefs.OutputMode = strings.TrimSpace(prefs.OutputMode)
+	prefs.Model = strings.TrimSpace(prefs.Model)
+
+	if _, known := modeIcons[prefs.DefaultMode]; prefs.DefaultMode != "" && !known {
+		return fmt.Errorf("unknown default_mode %q", prefs.DefaultMode)
+	}
+	if prefs.UserMode != "" && !slices.Contains(review_models.UserModes, prefs.UserMode) {
+		return fmt.Errorf("user_mode must be one of: %s", strings.Join(review_models.UserModes, ", "))
+	}
+	if prefs.OutputMode != "" && !slices.Contains(review_models.OutputModes, prefs.OutputMode) {
+		return fmt.Errorf("output_mode must be one of: %s", strings.Join(review_models.OutputModes, ", "))
+	}
+	if len(prefs.Model) > maxPreferredModelLength {
+		return fmt.Errorf("model must be at most %d characters", maxPreferredModelLength)
+	}
+	return nil
+}
+
+// preferencesUser returns the session token for the preferences endpoints,
+// writing an error response and reporting false if preferences are disabled
+// or there is no session.
+func (h *UIHandler) preferencesUser(c *gin.Context) (string, bool) {
+	if h.preferenceStore == nil {
+		h.logger.Warn("Preference store not configured")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Preferences are unavailable"})
+		return "", false
+	}
+	token := c.GetString("session_token")
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return "", false
+	}
+	return token, true
+}
+
+// preferences returns the signed-in user's saved preferences, or nil. A
+// failure to load them is logged and the built-in defaults apply, so a
+// Portal outage never blocks a review.
+func (h *UIHandler) preferences(c *gin.Context) *review_models.ReviewPreferences {
+	token := c.GetString("session_token")
+	if h.preferenceStore == nil || token == "" {
+		return nil
+	}
+	prefs, err := h.preferenceStore.GetPreferences(c.Request.Context(), token)
+	if err != nil {
+		h.logger.Warn("Failed to load preferences, using defaults", "error", err.Error())
+		return nil
+	}
+	return prefs
+}
+
+// applyRequestDefaults fills the options req omits from the user's
+// preferences, then from the built-in defaults.
+func (h *UIHandler) applyRequestDefaults(c *gin.Context, req *CodeRequest) {
+	if req.Model == "" || req.UserMode == "" || req.OutputMode == "" {
+		if prefs := h.preferences(c); prefs != nil {
+			req.Model = firstNonEmpty(req.Model, prefs.Model)
+			req.UserMode = firstNonEmpty(req.UserMode, prefs.UserMode)
+			req.OutputMode = firstNonEmpty(req.OutputMode, prefs.OutputMode)
+		}
+	}
+	req.Model = firstNonEmpty(req.Model, defaultModel)
+	req.UserMode = firstNonEmpty(req.UserMode, defaultUserMode)
+	req.OutputMode = firstNonEmpty(req.OutputMode, defaultOutputMode)
+}
+
+// preferredMode is the reading mode a workspace opens in when neither the
+// request nor the session picks one.
+func (h *UIHandler) preferredMode(c *gin.Context) string {
+	if prefs := h.preferences(c); prefs != nil && prefs.DefaultMode != "" {
+		return prefs.DefaultMode
+	}
+	return defaultMode
+}
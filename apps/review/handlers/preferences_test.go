@@ -0,0 +1,185 @@
+package review_handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryPreferenceStore keeps preferences per session token.
+type memoryPreferenceStore struct {
+	prefs map[string]review_models.ReviewPreferences
+	err   error
+}
+
+func (m *memoryPreferenceStore) GetPreferences(_ context.Context, token string) (*review_models.ReviewPreferences, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	prefs := m.prefs[token]
+	return &prefs, nil
+}
+
+func (m *memoryPreferenceStore) SavePreferences(_ context.Context, token string, prefs *review_models.ReviewPreferences) error {
+	m.prefs[token] = *prefs
+	return nil
+}
+
+// preferencesContext returns a test context for a signed-in user.
+func preferencesContext(method, target, contentType, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	if contentType != "" {
+		c.Request.Header.Set("Content-Type", contentType)
+	}
+	c.Set("session_token", "token-7")
+	return c, w
+}
+
+func savedPreferences() *memoryPreferenceStore {
+	return &memoryPreferenceStore{prefs: map[string]review_models.ReviewPreferences{
+		"token-7": {DefaultMode: "critical", UserMode: "expert", OutputMode: "full", Model: "qwen2.5-coder:7b"},
+	}}
+}
+
+func TestBindCodeRequest_InheritsPreferences(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createTestHandler(t)
+	handler.SetPreferenceStore(savedPreferences())
+
+	tests := []struct {
+		name        string
+		contentType string
+		target      string
+		body        string
+	}{
+		{"JSON", "application/json", "/test", `{"pasted_code": "test"}`},
+		{"form", "application/x-www-form-urlencoded", "/test", url.Values{"pasted_code": {"test"}}.Encode()},
+		{"diff body", "text/x-diff", "/test", "--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-a\n+b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := preferencesContext(http.MethodPost, tt.target, tt.contentType, tt.body)
+			req, ok := handler.bindCodeRequest(c)
+			require.True(t, ok)
+
+			assert.Equal(t, "expert", req.UserMode)
+			assert.Equal(t, "full", req.OutputMode)
+			assert.Equal(t, "qwen2.5-coder:7b", req.Model)
+		})
+	}
+}
+
+func TestBindCodeRequest_RequestOverridesPreferences(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createTestHandler(t)
+	handler.SetPreferenceStore(savedPreferences())
+
+	c, _ := preferencesContext(http.MethodPost, "/test", "application/json",
+		`{"pasted_code": "test", "user_mode": "beginner", "model": "mistral:7b-instruct"}`)
+	req, ok := handler.bindCodeRequest(c)
+	require.True(t, ok)
+	assert.Equal(t, "beginner", req.UserMode)
+	assert.Equal(t, "mistral:7b-instruct", req.Model)
+	assert.Equal(t, "full", req.OutputMode, "omitted fields still inherit")
+
+	c, _ = preferencesContext(http.MethodPost, "/test?user_mode=novice&output_mode=quick", "text/x-diff", "--- a/x.go\n+++ b/x.go\n")
+	req, ok = handler.bindCodeRequest(c)
+	require.True(t, ok)
+	assert.Equal(t, "novice", req.UserMode)
+	assert.Equal(t, "quick", req.OutputMode)
+	assert.Equal(t, "qwen2.5-coder:7b", req.Model)
+}
+
+func TestBindCodeRequest_PreferencesUnavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createTestHandler(t)
+	handler.SetPreferenceStore(&memoryPreferenceStore{err: errors.New("portal down")})
+
+	c, _ := preferencesContext(http.MethodPost, "/test", "application/json", `{"pasted_code": "test"}`)
+	req, ok := handler.bindCodeRequest(c)
+	require.True(t, ok, "a Portal outage doesn't block reviews")
+	assert.Equal(t, "intermediate", req.UserMode)
+	assert.Equal(t, "quick", req.OutputMode)
+	assert.Equal(t, "mistral:7b-instruct", req.Model)
+}
+
+func TestPreferencesHandlers_RoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createTestHandler(t)
+	store := &memoryPreferenceStore{prefs: map[string]review_models.ReviewPreferences{}}
+	handler.SetPreferenceStore(store)
+
+	c, w := preferencesContext(http.MethodGet, "/api/review/preferences", "", "")
+	handler.GetPreferencesHandler(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{}`, w.Body.String())
+
+	c, w = preferencesContext(http.MethodPut, "/api/review/preferences", "application/json",
+		`{"default_mode": " skim ", "user_mode": "beginner", "output_mode": "full", "model": "llama3"}`)
+	handler.PutPreferencesHandler(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, review_models.ReviewPreferences{DefaultMode: "skim", UserMode: "beginner", OutputMode: "full", Model: "llama3"}, store.prefs["token-7"])
+
+	c, w = preferencesContext(http.MethodGet, "/api/review/preferences", "", "")
+	handler.GetPreferencesHandler(c)
+	assert.JSONEq(t, `{"default_mode":"skim","user_mode":"beginner","output_mode":"full","model":"llama3"}`, w.Body.String())
+}
+
+func TestPutPreferencesHandler_Validates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createTestHandler(t)
+	store := savedPreferences()
+	handler.SetPreferenceStore(store)
+
+	for _, body := range []string{
+		`{"default_mode": "skimming"}`,
+		`{"user_mode": "guru"}`,
+		`{"output_mode": "verbose"}`,
+		`{"model": "` + strings.Repeat("m", 101) + `"}`,
+		`not json`,
+	} {
+		c, w := preferencesContext(http.MethodPut, "/api/review/preferences", "application/json", body)
+		handler.PutPreferencesHandler(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	assert.Equal(t, "critical", store.prefs["token-7"].DefaultMode, "invalid preferences aren't saved")
+
+	// Without a session
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/review/preferences", http.NoBody)
+	handler.GetPreferencesHandler(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestShowWorkspace_DefaultsToPreferredMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := createTestHandler(t)
+	handler.SetPreferenceStore(savedPreferences())
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("session_token", "token-7") })
+	router.GET("/review/workspace/:session_id", handler.ShowWorkspace)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/review/workspace/8", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<option value="critical" selected>`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/review/workspace/8?mode=scan", http.NoBody))
+	assert.Contains(t, w.Body.String(), `<option value="scan" selected>`, "an explicit mode wins")
+	assert.NotContains(t, w.Body.String(), `<option value="critical" selected>`)
+}
@@ -0,0 +1,379 @@
+package review_handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// exportModes is the order modes appear in a session report.
+var exportModes = []string{
+	review_models.PreviewMode,
+	review_models.SkimMode,
+	review_models.ScanMode,
+	review_models.DetailedMode,
+	review_models.CriticalMode,
+}
+
+// issueSeverities is the order issue groups appear in, most severe first.
+// Issues with any other severity are listed last.
+var issueSeverities = []string{"critical", "high", "medium", "low"}
+
+// exportSessionMarkdown writes the session's latest result of each mode as a
+// Markdown report that can be pasted into a pull request comment.
+func (h *UIHandler) exportSessionMarkdown(c *gin.Context) {
+	userID, ok := h.sessionUser(c)
+	if !ok {
+		return
+	}
+	if h.analysisStore == nil {
+		h.logger.Warn("Analysis store not configured")
+		c.String(http.StatusServiceUnavailable, "Session export is unavailable")
+		return
+	}
+	sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || sessionID <= 0 {
+		c.String(http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	ctx := c.Request.Context()
+	session, err := h.sessionStore.GetSessionByUser(ctx, userID, sessionID)
+	if err != nil {
+		h.logger.Error("Failed to load session", "error", err.Error(), "session_id", sessionID)
+		c.String(http.StatusInternalServerError, "Failed to load session")
+		return
+	}
+	if session == nil {
+		c.String(http.StatusNotFound, "Session not found")
+		return
+	}
+	results, err := h.analysisStore.ListLatestByReview(ctx, sessionID)
+	if err != nil {
+		h.logger.Error("Failed to load session results", "error", err.Error(), "session_id", sessionID)
+		c.String(http.StatusInternalServerError, "Failed to load session")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=session-%d.md", sessionID))
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(sessionMarkdown(session, results)))
+}
+
+// sessionMarkdown renders a session report: metadata, each mode's findings,
+// and Critical Mode issues grouped by severity.
+func sessionMarkdown(session *review_models.SessionSummary, results []review_models.AnalysisResult) string {
+	byMode := make(map[string]review_models.AnalysisResult, len(results))
+	for _, result := range results {
+		if _, seen := byMode[result.Mode]; !seen {
+			byMode[result.Mode] = result
+		}
+	}
+
+	var critical *review_models.CriticalModeOutput
+	if result, ok := byMode[review_models.CriticalMode]; ok {
+		var out review_models.CriticalModeOutput
+		if json.Unmarshal([]byte(result.Output), &out) == nil {
+			critical = &out
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Code Review: %s\n\n", mdText(sessionInfo(session).Title))
+	b.WriteString("| Field | Value |\n| --- | --- |\n")
+	fmt.Fprintf(&b, "| Session | %d |\n", session.ID)
+	if session.GithubRepo != "" {
+		fmt.Fprintf(&b, "| Repository | %s |\n", mdTableCell(mdCode(session.GithubRepo)))
+	}
+	fmt.Fprintf(&b, "| Created | %s |\n", session.CreatedAt.UTC().Format(sessionTimeLayout+" MST"))
+	fmt.Fprintf(&b, "| Status | %s (%d of %d modes) |\n", mdText(session.Status), session.ModeProgress, len(exportModes))
+	if critical != nil && critical.OverallGrade != "" {
+		fmt.Fprintf(&b, "| Overall grade | **%s** |\n", mdText(critical.OverallGrade))
+	}
+
+	if len(byMode) == 0 {
+		b.WriteString("\n_No analyses have been run for this session._\n")
+		return b.String()
+	}
+
+	for _, mode := range exportModes {
+		result, ok := byMode[mode]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s Mode\n\n", modeTitle(mode))
+		if result.ModelUsed != "" {
+			fmt.Fprintf(&b, "_%s with %s_\n\n", result.CreatedAt.UTC().Format(sessionTimeLayout+" MST"), mdText(result.ModelUsed))
+		}
+		if !writeModeFindings(&b, mode, result.Output) {
+			writeParagraph(&b, result.Summary)
+		}
+	}
+	return b.String()
+}
+
+// writeModeFindings renders a stored mode output. It reports false when the
+// output is missing or can't be decoded, so the caller falls back to the
+// stored summary.
+func writeModeFindings(b *strings.Builder, mode, output string) bool {
+	if output == "" {
+		return false
+	}
+	switch mode {
+	case review_models.PreviewMode:
+		var out review_models.PreviewModeOutput
+		if json.Unmarshal([]byte(output), &out) != nil {
+			return false
+		}
+		writeParagraph(b, out.Summary)
+		writeField(b, "Architecture", out.ArchitectureStyle)
+		writeField(b, "Tech stack", strings.Join(out.TechStack, ", "))
+		writeList(b, "Entry points", out.EntryPoints, true)
+		writeList(b, "Bounded contexts", out.BoundedContexts, false)
+		writeList(b, "External dependencies", out.ExternalDeps, true)
+	case review_models.SkimMode:
+		var out review_models.SkimModeOutput
+		if json.Unmarshal([]byte(output), &out) != nil {
+			return false
+		}
+		writeParagraph(b, out.Summary)
+		if len(out.Functions) > 0 {
+			b.WriteString("### Functions\n\n")
+			for _, fn := range out.Functions {
+				signature := fn.Signature
+				if signature == "" {
+					signature = fn.Name
+				}
+				writeItem(b, mdCode(signature), fn.Description)
+			}
+			b.WriteString("\n")
+		}
+		if len(out.Interfaces) > 0 {
+			b.WriteString("### Interfaces\n\n")
+			for _, iface := range out.Interfaces {
+				writeItem(b, mdCode(iface.Name), firstNonEmpty(iface.Purpose, iface.Description))
+			}
+			b.WriteString("\n")
+		}
+		if len(out.DataModels) > 0 {
+			b.WriteString("### Data models\n\n")
+			for _, model := range out.DataModels {
+				writeItem(b, mdCode(model.Name), firstNonEmpty(model.Purpose, model.Description))
+			}
+			b.WriteString("\n")
+		}
+		if len(out.Workflows) > 0 {
+			b.WriteString("### Workflows\n\n")
+			for _, workflow := range out.Workflows {
+				writeItem(b, "**"+mdText(workflow.Name)+"**", strings.Join(workflow.Steps, " → "))
+			}
+			b.WriteString("\n")
+		}
+	case review_models.ScanMode:
+		var out review_models.ScanModeOutput
+		if json.Unmarshal([]byte(output), &out) != nil {
+			return false
+		}
+		writeParagraph(b, out.Summary)
+		if len(out.Matches) > 0 {
+			b.WriteString("### Matches\n\n")
+			for _, match := range out.Matches {
+				writeItem(b, mdCode(fileLine(match.FilePath, match.Line)), match.Context)
+			}
+			b.WriteString("\n")
+		}
+	case review_models.DetailedMode:
+		var out review_models.DetailedModeOutput
+		if json.Unmarshal([]byte(output), &out) != nil {
+			return false
+		}
+		writeParagraph(b, out.Summary)
+		writeField(b, "Algorithm", out.AlgorithmSummary)
+		writeField(b, "Complexity", out.Complexity)
+		writeList(b, "Edge cases", out.EdgeCases, false)
+	case review_models.CriticalMode:
+		var out review_models.CriticalModeOutput
+		if json.Unmarshal([]byte(output), &out) != nil {
+			return false
+		}
+		writeField(b, "Overall grade", out.OverallGrade)
+		writeParagraph(b, out.Summary)
+		writeIssues(b, out.Issues)
+	default:
+		return false
+	}
+	return true
+}
+
+// writeIssues lists issues grouped by severity, most severe first.
+func writeIssues(b *strings.Builder, issues []review_models.CodeIssue) {
+	if len(issues) == 0 {
+		b.WriteString("No issues found.\n\n")
+		return
+	}
+	b.WriteString("### Issues\n\n")
+
+	for _, group := range groupIssuesBySeverity(issues) {
+		fmt.Fprintf(b, "#### %s (%d)\n\n", mdText(group.Title), len(group.Issues))
+		for _, issue := range group.Issues {
+			var heading []string
+			if issue.Category != "" {
+				heading = append(heading, "**"+mdText(issue.Category)+"**")
+			}
+			if issue.File != "" {
+				heading = append(heading, mdCode(fileLine(issue.File, issue.Line)))
+			}
+			writeItem(b, strings.Join(heading, " "), issue.Description)
+			if issue.Impact != "" {
+				fmt.Fprintf(b, "  - Impact: %s\n", mdText(issue.Impact))
+			}
+			if issue.FixSuggestion != "" {
+				fmt.Fprintf(b, "  - Fix: %s\n", mdText(issue.FixSuggestion))
+			}
+		}
+		b.WriteString("\n")
+	}
+}
+
+// issueGroup is the issues of one severity.
+type issueGroup struct {
+	Severity string
+	Title    string
+	Issues   []review_models.CodeIssue
+}
+
+// groupIssuesBySeverity groups issues most severe first, followed by any
+// unrecognized severities in the order they first appear.
+func groupIssuesBySeverity(issues []review_models.CodeIssue) []issueGroup {
+	groups := make(map[string][]review_models.CodeIssue)
+	var others []string
+	for _, issue := range issues {
+		severity := strings.ToLower(strings.TrimSpace(issue.Severity))
+		if severity == "" {
+			severity = "unspecified"
+		}
+		if _, seen := groups[severity]; !seen && !slices.Contains(issueSeverities, severity) {
+			others = append(others, severity)
+		}
+		groups[severity] = append(groups[severity], issue)
+	}
+
+	var ordered []issueGroup
+	for _, severity := range append(append([]string{}, issueSeverities...), others...) {
+		if len(groups[severity]) > 0 {
+			ordered = append(ordered, issueGroup{
+				Severity: severity,
+				Title:    strings.ToUpper(severity[:1]) + severity[1:],
+				Issues:   groups[severity],
+			})
+		}
+	}
+	return ordered
+}
+
+func writeParagraph(b *strings.Builder, text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		b.WriteString(mdText(text) + "\n\n")
+	}
+}
+
+func writeField(b *strings.Builder, name, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		fmt.Fprintf(b, "**%s:** %s\n\n", name, mdText(value))
+	}
+}
+
+// writeList writes a titled bullet list, formatting items as code when asCode
+// is set.
+func writeList(b *strings.Builder, title string, items []string, asCode bool) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "**%s:**\n\n", title)
+	for _, item := range items {
+		if asCode {
+			writeItem(b, mdCode(item), "")
+		} else {
+			writeItem(b, mdText(item), "")
+		}
+	}
+	b.WriteString("\n")
+}
+
+// writeItem writes a bullet with an already formatted label and a plain text
+// description.
+func writeItem(b *strings.Builder, label, description string) {
+	description = mdText(description)
+	switch {
+	case label == "":
+		fmt.Fprintf(b, "- %s\n", description)
+	case description == "":
+		fmt.Fprintf(b, "- %s\n", label)
+	default:
+		fmt.Fprintf(b, "- %s — %s\n", label, description)
+	}
+}
+
+// modeTitle capitalizes a mode name for headings.
+func modeTitle(mode string) string {
+	if mode == "" {
+		return "Unknown"
+	}
+	return strings.ToUpper(mode[:1]) + mode[1:]
+}
+
+func fileLine(file string, line int) string {
+	if line > 0 {
+		return fmt.Sprintf("%s:%d", file, line)
+	}
+	return file
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// mdEscaper escapes characters that would otherwise start Markdown or HTML
+// formatting in model output.
+var mdEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `&lt;`, `>`, `&gt;`, `#`, `\#`, `|`, `\|`,
+)
+
+// mdText makes model output safe to embed in a single Markdown line.
+func mdText(s string) string {
+	return mdEscaper.Replace(strings.Join(strings.Fields(s), " "))
+}
+
+// mdCode formats s as an inline code span, using a longer fence when s
+// contains backticks.
+func mdCode(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if fence != "`" {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+// mdTableCell escapes pipes that would end a table cell early. Code spans do
+// not protect them in GitHub-flavored Markdown.
+func mdTableCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
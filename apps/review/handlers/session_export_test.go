@@ -0,0 +1,175 @@
+package review_handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// seededExportSession stores a session of user 7 with skim, scan and critical
+// results; the critical mode was run twice.
+func seededExportSession(t *testing.T) (*memorySessionStore, *memoryAnalysisStore) {
+	t.Helper()
+	created := time.Date(2025, 11, 13, 9, 30, 0, 0, time.UTC)
+	sessions := &memorySessionStore{userID: 7, sessions: []*review_models.SessionSummary{{
+		ID: 42, Title: "Checkout service", GithubRepo: "acme/shop", Status: "active", ModeProgress: 3, CreatedAt: created,
+	}}}
+
+	skim := review_models.SkimModeOutput{
+		Summary:   "Order placement and payment capture.",
+		Functions: []review_models.FunctionSignature{{Name: "PlaceOrder", Signature: "func PlaceOrder(ctx context.Context, o *Order) error", Description: "Validates and stores an order"}},
+	}
+	scan := review_models.ScanModeOutput{
+		Summary: "1 match for SQL queries",
+		Matches: []review_models.CodeMatch{{FilePath: "orders/repo.go", Line: 88, Context: "Builds the query with fmt.Sprintf"}},
+	}
+	oldCritical := review_models.CriticalModeOutput{OverallGrade: "D", Summary: "Superseded run"}
+	critical := review_models.CriticalModeOutput{
+		OverallGrade: "C",
+		Summary:      "Two problems need attention before merging.",
+		Issues: []review_models.CodeIssue{
+			{Severity: "medium", Category: "maintainability", File: "orders/service.go", Line: 120, Description: "PlaceOrder is 200 lines long", FixSuggestion: "Extract validation"},
+			{Severity: "critical", Category: "security", File: "orders/repo.go", Line: 88, Description: "SQL injection via order_id", Impact: "Attackers can read any order", FixSuggestion: "Use a parameterized query"},
+			{Severity: "low", Category: "style", Description: "Inconsistent receiver names"},
+		},
+	}
+	analyses := &memoryAnalysisStore{results: []review_models.AnalysisResult{
+		{ReviewID: 42, Mode: review_models.SkimMode, ModelUsed: "qwen2.5-coder:7b", Output: mustJSON(t, skim), CreatedAt: created.Add(time.Minute)},
+		{ReviewID: 42, Mode: review_models.CriticalMode, Output: mustJSON(t, oldCritical), CreatedAt: created.Add(2 * time.Minute)},
+		{ReviewID: 42, Mode: review_models.ScanMode, Output: mustJSON(t, scan), CreatedAt: created.Add(3 * time.Minute)},
+		{ReviewID: 42, Mode: review_models.CriticalMode, ModelUsed: "qwen2.5-coder:7b", Output: mustJSON(t, critical), CreatedAt: created.Add(4 * time.Minute)},
+		{ReviewID: 99, Mode: review_models.PreviewMode, Output: `{"summary":"Someone else's session"}`},
+	}}
+	return sessions, analyses
+}
+
+func setupExportRoute(t *testing.T, sessions SessionStore, analyses AnalysisStore, userID interface{}) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	handler := createTestHandler(t)
+	handler.SetSessionStore(sessions)
+	handler.SetAnalysisStore(analyses)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID != nil {
+			c.Set("user_id", userID)
+		}
+	})
+	router.GET("/api/review/sessions/:id/export", handler.ExportSessionHTMX)
+	return router
+}
+
+func exportSession(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	return w
+}
+
+func TestExportSessionMarkdown_RendersReport(t *testing.T) {
+	sessions, analyses := seededExportSession(t)
+	router := setupExportRoute(t, sessions, analyses, int64(7))
+
+	w := exportSession(router, "/api/review/sessions/42/export?format=markdown")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "text/markdown; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "session-42.md")
+	md := w.Body.String()
+
+	// Metadata
+	assert.True(t, strings.HasPrefix(md, "# Code Review: Checkout service\n"))
+	assert.Contains(t, md, "| Repository | `acme/shop` |")
+	assert.Contains(t, md, "| Created | 2025-11-13 09:30:00 UTC |")
+	assert.Contains(t, md, "| Status | active (3 of 5 modes) |")
+	assert.Contains(t, md, "| Overall grade | **C** |", "the latest critical run sets the grade")
+	assert.NotContains(t, md, "Superseded run")
+
+	// One section per mode that ran, in reading order
+	skim := strings.Index(md, "\n## Skim Mode\n")
+	scan := strings.Index(md, "\n## Scan Mode\n")
+	critical := strings.Index(md, "\n## Critical Mode\n")
+	require.True(t, skim >= 0 && scan > skim && critical > scan, md)
+	assert.NotContains(t, md, "## Preview Mode")
+	assert.NotContains(t, md, "Someone else")
+
+	assert.Contains(t, md, "- `func PlaceOrder(ctx context.Context, o *Order) error` — Validates and stores an order")
+	assert.Contains(t, md, "- `orders/repo.go:88` — Builds the query with fmt.Sprintf")
+
+	// Issues grouped by severity, most severe first, with location and fix
+	criticalGroup := strings.Index(md, "#### Critical (1)\n")
+	mediumGroup := strings.Index(md, "#### Medium (1)\n")
+	lowGroup := strings.Index(md, "#### Low (1)\n")
+	require.True(t, criticalGroup > critical && mediumGroup > criticalGroup && lowGroup > mediumGroup, md)
+	assert.NotContains(t, md, "#### High")
+	assert.Contains(t, md, "- **security** `orders/repo.go:88` — SQL injection via order\\_id\n"+
+		"  - Impact: Attackers can read any order\n"+
+		"  - Fix: Use a parameterized query\n")
+	assert.Contains(t, md, "- **maintainability** `orders/service.go:120` — PlaceOrder is 200 lines long\n  - Fix: Extract validation\n")
+	assert.Contains(t, md, "- **style** — Inconsistent receiver names\n")
+}
+
+func TestExportSessionMarkdown_EscapesModelOutput(t *testing.T) {
+	sessions, analyses := seededExportSession(t)
+	analyses.results = []review_models.AnalysisResult{{
+		ReviewID: 42,
+		Mode:     review_models.CriticalMode,
+		Output: mustJSON(t, review_models.CriticalModeOutput{
+			OverallGrade: "B",
+			Summary:      "# Not a heading\n<script>alert(1)</script>",
+			Issues: []review_models.CodeIssue{
+				{Severity: "high", File: "a`b.go", Description: "Uses | in a [link](http://x)"},
+			},
+		}),
+	}}
+	router := setupExportRoute(t, sessions, analyses, int64(7))
+
+	w := exportSession(router, "/api/review/sessions/42/export?format=markdown")
+	require.Equal(t, http.StatusOK, w.Code)
+	md := w.Body.String()
+
+	assert.Contains(t, md, `\# Not a heading &lt;script&gt;alert(1)&lt;/script&gt;`)
+	assert.Contains(t, md, "- `` a`b.go `` — Uses \\| in a \\[link\\](http://x)")
+	assert.NotContains(t, md, "<script>")
+}
+
+func TestExportSessionMarkdown_FallsBackToSummary(t *testing.T) {
+	sessions, _ := seededExportSession(t)
+	analyses := &memoryAnalysisStore{results: []review_models.AnalysisResult{
+		{ReviewID: 42, Mode: review_models.DetailedMode, Summary: "Explained 40 lines", Output: "not json"},
+	}}
+	router := setupExportRoute(t, sessions, analyses, int64(7))
+
+	w := exportSession(router, "/api/review/sessions/42/export?format=markdown")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "## Detailed Mode\n\nExplained 40 lines\n")
+	assert.NotContains(t, w.Body.String(), "Overall grade")
+}
+
+func TestExportSessionMarkdown_OnlyOwnSessions(t *testing.T) {
+	sessions, analyses := seededExportSession(t)
+
+	w := exportSession(setupExportRoute(t, sessions, analyses, int64(8)), "/api/review/sessions/42/export?format=markdown")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = exportSession(setupExportRoute(t, sessions, analyses, nil), "/api/review/sessions/42/export?format=markdown")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = exportSession(setupExportRoute(t, sessions, analyses, int64(7)), "/api/review/sessions/abc/export?format=markdown")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,151 @@
+package review_handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	templates "github.com/mikejsmith1985/devsmith-modular-platform/apps/review/templates"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+const (
+	defaultSessionPageSize = 20
+	maxSessionPageSize     = 100
+	sessionTimeLayout      = "2006-01-02 15:04:05"
+)
+
+// SessionStore lists a user's review sessions and their mode history for the
+// sessions sidebar.
+type SessionStore interface {
+	ListSessionsByUser(ctx context.Context, userID int64, search string, limit, offset int) ([]*review_models.SessionSummary, error)
+	GetSessionByUser(ctx context.Context, userID, sessionID int64) (*review_models.SessionSummary, error)
+	ListByReview(ctx context.Context, reviewID int64) ([]review_models.AnalysisResult, error)
+}
+
+// SetSessionStore enables the session list, search and detail endpoints.
+func (h *UIHandler) SetSessionStore(store SessionStore) {
+	h.sessionStore = store
+}
+
+// modeIcons match the icons used for the reading modes on the home page.
+var modeIcons = map[string]string{
+	review_models.PreviewMode:  "👁️",
+	review_models.SkimMode:     "⚡",
+	review_models.ScanMode:     "🔎",
+	review_models.DetailedMode: "📖",
+	review_models.CriticalMode: "🔬",
+}
+
+// sessionUser returns the authenticated user for the session endpoints,
+// writing an error response and reporting false if there is none.
+func (h *UIHandler) sessionUser(c *gin.Context) (int64, bool) {
+	if h.sessionStore == nil {
+		h.logger.Warn("Session store not configured")
+		c.String(http.StatusServiceUnavailable, "Session history is unavailable")
+		return 0, false
+	}
+
+	userID := requestUserID(c)
+	if userID <= 0 {
+		c.String(http.StatusUnauthorized, "Authentication required")
+		return 0, false
+	}
+	return userID, true
+}
+
+// renderSessionList writes one page of the user's sessions matching search.
+func (h *UIHandler) renderSessionList(c *gin.Context, search string) {
+	userID, ok := h.sessionUser(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultSessionPageSize
+	if parsed, err := strconv.Atoi(c.Query("limit")); err == nil && parsed > 0 {
+		limit = min(parsed, maxSessionPageSize)
+	}
+	offset := 0
+	if parsed, err := strconv.Atoi(c.Query("offset")); err == nil && parsed > 0 {
+		offset = parsed
+	}
+
+	ctx := c.Request.Context()
+	sessions, err := h.sessionStore.ListSessionsByUser(ctx, userID, search, limit, offset)
+	if err != nil {
+		h.logger.Error("Failed to list sessions", "error", err.Error(), "user_id", userID)
+		c.String(http.StatusInternalServerError, "Failed to load sessions")
+		return
+	}
+
+	infos := make([]templates.SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		infos = append(infos, sessionInfo(session))
+	}
+
+	// A full page may have more behind it
+	var nextURL string
+	if len(sessions) == limit {
+		next := url.Values{"limit": {strconv.Itoa(limit)}, "offset": {strconv.Itoa(offset + limit)}}
+		path := "/api/review/sessions/list"
+		if search != "" {
+			path = "/api/review/sessions/search"
+			next.Set("query", search)
+		}
+		nextURL = path + "?" + next.Encode()
+	}
+
+	c.Header("Content-Type", "text/html")
+	c.Status(http.StatusOK)
+	if err := templates.SessionList(infos, nextURL).Render(ctx, c.Writer); err != nil {
+		h.logger.Error("Failed to render session list", "error", err.Error())
+	}
+}
+
+// sessionInfo converts a stored session for display.
+func sessionInfo(session *review_models.SessionSummary) templates.SessionInfo {
+	title := session.Title
+	if title == "" {
+		title = session.GithubRepo
+	}
+	if title == "" {
+		title = fmt.Sprintf("Session %d", session.ID)
+	}
+	return templates.SessionInfo{
+		ID:        session.ID,
+		Title:     title,
+		CreatedAt: session.CreatedAt.Format(sessionTimeLayout),
+		UpdatedAt: session.LastAccessedAt.Format(sessionTimeLayout),
+		Status:    session.Status,
+		ModeCount: session.ModeProgress,
+	}
+}
+
+// modeHistory converts stored results into history entries, oldest first.
+func modeHistory(results []review_models.AnalysisResult) []templates.ModeHistoryEntry {
+	entries := make([]templates.ModeHistoryEntry, 0, len(results))
+	for _, result := range results {
+		description := result.Summary
+		if description == "" {
+			description = "Analysis completed"
+		}
+		if result.ModelUsed != "" {
+			description += " (" + result.ModelUsed + ")"
+		}
+		name := result.Mode
+		if name != "" {
+			name = strings.ToUpper(name[:1]) + name[1:]
+		}
+		entries = append(entries, templates.ModeHistoryEntry{
+			Icon:        modeIcons[result.Mode],
+			ModeName:    name + " Mode",
+			Timestamp:   result.CreatedAt.Format(sessionTimeLayout),
+			Description: description,
+		})
+	}
+	return entries
+}
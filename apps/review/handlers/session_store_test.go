@@ -0,0 +1,184 @@
+package review_handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySessionStore serves sessions owned by userID.
+type memorySessionStore struct {
+	sessions []*review_models.SessionSummary
+	history  map[int64][]review_models.AnalysisResult
+	userID   int64
+	search   string
+	limit    int
+	offset   int
+}
+
+func (m *memorySessionStore) ListSessionsByUser(_ context.Context, userID int64, search string, limit, offset int) ([]*review_models.SessionSummary, error) {
+	m.search, m.limit, m.offset = search, limit, offset
+	if userID != m.userID {
+		return nil, nil
+	}
+	var matched []*review_models.SessionSummary
+	for _, s := range m.sessions {
+		if strings.Contains(strings.ToLower(s.Title+" "+s.GithubRepo), strings.ToLower(search)) {
+			matched = append(matched, s)
+		}
+	}
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	return matched[offset:min(offset+limit, len(matched))], nil
+}
+
+func (m *memorySessionStore) GetSessionByUser(_ context.Context, userID, sessionID int64) (*review_models.SessionSummary, error) {
+	for _, s := range m.sessions {
+		if s.ID == sessionID && userID == m.userID {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memorySessionStore) ListByReview(_ context.Context, reviewID int64) ([]review_models.AnalysisResult, error) {
+	return m.history[reviewID], nil
+}
+
+func setupSessionRoutes(t *testing.T, store *memorySessionStore, userID interface{}) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	handler := createTestHandler(t)
+	handler.SetSessionStore(store)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID != nil {
+			c.Set("user_id", userID)
+		}
+	})
+	router.GET("/api/review/sessions/list", handler.ListSessionsHTMX)
+	router.GET("/api/review/sessions/search", handler.SearchSessionsHTMX)
+	router.GET("/api/review/sessions/:id", handler.GetSessionDetailHTMX)
+	return router
+}
+
+func getSessions(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	return w
+}
+
+func testSessionStore() *memorySessionStore {
+	created := time.Date(2025, 11, 10, 9, 30, 0, 0, time.UTC)
+	return &memorySessionStore{
+		userID: 42,
+		sessions: []*review_models.SessionSummary{
+			{ID: 1017, Title: "Payments API", GithubRepo: "acme/payments", Status: "active", ModeProgress: 2, CreatedAt: created, LastAccessedAt: created},
+			{ID: 1009, Title: "<script>alert(1)</script>", Status: "completed", ModeProgress: 5, CreatedAt: created, LastAccessedAt: created},
+		},
+		history: map[int64][]review_models.AnalysisResult{
+			1017: {
+				{ReviewID: 1017, Mode: review_models.PreviewMode, Summary: "Payment handlers and Stripe client", CreatedAt: created},
+				{ReviewID: 1017, Mode: review_models.CriticalMode, Summary: "Two unchecked errors", ModelUsed: "mistral:7b-instruct", CreatedAt: created.Add(time.Minute)},
+			},
+		},
+	}
+}
+
+func TestListSessionsHTMX_RendersUserSessions(t *testing.T) {
+	store := testSessionStore()
+	w := getSessions(setupSessionRoutes(t, store, 42), "/api/review/sessions/list")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `hx-get="/api/review/sessions/1017"`)
+	assert.Contains(t, body, `hx-get="/api/review/sessions/1009"`)
+	assert.Contains(t, body, "Payments API")
+	assert.Contains(t, body, "2025-11-10 09:30:00")
+	assert.Contains(t, body, "2 modes")
+	assert.Contains(t, body, "completed")
+	assert.NotContains(t, body, "<script>alert(1)</script>", "titles are escaped")
+	assert.NotContains(t, body, "Latest Session", "no placeholder sessions")
+	assert.NotContains(t, body, "Load more", "a short page has nothing after it")
+	assert.Equal(t, defaultSessionPageSize, store.limit)
+}
+
+func TestListSessionsHTMX_Paginates(t *testing.T) {
+	store := testSessionStore()
+	router := setupSessionRoutes(t, store, 42)
+
+	body := getSessions(router, "/api/review/sessions/list?limit=1").Body.String()
+	assert.Contains(t, body, "/api/review/sessions/1017")
+	assert.NotContains(t, body, "/api/review/sessions/1009")
+	assert.Contains(t, body, `hx-get="/api/review/sessions/list?limit=1&amp;offset=1"`)
+
+	body = getSessions(router, "/api/review/sessions/list?limit=1&offset=1").Body.String()
+	assert.Contains(t, body, "/api/review/sessions/1009")
+	assert.Equal(t, 1, store.offset)
+}
+
+func TestListSessionsHTMX_OnlyOwnSessions(t *testing.T) {
+	w := getSessions(setupSessionRoutes(t, testSessionStore(), 7), "/api/review/sessions/list")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "1017")
+	assert.Contains(t, w.Body.String(), "No sessions found")
+}
+
+func TestListSessionsHTMX_RequiresUser(t *testing.T) {
+	w := getSessions(setupSessionRoutes(t, testSessionStore(), nil), "/api/review/sessions/list")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSearchSessionsHTMX_MatchesTitleAndRepo(t *testing.T) {
+	store := testSessionStore()
+	router := setupSessionRoutes(t, store, int64(42))
+
+	body := getSessions(router, "/api/review/sessions/search?query=ACME").Body.String()
+	assert.Equal(t, "ACME", store.search)
+	assert.Contains(t, body, "/api/review/sessions/1017")
+	assert.NotContains(t, body, "/api/review/sessions/1009")
+
+	// An empty query lists everything instead of redirecting
+	w := getSessions(router, "/api/review/sessions/search?query=")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "/api/review/sessions/1009")
+}
+
+func TestGetSessionDetailHTMX_ShowsModeHistory(t *testing.T) {
+	router := setupSessionRoutes(t, testSessionStore(), 42)
+
+	w := getSessions(router, "/api/review/sessions/1017")
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "Payments API")
+	assert.Contains(t, body, "/api/review/sessions/1017/resume")
+	assert.Contains(t, body, "Preview Mode")
+	assert.Contains(t, body, "Payment handlers and Stripe client")
+	assert.Contains(t, body, "Critical Mode")
+	assert.Contains(t, body, "Two unchecked errors (mistral:7b-instruct)")
+	assert.NotContains(t, body, "Searched for error handling", "no placeholder history")
+	assert.Less(t, strings.Index(body, "Preview Mode"), strings.Index(body, "Critical Mode"), "history is in run order")
+
+	body = getSessions(router, "/api/review/sessions/1009").Body.String()
+	assert.Contains(t, body, "No reading modes have been run")
+
+	assert.Equal(t, http.StatusNotFound, getSessions(router, "/api/review/sessions/555").Code)
+	assert.Equal(t, http.StatusBadRequest, getSessions(router, "/api/review/sessions/abc").Code)
+}
+
+func TestGetSessionDetailHTMX_OtherUsersSessionNotFound(t *testing.T) {
+	w := getSessions(setupSessionRoutes(t, testSessionStore(), 7), "/api/review/sessions/1017")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -3,9 +3,11 @@ package review_handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -13,8 +15,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	templates "github.com/mikejsmith1985/devsmith-modular-platform/apps/review/templates"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/logging"
+	review_cache "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/cache"
 	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_errors "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/errors"
 	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
 	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
@@ -23,14 +28,22 @@ import (
 // UIHandler provides HTTP handlers for the Review UI with logging.
 // This handler depends on interfaces (not concrete types) to enforce clean architecture.
 type UIHandler struct {
-	logger          logger.Interface
-	logClient       *logging.Client
-	previewService  review_services.PreviewAnalyzer
-	skimService     review_services.SkimAnalyzer
-	scanService     review_services.ScanAnalyzer
-	detailedService review_services.DetailedAnalyzer
-	criticalService review_services.CriticalAnalyzer
-	modelService    *review_services.ModelService
+	logger           logger.Interface
+	logClient        *logging.Client
+	previewService   review_services.PreviewAnalyzer
+	skimService      review_services.SkimAnalyzer
+	scanService      review_services.ScanAnalyzer
+	detailedService  review_services.DetailedAnalyzer
+	criticalService  review_services.CriticalAnalyzer
+	modelService     *review_services.ModelService
+	analysisStore    AnalysisStore
+	sessionStore     SessionStore
+	analysisSearcher AnalysisSearcher
+	analysisSharer   AnalysisSharer
+	preferenceStore  PreferenceStore
+	gradePolicy      *review_services.GradePolicy
+	analysisSlots    analysisLimiter
+	compareTimeout   time.Duration
 }
 
 // NewUIHandler creates a new UIHandler with the given logger, logging client, and analyzer services.
@@ -57,16 +70,130 @@ func NewUIHandler(
 	}
 }
 
+// SetGradePolicy replaces the default rubric used to normalize Critical
+// mode grades.
+func (h *UIHandler) SetGradePolicy(policy *review_services.GradePolicy) {
+	h.gradePolicy = policy
+}
+
+// policy returns the configured grade policy, or the default one.
+func (h *UIHandler) policy() *review_services.GradePolicy {
+	if h.gradePolicy == nil {
+		return review_services.DefaultGradePolicy()
+	}
+	return h.gradePolicy
+}
+
 // CodeRequest represents the code submission request
 type CodeRequest struct {
 	PastedCode string `form:"pasted_code" json:"pasted_code" binding:"required"`
 	Model      string `form:"model" json:"model"`
 	UserMode   string `form:"user_mode" json:"user_mode"`     // beginner, novice, intermediate, expert
 	OutputMode string `form:"output_mode" json:"output_mode"` // quick, full
+	// Diff marks PastedCode as a unified diff. Set by the client, by a
+	// text/x-diff body, or when the pasted text is recognizably a diff.
+	Diff bool `form:"diff" json:"diff"`
+	// SessionID ties the request to one of the user's workspace sessions;
+	// results are stored and reused for it when an AnalysisStore is
+	// configured. Values that aren't the caller's own session are ignored.
+	SessionID sessionIDParam `form:"session_id" json:"session_id"`
+	// Language is the programming language of PastedCode. Clients may set
+	// it to a supported language; otherwise, or when the name isn't
+	// supported, it is detected, falling back to "unknown".
+	Language           string  `form:"language" json:"language"`
+	LanguageConfidence float64 `form:"-" json:"-"`
+}
+
+// setLanguage detects the language of the pasted code unless the client
+// named a supported one. filename, when known, takes precedence over the
+// content.
+func (req *CodeRequest) setLanguage(filename string) {
+	if lang, ok := review_services.SupportedLanguage(req.Language); ok {
+		req.Language, req.LanguageConfidence = lang, 1
+		return
+	}
+	detected := review_services.DetectLanguage(req.PastedCode, filename)
+	req.Language, req.LanguageConfidence = detected.Name, detected.Confidence
+}
+
+// analysisContext carries the request's model override and language to the
+// analyzer services, and tracks whether they answer from the response cache.
+func analysisContext(c *gin.Context, req *CodeRequest) context.Context {
+	ctx := context.WithValue(c.Request.Context(), reviewcontext.ModelContextKey, req.Model)
+	ctx = context.WithValue(ctx, reviewcontext.LanguageContextKey, req.Language)
+	return review_cache.WithHitTracking(ctx)
 }
 
-// bindCodeRequest binds code from JSON or form data using Gin's binding
+// CacheStatusHeader tells clients whether an analysis was served from the
+// response cache ("hit") or by the AI ("miss"). It is omitted when the
+// response cache is disabled.
+const CacheStatusHeader = "X-Review-Cache"
+
+// setCacheStatus sets CacheStatusHeader from the analysis run under ctx.
+func setCacheStatus(ctx context.Context, c *gin.Context) {
+	hit, consulted := review_cache.Hit(ctx)
+	if !consulted {
+		return
+	}
+	status := "miss"
+	if hit {
+		status = "hit"
+	}
+	c.Header(CacheStatusHeader, status)
+}
+
+// diffContentTypes are request bodies bound verbatim as a unified diff.
+var diffContentTypes = map[string]bool{
+	"text/x-diff":  true,
+	"text/x-patch": true,
+}
+
+// bindDiffBody reads a raw text/x-diff (or text/x-patch) body. Options that
+// would normally be form fields come from the query string instead.
+func (h *UIHandler) bindDiffBody(c *gin.Context) (*CodeRequest, bool) {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil || strings.TrimSpace(string(data)) == "" {
+		c.String(http.StatusBadRequest, "Diff required. Send a unified diff as the request body.")
+		return nil, false
+	}
+
+	req := &CodeRequest{
+		PastedCode: string(data),
+		Model:      c.Query("model"),
+		UserMode:   c.Query("user_mode"),
+		OutputMode: c.Query("output_mode"),
+		Diff:       true,
+	}
+	h.applyRequestDefaults(c, req)
+	req.SessionID = parseSessionIDParam(c.Query("session_id"))
+	req.Language = c.Query("language")
+	req.setLanguage("")
+	h.logger.Info("Diff request bound from body",
+		"diff_length", len(req.PastedCode),
+		"language", req.Language,
+		"model", req.Model,
+		"user_mode", req.UserMode,
+		"output_mode", req.OutputMode)
+	return req, true
+}
+
+// bindCodeRequest binds code from JSON, form data or a diff body, dropping a
+// session_id the caller doesn't own.
 func (h *UIHandler) bindCodeRequest(c *gin.Context) (*CodeRequest, bool) {
+	req, ok := h.bindCodeInput(c)
+	if ok && req.SessionID > 0 && !h.ownedSession(c, int64(req.SessionID)) {
+		h.logger.Warn("Ignoring session_id not owned by the caller", "session_id", int64(req.SessionID))
+		req.SessionID = 0
+	}
+	return req, ok
+}
+
+// bindCodeInput binds code from JSON or form data using Gin's binding
+func (h *UIHandler) bindCodeInput(c *gin.Context) (*CodeRequest, bool) {
+	if diffContentTypes[c.ContentType()] {
+		return h.bindDiffBody(c)
+	}
+
 	var req CodeRequest
 
 	// Try binding as form first, then JSON
@@ -92,6 +219,7 @@ func (h *UIHandler) bindCodeRequest(c *gin.Context) (*CodeRequest, bool) {
 					if om := c.PostForm("output_mode"); om != "" {
 						req.OutputMode = om
 					}
+					req.SessionID = parseSessionIDParam(c.PostForm("session_id"))
 					h.logger.Info("Code request bound from uploaded file",
 						"code_length", len(req.PastedCode),
 						"filename", fileHeader.Filename,
@@ -99,20 +227,12 @@ func (h *UIHandler) bindCodeRequest(c *gin.Context) (*CodeRequest, bool) {
 						"user_mode", req.UserMode,
 						"output_mode", req.OutputMode)
 
-					// Default model if not provided
-					if req.Model == "" {
-						req.Model = "mistral:7b-instruct"
-					}
+					h.applyRequestDefaults(c, &req)
 
-					// Default user_mode if not provided
-					if req.UserMode == "" {
-						req.UserMode = "intermediate"
-					}
-
-					// Default output_mode if not provided
-					if req.OutputMode == "" {
-						req.OutputMode = "quick"
-					}
+					ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+					req.Diff = ext == ".diff" || ext == ".patch" || review_services.IsUnifiedDiff(req.PastedCode)
+					req.Language = c.PostForm("language")
+					req.setLanguage(fileHeader.Filename)
 
 					return &req, true
 				}
@@ -126,23 +246,20 @@ func (h *UIHandler) bindCodeRequest(c *gin.Context) (*CodeRequest, bool) {
 		return nil, false
 	}
 
-	// Default model if not provided
-	if req.Model == "" {
-		req.Model = "mistral:7b-instruct"
-	}
-
-	// Default user_mode if not provided (defaults to intermediate)
-	if req.UserMode == "" {
-		req.UserMode = "intermediate"
-	}
+	// Options the request omits come from the user's preferences, then
+	// the built-in defaults
+	h.applyRequestDefaults(c, &req)
 
-	// Default output_mode if not provided (defaults to quick)
-	if req.OutputMode == "" {
-		req.OutputMode = "quick"
+	if !req.Diff {
+		req.Diff = review_services.IsUnifiedDiff(req.PastedCode)
 	}
+	req.setLanguage(c.Query("filename"))
 
 	h.logger.Info("Code request bound successfully",
 		"code_length", len(req.PastedCode),
+		"diff", req.Diff,
+		"language", req.Language,
+		"language_confidence", req.LanguageConfidence,
 		"model", req.Model,
 		"user_mode", req.UserMode,
 		"output_mode", req.OutputMode)
@@ -157,6 +274,10 @@ func looksLikeCode(s string) bool {
 	if s == "" {
 		return false
 	}
+	// a diff is code changes even when the changed lines alone look like prose
+	if review_services.IsUnifiedDiff(s) {
+		return true
+	}
 	// heuristics: common code tokens across languages
 	checks := []string{"package ", "func ", "class ", "import ", "def ", "struct ", "interface ", "=>", "->", "{", "}"}
 	score := 0
@@ -204,13 +325,14 @@ func (h *UIHandler) marshalAndFormat(c *gin.Context, result interface{}, title,
 	}
 }
 
-func (h *UIHandler) renderPreviewHTML(w http.ResponseWriter, result *review_models.PreviewModeOutput) {
+func (h *UIHandler) renderPreviewHTML(w io.Writer, result *review_models.PreviewModeOutput) {
 	html := `<div class="space-y-6 p-6 bg-indigo-50 dark:bg-indigo-900 rounded-lg border border-indigo-200 dark:border-indigo-700">
 		<div class="flex items-center gap-3 border-b border-indigo-200 dark:border-gray-700 pb-4">
 			<span class="text-3xl">👁️</span>
 			<div><h3 class="text-xl font-bold text-indigo-900 dark:text-indigo-50">Quick Preview</h3>
 			<p class="text-sm text-indigo-700 dark:text-indigo-200">High-level structure and overview</p></div>
 		</div>`
+	html += injectionWarningHTML(result.InjectionWarning)
 
 	if result.Summary != "" {
 		html += fmt.Sprintf(`<div class="prose prose-sm dark:prose-invert max-w-none">
@@ -240,13 +362,14 @@ func (h *UIHandler) renderPreviewHTML(w http.ResponseWriter, result *review_mode
 	fmt.Fprint(w, html)
 }
 
-func (h *UIHandler) renderSkimHTML(w http.ResponseWriter, result *review_models.SkimModeOutput) {
+func (h *UIHandler) renderSkimHTML(w io.Writer, result *review_models.SkimModeOutput) {
 	html := `<div class="space-y-6 p-6 bg-blue-50 dark:bg-slate-800 rounded-lg border border-blue-200 dark:border-slate-700">
 		<div class="flex items-center gap-3 border-b border-blue-200 dark:border-slate-700 pb-4">
 			<span class="text-3xl">📚</span>
 			<div><h3 class="text-xl font-bold text-blue-900 dark:text-slate-50">Skim Analysis</h3>
 			<p class="text-sm text-blue-700 dark:text-slate-200">Key components and abstractions</p></div>
 		</div>`
+	html += injectionWarningHTML(result.InjectionWarning)
 
 	// If the service returned a summary (used when input wasn't code), show it prominently
 	if result.Summary != "" {
@@ -283,13 +406,14 @@ func (h *UIHandler) renderSkimHTML(w http.ResponseWriter, result *review_models.
 	fmt.Fprint(w, html)
 }
 
-func (h *UIHandler) renderScanHTML(w http.ResponseWriter, result *review_models.ScanModeOutput) {
+func (h *UIHandler) renderScanHTML(w io.Writer, result *review_models.ScanModeOutput) {
 	html := fmt.Sprintf(`<div class="space-y-6 p-6 bg-green-50 dark:bg-green-900 rounded-lg border border-green-200 dark:border-green-700">
 		<div class="flex items-center gap-3 border-b border-green-200 dark:border-green-700 pb-4">
 			<span class="text-3xl">🔎</span>
 			<div><h3 class="text-xl font-bold text-green-900 dark:text-green-50">Search Results</h3>
 			<p class="text-sm text-green-700 dark:text-green-200">Found %d matches</p></div>
 		</div>`, len(result.Matches))
+	html += injectionWarningHTML(result.InjectionWarning)
 
 	if len(result.Matches) > 0 {
 		html += `<div class="space-y-4">`
@@ -311,13 +435,14 @@ func (h *UIHandler) renderScanHTML(w http.ResponseWriter, result *review_models.
 	fmt.Fprint(w, html)
 }
 
-func (h *UIHandler) renderDetailedHTML(w http.ResponseWriter, result *review_models.DetailedModeOutput) {
+func (h *UIHandler) renderDetailedHTML(w io.Writer, result *review_models.DetailedModeOutput) {
 	html := `<div class="space-y-6 p-6 bg-yellow-50 dark:bg-yellow-900 rounded-lg border border-yellow-200 dark:border-yellow-700">
 		<div class="flex items-center gap-3 border-b border-yellow-200 dark:border-yellow-700 pb-4">
 			<span class="text-3xl">📖</span>
 			<div><h3 class="text-xl font-bold text-yellow-900 dark:text-yellow-50">Detailed Analysis</h3>
 			<p class="text-sm text-yellow-700 dark:text-yellow-200">Line-by-line explanation</p></div>
 		</div>`
+	html += injectionWarningHTML(result.InjectionWarning)
 
 	if result.AlgorithmSummary != "" {
 		html += fmt.Sprintf(`<div class="p-4 bg-yellow-100 dark:bg-yellow-800 rounded-lg border border-yellow-200 dark:border-yellow-700">
@@ -346,13 +471,14 @@ func (h *UIHandler) renderDetailedHTML(w http.ResponseWriter, result *review_mod
 	fmt.Fprint(w, html)
 }
 
-func (h *UIHandler) renderCriticalHTML(w http.ResponseWriter, result *review_models.CriticalModeOutput) {
+func (h *UIHandler) renderCriticalHTML(w io.Writer, result *review_models.CriticalModeOutput) {
 	html := fmt.Sprintf(`<div class="space-y-6 p-6 bg-red-50 dark:bg-red-900 rounded-lg border border-red-200 dark:border-red-700">
 		<div class="flex items-center gap-3 border-b border-red-200 dark:border-red-700 pb-4">
 			<span class="text-3xl">🚨</span>
 			<div><h3 class="text-xl font-bold text-red-900 dark:text-red-50">Critical Review</h3>
 			<p class="text-sm text-red-700 dark:text-red-200">Found %d issues</p></div>
 		</div>`, len(result.Issues))
+	html += injectionWarningHTML(result.InjectionWarning)
 
 	if result.OverallGrade != "" {
 		html += fmt.Sprintf(`<div class="text-center p-4 bg-white dark:bg-gray-800 rounded-lg">
@@ -420,6 +546,20 @@ func (h *UIHandler) renderError(c *gin.Context, err error, fallbackMessage strin
 	h.logger.Error("Request error", "error", err.Error(), "path", c.Request.URL.Path)
 
 	c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	// Over budget is expected, not a server error
+	if errors.Is(err, ai.ErrBudgetExceeded) {
+		c.Status(http.StatusPaymentRequired)
+		templates.BudgetExceeded().Render(c.Request.Context(), c.Writer)
+		return
+	}
+	// Oversized input is rejected before the AI call; retrying won't help
+	var businessErr *review_errors.BusinessError
+	if errors.As(err, &businessErr) && businessErr.Code == review_services.ErrCodeInputTooLarge {
+		c.Status(businessErr.StatusCode())
+		templates.ErrorDisplay("warning", "Input Too Large", businessErr.Message, false, "").Render(c.Request.Context(), c.Writer)
+		return
+	}
 	c.Status(http.StatusInternalServerError)
 
 	// Classify error and render appropriate template
@@ -589,8 +729,21 @@ func (h *UIHandler) HandlePreviewMode(c *gin.Context) {
 		return
 	}
 
-	// TODO: Pass model to service via context for Ollama override
-	ctx := context.WithValue(c.Request.Context(), reviewcontext.ModelContextKey, req.Model)
+	// Pass model and language to service via context
+	ctx := analysisContext(c, req)
+
+	inputHash := analysisInputHash(review_models.PreviewMode, req)
+	var stored review_models.PreviewModeOutput
+	if h.loadStoredResult(ctx, req, review_models.PreviewMode, inputHash, &stored) {
+		h.marshalAndFormat(c, &stored, "👁️ Preview Mode Analysis", "bg-indigo-50 dark:bg-indigo-900 border border-indigo-200 dark:border-indigo-700")
+		return
+	}
+
+	release, ok := h.acquireAnalysisSlot(c)
+	if !ok {
+		return
+	}
+	defer release()
 
 	result, err := h.previewService.AnalyzePreview(ctx, req.PastedCode, req.UserMode, req.OutputMode)
 	if err != nil {
@@ -598,6 +751,8 @@ func (h *UIHandler) HandlePreviewMode(c *gin.Context) {
 		h.renderError(c, err, "Preview analysis failed")
 		return
 	}
+	setCacheStatus(ctx, c)
+	h.saveResult(ctx, req, review_models.PreviewMode, inputHash, "", result)
 
 	h.marshalAndFormat(c, result, "👁️ Preview Mode Analysis", "bg-indigo-50 dark:bg-indigo-900 border border-indigo-200 dark:border-indigo-700")
 }
@@ -618,8 +773,8 @@ func (h *UIHandler) HandleSkimMode(c *gin.Context) {
 		return
 	}
 
-	// Pass model to service via context for Ollama override
-	ctx := context.WithValue(c.Request.Context(), reviewcontext.ModelContextKey, req.Model)
+	// Pass model and language to service via context
+	ctx := analysisContext(c, req)
 
 	// If the pasted input doesn't look like source code, avoid calling Skim mode
 	// which expects actual source files (functions, interfaces, data models).
@@ -633,12 +788,27 @@ func (h *UIHandler) HandleSkimMode(c *gin.Context) {
 		return
 	}
 
+	inputHash := analysisInputHash(review_models.SkimMode, req)
+	var stored review_models.SkimModeOutput
+	if h.loadStoredResult(ctx, req, review_models.SkimMode, inputHash, &stored) {
+		h.marshalAndFormat(c, &stored, "📚 Skim Mode Analysis", "bg-blue-50 dark:bg-blue-900 border border-blue-200 dark:border-blue-700")
+		return
+	}
+
+	release, ok := h.acquireAnalysisSlot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
 	result, err := h.skimService.AnalyzeSkim(ctx, req.PastedCode, req.UserMode, req.OutputMode)
 	if err != nil {
 		h.logger.Error("Skim analysis failed", "error", err.Error(), "model", req.Model, "user_mode", req.UserMode, "output_mode", req.OutputMode)
 		h.renderError(c, err, "Skim analysis failed")
 		return
 	}
+	setCacheStatus(ctx, c)
+	h.saveResult(ctx, req, review_models.SkimMode, inputHash, "", result)
 
 	h.marshalAndFormat(c, result, "📚 Skim Mode Analysis", "bg-blue-50 dark:bg-blue-900 border border-blue-200 dark:border-blue-700")
 }
@@ -661,8 +831,8 @@ func (h *UIHandler) HandleScanMode(c *gin.Context) {
 		return
 	}
 
-	// Pass model to service via context for Ollama override
-	ctx := context.WithValue(c.Request.Context(), reviewcontext.ModelContextKey, req.Model)
+	// Pass model and language to service via context
+	ctx := analysisContext(c, req)
 
 	// If the pasted content doesn't look like source code, avoid calling the LLM-driven
 	// scan which may hallucinate code-like matches. Instead, perform a safe local
@@ -710,12 +880,27 @@ func (h *UIHandler) HandleScanMode(c *gin.Context) {
 		return
 	}
 
+	inputHash := analysisInputHash(review_models.ScanMode, req, query)
+	var stored review_models.ScanModeOutput
+	if h.loadStoredResult(ctx, req, review_models.ScanMode, inputHash, &stored) {
+		h.marshalAndFormat(c, &stored, "🔎 Scan Mode Analysis", "bg-green-50 dark:bg-green-900 border border-green-200 dark:border-green-700")
+		return
+	}
+
+	release, ok := h.acquireAnalysisSlot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
 	result, err := h.scanService.AnalyzeScan(ctx, query, req.PastedCode, req.UserMode, req.OutputMode)
 	if err != nil {
 		h.logger.Error("Scan analysis failed", "error", err.Error(), "model", req.Model, "user_mode", req.UserMode, "output_mode", req.OutputMode)
 		h.renderError(c, err, "Scan analysis failed")
 		return
 	}
+	setCacheStatus(ctx, c)
+	h.saveResult(ctx, req, review_models.ScanMode, inputHash, query, result)
 
 	h.marshalAndFormat(c, result, "🔎 Scan Mode Analysis", "bg-green-50 dark:bg-green-900 border border-green-200 dark:border-green-700")
 }
@@ -738,8 +923,8 @@ func (h *UIHandler) HandleDetailedMode(c *gin.Context) {
 		return
 	}
 
-	// Pass model to service via context for Ollama override
-	ctx := context.WithValue(c.Request.Context(), reviewcontext.ModelContextKey, req.Model)
+	// Pass model and language to service via context
+	ctx := analysisContext(c, req)
 
 	// If the content doesn't look like code, avoid running Detailed Mode (it expects source)
 	if !looksLikeCode(req.PastedCode) {
@@ -762,17 +947,34 @@ func (h *UIHandler) HandleDetailedMode(c *gin.Context) {
 		return
 	}
 
+	inputHash := analysisInputHash(review_models.DetailedMode, req, filename)
+	var stored review_models.DetailedModeOutput
+	if h.loadStoredResult(ctx, req, review_models.DetailedMode, inputHash, &stored) {
+		h.marshalAndFormat(c, &stored, "📖 Detailed Mode Analysis", "bg-yellow-50 dark:bg-yellow-900 border border-yellow-200 dark:border-yellow-700")
+		return
+	}
+
+	release, ok := h.acquireAnalysisSlot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
 	result, err := h.detailedService.AnalyzeDetailed(ctx, req.PastedCode, filename, req.UserMode, req.OutputMode)
 	if err != nil {
 		h.logger.Error("Detailed analysis failed", "error", err.Error(), "model", req.Model, "user_mode", req.UserMode, "output_mode", req.OutputMode)
 		h.renderError(c, err, "Detailed analysis failed")
 		return
 	}
+	setCacheStatus(ctx, c)
+	h.saveResult(ctx, req, review_models.DetailedMode, inputHash, "", result)
 
 	h.marshalAndFormat(c, result, "📖 Detailed Mode Analysis", "bg-yellow-50 dark:bg-yellow-900 border border-yellow-200 dark:border-yellow-700")
 }
 
 // HandleCriticalMode handles POST /api/review/modes/critical (HTMX)
+// A unified diff (text/x-diff body, diff=true, or pasted diff text) is
+// reviewed change-by-change instead of as a whole file.
 // nolint:dupl // Similar structure across handlers is acceptable; each mode has distinct service and context
 func (h *UIHandler) HandleCriticalMode(c *gin.Context) {
 	req, ok := h.bindCodeRequest(c)
@@ -788,8 +990,8 @@ func (h *UIHandler) HandleCriticalMode(c *gin.Context) {
 		return
 	}
 
-	// Pass model to service via context for Ollama override
-	ctx := context.WithValue(c.Request.Context(), reviewcontext.ModelContextKey, req.Model)
+	// Pass model and language to service via context
+	ctx := analysisContext(c, req)
 
 	// If pasted content doesn't look like source code, avoid running full Critical
 	// analysis which focuses on architecture/layering and code quality.
@@ -801,15 +1003,42 @@ func (h *UIHandler) HandleCriticalMode(c *gin.Context) {
 		return
 	}
 
-	result, err := h.criticalService.AnalyzeCritical(ctx, req.PastedCode)
+	inputHash := analysisInputHash(review_models.CriticalMode, req)
+	var stored review_models.CriticalModeOutput
+	if h.loadStoredResult(ctx, req, review_models.CriticalMode, inputHash, &stored) {
+		h.marshalAndFormat(c, &stored, "🚨 Critical Mode Analysis", "bg-red-50 dark:bg-red-900 border border-red-200 dark:border-red-700")
+		return
+	}
+
+	release, ok := h.acquireAnalysisSlot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
+	analyze := h.criticalService.AnalyzeCritical
+	if req.Diff {
+		// Review only what changed; issue lines refer to the new file
+		analyze = h.criticalService.AnalyzeCriticalDiff
+	}
+	result, err := analyze(ctx, req.PastedCode)
 	if err != nil {
 		h.logger.Error("Critical analysis failed", "error", err.Error(), "model", req.Model)
 		h.renderError(c, err, "Critical analysis failed")
 		return
 	}
 
-	// Normalize overall grade deterministically based on issues to reduce LLM variance
-	deterministic := determineGradeFromIssues(result.Issues)
+	setCacheStatus(ctx, c)
+	h.normalizeCriticalGrade(result)
+	h.saveResult(ctx, req, review_models.CriticalMode, inputHash, "", result)
+
+	h.marshalAndFormat(c, result, "🚨 Critical Mode Analysis", "bg-red-50 dark:bg-red-900 border border-red-200 dark:border-red-700")
+}
+
+// normalizeCriticalGrade replaces the model's overall grade with one derived
+// deterministically from the issues by the grade policy, to reduce LLM variance.
+func (h *UIHandler) normalizeCriticalGrade(result *review_models.CriticalModeOutput) {
+	deterministic := h.policy().GradeFromIssues(result.Issues)
 	if deterministic != "" && deterministic != result.OverallGrade {
 		// preserve original grade in the summary for traceability
 		orig := result.OverallGrade
@@ -820,48 +1049,16 @@ func (h *UIHandler) HandleCriticalMode(c *gin.Context) {
 		}
 		result.OverallGrade = deterministic
 	}
-
-	h.marshalAndFormat(c, result, "🚨 Critical Mode Analysis", "bg-red-50 dark:bg-red-900 border border-red-200 dark:border-red-700")
-}
-
-// determineGradeFromIssues applies a simple deterministic rubric to compute an overall grade
-// based on the counts of issues by severity. This prevents non-deterministic LLM grades.
-func determineGradeFromIssues(issues []review_models.CodeIssue) string {
-	if len(issues) == 0 {
-		return "A"
-	}
-	var crit, high, med int
-	for _, it := range issues {
-		switch strings.ToLower(it.Severity) {
-		case "critical":
-			crit++
-		case "high":
-			high++
-		case "medium":
-			med++
-		}
-	}
-	// Rubric (conservative): any critical -> F, 2+ high -> D, 1 high or 3+ med -> C, 1-2 med -> B, else A
-	switch {
-	case crit > 0:
-		return "F"
-	case high >= 2:
-		return "D"
-	case high == 1 || med >= 3:
-		return "C"
-	case med >= 1:
-		return "B"
-	default:
-		return "A"
-	}
 }
 
-// GetAvailableModels returns a list of available Ollama models (queries dynamically)
+// GetAvailableModels returns a list of available Ollama models.
+// The list is cached briefly; ?force=true queries Ollama again.
 func (h *UIHandler) GetAvailableModels(c *gin.Context) {
 	ctx := c.Request.Context()
+	force := c.Query("force") == "true"
 
 	// Use model service to query Ollama for actual available models
-	modelsJSON, err := h.modelService.ListAvailableModelsJSON(ctx)
+	modelsJSON, err := h.modelService.ListAvailableModelsJSON(ctx, force)
 	if err != nil {
 		h.logger.Error("Failed to retrieve available models", "error", err.Error())
 		// Return fallback: only Mistral 7B (guaranteed to be available)
@@ -877,143 +1074,55 @@ func (h *UIHandler) GetAvailableModels(c *gin.Context) {
 }
 
 // ListSessionsHTMX handles GET /api/review/sessions/list (HTMX)
+// Lists the authenticated user's sessions, newest first, paged by limit and offset.
 func (h *UIHandler) ListSessionsHTMX(c *gin.Context) {
-	// For now, return placeholder HTML with mock sessions
-	// In production, this would fetch from SessionHandler via internal API
-	html := `
-	<div class="space-y-2">
-		<div class="p-3 rounded-lg border border-indigo-400 bg-indigo-50 dark:bg-indigo-900 dark:border-indigo-600 cursor-pointer">
-			<div class="flex items-start justify-between">
-				<div class="flex-1 min-w-0">
-					<h4 class="text-sm font-medium truncate text-indigo-900 dark:text-indigo-200">Latest Session</h4>
-					<p class="text-xs truncate text-indigo-700 dark:text-indigo-300">2025-11-01 10:30:00</p>
-				</div>
-				<span class="px-2 py-1 rounded text-xs font-medium whitespace-nowrap ml-2 bg-green-100 dark:bg-green-900 text-green-800 dark:text-green-200">active</span>
-			</div>
-			<div class="mt-2 flex items-center justify-between">
-				<span class="text-xs text-gray-500 dark:text-gray-400">2 modes</span>
-				<button class="text-xs text-red-600 dark:text-red-400 hover:text-red-800">🗑️</button>
-			</div>
-		</div>
-		<div class="p-3 rounded-lg border border-gray-200 dark:border-gray-700 hover:border-gray-300 dark:hover:border-gray-600 cursor-pointer">
-			<div class="flex items-start justify-between">
-				<div class="flex-1 min-w-0">
-					<h4 class="text-sm font-medium truncate text-gray-900 dark:text-white">Review Session 2</h4>
-					<p class="text-xs truncate text-gray-600 dark:text-gray-400">2025-10-31 15:45:00</p>
-				</div>
-				<span class="px-2 py-1 rounded text-xs font-medium whitespace-nowrap ml-2 bg-gray-100 dark:bg-gray-700 text-gray-800 dark:text-gray-200">completed</span>
-			</div>
-			<div class="mt-2 flex items-center justify-between">
-				<span class="text-xs text-gray-500 dark:text-gray-400">5 modes</span>
-				<button class="text-xs text-red-600 dark:text-red-400 hover:text-red-800">🗑️</button>
-			</div>
-		</div>
-	</div>
-	`
-	c.Header("Content-Type", "text/html")
-	c.String(http.StatusOK, html)
+	h.renderSessionList(c, "")
 }
 
 // SearchSessionsHTMX handles GET /api/review/sessions/search (HTMX)
+// Matches query against session title and GitHub repository, ignoring case.
 func (h *UIHandler) SearchSessionsHTMX(c *gin.Context) {
-	query := c.Query("query")
+	query := strings.TrimSpace(c.Query("query"))
 	h.logger.Info("Searching sessions", "query", query)
-
-	// Placeholder: return filtered results
-	if query == "" {
-		// Return all sessions
-		c.Redirect(http.StatusMovedPermanently, "/api/review/sessions/list")
-		return
-	}
-
-	html := `
-	<div class="space-y-2">
-		<div class="p-3 rounded-lg border border-gray-200 dark:border-gray-700 cursor-pointer">
-			<div class="flex items-start justify-between">
-				<div class="flex-1 min-w-0">
-					<h4 class="text-sm font-medium truncate text-gray-900 dark:text-white">Matching: ` + query + `</h4>
-					<p class="text-xs truncate text-gray-600 dark:text-gray-400">2025-10-30 12:00:00</p>
-				</div>
-				<span class="px-2 py-1 rounded text-xs font-medium whitespace-nowrap ml-2 bg-gray-100 dark:bg-gray-700 text-gray-800 dark:text-gray-200">completed</span>
-			</div>
-			<div class="mt-2 flex items-center justify-between">
-				<span class="text-xs text-gray-500 dark:text-gray-400">3 modes</span>
-				<button class="text-xs text-red-600 dark:text-red-400 hover:text-red-800">🗑️</button>
-			</div>
-		</div>
-	</div>
-	`
-	c.Header("Content-Type", "text/html")
-	c.String(http.StatusOK, html)
+	h.renderSessionList(c, query)
 }
 
 // GetSessionDetailHTMX handles GET /api/review/sessions/:id (HTMX)
 func (h *UIHandler) GetSessionDetailHTMX(c *gin.Context) {
-	sessionID := c.Param("id")
+	userID, ok := h.sessionUser(c)
+	if !ok {
+		return
+	}
+	sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || sessionID <= 0 {
+		c.String(http.StatusBadRequest, "Invalid session ID")
+		return
+	}
 	h.logger.Info("Loading session detail", "session_id", sessionID)
 
-	// Placeholder: return session detail view
-	html := `
-	<div class="w-full lg:flex-1 bg-white dark:bg-gray-800 rounded-lg shadow-sm border border-gray-200 dark:border-gray-700 p-6">
-		<div class="flex items-start justify-between mb-6 pb-6 border-b border-gray-200 dark:border-gray-700">
-			<div class="flex-1">
-				<h2 class="text-2xl font-bold text-gray-900 dark:text-white">Session ` + sessionID + `</h2>
-				<div class="mt-2 flex items-center gap-4">
-					<span class="px-3 py-1 rounded-lg text-sm font-medium bg-green-100 dark:bg-green-900 text-green-800 dark:text-green-200">active</span>
-					<span class="text-sm text-gray-600 dark:text-gray-400">Created: 2025-11-01 10:30:00</span>
-				</div>
-			</div>
-			<button class="px-4 py-2 rounded-lg font-medium bg-red-600 text-white hover:bg-red-700 dark:hover:bg-red-800 transition-colors text-sm" onclick="if(confirm('Delete this session?')) { htmx.ajax('DELETE', '/api/review/sessions/` + sessionID + `', {target:'#session-detail', swap:'innerHTML'}) }">🗑️ Delete</button>
-		</div>
-
-		<div class="grid grid-cols-1 md:grid-cols-3 gap-4 mb-6">
-			<div class="p-4 rounded-lg bg-indigo-50 dark:bg-indigo-900 border border-indigo-200 dark:border-indigo-700">
-				<div class="text-sm font-medium text-indigo-600 dark:text-indigo-300">Reading Modes Used</div>
-				<div class="mt-2 text-2xl font-bold text-indigo-900 dark:text-indigo-100">2</div>
-			</div>
-			<div class="p-4 rounded-lg bg-green-50 dark:bg-green-900 border border-green-200 dark:border-green-700">
-				<div class="text-sm font-medium text-green-600 dark:text-green-300">Created</div>
-				<div class="mt-2 text-sm text-green-900 dark:text-green-100">2025-11-01 10:30:00</div>
-			</div>
-			<div class="p-4 rounded-lg bg-blue-50 dark:bg-blue-900 border border-blue-200 dark:border-blue-700">
-				<div class="text-sm font-medium text-blue-600 dark:text-blue-300">Last Updated</div>
-				<div class="mt-2 text-sm text-blue-900 dark:text-blue-100">2025-11-01 10:45:00</div>
-			</div>
-		</div>
-
-		<div class="space-y-4">
-			<h3 class="text-lg font-semibold text-gray-900 dark:text-white">Actions</h3>
-			<div class="grid grid-cols-1 sm:grid-cols-2 gap-3">
-				<button class="px-4 py-3 rounded-lg font-medium bg-indigo-600 text-white hover:bg-indigo-700 dark:hover:bg-indigo-800 transition-colors text-sm">▶️ Resume Session</button>
-				<button class="px-4 py-3 rounded-lg font-medium bg-gray-600 text-white hover:bg-gray-700 dark:hover:bg-gray-800 transition-colors text-sm">⬇️ Export Session</button>
-				<button class="px-4 py-3 rounded-lg font-medium bg-purple-600 text-white hover:bg-purple-700 dark:hover:bg-purple-800 transition-colors text-sm">📋 Duplicate</button>
-				<button class="px-4 py-3 rounded-lg font-medium border-2 border-gray-300 dark:border-gray-600 text-gray-700 dark:text-gray-300">📁 Archive</button>
-			</div>
-		</div>
+	ctx := c.Request.Context()
+	session, err := h.sessionStore.GetSessionByUser(ctx, userID, sessionID)
+	if err != nil {
+		h.logger.Error("Failed to load session", "error", err.Error(), "session_id", sessionID)
+		c.String(http.StatusInternalServerError, "Failed to load session")
+		return
+	}
+	if session == nil {
+		c.String(http.StatusNotFound, "Session not found")
+		return
+	}
+	history, err := h.sessionStore.ListByReview(ctx, sessionID)
+	if err != nil {
+		h.logger.Error("Failed to load mode history", "error", err.Error(), "session_id", sessionID)
+		c.String(http.StatusInternalServerError, "Failed to load session")
+		return
+	}
 
-		<div class="mt-8 pt-8 border-t border-gray-200 dark:border-gray-700">
-			<h3 class="text-lg font-semibold text-gray-900 dark:text-white mb-4">Mode History</h3>
-			<div class="space-y-3">
-				<div class="p-3 rounded-lg bg-gray-50 dark:bg-gray-700 border border-gray-200 dark:border-gray-600">
-					<div class="flex items-center justify-between">
-						<span class="text-sm font-medium text-gray-900 dark:text-white">👁️ Preview Mode</span>
-						<span class="text-xs text-gray-500 dark:text-gray-400">10:15 AM</span>
-					</div>
-					<p class="mt-1 text-xs text-gray-600 dark:text-gray-400">Analyzed project structure</p>
-				</div>
-				<div class="p-3 rounded-lg bg-gray-50 dark:bg-gray-700 border border-gray-200 dark:border-gray-600">
-					<div class="flex items-center justify-between">
-						<span class="text-sm font-medium text-gray-900 dark:text-white">🔎 Scan Mode</span>
-						<span class="text-xs text-gray-500 dark:text-gray-400">10:20 AM</span>
-					</div>
-					<p class="mt-1 text-xs text-gray-600 dark:text-gray-400">Searched for error handling</p>
-				</div>
-			</div>
-		</div>
-	</div>
-	`
 	c.Header("Content-Type", "text/html")
-	c.String(http.StatusOK, html)
+	c.Status(http.StatusOK)
+	if err := templates.SessionDetailView(sessionInfo(session), modeHistory(history)).Render(ctx, c.Writer); err != nil {
+		h.logger.Error("Failed to render session detail", "error", err.Error())
+	}
 }
 
 // ResumeSessionHTMX handles POST /api/review/sessions/:id/resume (HTMX)
@@ -1137,7 +1246,10 @@ func (h *UIHandler) ExportSessionHTMX(c *gin.Context) {
 	format := c.DefaultQuery("format", "json")
 	h.logger.Info("Exporting session", "session_id", sessionID, "format", format)
 
-	if format == "json" {
+	switch format {
+	case "markdown", "md":
+		h.exportSessionMarkdown(c)
+	case "json":
 		c.Header("Content-Type", "application/json")
 		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=session-%s.json", sessionID))
 		c.JSON(http.StatusOK, gin.H{
@@ -1149,7 +1261,7 @@ func (h *UIHandler) ExportSessionHTMX(c *gin.Context) {
 				"analysis_time_ms": 3245,
 			},
 		})
-	} else {
+	default:
 		c.Header("Content-Type", "text/csv")
 		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=session-%s.csv", sessionID))
 		c.String(http.StatusOK, "session_id,modes_used,code_lines,analysis_time_ms\n"+sessionID+",5,2847,3245\n")
@@ -1270,6 +1382,10 @@ func GenerateAnalysisID() string {
 // Path Parameters:
 //   - session_id: Session ID (integer)
 //
+// Query Parameters:
+//   - mode: Reading mode to open (optional; defaults to the session's latest
+//     run, then the user's preferred mode, then preview)
+//
 // Response: HTML page with two-pane layout (code left, analysis right)
 func (h *UIHandler) ShowWorkspace(c *gin.Context) {
 	// Extract session ID from URL
@@ -1289,7 +1405,7 @@ func (h *UIHandler) ShowWorkspace(c *gin.Context) {
 			SessionID:      0,
 			Title:          fmt.Sprintf("DevSmith Review - Workspace (User: %v)", username),
 			Code:           sampleCodeForWorkspace(),
-			CurrentMode:    "preview",
+			CurrentMode:    h.preferredMode(c),
 			AnalysisResult: "",
 		}
 	} else {
@@ -1304,15 +1420,24 @@ func (h *UIHandler) ShowWorkspace(c *gin.Context) {
 
 		h.logger.Info("showing workspace", "session_id", sessionID, "user_id", userID, "username", username)
 
-		// For now, use sample data with user context
-		// TODO: Fetch actual session data from ReviewRepository by session_id and user_id
 		props = templates.WorkspaceProps{
 			SessionID:      sessionID,
 			Title:          fmt.Sprintf("Code Review Session #%d (User: %v)", sessionID, username),
 			Code:           sampleCodeForWorkspace(),
-			CurrentMode:    "preview",
+			CurrentMode:    h.preferredMode(c),
 			AnalysisResult: "",
 		}
+		// Restore the session's latest stored results instead of the sample
+		// code; falls back to the sample when nothing has been stored yet or
+		// the session belongs to someone else
+		if h.ownedSession(c, int64(sessionID)) {
+			h.loadWorkspaceResults(c.Request.Context(), int64(sessionID), &props)
+		}
+	}
+
+	// An explicit ?mode= wins over the restored and preferred modes
+	if mode := c.Query("mode"); modeIcons[mode] != "" {
+		props.CurrentMode = mode
 	}
 
 	// Render workspace template
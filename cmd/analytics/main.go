@@ -4,6 +4,7 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
 	"os"
 	"time"
 
@@ -15,8 +16,12 @@ import (
 	analytics_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/services"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/common/debug"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/health"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/instrumentation"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/middleware"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/server"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/tracing"
 	"github.com/sirupsen/logrus"
 )
 
@@ -65,7 +70,10 @@ func main() {
 	aggregationRepo := analytics_db.NewAggregationRepository(dbPool)
 	logReader := analytics_db.NewLogReader(dbPool)
 
+	rollupRepo := analytics_db.NewRollupRepository(dbPool)
+
 	aggregatorService := analytics_services.NewAggregatorService(aggregationRepo, logReader, logger)
+	aggregatorService.SetRollupRepository(rollupRepo)
 	trendService := analytics_services.NewTrendService(aggregationRepo, logger)
 	anomalyService := analytics_services.NewAnomalyService(aggregationRepo, logger)
 	topIssuesService := analytics_services.NewTopIssuesService(logReader, logger)
@@ -76,6 +84,42 @@ func main() {
 
 	router := gin.Default()
 
+	// CORS runs first so preflight requests are answered before auth
+	corsConfig, err := config.LoadCORSConfig()
+	if err != nil {
+		log.Fatalf("Failed to load CORS configuration: %v", err)
+	}
+	router.Use(middleware.CORSMiddleware(corsConfig))
+
+	// Cap request bodies and handler time (REQUEST_MAX_BODY_BYTES,
+	// REQUEST_TIMEOUT_SECONDS). The export streams until it is done, so it
+	// has no timeout.
+	requestLimits, err := config.LoadRequestLimits("REQUEST_", config.DefaultRequestLimits)
+	if err != nil {
+		log.Fatalf("Failed to load request limits: %v", err)
+	}
+	router.Use(middleware.RequestLimitsMiddleware(requestLimits, map[string]config.RequestLimits{
+		"/api/analytics/export": {MaxBodyBytes: requestLimits.MaxBodyBytes},
+	}))
+
+	// Initialize OpenTelemetry tracing; a failure leaves tracing disabled
+	tracingEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if tracingEndpoint == "" {
+		tracingEndpoint = tracing.DefaultEndpoint
+	}
+	shutdownTracer, err := tracing.InitTracer("devsmith-analytics", tracingEndpoint)
+	if err != nil {
+		log.Printf("Warning: Failed to initialize tracing: %v", err)
+	} else {
+		defer func() {
+			if shutdownErr := shutdownTracer(context.Background()); shutdownErr != nil {
+				log.Printf("Warning: Failed to flush traces: %v", shutdownErr)
+			}
+		}()
+		log.Printf("Tracing initialized (endpoint: %s)", tracingEndpoint)
+	}
+	router.Use(tracing.Middleware("devsmith-analytics"))
+
 	// Middleware for logging requests (skip health checks)
 	router.Use(func(c *gin.Context) {
 		if c.Request.URL.Path != "/health" {
@@ -93,17 +137,14 @@ func main() {
 	// Serve static files (CSS, JS)
 	router.Static("/static", "./apps/analytics/static")
 
-	// Register API routes
+	// Register API routes; the cache diagnostics need a session
 	apiHandler.RegisterRoutes(router)
+	apiHandler.RegisterDebugRoutes(router.Group("/api/analytics/debug", middleware.RedisSessionAuthMiddleware(sessionStore)))
 
-	// Simple health endpoint for smoke tests
-	router.GET("/api/analytics/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"service": "analytics",
-			"status":  "healthy",
-			"version": "1.0.0",
-		})
-	})
+	// Service health for monitoring and smoke tests
+	analyticsHealth := health.NewHandler("analytics")
+	analyticsHealth.AddCheck("database", health.Ping(dbPool.Ping))
+	analyticsHealth.Register(router, "/api/analytics/health")
 
 	// Register metrics dashboard routes
 	router.GET("/api/analytics/metrics/dashboard", metricsHandler.GetDashboardData)
@@ -124,6 +165,20 @@ func main() {
 	// Register debug routes (development only)
 	debug.RegisterDebugRoutes(router, "analytics")
 
+	// Roll raw logs up into hourly/daily buckets in the background
+	rollupInterval := analytics_services.DefaultRollupInterval
+	if v := os.Getenv("ANALYTICS_ROLLUP_INTERVAL"); v != "" {
+		if d, parseErr := time.ParseDuration(v); parseErr == nil && d > 0 {
+			rollupInterval = d
+		} else {
+			logger.Warnf("Invalid ANALYTICS_ROLLUP_INTERVAL %q, using %s", v, rollupInterval)
+		}
+	}
+	rollupCtx, stopRollups := context.WithCancel(context.Background())
+	defer stopRollups()
+	go analytics_services.NewRollupJob(rollupRepo, logger).Start(rollupCtx, rollupInterval)
+	logger.Infof("Log rollup job started: interval=%s", rollupInterval)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8083"
@@ -131,7 +186,15 @@ func main() {
 
 	logger.Infof("Analytics service starting on port %s...", port)
 
-	if err := router.Run(":" + port); err != nil {
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           router,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	// On SIGINT/SIGTERM, drain in-flight requests, then stop the rollup job
+	// before the deferred database and Redis closes run
+	if err := server.Run(srv, server.DefaultShutdownTimeout, stopRollups); err != nil {
 		logger.WithError(err).Fatalf("Failed to start server: %v", err)
 	}
+	logger.Info("Analytics service shutdown complete")
 }
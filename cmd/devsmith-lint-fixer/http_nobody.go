@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+)
+
+// requestBodyArg is the position of the body argument in each net/http
+// request constructor
+var requestBodyArg = map[string]int{
+	"NewRequest":            2,
+	"NewRequestWithContext": 3,
+}
+
+// fixHTTPNoBodyIssues rewrites nil request bodies passed to
+// http.NewRequest and http.NewRequestWithContext as http.NoBody.
+// Returns one change per call rewritten.
+func fixHTTPNoBodyIssues(files []string, dryRun bool) ([]Change, error) {
+	contents := make(map[string][]byte)
+	var changes []Change
+	for _, path := range files {
+		src, fileChanges, err := rewriteNilBodies(path)
+		if err != nil {
+			return changes, err
+		}
+		if len(fileChanges) > 0 {
+			contents[path] = src
+			changes = append(changes, fileChanges...)
+		}
+	}
+	return changes, applyChanges(contents, dryRun)
+}
+
+// rewriteNilBodies returns the new contents of path and the calls rewritten
+func rewriteNilBodies(path string) ([]byte, []Change, error) {
+	//nolint:gosec // path is from findGoFiles, not user input
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	// The calls can only reach net/http through its import, so the import
+	// is always present; it may be renamed though
+	httpName := ""
+	for _, spec := range file.Imports {
+		if importPath, _ := strconv.Unquote(spec.Path.Value); importPath == "net/http" {
+			httpName = "http"
+			if spec.Name != nil {
+				httpName = spec.Name.Name
+			}
+		}
+	}
+	if httpName == "" || httpName == "_" || httpName == "." {
+		return nil, nil, nil
+	}
+
+	var edits []textEdit
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := sel.X.(*ast.Ident)
+		if !ok || pkg.Name != httpName || pkg.Obj != nil {
+			return true
+		}
+		bodyArg, ok := requestBodyArg[sel.Sel.Name]
+		if !ok || len(call.Args) <= bodyArg {
+			return true
+		}
+		// A nil that resolves to a local declaration is not the builtin
+		if body, ok := call.Args[bodyArg].(*ast.Ident); ok && body.Name == "nil" && body.Obj == nil {
+			edits = append(edits, textEdit{
+				start: fset.Position(body.Pos()).Offset,
+				end:   fset.Position(body.End()).Offset,
+				text:  httpName + ".NoBody",
+			})
+		}
+		return true
+	})
+	if len(edits) == 0 {
+		return nil, nil, nil
+	}
+
+	changes := describeEdits(path, src, edits, ruleHTTPNoBody)
+	formatted, err := format.Source(applyEdits(src, edits))
+	if err != nil {
+		return nil, nil, fmt.Errorf("format %s: %w", path, err)
+	}
+	return formatted, changes, nil
+}
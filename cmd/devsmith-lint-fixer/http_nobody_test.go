@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleNilBody = `package sample
+
+import (
+	"context"
+	"net/http"
+)
+
+func fetch(ctx context.Context, url string) (*http.Request, error) {
+	if _, err := http.NewRequest("GET", url, nil); err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, "GET", url, nil)
+}
+`
+
+const sampleNoBody = `package sample
+
+import (
+	"context"
+	nethttp "net/http"
+)
+
+func fetch(ctx context.Context, url string) (*nethttp.Request, error) {
+	return nethttp.NewRequestWithContext(ctx, "GET", url, nethttp.NoBody)
+}
+`
+
+func writeSample(t *testing.T, src string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "client.go")
+	require.NoError(t, os.WriteFile(path, []byte(src), 0o600))
+	return path
+}
+
+func TestFixHTTPNoBodyIssues_RewritesNilBody(t *testing.T) {
+	path := writeSample(t, sampleNilBody)
+
+	changes, err := fixHTTPNoBodyIssues([]string{path}, false)
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+
+	fixed := readFile(t, path)
+	assert.Contains(t, fixed, `http.NewRequest("GET", url, http.NoBody)`)
+	assert.Contains(t, fixed, `http.NewRequestWithContext(ctx, "GET", url, http.NoBody)`)
+	assert.Contains(t, fixed, "return nil, err", "other nil arguments are untouched")
+}
+
+func TestFixHTTPNoBodyIssues_LeavesNoBodyAlone(t *testing.T) {
+	path := writeSample(t, sampleNoBody)
+
+	changes, err := fixHTTPNoBodyIssues([]string{path}, false)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Equal(t, sampleNoBody, readFile(t, path))
+}
+
+func TestFixHTTPNoBodyIssues_UsesRenamedImport(t *testing.T) {
+	path := writeSample(t, `package sample
+
+import nethttp "net/http"
+
+func build(url string) (*nethttp.Request, error) {
+	return nethttp.NewRequest("GET", url, nil)
+}
+`)
+
+	changes, err := fixHTTPNoBodyIssues([]string{path}, false)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+	assert.Contains(t, readFile(t, path), `nethttp.NewRequest("GET", url, nethttp.NoBody)`)
+}
+
+func TestFixHTTPNoBodyIssues_DryRunPrintsDiff(t *testing.T) {
+	path := writeSample(t, sampleNilBody)
+	var out bytes.Buffer
+	diffOut = &out
+	t.Cleanup(func() { diffOut = os.Stdout })
+
+	changes, err := fixHTTPNoBodyIssues([]string{path}, true)
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+
+	assert.Equal(t, sampleNilBody, readFile(t, path), "dry run leaves files alone")
+	assert.Contains(t, out.String(), "-\treturn http.NewRequestWithContext(ctx, \"GET\", url, nil)")
+	assert.Contains(t, out.String(), "+\treturn http.NewRequestWithContext(ctx, \"GET\", url, http.NoBody)")
+}
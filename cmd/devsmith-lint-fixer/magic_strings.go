@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// defaultMinOccurrences is how many times a literal must appear in a package
+// before it is extracted
+const defaultMinOccurrences = 3
+
+// commonInitialisms are upper-cased when naming extracted constants
+var commonInitialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"JSON": true, "SQL": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// sourceFile is one parsed file of a package
+type sourceFile struct {
+	path string
+	src  []byte
+	file *ast.File
+}
+
+// literalUse is one occurrence of a string literal
+type literalUse struct {
+	file *sourceFile
+	lit  *ast.BasicLit
+}
+
+// fixMagicStringsIssues extracts string literals repeated at least
+// minOccurrences times within a package into constants. Import paths, struct
+// tags, existing constant declarations, and test files are left alone.
+// Returns one change per literal replaced.
+func fixMagicStringsIssues(files []string, minOccurrences int, dryRun bool) ([]Change, error) {
+	contents := make(map[string][]byte)
+	var changes []Change
+	for _, pkgFiles := range groupByPackage(files) {
+		pkgContents, pkgChanges, err := extractMagicStrings(pkgFiles, minOccurrences)
+		if err != nil {
+			return changes, err
+		}
+		for path, src := range pkgContents {
+			contents[path] = src
+		}
+		changes = append(changes, pkgChanges...)
+	}
+	return changes, applyChanges(contents, dryRun)
+}
+
+// groupByPackage groups non-test files by directory
+func groupByPackage(files []string) [][]string {
+	byDir := make(map[string][]string)
+	var dirs []string
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		dir := filepath.Dir(file)
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], file)
+	}
+	sort.Strings(dirs)
+
+	groups := make([][]string, 0, len(dirs))
+	for _, dir := range dirs {
+		sort.Strings(byDir[dir])
+		groups = append(groups, byDir[dir])
+	}
+	return groups
+}
+
+// extractMagicStrings rewrites the files of one package, returning the new
+// contents of each changed file and the literals replaced
+func extractMagicStrings(paths []string, minOccurrences int) (map[string][]byte, []Change, error) {
+	if minOccurrences < 2 {
+		minOccurrences = 2
+	}
+
+	fset := token.NewFileSet()
+	var files []*sourceFile
+	for _, path := range paths {
+		//nolint:gosec // path is from findGoFiles, not user input
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		// Files behind build constraints may not be compiled alongside the
+		// rest, so they can neither host nor share the constants
+		if ast.IsGenerated(file) || hasBuildConstraint(file) {
+			continue
+		}
+		files = append(files, &sourceFile{path: path, src: src, file: file})
+	}
+
+	// Only files of the package's main name; a stray package main in a
+	// library directory is its own program
+	files = filterMainPackage(files)
+
+	uses := make(map[string][]literalUse)
+	var values []string
+	taken := make(map[string]bool)
+	for _, sf := range files {
+		for _, lit := range stringLiterals(sf.file) {
+			value, err := strconv.Unquote(lit.Value)
+			if err != nil || !strings.ContainsFunc(value, isWordRune) {
+				continue
+			}
+			if _, ok := uses[value]; !ok {
+				values = append(values, value)
+			}
+			uses[value] = append(uses[value], literalUse{file: sf, lit: lit})
+		}
+		// Avoid every identifier in the package, not just top-level ones,
+		// so a constant is never shadowed where it is used
+		ast.Inspect(sf.file, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				taken[ident.Name] = true
+			}
+			return true
+		})
+	}
+
+	type constant struct {
+		name  string
+		value string
+		uses  []literalUse
+	}
+	var constants []constant
+	for _, value := range values {
+		if len(uses[value]) < minOccurrences {
+			continue
+		}
+		name := constantName(value, taken)
+		taken[name] = true
+		constants = append(constants, constant{name: name, value: value, uses: uses[value]})
+	}
+	if len(constants) == 0 {
+		return nil, nil, nil
+	}
+	sort.Slice(constants, func(i, j int) bool { return constants[i].name < constants[j].name })
+
+	// The constants live in the file that uses them most
+	perFile := make(map[*sourceFile]int)
+	edits := make(map[*sourceFile][]textEdit)
+	for _, c := range constants {
+		for _, use := range c.uses {
+			perFile[use.file]++
+			edits[use.file] = append(edits[use.file], textEdit{
+				start: fset.Position(use.lit.Pos()).Offset,
+				end:   fset.Position(use.lit.End()).Offset,
+				text:  c.name,
+			})
+		}
+	}
+	var host *sourceFile
+	var changes []Change
+	for _, sf := range files {
+		if host == nil || perFile[sf] > perFile[host] {
+			host = sf
+		}
+		changes = append(changes, describeEdits(sf.path, sf.src, edits[sf], ruleMagicString)...)
+	}
+
+	var block strings.Builder
+	block.WriteString("\n\n// Constants extracted by devsmith-lint-fixer\nconst (\n")
+	for _, c := range constants {
+		fmt.Fprintf(&block, "\t%s = %s\n", c.name, strconv.Quote(c.value))
+	}
+	block.WriteString(")\n")
+	at := fset.Position(host.file.Name.End()).Offset
+	for _, decl := range host.file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			at = fset.Position(gen.End()).Offset
+		}
+	}
+	edits[host] = append(edits[host], textEdit{start: at, end: at, text: block.String()})
+
+	contents := make(map[string][]byte, len(edits))
+	for sf, fileEdits := range edits {
+		formatted, err := format.Source(applyEdits(sf.src, fileEdits))
+		if err != nil {
+			return nil, nil, fmt.Errorf("format %s: %w", sf.path, err)
+		}
+		contents[sf.path] = formatted
+	}
+	return contents, changes, nil
+}
+
+// stringLiterals returns the string literals in file that could be replaced
+// by a constant
+func stringLiterals(file *ast.File) []*ast.BasicLit {
+	skip := make(map[*ast.BasicLit]bool)
+	var lits []*ast.BasicLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ImportSpec:
+			return false
+		case *ast.GenDecl:
+			if n.Tok == token.CONST {
+				return false
+			}
+		case *ast.Field:
+			if n.Tag != nil {
+				skip[n.Tag] = true
+			}
+		case *ast.BasicLit:
+			if n.Kind == token.STRING && !skip[n] {
+				lits = append(lits, n)
+			}
+		}
+		return true
+	})
+	return lits
+}
+
+// filterMainPackage keeps the files declaring the most common package name
+func filterMainPackage(files []*sourceFile) []*sourceFile {
+	counts := make(map[string]int)
+	name := ""
+	for _, sf := range files {
+		pkg := sf.file.Name.Name
+		counts[pkg]++
+		if name == "" || counts[pkg] > counts[name] {
+			name = pkg
+		}
+	}
+	kept := files[:0]
+	for _, sf := range files {
+		if sf.file.Name.Name == name {
+			kept = append(kept, sf)
+		}
+	}
+	return kept
+}
+
+// hasBuildConstraint reports whether file has a //go:build line
+func hasBuildConstraint(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			break
+		}
+		for _, c := range group.List {
+			if strings.HasPrefix(c.Text, "//go:build") || strings.HasPrefix(c.Text, "// +build") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isWordRune reports whether r can appear in an identifier
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// constantName derives an unexported identifier from value that is not in taken
+func constantName(value string, taken map[string]bool) string {
+	words := strings.FieldsFunc(value, func(r rune) bool { return !isWordRune(r) })
+	if len(words) > 4 {
+		words = words[:4]
+	}
+	var name strings.Builder
+	name.WriteString("str")
+	for _, word := range words {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			name.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		name.WriteString(string(runes))
+	}
+
+	base := name.String()
+	candidate := base
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	return candidate
+}
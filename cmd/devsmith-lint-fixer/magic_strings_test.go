@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleHandlers = `package sample
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type response struct {
+	Status string ` + "`json:\"status\"`" + `
+}
+
+const existing = "application/json"
+
+func ok(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response{Status: "ok"})
+}
+
+func created(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+}
+`
+
+const sampleClient = `package sample
+
+import "net/http"
+
+func request(req *http.Request) {
+	req.Header.Set("Content-Type", "text/plain")
+}
+`
+
+const sampleTest = `package sample
+
+import "testing"
+
+func TestThing(t *testing.T) {
+	t.Log("application/json")
+}
+`
+
+// writeSamplePackage writes a small package with "Content-Type" used three
+// times across two files and returns the file paths
+func writeSamplePackage(t *testing.T) (dir string, files []string) {
+	t.Helper()
+	dir = t.TempDir()
+	for name, src := range map[string]string{
+		"handlers.go":      sampleHandlers,
+		"client.go":        sampleClient,
+		"handlers_test.go": sampleTest,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600))
+	}
+	return dir, findGoFiles(dir)
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path) //nolint:gosec // test temp dir
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestFixMagicStringsIssues_ExtractsRepeatedLiteral(t *testing.T) {
+	dir, files := writeSamplePackage(t)
+
+	changes, err := fixMagicStringsIssues(files, 3, false)
+	require.NoError(t, err)
+	assert.Len(t, changes, 3)
+
+	handlers := readFile(t, filepath.Join(dir, "handlers.go"))
+	assert.Contains(t, handlers, "const (\n\tstrContentType = \"Content-Type\"\n)")
+	assert.Equal(t, 2, strings.Count(handlers, `w.Header().Set(strContentType, "application/json")`),
+		"literal below the threshold is untouched")
+	assert.Contains(t, handlers, "`json:\"status\"`", "struct tag is untouched")
+	assert.Contains(t, handlers, `const existing = "application/json"`, "existing constant is untouched")
+	assert.Contains(t, handlers, `"encoding/json"`, "import path is untouched")
+	assert.Less(t, strings.Index(handlers, ")\n\n// Constants extracted"), strings.Index(handlers, "type response"),
+		"constants follow the imports")
+
+	client := readFile(t, filepath.Join(dir, "client.go"))
+	assert.Contains(t, client, `req.Header.Set(strContentType, "text/plain")`)
+	assert.NotContains(t, client, "const (", "constants are declared once per package")
+
+	assert.Equal(t, sampleTest, readFile(t, filepath.Join(dir, "handlers_test.go")), "test files are untouched")
+}
+
+func TestFixMagicStringsIssues_ThresholdIsConfigurable(t *testing.T) {
+	dir, files := writeSamplePackage(t)
+
+	// "application/json" appears twice outside the const declaration
+	changes, err := fixMagicStringsIssues(files, 2, false)
+	require.NoError(t, err)
+	assert.Len(t, changes, 5)
+
+	handlers := readFile(t, filepath.Join(dir, "handlers.go"))
+	assert.Contains(t, handlers, `strApplicationJSON = "application/json"`)
+	assert.Contains(t, handlers, "w.Header().Set(strContentType, strApplicationJSON)")
+}
+
+func TestFixMagicStringsIssues_DryRunPrintsDiff(t *testing.T) {
+	dir, files := writeSamplePackage(t)
+	var out bytes.Buffer
+	diffOut = &out
+	t.Cleanup(func() { diffOut = os.Stdout })
+
+	changes, err := fixMagicStringsIssues(files, 3, true)
+	require.NoError(t, err)
+	assert.Len(t, changes, 3)
+
+	assert.Equal(t, sampleHandlers, readFile(t, filepath.Join(dir, "handlers.go")), "dry run leaves files alone")
+	assert.Contains(t, out.String(), "+++ b/"+strings.TrimPrefix(filepath.ToSlash(filepath.Join(dir, "handlers.go")), "/"))
+	assert.Contains(t, out.String(), "+\tstrContentType = \"Content-Type\"")
+	assert.Contains(t, out.String(), "-\treq.Header.Set(\"Content-Type\", \"text/plain\")")
+}
+
+func TestConstantName(t *testing.T) {
+	taken := map[string]bool{"strUserID": true}
+
+	assert.Equal(t, "strAPIGenerate", constantName("/api/generate", taken))
+	assert.Equal(t, "strUserID2", constantName("user_id", taken))
+	assert.Equal(t, "strListenAndServe", constantName("listen and Serve", taken))
+}
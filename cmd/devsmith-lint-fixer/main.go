@@ -4,21 +4,49 @@ package main
 import (
 	"flag"
 	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+// Exit codes
+const (
+	exitOK             = 0
+	exitChangesPending = 1 // Dry run found fixes to apply
+	exitError          = 2
+)
+
 func main() {
-	fixMagicStringsFlag := flag.Bool("fix-strings", false, "Extract magic strings to constants")
-	fixPackageCommentsFlag := flag.Bool("fix-comments", false, "Add missing package comments")
-	fixHTTPNoBodyFlag := flag.Bool("fix-http", false, "Replace nil with http.NoBody in requests")
-	fixAllFlag := flag.Bool("all", false, "Run all fixes")
-	dryRun := flag.Bool("dry-run", true, "Show what would be changed (default true)")
-	path := flag.String("path", ".", "Path to analyze (default current directory)")
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
 
-	flag.Parse()
+// run parses args, applies the selected fixes, and returns the exit code.
+// With --report json the report goes to stdout (or --report-file) and the
+// human output to stderr.
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("devsmith-lint-fixer", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fixMagicStringsFlag := fs.Bool("fix-strings", false, "Extract magic strings to constants")
+	fixPackageCommentsFlag := fs.Bool("fix-comments", false, "Add missing package comments")
+	fixHTTPNoBodyFlag := fs.Bool("fix-http", false, "Replace nil with http.NoBody in requests")
+	fixAllFlag := fs.Bool("all", false, "Run all fixes")
+	dryRun := fs.Bool("dry-run", true, "Show what would be changed (default true)")
+	path := fs.String("path", ".", "Path to analyze (default current directory)")
+	minOccurrences := fs.Int("min-occurrences", defaultMinOccurrences, "Times a string must repeat within a package before it is extracted")
+	reportFormat := fs.String("report", "text", "Report format: text or json")
+	reportFile := fs.String("report-file", "", "Write the JSON report to this file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *reportFormat != "text" && *reportFormat != "json" {
+		fmt.Fprintf(stderr, "Unknown report format %q (want text or json)\n", *reportFormat)
+		return exitError
+	}
 
 	if *fixAllFlag {
 		*fixMagicStringsFlag = true
@@ -27,49 +55,74 @@ func main() {
 	}
 
 	if !*fixMagicStringsFlag && !*fixPackageCommentsFlag && !*fixHTTPNoBodyFlag {
-		flag.PrintDefaults()
-		fmt.Println("\nExample:")
-		fmt.Println("  devsmith-lint-fixer --all --path ./internal/ai")
-		fmt.Println("  devsmith-lint-fixer --all --path ./internal/ai --dry-run=false")
-		return
+		fs.SetOutput(stdout)
+		fs.PrintDefaults()
+		fmt.Fprintln(stdout, "\nExample:")
+		fmt.Fprintln(stdout, "  devsmith-lint-fixer --all --path ./internal/ai")
+		fmt.Fprintln(stdout, "  devsmith-lint-fixer --all --path ./internal/ai --dry-run=false")
+		fmt.Fprintln(stdout, "  devsmith-lint-fixer --all --path . --report json  # exits 1 if fixes are pending")
+		return exitOK
 	}
 
+	// Keep stdout clean for the JSON report
+	human := stdout
+	if *reportFormat == "json" && *reportFile == "" {
+		human = stderr
+	}
+	diffOut = human
+	logger := log.New(stderr, "", log.LstdFlags)
+
 	files := findGoFiles(*path)
 	if len(files) == 0 {
-		log.Fatalf("No Go files found in %s", *path)
+		logger.Printf("No Go files found in %s", *path)
+		return exitError
 	}
 
-	fmt.Printf("Found %d Go files\n", len(files))
+	fmt.Fprintf(human, "Found %d Go files\n", len(files))
 
-	changeCount := 0
-
-	if *fixPackageCommentsFlag {
-		count, err := fixMissingPackageComments(files, *dryRun)
+	report := &Report{DryRun: *dryRun, Files: len(files), Changes: []Change{}, Errors: []string{}}
+	record := func(what string, changes []Change, err error) {
+		report.Changes = append(report.Changes, changes...)
 		if err != nil {
-			log.Printf("Error fixing package comments: %v", err)
+			logger.Printf("Error fixing %s: %v", what, err)
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", what, err))
 		}
-		changeCount += count
+	}
+
+	if *fixPackageCommentsFlag {
+		changes, err := fixMissingPackageComments(files, *dryRun)
+		record("package comments", changes, err)
 	}
 
 	if *fixMagicStringsFlag {
-		count, err := fixMagicStringsIssues(files, *dryRun)
-		if err != nil {
-			log.Printf("Error fixing magic strings: %v", err)
-		}
-		changeCount += count
+		changes, err := fixMagicStringsIssues(files, *minOccurrences, *dryRun)
+		record("magic strings", changes, err)
 	}
 
 	if *fixHTTPNoBodyFlag {
-		count, err := fixHTTPNoBodyIssues(files, *dryRun)
-		if err != nil {
-			log.Printf("Error fixing http.NoBody: %v", err)
-		}
-		changeCount += count
+		changes, err := fixHTTPNoBodyIssues(files, *dryRun)
+		record("http.NoBody", changes, err)
 	}
 
-	fmt.Printf("\nTotal changes: %d\n", changeCount)
+	fmt.Fprintf(human, "\nTotal changes: %d\n", len(report.Changes))
 	if *dryRun {
-		fmt.Println("(Dry run mode - no files modified)")
+		fmt.Fprintln(human, "(Dry run mode - no files modified)")
+	}
+
+	if *reportFormat == "json" {
+		if err := writeReport(report, *reportFile, stdout); err != nil {
+			logger.Printf("Error writing report: %v", err)
+			return exitError
+		}
+	}
+
+	switch {
+	case len(report.Errors) > 0:
+		return exitError
+	case *dryRun && len(report.Changes) > 0:
+		return exitChangesPending
+	default:
+		return exitOK
 	}
 }
 
@@ -90,11 +143,9 @@ func findGoFiles(path string) []string {
 	return files
 }
 
-// fixMissingPackageComments adds package-level comments to files that lack them.
-//
-//nolint:unparam // dryRun parameter will be used in full implementation
-func fixMissingPackageComments(files []string, dryRun bool) (int, error) {
-	count := 0
+// fixMissingPackageComments adds a package comment to known packages that
+// lack one. Returns one change per comment added.
+func fixMissingPackageComments(files []string, dryRun bool) ([]Change, error) {
 	packageComments := map[string]string{
 		"providers": "Package providers contains AI provider implementations for different services.",
 		"security":  "Package security provides encryption and security utilities for the DevSmith platform.",
@@ -103,43 +154,52 @@ func fixMissingPackageComments(files []string, dryRun bool) (int, error) {
 		"button":    "Package button provides UI button components using design tokens.",
 	}
 
-	for _, file := range files {
-		pkg := filepath.Base(filepath.Dir(file))
-		if comment, exists := packageComments[pkg]; exists {
-			if err := ensurePackageComment(file, pkg, comment, dryRun); err == nil {
-				count++
-			}
+	contents := make(map[string][]byte)
+	var changes []Change
+	for _, pkgFiles := range groupByPackage(files) {
+		comment, exists := packageComments[filepath.Base(filepath.Dir(pkgFiles[0]))]
+		if !exists {
+			continue
+		}
+		file, src, change, err := ensurePackageComment(pkgFiles, comment)
+		if err != nil {
+			return changes, err
+		}
+		if change != nil {
+			contents[file] = src
+			changes = append(changes, *change)
 		}
 	}
 
-	return count, nil
-}
-
-// fixMagicStringsIssues extracts repeated string literals to constants (placeholder).
-//
-//nolint:unparam // Placeholder function - dryRun parameter will be used in full implementation
-func fixMagicStringsIssues(files []string, dryRun bool) (int, error) {
-	// Extract repeated string literals and create constants
-	// This is a simplified version - real implementation would be more sophisticated
-	count := 0
-	for range files {
-		// Placeholder: actual implementation would parse and modify files
-	}
-	return count, nil
+	return changes, applyChanges(contents, dryRun)
 }
 
-// fixHTTPNoBodyIssues replaces nil with http.NoBody in HTTP requests (placeholder).
-//
-//nolint:unparam // Placeholder function - dryRun parameter will be used in full implementation
-func fixHTTPNoBodyIssues(files []string, dryRun bool) (int, error) {
-	count := 0
-	for range files {
-		// Placeholder: replace nil with http.NoBody in NewRequest calls
+// ensurePackageComment adds comment above the package clause of the first of
+// a package's files, unless one of them already has a package comment.
+// Returns the file changed and its new contents, or a nil change.
+func ensurePackageComment(files []string, comment string) (string, []byte, *Change, error) {
+	fset := token.NewFileSet()
+	var first []byte
+	at := 0
+	for i, file := range files {
+		//nolint:gosec // path is from findGoFiles, not user input
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		parsed, err := parser.ParseFile(fset, file, src, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("parse %s: %w", file, err)
+		}
+		if parsed.Doc != nil {
+			return "", nil, nil, nil
+		}
+		if i == 0 {
+			first, at = src, fset.Position(parsed.Package).Offset
+		}
 	}
-	return count, nil
-}
 
-func ensurePackageComment(file, pkg, comment string, dryRun bool) error {
-	// Placeholder for actual implementation
-	return nil
+	edit := textEdit{start: at, end: at, text: "// " + comment + "\n"}
+	change := describeEdits(files[0], first, []textEdit{edit}, rulePackageComment)[0]
+	return files[0], applyEdits(first, []textEdit{edit}), &change, nil
 }
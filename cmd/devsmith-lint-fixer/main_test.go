@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_JSONReport(t *testing.T) {
+	path := writeSample(t, sampleNilBody)
+	var stdout, stderr bytes.Buffer
+	t.Cleanup(func() { diffOut = os.Stdout })
+
+	code := run([]string{"-fix-http", "-path", filepath.Dir(path), "-report", "json"}, &stdout, &stderr)
+	assert.Equal(t, exitChangesPending, code)
+
+	var report Report
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report), "stdout holds only the report")
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Files)
+	assert.Empty(t, report.Errors)
+	require.Len(t, report.Changes, 2)
+	assert.Equal(t, Change{
+		File:   path,
+		Line:   12,
+		Rule:   ruleHTTPNoBody,
+		Before: `return http.NewRequestWithContext(ctx, "GET", url, nil)`,
+		After:  `return http.NewRequestWithContext(ctx, "GET", url, http.NoBody)`,
+	}, report.Changes[1])
+
+	assert.Contains(t, stderr.String(), "Total changes: 2")
+	assert.Contains(t, stderr.String(), "+++ b/", "diff goes to stderr")
+}
+
+func TestRun_JSONReportFile(t *testing.T) {
+	path := writeSample(t, sampleNilBody)
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	var stdout, stderr bytes.Buffer
+	t.Cleanup(func() { diffOut = os.Stdout })
+
+	code := run([]string{"-fix-http", "-path", filepath.Dir(path), "-report", "json", "-report-file", reportPath, "-dry-run=false"}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, "applied fixes are not pending")
+
+	var report Report
+	require.NoError(t, json.Unmarshal([]byte(readFile(t, reportPath)), &report))
+	assert.False(t, report.DryRun)
+	assert.Len(t, report.Changes, 2)
+	assert.Contains(t, stdout.String(), "Total changes: 2", "human output stays on stdout")
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	t.Cleanup(func() { diffOut = os.Stdout })
+	var out bytes.Buffer
+
+	clean := writeSample(t, sampleNoBody)
+	assert.Equal(t, exitOK, run([]string{"-fix-http", "-path", filepath.Dir(clean)}, &out, &out))
+
+	pending := writeSample(t, sampleNilBody)
+	assert.Equal(t, exitChangesPending, run([]string{"-fix-http", "-path", filepath.Dir(pending)}, &out, &out))
+
+	broken := writeSample(t, "package sample\n\nfunc {")
+	assert.Equal(t, exitError, run([]string{"-fix-http", "-path", filepath.Dir(broken)}, &out, &out))
+
+	assert.Equal(t, exitError, run([]string{"-fix-http", "-report", "xml"}, &out, &out))
+	assert.Equal(t, exitError, run([]string{"-fix-http", "-path", t.TempDir()}, &out, &out), "no Go files")
+}
+
+func TestFixMissingPackageComments(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "security")
+	require.NoError(t, os.Mkdir(dir, 0o750))
+	path := filepath.Join(dir, "crypto.go")
+	require.NoError(t, os.WriteFile(path, []byte("package security\n\nfunc Hash() {}\n"), 0o600))
+
+	changes, err := fixMissingPackageComments([]string{path}, false)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, rulePackageComment, changes[0].Rule)
+	assert.Equal(t, 1, changes[0].Line)
+	assert.Equal(t, "// Package security provides encryption and security utilities for the DevSmith platform.\npackage security\n\nfunc Hash() {}\n",
+		readFile(t, path))
+
+	changes, err = fixMissingPackageComments([]string{path}, false)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "an existing package comment is kept")
+}
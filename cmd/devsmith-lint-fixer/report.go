@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Rules reported for each change
+const (
+	rulePackageComment = "package-comment"
+	ruleMagicString    = "magic-string"
+	ruleHTTPNoBody     = "http-nobody"
+)
+
+// Change is one fix, applied or, in dry-run mode, pending
+type Change struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Rule   string `json:"rule"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Report is the machine-readable result of a run
+type Report struct {
+	DryRun  bool     `json:"dry_run"`
+	Files   int      `json:"files"`
+	Changes []Change `json:"changes"`
+	Errors  []string `json:"errors"`
+}
+
+// describeEdits reports each edit to the file at path as a Change, using the
+// whole source line before and after as the snippet
+func describeEdits(path string, src []byte, edits []textEdit, rule string) []Change {
+	sorted := append([]textEdit(nil), edits...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start < sorted[j].start })
+
+	changes := make([]Change, 0, len(sorted))
+	for _, e := range sorted {
+		lineStart := bytes.LastIndexByte(src[:e.start], '\n') + 1
+		lineEnd := len(src)
+		if i := bytes.IndexByte(src[e.end:], '\n'); i >= 0 {
+			lineEnd = e.end + i
+		}
+		// Other edits on the same line show up in its after snippet too
+		var onLine []textEdit
+		for _, other := range sorted {
+			if other.start >= lineStart && other.end <= lineEnd {
+				onLine = append(onLine, textEdit{start: other.start - lineStart, end: other.end - lineStart, text: other.text})
+			}
+		}
+		line := src[lineStart:lineEnd]
+		changes = append(changes, Change{
+			File:   path,
+			Line:   bytes.Count(src[:e.start], []byte("\n")) + 1,
+			Rule:   rule,
+			Before: strings.TrimSpace(string(line)),
+			After:  strings.TrimSpace(string(applyEdits(line, onLine))),
+		})
+	}
+	return changes
+}
+
+// writeReport writes report as JSON to path, or to out when path is empty
+func writeReport(report *Report, path string, out io.Writer) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "" {
+		_, err = out.Write(data)
+		return err
+	}
+	//nolint:gosec // intentional 0o644 for readable files
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// diffOut receives dry-run diffs
+var diffOut io.Writer = os.Stdout
+
+// applyChanges writes the new contents of each changed file, or in dry-run
+// mode prints a unified diff against what is on disk instead
+func applyChanges(changes map[string][]byte, dryRun bool) error {
+	paths := make([]string, 0, len(changes))
+	for path := range changes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		//nolint:gosec // path is from findGoFiles, not user input
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			//nolint:gosec // intentional 0o644 for readable files
+			if err := os.WriteFile(path, changes[path], 0o644); err != nil {
+				return err
+			}
+			continue
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(original)),
+			B:        difflib.SplitLines(string(changes[path])),
+			FromFile: "a/" + diffPath(path),
+			ToFile:   "b/" + diffPath(path),
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(diffOut, diff)
+	}
+	return nil
+}
+
+// diffPath formats path for a diff header
+func diffPath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(path), "/")
+}
+
+// textEdit replaces src[start:end] with text
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits applies non-overlapping edits to src
+func applyEdits(src []byte, edits []textEdit) []byte {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var out []byte
+	last := 0
+	for _, e := range edits {
+		out = append(out, src[last:e.start]...)
+		out = append(out, e.text...)
+		last = e.end
+	}
+	return append(out, src[last:]...)
+}
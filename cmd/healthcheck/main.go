@@ -2,9 +2,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/healthcheck"
@@ -14,9 +21,72 @@ func main() {
 	// Parse command-line flags
 	format := flag.String("format", "human", "Output format: human or json")
 	advanced := flag.Bool("advanced", true, "Include Phase 2 advanced diagnostics")
+	timeout := flag.Duration("timeout", healthcheck.DefaultCheckTimeout, "Timeout for each check (overrides the config file)")
+	configPath := flag.String("config", "", "YAML file defining the checks (default: built-in checks)")
+	serve := flag.Bool("serve", false, "Serve /healthz and /metrics, re-running checks in the background")
+	addr := flag.String("addr", ":9091", "Listen address for -serve")
+	interval := flag.Duration("interval", healthcheck.DefaultExportInterval, "How often to re-run checks with -serve")
 	flag.Parse()
 
-	// Create health check runner
+	// Create health check runner from the config file, or the built-in defaults
+	var runner *healthcheck.Runner
+	if *configPath != "" {
+		cfg, err := healthcheck.LoadConfig(*configPath)
+		if err == nil {
+			runner, err = cfg.NewRunner(*advanced)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		runner = defaultRunner(*advanced)
+	}
+	if isFlagSet("timeout") {
+		runner.Timeout = *timeout
+	}
+
+	if *serve {
+		if err := serveExporter(runner, *addr, *interval); err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving health checks: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Run all checks
+	report := runner.Run()
+
+	// Format output
+	var output string
+	var err error
+
+	switch *format {
+	case "json":
+		output, err = healthcheck.FormatJSON(&report)
+	case "human":
+		output = healthcheck.FormatHuman(&report)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format: %s\n", *format)
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(output)
+
+	// Exit with appropriate code
+	if report.Status == healthcheck.StatusFail {
+		os.Exit(1)
+	}
+}
+
+// defaultRunner builds the checks for the local docker-compose deployment,
+// used when no config file is given.
+func defaultRunner(advanced bool) *healthcheck.Runner {
 	runner := healthcheck.NewRunner()
 
 	// Add Docker container checks
@@ -49,7 +119,7 @@ func main() {
 	})
 
 	// Phase 2: Advanced Diagnostics (optional)
-	if *advanced {
+	if advanced {
 		// Gateway routing validation
 		nginxConfig := os.Getenv("NGINX_CONFIG_PATH")
 		if nginxConfig == "" {
@@ -91,32 +161,45 @@ func main() {
 		})
 	}
 
-	// Run all checks
-	report := runner.Run()
+	return runner
+}
 
-	// Format output
-	var output string
-	var err error
+// serveExporter serves the latest report until SIGINT or SIGTERM.
+func serveExporter(runner *healthcheck.Runner, addr string, interval time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	switch *format {
-	case "json":
-		output, err = healthcheck.FormatJSON(&report)
-	case "human":
-		output = healthcheck.FormatHuman(&report)
-	default:
-		fmt.Fprintf(os.Stderr, "Unknown format: %s\n", *format)
-		os.Exit(1)
-	}
+	exporter := healthcheck.NewExporter(runner, interval)
+	go exporter.Start(ctx)
 
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error formatting output: %v\n", err)
-		os.Exit(1)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           exporter.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
 	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("warning: healthcheck server shutdown: %v", err)
+		}
+	}()
 
-	fmt.Println(output)
-
-	// Exit with appropriate code
-	if report.Status == healthcheck.StatusFail {
-		os.Exit(1)
+	log.Printf("Serving health checks on %s every %s (/healthz, /metrics)", addr, interval)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+	return nil
+}
+
+// isFlagSet reports whether the named flag was given on the command line.
+func isFlagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
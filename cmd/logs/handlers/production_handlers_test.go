@@ -187,37 +187,20 @@ func TestGetErrorTrends_Valid(t *testing.T) {
 	assert.NotNil(t, resp["trend"])
 }
 
-// TestExportLogs_JSON tests exporting logs as JSON
-func TestExportLogs_JSON(t *testing.T) {
+// TestExportLogs_NDJSON tests exporting logs as NDJSON
+func TestExportLogs_NDJSON(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
-	// Mock exporter would be provided here
-	router.GET("/api/logs/export", ExportLogs())
+	router.GET("/api/logs/export", ExportLogs(&MockLogService{}))
 
-	req := httptest.NewRequest("GET", "/api/logs/export?format=json&service=review", http.NoBody)
+	req := httptest.NewRequest("GET", "/api/logs/export?format=ndjson&service=review", http.NoBody)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
-}
-
-// TestExportLogs_CSV tests exporting logs as CSV
-func TestExportLogs_CSV(t *testing.T) {
-	gin.SetMode(gin.TestMode)
-	router := gin.New()
-
-	router.GET("/api/logs/export", ExportLogs())
-
-	req := httptest.NewRequest("GET", "/api/logs/export?format=csv&service=review", http.NoBody)
-	w := httptest.NewRecorder()
-
-	router.ServeHTTP(w, req)
-
-	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
 }
 
 // TestExportLogs_InvalidFormat tests export with invalid format
@@ -225,9 +208,9 @@ func TestExportLogs_InvalidFormat(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
-	router.GET("/api/logs/export", ExportLogs())
+	router.GET("/api/logs/export", ExportLogs(&MockLogService{}))
 
-	req := httptest.NewRequest("GET", "/api/logs/export?format=invalid", http.NoBody)
+	req := httptest.NewRequest("GET", "/api/logs/export?format=csv", http.NoBody)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
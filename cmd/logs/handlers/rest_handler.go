@@ -2,14 +2,21 @@ package cmd_logs_handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	logs_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/services"
 )
 
+// MatchSubstring is the match= value that makes q an ILIKE substring search.
+const MatchSubstring = "substring"
+
 // Pagination and query parameter constants
 const (
 	DefaultLimit     = 100
@@ -40,10 +47,34 @@ const (
 type LogService interface {
 	Insert(ctx context.Context, entry map[string]interface{}) (int64, error)
 	Query(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error)
-	GetByID(ctx context.Context, id int64) (interface{}, error)
-	Stats(ctx context.Context) (map[string]interface{}, error)
+	QueryCursor(ctx context.Context, filters map[string]interface{}, limit int, after string) ([]interface{}, *string, error)
+	FuzzyQuery(ctx context.Context, filters map[string]interface{}, limit int) ([]interface{}, []string, error)
+	Export(ctx context.Context, filters map[string]interface{}, w io.Writer) (int64, error)
+	GetByID(ctx context.Context, id int64, userID int) (interface{}, error)
+	GetContext(ctx context.Context, id int64, before, after int, sameProject bool, userID int) ([]interface{}, error)
+	Stats(ctx context.Context, userID int) (map[string]interface{}, error)
+	TimeSeries(ctx context.Context, q logs_models.TimeSeriesQuery, userID int) ([]logs_models.TimeSeriesPoint, error)
+	Fingerprints(ctx context.Context, q logs_models.FingerprintQuery, userID int) ([]logs_models.FingerprintGroup, error)
 	DeleteByID(ctx context.Context, id int64) error
-	Delete(ctx context.Context, filters map[string]interface{}) (int64, error)
+	Delete(ctx context.Context, req logs_services.DeleteLogsRequest) (int64, error)
+	Trash(ctx context.Context, userID, projectID int) ([]logs_models.TrashBatch, error)
+	Restore(ctx context.Context, userID, projectID int, deletedAt *time.Time) (int64, error)
+}
+
+// RetentionRunner runs a single log retention pass.
+type RetentionRunner interface {
+	RunOnce(ctx context.Context) (logs_services.RetentionResult, error)
+}
+
+// SeverityBackfiller starts the severity_score backfill in the background.
+type SeverityBackfiller interface {
+	Start(ctx context.Context) error
+}
+
+// DeadLetterReplayer reports on and replays batches that failed to insert.
+type DeadLetterReplayer interface {
+	Count(ctx context.Context) (int64, error)
+	Replay(ctx context.Context) (logs_services.DeadLetterReplayResult, error)
 }
 
 // AlertThresholdService defines the interface for alert threshold operations.
@@ -83,12 +114,30 @@ func parseFilters(c *gin.Context) map[string]interface{} {
 	if search := c.Query("search"); search != "" {
 		filters["search"] = search
 	}
+	if q := c.Query("q"); q != "" {
+		// match=substring trades ranking for an exact ILIKE substring match
+		if c.Query("match") == MatchSubstring {
+			filters["search"] = q
+		} else {
+			filters["q"] = q
+		}
+	}
 	if from := c.Query("from"); from != "" {
 		filters["from"] = from
 	}
 	if to := c.Query("to"); to != "" {
 		filters["to"] = to
 	}
+	if correlationID := c.Query("correlation_id"); correlationID != "" {
+		filters["correlation_id"] = correlationID
+	}
+	if contextFilters := c.QueryArray("context_filter"); len(contextFilters) > 0 {
+		filters["context_filter"] = contextFilters
+	}
+	// The session's user, which limits results to their projects
+	if userID := c.GetInt("user_id"); userID != 0 {
+		filters[logs_services.CallerFilterKey] = userID
+	}
 	return filters
 }
 
@@ -169,27 +218,233 @@ func PostLogs(svc LogService) gin.HandlerFunc {
 }
 
 // GetLogs handles GET /api/logs - query logs with filters.
+//
+// Pagination:
+//   - Cursor mode (preferred): pass after=<next_cursor> from the previous
+//     response (an empty after= starts at the newest entry). The response
+//     carries next_cursor, which is null once the results are exhausted.
+//   - Offset mode (deprecated): limit/offset. Large offsets force Postgres to
+//     scan and discard rows, so this mode degrades on big tables.
+//
+// When a search finds nothing, similar messages are returned instead with
+// "fuzzy": true and "did you mean" suggestions. Pass fuzzy=false to disable.
+//
+// q runs a ranked full-text search on the message: every word must appear,
+// in any order, and each entry carries a relevance "score". Offset-mode
+// results are ordered by score; cursor mode keeps recency order. Add
+// match=substring to match q as an exact substring instead (unranked).
+//
+// context_filter (repeatable) matches structured metadata, e.g.
+// context_filter=context.user_id=1000 or context_filter=context.status_code>=400.
+// Malformed expressions and unknown operators are rejected with 400.
+//
+// correlation_id returns the entries logged for one request, across services.
+//
+// For a signed-in user only entries of projects they own are returned,
+// unless they are an admin; unclaimed projects' logs are not listed.
 func GetLogs(svc LogService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if after, ok := c.GetQuery("after"); ok {
+			getLogsByCursor(c, svc, after)
+			return
+		}
+
 		limit, offset := parsePagination(c)
 		filters := parseFilters(c)
 		page := map[string]int{"limit": limit, "offset": offset}
+		if _, ok := c.GetQuery("offset"); ok {
+			c.Header("Deprecation", "true")
+		}
 
 		entries, err := svc.Query(c.Request.Context(), filters, page)
 		if err != nil {
+			if errors.Is(err, logs_services.ErrInvalidContextFilter) {
+				respondError(c, http.StatusBadRequest, "invalid context_filter", err.Error())
+				return
+			}
 			respondInternalError(c, "failed to query logs", err)
 			return
 		}
 
+		fuzzy := false
+		suggestions := []string{}
+		if len(entries) == 0 && offset == 0 && filters["search"] != nil && c.DefaultQuery("fuzzy", "true") != "false" {
+			// Fallback is best-effort: on failure keep the empty exact result
+			if fuzzyEntries, fuzzySuggestions, fuzzyErr := svc.FuzzyQuery(c.Request.Context(), filters, limit); fuzzyErr == nil {
+				fuzzy = len(fuzzyEntries) > 0
+				if fuzzy {
+					entries = fuzzyEntries
+				}
+				suggestions = fuzzySuggestions
+			}
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"entries": entries,
-			"count":   len(entries),
-			"limit":   limit,
-			"offset":  offset,
+			"entries":     entries,
+			"count":       len(entries),
+			"limit":       limit,
+			"offset":      offset,
+			"fuzzy":       fuzzy,
+			"suggestions": suggestions,
 		})
 	}
 }
 
+// getLogsByCursor serves GET /api/logs in keyset pagination mode.
+func getLogsByCursor(c *gin.Context, svc LogService, after string) {
+	limit, _ := parsePagination(c)
+	filters := parseFilters(c)
+
+	entries, nextCursor, err := svc.QueryCursor(c.Request.Context(), filters, limit, after)
+	if err != nil {
+		if errors.Is(err, logs_services.ErrInvalidCursor) {
+			respondBadRequest(c, "invalid cursor")
+			return
+		}
+		if errors.Is(err, logs_services.ErrInvalidContextFilter) {
+			respondError(c, http.StatusBadRequest, "invalid context_filter", err.Error())
+			return
+		}
+		respondInternalError(c, "failed to query logs", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries":     entries,
+		"count":       len(entries),
+		"limit":       limit,
+		"next_cursor": nextCursor,
+	})
+}
+
+// ExportLogs handles GET /api/logs/export - stream matching logs as NDJSON.
+// It accepts the same filters as GetLogs but has no pagination: every match
+// is written as one JSON object per line while rows are read from the
+// database, so memory use stays flat regardless of result size.
+func ExportLogs(svc LogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if format := c.DefaultQuery("format", "ndjson"); format != "ndjson" {
+			respondBadRequest(c, "format must be ndjson")
+			return
+		}
+
+		filename := fmt.Sprintf("logs-export-%s.ndjson", time.Now().UTC().Format("20060102-150405"))
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Header("Cache-Control", "no-cache")
+
+		// Large exports outlive the server's WriteTimeout; lift it for this
+		// response only. Unsupported writers (e.g. in tests) just keep theirs.
+		_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+		written, err := svc.Export(c.Request.Context(), parseFilters(c), c.Writer)
+		if err == nil {
+			return
+		}
+
+		if written == 0 && !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Del("Content-Type")
+			if errors.Is(err, logs_services.ErrInvalidContextFilter) {
+				respondError(c, http.StatusBadRequest, "invalid context_filter", err.Error())
+				return
+			}
+			respondInternalError(c, "failed to export logs", err)
+			return
+		}
+
+		// Headers and part of the body are already sent; all we can do is
+		// record the failure (usually the client went away mid-stream)
+		_ = c.Error(err)
+	}
+}
+
+// RequireAdmin only lets admins through; it must run after the session
+// middleware. Everyone else gets a 403.
+func RequireAdmin(admins logs_services.Admins) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !admins.Contains(c.GetInt("user_id")) {
+			respondError(c, http.StatusForbidden, "admin access required", "")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RunRetention handles POST /api/logs/retention/run - purge expired logs now
+// instead of waiting for the next scheduled run.
+func RunRetention(job RetentionRunner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := job.RunOnce(c.Request.Context())
+		if err != nil {
+			if errors.Is(err, logs_services.ErrRetentionRunning) {
+				respondError(c, http.StatusConflict, "retention run already in progress", "")
+				return
+			}
+			respondInternalError(c, "retention run failed", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"deleted":     result.Deleted,
+			"projects":    result.Projects,
+			"started_at":  result.StartedAt,
+			"duration_ms": result.Duration.Milliseconds(),
+		})
+	}
+}
+
+// BackfillSeverity handles POST /api/logs/admin/backfill-severity - score
+// logs that have no severity_score. The backfill runs in the background and
+// resumes from its checkpoint, so it's safe to trigger again.
+func BackfillSeverity(job SeverityBackfiller) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// The run outlives the request
+		err := job.Start(context.WithoutCancel(c.Request.Context()))
+		if errors.Is(err, logs_services.ErrSeverityBackfillRunning) {
+			respondError(c, http.StatusConflict, "severity backfill already in progress", "")
+			return
+		}
+		if err != nil {
+			respondInternalError(c, "failed to start severity backfill", err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"status": "started"})
+	}
+}
+
+// GetDeadLetter handles GET /api/logs/dead-letter - how many failed batches
+// are waiting to be replayed.
+func GetDeadLetter(queue DeadLetterReplayer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		count, err := queue.Count(c.Request.Context())
+		if err != nil {
+			respondInternalError(c, "failed to read dead-letter queue", err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"count": count})
+	}
+}
+
+// ReplayDeadLetter handles POST /api/logs/dead-letter/replay - replay failed
+// batches now instead of waiting for the background worker.
+func ReplayDeadLetter(queue DeadLetterReplayer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := queue.Replay(c.Request.Context())
+		if err != nil {
+			if errors.Is(err, logs_services.ErrDeadLetterReplayRunning) {
+				respondError(c, http.StatusConflict, "dead-letter replay already in progress", "")
+				return
+			}
+			respondError(c, http.StatusServiceUnavailable, "dead-letter replay stopped early", err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
 // GetLogByID handles GET /api/logs/:id - get single log entry.
 func GetLogByID(svc LogService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -199,7 +454,7 @@ func GetLogByID(svc LogService) gin.HandlerFunc {
 			return
 		}
 
-		entry, err := svc.GetByID(c.Request.Context(), id)
+		entry, err := svc.GetByID(c.Request.Context(), id, c.GetInt("user_id"))
 		if err != nil {
 			respondError(c, http.StatusNotFound, "entry not found", "")
 			return
@@ -209,10 +464,65 @@ func GetLogByID(svc LogService) gin.HandlerFunc {
 	}
 }
 
+// GetLogContext handles GET /api/logs/:id/context - a log entry with the
+// entries logged around it by the same service.
+// Query params: before, after (neighbors on each side, default 10, max 100)
+// and same_project=true to stay within the entry's project.
+func GetLogContext(svc LogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			respondBadRequest(c, "invalid id format")
+			return
+		}
+
+		before, ok := parseNeighborCount(c, "before")
+		if !ok {
+			return
+		}
+		after, ok := parseNeighborCount(c, "after")
+		if !ok {
+			return
+		}
+		sameProject := c.Query("same_project") == "true"
+
+		entries, err := svc.GetContext(c.Request.Context(), id, before, after, sameProject, c.GetInt("user_id"))
+		if errors.Is(err, logs_services.ErrLogNotFound) {
+			respondError(c, http.StatusNotFound, "entry not found", "")
+			return
+		}
+		if err != nil {
+			respondInternalError(c, "failed to get log context", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"id":      id,
+			"entries": entries,
+			"count":   len(entries),
+		})
+	}
+}
+
+// parseNeighborCount reads a before/after query parameter, responding 400
+// and returning false if it is not an integer in [0, MaxContextNeighbors].
+func parseNeighborCount(c *gin.Context, name string) (int, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return logs_services.DefaultContextNeighbors, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 || n > logs_services.MaxContextNeighbors {
+		respondBadRequest(c, fmt.Sprintf("%s must be between 0 and %d", name, logs_services.MaxContextNeighbors))
+		return 0, false
+	}
+	return n, true
+}
+
 // GetStats handles GET /api/logs/stats - aggregated statistics.
 func GetStats(svc LogService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		stats, err := svc.Stats(c.Request.Context())
+		stats, err := svc.Stats(c.Request.Context(), c.GetInt("user_id"))
 		if err != nil {
 			respondInternalError(c, "failed to retrieve statistics", err)
 			return
@@ -222,25 +532,234 @@ func GetStats(svc LogService) gin.HandlerFunc {
 	}
 }
 
-// DeleteLogs handles DELETE /api/logs - bulk delete old logs.
+// GetStatsTimeSeries handles GET /api/logs/stats/timeseries - log counts
+// bucketed over time for charting, with empty buckets reported as zero.
+// Query params: interval (minute, hour or day; default hour), from and to
+// (RFC3339; default the last 24 hours) and group_by (level or service).
+func GetStatsTimeSeries(svc LogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		q := logs_models.TimeSeriesQuery{
+			Interval: c.Query("interval"),
+			GroupBy:  c.Query("group_by"),
+		}
+		for _, param := range []struct {
+			name string
+			dst  *time.Time
+		}{{"from", &q.From}, {"to", &q.To}} {
+			raw := c.Query(param.name)
+			if raw == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				respondBadRequest(c, param.name+" must be an RFC3339 timestamp")
+				return
+			}
+			*param.dst = t
+		}
+
+		points, err := svc.TimeSeries(c.Request.Context(), q, c.GetInt("user_id"))
+		if errors.Is(err, logs_services.ErrInvalidTimeSeries) {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if err != nil {
+			respondInternalError(c, "failed to retrieve time series", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, points)
+	}
+}
+
+// GetFingerprints handles GET /api/logs/fingerprints - entries grouped by
+// the fingerprint of their normalized message, so near-duplicate errors that
+// differ only by ids or numbers count as one. Each group has its count, first
+// and last seen times and the most recent message. Query params: from and to
+// (RFC3339; default the last 24 hours), level, service and limit (default 50).
+func GetFingerprints(svc LogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		q := logs_models.FingerprintQuery{
+			Level:   c.Query("level"),
+			Service: c.Query("service"),
+		}
+		for _, param := range []struct {
+			name string
+			dst  *time.Time
+		}{{"from", &q.From}, {"to", &q.To}} {
+			raw := c.Query(param.name)
+			if raw == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				respondBadRequest(c, param.name+" must be an RFC3339 timestamp")
+				return
+			}
+			*param.dst = t
+		}
+		if raw := c.Query("limit"); raw != "" {
+			limit, err := strconv.Atoi(raw)
+			if err != nil {
+				respondBadRequest(c, "limit must be an integer")
+				return
+			}
+			q.Limit = limit
+		}
+
+		groups, err := svc.Fingerprints(c.Request.Context(), q, c.GetInt("user_id"))
+		if errors.Is(err, logs_services.ErrInvalidFingerprintQuery) {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if err != nil {
+			respondInternalError(c, "failed to retrieve fingerprints", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, groups)
+	}
+}
+
+// DeleteLogs handles DELETE /api/logs - bulk delete a project's logs.
+// The body holds project_id, which must belong to the session's user, and
+// the filters selecting what to delete (service, level, search, q,
+// correlation_id, from, to). A body with no filters is refused unless
+// ?confirm=all is sent; ?dry_run=true returns the count that would be
+// deleted without deleting anything. Deleted entries go to the trash and
+// can be restored until the grace period elapses.
 func DeleteLogs(svc LogService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		userID := c.GetInt("user_id")
+		if userID == 0 {
+			respondError(c, http.StatusUnauthorized, "authentication required", "")
+			return
+		}
+
 		var req map[string]interface{}
 		if err := c.ShouldBindJSON(&req); err != nil {
 			respondBadRequest(c, "invalid request body")
 			return
 		}
 
-		count, err := svc.Delete(c.Request.Context(), req)
-		if err != nil {
+		deleteReq := logs_services.DeleteLogsRequest{
+			Filters:    req,
+			UserID:     userID,
+			ConfirmAll: c.Query("confirm") == "all",
+		}
+		if raw, present := req["project_id"]; present {
+			id, isNumber := raw.(float64)
+			if !isNumber || id != float64(int(id)) {
+				respondBadRequest(c, "project_id must be an integer")
+				return
+			}
+			deleteReq.ProjectID = int(id)
+			delete(req, "project_id")
+		}
+		if raw := c.Query("dry_run"); raw != "" {
+			dryRun, err := strconv.ParseBool(raw)
+			if err != nil {
+				respondBadRequest(c, "dry_run must be true or false")
+				return
+			}
+			deleteReq.DryRun = dryRun
+		}
+
+		count, err := svc.Delete(c.Request.Context(), deleteReq)
+		switch {
+		case errors.Is(err, logs_services.ErrDeleteForbidden):
+			respondError(c, http.StatusForbidden, "project not found or not yours", "")
+			return
+		case errors.Is(err, logs_services.ErrUnfilteredDelete), errors.Is(err, logs_services.ErrInvalidDelete):
+			respondBadRequest(c, err.Error())
+			return
+		case err != nil:
 			respondInternalError(c, "failed to delete logs", err)
 			return
 		}
 
+		if deleteReq.DryRun {
+			c.JSON(http.StatusOK, gin.H{"would_delete": count, "dry_run": true})
+			return
+		}
 		c.JSON(http.StatusOK, gin.H{"deleted": count, "timestamp": time.Now()})
 	}
 }
 
+// GetTrash handles GET /api/logs/trash?project_id= - lists the deletions of
+// one of the session user's projects that can still be restored.
+func GetTrash(svc LogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetInt("user_id")
+		if userID == 0 {
+			respondError(c, http.StatusUnauthorized, "authentication required", "")
+			return
+		}
+
+		projectID, err := strconv.Atoi(c.Query("project_id"))
+		if err != nil || projectID <= 0 {
+			respondBadRequest(c, "project_id must be a positive integer")
+			return
+		}
+
+		batches, err := svc.Trash(c.Request.Context(), userID, projectID)
+		switch {
+		case errors.Is(err, logs_services.ErrDeleteForbidden):
+			respondError(c, http.StatusForbidden, "project not found or not yours", "")
+			return
+		case err != nil:
+			respondInternalError(c, "failed to list deleted logs", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"project_id": projectID, "deletions": batches, "count": len(batches)})
+	}
+}
+
+// restoreRequest is the body of POST /api/logs/trash/restore.
+type restoreRequest struct {
+	DeletedAt *time.Time `json:"deleted_at"` // One deletion from GET /api/logs/trash; all when omitted
+	ProjectID int        `json:"project_id"`
+}
+
+// RestoreTrash handles POST /api/logs/trash/restore - restores one of the
+// session user's projects' deleted entries that are still within the grace
+// period.
+func RestoreTrash(svc LogService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetInt("user_id")
+		if userID == 0 {
+			respondError(c, http.StatusUnauthorized, "authentication required", "")
+			return
+		}
+
+		var req restoreRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, "invalid request body")
+			return
+		}
+		if req.ProjectID <= 0 {
+			respondBadRequest(c, "project_id must be a positive integer")
+			return
+		}
+
+		restored, err := svc.Restore(c.Request.Context(), userID, req.ProjectID, req.DeletedAt)
+		switch {
+		case errors.Is(err, logs_services.ErrDeleteForbidden):
+			respondError(c, http.StatusForbidden, "project not found or not yours", "")
+			return
+		case errors.Is(err, logs_services.ErrNothingToRestore):
+			respondError(c, http.StatusNotFound, "nothing to restore", "deleted logs are purged once the grace period elapses")
+			return
+		case err != nil:
+			respondInternalError(c, "failed to restore logs", err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"restored": restored})
+	}
+}
+
 // ValidationAggregationInterface defines the interface for validation error aggregation.
 type ValidationAggregationInterface interface {
 	GetTopErrors(ctx context.Context, service string, limit int, days int) ([]logs_models.ValidationError, error)
@@ -346,29 +865,6 @@ func GetErrorTrends(agg ValidationAggregationInterface) gin.HandlerFunc {
 	}
 }
 
-// ExportLogs handles GET /api/logs/export - exports logs as JSON or CSV.
-func ExportLogs() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		format := c.DefaultQuery("format", "json")
-		if format != "json" && format != "csv" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
-			return
-		}
-
-		service := c.DefaultQuery("service", "")
-		errorType := c.DefaultQuery("error_type", "")
-
-		// Placeholder: In real implementation, would fetch and format logs
-		if format == "json" {
-			c.Header("Content-Type", "application/json")
-			c.JSON(http.StatusOK, gin.H{"logs": []interface{}{}, "service": service, "error_type": errorType})
-		} else {
-			c.Header("Content-Type", "text/csv")
-			c.String(http.StatusOK, "id,service,level,message,timestamp\n")
-		}
-	}
-}
-
 // CreateAlertConfig handles POST /api/logs/alert-config - creates alert configuration.
 func CreateAlertConfig(svc AlertThresholdService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -497,6 +993,12 @@ func RegisterRestRoutes(router *gin.Engine, svc LogService) {
 	// GET /api/logs/stats - get aggregated statistics
 	router.GET("/api/logs/stats", GetStats(svc))
 
-	// DELETE /api/logs - bulk delete logs by filters
+	// GET /api/logs/stats/timeseries - bucketed log counts for charts
+	router.GET("/api/logs/stats/timeseries", GetStatsTimeSeries(svc))
+	router.GET("/api/logs/fingerprints", GetFingerprints(svc))
+
+	// DELETE /api/logs - bulk delete logs by filters, into the trash
 	router.DELETE("/api/logs", DeleteLogs(svc))
+	router.GET("/api/logs/trash", GetTrash(svc))
+	router.POST("/api/logs/trash/restore", RestoreTrash(svc))
 }
@@ -3,23 +3,42 @@ package cmd_logs_handlers
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	logs_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/services"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // nolint:dupl // MockLogService implements LogService interface - dupl is expected
 type MockLogService struct {
-	InsertFn     func(ctx context.Context, entry map[string]interface{}) (int64, error)
-	QueryFn      func(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error)
-	GetByIDFn    func(ctx context.Context, id int64) (interface{}, error)
-	StatsFn      func(ctx context.Context) (map[string]interface{}, error)
-	DeleteByIDFn func(ctx context.Context, id int64) error
-	DeleteFn     func(ctx context.Context, filters map[string]interface{}) (int64, error)
+	InsertFn       func(ctx context.Context, entry map[string]interface{}) (int64, error)
+	QueryFn        func(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error)
+	QueryCursorFn  func(ctx context.Context, filters map[string]interface{}, limit int, after string) ([]interface{}, *string, error)
+	FuzzyQueryFn   func(ctx context.Context, filters map[string]interface{}, limit int) ([]interface{}, []string, error)
+	ExportFn       func(ctx context.Context, filters map[string]interface{}, w io.Writer) (int64, error)
+	GetByIDFn      func(ctx context.Context, id int64, userID int) (interface{}, error)
+	GetContextFn   func(ctx context.Context, id int64, before, after int, sameProject bool, userID int) ([]interface{}, error)
+	StatsFn        func(ctx context.Context, userID int) (map[string]interface{}, error)
+	TimeSeriesFn   func(ctx context.Context, q logs_models.TimeSeriesQuery, userID int) ([]logs_models.TimeSeriesPoint, error)
+	FingerprintsFn func(ctx context.Context, q logs_models.FingerprintQuery, userID int) ([]logs_models.FingerprintGroup, error)
+	DeleteByIDFn   func(ctx context.Context, id int64) error
+	DeleteFn       func(ctx context.Context, req logs_services.DeleteLogsRequest) (int64, error)
+	TrashFn        func(ctx context.Context, userID, projectID int) ([]logs_models.TrashBatch, error)
+	RestoreFn      func(ctx context.Context, userID, projectID int, deletedAt *time.Time) (int64, error)
 }
 
 func (m *MockLogService) Insert(ctx context.Context, entry map[string]interface{}) (int64, error) {
@@ -36,20 +55,62 @@ func (m *MockLogService) Query(ctx context.Context, filters map[string]interface
 	return []interface{}{}, nil
 }
 
-func (m *MockLogService) GetByID(ctx context.Context, id int64) (interface{}, error) {
+func (m *MockLogService) QueryCursor(ctx context.Context, filters map[string]interface{}, limit int, after string) ([]interface{}, *string, error) {
+	if m.QueryCursorFn != nil {
+		return m.QueryCursorFn(ctx, filters, limit, after)
+	}
+	return []interface{}{}, nil, nil
+}
+
+func (m *MockLogService) FuzzyQuery(ctx context.Context, filters map[string]interface{}, limit int) ([]interface{}, []string, error) {
+	if m.FuzzyQueryFn != nil {
+		return m.FuzzyQueryFn(ctx, filters, limit)
+	}
+	return []interface{}{}, []string{}, nil
+}
+
+func (m *MockLogService) Export(ctx context.Context, filters map[string]interface{}, w io.Writer) (int64, error) {
+	if m.ExportFn != nil {
+		return m.ExportFn(ctx, filters, w)
+	}
+	return 0, nil
+}
+
+func (m *MockLogService) GetByID(ctx context.Context, id int64, userID int) (interface{}, error) {
 	if m.GetByIDFn != nil {
-		return m.GetByIDFn(ctx, id)
+		return m.GetByIDFn(ctx, id, userID)
 	}
 	return nil, nil
 }
 
-func (m *MockLogService) Stats(ctx context.Context) (map[string]interface{}, error) {
+func (m *MockLogService) GetContext(ctx context.Context, id int64, before, after int, sameProject bool, userID int) ([]interface{}, error) {
+	if m.GetContextFn != nil {
+		return m.GetContextFn(ctx, id, before, after, sameProject, userID)
+	}
+	return []interface{}{}, nil
+}
+
+func (m *MockLogService) Stats(ctx context.Context, userID int) (map[string]interface{}, error) {
 	if m.StatsFn != nil {
-		return m.StatsFn(ctx)
+		return m.StatsFn(ctx, userID)
 	}
 	return map[string]interface{}{}, nil
 }
 
+func (m *MockLogService) TimeSeries(ctx context.Context, q logs_models.TimeSeriesQuery, userID int) ([]logs_models.TimeSeriesPoint, error) {
+	if m.TimeSeriesFn != nil {
+		return m.TimeSeriesFn(ctx, q, userID)
+	}
+	return []logs_models.TimeSeriesPoint{}, nil
+}
+
+func (m *MockLogService) Fingerprints(ctx context.Context, q logs_models.FingerprintQuery, userID int) ([]logs_models.FingerprintGroup, error) {
+	if m.FingerprintsFn != nil {
+		return m.FingerprintsFn(ctx, q, userID)
+	}
+	return []logs_models.FingerprintGroup{}, nil
+}
+
 func (m *MockLogService) DeleteByID(ctx context.Context, id int64) error {
 	if m.DeleteByIDFn != nil {
 		return m.DeleteByIDFn(ctx, id)
@@ -57,9 +118,23 @@ func (m *MockLogService) DeleteByID(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (m *MockLogService) Delete(ctx context.Context, filters map[string]interface{}) (int64, error) {
+func (m *MockLogService) Delete(ctx context.Context, req logs_services.DeleteLogsRequest) (int64, error) {
 	if m.DeleteFn != nil {
-		return m.DeleteFn(ctx, filters)
+		return m.DeleteFn(ctx, req)
+	}
+	return 0, nil
+}
+
+func (m *MockLogService) Trash(ctx context.Context, userID, projectID int) ([]logs_models.TrashBatch, error) {
+	if m.TrashFn != nil {
+		return m.TrashFn(ctx, userID, projectID)
+	}
+	return []logs_models.TrashBatch{}, nil
+}
+
+func (m *MockLogService) Restore(ctx context.Context, userID, projectID int, deletedAt *time.Time) (int64, error) {
+	if m.RestoreFn != nil {
+		return m.RestoreFn(ctx, userID, projectID, deletedAt)
 	}
 	return 0, nil
 }
@@ -146,7 +221,7 @@ func TestGetLogByID_Valid(t *testing.T) {
 	router := gin.New()
 
 	mockSvc := &MockLogService{
-		GetByIDFn: func(ctx context.Context, id int64) (interface{}, error) {
+		GetByIDFn: func(ctx context.Context, id int64, userID int) (interface{}, error) {
 			return map[string]interface{}{
 				"id": id, "service": "portal", "level": "info", "message": "test",
 			}, nil
@@ -168,7 +243,7 @@ func TestGetStats_Valid(t *testing.T) {
 	router := gin.New()
 
 	mockSvc := &MockLogService{
-		StatsFn: func(ctx context.Context) (map[string]interface{}, error) {
+		StatsFn: func(ctx context.Context, userID int) (map[string]interface{}, error) {
 			return map[string]interface{}{
 				"total": 100, "by_level": map[string]int{"info": 50, "error": 50},
 			}, nil
@@ -189,15 +264,17 @@ func TestDeleteLogs_Valid(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
+	var got logs_services.DeleteLogsRequest
 	mockSvc := &MockLogService{
-		DeleteFn: func(ctx context.Context, filters map[string]interface{}) (int64, error) {
+		DeleteFn: func(ctx context.Context, req logs_services.DeleteLogsRequest) (int64, error) {
+			got = req
 			return 25, nil
 		},
 	}
 
-	router.DELETE("/api/logs", DeleteLogs(mockSvc))
+	router.DELETE("/api/logs", withUser(7), DeleteLogs(mockSvc))
 
-	body := map[string]interface{}{"before": "2025-01-01"}
+	body := map[string]interface{}{"project_id": 3, "to": "2025-01-01T00:00:00Z"}
 	bodyBytes, _ := json.Marshal(body)
 
 	req := httptest.NewRequest("DELETE", "/api/logs", bytes.NewReader(bodyBytes))
@@ -207,6 +284,91 @@ func TestDeleteLogs_Valid(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deleted":25`)
+	assert.Equal(t, logs_services.DeleteLogsRequest{
+		Filters:   map[string]interface{}{"to": "2025-01-01T00:00:00Z"},
+		ProjectID: 3,
+		UserID:    7,
+	}, got)
+}
+
+// withUser sets the session's user, as RedisSessionAuthMiddleware does.
+func withUser(userID int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user_id", userID)
+	}
+}
+
+func serveDelete(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("DELETE", path, bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestDeleteLogs_UnfilteredRequiresConfirmation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var confirmed []bool
+	router.DELETE("/api/logs", withUser(7), DeleteLogs(&MockLogService{
+		DeleteFn: func(ctx context.Context, req logs_services.DeleteLogsRequest) (int64, error) {
+			confirmed = append(confirmed, req.ConfirmAll)
+			if len(req.Filters) == 0 && !req.ConfirmAll {
+				return 0, logs_services.ErrUnfilteredDelete
+			}
+			return 120, nil
+		},
+	}))
+
+	w := serveDelete(router, "/api/logs", `{"project_id": 3}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "confirm=all")
+
+	w = serveDelete(router, "/api/logs?confirm=all", `{"project_id": 3}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deleted":120`)
+	assert.Equal(t, []bool{false, true}, confirmed)
+}
+
+func TestDeleteLogs_DryRun(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.DELETE("/api/logs", withUser(7), DeleteLogs(&MockLogService{
+		DeleteFn: func(ctx context.Context, req logs_services.DeleteLogsRequest) (int64, error) {
+			assert.True(t, req.DryRun)
+			return 42, nil
+		},
+	}))
+
+	w := serveDelete(router, "/api/logs?dry_run=true", `{"project_id": 3, "level": "DEBUG"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"would_delete": 42, "dry_run": true}`, w.Body.String())
+
+	w = serveDelete(router, "/api/logs?dry_run=maybe", `{"project_id": 3, "level": "DEBUG"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDeleteLogs_RequiresOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := &MockLogService{
+		DeleteFn: func(ctx context.Context, req logs_services.DeleteLogsRequest) (int64, error) {
+			return 0, logs_services.ErrDeleteForbidden
+		},
+	}
+
+	router := gin.New()
+	router.DELETE("/api/logs", DeleteLogs(mockSvc))
+	w := serveDelete(router, "/api/logs", `{"project_id": 3, "level": "DEBUG"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "no session")
+
+	router = gin.New()
+	router.DELETE("/api/logs", withUser(8), DeleteLogs(mockSvc))
+	w = serveDelete(router, "/api/logs", `{"project_id": 3, "level": "DEBUG"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serveDelete(router, "/api/logs", `{"project_id": "three", "level": "DEBUG"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
 func TestPostLogs_InvalidJSON(t *testing.T) {
@@ -313,7 +475,7 @@ func TestGetLogByID_NotFound(t *testing.T) {
 	router := gin.New()
 
 	mockSvc := &MockLogService{
-		GetByIDFn: func(ctx context.Context, id int64) (interface{}, error) {
+		GetByIDFn: func(ctx context.Context, id int64, userID int) (interface{}, error) {
 			return nil, assert.AnError
 		},
 	}
@@ -333,7 +495,7 @@ func TestGetStats_Error(t *testing.T) {
 	router := gin.New()
 
 	mockSvc := &MockLogService{
-		StatsFn: func(ctx context.Context) (map[string]interface{}, error) {
+		StatsFn: func(ctx context.Context, userID int) (map[string]interface{}, error) {
 			return nil, assert.AnError
 		},
 	}
@@ -353,7 +515,7 @@ func TestDeleteLogs_InvalidJSON(t *testing.T) {
 	router := gin.New()
 	mockSvc := &MockLogService{}
 
-	router.DELETE("/api/logs", DeleteLogs(mockSvc))
+	router.DELETE("/api/logs", withUser(7), DeleteLogs(mockSvc))
 
 	req := httptest.NewRequest("DELETE", "/api/logs", bytes.NewReader([]byte("invalid")))
 	req.Header.Set("Content-Type", "application/json")
@@ -369,14 +531,14 @@ func TestDeleteLogs_Error(t *testing.T) {
 	router := gin.New()
 
 	mockSvc := &MockLogService{
-		DeleteFn: func(ctx context.Context, filters map[string]interface{}) (int64, error) {
+		DeleteFn: func(ctx context.Context, req logs_services.DeleteLogsRequest) (int64, error) {
 			return 0, assert.AnError
 		},
 	}
 
-	router.DELETE("/api/logs", DeleteLogs(mockSvc))
+	router.DELETE("/api/logs", withUser(7), DeleteLogs(mockSvc))
 
-	body := map[string]interface{}{"before": "2025-01-01"}
+	body := map[string]interface{}{"project_id": 3, "to": "2025-01-01T00:00:00Z"}
 	bodyBytes, _ := json.Marshal(body)
 
 	req := httptest.NewRequest("DELETE", "/api/logs", bytes.NewReader(bodyBytes))
@@ -387,3 +549,926 @@ func TestDeleteLogs_Error(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestGetLogs_FuzzyFallbackOnEmptySearch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	mockSvc := &MockLogService{
+		QueryFn: func(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error) {
+			return []interface{}{}, nil
+		},
+		FuzzyQueryFn: func(ctx context.Context, filters map[string]interface{}, limit int) ([]interface{}, []string, error) {
+			assert.Equal(t, "conection", filters["search"])
+			return []interface{}{
+				map[string]interface{}{"id": 7, "message": "database connection failed", "fuzzy": true, "similarity": 0.8},
+			}, []string{"connection"}, nil
+		},
+	}
+
+	router.GET("/api/logs", GetLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs?search=conection", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, true, resp["fuzzy"])
+	assert.Equal(t, float64(1), resp["count"])
+	assert.Equal(t, []interface{}{"connection"}, resp["suggestions"])
+	entry := resp["entries"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, true, entry["fuzzy"])
+	assert.Equal(t, 0.8, entry["similarity"])
+}
+
+func TestGetLogs_FuzzyFallbackDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	fuzzyCalled := false
+	mockSvc := &MockLogService{
+		FuzzyQueryFn: func(ctx context.Context, filters map[string]interface{}, limit int) ([]interface{}, []string, error) {
+			fuzzyCalled = true
+			return nil, nil, nil
+		},
+	}
+
+	router.GET("/api/logs", GetLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs?search=conection&fuzzy=false", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, fuzzyCalled)
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, false, resp["fuzzy"])
+}
+
+func TestGetLogs_NoFuzzyFallbackWhenExactMatches(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	fuzzyCalled := false
+	mockSvc := &MockLogService{
+		QueryFn: func(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error) {
+			return []interface{}{map[string]interface{}{"id": 1, "message": "connection reset"}}, nil
+		},
+		FuzzyQueryFn: func(ctx context.Context, filters map[string]interface{}, limit int) ([]interface{}, []string, error) {
+			fuzzyCalled = true
+			return nil, nil, nil
+		},
+	}
+
+	router.GET("/api/logs", GetLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs?search=connection", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, fuzzyCalled)
+}
+
+func TestGetLogs_CursorMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	next := "bmV4dA"
+	mockSvc := &MockLogService{
+		QueryFn: func(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error) {
+			t.Error("offset query should not be used in cursor mode")
+			return nil, nil
+		},
+		QueryCursorFn: func(ctx context.Context, filters map[string]interface{}, limit int, after string) ([]interface{}, *string, error) {
+			assert.Equal(t, "abc", after)
+			assert.Equal(t, 2, limit)
+			assert.Equal(t, "error", filters["level"])
+			return []interface{}{
+				map[string]interface{}{"id": 9}, map[string]interface{}{"id": 8},
+			}, &next, nil
+		},
+	}
+
+	router.GET("/api/logs", GetLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs?after=abc&limit=2&level=error", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, next, resp["next_cursor"])
+	assert.Equal(t, float64(2), resp["count"])
+}
+
+func TestGetLogs_CursorExhausted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	mockSvc := &MockLogService{
+		QueryCursorFn: func(ctx context.Context, filters map[string]interface{}, limit int, after string) ([]interface{}, *string, error) {
+			assert.Equal(t, "", after)
+			return []interface{}{map[string]interface{}{"id": 1}}, nil, nil
+		},
+	}
+
+	router.GET("/api/logs", GetLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs?after=", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	next, ok := resp["next_cursor"]
+	assert.True(t, ok)
+	assert.Nil(t, next)
+}
+
+func TestGetLogs_InvalidCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	mockSvc := &MockLogService{
+		QueryCursorFn: func(ctx context.Context, filters map[string]interface{}, limit int, after string) ([]interface{}, *string, error) {
+			return nil, nil, fmt.Errorf("%w: bad token", logs_services.ErrInvalidCursor)
+		},
+	}
+
+	router.GET("/api/logs", GetLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs?after=not-a-cursor", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetLogs_OffsetModeMarkedDeprecated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	mockSvc := &MockLogService{}
+
+	router.GET("/api/logs", GetLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs?limit=10&offset=20", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+}
+
+func TestGetLogs_PassesContextFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var got interface{}
+	mockSvc := &MockLogService{
+		QueryFn: func(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error) {
+			got = filters["context_filter"]
+			return []interface{}{}, nil
+		},
+	}
+
+	router.GET("/api/logs", GetLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs?context_filter=context.user_id%3D1000&context_filter=context.status_code%3E%3D400", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"context.user_id=1000", "context.status_code>=400"}, got)
+}
+
+func TestGetLogs_PassesCorrelationID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var got interface{}
+	mockSvc := &MockLogService{
+		QueryFn: func(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error) {
+			got = filters["correlation_id"]
+			return []interface{}{}, nil
+		},
+	}
+
+	router.GET("/api/logs", GetLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs?correlation_id=req-42", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "req-42", got)
+}
+
+func TestGetLogs_InvalidContextFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	mockSvc := &MockLogService{
+		QueryFn: func(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error) {
+			return nil, fmt.Errorf("%w: unknown operator \"~\"", logs_services.ErrInvalidContextFilter)
+		},
+	}
+
+	router.GET("/api/logs", GetLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs?context_filter=context.status_code~400", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "unknown operator")
+}
+
+func TestGetLogs_FullTextQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantQ      interface{}
+		wantSearch interface{}
+	}{
+		{name: "ranked by default", query: "q=connection+timeout", wantQ: "connection timeout"},
+		{name: "substring match", query: "q=connection+timeout&match=substring", wantSearch: "connection timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]interface{}
+			mockSvc := &MockLogService{
+				QueryFn: func(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error) {
+					got = filters
+					return []interface{}{map[string]interface{}{"id": int64(1), "score": 0.6}}, nil
+				},
+			}
+
+			router := gin.New()
+			router.GET("/api/logs", GetLogs(mockSvc))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/api/logs?"+tt.query, http.NoBody))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantQ, got["q"])
+			assert.Equal(t, tt.wantSearch, got["search"])
+		})
+	}
+}
+
+func TestExportLogs_StreamsNDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var gotFilters map[string]interface{}
+	mockSvc := &MockLogService{
+		ExportFn: func(ctx context.Context, filters map[string]interface{}, w io.Writer) (int64, error) {
+			gotFilters = filters
+			_, err := io.WriteString(w, "{\"id\":2}\n{\"id\":1}\n")
+			return 2, err
+		},
+	}
+
+	router.GET("/api/logs/export", ExportLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs/export?service=portal&level=error", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment; filename=")
+	assert.Equal(t, "{\"id\":2}\n{\"id\":1}\n", w.Body.String())
+	assert.Equal(t, "portal", gotFilters["service"])
+	assert.Equal(t, "error", gotFilters["level"])
+}
+
+func TestExportLogs_InvalidContextFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	mockSvc := &MockLogService{
+		ExportFn: func(ctx context.Context, filters map[string]interface{}, w io.Writer) (int64, error) {
+			return 0, fmt.Errorf("%w: unknown operator", logs_services.ErrInvalidContextFilter)
+		},
+	}
+
+	router.GET("/api/logs/export", ExportLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs/export?context_filter=context.a~1", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+}
+
+func TestExportLogs_ErrorBeforeFirstRecord(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	mockSvc := &MockLogService{
+		ExportFn: func(ctx context.Context, filters map[string]interface{}, w io.Writer) (int64, error) {
+			return 0, fmt.Errorf("export failed: connection refused")
+		},
+	}
+
+	router.GET("/api/logs/export", ExportLogs(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs/export", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestExportLogs_QueryFailureIsNotAnEmptyExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// A closed pool makes StreamQuery fail the way a lost database does
+	db, err := sql.Open("postgres", "postgres://localhost/unused?sslmode=disable")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	svc := logs_services.NewRestLogService(logs_db.NewLogRepository(db), logrus.New())
+
+	router := gin.New()
+	router.GET("/api/logs/export", ExportLogs(svc))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/logs/export", http.NoBody))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+	assert.Contains(t, w.Body.String(), "failed to export logs")
+}
+
+type mockRetentionRunner struct {
+	result logs_services.RetentionResult
+	err    error
+}
+
+func (m *mockRetentionRunner) RunOnce(ctx context.Context) (logs_services.RetentionResult, error) {
+	return m.result, m.err
+}
+
+func TestRunRetention_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/logs/retention/run", RunRetention(&mockRetentionRunner{
+		result: logs_services.RetentionResult{Deleted: 42, Projects: 2},
+	}))
+
+	req := httptest.NewRequest("POST", "/api/logs/retention/run", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(42), resp["deleted"])
+	assert.Equal(t, float64(2), resp["projects"])
+}
+
+func TestRunRetention_AlreadyRunning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/logs/retention/run", RunRetention(&mockRetentionRunner{err: logs_services.ErrRetentionRunning}))
+
+	req := httptest.NewRequest("POST", "/api/logs/retention/run", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	admins := logs_services.NewAdmins([]int{1})
+
+	for _, tt := range []struct {
+		name   string
+		userID int
+		want   int
+	}{
+		{"admin", 1, http.StatusOK},
+		{"other user", 7, http.StatusForbidden},
+		{"no session user", 0, http.StatusForbidden},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &mockRetentionRunner{}
+			router := gin.New()
+			router.POST("/api/logs/retention/run", withUser(tt.userID), RequireAdmin(admins), RunRetention(job))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/api/logs/retention/run", http.NoBody))
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+
+	// With no admins configured nobody gets through
+	router := gin.New()
+	router.POST("/api/logs/dead-letter/replay", withUser(1), RequireAdmin(logs_services.NewAdmins(nil)), ReplayDeadLetter(&mockDeadLetterReplayer{}))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/logs/dead-letter/replay", http.NoBody))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+type mockSeverityBackfiller struct {
+	ctx context.Context
+	err error
+}
+
+func (m *mockSeverityBackfiller) Start(ctx context.Context) error {
+	m.ctx = ctx
+	return m.err
+}
+
+func TestBackfillSeverity_Started(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	job := &mockSeverityBackfiller{}
+	router.POST("/api/logs/admin/backfill-severity", BackfillSeverity(job))
+
+	req := httptest.NewRequest("POST", "/api/logs/admin/backfill-severity", http.NoBody)
+	ctx, cancel := context.WithCancel(req.Context())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req.WithContext(ctx))
+	cancel()
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.JSONEq(t, `{"status": "started"}`, w.Body.String())
+	assert.NoError(t, job.ctx.Err(), "the run is not cancelled with the request")
+}
+
+func TestBackfillSeverity_AlreadyRunning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/logs/admin/backfill-severity", BackfillSeverity(&mockSeverityBackfiller{err: logs_services.ErrSeverityBackfillRunning}))
+
+	req := httptest.NewRequest("POST", "/api/logs/admin/backfill-severity", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+type mockDeadLetterReplayer struct {
+	count  int64
+	result logs_services.DeadLetterReplayResult
+	err    error
+}
+
+func (m *mockDeadLetterReplayer) Count(ctx context.Context) (int64, error) {
+	return m.count, nil
+}
+
+func (m *mockDeadLetterReplayer) Replay(ctx context.Context) (logs_services.DeadLetterReplayResult, error) {
+	return m.result, m.err
+}
+
+func TestDeadLetterEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	queue := &mockDeadLetterReplayer{
+		count:  3,
+		result: logs_services.DeadLetterReplayResult{Replayed: 3, Inserted: 250, Deduped: 10},
+	}
+	router := gin.New()
+	router.GET("/api/logs/dead-letter", GetDeadLetter(queue))
+	router.POST("/api/logs/dead-letter/replay", ReplayDeadLetter(queue))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/logs/dead-letter", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"count":3}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/logs/dead-letter/replay", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"replayed":3,"inserted":250,"deduped":10,"parked":0,"remaining":0}`, w.Body.String())
+
+	queue.err = errors.New("connection refused")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/api/logs/dead-letter/replay", http.NoBody))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestGetLogContext_Valid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var gotBefore, gotAfter int
+	var gotSameProject bool
+	mockSvc := &MockLogService{
+		GetContextFn: func(ctx context.Context, id int64, before, after int, sameProject bool, userID int) ([]interface{}, error) {
+			gotBefore, gotAfter, gotSameProject = before, after, sameProject
+			return []interface{}{
+				map[string]interface{}{"id": id - 1, "target": false},
+				map[string]interface{}{"id": id, "target": true},
+			}, nil
+		},
+	}
+	router.GET("/api/logs/:id/context", GetLogContext(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs/42/context?before=5&after=0&same_project=true", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 5, gotBefore)
+	assert.Equal(t, 0, gotAfter)
+	assert.True(t, gotSameProject)
+
+	var resp map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, float64(2), resp["count"])
+}
+
+func TestGetLogContext_Defaults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var gotBefore, gotAfter int
+	mockSvc := &MockLogService{
+		GetContextFn: func(ctx context.Context, id int64, before, after int, sameProject bool, userID int) ([]interface{}, error) {
+			gotBefore, gotAfter = before, after
+			return []interface{}{}, nil
+		},
+	}
+	router.GET("/api/logs/:id/context", GetLogContext(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs/42/context", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, logs_services.DefaultContextNeighbors, gotBefore)
+	assert.Equal(t, logs_services.DefaultContextNeighbors, gotAfter)
+}
+
+func TestGetLogContext_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/logs/:id/context", GetLogContext(&MockLogService{}))
+
+	for _, path := range []string{
+		"/api/logs/abc/context",
+		"/api/logs/42/context?before=-1",
+		"/api/logs/42/context?after=many",
+		fmt.Sprintf("/api/logs/42/context?after=%d", logs_services.MaxContextNeighbors+1),
+	} {
+		req := httptest.NewRequest("GET", path, http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+}
+
+func TestGetLogContext_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/logs/:id/context", GetLogContext(&MockLogService{
+		GetContextFn: func(ctx context.Context, id int64, before, after int, sameProject bool, userID int) ([]interface{}, error) {
+			return nil, logs_services.ErrLogNotFound
+		},
+	}))
+
+	req := httptest.NewRequest("GET", "/api/logs/999/context", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetStatsTimeSeries_Valid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	bucket := time.Date(2025, 11, 23, 10, 0, 0, 0, time.UTC)
+	var got logs_models.TimeSeriesQuery
+	mockSvc := &MockLogService{
+		TimeSeriesFn: func(ctx context.Context, q logs_models.TimeSeriesQuery, userID int) ([]logs_models.TimeSeriesPoint, error) {
+			got = q
+			return []logs_models.TimeSeriesPoint{
+				{Bucket: bucket, Level: "ERROR", Count: 3},
+				{Bucket: bucket.Add(time.Hour), Level: "ERROR", Count: 0},
+			}, nil
+		},
+	}
+	// Registered alongside the :id routes, as in the service
+	router.GET("/api/logs/:id/context", GetLogContext(mockSvc))
+	router.GET("/api/logs/stats/timeseries", GetStatsTimeSeries(mockSvc))
+
+	req := httptest.NewRequest("GET", "/api/logs/stats/timeseries?interval=hour&from=2025-11-23T10:00:00Z&to=2025-11-23T12:00:00Z&group_by=level", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, logs_models.TimeSeriesQuery{From: bucket, To: bucket.Add(2 * time.Hour), Interval: "hour", GroupBy: "level"}, got)
+	assert.JSONEq(t, `[
+		{"bucket": "2025-11-23T10:00:00Z", "level": "ERROR", "count": 3},
+		{"bucket": "2025-11-23T11:00:00Z", "level": "ERROR", "count": 0}
+	]`, w.Body.String())
+}
+
+func TestGetStatsTimeSeries_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/logs/stats/timeseries", GetStatsTimeSeries(&MockLogService{
+		TimeSeriesFn: func(ctx context.Context, q logs_models.TimeSeriesQuery, userID int) ([]logs_models.TimeSeriesPoint, error) {
+			if q.Interval == "week" {
+				return nil, fmt.Errorf("%w: interval must be minute, hour or day", logs_services.ErrInvalidTimeSeries)
+			}
+			return nil, nil
+		},
+	}))
+
+	for _, path := range []string{
+		"/api/logs/stats/timeseries?from=yesterday",
+		"/api/logs/stats/timeseries?to=1700000000",
+		"/api/logs/stats/timeseries?interval=week",
+	} {
+		req := httptest.NewRequest("GET", path, http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+}
+
+func TestGetStatsTimeSeries_Error(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/logs/stats/timeseries", GetStatsTimeSeries(&MockLogService{
+		TimeSeriesFn: func(ctx context.Context, q logs_models.TimeSeriesQuery, userID int) ([]logs_models.TimeSeriesPoint, error) {
+			return nil, errors.New("database down")
+		},
+	}))
+
+	req := httptest.NewRequest("GET", "/api/logs/stats/timeseries", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetFingerprints_Valid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	seen := time.Date(2025, 11, 25, 9, 0, 0, 0, time.UTC)
+	var got logs_models.FingerprintQuery
+	router.GET("/api/logs/fingerprints", GetFingerprints(&MockLogService{
+		FingerprintsFn: func(ctx context.Context, q logs_models.FingerprintQuery, userID int) ([]logs_models.FingerprintGroup, error) {
+			got = q
+			return []logs_models.FingerprintGroup{{
+				Fingerprint: "3f1c9a7b2d4e6f80",
+				Count:       42,
+				FirstSeen:   seen,
+				LastSeen:    seen.Add(time.Hour),
+				Message:     "order 981 not found",
+				Level:       "ERROR",
+				Service:     "orders",
+			}}, nil
+		},
+	}))
+
+	req := httptest.NewRequest("GET", "/api/logs/fingerprints?level=error&service=orders&limit=10&from=2025-11-25T00:00:00Z", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, logs_models.FingerprintQuery{From: seen.Add(-9 * time.Hour), Level: "error", Service: "orders", Limit: 10}, got)
+	assert.JSONEq(t, `[{
+		"fingerprint": "3f1c9a7b2d4e6f80",
+		"count": 42,
+		"first_seen": "2025-11-25T09:00:00Z",
+		"last_seen": "2025-11-25T10:00:00Z",
+		"message": "order 981 not found",
+		"level": "ERROR",
+		"service": "orders"
+	}]`, w.Body.String())
+}
+
+func TestGetFingerprints_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/logs/fingerprints", GetFingerprints(&MockLogService{
+		FingerprintsFn: func(ctx context.Context, q logs_models.FingerprintQuery, userID int) ([]logs_models.FingerprintGroup, error) {
+			if q.Limit > logs_services.MaxFingerprintLimit {
+				return nil, fmt.Errorf("%w: limit too large", logs_services.ErrInvalidFingerprintQuery)
+			}
+			return nil, nil
+		},
+	}))
+
+	for _, path := range []string{
+		"/api/logs/fingerprints?from=yesterday",
+		"/api/logs/fingerprints?limit=ten",
+		"/api/logs/fingerprints?limit=100000",
+	} {
+		req := httptest.NewRequest("GET", path, http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+}
+
+func TestGetFingerprints_Error(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/logs/fingerprints", GetFingerprints(&MockLogService{
+		FingerprintsFn: func(ctx context.Context, q logs_models.FingerprintQuery, userID int) ([]logs_models.FingerprintGroup, error) {
+			return nil, errors.New("database down")
+		},
+	}))
+
+	req := httptest.NewRequest("GET", "/api/logs/fingerprints", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGetTrash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	deletedAt := time.Date(2025, 11, 25, 10, 0, 0, 0, time.UTC)
+	router := gin.New()
+	router.GET("/api/logs/trash", withUser(7), GetTrash(&MockLogService{
+		TrashFn: func(ctx context.Context, userID, projectID int) ([]logs_models.TrashBatch, error) {
+			if userID != 7 || projectID != 3 {
+				return nil, logs_services.ErrDeleteForbidden
+			}
+			return []logs_models.TrashBatch{{DeletedAt: deletedAt, PurgeAt: deletedAt.AddDate(0, 0, 7), Count: 12}}, nil
+		},
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/logs/trash?project_id=3", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"project_id": 3, "count": 1, "deletions": [
+		{"deleted_at": "2025-11-25T10:00:00Z", "purge_at": "2025-12-02T10:00:00Z", "count": 12}
+	]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/logs/trash?project_id=4", http.NoBody))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/logs/trash", http.NoBody))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRestoreTrash(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var gotDeletedAt *time.Time
+	router := gin.New()
+	router.POST("/api/logs/trash/restore", withUser(7), RestoreTrash(&MockLogService{
+		RestoreFn: func(ctx context.Context, userID, projectID int, deletedAt *time.Time) (int64, error) {
+			gotDeletedAt = deletedAt
+			if deletedAt != nil && deletedAt.Year() < 2025 {
+				return 0, logs_services.ErrNothingToRestore
+			}
+			return 12, nil
+		},
+	}))
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/logs/trash/restore", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"project_id": 3, "deleted_at": "2025-11-25T10:00:00.123456Z"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"restored": 12}`, w.Body.String())
+	if assert.NotNil(t, gotDeletedAt) {
+		assert.True(t, gotDeletedAt.Equal(time.Date(2025, 11, 25, 10, 0, 0, 123456000, time.UTC)))
+	}
+
+	w = post(`{"project_id": 3}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, gotDeletedAt, "restores every deletion within the grace period")
+
+	w = post(`{"project_id": 3, "deleted_at": "2024-01-01T00:00:00Z"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = post(`{"deleted_at": "2025-11-25T10:00:00Z"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetLogs_PassesSessionUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var got []interface{}
+	mockSvc := &MockLogService{
+		QueryFn: func(ctx context.Context, filters map[string]interface{}, page map[string]int) ([]interface{}, error) {
+			got = append(got, filters[logs_services.CallerFilterKey])
+			return []interface{}{}, nil
+		},
+	}
+
+	router := gin.New()
+	router.GET("/api/logs", withUser(7), GetLogs(mockSvc))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/logs?user_id=8", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	router = gin.New()
+	router.GET("/api/logs", GetLogs(mockSvc))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/logs?user_id=8", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, []interface{}{7, nil}, got, "the caller comes from the session, never the query string")
+}
+
+func TestLogReads_PassSessionUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var got int
+	mockSvc := &MockLogService{
+		GetByIDFn: func(ctx context.Context, id int64, userID int) (interface{}, error) {
+			got = userID
+			return map[string]interface{}{"id": id}, nil
+		},
+		GetContextFn: func(ctx context.Context, id int64, before, after int, sameProject bool, userID int) ([]interface{}, error) {
+			got = userID
+			return []interface{}{}, nil
+		},
+		StatsFn: func(ctx context.Context, userID int) (map[string]interface{}, error) {
+			got = userID
+			return map[string]interface{}{}, nil
+		},
+		TimeSeriesFn: func(ctx context.Context, q logs_models.TimeSeriesQuery, userID int) ([]logs_models.TimeSeriesPoint, error) {
+			got = userID
+			return []logs_models.TimeSeriesPoint{}, nil
+		},
+		FingerprintsFn: func(ctx context.Context, q logs_models.FingerprintQuery, userID int) ([]logs_models.FingerprintGroup, error) {
+			got = userID
+			return []logs_models.FingerprintGroup{}, nil
+		},
+	}
+
+	tests := []struct {
+		route   string
+		path    string
+		handler gin.HandlerFunc
+	}{
+		{"/api/logs/:id", "/api/logs/42", GetLogByID(mockSvc)},
+		{"/api/logs/:id/context", "/api/logs/42/context", GetLogContext(mockSvc)},
+		{"/api/logs/stats", "/api/logs/stats", GetStats(mockSvc)},
+		{"/api/logs/stats/timeseries", "/api/logs/stats/timeseries", GetStatsTimeSeries(mockSvc)},
+		{"/api/logs/fingerprints", "/api/logs/fingerprints", GetFingerprints(mockSvc)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			got = 0
+			router := gin.New()
+			router.GET(tt.route, withUser(7), tt.handler)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", tt.path+"?user_id=8", http.NoBody))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, 7, got, "the caller comes from the session, never the query string")
+		})
+	}
+}
+
+func TestGetLogByID_ForeignEntryIsNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockSvc := &MockLogService{
+		GetByIDFn: func(ctx context.Context, id int64, userID int) (interface{}, error) {
+			return nil, errors.New("log entry not found")
+		},
+	}
+
+	router := gin.New()
+	router.GET("/api/logs/:id", withUser(7), GetLogByID(mockSvc))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/logs/42", http.NoBody))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
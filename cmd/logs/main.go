@@ -5,10 +5,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"expvar"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,14 +21,20 @@ import (
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai/providers"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/common/debug"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/health"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/instrumentation"
 	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
 	internal_logs_handlers "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/handlers"
+	logs_metrics "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/metrics"
 	logs_middleware "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/middleware"
 	logs_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/services"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/middleware"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/monitoring"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/server"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/tracing"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
@@ -89,6 +97,10 @@ func main() {
 		port = "8082"
 	}
 
+	// Cancelled on shutdown so background jobs stop promptly
+	appCtx, cancelAppCtx := context.WithCancel(context.Background())
+	defer cancelAppCtx()
+
 	// Initialize instrumentation logger for this service
 	// Note: Logs service has circular dependency prevention built in
 	logsServiceURL := os.Getenv("LOGS_SERVICE_URL")
@@ -157,6 +169,71 @@ func main() {
 	// Initialize Gin router
 	router := gin.Default()
 
+	// CORS runs first so preflight requests are answered before auth
+	corsConfig, err := config.LoadCORSConfig()
+	if err != nil {
+		log.Fatalf("Failed to load CORS configuration: %v", err)
+	}
+	router.Use(middleware.CORSMiddleware(corsConfig))
+
+	// Cap request bodies and handler time (REQUEST_MAX_BODY_BYTES,
+	// REQUEST_TIMEOUT_SECONDS). Batches, AI insights and admin jobs get their
+	// own LOGS_BATCH_*, LOGS_INSIGHTS_* and LOGS_ADMIN_* limits; the export
+	// streams until it is done, so it has no timeout.
+	requestLimits, err := config.LoadRequestLimits("REQUEST_", config.DefaultRequestLimits)
+	if err != nil {
+		log.Fatalf("Failed to load request limits: %v", err)
+	}
+	batchLimits, err := config.LoadRequestLimits("LOGS_BATCH_", config.RequestLimits{
+		MaxBodyBytes: 10 << 20, // 10 MiB
+		Timeout:      2 * time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load batch request limits: %v", err)
+	}
+	insightsLimits, err := config.LoadRequestLimits("LOGS_INSIGHTS_", config.RequestLimits{
+		MaxBodyBytes: requestLimits.MaxBodyBytes,
+		Timeout:      5 * time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load insights request limits: %v", err)
+	}
+	adminLimits, err := config.LoadRequestLimits("LOGS_ADMIN_", config.RequestLimits{
+		MaxBodyBytes: requestLimits.MaxBodyBytes,
+		Timeout:      30 * time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load admin request limits: %v", err)
+	}
+	exportLimits := config.RequestLimits{MaxBodyBytes: requestLimits.MaxBodyBytes}
+	router.Use(middleware.RequestLimitsMiddleware(requestLimits, map[string]config.RequestLimits{
+		"/api/logs/batch":                   batchLimits,
+		"/api/logs/:id/insights":            insightsLimits,
+		"/api/logs/insights/correlated":     insightsLimits,
+		"/api/logs/admin/backfill-severity": adminLimits,
+		"/api/logs/retention/run":           adminLimits,
+		"/api/logs/dead-letter/replay":      adminLimits,
+		"/api/logs/export":                  exportLimits,
+	}))
+
+	// Initialize OpenTelemetry tracing; a failure leaves tracing disabled
+	tracingEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if tracingEndpoint == "" {
+		tracingEndpoint = tracing.DefaultEndpoint
+	}
+	shutdownTracer, err := tracing.InitTracer("devsmith-logs", tracingEndpoint)
+	if err != nil {
+		log.Printf("Warning: Failed to initialize tracing: %v", err)
+	} else {
+		defer func() {
+			if shutdownErr := shutdownTracer(context.Background()); shutdownErr != nil {
+				log.Printf("Warning: Failed to flush traces: %v", shutdownErr)
+			}
+		}()
+		log.Printf("Tracing initialized (endpoint: %s)", tracingEndpoint)
+	}
+	router.Use(tracing.Middleware("devsmith-logs"))
+
 	// Middleware for logging requests (skip health checks in event log, but still track them)
 	router.Use(func(c *gin.Context) {
 		// Log all requests asynchronously (health checks too, for observability)
@@ -186,6 +263,74 @@ func main() {
 	logRepo := logs_db.NewLogRepository(dbConn)
 	restSvc := logs_services.NewRestLogService(logRepo, logger)
 
+	// Rule-based issue_type classification at ingestion, extended by LOGS_ISSUE_RULES_FILE
+	// (a JSON array of {"issue_type", "pattern"}); AI analysis can refine it later
+	patternMatcher := logs_services.NewPatternMatcher()
+	if path := os.Getenv("LOGS_ISSUE_RULES_FILE"); path != "" {
+		if loadErr := patternMatcher.LoadRulesFile(path); loadErr != nil {
+			log.Printf("Warning: %v, using built-in issue rules", loadErr)
+		} else {
+			log.Printf("Issue rules loaded from %s", path)
+		}
+	}
+	logRepo.SetIssueClassifier(patternMatcher)
+
+	// Prometheus metrics: ingestion counters plus DB pool stats, served at /metrics.
+	// The JSON /api/logs/monitoring/metrics endpoint is unaffected.
+	metricsRegistry := logs_metrics.NewRegistry(dbConn)
+	ingestMetrics := logs_metrics.NewIngestMetrics(metricsRegistry)
+	restSvc.SetMetrics(ingestMetrics)
+	router.GET("/metrics", gin.WrapH(logs_metrics.Handler(metricsRegistry)))
+
+	// Log retention: purge logs.entries past each project's window (default LOGS_RETENTION_DAYS)
+	retentionDays := logs_services.DefaultRetentionDays
+	if v := os.Getenv("LOGS_RETENTION_DAYS"); v != "" {
+		if d, convErr := strconv.Atoi(v); convErr == nil && d > 0 {
+			retentionDays = d
+		}
+	}
+	retentionInterval := 24 * time.Hour
+	if v := os.Getenv("LOGS_RETENTION_INTERVAL_HOURS"); v != "" {
+		if h, convErr := strconv.Atoi(v); convErr == nil && h > 0 {
+			retentionInterval = time.Duration(h) * time.Hour
+		}
+	}
+	retentionJob := logs_services.NewRetentionJob(logRepo, retentionDays, logs_services.DefaultRetentionBatchSize, logger)
+	retentionJob.Start(appCtx, retentionInterval)
+
+	// Trash: DELETE /api/logs soft-deletes; entries are restorable for
+	// LOGS_TRASH_GRACE_DAYS, then purged on the retention schedule
+	trashGrace := logs_services.DefaultTrashGracePeriod
+	if v := os.Getenv("LOGS_TRASH_GRACE_DAYS"); v != "" {
+		if d, convErr := strconv.Atoi(v); convErr == nil && d > 0 {
+			trashGrace = time.Duration(d) * 24 * time.Hour
+		}
+	}
+	restSvc.SetTrashGracePeriod(trashGrace)
+	trashPurgeJob := logs_services.NewTrashPurgeJob(logRepo, trashGrace, logs_services.DefaultRetentionBatchSize, logger)
+	trashPurgeJob.Start(appCtx, retentionInterval)
+
+	// Users listed in LOGS_ADMIN_USER_IDS (comma-separated) can read every
+	// project's logs and run the admin triggers; everyone else only sees the
+	// projects they own
+	var logAdmins []int
+	if v := os.Getenv("LOGS_ADMIN_USER_IDS"); v != "" {
+		for _, field := range strings.Split(v, ",") {
+			if id, convErr := strconv.Atoi(strings.TrimSpace(field)); convErr == nil && id > 0 {
+				logAdmins = append(logAdmins, id)
+			} else {
+				log.Printf("Warning: ignoring invalid LOGS_ADMIN_USER_IDS entry %q", field)
+			}
+		}
+	}
+	restSvc.SetAdmins(logAdmins)
+
+	// Fuzzy (pg_trgm) fallback for searches with no exact matches - on unless disabled
+	if os.Getenv("LOGS_FUZZY_SEARCH_ENABLED") == "false" {
+		restSvc.SetFuzzyFallback(false)
+		log.Println("Fuzzy search fallback disabled via LOGS_FUZZY_SEARCH_ENABLED")
+	}
+
 	// Issue #023: Production Enhancements - Initialize alert and aggregation services
 	alertConfigRepo := logs_db.NewAlertConfigRepository(dbConn)
 	alertViolationRepo := logs_db.NewAlertViolationRepository(dbConn)
@@ -311,11 +456,19 @@ func main() {
 		}
 	}
 
+	// Severity backfill for logs without a severity_score: rules, plus AI for one
+	// in every LOGS_SEVERITY_BACKFILL_AI_EVERY warnings/errors when AI is available
+	severityBackfill := logs_services.NewSeverityBackfillJob(logRepo, patternMatcher, logs_services.DefaultSeverityBackfillBatchSize, logger)
+
 	// Initialize AI analysis services (if AI available)
 	var analysisHandler *internal_logs_handlers.AnalysisHandler
 	if rawAIClient != nil {
 		aiAnalyzer := logs_services.NewAIAnalyzer(rawAIClient)
-		patternMatcher := logs_services.NewPatternMatcher()
+		if v := os.Getenv("LOGS_SEVERITY_BACKFILL_AI_EVERY"); v != "" {
+			if n, convErr := strconv.Atoi(v); convErr == nil && n > 0 {
+				severityBackfill.SetAISampling(aiAnalyzer, n)
+			}
+		}
 		analysisService := logs_services.NewAnalysisService(aiAnalyzer, patternMatcher)
 		analysisHandler = internal_logs_handlers.NewAnalysisHandler(analysisService, logger)
 		log.Printf("✓ AI analysis services ready\n")
@@ -326,9 +479,21 @@ func main() {
 	// Week 1: Cross-Repository Logging - Initialize batch ingestion services
 	projectRepo := logs_db.NewProjectRepository(dbConn)
 	projectService := logs_services.NewProjectService(projectRepo)
+	// rotate-key keeps the old key working for LOGS_API_KEY_ROTATION_GRACE_HOURS by default
+	if v := os.Getenv("LOGS_API_KEY_ROTATION_GRACE_HOURS"); v != "" {
+		if h, convErr := strconv.Atoi(v); convErr == nil && h > 0 {
+			projectService.SetKeyRotationGrace(time.Duration(h) * time.Hour)
+		}
+	}
 	logEntryRepo := logs_db.NewLogEntryRepository(dbConn)
+	tagRuleRepo := logs_db.NewTagRuleRepository(dbConn)
+	logEntryRepo.SetTagRules(tagRuleRepo)
+	logEntryRepo.SetIssueClassifier(patternMatcher)
 	batchHandler := internal_logs_handlers.NewBatchHandler(logEntryRepo, projectRepo, projectService)
+	batchHandler.SetMetrics(ingestMetrics)
 	projectHandler := internal_logs_handlers.NewProjectHandler(projectService)
+	// DELETE /api/logs and the trash only touch projects the session's user owns
+	restSvc.SetProjects(projectRepo)
 
 	log.Println("Batch ingestion service initialized for cross-repository logging")
 
@@ -340,35 +505,132 @@ func main() {
 	// Week 1: Cross-Repository Logging - Batch ingestion endpoint
 	// This endpoint allows external applications to send logs in batches (100x performance improvement)
 	// Authentication: Simple API token validation (fast O(1) lookup)
-	// Rate limit: 100 requests/minute per API key (TODO: implement rate limiting middleware)
+	// Rate limit: LOGS_BATCH_RATE_LIMIT requests/minute per API key (default 100),
+	// sliding window in Redis so the limit holds across replicas
 	//
 	// Standalone: Works for ANY external codebase (Node.js, Go, Java, Python, etc.)
 	// No dependency on Portal service - projects can be unclaimed (user_id=NULL)
-	router.POST("/api/logs/batch", logs_middleware.SimpleAPITokenAuth(projectRepo), batchHandler.IngestBatch)
+	batchRateLimit := logs_middleware.DefaultRateLimit
+	if v := os.Getenv("LOGS_BATCH_RATE_LIMIT"); v != "" {
+		if n, convErr := strconv.Atoi(v); convErr == nil && n > 0 {
+			batchRateLimit = n
+		} else {
+			log.Printf("Warning: invalid LOGS_BATCH_RATE_LIMIT=%q, using default %d", v, batchRateLimit)
+		}
+	}
+	redisClient := redis.NewClient(&redis.Options{Addr: redisAddr})
+	defer func() {
+		if closeErr := redisClient.Close(); closeErr != nil {
+			log.Printf("Error closing Redis client: %v", closeErr)
+		}
+	}()
+	// Batches the database rejects wait in a Redis list and are replayed once
+	// it recovers; idempotency keys keep replays from inserting twice
+	deadLetter := logs_services.NewDeadLetterQueue(logs_services.NewRedisDeadLetterStore(redisClient), logEntryRepo, logger)
+	batchHandler.SetDeadLetter(deadLetter)
+
+	// Per-project daily_log_quota, counted in Redis per UTC day
+	logQuota := logs_services.NewLogQuota(logs_services.NewRedisQuotaStore(redisClient))
+	batchHandler.SetQuota(logQuota)
+	projectHandler.SetQuota(logQuota)
+	deadLetter.Start(appCtx, logs_services.DefaultDeadLetterReplayInterval)
+
+	batchLimiter := logs_middleware.NewRateLimiter(
+		logs_middleware.NewRedisRateLimitStore(redisClient), batchRateLimit, logs_middleware.DefaultRateWindow)
+	// Batches may be sent with Content-Encoding: gzip; LOGS_BATCH_MAX_DECOMPRESSED_BYTES
+	// caps what they expand to (default 10 MiB)
+	batchMaxDecompressed := int64(logs_middleware.DefaultMaxDecompressedBytes)
+	if v := os.Getenv("LOGS_BATCH_MAX_DECOMPRESSED_BYTES"); v != "" {
+		if n, convErr := strconv.ParseInt(v, 10, 64); convErr == nil && n > 0 {
+			batchMaxDecompressed = n
+		} else {
+			log.Printf("Warning: invalid LOGS_BATCH_MAX_DECOMPRESSED_BYTES=%q, using default %d", v, batchMaxDecompressed)
+		}
+	}
+	router.POST("/api/logs/batch",
+		logs_middleware.SimpleAPITokenAuth(projectRepo),
+		logs_middleware.APIKeyRateLimit(batchLimiter),
+		logs_middleware.DecompressGzip(batchMaxDecompressed),
+		batchHandler.IngestBatch)
+	log.Printf("Batch ingestion rate limit: %d requests/minute per API key", batchRateLimit)
 
 	// Week 1: Cross-Repository Logging - Project management endpoints
 	// Authentication: Redis session middleware (requires GitHub OAuth login)
 	// These endpoints allow authenticated users to create projects and manage API keys
 	projectRoutes := router.Group("/api/logs/projects")
-	projectRoutes.Use(middleware.RedisSessionAuthMiddleware(sessionStore))
+	projectRoutes.Use(middleware.RedisSessionAuthMiddleware(sessionStore), middleware.CSRFMiddleware())
 	projectRoutes.POST("", projectHandler.CreateProject)
 	projectRoutes.GET("", projectHandler.ListProjects)
 	projectRoutes.GET("/:id", projectHandler.GetProject)
+	projectRoutes.GET("/:id/quota", projectHandler.GetQuota)
 	projectRoutes.POST("/:id/regenerate-key", projectHandler.RegenerateAPIKey)
+	projectRoutes.POST("/:id/rotate-key", projectHandler.RotateAPIKey)
 	projectRoutes.DELETE("/:id", projectHandler.DeleteProject)
 
-	router.GET("/api/logs", func(c *gin.Context) {
-		resthandlers.GetLogs(restSvc)(c)
-	})
-	router.GET("/api/logs/:id", func(c *gin.Context) {
-		resthandlers.GetLogByID(restSvc)(c)
-	})
-	router.GET("/api/logs/stats", func(c *gin.Context) {
-		resthandlers.GetStats(restSvc)(c)
-	})
-	router.DELETE("/api/logs", func(c *gin.Context) {
-		resthandlers.DeleteLogs(restSvc)(c)
-	})
+	// Operational triggers act on every project's data, so only users in
+	// LOGS_ADMIN_USER_IDS may run them; with no admins configured they are
+	// refused for everyone
+	requireAdmin := resthandlers.RequireAdmin(logs_services.NewAdmins(logAdmins))
+	adminOnly := []gin.HandlerFunc{
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		middleware.CSRFMiddleware(),
+		requireAdmin,
+	}
+
+	// Runtime counters (expvar), e.g. logs_retention_rows_deleted_total. The
+	// handler also publishes the command line and memory stats, so it is
+	// admin only like the triggers below
+	router.GET("/debug/vars",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		requireAdmin,
+		gin.WrapH(expvar.Handler()))
+
+	// Manual retention trigger (purges data)
+	router.POST("/api/logs/retention/run",
+		append(adminOnly, resthandlers.RunRetention(retentionJob))...)
+
+	// Severity backfill trigger (runs in the background)
+	router.POST("/api/logs/admin/backfill-severity",
+		append(adminOnly, resthandlers.BackfillSeverity(severityBackfill))...)
+
+	// Dead-letter queue: count of failed batches, and a manual replay
+	router.GET("/api/logs/dead-letter",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetDeadLetter(deadLetter))
+	router.POST("/api/logs/dead-letter/replay",
+		append(adminOnly, resthandlers.ReplayDeadLetter(deadLetter))...)
+
+	// Log reads are limited to the session user's projects (admins see all)
+	router.GET("/api/logs",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetLogs(restSvc))
+	router.GET("/api/logs/export",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.ExportLogs(restSvc))
+	router.GET("/api/logs/:id",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetLogByID(restSvc))
+	router.GET("/api/logs/:id/context",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetLogContext(restSvc))
+	router.GET("/api/logs/stats",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetStats(restSvc))
+	router.GET("/api/logs/stats/timeseries",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetStatsTimeSeries(restSvc))
+	router.GET("/api/logs/fingerprints",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetFingerprints(restSvc))
+	router.DELETE("/api/logs",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.DeleteLogs(restSvc))
+	router.GET("/api/logs/trash",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetTrash(restSvc))
+	router.POST("/api/logs/trash/restore",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.RestoreTrash(restSvc))
 
 	// TODO: Add protected routes group when authentication is required
 	// Example:
@@ -382,18 +644,33 @@ func main() {
 	router.POST("/api/v1/logs", func(c *gin.Context) {
 		resthandlers.PostLogs(restSvc)(c)
 	})
-	router.GET("/api/v1/logs", func(c *gin.Context) {
-		resthandlers.GetLogs(restSvc)(c)
-	})
-	router.GET("/api/v1/logs/:id", func(c *gin.Context) {
-		resthandlers.GetLogByID(restSvc)(c)
-	})
-	router.GET("/api/v1/logs/stats", func(c *gin.Context) {
-		resthandlers.GetStats(restSvc)(c)
-	})
-	router.DELETE("/api/v1/logs", func(c *gin.Context) {
-		resthandlers.DeleteLogs(restSvc)(c)
-	})
+	router.GET("/api/v1/logs",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetLogs(restSvc))
+	router.GET("/api/v1/logs/:id",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetLogByID(restSvc))
+	router.GET("/api/v1/logs/:id/context",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetLogContext(restSvc))
+	router.GET("/api/v1/logs/stats",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetStats(restSvc))
+	router.GET("/api/v1/logs/stats/timeseries",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetStatsTimeSeries(restSvc))
+	router.GET("/api/v1/logs/fingerprints",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetFingerprints(restSvc))
+	router.DELETE("/api/v1/logs",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.DeleteLogs(restSvc))
+	router.GET("/api/v1/logs/trash",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.GetTrash(restSvc))
+	router.POST("/api/v1/logs/trash/restore",
+		middleware.RedisSessionAuthMiddleware(sessionStore),
+		resthandlers.RestoreTrash(restSvc))
 
 	// Issue #023: Production Enhancements - Dashboard & Alert Endpoints
 	// Dashboard statistics endpoint
@@ -454,14 +731,25 @@ func main() {
 		aiInsightsRepo := logs_db.NewAIInsightsRepository(dbConn)
 		logRepoAdapter := logs_services.NewLogRepositoryAdapter(logRepo)
 		aiInsightsService := logs_services.NewAIInsightsService(adaptedAIClient, logRepoAdapter, aiInsightsRepo)
+		aiInsightsService.SetCorrelation(logEntryRepo, aiInsightsRepo)
+		aiInsightsService.SetAdmins(logAdmins)
+		aiInsightsService.SetUsageRecorder(ai.NewPostgresUsageRecorder(dbConn), rawAIClient.GetModelInfo(), logger)
 		aiInsightsHandler = internal_logs_handlers.NewAIInsightsHandler(aiInsightsService, logger, logEntryRepo)
 		log.Println("AI insights service initialized - ready for log analysis")
 	}
 
-	// AI insights endpoints (if AI available)
+	// AI insights endpoints (if AI available); limited to the session user's
+	// projects like the other log reads
 	if aiInsightsHandler != nil {
-		router.POST("/api/logs/:id/insights", aiInsightsHandler.GenerateInsights)
-		router.GET("/api/logs/:id/insights", aiInsightsHandler.GetInsights)
+		router.POST("/api/logs/:id/insights",
+			middleware.RedisSessionAuthMiddleware(sessionStore),
+			aiInsightsHandler.GenerateInsights)
+		router.GET("/api/logs/:id/insights",
+			middleware.RedisSessionAuthMiddleware(sessionStore),
+			aiInsightsHandler.GetInsights)
+		router.POST("/api/logs/insights/correlated",
+			middleware.RedisSessionAuthMiddleware(sessionStore),
+			aiInsightsHandler.GenerateCorrelatedInsights)
 	} else {
 		router.POST("/api/logs/:id/insights", func(c *gin.Context) {
 			c.JSON(503, gin.H{"error": "AI insights not available - no LLM configured"})
@@ -469,17 +757,27 @@ func main() {
 		router.GET("/api/logs/:id/insights", func(c *gin.Context) {
 			c.JSON(503, gin.H{"error": "AI insights not available - no LLM configured"})
 		})
+		router.POST("/api/logs/insights/correlated", func(c *gin.Context) {
+			c.JSON(503, gin.H{"error": "AI insights not available - no LLM configured"})
+		})
 	}
 
 	// Phase 3: Smart Tagging System - Initialize tag management
 	tagsHandler := internal_logs_handlers.NewTagsHandler(logRepo)
+	tagsHandler.SetTagRuleStore(tagRuleRepo)
 
 	// Tag management endpoints
 	router.GET("/api/logs/tags", tagsHandler.GetAvailableTags)             // Get all unique tags with counts
 	router.POST("/api/logs/:id/tags", tagsHandler.AddTagToLog)             // Add manual tag to log entry
 	router.DELETE("/api/logs/:id/tags/:tag", tagsHandler.RemoveTagFromLog) // Remove tag from log entry
 
-	log.Println("Tag management service initialized - 3 endpoints registered (auto-tagging + manual)")
+	// Per-project tag rules, applied at batch ingest (session auth via projectRoutes)
+	projectRoutes.GET("/:id/tag-rules", tagsHandler.ListTagRules)
+	projectRoutes.POST("/:id/tag-rules", tagsHandler.CreateTagRule)
+	projectRoutes.PUT("/:id/tag-rules/:rule_id", tagsHandler.UpdateTagRule)
+	projectRoutes.DELETE("/:id/tag-rules/:rule_id", tagsHandler.DeleteTagRule)
+
+	log.Println("Tag management service initialized - 7 endpoints registered (auto-tagging + manual + project rules)")
 
 	// Health Monitoring Dashboard - Real-time metrics and alerts
 	metricsCollector := monitoring.NewSQLMetricsCollector(dbConn)
@@ -492,28 +790,56 @@ func main() {
 	// Start Alert Engine - Background monitoring and alerting
 	alertThresholds := monitoring.DefaultAlertThresholds()
 	alertEngine := monitoring.NewAlertEngine(dbConn, alertThresholds, 1*time.Minute, log.Default())
+	var alertNotifiers []monitoring.Notifier
+	for _, url := range strings.Split(os.Getenv("LOGS_ALERT_WEBHOOK_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			alertNotifiers = append(alertNotifiers, monitoring.NewWebhookNotifier(url))
+		}
+	}
+	alertEngine.SetNotifiers(alertNotifiers...)
+	log.Printf("Alert webhook notifiers: %d", len(alertNotifiers))
 	alertEngine.Start()
-	defer alertEngine.Stop()
 
 	// Phase 3: WebSocket hub re-enabled with frontend connection
-	hub := logs_services.NewWebSocketHub()
+	// Redis pub/sub fans broadcasts out to the hubs of every replica
+	hub := logs_services.NewWebSocketHub(redisClient)
+	dropPolicy, err := logs_services.ParseDropPolicy(os.Getenv("LOGS_WS_DROP_POLICY"))
+	if err != nil {
+		log.Printf("Warning: %v, using %s", err, logs_services.DropNewest)
+		dropPolicy = logs_services.DropNewest
+	}
+	hub.SetDropPolicy(dropPolicy)
+	maxReplay := logs_services.DefaultMaxReplay
+	if v := os.Getenv("LOGS_WS_MAX_REPLAY"); v != "" {
+		if n, convErr := strconv.Atoi(v); convErr == nil && n > 0 {
+			maxReplay = n
+		} else {
+			log.Printf("Warning: invalid LOGS_WS_MAX_REPLAY=%q, using default %d", v, maxReplay)
+		}
+	}
+	hub.SetReplaySource(logEntryRepo, maxReplay)
 	go hub.Run()
-	defer hub.Stop() // Ensure graceful shutdown of WebSocket hub
 
-	// Register WebSocket routes
-	logs_services.RegisterWebSocketRoutes(router, hub)
+	// Register WebSocket routes, plus an SSE fallback for proxies that block
+	// upgrades. Both need a session and stream only the user's projects
+	// (admins see all), like log queries
+	streamRoutes := router.Group("", middleware.RedisSessionAuthMiddleware(sessionStore))
+	streamAccess := logs_services.NewStreamAccess(logs_services.NewAdmins(logAdmins), projectRepo)
+	logs_services.RegisterWebSocketRoutes(streamRoutes, hub, streamAccess)
+	logs_services.RegisterSSERoutes(streamRoutes, hub, streamAccess)
+	monitoringHandler.SetStreamStats(hub)
+	router.GET("/api/logs/monitoring/stream", monitoringHandler.GetStreamStats)
 
 	// Health check endpoint (system-wide diagnostics)
 	router.GET("/api/logs/healthcheck", resthandlers.GetHealthCheck)
 
-	// Simple health endpoint for smoke tests
-	router.GET("/api/logs/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"service": "logs",
-			"status":  "healthy",
-			"version": "1.0.0",
-		})
-	})
+	// Service health for monitoring and smoke tests
+	logsHealth := health.NewHandler("logs")
+	logsHealth.AddCheck("database", health.Ping(dbConn.PingContext))
+	logsHealth.AddCheck("redis", health.Ping(func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	}))
+	logsHealth.Register(router, "/api/logs/health")
 
 	// Phase 3: Health Intelligence - Initialize services
 	storageService := logs_services.NewHealthStorageService(dbConn)
@@ -543,14 +869,13 @@ func main() {
 	// Start health scheduler (runs background checks every 5 minutes)
 	scheduler := logs_services.NewHealthScheduler(5*time.Minute, storageService, repairService)
 	scheduler.Start()
-	defer scheduler.Stop() // Ensure graceful shutdown of health scheduler
 
 	log.Println("Health intelligence system initialized - scheduler running every 5 minutes")
 
 	log.Printf("Starting logs service on port %s", port)
 
 	// Create an HTTP server with timeouts
-	server := &http.Server{
+	srv := &http.Server{
 		Addr:              ":" + port,
 		Handler:           router,
 		ReadTimeout:       10 * time.Second,
@@ -559,12 +884,25 @@ func main() {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		if closeErr := dbConn.Close(); closeErr != nil {
-			log.Printf("[ERROR] Failed to close database: %v", closeErr)
-		}
-		log.Fatalf("[ERROR] Failed to start server: %v", err)
+	// On SIGINT/SIGTERM, drain in-flight requests, then stop background work
+	// (jobs, alert engine, WebSocket hub) before closing the database they use
+	err = server.Run(srv, server.DefaultShutdownTimeout,
+		cancelAppCtx,
+		scheduler.Stop,
+		alertEngine.Stop,
+		hub.Stop,
+		func() {
+			if closeErr := dbConn.Close(); closeErr != nil {
+				log.Printf("[ERROR] Failed to close database: %v", closeErr)
+			}
+		},
+	)
+	if err != nil {
+		log.Printf("[ERROR] Logs service stopped: %v", err)
+		return
 	}
+
+	log.Println("Logs service shutdown complete")
 }
 
 // runMigrations executes the database migration SQL file
@@ -682,6 +1020,29 @@ COMMENT ON COLUMN logs.entries.issue_type IS 'Categorized error type: db_connect
 COMMENT ON COLUMN logs.entries.ai_analysis IS 'Cached AI analysis result with root cause, suggested fix, and fix steps';
 COMMENT ON COLUMN logs.entries.severity_score IS 'Severity rating from AI analysis: 1-5 (1=info, 5=critical)';
 
+-- Full-text search over log messages (GET /api/logs?q=)
+ALTER TABLE logs.entries
+ADD COLUMN IF NOT EXISTS message_tsv tsvector
+GENERATED ALWAYS AS (to_tsvector('english', coalesce(message, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_logs_entries_message_tsv
+ON logs.entries USING GIN (message_tsv);
+
+-- Fingerprints of normalized messages (GET /api/logs/fingerprints)
+ALTER TABLE logs.entries
+ADD COLUMN IF NOT EXISTS fingerprint VARCHAR(16);
+
+CREATE INDEX IF NOT EXISTS idx_logs_entries_fingerprint
+ON logs.entries(fingerprint, created_at DESC);
+
+-- Soft delete: trashed entries are hidden and purged after the grace period
+ALTER TABLE logs.entries
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_logs_entries_deleted_at
+ON logs.entries(project_id, deleted_at)
+WHERE deleted_at IS NOT NULL;
+
 -- Phase 4: Health Monitoring Dashboard & Alert Engine
 -- Create monitoring schema for health metrics and alerts
 CREATE SCHEMA IF NOT EXISTS monitoring;
@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	_ "github.com/lib/pq"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/metrics"
 )
 
@@ -15,7 +23,14 @@ func main() {
 		os.Exit(1)
 	}
 
-	collector := metrics.NewCollector()
+	store, closeStore, err := openStore(os.Getenv("METRICS_DATABASE_URL"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening metrics store: %v\n", err)
+		os.Exit(1)
+	}
+	defer closeStore()
+
+	collector := metrics.NewCollector(store)
 	command := os.Args[1]
 
 	switch command {
@@ -29,6 +44,8 @@ func main() {
 		handleViolationCommand(collector, os.Args[2:])
 	case "health":
 		handleHealthCommand(collector, os.Args[2:])
+	case "summary":
+		handleSummaryCommand(collector, os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
 		printUsage()
@@ -36,6 +53,27 @@ func main() {
 	}
 }
 
+// openStore returns a Postgres store when databaseURL is set, or nil for the
+// default file store
+func openStore(databaseURL string) (metrics.Store, func(), error) {
+	if databaseURL == "" {
+		return nil, func() {}, nil
+	}
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	store := metrics.NewPostgresStore(db)
+	if err := store.Migrate(ctx); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to migrate metrics tables: %w", err)
+	}
+	return store, func() { db.Close() }, nil
+}
+
 func printUsage() {
 	fmt.Fprintf(os.Stderr, "Usage: metrics <command> [args...]\n\n")
 	fmt.Fprintf(os.Stderr, "Commands:\n")
@@ -43,7 +81,11 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "  deploy <service> <success> <duration_ms>      - Record deployment\n")
 	fmt.Fprintf(os.Stderr, "  cert <success>                                - Record certificate generation\n")
 	fmt.Fprintf(os.Stderr, "  violation <rule> <severity>                   - Record rule violation\n")
+	fmt.Fprintf(os.Stderr, "  violation trend [--since 28d] [--bucket 7d] [--threshold 0] [--fail-on-increase] [--json]\n")
+	fmt.Fprintf(os.Stderr, "                                                - Report violation counts per rule over time\n")
 	fmt.Fprintf(os.Stderr, "  health <service> <available> <response_ms>    - Record service health\n")
+	fmt.Fprintf(os.Stderr, "  summary [--since 7d] [--json]                 - Summarize recorded metrics\n")
+	fmt.Fprintf(os.Stderr, "\nMetrics are kept in test-results/metrics, or in Postgres when METRICS_DATABASE_URL is set.\n")
 }
 
 func handleTestCommand(collector *metrics.Collector, args []string) {
@@ -114,6 +156,9 @@ func handleCertCommand(collector *metrics.Collector, args []string) {
 }
 
 func handleViolationCommand(collector *metrics.Collector, args []string) {
+	if len(args) > 0 && args[0] == "trend" {
+		os.Exit(runViolationTrend(collector, args[1:], os.Stdout, time.Now()))
+	}
 	if len(args) < 2 {
 		fmt.Fprintf(os.Stderr, "Usage: metrics violation <rule> <severity>\n")
 		os.Exit(1)
@@ -152,3 +197,168 @@ func handleHealthCommand(collector *metrics.Collector, args []string) {
 	}
 	fmt.Printf("✓ Recorded service health: %s (%s, %.1fms)\n", service, status, responseMs)
 }
+
+func handleSummaryCommand(collector *metrics.Collector, args []string) {
+	if err := runSummary(collector, args, os.Stdout, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error summarizing metrics: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runSummary prints a summary of the metrics recorded since the --since
+// duration before now
+func runSummary(collector *metrics.Collector, args []string, out io.Writer, now time.Time) error {
+	fs := flag.NewFlagSet("summary", flag.ContinueOnError)
+	since := fs.String("since", "7d", "How far back to summarize, e.g. 24h or 30d")
+	asJSON := fs.Bool("json", false, "Print JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	window, err := parseSince(*since)
+	if err != nil {
+		return err
+	}
+
+	timeRange := metrics.TimeRange{Start: now.Add(-window), End: now}
+	recorded, err := collector.Query(timeRange)
+	if err != nil {
+		return err
+	}
+	summary := metrics.Summarize(recorded, timeRange)
+
+	if *asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	}
+	return printSummaryTable(out, summary)
+}
+
+// parseSince parses a Go duration, also accepting whole days such as "7d"
+func parseSince(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --since %q: use a positive duration such as 24h or 7d", s)
+	}
+	return d, nil
+}
+
+func printSummaryTable(out io.Writer, s *metrics.Summary) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Metrics from %s to %s\n\n", s.Start.Format(time.RFC3339), s.End.Format(time.RFC3339))
+	fmt.Fprintf(tw, "METRIC\tVALUE\n")
+	fmt.Fprintf(tw, "Test runs\t%d (%.1f%% passed)\n", s.TestRuns, s.RunPassRate)
+	fmt.Fprintf(tw, "Test pass rate\t%.1f%% (%d passed, %d failed)\n", s.TestPassRate, s.TestsPassed, s.TestsFailed)
+	fmt.Fprintf(tw, "Avg test duration\t%.1fs\n", s.AvgTestDurationSec)
+	fmt.Fprintf(tw, "Deployments\t%d (%.1f%% succeeded)\n", s.Deployments, s.DeploymentSuccessRate)
+	fmt.Fprintf(tw, "Certificates\t%d (%.1f%% succeeded)\n", s.Certificates, s.CertificateSuccessRate)
+	fmt.Fprintf(tw, "Service availability\t%.1f%% of %d checks\n", s.ServiceAvailability, s.HealthChecks)
+	fmt.Fprintf(tw, "Rule violations\t%d\n", s.TotalViolations)
+
+	if len(s.Violations) > 0 {
+		fmt.Fprintf(tw, "\nRULE\tSEVERITY\tCOUNT\n")
+		for _, v := range s.Violations {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", v.Rule, v.Severity, v.Count)
+		}
+	}
+	return tw.Flush()
+}
+
+// Exit codes for violation trend
+const (
+	exitOK         = 0
+	exitRegression = 1
+	exitError      = 2
+)
+
+// runViolationTrend prints violation counts per rule and bucket and returns
+// the exit code: exitRegression when --fail-on-increase is set and a rule
+// regressed beyond --threshold
+func runViolationTrend(collector *metrics.Collector, args []string, out io.Writer, now time.Time) int {
+	fs := flag.NewFlagSet("violation trend", flag.ContinueOnError)
+	since := fs.String("since", "28d", "How far back to report, e.g. 14d")
+	bucketFlag := fs.String("bucket", "7d", "Bucket size, e.g. 1d or 7d")
+	threshold := fs.Int("threshold", 0, "Flag rules whose count grew by more than this in the last bucket")
+	failOnIncrease := fs.Bool("fail-on-increase", false, "Exit with status 1 if any rule is flagged")
+	asJSON := fs.Bool("json", false, "Print JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	window, err := parseSince(*since)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+	bucket, err := parseSince(*bucketFlag)
+	if err != nil || bucket > window {
+		fmt.Fprintf(os.Stderr, "Error: invalid --bucket %q: use a duration no longer than --since\n", *bucketFlag)
+		return exitError
+	}
+
+	timeRange := metrics.TimeRange{Start: now.Add(-window), End: now}
+	report, err := printViolationTrend(collector, out, timeRange, bucket, *threshold, *asJSON)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reporting violation trend: %v\n", err)
+		return exitError
+	}
+	if *failOnIncrease && len(report.Regressions) > 0 {
+		return exitRegression
+	}
+	return exitOK
+}
+
+func printViolationTrend(collector *metrics.Collector, out io.Writer, timeRange metrics.TimeRange, bucket time.Duration, threshold int, asJSON bool) (*metrics.ViolationTrendReport, error) {
+	recorded, err := collector.Query(timeRange, metrics.MetricRuleViolation)
+	if err != nil {
+		return nil, err
+	}
+	report := metrics.ViolationTrends(recorded, timeRange, bucket, threshold)
+
+	if asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return report, enc.Encode(report)
+	}
+	return report, printViolationTrendTable(out, report)
+}
+
+func printViolationTrendTable(out io.Writer, report *metrics.ViolationTrendReport) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "RULE")
+	for _, b := range report.Buckets {
+		fmt.Fprintf(tw, "\t%s", b.Start.Format("2006-01-02"))
+	}
+	fmt.Fprint(tw, "\tCHANGE\t\n")
+	for _, trend := range report.Rules {
+		fmt.Fprint(tw, trend.Rule)
+		for _, count := range trend.Counts {
+			fmt.Fprintf(tw, "\t%d", count)
+		}
+		status := ""
+		if trend.Regressing {
+			status = "✗ regressing"
+		}
+		fmt.Fprintf(tw, "\t%+d\t%s\n", trend.Change, status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Rules) == 0 {
+		fmt.Fprintln(out, "No rule violations recorded.")
+	} else if len(report.Regressions) > 0 {
+		fmt.Fprintf(out, "\n✗ %d rule(s) increased by more than %d: %s\n", len(report.Regressions), report.Threshold, strings.Join(report.Regressions, ", "))
+	} else {
+		fmt.Fprintf(out, "\n✓ No rule increased by more than %d\n", report.Threshold)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSince(t *testing.T) {
+	d, err := parseSince("7d")
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, d)
+
+	d, err = parseSince("90m")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, d)
+
+	for _, bad := range []string{"", "d", "-1h", "seven"} {
+		_, err := parseSince(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestRunSummary(t *testing.T) {
+	t.Chdir(t.TempDir())
+	collector := metrics.NewCollector(nil)
+	require.NoError(t, collector.RecordDeployment("review", true, time.Second))
+	require.NoError(t, collector.RecordRuleViolation("no-direct-db", "high"))
+
+	var out bytes.Buffer
+	require.NoError(t, runSummary(collector, []string{"--since", "1h", "--json"}, &out, time.Now().Add(time.Minute)))
+	var summary metrics.Summary
+	require.NoError(t, json.Unmarshal(out.Bytes(), &summary))
+	assert.Equal(t, 1, summary.Deployments)
+	assert.Equal(t, 1, summary.TotalViolations)
+
+	out.Reset()
+	require.NoError(t, runSummary(collector, nil, &out, time.Now().Add(time.Minute)))
+	assert.Contains(t, out.String(), "Deployments")
+	assert.Contains(t, out.String(), "no-direct-db")
+
+	assert.Error(t, runSummary(collector, []string{"--since", "soon"}, &out, time.Now()))
+}
+
+func TestRunViolationTrend(t *testing.T) {
+	now := time.Date(2025, 11, 14, 0, 0, 0, 0, time.UTC)
+	record := func(c *metrics.Collector, rule string, n int, at time.Time) {
+		for i := 0; i < n; i++ {
+			require.NoError(t, c.Record(metrics.Metric{Type: metrics.MetricRuleViolation, Timestamp: at,
+				Metadata: map[string]interface{}{"rule": rule, "severity": "high"}}))
+		}
+	}
+	lastWeek, thisWeek := now.Add(-10*24*time.Hour), now.Add(-3*24*time.Hour)
+	args := []string{"--since", "14d", "--bucket", "7d", "--fail-on-increase"}
+
+	t.Run("improving", func(t *testing.T) {
+		collector := metrics.NewCollector(metrics.NewFileStore(t.TempDir()))
+		record(collector, "no-direct-db", 6, lastWeek)
+		record(collector, "no-direct-db", 2, thisWeek)
+
+		var out bytes.Buffer
+		assert.Equal(t, exitOK, runViolationTrend(collector, args, &out, now))
+		assert.Contains(t, out.String(), "✓ No rule increased")
+	})
+
+	t.Run("regressing", func(t *testing.T) {
+		collector := metrics.NewCollector(metrics.NewFileStore(t.TempDir()))
+		record(collector, "no-direct-db", 2, lastWeek)
+		record(collector, "no-direct-db", 1, thisWeek)
+		record(collector, "tdd-required", 1, lastWeek)
+		record(collector, "tdd-required", 4, thisWeek)
+
+		var out bytes.Buffer
+		assert.Equal(t, exitRegression, runViolationTrend(collector, args, &out, now))
+		assert.Contains(t, out.String(), "1 rule(s) increased by more than 0: tdd-required")
+
+		out.Reset()
+		assert.Equal(t, exitOK, runViolationTrend(collector, args[:4], &out, now), "regressions only fail with --fail-on-increase")
+
+		out.Reset()
+		assert.Equal(t, exitOK, runViolationTrend(collector, append(args, "--threshold", "3", "--json"), &out, now))
+		var report metrics.ViolationTrendReport
+		require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+		assert.Empty(t, report.Regressions)
+		assert.Equal(t, []int{1, 4}, report.Rules[0].Counts)
+	})
+
+	t.Run("invalid bucket", func(t *testing.T) {
+		collector := metrics.NewCollector(metrics.NewFileStore(t.TempDir()))
+		assert.Equal(t, exitError, runViolationTrend(collector, []string{"--since", "7d", "--bucket", "14d"}, &bytes.Buffer{}, now))
+	})
+}
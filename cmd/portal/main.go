@@ -6,6 +6,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -18,11 +19,13 @@ import (
 	handlers "github.com/mikejsmith1985/devsmith-modular-platform/apps/portal/handlers"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/common/debug"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/health"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/instrumentation"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/middleware"
 	portal_handlers "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/handlers"
 	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
 	portal_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/services"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/server"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
 )
 
@@ -36,6 +39,20 @@ func main() {
 	// Create Gin router
 	router := gin.Default()
 
+	// CORS runs first so preflight requests are answered before auth
+	corsConfig, err := config.LoadCORSConfig()
+	if err != nil {
+		log.Fatalf("Failed to load CORS configuration: %v", err)
+	}
+	router.Use(middleware.CORSMiddleware(corsConfig))
+
+	// Cap request bodies and handler time (REQUEST_MAX_BODY_BYTES, REQUEST_TIMEOUT_SECONDS)
+	requestLimits, err := config.LoadRequestLimits("REQUEST_", config.DefaultRequestLimits)
+	if err != nil {
+		log.Fatalf("Failed to load request limits: %v", err)
+	}
+	router.Use(middleware.RequestLimitsMiddleware(requestLimits, nil))
+
 	// Initialize instrumentation logger for this service (use validated config)
 	logsServiceURL, logsEnabled, err := config.LoadLogsConfigWithFallbackFor("portal")
 	if err != nil {
@@ -62,13 +79,8 @@ func main() {
 	})
 
 	// Health check endpoint - moved to /api/portal/health to avoid conflict with frontend /health route
-	router.GET("/api/portal/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "healthy",
-			"service": "portal",
-			"version": "1.0.0",
-		})
-	})
+	portalHealth := health.NewHandler("portal")
+	portalHealth.Register(router, "/api/portal/health")
 
 	// Database connection
 	dbURL := os.Getenv("DATABASE_URL")
@@ -93,6 +105,21 @@ func main() {
 		return
 	}
 
+	// One-off admin command: re-encrypt stored API keys with the primary key
+	if len(os.Args) > 1 && os.Args[1] == "rotate-keys" {
+		rotated, rotateErr := portal_services.NewEncryptionService().RotateKeys(
+			context.Background(), portal_repositories.NewLLMConfigRepository(dbConn))
+		log.Printf("Re-encrypted %d API keys", rotated)
+		if closeErr := dbConn.Close(); closeErr != nil {
+			log.Printf("Error closing DB connection: %v", closeErr)
+		}
+		if rotateErr != nil {
+			log.Printf("Key rotation incomplete: %v", rotateErr)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Initialize Redis session store
 	redisURL := os.Getenv("REDIS_URL")
 	if redisURL == "" {
@@ -112,6 +139,7 @@ func main() {
 		}
 	}()
 	log.Printf("Redis session store initialized at %s", redisURL)
+	portalHealth.AddCheck("database", health.Ping(dbConn.PingContext))
 
 	// Initialize LLM configuration services
 	encryptionService := portal_services.NewEncryptionService()
@@ -119,6 +147,7 @@ func main() {
 	llmConfigService := portal_services.NewLLMConfigService(llmConfigRepo, encryptionService)
 
 	// Register authentication routes (pass session store)
+	handlers.SetAuthAuditRecorder(portal_repositories.NewAuthAuditRepository(dbConn))
 	handlers.RegisterAuthRoutesWithSession(router, dbConn, sessionStore)
 
 	// Register version endpoint (public - no auth required)
@@ -139,6 +168,18 @@ func main() {
 	apiAuthenticated := router.Group("/api/portal")
 	apiAuthenticated.Use(middleware.RedisSessionAuthMiddleware(sessionStore))
 	portal_handlers.RegisterLLMConfigRoutes(apiAuthenticated, llmConfigService)
+	// Decrypted API keys are only served to services holding the shared token
+	if serviceToken := os.Getenv("PORTAL_SERVICE_TOKEN"); serviceToken != "" {
+		portal_handlers.RegisterInternalLLMConfigRoutes(apiAuthenticated, llmConfigService, serviceToken)
+	} else {
+		log.Println("PORTAL_SERVICE_TOKEN not set; services can't resolve API keys from Portal")
+	}
+	portal_handlers.RegisterSessionRoutes(apiAuthenticated, sessionStore)
+	llmUsageRepo := portal_repositories.NewLLMUsageRepository(dbConn)
+	portal_handlers.RegisterLLMUsageRoutes(apiAuthenticated, llmUsageRepo)
+	portal_handlers.RegisterLLMBudgetRoutes(apiAuthenticated, portal_services.NewBudgetService(
+		portal_repositories.NewLLMBudgetRepository(dbConn), llmUsageRepo))
+	portal_handlers.RegisterAppPreferencesRoutes(apiAuthenticated, portal_repositories.NewAppPreferencesRepository(dbConn))
 
 	// Serve static files (path works in both local dev and Docker)
 	staticPath := "apps/portal/static"
@@ -183,14 +224,22 @@ func main() {
 
 	// Replace fmt.Printf with log.Printf for better logging consistency
 	log.Printf("Portal service starting on port %s...", port)
-	if err := router.Run(":" + port); err != nil {
-		// Replace os.Exit with proper error handling
-		log.Printf("Failed to start server: %v", err)
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           router,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	// On SIGINT/SIGTERM, drain in-flight requests before closing the database
+	err = server.Run(srv, server.DefaultShutdownTimeout, func() {
 		if closeErr := dbConn.Close(); closeErr != nil {
 			log.Printf("Error closing DB connection: %v", closeErr)
 		}
+	})
+	if err != nil {
+		log.Printf("Failed to start server: %v", err)
 		os.Exit(1) // Ensure the application exits with a non-zero status
 	}
+	log.Printf("Portal service shutdown complete")
 }
 
 func validateOAuthEnvironment() error {
@@ -7,28 +7,36 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"os/signal"
 	"strconv"
-	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	app_handlers "github.com/mikejsmith1985/devsmith-modular-platform/apps/review/handlers"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai/providers"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/common/debug"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/health"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/logging"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/middleware"
+	review_cache "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/cache"
 	review_circuit "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/circuit"
 	review_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/db"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/review/github"
 	review_handlers "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/handlers"
 	review_health "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/health"
+	review_retry "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/retry"
 	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
 	review_tracing "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/tracing"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/server"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 )
 
 // nolint:gocyclo // Main initialization is inherently complex with multiple setup steps
@@ -39,6 +47,39 @@ func main() {
 
 	router := gin.Default()
 
+	// CORS runs first so preflight requests are answered before auth
+	corsConfig, err := config.LoadCORSConfig()
+	if err != nil {
+		log.Fatalf("Failed to load CORS configuration: %v", err)
+	}
+	router.Use(middleware.CORSMiddleware(corsConfig))
+
+	// Cap request bodies and handler time (REQUEST_MAX_BODY_BYTES,
+	// REQUEST_TIMEOUT_SECONDS). Analysis routes take pasted code and wait on
+	// the LLM, so they get their own REVIEW_ANALYSIS_* limits.
+	requestLimits, err := config.LoadRequestLimits("REQUEST_", config.DefaultRequestLimits)
+	if err != nil {
+		log.Fatalf("Failed to load request limits: %v", err)
+	}
+	analysisLimits, err := config.LoadRequestLimits("REVIEW_ANALYSIS_", config.RequestLimits{
+		MaxBodyBytes: 10 << 20, // 10 MiB
+		Timeout:      5 * time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("Failed to load analysis request limits: %v", err)
+	}
+	router.Use(middleware.RequestLimitsMiddleware(requestLimits, map[string]config.RequestLimits{
+		"/api/review/sessions":             analysisLimits,
+		"/api/review/modes/preview":        analysisLimits,
+		"/api/review/modes/skim":           analysisLimits,
+		"/api/review/modes/scan":           analysisLimits,
+		"/api/review/modes/detailed":       analysisLimits,
+		"/api/review/modes/critical":       analysisLimits,
+		"/api/review/modes/compare":        analysisLimits,
+		"/api/review/modes/:mode/stream":   analysisLimits,
+		"/api/review/sessions/:id/analyze": analysisLimits,
+	}))
+
 	// Load and validate logs service configuration (allow configurable fallback)
 	logURL, logsEnabled, err := config.LoadLogsConfigWithFallbackFor("review")
 	if err != nil {
@@ -61,6 +102,7 @@ func main() {
 		BatchTimeoutSec: 5,
 		LogToStdout:     true,
 		EnableStdout:    true,
+		Format:          os.Getenv("LOG_FORMAT"), // "text" (default) or "json"
 	})
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
@@ -78,6 +120,7 @@ func main() {
 		defer shutdownTracer(context.Background())
 		log.Printf("Tracing initialized (endpoint: %s)", tracingEndpoint)
 	}
+	router.Use(tracing.Middleware("devsmith-review"))
 
 	// Middleware: Log all requests (async, non-blocking)
 	router.Use(func(c *gin.Context) {
@@ -165,20 +208,44 @@ func main() {
 	reviewLogger.Info("Initializing AI client", "portal_url", portalURL, "config_source", "Portal AI Factory")
 
 	unifiedAIClient := review_services.NewUnifiedAIClient(portalURL)
+	unifiedAIClient.SetPortalServiceToken(os.Getenv("PORTAL_SERVICE_TOKEN"))
+	unifiedAIClient.SetUsageRecorder(ai.NewPostgresUsageRecorder(sqlDB), reviewLogger)
+
+	retryConfig, err := config.LoadAIRetryConfig()
+	if err != nil {
+		log.Fatalf("Invalid AI retry configuration: %v", err)
+	}
+	unifiedAIClient.SetRetryPolicy(review_retry.Config{
+		MaxRetries:        retryConfig.MaxAttempts,
+		InitialDelay:      retryConfig.InitialDelay,
+		BackoffMultiplier: 2,
+		MaxDelay:          retryConfig.MaxDelay,
+		JitterFraction:    0.2,
+	})
 
 	// Wrap unified AI client with circuit breaker for resilience
-	aiClientWithCircuitBreaker := review_circuit.NewOllamaCircuitBreaker(unifiedAIClient, reviewLogger)
-	reviewLogger.Info("Circuit breaker initialized", "threshold", 5, "timeout", "60s")
+	breakerConfig, err := config.LoadCircuitBreakerConfig()
+	if err != nil {
+		log.Fatalf("Invalid circuit breaker configuration: %v", err)
+	}
+	aiClientWithCircuitBreaker := review_circuit.NewOllamaCircuitBreaker(unifiedAIClient, reviewLogger, breakerConfig)
+	reviewLogger.Info("Circuit breaker initialized",
+		"threshold", breakerConfig.Threshold,
+		"timeout", breakerConfig.Timeout.String(),
+		"half_open_max", breakerConfig.HalfOpenMax)
 
 	// NOTE: ModelService and MultiFileAnalyzer still use direct Ollama for model discovery
 	// These will be refactored in future to use Portal AI Factory as well
 	// For now, we keep a minimal Ollama client just for these legacy services
-	ollamaEndpoint := os.Getenv("OLLAMA_ENDPOINT")
-	if ollamaEndpoint == "" {
-		ollamaEndpoint = "http://host.docker.internal:11434"
+	// OLLAMA_ENDPOINTS lists several instances to balance across
+	ollamaEndpoints, err := config.LoadOllamaEndpoints()
+	if err != nil {
+		log.Fatalf("Invalid Ollama configuration: %v", err)
 	}
 	ollamaDefaultModel := "mistral:7b-instruct" // Used only for multiFileAnalyzer fallback
-	ollamaClient := providers.NewOllamaClient(ollamaEndpoint, ollamaDefaultModel)
+	ollamaClient := providers.NewOllamaPool(ollamaEndpoints, ollamaDefaultModel)
+	ollamaClient.StartHealthChecks(appCtx, 30*time.Second)
+	reviewLogger.Info("Ollama endpoint pool initialized", "endpoints", len(ollamaEndpoints))
 
 	// Wire up services with circuit breaker wrapper (fail-fast when AI is unhealthy)
 	previewService := review_services.NewPreviewService(aiClientWithCircuitBreaker, reviewLogger)
@@ -199,53 +266,105 @@ func main() {
 		reviewLogger,
 	)
 
-	// Health and root endpoints (registered after healthChecker initialization)
-	router.GET("/api/review/health", func(c *gin.Context) {
-		// Perform comprehensive health check
-		health, err := healthChecker.CheckHealth(c.Request.Context())
-		if err != nil {
-			reviewLogger.Error("Health check failed", "error", err)
-			c.JSON(500, gin.H{
-				"service": "review",
-				"status":  "error",
-				"error":   err.Error(),
-			})
-			return
-		}
-
-		// Map health status to HTTP status code
-		statusCode := 200
-		if health.Status == review_health.HealthStatusDegraded {
-			statusCode = 200 // Still serving traffic
-		} else if health.Status == review_health.HealthStatusUnhealthy {
-			statusCode = 503 // Service unavailable
-		}
-
-		c.JSON(statusCode, health)
-	})
-	router.HEAD("/health", func(c *gin.Context) {
-		reviewLogger.Info("HEAD /health endpoint hit")
-		c.Status(200)
-	})
-	// Add GET handler for Traefik load balancer health check (uses GET by default)
-	router.GET("/health", func(c *gin.Context) {
-		reviewLogger.Info("GET /health endpoint hit")
-		c.Status(200)
-	})
+	// Health endpoints (registered after healthChecker initialization). The
+	// API endpoint checks every component; /health is the cheap liveness
+	// probe Traefik polls.
+	reviewHealth := health.NewHandler("review")
+	reviewHealth.AddCheckSet(healthChecker.Checks)
+	reviewHealth.AddCheck("ai_circuit", aiClientWithCircuitBreaker.HealthCheck)
+	reviewHealth.Register(router, "/api/review/health")
+	health.NewHandler("review").Register(router, "/health")
+
+	// Prometheus metrics (Go runtime plus AI circuit breaker state) at /metrics
+	metricsRegistry := prometheus.NewRegistry()
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	aiClientWithCircuitBreaker.RegisterMetrics(metricsRegistry)
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{Registry: metricsRegistry})))
 
 	// Prepare logging client to send lightweight events to Logs service (optional)
 	var logClient *logging.Client
 	if logsEnabled && logURL != "" {
 		logClient = logging.NewClient(logURL)
+		logClient.SetFailureHandler(func(err error, pending int) {
+			reviewLogger.Warn("Failed to send events to Logs service", "error", err.Error(), "pending", pending, "dropped", logClient.Dropped())
+		})
+		logClient.RegisterMetrics(metricsRegistry)
+		logClient.Start(appCtx, 5*time.Second)
 	} else {
 		logClient = nil
 	}
 
-	// Create model service for dynamic model discovery (needs Ollama endpoint)
-	modelService := review_services.NewModelService(reviewLogger, ollamaEndpoint)
+	// Create model service for dynamic model discovery (needs Ollama endpoint).
+	// Pooled instances serve the same models, so the first one is asked.
+	modelService := review_services.NewModelService(reviewLogger, ollamaEndpoints[0])
+
+	// Identical analyses (same code, mode, options and the user's resolved
+	// provider and model) share one AI call for REVIEW_CACHE_TTL; 0 disables
+	// the cache
+	cacheTTL, err := config.LoadReviewCacheTTL()
+	if err != nil {
+		log.Fatalf("Invalid review cache configuration: %v", err)
+	}
+	// Concurrent identical analyses from one session share one in-flight AI call
+	dedup := review_cache.NewDeduplicator()
+	var (
+		previewAnalyzer  = dedup.Preview(previewService)
+		skimAnalyzer     = dedup.Skim(skimService)
+		scanAnalyzer     = dedup.Scan(scanService)
+		detailedAnalyzer = dedup.Detailed(detailedService)
+		criticalAnalyzer = dedup.Critical(criticalService)
+	)
+	if cacheTTL > 0 {
+		cacheClient := redis.NewClient(&redis.Options{Addr: redisAddr})
+		defer func() {
+			if err := cacheClient.Close(); err != nil {
+				log.Printf("Error closing Redis cache client: %v", err)
+			}
+		}()
+		responseCache := review_cache.NewResponseCache(cacheClient, cacheTTL, unifiedAIClient, reviewLogger)
+		previewAnalyzer = responseCache.Preview(previewAnalyzer)
+		skimAnalyzer = responseCache.Skim(skimAnalyzer)
+		scanAnalyzer = responseCache.Scan(scanAnalyzer)
+		detailedAnalyzer = responseCache.Detailed(detailedAnalyzer)
+		criticalAnalyzer = responseCache.Critical(criticalAnalyzer)
+		reviewLogger.Info("Review response cache enabled", "ttl", cacheTTL.String())
+	}
 
 	// Handler setup with services (UIHandler takes logger, logging client, and AI services)
-	uiHandler := app_handlers.NewUIHandler(reviewLogger, logClient, previewService, skimService, scanService, detailedService, criticalService, modelService)
+	uiHandler := app_handlers.NewUIHandler(reviewLogger, logClient, previewAnalyzer, skimAnalyzer, scanAnalyzer, detailedAnalyzer, criticalAnalyzer, modelService)
+	// Persist mode results per session so reloads and repeats skip the LLM
+	uiHandler.SetAnalysisStore(analysisRepo)
+	uiHandler.SetSessionStore(analysisRepo)
+	uiHandler.SetAnalysisSearcher(analysisRepo)
+	// Review defaults are kept with the user's other settings in Portal
+	uiHandler.SetPreferenceStore(review_services.NewPortalPreferenceStore(portalURL))
+	// Share links are signed with the platform JWT secret
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		uiHandler.SetAnalysisSharer(review_services.NewShareService(review_db.NewShareRepository(sqlDB), analysisRepo, []byte(secret)))
+	} else {
+		reviewLogger.Warn("JWT_SECRET not set - analysis share links are disabled")
+	}
+
+	// Optional JSON rubric for Critical mode grades (see review_services.ParseGradePolicy)
+	if v := os.Getenv("REVIEW_GRADE_POLICY"); v != "" {
+		if policy, err := review_services.ParseGradePolicy([]byte(v)); err != nil {
+			reviewLogger.Warn("Ignoring invalid REVIEW_GRADE_POLICY, using default rubric", "error", err.Error())
+		} else {
+			uiHandler.SetGradePolicy(policy)
+		}
+	}
+
+	// Per-user cap on analyses in flight (default app_handlers.DefaultMaxConcurrentAnalyses)
+	if v := os.Getenv("REVIEW_MAX_CONCURRENT_ANALYSES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			uiHandler.SetMaxConcurrentAnalyses(n)
+		} else {
+			reviewLogger.Warn("Ignoring invalid REVIEW_MAX_CONCURRENT_ANALYSES", "value", v)
+		}
+	}
 
 	// Initialize GitHub client for Phase 2 GitHub integration
 	githubToken := os.Getenv("GITHUB_TOKEN")
@@ -265,17 +384,52 @@ func main() {
 	// Initialize GitHub handler for Phase 1 GitHub integration (tree, file, quick-scan endpoints)
 	// Pass previewService so Quick Scan can run AI analysis
 	githubHandler := review_handlers.NewGitHubHandler(reviewLogger, previewService)
+	githubHandler.SetTokenStore(sessionStore)
+	// Tokens from GitHub Apps with expiring user tokens are refreshed; OAuth
+	// App tokens never expire and are only invalidated when GitHub rejects them
+	if clientID, clientSecret := os.Getenv("GITHUB_CLIENT_ID"), os.Getenv("GITHUB_CLIENT_SECRET"); clientID != "" && clientSecret != "" {
+		githubHandler.SetTokenRefresher(github.NewTokenRefresher(clientID, clientSecret))
+	}
+
+	// GitHub webhook: push and pull_request events trigger a background Critical
+	// mode review. There is no user session to resolve an AI Factory config
+	// from, so these reviews go to Ollama directly.
+	webhookUserID, _ := strconv.ParseInt(os.Getenv("REVIEW_WEBHOOK_USER_ID"), 10, 64)
+	webhookMaxConcurrent, _ := strconv.Atoi(os.Getenv("REVIEW_WEBHOOK_MAX_CONCURRENT"))
+	webhookAI := review_circuit.NewOllamaCircuitBreaker(review_services.NewOllamaClientAdapter(ollamaClient), reviewLogger, breakerConfig)
+	webhookHandler := review_handlers.NewGitHubWebhookHandler(
+		review_handlers.GitHubWebhookConfig{
+			Secret:        os.Getenv("GITHUB_WEBHOOK_SECRET"),
+			Token:         githubToken,
+			Model:         ollamaDefaultModel,
+			UserID:        webhookUserID,
+			MaxConcurrent: webhookMaxConcurrent,
+		},
+		githubClient,
+		review_services.NewCriticalService(webhookAI, analysisRepo, reviewLogger),
+		review_db.NewReviewRepository(sqlDB),
+		analysisRepo,
+		reviewLogger,
+	)
+	if os.Getenv("GITHUB_WEBHOOK_SECRET") == "" {
+		reviewLogger.Warn("GITHUB_WEBHOOK_SECRET not set - GitHub webhook reviews are disabled")
+	}
 
 	// Initialize prompt template service and handler for prompt management
 	promptService := review_services.NewPromptTemplateService(promptRepo)
 	promptHandler := review_handlers.NewPromptHandler(promptService)
 
+	circuitHandler := review_handlers.NewCircuitHandler(aiClientWithCircuitBreaker)
+
 	// Serve static files (CSS, JS) from apps/review/static
 	router.Static("/static", "./apps/review/static")
 	reviewLogger.Info("Static files configured", "path", "/static", "dir", "./apps/review/static")
 
 	// Public endpoints (no authentication required)
-	router.GET("/api/review/models", uiHandler.GetAvailableModels) // Model list is public
+	router.GET("/api/review/models", uiHandler.GetAvailableModels)           // Model list is public
+	router.POST("/api/review/webhooks/github", webhookHandler.HandleWebhook) // Authenticated by HMAC signature
+	router.GET("/api/review/circuit", circuitHandler.GetCircuit)             // AI breaker state for operators
+	router.GET("/api/review/shared/:token", uiHandler.SharedAnalysisHandler) // Authenticated by the signed share token
 
 	// Home/landing page - REQUIRES authentication via Redis session (SSO with Portal)
 	// Handles both / (legacy direct access) and /review (Traefik gateway access)
@@ -301,11 +455,15 @@ func main() {
 		protected.POST("/api/review/modes/scan", uiHandler.HandleScanMode)
 		protected.POST("/api/review/modes/detailed", uiHandler.HandleDetailedMode)
 		protected.POST("/api/review/modes/critical", uiHandler.HandleCriticalMode)
+		protected.POST("/api/review/modes/compare", uiHandler.HandleCompareMode)
+		protected.POST("/api/review/modes/:mode/stream", uiHandler.HandleModeStream)
 
 		// Session management endpoints (all require auth)
 		protected.GET("/api/review/sessions/list", uiHandler.ListSessionsHTMX)
 		protected.GET("/api/review/sessions/search", uiHandler.SearchSessionsHTMX)
 		protected.GET("/api/review/sessions/:id", uiHandler.GetSessionDetailHTMX)
+		protected.GET("/api/review/sessions/:id/export", uiHandler.ExportSessionHTMX)
+		protected.GET("/api/review/analyses/search", uiHandler.SearchAnalysesHandler)
 		protected.POST("/api/review/sessions/:id/resume", uiHandler.ResumeSessionHTMX)
 		protected.POST("/api/review/sessions/:id/duplicate", uiHandler.DuplicateSessionHTMX)
 		protected.POST("/api/review/sessions/:id/archive", uiHandler.ArchiveSessionHTMX)
@@ -326,15 +484,26 @@ func main() {
 		protected.GET("/api/review/github/quick-scan", githubHandler.QuickRepoScan)
 
 		// Prompt template endpoints (Issue #2 - Details button)
-		protected.GET("/api/review/prompts", promptHandler.GetPrompt)
-		protected.PUT("/api/review/prompts", promptHandler.SavePrompt)
-		protected.DELETE("/api/review/prompts", promptHandler.ResetPrompt)
+		// Loading a prompt issues the CSRF token that saving it requires
+		csrf := middleware.CSRFMiddleware()
+		protected.GET("/api/review/prompts", csrf, promptHandler.GetPrompt)
+		protected.PUT("/api/review/prompts", csrf, promptHandler.SavePrompt)
+		protected.DELETE("/api/review/prompts", csrf, promptHandler.ResetPrompt)
 		protected.GET("/api/review/prompts/history", promptHandler.GetHistory)
+		protected.GET("/api/review/prompts/versions", promptHandler.GetVersions)
+		protected.POST("/api/review/prompts/rollback", csrf, promptHandler.RollbackPrompt)
+
+		// Read-only share links for analysis results
+		protected.POST("/api/review/analyses/:id/share", csrf, uiHandler.ShareAnalysisHandler)
+		protected.DELETE("/api/review/shares/:id", csrf, uiHandler.RevokeShareHandler)
+
+		// Per-user defaults for mode, user_mode, output_mode and model
+		protected.GET("/api/review/preferences", csrf, uiHandler.GetPreferencesHandler)
+		protected.PUT("/api/review/preferences", csrf, uiHandler.PutPreferencesHandler)
 	}
 	router.DELETE("/api/review/sessions/:id", uiHandler.DeleteSessionHTMX)            // Delete session (HTMX, replaces sessionHandler.DeleteSession)
 	router.GET("/api/review/sessions/:id/stats", uiHandler.GetSessionStatsHTMX)       // Session statistics
 	router.GET("/api/review/sessions/:id/metadata", uiHandler.GetSessionMetadataHTMX) // Session metadata
-	router.GET("/api/review/sessions/:id/export", uiHandler.ExportSessionHTMX)        // Export session
 
 	// Debug routes (TODO: remove in production or guard with env flag)
 	app_handlers.RegisterDebugRoutes(router)
@@ -350,30 +519,22 @@ func main() {
 		Handler: router,
 	}
 
-	// Start server in goroutine
-	go func() {
-		reviewLogger.Info("Review service starting", "port", port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			reviewLogger.Error("Failed to start server", "error", err)
+	reviewLogger.Info("Review service starting", "port", port)
+	// On SIGINT/SIGTERM, drain in-flight requests, let background webhook
+	// reviews finish, then cancel the app context to stop the retention job
+	// and other background tasks
+	err = server.Run(srv, server.DefaultShutdownTimeout, webhookHandler.Wait, cancelAppCtx)
+
+	// Deliver buffered events before exiting, within a bounded deadline
+	if logClient != nil {
+		closeCtx, cancelClose := context.WithTimeout(context.Background(), logging.DefaultCloseTimeout)
+		if closeErr := logClient.Close(closeCtx); closeErr != nil {
+			reviewLogger.Warn("Failed to flush events to Logs service on shutdown", "error", closeErr.Error())
 		}
-	}()
-
-	// Wait for interrupt signal for graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	reviewLogger.Info("Shutting down gracefully...")
-
-	// Cancel app context to signal retention job and other background tasks to stop
-	cancelAppCtx()
-
-	// Give outstanding requests 30 seconds to complete
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := srv.Shutdown(ctx); err != nil {
-		reviewLogger.Error("Server forced to shutdown", "error", err)
+		cancelClose()
+	}
+	if err != nil {
+		reviewLogger.Error("Server stopped with error", "error", err)
 		return
 	}
 
@@ -0,0 +1,17 @@
+-- Migration: Store mode results for workspace reloads
+-- Date: 2025-11-13
+-- Purpose: Keep the structured output of each mode run keyed by session and
+-- input hash, so reloading a workspace (or re-running the same input) does
+-- not call the LLM again
+
+ALTER TABLE reviews.analysis_results
+    ADD COLUMN IF NOT EXISTS input_hash VARCHAR(64),  -- sha256 of mode + code + options
+    ADD COLUMN IF NOT EXISTS output JSONB;           -- Mode output as returned to the UI
+
+-- Latest result per session/mode/input
+CREATE INDEX IF NOT EXISTS idx_analysis_results_cache
+    ON reviews.analysis_results(review_id, mode, input_hash, created_at DESC)
+    WHERE output IS NOT NULL;
+
+COMMENT ON COLUMN reviews.analysis_results.input_hash IS 'Hash of the inputs that produced output; NULL for troubleshooting captures';
+COMMENT ON COLUMN reviews.analysis_results.output IS 'Structured mode output served from cache when input_hash matches';
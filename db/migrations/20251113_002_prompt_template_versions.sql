@@ -0,0 +1,32 @@
+-- Migration: 20251113_002_prompt_template_versions
+-- Description: Keep every saved version of custom prompt templates so users can roll back
+-- Date: 2025-11-13
+
+-- One row per save or rollback. template_id is not a foreign key so history
+-- survives a factory reset and a deleted template can be restored.
+CREATE TABLE IF NOT EXISTS review.prompt_template_versions (
+    id SERIAL PRIMARY KEY,
+    template_id VARCHAR(64) NOT NULL,
+    user_id INT NOT NULL,
+    mode VARCHAR(20) NOT NULL,
+    user_level VARCHAR(20) NOT NULL,
+    output_mode VARCHAR(20) NOT NULL,
+    prompt_text TEXT NOT NULL,
+    variables JSONB DEFAULT '[]'::jsonb,
+    version INT NOT NULL,
+    restored_from INT REFERENCES review.prompt_template_versions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_prompt_template_versions_template
+    ON review.prompt_template_versions(user_id, mode, user_level, output_mode, created_at DESC);
+
+-- Seed history with the current custom templates
+INSERT INTO review.prompt_template_versions
+    (template_id, user_id, mode, user_level, output_mode, prompt_text, variables, version, created_at)
+SELECT id, user_id, mode, user_level, output_mode, prompt_text, variables, version, updated_at
+FROM review.prompt_templates
+WHERE user_id IS NOT NULL;
+
+COMMENT ON TABLE review.prompt_template_versions IS 'Audit history of custom prompt templates; each save and rollback adds a row';
+COMMENT ON COLUMN review.prompt_template_versions.restored_from IS 'Version row this one was rolled back to, NULL for ordinary saves';
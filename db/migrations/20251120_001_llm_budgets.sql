@@ -0,0 +1,14 @@
+-- Migration: 20251120_001_llm_budgets
+-- Description: Optional monthly USD budget for each user's hosted LLM usage
+-- Date: 2025-11-20
+
+-- No row means no limit. Spend is summed from portal.llm_usage_logs for the
+-- current calendar month (UTC).
+CREATE TABLE IF NOT EXISTS portal.llm_budgets (
+    user_id INT PRIMARY KEY REFERENCES portal.users(id) ON DELETE CASCADE,
+    monthly_limit_usd DECIMAL(10,2) NOT NULL CHECK (monthly_limit_usd >= 0),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+COMMENT ON TABLE portal.llm_budgets IS 'Per-user monthly AI spend limits; AI calls are refused once exceeded';
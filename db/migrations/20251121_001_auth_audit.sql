@@ -0,0 +1,24 @@
+-- Migration: 20251121_001_auth_audit
+-- Description: Audit trail of logins, logouts, token exchanges and failed OAuth state checks
+-- Date: 2025-11-21
+
+-- user_id is NULL for events before a portal user is known (failed state
+-- checks, failed token exchanges). Rows outlive the user so the trail stays
+-- intact after an account is deleted.
+CREATE TABLE IF NOT EXISTS portal.auth_audit (
+    id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('success', 'failure')),
+    user_id INT,
+    github_id BIGINT,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_audit_created_at ON portal.auth_audit(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_user ON portal.auth_audit(user_id, created_at DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_auth_audit_failures ON portal.auth_audit(event_type, created_at DESC) WHERE outcome = 'failure';
+
+COMMENT ON TABLE portal.auth_audit IS 'Security audit trail of authentication events; append-only';
@@ -0,0 +1,24 @@
+-- Migration: Indexes for searching the analysis history
+-- Date: 2025-11-22
+-- Purpose: Back GET /api/review/analyses/search, which pages through a user's
+--          stored mode results filtered by mode, grade, repository and date
+
+-- A user's sessions are joined to their results and read newest first
+CREATE INDEX IF NOT EXISTS idx_analysis_results_review_created
+    ON reviews.analysis_results(review_id, created_at DESC, id DESC);
+
+-- Mode plus date range without a repository filter
+CREATE INDEX IF NOT EXISTS idx_analysis_results_mode_created
+    ON reviews.analysis_results(mode, created_at DESC);
+
+-- Grade rank (F=1 .. A=5) of Critical Mode results, matching the expression
+-- used by AnalysisRepository.SearchAnalyses
+CREATE INDEX IF NOT EXISTS idx_analysis_results_grade_rank
+    ON reviews.analysis_results ((position(NULLIF(upper(left(output->>'overall_grade', 1)), '') IN 'FDCBA')))
+    WHERE output ? 'overall_grade';
+
+-- Repository substring matches (ILIKE '%...%') need a trigram index
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_github_repo_trgm
+    ON reviews.sessions USING GIN (github_repo gin_trgm_ops);
@@ -0,0 +1,19 @@
+-- Migration: Read-only share links for analysis results
+-- Date: 2025-11-22
+-- Purpose: Back POST /api/review/analyses/:id/share and the public
+--          GET /api/review/shared/:token. The token itself is signed and
+--          carries its expiry; a row per link lets the owner revoke it early.
+
+CREATE TABLE IF NOT EXISTS reviews.analysis_shares (
+    id VARCHAR(32) PRIMARY KEY,  -- Random hex, embedded in the token
+    analysis_id INTEGER NOT NULL REFERENCES reviews.analysis_results(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_shares_analysis ON reviews.analysis_shares(analysis_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_shares_user ON reviews.analysis_shares(user_id, created_at DESC);
+
+COMMENT ON TABLE reviews.analysis_shares IS 'Read-only share links for analysis results; revoked_at set when the owner revokes a link';
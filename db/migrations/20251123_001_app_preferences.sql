@@ -0,0 +1,16 @@
+-- Migration: 20251123_001_app_preferences
+-- Description: Per-user settings for each app, such as Review's default mode
+-- Date: 2025-11-23
+
+-- Portal stores the settings as an opaque JSON object; each app validates
+-- and interprets its own keys. No row means the app's built-in defaults.
+CREATE TABLE IF NOT EXISTS portal.app_preferences (
+    user_id INT NOT NULL REFERENCES portal.users(id) ON DELETE CASCADE,
+    app_name VARCHAR(50) NOT NULL CHECK (app_name IN ('review', 'logs', 'analytics')),
+    preferences JSONB NOT NULL DEFAULT '{}'::jsonb CHECK (jsonb_typeof(preferences) = 'object'),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (user_id, app_name)
+);
+
+COMMENT ON TABLE portal.app_preferences IS 'Per-user, per-app settings stored on behalf of the apps';
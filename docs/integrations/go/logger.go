@@ -35,9 +35,14 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// DefaultMaxBuffer caps how many entries wait in memory while the API is
+// unreachable; beyond it the oldest entries are dropped
+const DefaultMaxBuffer = 10000
+
 // LogEntry represents a single log entry
 type LogEntry struct {
 	Timestamp string                 `json:"timestamp"`
@@ -63,7 +68,11 @@ type DevSmithLogger struct {
 	flushInterval time.Duration
 
 	buffer     []LogEntry
+	maxBuffer  int
+	dropped    atomic.Uint64
+	onFailure  func(err error, pending int)
 	mutex      sync.Mutex
+	flushMu    sync.Mutex
 	ticker     *time.Ticker
 	httpClient *http.Client
 	done       chan bool
@@ -102,6 +111,7 @@ func NewLoggerWithOptions(
 		batchSize:     batchSize,
 		flushInterval: flushInterval,
 		buffer:        make([]LogEntry, 0, batchSize),
+		maxBuffer:     DefaultMaxBuffer,
 		ticker:        time.NewTicker(flushInterval),
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
@@ -115,6 +125,45 @@ func NewLoggerWithOptions(
 	return logger
 }
 
+// SetMaxBuffer caps the number of buffered entries (default DefaultMaxBuffer)
+func (l *DevSmithLogger) SetMaxBuffer(n int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if n > 0 {
+		l.maxBuffer = n
+		l.trimLocked()
+	}
+}
+
+// SetFailureHandler registers fn to be called when a flush fails, with the
+// error and the number of entries still buffered. Without one, failures are
+// printed.
+func (l *DevSmithLogger) SetFailureHandler(fn func(err error, pending int)) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.onFailure = fn
+}
+
+// Dropped returns how many entries were dropped because the buffer was full
+func (l *DevSmithLogger) Dropped() uint64 {
+	return l.dropped.Load()
+}
+
+// Buffered returns the number of entries waiting to be sent
+func (l *DevSmithLogger) Buffered() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.buffer)
+}
+
+// trimLocked drops the oldest entries beyond maxBuffer. l.mutex must be held.
+func (l *DevSmithLogger) trimLocked() {
+	if over := len(l.buffer) - l.maxBuffer; over > 0 {
+		l.buffer = append(l.buffer[:0:0], l.buffer[over:]...)
+		l.dropped.Add(uint64(over))
+	}
+}
+
 // flushPeriodically runs in background and flushes logs periodically
 func (l *DevSmithLogger) flushPeriodically() {
 	for {
@@ -139,73 +188,82 @@ func (l *DevSmithLogger) Log(level, message string, context map[string]interface
 
 	l.mutex.Lock()
 	l.buffer = append(l.buffer, entry)
+	l.trimLocked()
 	shouldFlush := len(l.buffer) >= l.batchSize
 	l.mutex.Unlock()
 
 	if shouldFlush {
-		l.Flush()
+		go l.Flush() // Never block the caller on the network
 	}
 }
 
-// Flush sends buffered logs to DevSmith API
+// Flush sends buffered logs to DevSmith API, retrying with backoff. Logs
+// that still fail go back into the buffer, which stays within its cap.
 func (l *DevSmithLogger) Flush() {
+	l.flushMu.Lock()
+	defer l.flushMu.Unlock()
+
 	l.mutex.Lock()
 	if len(l.buffer) == 0 {
 		l.mutex.Unlock()
 		return
 	}
+	logs := l.buffer
+	l.buffer = make([]LogEntry, 0, l.batchSize)
+	l.mutex.Unlock()
+
+	err := l.send(logs)
+	delay := 500 * time.Millisecond
+	for attempt := 2; err != nil && attempt <= 3; attempt++ {
+		time.Sleep(delay)
+		delay *= 2
+		err = l.send(logs)
+	}
+	if err == nil {
+		return
+	}
 
-	logs := make([]LogEntry, len(l.buffer))
-	copy(logs, l.buffer)
-	l.buffer = l.buffer[:0]
+	// Re-add logs ahead of newer entries, dropping the oldest past the cap
+	l.mutex.Lock()
+	l.buffer = append(logs, l.buffer...)
+	l.trimLocked()
+	onFailure, pending := l.onFailure, len(l.buffer)
 	l.mutex.Unlock()
 
-	payload := BatchRequest{
-		ProjectSlug: l.projectSlug,
-		Logs:        logs,
+	if onFailure != nil {
+		onFailure(err, pending)
+	} else {
+		fmt.Printf("DevSmith Logger: %v (%d pending, %d dropped)\n", err, pending, l.Dropped())
 	}
+}
 
-	jsonData, err := json.Marshal(payload)
+// send posts one batch
+func (l *DevSmithLogger) send(logs []LogEntry) error {
+	jsonData, err := json.Marshal(BatchRequest{
+		ProjectSlug: l.projectSlug,
+		Logs:        logs,
+	})
 	if err != nil {
-		fmt.Printf("DevSmith Logger: Failed to marshal logs: %v\n", err)
-		// Re-add logs to buffer
-		l.mutex.Lock()
-		l.buffer = append(l.buffer, logs...)
-		l.mutex.Unlock()
-		return
+		return fmt.Errorf("failed to marshal logs: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", l.apiURL+"/api/logs/batch", bytes.NewBuffer(jsonData))
 	if err != nil {
-		fmt.Printf("DevSmith Logger: Failed to create request: %v\n", err)
-		// Re-add logs to buffer
-		l.mutex.Lock()
-		l.buffer = append(l.buffer, logs...)
-		l.mutex.Unlock()
-		return
+		return fmt.Errorf("failed to create request: %w", err)
 	}
-
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+l.apiKey)
 
 	resp, err := l.httpClient.Do(req)
 	if err != nil {
-		fmt.Printf("DevSmith Logger: Network error: %v\n", err)
-		// Re-add logs to buffer
-		l.mutex.Lock()
-		l.buffer = append(l.buffer, logs...)
-		l.mutex.Unlock()
-		return
+		return fmt.Errorf("network error: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		fmt.Printf("DevSmith Logger: Failed to send logs (%d)\n", resp.StatusCode)
-		// Re-add logs to buffer
-		l.mutex.Lock()
-		l.buffer = append(l.buffer, logs...)
-		l.mutex.Unlock()
+		return fmt.Errorf("failed to send logs (%d)", resp.StatusCode)
 	}
+	return nil
 }
 
 // Close flushes remaining logs and stops the logger
@@ -3,6 +3,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -29,19 +30,23 @@ type LogEntry struct {
 }
 
 type DevSmithLogger struct {
-	apiURL       string
-	apiKey       string
-	projectSlug  string
-	serviceName  string
-	buffer       []LogEntry
-	bufferSize   int
-	flushTimer   *time.Timer
-	mu           sync.Mutex
-	httpClient   *http.Client
-	stopChan     chan struct{}
-	flushPending bool
+	apiURL      string
+	apiKey      string
+	projectSlug string
+	serviceName string
+	buffer      []LogEntry
+	bufferSize  int
+	mu          sync.Mutex
+	httpClient  *http.Client
+	flushNow    chan struct{}
+	done        chan struct{}
+	stopped     chan struct{}
+	closeOnce   sync.Once
 }
 
+// closeTimeout bounds the final flush when Close's context has no deadline
+const closeTimeout = 5 * time.Second
+
 func NewLogger(apiURL, apiKey, projectSlug, serviceName string, bufferSize int, flushInterval time.Duration) *DevSmithLogger {
 	logger := &DevSmithLogger{
 		apiURL:      apiURL,
@@ -50,22 +55,38 @@ func NewLogger(apiURL, apiKey, projectSlug, serviceName string, bufferSize int,
 		serviceName: serviceName,
 		buffer:      make([]LogEntry, 0, bufferSize),
 		bufferSize:  bufferSize,
-		httpClient:  &http.Client{Timeout: 5 * time.Second},
-		stopChan:    make(chan struct{}),
+		httpClient:  &http.Client{},
+		flushNow:    make(chan struct{}, 1),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
 	}
 	
-	logger.scheduleFlush(flushInterval)
+	go logger.run(flushInterval)
 	return logger
 }
 
-func (l *DevSmithLogger) scheduleFlush(interval time.Duration) {
-	l.flushTimer = time.AfterFunc(interval, func() {
-		l.Flush()
-		l.scheduleFlush(interval)
-	})
+// run flushes on every tick and whenever a full buffer asks for it, until
+// Close closes done
+func (l *DevSmithLogger) run(interval time.Duration) {
+	defer close(l.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-ticker.C:
+		case <-l.flushNow:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		l.Flush(ctx)
+		cancel()
+	}
 }
 
-func (l *DevSmithLogger) Flush() {
+// Flush sends the buffered entries; ctx bounds the request
+func (l *DevSmithLogger) Flush(ctx context.Context) {
 	l.mu.Lock()
 	if len(l.buffer) == 0 {
 		l.mu.Unlock()
@@ -83,7 +104,11 @@ func (l *DevSmithLogger) Flush() {
 	}
 	
 	data, _ := json.Marshal(payload)
-	req, _ := http.NewRequest("POST", l.apiURL, bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, "POST", l.apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		log.Printf("DevSmith flush error: %v\n", err)
+		return
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+l.apiKey)
 	
@@ -111,10 +136,10 @@ func (l *DevSmithLogger) log(level, message string, context map[string]interface
 	l.mu.Unlock()
 	
 	if shouldFlush {
-		if l.flushTimer != nil {
-			l.flushTimer.Stop()
+		select {
+		case l.flushNow <- struct{}{}:
+		default: // A flush is already pending
 		}
-		l.Flush()
 	}
 }
 
@@ -134,12 +159,21 @@ func (l *DevSmithLogger) Error(message string, context map[string]interface{}, t
 	l.log("ERROR", message, context, tags)
 }
 
-func (l *DevSmithLogger) Close() {
-	if l.flushTimer != nil {
-		l.flushTimer.Stop()
-	}
-	l.Flush()
-	close(l.stopChan)
+// Close stops the background flusher and sends what is left. ctx bounds the
+// final flush; without a deadline it gets closeTimeout. Calling Close again
+// is a no-op.
+func (l *DevSmithLogger) Close(ctx context.Context) {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		<-l.stopped
+		
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, closeTimeout)
+			defer cancel()
+		}
+		l.Flush(ctx)
+	})
 }
 
 // Gin Middleware (simplified from docs/integrations/go/gin_middleware.go)
@@ -210,7 +244,7 @@ func main() {
 		100,
 		5*time.Second,
 	)
-	defer logger.Close()
+	defer logger.Close(context.Background())
 	
 	// Setup Gin
 	gin.SetMode(os.Getenv("GIN_MODE"))
@@ -366,6 +400,8 @@ func main() {
 	
 	<-sigChan
 	logger.Info("Server shutting down - flushing logs", map[string]interface{}{}, []string{"shutdown"})
-	logger.Close()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	logger.Close(shutdownCtx)
 	fmt.Println("Server stopped")
 }
@@ -10,6 +10,13 @@ class ApiError extends Error {
   }
 }
 
+// CSRF token issued by the backend in a readable cookie; state-changing
+// requests must echo it in the X-CSRF-Token header
+function getCsrfToken() {
+  const match = document.cookie.match(/(?:^|;\s*)devsmith_csrf=([^;]*)/);
+  return match ? decodeURIComponent(match[1]) : '';
+}
+
 // Generic API fetch with error handling
 export async function apiRequest(endpoint, options = {}) {
   const url = `${API_BASE_URL}${endpoint}`;
@@ -24,6 +31,15 @@ export async function apiRequest(endpoint, options = {}) {
     credentials: 'include', // Include cookies for session auth
   };
 
+  const method = (fetchOptions.method || 'GET').toUpperCase();
+  if (!['GET', 'HEAD', 'OPTIONS'].includes(method)) {
+    fetchOptions.headers = {
+      ...defaultOptions.headers,
+      'X-CSRF-Token': getCsrfToken(),
+      ...fetchOptions.headers,
+    };
+  }
+
   // Setup AbortController for timeout handling
   const controller = new AbortController();
   const signal = controller.signal;
@@ -0,0 +1,33 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrRateLimited matches (via errors.Is) provider errors for requests
+// rejected with HTTP 429
+var ErrRateLimited = errors.New("AI provider rate limit exceeded")
+
+// ProviderError is an error response from a hosted provider's API
+type ProviderError struct {
+	Provider   string        // e.g. "anthropic"
+	Type       string        // The provider's error type, e.g. "rate_limit_error"
+	Message    string        // The provider's error message, or the raw body
+	StatusCode int           // HTTP status
+	RetryAfter time.Duration // From the Retry-After header; 0 if absent
+}
+
+func (e *ProviderError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("HTTP %d from %s: %s", e.StatusCode, e.Provider, e.Message)
+	}
+	return fmt.Sprintf("HTTP %d from %s: %s (%s)", e.StatusCode, e.Provider, e.Message, e.Type)
+}
+
+// Is reports rate-limit responses as ErrRateLimited
+func (e *ProviderError) Is(target error) bool {
+	return target == ErrRateLimited && e.StatusCode == http.StatusTooManyRequests
+}
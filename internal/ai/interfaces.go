@@ -18,6 +18,15 @@ type Provider interface {
 	GetModelInfo() *ModelInfo
 }
 
+// StreamingProvider is implemented by providers that can return output
+// incrementally. onChunk is called with each piece of text as it arrives;
+// returning an error from it aborts the request. The returned Response holds
+// the complete content.
+type StreamingProvider interface {
+	Provider
+	GenerateStream(ctx context.Context, req *Request, onChunk func(chunk string) error) (*Response, error)
+}
+
 // Request represents a request to any AI provider
 type Request struct {
 	Metadata    map[string]interface{} // Provider-specific options
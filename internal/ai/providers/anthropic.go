@@ -5,9 +5,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,6 +32,16 @@ type anthropicRequest struct {
 	Temperature float64             `json:"temperature,omitempty"`
 }
 
+// anthropicErrorResponse is the error envelope of the Anthropic API, e.g.
+// {"type":"error","error":{"type":"rate_limit_error","message":"..."}}
+type anthropicErrorResponse struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
 // anthropicResponse represents the JSON response from Anthropic API
 type anthropicResponse struct {
 	ID         string `json:"id"`
@@ -131,12 +143,7 @@ func (c *AnthropicClient) Generate(ctx context.Context, req *ai.Request) (*ai.Re
 
 	// Check HTTP status
 	if httpResp.StatusCode != http.StatusOK {
-		readErr := error(nil)
-		bodyBytes, readErr := io.ReadAll(httpResp.Body)
-		if readErr != nil {
-			bodyBytes = []byte("(unable to read error body)")
-		}
-		return nil, fmt.Errorf("HTTP %d from Anthropic: %s", httpResp.StatusCode, string(bodyBytes))
+		return nil, anthropicError(httpResp)
 	}
 
 	bodyBytes, err := io.ReadAll(httpResp.Body)
@@ -183,7 +190,8 @@ func (c *AnthropicClient) HealthCheck(ctx context.Context) error {
 	_, err := c.Generate(ctx, req)
 	if err != nil {
 		// Check if it's an auth error
-		if strings.Contains(err.Error(), "401") || strings.Contains(err.Error(), "Unauthorized") {
+		var providerErr *ai.ProviderError
+		if errors.As(err, &providerErr) && providerErr.StatusCode == http.StatusUnauthorized {
 			return fmt.Errorf("Anthropic authentication failed: invalid API key")
 		}
 		return fmt.Errorf("Anthropic health check failed: %w", err)
@@ -217,6 +225,48 @@ func (c *AnthropicClient) GetModelInfo() *ai.ModelInfo {
 	}
 }
 
+// anthropicError converts a non-200 response into an *ai.ProviderError.
+// Rate-limited (429) and overloaded (529) responses carry a Retry-After
+// header telling callers when to try again.
+func anthropicError(httpResp *http.Response) error {
+	providerErr := &ai.ProviderError{
+		Provider:   "Anthropic",
+		StatusCode: httpResp.StatusCode,
+		RetryAfter: retryAfter(httpResp.Header),
+	}
+
+	bodyBytes, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		providerErr.Message = "(unable to read error body)"
+		return providerErr
+	}
+	var envelope anthropicErrorResponse
+	if json.Unmarshal(bodyBytes, &envelope) == nil && envelope.Error.Message != "" {
+		providerErr.Type = envelope.Error.Type
+		providerErr.Message = envelope.Error.Message
+	} else {
+		providerErr.Message = strings.TrimSpace(string(bodyBytes))
+	}
+	return providerErr
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(h http.Header) time.Duration {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
 // calculateCost calculates the estimated API cost based on tokens used
 func (c *AnthropicClient) calculateCost(inputTokens, outputTokens int) float64 {
 	pricing, exists := claudeModels[c.model]
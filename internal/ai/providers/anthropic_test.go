@@ -2,12 +2,15 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
 )
@@ -150,6 +153,74 @@ func TestAnthropicClient_Generate_ReturnsValidResponse(t *testing.T) {
 	assert.Equal(t, "end_turn", resp.FinishReason)
 }
 
+// TestAnthropicClient_Generate_MessagesRequestShape verifies the prompt is
+// sent as a Messages API user message with the auth and version headers
+func TestAnthropicClient_Generate_MessagesRequestShape(t *testing.T) {
+	var gotKey, gotVersion string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get("x-api-key")
+		gotVersion = r.Header.Get("anthropic-version")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.Write([]byte(`{"type":"message","content":[{"type":"text","text":"ok"}],"model":"claude-3-5-haiku-20241022","stop_reason":"end_turn","usage":{"input_tokens":1,"output_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient("sk-ant-test", "claude-3-5-haiku-20241022")
+	client.apiBaseURL = server.URL
+	_, err := client.Generate(context.Background(), &ai.Request{Prompt: "Review this"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "sk-ant-test", gotKey)
+	assert.Equal(t, "2023-06-01", gotVersion)
+	assert.Equal(t, "claude-3-5-haiku-20241022", gotBody["model"])
+	assert.Equal(t, float64(4096), gotBody["max_tokens"], "max_tokens is required by the Messages API")
+	assert.Equal(t, []interface{}{map[string]interface{}{"role": "user", "content": "Review this"}}, gotBody["messages"])
+}
+
+// TestAnthropicClient_Generate_RateLimited verifies a 429 is reported as
+// ai.ErrRateLimited with the error envelope and Retry-After
+func TestAnthropicClient_Generate_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "12")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"Number of request tokens has exceeded your per-minute rate limit"}}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient("sk-ant-test", "claude-3-5-haiku-20241022")
+	client.apiBaseURL = server.URL
+	resp, err := client.Generate(context.Background(), &ai.Request{Prompt: "Test"})
+
+	assert.Nil(t, resp)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ai.ErrRateLimited))
+	var providerErr *ai.ProviderError
+	require.True(t, errors.As(err, &providerErr))
+	assert.Equal(t, http.StatusTooManyRequests, providerErr.StatusCode)
+	assert.Equal(t, "rate_limit_error", providerErr.Type)
+	assert.Equal(t, "Number of request tokens has exceeded your per-minute rate limit", providerErr.Message)
+	assert.Equal(t, 12*time.Second, providerErr.RetryAfter)
+}
+
+// TestAnthropicClient_Generate_Overloaded verifies other error envelopes are
+// parsed but not reported as rate limits
+func TestAnthropicClient_Generate_Overloaded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(529)
+		w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient("sk-ant-test", "claude-3-5-haiku-20241022")
+	client.apiBaseURL = server.URL
+	_, err := client.Generate(context.Background(), &ai.Request{Prompt: "Test"})
+
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ai.ErrRateLimited))
+	assert.EqualError(t, err, "HTTP 529 from Anthropic: Overloaded (overloaded_error)")
+}
+
 // TestAnthropicClient_Generate_HandlesEmptyPrompt verifies edge case
 func TestAnthropicClient_Generate_HandlesEmptyPrompt(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
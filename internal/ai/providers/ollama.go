@@ -2,12 +2,14 @@
 package providers
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
@@ -38,6 +40,7 @@ type ollamaResponse struct {
 	EvalCount          int    `json:"eval_count"`
 	EvalDuration       int64  `json:"eval_duration"`
 	PromptEvalDuration int64  `json:"prompt_eval_duration"`
+	Error              string `json:"error,omitempty"`
 	Done               bool   `json:"done"`
 }
 
@@ -61,11 +64,87 @@ func NewOllamaClient(endpoint, model string) *OllamaClient {
 
 // Generate sends a prompt to Ollama and returns the response
 func (c *OllamaClient) Generate(ctx context.Context, req *ai.Request) (*ai.Response, error) {
+	startTime := time.Now()
+	httpResp, err := c.post(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = httpResp.Body.Close() //nolint:errcheck // error after response processed
+	}()
+
+	bodyBytes, readErr := io.ReadAll(httpResp.Body)
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", readErr)
+	}
+
+	// Parse JSON response
+	var resp ollamaResponse
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+
+	return toAIResponse(&resp, resp.Response, startTime), nil
+}
+
+// GenerateStream sends a prompt to Ollama with streaming enabled and calls
+// onChunk with each token batch as Ollama produces it.
+func (c *OllamaClient) GenerateStream(ctx context.Context, req *ai.Request, onChunk func(chunk string) error) (*ai.Response, error) {
+	startTime := time.Now()
+	httpResp, err := c.post(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = httpResp.Body.Close() //nolint:errcheck // error after response processed
+	}()
+
+	// Ollama streams one JSON object per line; the last has done=true
+	var content strings.Builder
+	var last ollamaResponse
+	scanner := bufio.NewScanner(httpResp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var part ollamaResponse
+		if err := json.Unmarshal(line, &part); err != nil {
+			return nil, fmt.Errorf("failed to parse Ollama stream: %w", err)
+		}
+		if part.Error != "" {
+			return nil, fmt.Errorf("Ollama stream error: %s", part.Error)
+		}
+		if part.Response != "" {
+			content.WriteString(part.Response)
+			if err := onChunk(part.Response); err != nil {
+				return nil, err
+			}
+		}
+		last = part
+		if part.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Ollama stream: %w", err)
+	}
+	if !last.Done {
+		return nil, fmt.Errorf("Ollama stream ended before completion")
+	}
+
+	return toAIResponse(&last, content.String(), startTime), nil
+}
+
+// post sends a generate request and returns the response once Ollama has
+// accepted it with HTTP 200.
+func (c *OllamaClient) post(ctx context.Context, req *ai.Request, stream bool) (*http.Response, error) {
 	// Prepare Ollama request
 	ollamaReq := ollamaRequest{
 		Model:       req.Model,
 		Prompt:      req.Prompt,
-		Stream:      false,
+		Stream:      stream,
 		Temperature: req.Temperature,
 	}
 
@@ -89,50 +168,41 @@ func (c *OllamaClient) Generate(ctx context.Context, req *ai.Request) (*ai.Respo
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	// Execute request
-	startTime := time.Now()
 	httpResp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request to Ollama: %w", err)
 	}
-	defer func() {
-		_ = httpResp.Body.Close() //nolint:errcheck // error after response processed
-	}()
 
 	// Check HTTP status
 	if httpResp.StatusCode != http.StatusOK {
+		defer func() {
+			_ = httpResp.Body.Close() //nolint:errcheck // error after response processed
+		}()
 		bodyBytes, readErr := io.ReadAll(httpResp.Body)
 		if readErr != nil {
 			bodyBytes = []byte("(unable to read error body)")
 		}
-		return nil, fmt.Errorf("HTTP %d from Ollama: %s", httpResp.StatusCode, string(bodyBytes))
-	}
-
-	bodyBytes, readErr := io.ReadAll(httpResp.Body)
-	if readErr != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", readErr)
-	}
-
-	// Parse JSON response
-	var resp ollamaResponse
-	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
-		return nil, fmt.Errorf("failed to parse Ollama response: %w", err)
+		return nil, &ai.ProviderError{Provider: "Ollama", StatusCode: httpResp.StatusCode, Message: string(bodyBytes)}
 	}
+	return httpResp, nil
+}
 
-	// Convert to Response
+// toAIResponse converts Ollama's final response object to an ai.Response.
+func toAIResponse(resp *ollamaResponse, content string, startTime time.Time) *ai.Response {
 	finishReason := "complete"
 	if resp.StopReason != "" {
 		finishReason = resp.StopReason
 	}
 
 	return &ai.Response{
-		Content:      resp.Response,
+		Content:      content,
 		InputTokens:  resp.PromptEvalCount,
 		OutputTokens: resp.EvalCount,
 		ResponseTime: time.Since(startTime),
 		CostUSD:      0.0, // Ollama is local, no cost
 		Model:        resp.Model,
 		FinishReason: finishReason,
-	}, nil
+	}
 }
 
 // HealthCheck verifies that Ollama is reachable and the model is available
@@ -0,0 +1,236 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/sony/gobreaker"
+
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+)
+
+// ErrNoHealthyEndpoints is returned when every endpoint in an OllamaPool is
+// failing its health check or has an open circuit breaker
+var ErrNoHealthyEndpoints = errors.New("no healthy Ollama endpoints")
+
+// Per-endpoint circuit breaker settings
+const (
+	poolBreakerThreshold = 3                // Consecutive failures that take an endpoint out of rotation
+	poolBreakerTimeout   = 30 * time.Second // How long it stays out before one probe request
+)
+
+// OllamaPool spreads requests across several Ollama instances serving the
+// same models. Each request goes to the available endpoint with the fewest
+// requests in flight, rotating between equally loaded ones. Endpoints that
+// fail their last health check or whose circuit breaker is open are skipped,
+// and a request that fails on one endpoint moves on to the next.
+type OllamaPool struct {
+	endpoints []*poolEndpoint
+	next      atomic.Uint64
+	model     string
+}
+
+// poolEndpoint is one Ollama instance and its routing state
+type poolEndpoint struct {
+	client   *OllamaClient
+	url      string
+	breaker  *gobreaker.CircuitBreaker
+	healthy  atomic.Bool
+	inFlight atomic.Int64
+}
+
+// OllamaEndpointStatus describes one endpoint of an OllamaPool
+type OllamaEndpointStatus struct {
+	URL      string `json:"url"`
+	Healthy  bool   `json:"healthy"`
+	Breaker  string `json:"breaker"`
+	InFlight int64  `json:"in_flight"`
+}
+
+// NewOllamaPool creates a pool over endpoints, all serving model by default.
+// Endpoints start out healthy; call HealthCheck or StartHealthChecks to
+// track their health.
+func NewOllamaPool(endpoints []string, model string) *OllamaPool {
+	pool := &OllamaPool{model: model}
+	for _, url := range endpoints {
+		ep := &poolEndpoint{client: NewOllamaClient(url, model), url: url}
+		ep.healthy.Store(true)
+		ep.breaker = gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:        url,
+			MaxRequests: 1,
+			Timeout:     poolBreakerTimeout,
+			ReadyToTrip: func(counts gobreaker.Counts) bool {
+				return counts.ConsecutiveFailures >= poolBreakerThreshold
+			},
+			IsSuccessful: endpointHealthy,
+			OnStateChange: func(name string, from, to gobreaker.State) {
+				log.Printf("Ollama endpoint %s circuit %s -> %s", name, from, to)
+			},
+		})
+		pool.endpoints = append(pool.endpoints, ep)
+	}
+	return pool
+}
+
+// endpointHealthy reports whether err leaves the endpoint in good standing.
+// Requests the endpoint rejected as invalid and callers giving up say
+// nothing about its health; rate limits, server errors and network failures
+// do.
+func endpointHealthy(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return true
+	}
+	var providerErr *ai.ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.StatusCode < http.StatusInternalServerError && providerErr.StatusCode != http.StatusTooManyRequests
+	}
+	return false
+}
+
+// Generate sends the request to the least busy available endpoint, failing
+// over to the others in turn
+func (p *OllamaPool) Generate(ctx context.Context, req *ai.Request) (*ai.Response, error) {
+	return p.do(ctx, func(client *OllamaClient) (*ai.Response, error) {
+		return client.Generate(ctx, req)
+	}, func() bool { return false })
+}
+
+// GenerateStream streams from the least busy available endpoint. It fails
+// over only while nothing has been streamed, so the caller never sees output
+// twice.
+func (p *OllamaPool) GenerateStream(ctx context.Context, req *ai.Request, onChunk func(chunk string) error) (*ai.Response, error) {
+	var streamed atomic.Bool
+	return p.do(ctx, func(client *OllamaClient) (*ai.Response, error) {
+		return client.GenerateStream(ctx, req, func(chunk string) error {
+			streamed.Store(true)
+			return onChunk(chunk)
+		})
+	}, streamed.Load)
+}
+
+// do runs call on endpoints in routing order until one succeeds, the error
+// is the request's fault rather than the endpoint's, or stop reports that
+// failing over is no longer safe
+func (p *OllamaPool) do(ctx context.Context, call func(*OllamaClient) (*ai.Response, error), stop func() bool) (*ai.Response, error) {
+	var lastErr error
+	tried := make(map[*poolEndpoint]bool, len(p.endpoints))
+	for len(tried) < len(p.endpoints) {
+		ep := p.pick(tried)
+		if ep == nil {
+			break
+		}
+		tried[ep] = true
+
+		ep.inFlight.Add(1)
+		result, err := ep.breaker.Execute(func() (interface{}, error) {
+			return call(ep.client)
+		})
+		ep.inFlight.Add(-1)
+		if err == nil {
+			return result.(*ai.Response), nil
+		}
+
+		lastErr = err
+		if endpointHealthy(err) || stop() || ctx.Err() != nil {
+			return nil, err
+		}
+		log.Printf("Ollama endpoint %s failed, trying next: %v", ep.url, err)
+	}
+	if lastErr == nil {
+		return nil, ErrNoHealthyEndpoints
+	}
+	return nil, fmt.Errorf("all Ollama endpoints failed: %w", lastErr)
+}
+
+// pick returns the available endpoint with the fewest requests in flight,
+// starting the scan at a rotating offset so ties are shared round-robin.
+// It returns nil when no untried endpoint is available.
+func (p *OllamaPool) pick(tried map[*poolEndpoint]bool) *poolEndpoint {
+	n := len(p.endpoints)
+	if n == 0 {
+		return nil
+	}
+	start := int(p.next.Add(1) % uint64(n))
+
+	var best *poolEndpoint
+	for i := 0; i < n; i++ {
+		ep := p.endpoints[(start+i)%n]
+		if tried[ep] || !ep.healthy.Load() || ep.breaker.State() == gobreaker.StateOpen {
+			continue
+		}
+		if best == nil || ep.inFlight.Load() < best.inFlight.Load() {
+			best = ep
+		}
+	}
+	return best
+}
+
+// HealthCheck checks every endpoint, updating which ones receive requests.
+// It succeeds when at least one endpoint is healthy.
+func (p *OllamaPool) HealthCheck(ctx context.Context) error {
+	var lastErr error
+	healthy := 0
+	for _, ep := range p.endpoints {
+		err := ep.client.HealthCheck(ctx)
+		if was := ep.healthy.Swap(err == nil); was != (err == nil) {
+			log.Printf("Ollama endpoint %s healthy=%t (error: %v)", ep.url, err == nil, err)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		healthy++
+	}
+	if healthy == 0 {
+		if lastErr == nil {
+			return ErrNoHealthyEndpoints
+		}
+		return fmt.Errorf("%w: %v", ErrNoHealthyEndpoints, lastErr)
+	}
+	return nil
+}
+
+// StartHealthChecks runs HealthCheck every interval until ctx is cancelled
+func (p *OllamaPool) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			_ = p.HealthCheck(checkCtx) //nolint:errcheck // state is recorded per endpoint
+			cancel()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Status reports the routing state of each endpoint
+func (p *OllamaPool) Status() []OllamaEndpointStatus {
+	status := make([]OllamaEndpointStatus, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		status = append(status, OllamaEndpointStatus{
+			URL:      ep.url,
+			Healthy:  ep.healthy.Load(),
+			Breaker:  ep.breaker.State().String(),
+			InFlight: ep.inFlight.Load(),
+		})
+	}
+	return status
+}
+
+// GetModelInfo returns metadata about the pool's default model
+func (p *OllamaPool) GetModelInfo() *ai.ModelInfo {
+	info := NewOllamaClient("", p.model).GetModelInfo()
+	info.DisplayName = fmt.Sprintf("Ollama - %s (%d endpoints)", p.model, len(p.endpoints))
+	return info
+}
@@ -0,0 +1,119 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+)
+
+// newPoolTestServer is an Ollama stand-in answering generate requests with
+// status, counting them in calls
+func newPoolTestServer(t *testing.T, status int, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/tags" {
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+			_, _ = w.Write([]byte(`{"models":[{"name":"mistral:7b-instruct"}]}`))
+			return
+		}
+		calls.Add(1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"error":"model failed to load"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":"ok","model":"mistral:7b-instruct","done":true}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// downEndpoint returns the URL of a server that is no longer listening
+func downEndpoint() string {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func TestOllamaPool_SkipsEndpointFailingHealthCheck(t *testing.T) {
+	var calls atomic.Int32
+	healthy := newPoolTestServer(t, http.StatusOK, &calls)
+	pool := NewOllamaPool([]string{downEndpoint(), healthy.URL}, "mistral:7b-instruct")
+
+	require.NoError(t, pool.HealthCheck(context.Background()), "one healthy endpoint is enough")
+	status := pool.Status()
+	assert.False(t, status[0].Healthy)
+	assert.True(t, status[1].Healthy)
+
+	for i := 0; i < 4; i++ {
+		resp, err := pool.Generate(context.Background(), &ai.Request{Prompt: "review this"})
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp.Content)
+	}
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestOllamaPool_FailsOverAndOpensBreaker(t *testing.T) {
+	var badCalls, goodCalls atomic.Int32
+	bad := newPoolTestServer(t, http.StatusInternalServerError, &badCalls)
+	good := newPoolTestServer(t, http.StatusOK, &goodCalls)
+	pool := NewOllamaPool([]string{bad.URL, good.URL}, "mistral:7b-instruct")
+
+	for i := 0; i < 10; i++ {
+		resp, err := pool.Generate(context.Background(), &ai.Request{Prompt: "review this"})
+		require.NoError(t, err, "requests that hit the failing endpoint fail over")
+		assert.Equal(t, "ok", resp.Content)
+	}
+	assert.Equal(t, int32(10), goodCalls.Load())
+	assert.Equal(t, int32(poolBreakerThreshold), badCalls.Load(), "the breaker takes the failing endpoint out of rotation")
+	assert.Equal(t, "open", pool.Status()[0].Breaker)
+}
+
+func TestOllamaPool_DoesNotFailOverClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	first := newPoolTestServer(t, http.StatusBadRequest, &calls)
+	second := newPoolTestServer(t, http.StatusBadRequest, &calls)
+	pool := NewOllamaPool([]string{first.URL, second.URL}, "mistral:7b-instruct")
+
+	_, err := pool.Generate(context.Background(), &ai.Request{Prompt: "review this"})
+	var providerErr *ai.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, http.StatusBadRequest, providerErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestOllamaPool_AllEndpointsDown(t *testing.T) {
+	pool := NewOllamaPool([]string{downEndpoint(), downEndpoint()}, "mistral:7b-instruct")
+
+	err := pool.HealthCheck(context.Background())
+	require.ErrorIs(t, err, ErrNoHealthyEndpoints)
+
+	_, err = pool.Generate(context.Background(), &ai.Request{Prompt: "review this"})
+	require.ErrorIs(t, err, ErrNoHealthyEndpoints)
+}
+
+func TestOllamaPool_BalancesAcrossHealthyEndpoints(t *testing.T) {
+	var first, second atomic.Int32
+	pool := NewOllamaPool([]string{
+		newPoolTestServer(t, http.StatusOK, &first).URL,
+		newPoolTestServer(t, http.StatusOK, &second).URL,
+	}, "mistral:7b-instruct")
+
+	for i := 0; i < 6; i++ {
+		_, err := pool.Generate(context.Background(), &ai.Request{Prompt: "review this"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), first.Load())
+	assert.Equal(t, int32(3), second.Load())
+}
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -306,3 +307,44 @@ func TestOllamaClient_Generate_LargeResponse(t *testing.T) {
 	assert.Equal(t, largeText, resp.Content)
 	assert.Greater(t, resp.OutputTokens, 1000)
 }
+
+// TestOllamaClient_GenerateStream_DeliversChunks verifies streamed tokens reach the callback in order
+func TestOllamaClient_GenerateStream_DeliversChunks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"stream":true`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"response":"{\"summary\":","done":false}` + "\n"))
+		w.Write([]byte(`{"response":"\"ok\"}","done":false}` + "\n"))
+		w.Write([]byte(`{"response":"","model":"deepseek-coder:6.7b","done":true,"prompt_eval_count":12,"eval_count":4}` + "\n"))
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "deepseek-coder:6.7b")
+	var chunks []string
+	resp, err := client.GenerateStream(context.Background(), &ai.Request{Prompt: "p", Model: "deepseek-coder:6.7b"}, func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`{"summary":`, `"ok"}`}, chunks)
+	assert.Equal(t, `{"summary":"ok"}`, resp.Content)
+	assert.Equal(t, 12, resp.InputTokens)
+	assert.Equal(t, 4, resp.OutputTokens)
+}
+
+// TestOllamaClient_GenerateStream_ErrorMidStream verifies an error line ends the stream with an error
+func TestOllamaClient_GenerateStream_ErrorMidStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response":"partial","done":false}` + "\n"))
+		w.Write([]byte(`{"error":"model runner crashed"}` + "\n"))
+	}))
+	defer server.Close()
+
+	client := NewOllamaClient(server.URL, "deepseek-coder:6.7b")
+	resp, err := client.GenerateStream(context.Background(), &ai.Request{Prompt: "p"}, func(string) error { return nil })
+
+	assert.ErrorContains(t, err, "model runner crashed")
+	assert.Nil(t, resp)
+}
@@ -0,0 +1,216 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+)
+
+// OpenAICompatibleClient implements the Provider interface for any endpoint
+// serving the OpenAI chat-completions API: DeepSeek, Mistral, Google's
+// OpenAI endpoint, or a local vLLM/LM Studio server. Responses are mapped to
+// the same ai.Response shape the Ollama client returns.
+type OpenAICompatibleClient struct {
+	httpClient      *http.Client
+	provider        string
+	baseURL         string
+	apiKey          string
+	model           string
+	inputCostPer1k  float64
+	outputCostPer1k float64
+}
+
+// chatCompletionRequest is the chat-completions request body
+type chatCompletionRequest struct {
+	Model       string              `json:"model"`
+	Messages    []map[string]string `json:"messages"`
+	MaxTokens   int                 `json:"max_tokens,omitempty"`
+	Temperature float64             `json:"temperature,omitempty"`
+	Stream      bool                `json:"stream"`
+}
+
+// chatCompletionResponse is the part of a chat-completions response we use
+type chatCompletionResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// NewOpenAICompatibleClient creates a client for the chat-completions API at
+// baseURL. provider names the service in model info and errors (e.g.
+// "deepseek", "vllm"). A baseURL without a path gets the standard /v1 prefix;
+// one with a path ("http://vllm:8000/v1") is used as-is. apiKey may be empty
+// for local servers that don't authenticate.
+func NewOpenAICompatibleClient(provider, baseURL, apiKey, model string) *OpenAICompatibleClient {
+	return &OpenAICompatibleClient{
+		provider: provider,
+		baseURL:  apiBaseURL(baseURL),
+		apiKey:   apiKey,
+		model:    model,
+		httpClient: &http.Client{
+			Timeout: 2 * time.Minute,
+		},
+	}
+}
+
+// SetPricing sets the cost per 1k input and output tokens used for CostUSD.
+// Without it calls are reported as free, as for local servers.
+func (c *OpenAICompatibleClient) SetPricing(inputCostPer1k, outputCostPer1k float64) {
+	c.inputCostPer1k = inputCostPer1k
+	c.outputCostPer1k = outputCostPer1k
+}
+
+// apiBaseURL returns the URL that chat-completions paths are appended to
+func apiBaseURL(baseURL string) string {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if u, err := url.Parse(baseURL); err == nil && u.Path == "" {
+		return baseURL + "/v1"
+	}
+	return baseURL
+}
+
+// Generate sends a prompt as a single user message and returns the first choice
+func (c *OpenAICompatibleClient) Generate(ctx context.Context, req *ai.Request) (*ai.Response, error) {
+	model := c.model
+	if req.Model != "" {
+		model = req.Model
+	}
+	body, err := json.Marshal(chatCompletionRequest{
+		Model:       model,
+		Messages:    []map[string]string{{"role": "user", "content": req.Prompt}},
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	c.authorize(httpReq)
+
+	startTime := time.Now()
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to %s: %w", c.provider, err)
+	}
+	defer func() {
+		_ = httpResp.Body.Close() //nolint:errcheck // error after response processed
+	}()
+
+	bodyBytes, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &ai.ProviderError{
+			Provider:   c.provider,
+			StatusCode: httpResp.StatusCode,
+			Message:    string(bodyBytes),
+			RetryAfter: retryAfter(httpResp.Header),
+		}
+	}
+
+	var resp chatCompletionResponse
+	if err := json.Unmarshal(bodyBytes, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", c.provider, err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("%s response has no choices", c.provider)
+	}
+	choice := resp.Choices[0]
+
+	if resp.Model != "" {
+		model = resp.Model
+	}
+	return &ai.Response{
+		Content:      choice.Message.Content,
+		InputTokens:  resp.Usage.PromptTokens,
+		OutputTokens: resp.Usage.CompletionTokens,
+		ResponseTime: time.Since(startTime),
+		CostUSD:      c.calculateCost(resp.Usage.PromptTokens, resp.Usage.CompletionTokens),
+		Model:        model,
+		FinishReason: toFinishReason(choice.FinishReason),
+	}, nil
+}
+
+// toFinishReason maps chat-completions finish reasons onto the values the
+// Ollama client reports: "stop" (or none) is "complete", others pass through.
+func toFinishReason(reason string) string {
+	if reason == "" || reason == "stop" {
+		return "complete"
+	}
+	return reason
+}
+
+// HealthCheck verifies the endpoint is reachable and accepts the API key by
+// listing its models, which costs no tokens
+func (c *OpenAICompatibleClient) HealthCheck(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/models", http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to create health check request: %w", err)
+	}
+	c.authorize(httpReq)
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%s health check failed: %w", c.provider, err)
+	}
+	defer func() {
+		_ = httpResp.Body.Close() //nolint:errcheck // error after response processed
+	}()
+
+	switch {
+	case httpResp.StatusCode == http.StatusUnauthorized || httpResp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%s authentication failed: invalid API key", c.provider)
+	case httpResp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s health check failed: HTTP %d", c.provider, httpResp.StatusCode)
+	}
+	return nil
+}
+
+// GetModelInfo returns metadata about the configured model
+func (c *OpenAICompatibleClient) GetModelInfo() *ai.ModelInfo {
+	return &ai.ModelInfo{
+		Provider:                 c.provider,
+		Model:                    c.model,
+		DisplayName:              fmt.Sprintf("%s - %s", c.provider, c.model),
+		MaxTokens:                32000,
+		CostPer1kInputTokens:     c.inputCostPer1k,
+		CostPer1kOutputTokens:    c.outputCostPer1k,
+		Capabilities:             []string{"code_analysis", "code_review", "explanation"},
+		SupportsStreaming:        false,
+		DefaultTemperature:       0.7,
+		RecommendedForCodeReview: true,
+	}
+}
+
+func (c *OpenAICompatibleClient) authorize(httpReq *http.Request) {
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+}
+
+// calculateCost calculates the estimated API cost based on tokens used
+func (c *OpenAICompatibleClient) calculateCost(inputTokens, outputTokens int) float64 {
+	return float64(inputTokens)/1000.0*c.inputCostPer1k + float64(outputTokens)/1000.0*c.outputCostPer1k
+}
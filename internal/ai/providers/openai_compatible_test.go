@@ -0,0 +1,164 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+)
+
+const chatCompletionFixture = `{
+	"id": "chatcmpl-1",
+	"object": "chat.completion",
+	"model": "qwen2.5-coder-served",
+	"choices": [{
+		"index": 0,
+		"message": {"role": "assistant", "content": "{\"summary\":\"ok\"}"},
+		"finish_reason": "stop"
+	}],
+	"usage": {"prompt_tokens": 120, "completion_tokens": 30, "total_tokens": 150}
+}`
+
+// TestOpenAICompatibleClient_Generate_RequestShape verifies the chat-completions
+// request body, path and auth header
+func TestOpenAICompatibleClient_Generate_RequestShape(t *testing.T) {
+	var gotPath, gotAuth, gotContentType string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotContentType = r.Header.Get("Content-Type")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(chatCompletionFixture))
+	}))
+	defer server.Close()
+
+	client := NewOpenAICompatibleClient("deepseek", server.URL, "sk-test", "deepseek-chat")
+	_, err := client.Generate(context.Background(), &ai.Request{Prompt: "review this", Temperature: 0.2, MaxTokens: 512})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/v1/chat/completions", gotPath)
+	assert.Equal(t, "Bearer sk-test", gotAuth)
+	assert.Equal(t, "application/json", gotContentType)
+	assert.Equal(t, "deepseek-chat", gotBody["model"])
+	assert.Equal(t, 0.2, gotBody["temperature"])
+	assert.Equal(t, float64(512), gotBody["max_tokens"])
+	assert.Equal(t, false, gotBody["stream"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"role": "user", "content": "review this"}}, gotBody["messages"])
+}
+
+// TestOpenAICompatibleClient_Generate_ParsesResponse verifies the response is
+// mapped to the shape the Ollama client returns
+func TestOpenAICompatibleClient_Generate_ParsesResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(chatCompletionFixture))
+	}))
+	defer server.Close()
+
+	client := NewOpenAICompatibleClient("vllm", server.URL+"/v1", "", "qwen2.5-coder")
+	client.SetPricing(0.001, 0.002)
+	resp, err := client.Generate(context.Background(), &ai.Request{Prompt: "review this"})
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"summary":"ok"}`, resp.Content)
+	assert.Equal(t, "qwen2.5-coder-served", resp.Model)
+	assert.Equal(t, "complete", resp.FinishReason)
+	assert.Equal(t, 120, resp.InputTokens)
+	assert.Equal(t, 30, resp.OutputTokens)
+	assert.InDelta(t, 0.12*0.001+0.03*0.002, resp.CostUSD, 1e-12)
+}
+
+// TestOpenAICompatibleClient_BaseURL verifies where chat completions are sent
+func TestOpenAICompatibleClient_BaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"https://api.deepseek.com", "https://api.deepseek.com/v1"},
+		{"https://api.mistral.ai/", "https://api.mistral.ai/v1"},
+		{"http://vllm:8000/v1", "http://vllm:8000/v1"},
+		{"https://generativelanguage.googleapis.com/v1beta/openai/", "https://generativelanguage.googleapis.com/v1beta/openai"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, NewOpenAICompatibleClient("p", tt.baseURL, "", "m").baseURL, tt.baseURL)
+	}
+}
+
+// TestOpenAICompatibleClient_Generate_NoAuthWithoutKey verifies local servers
+// are called without an Authorization header
+func TestOpenAICompatibleClient_Generate_NoAuthWithoutKey(t *testing.T) {
+	var hasAuth bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasAuth = r.Header["Authorization"]
+		_, _ = w.Write([]byte(chatCompletionFixture))
+	}))
+	defer server.Close()
+
+	_, err := NewOpenAICompatibleClient("vllm", server.URL, "", "m").Generate(context.Background(), &ai.Request{Prompt: "x"})
+	require.NoError(t, err)
+	assert.False(t, hasAuth)
+}
+
+// TestOpenAICompatibleClient_Generate_Errors verifies HTTP errors and empty
+// choices are reported
+func TestOpenAICompatibleClient_Generate_Errors(t *testing.T) {
+	status, body := http.StatusTooManyRequests, `{"error":{"message":"rate limited"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := NewOpenAICompatibleClient("mistral", server.URL, "key", "m")
+
+	_, err := client.Generate(context.Background(), &ai.Request{Prompt: "x"})
+	assert.ErrorContains(t, err, "HTTP 429 from mistral")
+
+	status, body = http.StatusOK, `{"model":"m","choices":[]}`
+	_, err = client.Generate(context.Background(), &ai.Request{Prompt: "x"})
+	assert.ErrorContains(t, err, "no choices")
+}
+
+// TestOpenAICompatibleClient_FinishReason verifies truncation is reported
+// like Ollama's
+func TestOpenAICompatibleClient_FinishReason(t *testing.T) {
+	assert.Equal(t, "complete", toFinishReason("stop"))
+	assert.Equal(t, "complete", toFinishReason(""))
+	assert.Equal(t, "length", toFinishReason("length"))
+}
+
+// TestOpenAICompatibleClient_HealthCheck verifies the models listing is used
+// and auth failures are reported as such
+func TestOpenAICompatibleClient_HealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/models" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	}))
+	defer server.Close()
+
+	assert.NoError(t, NewOpenAICompatibleClient("deepseek", server.URL, "good", "m").HealthCheck(context.Background()))
+	assert.ErrorContains(t, NewOpenAICompatibleClient("deepseek", server.URL, "bad", "m").HealthCheck(context.Background()), "authentication failed")
+}
+
+// TestOpenAICompatibleClient_GetModelInfo verifies provider metadata
+func TestOpenAICompatibleClient_GetModelInfo(t *testing.T) {
+	client := NewOpenAICompatibleClient("vllm", "http://localhost:8000", "", "qwen2.5-coder")
+	info := client.GetModelInfo()
+
+	assert.Equal(t, "vllm", info.Provider)
+	assert.Equal(t, "qwen2.5-coder", info.Model)
+	assert.Zero(t, info.CostPer1kInputTokens, "unpriced endpoints are free")
+}
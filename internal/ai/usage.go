@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrBudgetExceeded is returned instead of calling a model once the user's
+// monthly AI budget is spent
+var ErrBudgetExceeded = errors.New("monthly AI budget exceeded")
+
+// UsageRecord describes one AI call for usage and cost tracking
+type UsageRecord struct {
+	UserID       int
+	AppName      string
+	Provider     string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	Latency      time.Duration
+	CostUSD      float64 // As estimated by the provider (Response.CostUSD)
+	Success      bool
+	Error        string
+}
+
+// UsageRecorder persists AI usage records
+type UsageRecorder interface {
+	Record(ctx context.Context, record *UsageRecord) error
+}
+
+// PostgresUsageRecorder writes usage records to portal.llm_usage_logs
+type PostgresUsageRecorder struct {
+	db *sql.DB
+}
+
+// NewPostgresUsageRecorder creates a usage recorder backed by PostgreSQL
+func NewPostgresUsageRecorder(db *sql.DB) *PostgresUsageRecorder {
+	return &PostgresUsageRecorder{db: db}
+}
+
+// Record inserts one usage row
+func (r *PostgresUsageRecorder) Record(ctx context.Context, record *UsageRecord) error {
+	var errorMessage sql.NullString
+	if record.Error != "" {
+		errorMessage = sql.NullString{String: record.Error, Valid: true}
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO portal.llm_usage_logs
+			(user_id, app_name, provider, model_name, tokens_used, latency_ms, cost_usd, success, error_message)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		record.UserID, record.AppName, record.Provider, record.Model,
+		record.InputTokens+record.OutputTokens, record.Latency.Milliseconds(),
+		record.CostUSD, record.Success, errorMessage,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record LLM usage: %w", err)
+	}
+	return nil
+}
@@ -59,6 +59,34 @@ func (r *AggregationRepository) FindByRange(ctx context.Context, metricType anal
 	return aggregations, nil
 }
 
+// StreamByRange calls fn for each aggregation within a time range, in time
+// order, as rows are read. Unlike FindByRange it does not hold the whole
+// range in memory. Iteration stops at the first error from fn.
+func (r *AggregationRepository) StreamByRange(ctx context.Context, metricType analytics_models.MetricType, service string, start, end time.Time, fn func(*analytics_models.Aggregation) error) error {
+	query := `
+		SELECT id, metric_type, service, value, time_bucket, created_at
+		FROM analytics.aggregations
+		WHERE metric_type = $1 AND service = $2 AND time_bucket BETWEEN $3 AND $4
+		ORDER BY time_bucket ASC
+	`
+	rows, err := r.db.Query(ctx, query, metricType, service, start, end)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		agg := &analytics_models.Aggregation{}
+		if err := rows.Scan(&agg.ID, &agg.MetricType, &agg.Service, &agg.Value, &agg.TimeBucket, &agg.CreatedAt); err != nil {
+			return err
+		}
+		if err := fn(agg); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 // FindAllServices returns a list of all services that have aggregations.
 // The services are returned in alphabetical order.
 func (r *AggregationRepository) FindAllServices(ctx context.Context) ([]string, error) {
@@ -85,5 +113,6 @@ func (r *AggregationRepository) FindAllServices(ctx context.Context) ([]string,
 type AggregationRepositoryInterface interface {
 	Upsert(ctx context.Context, agg *analytics_models.Aggregation) error
 	FindByRange(ctx context.Context, metricType analytics_models.MetricType, service string, start, end time.Time) ([]*analytics_models.Aggregation, error)
+	StreamByRange(ctx context.Context, metricType analytics_models.MetricType, service string, start, end time.Time, fn func(*analytics_models.Aggregation) error) error
 	FindAllServices(ctx context.Context) ([]string, error)
 }
@@ -2,13 +2,16 @@ package analytics_db
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
 )
 
-// LogReader provides READ-ONLY access to logs.entries
+// LogReader provides READ-ONLY access to logs.entries. Soft-deleted entries
+// (deleted_at set) are left out of every read.
 type LogReader struct {
 	db *pgxpool.Pool
 }
@@ -23,7 +26,7 @@ func (r *LogReader) CountByServiceAndLevel(ctx context.Context, service, level s
 	query := `
 		SELECT COUNT(*)
 		FROM logs.entries
-		WHERE service = $1 AND level = $2 AND created_at BETWEEN $3 AND $4
+		WHERE service = $1 AND level = $2 AND created_at BETWEEN $3 AND $4 AND deleted_at IS NULL
 	`
 	var count int
 	err := r.db.QueryRow(ctx, query, service, level, start, end).Scan(&count)
@@ -35,7 +38,7 @@ func (r *LogReader) FindTopMessages(ctx context.Context, service, level string,
 	query := `
 		SELECT message, COUNT(*) AS count, MAX(created_at) AS last_seen
 		FROM logs.entries
-		WHERE service = $1 AND level = $2 AND created_at BETWEEN $3 AND $4
+		WHERE service = $1 AND level = $2 AND created_at BETWEEN $3 AND $4 AND deleted_at IS NULL
 		GROUP BY message
 		ORDER BY count DESC
 		LIMIT $5
@@ -57,9 +60,103 @@ func (r *LogReader) FindTopMessages(ctx context.Context, service, level string,
 	return issues, nil
 }
 
+// issueFingerprint groups messages that differ only in numbers (IDs,
+// durations, ports) into one issue.
+const issueFingerprint = `regexp_replace(message, '[0-9]+', 'N', 'g')`
+
+// FindTopIssues groups errors and warnings by service, level, issue type and
+// message fingerprint, filtered and paged by q. It also returns the number of
+// groups matching the filters so callers can paginate.
+func (r *LogReader) FindTopIssues(ctx context.Context, q analytics_models.TopIssuesQuery) ([]analytics_models.IssueItem, int, error) {
+	query, args := buildTopIssuesQuery(q)
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var issues []analytics_models.IssueItem
+	total := 0
+	for rows.Next() {
+		issue := analytics_models.IssueItem{}
+		if err := rows.Scan(&issue.Service, &issue.Level, &issue.IssueType, &issue.Message, &issue.Count, &issue.LastSeen, &total); err != nil {
+			return nil, 0, err
+		}
+		issues = append(issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// A page past the end has no rows to carry the total
+	if len(issues) == 0 && q.Offset > 0 {
+		countQuery, countArgs := buildTopIssuesCountQuery(q)
+		if err := r.db.QueryRow(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+	return issues, total, nil
+}
+
+// topIssuesGroups builds the filtered, grouped part of the top issues query.
+func topIssuesGroups(q analytics_models.TopIssuesQuery) (string, []interface{}) {
+	args := []interface{}{q.Start, q.End}
+	where := []string{"created_at BETWEEN $1 AND $2", "deleted_at IS NULL"}
+	if q.Service != "" {
+		args = append(args, q.Service)
+		where = append(where, fmt.Sprintf("service = $%d", len(args)))
+	}
+	if q.Level != "" {
+		args = append(args, strings.ToLower(q.Level))
+		where = append(where, fmt.Sprintf("LOWER(level) = $%d", len(args)))
+	} else {
+		where = append(where, "LOWER(level) IN ('error', 'warn', 'warning')")
+	}
+	minCount := q.MinCount
+	if minCount < 1 {
+		minCount = 1
+	}
+	args = append(args, minCount)
+
+	groups := fmt.Sprintf(`
+		SELECT service, LOWER(level) AS level, COALESCE(issue_type, '') AS issue_type,
+			MIN(message) AS message, COUNT(*) AS count, MAX(created_at) AS last_seen
+		FROM logs.entries
+		WHERE %s
+		GROUP BY service, LOWER(level), COALESCE(issue_type, ''), %s
+		HAVING COUNT(*) >= $%d`,
+		strings.Join(where, " AND "), issueFingerprint, len(args))
+	return groups, args
+}
+
+// buildTopIssuesQuery returns one page of issue groups, each row carrying
+// the total number of groups.
+func buildTopIssuesQuery(q analytics_models.TopIssuesQuery) (string, []interface{}) {
+	groups, args := topIssuesGroups(q)
+	order := "count DESC, last_seen DESC"
+	if q.OrderBy == analytics_models.IssueOrderRecent {
+		order = "last_seen DESC, count DESC"
+	}
+	args = append(args, q.Limit, q.Offset)
+	query := fmt.Sprintf(`
+		SELECT service, level, issue_type, message, count, last_seen, COUNT(*) OVER () AS total
+		FROM (%s
+		) AS groups
+		ORDER BY %s, service, message
+		LIMIT $%d OFFSET $%d`,
+		groups, order, len(args)-1, len(args))
+	return query, args
+}
+
+// buildTopIssuesCountQuery counts the issue groups matching q.
+func buildTopIssuesCountQuery(q analytics_models.TopIssuesQuery) (string, []interface{}) {
+	groups, args := topIssuesGroups(q)
+	return fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS groups", groups), args
+}
+
 // FindAllServices returns list of all services that have logged
 func (r *LogReader) FindAllServices(ctx context.Context) ([]string, error) {
-	query := `SELECT DISTINCT service FROM logs.entries ORDER BY service`
+	query := `SELECT DISTINCT service FROM logs.entries WHERE deleted_at IS NULL ORDER BY service`
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,25 @@
+-- Log rollups: counts of raw logs per hourly/daily bucket, maintained by the rollup job
+CREATE TABLE IF NOT EXISTS analytics.log_rollups (
+    bucket_size VARCHAR(10) NOT NULL,
+    bucket_start TIMESTAMPTZ NOT NULL,
+    service TEXT NOT NULL,
+    level TEXT NOT NULL,
+    issue_type TEXT NOT NULL DEFAULT '',
+    count BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT pk_log_rollups PRIMARY KEY (bucket_size, service, bucket_start, level, issue_type),
+    CONSTRAINT chk_log_rollups_bucket_size CHECK (bucket_size IN ('hour', 'day'))
+);
+
+-- Range reads for one bucket size across all services
+CREATE INDEX IF NOT EXISTS idx_log_rollups_bucket ON analytics.log_rollups(bucket_size, bucket_start DESC);
+
+-- How far each bucket size has been rolled up (exclusive)
+CREATE TABLE IF NOT EXISTS analytics.rollup_watermarks (
+    bucket_size VARCHAR(10) PRIMARY KEY,
+    rolled_until TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE analytics.log_rollups IS 'Log counts by service/level/issue_type per hour and day, rolled up from logs.entries';
+COMMENT ON TABLE analytics.rollup_watermarks IS 'End of the last complete bucket rolled up for each bucket size';
@@ -9,8 +9,9 @@ import (
 )
 
 // LogReaderInterface defines methods for reading log data from the database.
-// It includes methods for retrieving top messages and all services.
+// It includes methods for retrieving top messages, grouped issues and all services.
 type LogReaderInterface interface {
 	FindTopMessages(ctx context.Context, service, level string, start, end time.Time, limit int) ([]analytics_models.IssueItem, error)
+	FindTopIssues(ctx context.Context, q analytics_models.TopIssuesQuery) ([]analytics_models.IssueItem, int, error)
 	FindAllServices(ctx context.Context) ([]string, error)
 }
@@ -0,0 +1,130 @@
+package analytics_db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
+)
+
+// rollupBucket truncates created_at to a UTC bucket of size $1 ('hour' or 'day').
+const rollupBucket = `date_trunc($1, created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'`
+
+// RollupRepository maintains analytics.log_rollups from logs.entries and
+// reads log counts back from either.
+type RollupRepository struct {
+	db *pgxpool.Pool
+}
+
+// NewRollupRepository creates a new instance of RollupRepository.
+func NewRollupRepository(db *pgxpool.Pool) *RollupRepository {
+	return &RollupRepository{db: db}
+}
+
+// RollupRepositoryInterface defines the methods for log rollup operations.
+type RollupRepositoryInterface interface {
+	RollUp(ctx context.Context, bucketSize string, start, end time.Time) (int64, error)
+	Watermark(ctx context.Context, bucketSize string) (time.Time, error)
+	FindRollups(ctx context.Context, service, bucketSize string, start, end time.Time) ([]analytics_models.LogRollup, error)
+	CountRaw(ctx context.Context, service, bucketSize string, start, end time.Time) ([]analytics_models.LogRollup, error)
+}
+
+// RollUp recomputes the rollups of size bucketSize for the buckets in
+// [start, end) and moves the watermark to end, in one transaction. Both
+// bounds must fall on bucket boundaries. It returns the number of rollup
+// rows written.
+func (r *RollupRepository) RollUp(ctx context.Context, bucketSize string, start, end time.Time) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Recomputing replaces counts, so logs that arrived late are included
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM analytics.log_rollups
+		WHERE bucket_size = $1 AND bucket_start >= $2 AND bucket_start < $3
+	`, bucketSize, start, end); err != nil {
+		return 0, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO analytics.log_rollups (bucket_size, bucket_start, service, level, issue_type, count)
+		SELECT $1, `+rollupBucket+`, service, LOWER(level), COALESCE(issue_type, ''), COUNT(*)
+		FROM logs.entries
+		WHERE created_at >= $2 AND created_at < $3
+		GROUP BY 2, service, LOWER(level), COALESCE(issue_type, '')
+	`, bucketSize, start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO analytics.rollup_watermarks (bucket_size, rolled_until)
+		VALUES ($1, $2)
+		ON CONFLICT (bucket_size)
+		DO UPDATE SET rolled_until = GREATEST(analytics.rollup_watermarks.rolled_until, EXCLUDED.rolled_until), updated_at = NOW()
+	`, bucketSize, end); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Watermark returns the end of the rolled-up range for bucketSize, or the
+// zero time if nothing has been rolled up yet.
+func (r *RollupRepository) Watermark(ctx context.Context, bucketSize string) (time.Time, error) {
+	var until time.Time
+	err := r.db.QueryRow(ctx, `SELECT rolled_until FROM analytics.rollup_watermarks WHERE bucket_size = $1`, bucketSize).Scan(&until)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return until.UTC(), err
+}
+
+// FindRollups reads stored rollups for a service in [start, end).
+func (r *RollupRepository) FindRollups(ctx context.Context, service, bucketSize string, start, end time.Time) ([]analytics_models.LogRollup, error) {
+	return r.queryRollups(ctx, `
+		SELECT bucket_size, bucket_start, service, level, issue_type, count
+		FROM analytics.log_rollups
+		WHERE bucket_size = $1 AND bucket_start >= $2 AND bucket_start < $3 AND service = $4
+		ORDER BY bucket_start, level, issue_type
+	`, bucketSize, start, end, service)
+}
+
+// CountRaw counts logs.entries for a service in [start, end) into buckets,
+// for ranges not rolled up yet.
+func (r *RollupRepository) CountRaw(ctx context.Context, service, bucketSize string, start, end time.Time) ([]analytics_models.LogRollup, error) {
+	return r.queryRollups(ctx, `
+		SELECT $1::text, `+rollupBucket+` AS bucket_start, service, LOWER(level), COALESCE(issue_type, ''), COUNT(*)
+		FROM logs.entries
+		WHERE created_at >= $2 AND created_at < $3 AND service = $4
+		GROUP BY 2, service, LOWER(level), COALESCE(issue_type, '')
+		ORDER BY bucket_start, 4, 5
+	`, bucketSize, start, end, service)
+}
+
+func (r *RollupRepository) queryRollups(ctx context.Context, query string, args ...interface{}) ([]analytics_models.LogRollup, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollups []analytics_models.LogRollup
+	for rows.Next() {
+		var rollup analytics_models.LogRollup
+		if err := rows.Scan(&rollup.BucketSize, &rollup.BucketStart, &rollup.Service, &rollup.Level, &rollup.IssueType, &rollup.Count); err != nil {
+			return nil, err
+		}
+		rollup.BucketStart = rollup.BucketStart.UTC()
+		rollups = append(rollups, rollup)
+	}
+	return rollups, rows.Err()
+}
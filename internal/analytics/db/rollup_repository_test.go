@@ -0,0 +1,88 @@
+package analytics_db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _ RollupRepositoryInterface = (*RollupRepository)(nil)
+
+func TestRollupRepository_RollUp_Seeded(t *testing.T) {
+	pool, prefix := setupLogReaderDB(t)
+	ctx := context.Background()
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), "DELETE FROM analytics.log_rollups WHERE service LIKE $1", prefix+"%")
+	})
+	// A fixed day long past, so real rollups in the test database are untouched
+	day := time.Date(2001, 3, 4, 0, 0, 0, 0, time.UTC)
+	api := prefix + "-api"
+
+	seed := []struct {
+		level, issueType string
+		count            int
+		at               time.Time
+	}{
+		{"error", "db_connection", 3, day.Add(10 * time.Minute)},
+		{"ERROR", "", 2, day.Add(59 * time.Minute)},
+		{"error", "db_connection", 4, day.Add(time.Hour)},
+		{"warn", "", 1, day.Add(23 * time.Hour)},
+	}
+	for _, s := range seed {
+		for i := 0; i < s.count; i++ {
+			var issueType interface{}
+			if s.issueType != "" {
+				issueType = s.issueType
+			}
+			_, err := pool.Exec(ctx, "INSERT INTO logs.entries (service, level, message, issue_type, created_at) VALUES ($1, $2, 'seeded', $3, $4)",
+				api, s.level, issueType, s.at)
+			require.NoError(t, err)
+		}
+	}
+
+	repo := NewRollupRepository(pool)
+
+	t.Run("hourly buckets", func(t *testing.T) {
+		_, err := repo.RollUp(ctx, "hour", day, day.Add(24*time.Hour))
+		require.NoError(t, err)
+
+		rollups, err := repo.FindRollups(ctx, api, "hour", day, day.Add(24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []analytics_models.LogRollup{
+			{BucketStart: day, BucketSize: "hour", Service: api, Level: "error", Count: 2},
+			{BucketStart: day, BucketSize: "hour", Service: api, Level: "error", IssueType: "db_connection", Count: 3},
+			{BucketStart: day.Add(time.Hour), BucketSize: "hour", Service: api, Level: "error", IssueType: "db_connection", Count: 4},
+			{BucketStart: day.Add(23 * time.Hour), BucketSize: "hour", Service: api, Level: "warn", Count: 1},
+		}, rollups)
+
+		raw, err := repo.CountRaw(ctx, api, "hour", day, day.Add(24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, rollups, raw, "rollups match counting raw logs")
+	})
+
+	t.Run("daily buckets and re-rolling", func(t *testing.T) {
+		_, err := repo.RollUp(ctx, "day", day, day.Add(24*time.Hour))
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, "INSERT INTO logs.entries (service, level, message, created_at) VALUES ($1, 'warn', 'late', $2)",
+			api, day.Add(12*time.Hour))
+		require.NoError(t, err)
+		_, err = repo.RollUp(ctx, "day", day, day.Add(24*time.Hour))
+		require.NoError(t, err)
+
+		rollups, err := repo.FindRollups(ctx, api, "day", day, day.Add(24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []analytics_models.LogRollup{
+			{BucketStart: day, BucketSize: "day", Service: api, Level: "error", Count: 2},
+			{BucketStart: day, BucketSize: "day", Service: api, Level: "error", IssueType: "db_connection", Count: 7},
+			{BucketStart: day, BucketSize: "day", Service: api, Level: "warn", Count: 2},
+		}, rollups, "rolling up again replaces counts")
+
+		watermark, err := repo.Watermark(ctx, "day")
+		require.NoError(t, err)
+		assert.False(t, watermark.Before(day.Add(24*time.Hour)))
+	})
+}
@@ -2,9 +2,15 @@
 package internal_analytics_handlers
 
 import (
+	"errors"
+	"fmt"
+	"mime"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
 	analytics_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/services"
 	"github.com/sirupsen/logrus"
 )
@@ -38,7 +44,11 @@ func (h *AnalyticsHandler) RegisterRoutes(router *gin.Engine) {
 	router.Group("/api/analytics").GET("/aggregate", h.RunAggregation)
 	router.Group("/api/analytics").POST("/aggregate", h.RunAggregation)
 
+	router.Group("/api/analytics").GET("/aggregations", h.GetAggregations)
+	router.Group("/api/analytics").GET("/log-counts", h.GetLogCounts)
+
 	router.Group("/api/analytics").GET("/trends", h.GetTrends)
+	router.Group("/api/analytics").GET("/trends/:service/forecast", h.GetTrendForecast)
 	router.Group("/api/analytics").GET("/anomalies", h.GetAnomalies)
 	router.Group("/api/analytics").GET("/top-issues", h.GetTopIssues)
 
@@ -47,6 +57,12 @@ func (h *AnalyticsHandler) RegisterRoutes(router *gin.Engine) {
 	router.Group("/api/analytics").POST("/export", h.ExportData)
 }
 
+// RegisterDebugRoutes registers the cache diagnostics under debug, which
+// the caller mounts at /api/analytics/debug behind authentication.
+func (h *AnalyticsHandler) RegisterDebugRoutes(debug gin.IRoutes) {
+	debug.GET("/cache", h.GetCacheStats)
+}
+
 // RunAggregation triggers the hourly aggregation process.
 // It responds with a success message or an error if the aggregation fails.
 func (h *AnalyticsHandler) RunAggregation(c *gin.Context) {
@@ -58,26 +74,289 @@ func (h *AnalyticsHandler) RunAggregation(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Aggregation completed successfully"})
 }
 
+// GetAggregations returns a metric series for a service.
+//
+// Query parameters: service (required), metric_type (default
+// error_frequency), bucket ("hour" or "day"; default hour) and start/end
+// (RFC 3339; default the last 24 hours).
+func (h *AnalyticsHandler) GetAggregations(c *gin.Context) {
+	end := time.Now().UTC()
+	q := analytics_services.AggregationQuery{
+		Start:      end.Add(-24 * time.Hour),
+		End:        end,
+		MetricType: analytics_models.MetricType(c.DefaultQuery("metric_type", string(analytics_models.ErrorFrequency))),
+		Service:    c.Query("service"),
+		Bucket:     c.Query("bucket"),
+	}
+	if err := queryTimeRange(c, &q.Start, &q.End); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	points, err := h.aggregatorService.GetAggregationSeries(c.Request.Context(), q)
+	if errors.Is(err, analytics_services.ErrInvalidAggregationQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to fetch aggregations")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch aggregations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"service":     q.Service,
+		"metric_type": q.MetricType,
+		"points":      points,
+	})
+}
+
+// GetLogCounts returns a service's log counts per bucket, level and issue type.
+//
+// Query parameters: service (required), bucket ("hour" or "day"; default
+// hour) and start/end (RFC 3339; default the last 24 hours).
+func (h *AnalyticsHandler) GetLogCounts(c *gin.Context) {
+	end := time.Now().UTC()
+	start := end.Add(-24 * time.Hour)
+	if err := queryTimeRange(c, &start, &end); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	counts, err := h.aggregatorService.GetLogCounts(c.Request.Context(), c.Query("service"), c.Query("bucket"), start, end)
+	switch {
+	case errors.Is(err, analytics_services.ErrInvalidAggregationQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, analytics_services.ErrRollupsNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case err != nil:
+		h.logger.WithError(err).Error("Failed to fetch log counts")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch log counts"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"service": c.Query("service"), "counts": counts})
+	}
+}
+
+// GetCacheStats reports aggregation cache hits and misses for debugging.
+func (h *AnalyticsHandler) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.aggregatorService.CacheStats())
+}
+
 // GetTrends retrieves trend data for the analytics service.
 // It responds with the trend data or an error if the operation fails.
 func (h *AnalyticsHandler) GetTrends(c *gin.Context) {
 	// Implementation for fetching trends
 }
 
+// GetTrendForecast projects a service's daily metric totals forward.
+//
+// Query parameters: metric_type (default error_frequency), horizon (days to
+// forecast, default 7) and history (days fitted, default 28). Responds 422
+// when the service has too little history to forecast.
+func (h *AnalyticsHandler) GetTrendForecast(c *gin.Context) {
+	metricType := analytics_models.MetricType(c.DefaultQuery("metric_type", string(analytics_models.ErrorFrequency)))
+	horizon, err := strconv.Atoi(c.DefaultQuery("horizon", strconv.Itoa(analytics_services.DefaultForecastHorizon)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "horizon must be an integer"})
+		return
+	}
+	history, err := strconv.Atoi(c.DefaultQuery("history", strconv.Itoa(analytics_services.DefaultForecastHistory)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "history must be an integer"})
+		return
+	}
+
+	forecast, err := h.trendService.ForecastTrend(c.Request.Context(), metricType, c.Param("service"), time.Now().UTC(), horizon, history)
+	switch {
+	case errors.Is(err, analytics_services.ErrInvalidForecast):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, analytics_services.ErrInsufficientHistory):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "insufficient history", "message": err.Error()})
+	case err != nil:
+		h.logger.WithError(err).Error("Failed to forecast trend")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to forecast trend"})
+	default:
+		c.JSON(http.StatusOK, forecast)
+	}
+}
+
 // GetAnomalies retrieves anomaly data for the analytics service.
 // It responds with the anomaly data or an error if the operation fails.
+//
+// Query parameters: service (required), metric_type (default
+// error_frequency), start/end (RFC 3339; default the last 24 hours), and the
+// detector options method ("zscore" or "mad"), sensitivity, window, seasonal
+// and seasonal_weeks.
 func (h *AnalyticsHandler) GetAnomalies(c *gin.Context) {
-	// Implementation for fetching anomalies
+	service := c.Query("service")
+	if service == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "service is required"})
+		return
+	}
+	metricType := analytics_models.MetricType(c.DefaultQuery("metric_type", string(analytics_models.ErrorFrequency)))
+
+	end := time.Now().UTC()
+	start := end.Add(-24 * time.Hour)
+	if err := queryTimeRange(c, &start, &end); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := analytics_models.AnomalyOptions{Method: analytics_models.AnomalyMethod(c.Query("method"))}
+	var err error
+	if v := c.Query("sensitivity"); v != "" {
+		if opts.Sensitivity, err = strconv.ParseFloat(v, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sensitivity must be a number"})
+			return
+		}
+	}
+	for name, dst := range map[string]*int{"window": &opts.Window, "seasonal_weeks": &opts.SeasonalWeeks} {
+		if v := c.Query(name); v != "" {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be an integer"})
+				return
+			}
+		}
+	}
+	if v := c.Query("seasonal"); v != "" {
+		if opts.Seasonal, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "seasonal must be true or false"})
+			return
+		}
+	}
+	if opts, err = analytics_services.NormalizeAnomalyOptions(opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	anomalies, err := h.anomalyService.DetectAnomaliesWithOptions(c.Request.Context(), metricType, service, start, end, opts)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to detect anomalies")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to detect anomalies"})
+		return
+	}
+
+	resp := analytics_models.AnomalyResponse{
+		TimeRange: analytics_models.TimeRange{Start: start, End: end},
+		Anomalies: make([]analytics_models.Anomaly, 0, len(anomalies)),
+		Options:   opts,
+	}
+	for _, a := range anomalies {
+		resp.Anomalies = append(resp.Anomalies, *a)
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // GetTopIssues retrieves the top issues for the analytics service.
 // It responds with the top issues data or an error if the operation fails.
+//
+// Query parameters (all optional): service, level, start and end (RFC 3339),
+// min_count, order ("count" or "recent"), limit and offset.
 func (h *AnalyticsHandler) GetTopIssues(c *gin.Context) {
-	// Implementation for fetching top issues
+	q, err := parseTopIssuesQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.topIssuesService.QueryTopIssues(c.Request.Context(), q)
+	if errors.Is(err, analytics_services.ErrInvalidTopIssuesQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to fetch top issues")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch top issues"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// parseTopIssuesQuery reads the top issues filters from the query string.
+func parseTopIssuesQuery(c *gin.Context) (analytics_models.TopIssuesQuery, error) {
+	q := analytics_models.TopIssuesQuery{
+		Service: c.Query("service"),
+		Level:   c.Query("level"),
+		OrderBy: analytics_models.IssueOrder(c.Query("order")),
+	}
+
+	if err := queryTimeRange(c, &q.Start, &q.End); err != nil {
+		return q, err
+	}
+
+	for name, dst := range map[string]*int{"min_count": &q.MinCount, "limit": &q.Limit, "offset": &q.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return q, errors.New(name + " must be an integer")
+			}
+			*dst = n
+		}
+	}
+	return q, nil
+}
+
+// queryTimeRange overwrites start and end with the RFC 3339 "start" and
+// "end" query parameters when present.
+func queryTimeRange(c *gin.Context, start, end *time.Time) error {
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"start", start}, {"end", end}} {
+		if v := c.Query(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return errors.New(p.name + " must be an RFC 3339 timestamp")
+			}
+			*p.dst = t
+		}
+	}
+	return nil
 }
 
 // ExportData exports analytics data to a specified format.
 // It responds with a success message or an error if the export fails.
+//
+// Query parameters: service (required), metric_type (default
+// error_frequency), format ("json", "csv" or "xlsx"; default json) and
+// start/end (RFC 3339; default the last 24 hours). The file is streamed as
+// an attachment.
 func (h *AnalyticsHandler) ExportData(c *gin.Context) {
-	// Implementation for exporting data
+	service := c.Query("service")
+	if service == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "service is required"})
+		return
+	}
+	metricType := analytics_models.MetricType(c.DefaultQuery("metric_type", string(analytics_models.ErrorFrequency)))
+	format := c.DefaultQuery("format", analytics_services.ExportFormatJSON)
+	contentType, err := analytics_services.ExportContentType(format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-24 * time.Hour)
+	if err := queryTimeRange(c, &start, &end); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("analytics-%s-%s-%s.%s", metricType, service, end.Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Status(http.StatusOK)
+
+	err = h.exportService.StreamExport(c.Request.Context(), c.Writer, metricType, service, start, end, format)
+	if err == nil {
+		return
+	}
+	h.logger.WithError(err).Error("Failed to export data")
+	if c.Writer.Written() {
+		// Part of the file is already sent; the truncated download is all we can signal
+		c.Abort()
+		return
+	}
+	c.Writer.Header().Del("Content-Disposition")
+	c.Writer.Header().Del("Content-Type")
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export data"})
 }
@@ -2,12 +2,23 @@ package internal_analytics_handlers
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
 	analytics_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/services"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/testutils"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewAnalyticsHandler(t *testing.T) {
@@ -84,3 +95,317 @@ func TestAnalyticsHandlerFields(t *testing.T) {
 	_ = handler.topIssuesService
 	_ = handler.exportService
 }
+
+func newTopIssuesRouter(reader *testutils.MockLogReader) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(bytes.NewBuffer(nil))
+
+	handler := NewAnalyticsHandler(nil, nil, nil, analytics_services.NewTopIssuesService(reader, logger), nil, logger)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+	return router
+}
+
+func TestGetTopIssues_PassesFilters(t *testing.T) {
+	reader := &testutils.MockLogReader{}
+	start := time.Date(2025, 11, 12, 0, 0, 0, 0, time.UTC)
+	reader.On("FindTopIssues", mock.Anything, analytics_models.TopIssuesQuery{
+		Start: start, End: start.Add(6 * time.Hour), Service: "review", Level: "error",
+		OrderBy: analytics_models.IssueOrderRecent, MinCount: 5, Limit: 25, Offset: 50,
+	}).Return([]analytics_models.IssueItem{{Service: "review", Message: "model timeout", Count: 9}}, 61, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/top-issues?service=review&level=error"+
+		"&start=2025-11-12T00:00:00Z&end=2025-11-12T06:00:00Z&min_count=5&order=recent&limit=25&offset=50", http.NoBody)
+	newTopIssuesRouter(reader).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp analytics_models.TopIssuesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 61, resp.Total)
+	assert.Equal(t, 25, resp.Limit)
+	assert.Equal(t, 50, resp.Offset)
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, "model timeout", resp.Issues[0].Message)
+	reader.AssertExpectations(t)
+}
+
+func TestGetTopIssues_BadRequest(t *testing.T) {
+	for _, query := range []string{"limit=ten", "start=yesterday", "order=severity", "offset=-1"} {
+		t.Run(query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/analytics/top-issues?"+query, http.NoBody)
+			newTopIssuesRouter(&testutils.MockLogReader{}).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestGetTopIssues_ReaderError(t *testing.T) {
+	reader := &testutils.MockLogReader{}
+	reader.On("FindTopIssues", mock.Anything, mock.Anything).Return(nil, 0, errors.New("connection refused"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/top-issues", http.NoBody)
+	newTopIssuesRouter(reader).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "connection refused")
+}
+
+func newExportRouter(repo *testutils.MockAggregationRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(bytes.NewBuffer(nil))
+
+	handler := NewAnalyticsHandler(nil, nil, nil, nil, analytics_services.NewExportService(repo, logger), logger)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+	return router
+}
+
+func TestExportData_CSVAttachment(t *testing.T) {
+	repo := &testutils.MockAggregationRepository{}
+	start := time.Date(2025, 11, 12, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC)
+	repo.On("FindByRange", mock.Anything, analytics_models.ServiceActivity, "portal", start, end).
+		Return([]*analytics_models.Aggregation{
+			{MetricType: analytics_models.ServiceActivity, Service: "portal", Value: 12, TimeBucket: start},
+			{MetricType: analytics_models.ServiceActivity, Service: "portal", Value: 7, TimeBucket: start.Add(time.Hour)},
+		}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/export?service=portal&metric_type=service_activity"+
+		"&format=csv&start=2025-11-12T00:00:00Z&end=2025-11-13T00:00:00Z", http.NoBody)
+	newExportRouter(repo).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=analytics-service_activity-portal-20251113.csv", w.Header().Get("Content-Disposition"))
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Len(t, lines, 3)
+	repo.AssertExpectations(t)
+}
+
+func TestExportData_BadRequest(t *testing.T) {
+	for _, query := range []string{"format=csv", "service=portal&format=pdf", "service=portal&start=today"} {
+		t.Run(query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/analytics/export?"+query, http.NoBody)
+			newExportRouter(&testutils.MockAggregationRepository{}).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestExportData_QueryFailure(t *testing.T) {
+	repo := &testutils.MockAggregationRepository{}
+	repo.On("FindByRange", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("connection reset"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/export?service=portal&format=xlsx", http.NoBody)
+	newExportRouter(repo).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+}
+
+func TestGetAnomalies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(bytes.NewBuffer(nil))
+
+	start := time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC)
+	var series []*analytics_models.Aggregation
+	for i := 0; i < 10; i++ {
+		value := 40.0
+		if i == 9 {
+			value = 400
+		}
+		series = append(series, &analytics_models.Aggregation{TimeBucket: start.Add(time.Duration(i) * time.Hour), Value: value})
+	}
+	repo := &testutils.MockAggregationRepository{}
+	repo.On("FindByRange", mock.Anything, analytics_models.ErrorFrequency, "review", mock.Anything, mock.Anything).Return(series, nil)
+
+	handler := NewAnalyticsHandler(nil, nil, analytics_services.NewAnomalyService(repo, logger), nil, nil, logger)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/anomalies?service=review&method=mad&sensitivity=4"+
+		"&start=2025-11-13T00:00:00Z&end=2025-11-13T12:00:00Z", http.NoBody)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp analytics_models.AnomalyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, analytics_models.AnomalyMethodMAD, resp.Options.Method)
+	assert.Equal(t, 4.0, resp.Options.Sensitivity)
+	require.Len(t, resp.Anomalies, 1)
+	assert.Equal(t, 400.0, resp.Anomalies[0].Value)
+	assert.Equal(t, 40.0, resp.Anomalies[0].Expected)
+
+	for _, query := range []string{"method=mad", "service=review&method=prophet", "service=review&sensitivity=high", "service=review&seasonal=maybe"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/anomalies?"+query, http.NoBody))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestGetTrendForecast(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(bytes.NewBuffer(nil))
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var series []*analytics_models.Aggregation
+	for d := 14; d >= 1; d-- {
+		series = append(series, &analytics_models.Aggregation{TimeBucket: today.AddDate(0, 0, -d), Value: float64(200 - 5*d)})
+	}
+	repo := &testutils.MockAggregationRepository{}
+	repo.On("FindByRange", mock.Anything, analytics_models.ErrorFrequency, "review", mock.Anything, mock.Anything).Return(series, nil)
+	repo.On("FindByRange", mock.Anything, analytics_models.ErrorFrequency, "portal", mock.Anything, mock.Anything).Return(series[12:], nil)
+
+	handler := NewAnalyticsHandler(nil, analytics_services.NewTrendService(repo, logger), nil, nil, nil, logger)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/trends/review/forecast?horizon=7", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var forecast analytics_models.TrendForecast
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &forecast))
+	assert.Equal(t, "review", forecast.Service)
+	assert.Len(t, forecast.Points, 7)
+	assert.InDelta(t, 5, forecast.Slope, 1e-6)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/trends/portal/forecast", http.NoBody))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "insufficient history")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/trends/review/forecast?horizon=365", http.NoBody))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetAggregations_CacheStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(bytes.NewBuffer(nil))
+
+	start := time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC)
+	repo := &testutils.MockAggregationRepository{}
+	repo.On("FindByRange", mock.Anything, analytics_models.ErrorFrequency, "review", start, start.Add(2*time.Hour-time.Nanosecond)).
+		Return([]*analytics_models.Aggregation{{TimeBucket: start, Value: 4}, {TimeBucket: start.Add(time.Hour), Value: 6}}, nil).Once()
+
+	aggregator := analytics_services.NewAggregatorService(repo, &testutils.MockLogReader{}, logger)
+	handler := NewAnalyticsHandler(aggregator, nil, nil, nil, nil, logger)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+	handler.RegisterDebugRoutes(router.Group("/api/analytics/debug"))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet,
+			"/api/analytics/aggregations?service=review&start=2025-11-13T00:00:00Z&end=2025-11-13T02:00:00Z", http.NoBody))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), `"value":6`)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/debug/cache", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	var stats analytics_services.CacheStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	repo.AssertExpectations(t)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/aggregations?service=review&bucket=week", http.NoBody))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRegisterDebugRoutes_BehindAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(bytes.NewBuffer(nil))
+	aggregator := analytics_services.NewAggregatorService(&testutils.MockAggregationRepository{}, &testutils.MockLogReader{}, logger)
+	handler := NewAnalyticsHandler(aggregator, nil, nil, nil, nil, logger)
+
+	router := gin.New()
+	handler.RegisterRoutes(router)
+	handler.RegisterDebugRoutes(router.Group("/api/analytics/debug", func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	}))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/debug/cache", http.NoBody))
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "RegisterRoutes doesn't expose the cache stats")
+}
+
+// fixedRollupRepo serves rollups up to a fixed watermark and raw counts after it.
+type fixedRollupRepo struct {
+	watermark   time.Time
+	rolled, raw []analytics_models.LogRollup
+}
+
+func (r *fixedRollupRepo) RollUp(context.Context, string, time.Time, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fixedRollupRepo) Watermark(context.Context, string) (time.Time, error) {
+	return r.watermark, nil
+}
+
+func (r *fixedRollupRepo) FindRollups(context.Context, string, string, time.Time, time.Time) ([]analytics_models.LogRollup, error) {
+	return r.rolled, nil
+}
+
+func (r *fixedRollupRepo) CountRaw(context.Context, string, string, time.Time, time.Time) ([]analytics_models.LogRollup, error) {
+	return r.raw, nil
+}
+
+func TestGetLogCounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(bytes.NewBuffer(nil))
+
+	aggregator := analytics_services.NewAggregatorService(&testutils.MockAggregationRepository{}, &testutils.MockLogReader{}, logger)
+	handler := NewAnalyticsHandler(aggregator, nil, nil, nil, nil, logger)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+	url := "/api/analytics/log-counts?service=review&start=2025-11-13T00:00:00Z&end=2025-11-13T03:30:00Z"
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, http.NoBody))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "rollups not configured")
+
+	start := time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC)
+	aggregator.SetRollupRepository(&fixedRollupRepo{
+		watermark: start.Add(3 * time.Hour),
+		rolled:    []analytics_models.LogRollup{{BucketStart: start, BucketSize: "hour", Service: "review", Level: "error", Count: 7}},
+		raw:       []analytics_models.LogRollup{{BucketStart: start.Add(3 * time.Hour), BucketSize: "hour", Service: "review", Level: "error", Count: 2}},
+	})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var body struct {
+		Counts []analytics_models.LogRollup `json:"counts"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Counts, 2)
+	assert.Equal(t, int64(7), body.Counts[0].Count)
+	assert.Equal(t, int64(2), body.Counts[1].Count)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/log-counts?bucket=hour", http.NoBody))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -28,6 +28,9 @@ type LogReaderInterface interface {
 	// FindTopMessages finds most frequent log messages within a time range
 	FindTopMessages(ctx context.Context, service, level string, start, end time.Time, limit int) ([]analytics_models.IssueItem, error)
 
+	// FindTopIssues groups errors/warnings by issue and returns one page plus the total group count
+	FindTopIssues(ctx context.Context, q analytics_models.TopIssuesQuery) ([]analytics_models.IssueItem, int, error)
+
 	// FindAllServices returns list of all services that have logged
 	FindAllServices(ctx context.Context) ([]string, error)
 }
@@ -55,6 +58,9 @@ type AnomalyServiceInterface interface {
 type TopIssuesServiceInterface interface {
 	// GetTopIssues returns the most frequent errors/warnings within a time range
 	GetTopIssues(ctx context.Context, service, level string, start, end time.Time, limit int) ([]analytics_models.IssueItem, error)
+
+	// QueryTopIssues returns a filtered, ordered page of issue groups
+	QueryTopIssues(ctx context.Context, q analytics_models.TopIssuesQuery) (*analytics_models.TopIssuesResponse, error)
 }
 
 // ExportServiceInterface defines the contract for data export operations
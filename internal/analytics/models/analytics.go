@@ -47,6 +47,37 @@ type Anomaly struct {
 	ID         int64      `json:"id" db:"id"`
 	Value      float64    `json:"value" db:"value"`
 	ZScore     float64    `json:"z_score" db:"z_score"`
+	// Expected is the baseline value for the bucket; ExpectedLow and
+	// ExpectedHigh bound the range that was not considered anomalous.
+	Expected     float64 `json:"expected"`
+	ExpectedLow  float64 `json:"expected_low"`
+	ExpectedHigh float64 `json:"expected_high"`
+}
+
+// AnomalyMethod selects how the baseline spread is measured
+type AnomalyMethod string
+
+const (
+	// AnomalyMethodZScore measures deviation in standard deviations from the mean.
+	AnomalyMethodZScore AnomalyMethod = "zscore"
+	// AnomalyMethodMAD measures deviation from the median, scaled from the
+	// median absolute deviation. It is robust to earlier outliers in the baseline.
+	AnomalyMethodMAD AnomalyMethod = "mad"
+)
+
+// AnomalyOptions configures anomaly detection. Zero values use defaults.
+type AnomalyOptions struct {
+	// Method is the deviation measure (default zscore)
+	Method AnomalyMethod `json:"method"`
+	// Sensitivity is K: points more than K deviations from the baseline are anomalies (default 3)
+	Sensitivity float64 `json:"sensitivity"`
+	// Window is the number of preceding buckets in the rolling baseline (default 24)
+	Window int `json:"window"`
+	// Seasonal compares each bucket with the same weekday and hour in
+	// earlier weeks instead of the preceding buckets
+	Seasonal bool `json:"seasonal"`
+	// SeasonalWeeks is how many earlier weeks the seasonal baseline uses (default 4)
+	SeasonalWeeks int `json:"seasonal_weeks"`
 }
 
 // LogEntry represents a log from logs.entries (READ-ONLY model)
@@ -88,23 +119,51 @@ type TimeRange struct {
 type TopIssuesResponse struct {
 	TimeRange TimeRange   `json:"time_range"`
 	Issues    []IssueItem `json:"issues"`
+	Total     int         `json:"total"` // distinct issue groups matching the filters
+	Limit     int         `json:"limit"`
+	Offset    int         `json:"offset"`
 }
 
 // IssueItem represents a frequent issue
 // Added Value field to align with test expectations
 type IssueItem struct {
-	LastSeen time.Time
+	LastSeen  time.Time
+	Service   string
+	Level     string
+	IssueType string
+	Message   string
+	Count     int
+	Value     float64
+}
+
+// IssueOrder selects how top issue groups are sorted
+type IssueOrder string
+
+const (
+	// IssueOrderCount sorts the most frequent issues first.
+	IssueOrderCount IssueOrder = "count"
+	// IssueOrderRecent sorts the most recently seen issues first.
+	IssueOrderRecent IssueOrder = "recent"
+)
+
+// TopIssuesQuery filters and pages top issue groups. Empty Service and
+// Level match all services and both errors and warnings.
+type TopIssuesQuery struct {
+	Start    time.Time
+	End      time.Time
 	Service  string
 	Level    string
-	Message  string
-	Count    int
-	Value    float64
+	OrderBy  IssueOrder
+	MinCount int
+	Limit    int
+	Offset   int
 }
 
 // AnomalyResponse returns detected anomalies
 type AnomalyResponse struct {
-	TimeRange TimeRange `json:"time_range"`
-	Anomalies []Anomaly `json:"anomalies"`
+	TimeRange TimeRange      `json:"time_range"`
+	Anomalies []Anomaly      `json:"anomalies"`
+	Options   AnomalyOptions `json:"options"`
 }
 
 // TrendAnalysis represents the analysis of trends.
@@ -119,6 +178,40 @@ type TrendAnalysis struct {
 	PercentageChange float64
 }
 
+// ForecastPoint is the predicted total for one future day
+type ForecastPoint struct {
+	Date      time.Time `json:"date"`
+	Predicted float64   `json:"predicted"`
+	Lower     float64   `json:"lower"` // 95% prediction interval
+	Upper     float64   `json:"upper"`
+}
+
+// TrendForecast projects a metric's daily totals forward from a linear fit
+// of its history
+type TrendForecast struct {
+	GeneratedAt    time.Time       `json:"generated_at"`
+	MetricType     MetricType      `json:"metric_type"`
+	Service        string          `json:"service"`
+	Points         []ForecastPoint `json:"points"`
+	Horizon        int             `json:"horizon_days"`
+	HistoryDays    int             `json:"history_days"`
+	Slope          float64         `json:"slope"` // change per day
+	Intercept      float64         `json:"intercept"`
+	RSquared       float64         `json:"r_squared"`
+	ProjectedTotal float64         `json:"projected_total"` // sum of predictions over the horizon
+}
+
+// LogRollup is the number of logs for a service, level and issue type in
+// one hourly or daily bucket
+type LogRollup struct {
+	BucketStart time.Time `json:"bucket_start" db:"bucket_start"`
+	BucketSize  string    `json:"bucket_size" db:"bucket_size"`
+	Service     string    `json:"service" db:"service"`
+	Level       string    `json:"level" db:"level"`
+	IssueType   string    `json:"issue_type" db:"issue_type"`
+	Count       int64     `json:"count" db:"count"`
+}
+
 // Replace MinTime and MaxTime constants with variables
 var (
 	MinTime = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
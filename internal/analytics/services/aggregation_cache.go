@@ -0,0 +1,205 @@
+package analytics_services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
+)
+
+// DefaultAggregationCacheTTL is how long an aggregation series is served
+// from memory before it is read again.
+const DefaultAggregationCacheTTL = 30 * time.Second
+
+// DefaultAggregationCacheMaxEntries bounds the cache; past it the series
+// stored longest ago are evicted first.
+const DefaultAggregationCacheMaxEntries = 1000
+
+// CacheStats reports aggregation cache activity since startup.
+type CacheStats struct {
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Invalidations uint64 `json:"invalidations"`
+	Evictions     uint64 `json:"evictions"`
+	Entries       int    `json:"entries"`
+	MaxEntries    int    `json:"max_entries"`
+	TTLSeconds    int    `json:"ttl_seconds"`
+}
+
+// aggregationCache holds recent aggregation series keyed by their normalized
+// query. Concurrent misses for the same key share one load. Writes for a
+// service drop its entries; a load that started before such a write is not
+// stored, so stale data never outlives an invalidation. Expired entries
+// are swept at most once per TTL as new keys are added, and the cache never
+// holds more than maxEntries finished loads.
+type aggregationCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu         sync.Mutex
+	entries    map[string]*aggregationCacheEntry
+	generation map[string]uint64 // per service, bumped on invalidation
+	lastSweep  time.Time
+
+	hits, misses, invalidations, evictions atomic.Uint64
+}
+
+type aggregationCacheEntry struct {
+	service  string
+	ready    chan struct{} // closed once the load finishes
+	points   []analytics_models.AggregationDataPoint
+	err      error
+	storedAt time.Time
+}
+
+func newAggregationCache(ttl time.Duration) *aggregationCache {
+	if ttl <= 0 {
+		ttl = DefaultAggregationCacheTTL
+	}
+	return &aggregationCache{
+		ttl:        ttl,
+		maxEntries: DefaultAggregationCacheMaxEntries,
+		now:        time.Now,
+		entries:    make(map[string]*aggregationCacheEntry),
+		generation: make(map[string]uint64),
+	}
+}
+
+// cacheKey identifies a normalized aggregation query.
+func (q AggregationQuery) cacheKey() string {
+	return fmt.Sprintf("%s|%s|%s|%d|%d", q.MetricType, q.Service, q.Bucket, q.Start.Unix(), q.End.Unix())
+}
+
+// get returns the cached series for q, calling load on a miss. The returned
+// slice is a copy the caller may modify.
+func (c *aggregationCache) get(ctx context.Context, q AggregationQuery, load func(context.Context) ([]analytics_models.AggregationDataPoint, error)) ([]analytics_models.AggregationDataPoint, error) {
+	key := q.cacheKey()
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok {
+		select {
+		case <-entry.ready:
+			if c.now().Sub(entry.storedAt) < c.ttl {
+				c.mu.Unlock()
+				c.hits.Add(1)
+				return copyPoints(entry.points), nil
+			}
+		default:
+			// Another request is loading this key; wait for its result
+			c.mu.Unlock()
+			c.hits.Add(1)
+			select {
+			case <-entry.ready:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			if entry.err != nil {
+				return nil, entry.err
+			}
+			return copyPoints(entry.points), nil
+		}
+	}
+	c.evictLocked()
+	entry := &aggregationCacheEntry{service: q.Service, ready: make(chan struct{})}
+	c.entries[key] = entry
+	generation := c.generation[q.Service]
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	entry.points, entry.err = load(ctx)
+
+	c.mu.Lock()
+	entry.storedAt = c.now()
+	if entry.err != nil || c.generation[q.Service] != generation {
+		// Failed loads are not cached, nor loads raced by a write
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+	}
+	c.mu.Unlock()
+	close(entry.ready)
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return copyPoints(entry.points), nil
+}
+
+// evictLocked makes room for a new entry: it drops expired entries once per
+// TTL, then the oldest finished ones while the cache is full. Loads in
+// progress are never evicted. c.mu must be held.
+func (c *aggregationCache) evictLocked() {
+	now := c.now()
+	full := c.maxEntries > 0 && len(c.entries) >= c.maxEntries
+	if !full && now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+	for key, entry := range c.entries {
+		if isReady(entry) && now.Sub(entry.storedAt) >= c.ttl {
+			delete(c.entries, key)
+			c.evictions.Add(1)
+		}
+	}
+
+	for c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		oldestKey := ""
+		var oldest time.Time
+		for key, entry := range c.entries {
+			if isReady(entry) && (oldestKey == "" || entry.storedAt.Before(oldest)) {
+				oldestKey, oldest = key, entry.storedAt
+			}
+		}
+		if oldestKey == "" {
+			return
+		}
+		delete(c.entries, oldestKey)
+		c.evictions.Add(1)
+	}
+}
+
+func isReady(entry *aggregationCacheEntry) bool {
+	select {
+	case <-entry.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// invalidate drops every cached series for service.
+func (c *aggregationCache) invalidate(service string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation[service]++
+	for key, entry := range c.entries {
+		if entry.service == service {
+			delete(c.entries, key)
+		}
+	}
+	c.invalidations.Add(1)
+}
+
+func (c *aggregationCache) stats() CacheStats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+	return CacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+		Evictions:     c.evictions.Load(),
+		Entries:       entries,
+		MaxEntries:    c.maxEntries,
+		TTLSeconds:    int(c.ttl / time.Second),
+	}
+}
+
+func copyPoints(points []analytics_models.AggregationDataPoint) []analytics_models.AggregationDataPoint {
+	return append([]analytics_models.AggregationDataPoint{}, points...)
+}
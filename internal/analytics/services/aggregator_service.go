@@ -3,19 +3,47 @@ package analytics_services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"time"
 
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics"
+	analytics_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/db"
 	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
 	"github.com/sirupsen/logrus"
 )
 
+// Bucket sizes for aggregation series.
+const (
+	BucketHour = "hour"
+	BucketDay  = "day"
+)
+
+var (
+	// ErrInvalidAggregationQuery is returned for aggregation queries that cannot be run.
+	ErrInvalidAggregationQuery = errors.New("invalid aggregation query")
+	// ErrRollupsNotConfigured is returned by GetLogCounts without a rollup repository.
+	ErrRollupsNotConfigured = errors.New("log rollups are not configured")
+)
+
+// AggregationQuery selects an aggregation series. Start and End are rounded
+// out to whole buckets, so queries for the same window share a cache entry.
+type AggregationQuery struct {
+	Start      time.Time
+	End        time.Time
+	MetricType analytics_models.MetricType
+	Service    string
+	Bucket     string // BucketHour (default) or BucketDay
+}
+
 // AggregatorService provides methods to aggregate data.
 type AggregatorService struct {
 	aggregationRepo analytics.AggregationRepositoryInterface
 	logReader       analytics.LogReaderInterface
 	logger          *logrus.Logger
+	cache           *aggregationCache
+	rollups         analytics_db.RollupRepositoryInterface
 }
 
 // NewAggregatorService creates a new instance of AggregatorService.
@@ -24,9 +52,152 @@ func NewAggregatorService(aggregationRepo analytics.AggregationRepositoryInterfa
 		aggregationRepo: aggregationRepo,
 		logReader:       logReader,
 		logger:          logger,
+		cache:           newAggregationCache(DefaultAggregationCacheTTL),
 	}
 }
 
+// SetCacheTTL sets how long aggregation series are cached. It must be called
+// before the service handles requests.
+func (s *AggregatorService) SetCacheTTL(ttl time.Duration) {
+	maxEntries := s.cache.maxEntries
+	s.cache = newAggregationCache(ttl)
+	s.cache.maxEntries = maxEntries
+}
+
+// SetCacheMaxEntries sets how many aggregation series the cache holds;
+// 0 removes the bound. It must be called before the service handles
+// requests.
+func (s *AggregatorService) SetCacheMaxEntries(n int) {
+	s.cache.maxEntries = n
+}
+
+// SetRollupRepository enables GetLogCounts, which reads log counts from the
+// rollups maintained by RollupJob.
+func (s *AggregatorService) SetRollupRepository(repo analytics_db.RollupRepositoryInterface) {
+	s.rollups = repo
+}
+
+// GetLogCounts returns log counts for a service per bucket, level and issue
+// type. Buckets up to the rollup watermark are read from the rollup table;
+// only the rest (normally just the bucket in progress) is counted from raw logs.
+func (s *AggregatorService) GetLogCounts(ctx context.Context, service, bucket string, start, end time.Time) ([]analytics_models.LogRollup, error) {
+	if s.rollups == nil {
+		return nil, ErrRollupsNotConfigured
+	}
+	if service == "" {
+		return nil, fmt.Errorf("%w: service is required", ErrInvalidAggregationQuery)
+	}
+	bucket, start, end, err := normalizeBuckets(bucket, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	split, err := s.rollups.Watermark(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+	if split.Before(start) {
+		split = start
+	}
+	if split.After(end) {
+		split = end
+	}
+
+	counts := []analytics_models.LogRollup{}
+	if start.Before(split) {
+		rolled, err := s.rollups.FindRollups(ctx, service, bucket, start, split)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to read log rollups")
+			return nil, err
+		}
+		counts = append(counts, rolled...)
+	}
+	if split.Before(end) {
+		raw, err := s.rollups.CountRaw(ctx, service, bucket, split, end)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to count raw logs")
+			return nil, err
+		}
+		counts = append(counts, raw...)
+	}
+	return counts, nil
+}
+
+// CacheStats returns the aggregation cache counters.
+func (s *AggregatorService) CacheStats() CacheStats {
+	return s.cache.stats()
+}
+
+// GetAggregationSeries returns a metric's values for a service in hourly or
+// daily buckets. Identical queries within the cache TTL are answered from
+// memory; writing an aggregation for the service clears its entries.
+func (s *AggregatorService) GetAggregationSeries(ctx context.Context, q AggregationQuery) ([]analytics_models.AggregationDataPoint, error) {
+	q, err := normalizeAggregationQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	return s.cache.get(ctx, q, func(ctx context.Context) ([]analytics_models.AggregationDataPoint, error) {
+		aggregations, err := s.aggregationRepo.FindByRange(ctx, q.MetricType, q.Service, q.Start, q.End.Add(-time.Nanosecond))
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to retrieve aggregations")
+			return nil, err
+		}
+		return rollUp(aggregations, q.Bucket), nil
+	})
+}
+
+func normalizeAggregationQuery(q AggregationQuery) (AggregationQuery, error) {
+	if q.Service == "" || q.MetricType == "" {
+		return q, fmt.Errorf("%w: service and metric type are required", ErrInvalidAggregationQuery)
+	}
+	var err error
+	q.Bucket, q.Start, q.End, err = normalizeBuckets(q.Bucket, q.Start, q.End)
+	return q, err
+}
+
+// normalizeBuckets defaults bucket to BucketHour and rounds [start, end)
+// out to whole UTC buckets.
+func normalizeBuckets(bucket string, start, end time.Time) (string, time.Time, time.Time, error) {
+	var size time.Duration
+	switch bucket {
+	case "", BucketHour:
+		bucket, size = BucketHour, time.Hour
+	case BucketDay:
+		size = 24 * time.Hour
+	default:
+		return bucket, start, end, fmt.Errorf("%w: bucket must be %q or %q", ErrInvalidAggregationQuery, BucketHour, BucketDay)
+	}
+	if !start.Before(end) {
+		return bucket, start, end, fmt.Errorf("%w: start must be before end", ErrInvalidAggregationQuery)
+	}
+
+	start = start.UTC().Truncate(size)
+	if rounded := end.UTC().Truncate(size); rounded.Equal(end.UTC()) {
+		end = rounded
+	} else {
+		end = rounded.Add(size)
+	}
+	return bucket, start, end, nil
+}
+
+// rollUp sums time-ordered aggregations into buckets of the given size.
+func rollUp(aggregations []*analytics_models.Aggregation, bucket string) []analytics_models.AggregationDataPoint {
+	size := time.Hour
+	if bucket == BucketDay {
+		size = 24 * time.Hour
+	}
+	points := []analytics_models.AggregationDataPoint{}
+	for _, agg := range aggregations {
+		ts := agg.TimeBucket.UTC().Truncate(size)
+		if n := len(points); n > 0 && points[n-1].Timestamp.Equal(ts) {
+			points[n-1].Value += agg.Value
+			continue
+		}
+		points = append(points, analytics_models.AggregationDataPoint{Timestamp: ts, Value: agg.Value})
+	}
+	return points
+}
+
 // RunHourlyAggregation performs hourly aggregation for all services
 func (s *AggregatorService) RunHourlyAggregation(ctx context.Context) error {
 	s.logger.Info("Starting hourly aggregation job")
@@ -59,6 +230,8 @@ func (s *AggregatorService) RunHourlyAggregation(ctx context.Context) error {
 
 func (s *AggregatorService) aggregateService(ctx context.Context, service string) error {
 	log.Printf("AggregatorService.aggregateService called with service=%s", service)
+	// Levels upserted before a failure are written too, so always invalidate
+	defer s.cache.invalidate(service)
 
 	levels := []string{"info", "warn", "error"}
 	end := time.Now().Truncate(time.Hour)
@@ -144,5 +317,9 @@ func (s *AggregatorService) Upsert(ctx context.Context, service, level string, c
 		Value:      float64(count),
 		TimeBucket: timestamp,
 	}
-	return s.aggregationRepo.Upsert(ctx, aggregation)
+	if err := s.aggregationRepo.Upsert(ctx, aggregation); err != nil {
+		return err
+	}
+	s.cache.invalidate(service)
+	return nil
 }
@@ -3,7 +3,12 @@ package analytics_services_test
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
 	analytics_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/services"
@@ -12,6 +17,7 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -166,3 +172,212 @@ func TestAggregatorService_FindAllServices_IsCalled(t *testing.T) {
 	assert.NoError(t, err)
 	mockLogReader.AssertCalled(t, "FindAllServices", mock.Anything)
 }
+
+// countingAggregationRepo serves a fixed series and counts FindByRange calls.
+// While gate is set, reads block until it is closed.
+type countingAggregationRepo struct {
+	testutils.MockAggregationRepository
+	series []*analytics_models.Aggregation
+	calls  atomic.Int32
+	gate   chan struct{}
+	err    error
+}
+
+func (r *countingAggregationRepo) FindByRange(_ context.Context, _ analytics_models.MetricType, _ string, _, _ time.Time) ([]*analytics_models.Aggregation, error) {
+	r.calls.Add(1)
+	if r.gate != nil {
+		<-r.gate
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.series, nil
+}
+
+func (r *countingAggregationRepo) Upsert(_ context.Context, _ *analytics_models.Aggregation) error {
+	return nil
+}
+
+func newCachedAggregator(repo *countingAggregationRepo) *analytics_services.AggregatorService {
+	logger, _ := test.NewNullLogger()
+	return analytics_services.NewAggregatorService(repo, new(testutils.MockLogReader), logger)
+}
+
+var seriesQuery = analytics_services.AggregationQuery{
+	Start:      time.Date(2025, 11, 12, 0, 0, 0, 0, time.UTC),
+	End:        time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC),
+	MetricType: analytics_models.ErrorFrequency,
+	Service:    service1,
+}
+
+func hourlySeries(hours int) []*analytics_models.Aggregation {
+	series := make([]*analytics_models.Aggregation, hours)
+	for i := range series {
+		series[i] = &analytics_models.Aggregation{TimeBucket: seriesQuery.Start.Add(time.Duration(i) * time.Hour), Value: float64(i)}
+	}
+	return series
+}
+
+func TestAggregatorService_GetAggregationSeries_CachesIdenticalQueries(t *testing.T) {
+	repo := &countingAggregationRepo{series: hourlySeries(24)}
+	service := newCachedAggregator(repo)
+	ctx := context.Background()
+
+	first, err := service.GetAggregationSeries(ctx, seriesQuery)
+	require.NoError(t, err)
+	second, err := service.GetAggregationSeries(ctx, seriesQuery)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), repo.calls.Load(), "the second request is served from the cache")
+	assert.Equal(t, first, second)
+	assert.Len(t, first, 24)
+
+	// Unaligned times within the same buckets normalize to the same key
+	shifted := seriesQuery
+	shifted.Start = shifted.Start.Add(20 * time.Minute)
+	shifted.End = shifted.End.Add(-40 * time.Minute)
+	_, err = service.GetAggregationSeries(ctx, shifted)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), repo.calls.Load())
+
+	stats := service.CacheStats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, 1, stats.Entries)
+}
+
+func TestAggregatorService_GetAggregationSeries_InvalidatedByUpsert(t *testing.T) {
+	repo := &countingAggregationRepo{series: hourlySeries(24)}
+	service := newCachedAggregator(repo)
+	ctx := context.Background()
+
+	_, err := service.GetAggregationSeries(ctx, seriesQuery)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Upsert(ctx, service2, logLevelError, 3, seriesQuery.Start))
+	_, err = service.GetAggregationSeries(ctx, seriesQuery)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), repo.calls.Load(), "writes for other services keep the entry")
+
+	require.NoError(t, service.Upsert(ctx, service1, logLevelError, 3, seriesQuery.Start))
+	_, err = service.GetAggregationSeries(ctx, seriesQuery)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), repo.calls.Load(), "a write for the service clears its entries")
+	assert.Equal(t, uint64(2), service.CacheStats().Invalidations)
+}
+
+func TestAggregatorService_GetAggregationSeries_ConcurrentMissesShareLoad(t *testing.T) {
+	repo := &countingAggregationRepo{series: hourlySeries(24), gate: make(chan struct{})}
+	service := newCachedAggregator(repo)
+
+	var wg sync.WaitGroup
+	results := make([][]analytics_models.AggregationDataPoint, 20)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			points, err := service.GetAggregationSeries(context.Background(), seriesQuery)
+			assert.NoError(t, err)
+			results[i] = points
+		}(i)
+	}
+	require.Eventually(t, func() bool { return repo.calls.Load() == 1 }, time.Second, time.Millisecond)
+	close(repo.gate)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), repo.calls.Load())
+	for _, points := range results {
+		assert.Len(t, points, 24)
+	}
+}
+
+func TestAggregatorService_GetAggregationSeries_ExpiresAndSkipsErrors(t *testing.T) {
+	repo := &countingAggregationRepo{err: errors.New("connection refused")}
+	service := newCachedAggregator(repo)
+	service.SetCacheTTL(10 * time.Millisecond)
+	ctx := context.Background()
+
+	_, err := service.GetAggregationSeries(ctx, seriesQuery)
+	assert.Error(t, err)
+	repo.err = nil
+	repo.series = hourlySeries(2)
+	_, err = service.GetAggregationSeries(ctx, seriesQuery)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), repo.calls.Load(), "failures are not cached")
+
+	time.Sleep(20 * time.Millisecond)
+	_, err = service.GetAggregationSeries(ctx, seriesQuery)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), repo.calls.Load(), "entries expire after the TTL")
+}
+
+func TestAggregatorService_GetAggregationSeries_CacheIsBounded(t *testing.T) {
+	repo := &countingAggregationRepo{series: hourlySeries(2)}
+	service := newCachedAggregator(repo)
+	service.SetCacheMaxEntries(3)
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		q := seriesQuery
+		q.Service = fmt.Sprintf("service-%d", i)
+		_, err := service.GetAggregationSeries(ctx, q)
+		require.NoError(t, err)
+	}
+
+	stats := service.CacheStats()
+	assert.Equal(t, 3, stats.Entries)
+	assert.Equal(t, uint64(7), stats.Evictions)
+
+	// The most recent series are the ones kept
+	q := seriesQuery
+	q.Service = "service-9"
+	_, err := service.GetAggregationSeries(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), repo.calls.Load())
+}
+
+func TestAggregatorService_GetAggregationSeries_SweepsExpiredEntries(t *testing.T) {
+	repo := &countingAggregationRepo{series: hourlySeries(2)}
+	service := newCachedAggregator(repo)
+	service.SetCacheTTL(10 * time.Millisecond)
+	ctx := context.Background()
+
+	for _, name := range []string{"a", "b", "c"} {
+		q := seriesQuery
+		q.Service = name
+		_, err := service.GetAggregationSeries(ctx, q)
+		require.NoError(t, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	q := seriesQuery
+	q.Service = "d"
+	_, err := service.GetAggregationSeries(ctx, q)
+	require.NoError(t, err)
+	assert.Equal(t, 1, service.CacheStats().Entries, "keys that are never asked for again don't pile up")
+}
+
+func TestAggregatorService_GetAggregationSeries_DailyBuckets(t *testing.T) {
+	repo := &countingAggregationRepo{series: hourlySeries(48)}
+	service := newCachedAggregator(repo)
+
+	q := seriesQuery
+	q.End = q.End.Add(24 * time.Hour)
+	q.Bucket = analytics_services.BucketDay
+	points, err := service.GetAggregationSeries(context.Background(), q)
+
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.Equal(t, seriesQuery.Start, points[0].Timestamp)
+	assert.Equal(t, 276.0, points[0].Value) // 0+1+...+23
+	assert.Equal(t, 852.0, points[1].Value) // 24+...+47
+
+	for _, bad := range []analytics_services.AggregationQuery{
+		{MetricType: analytics_models.ErrorFrequency, Start: q.Start, End: q.End},
+		{Service: service1, MetricType: analytics_models.ErrorFrequency, Start: q.End, End: q.Start},
+		{Service: service1, MetricType: analytics_models.ErrorFrequency, Start: q.Start, End: q.End, Bucket: "week"},
+	} {
+		_, err := service.GetAggregationSeries(context.Background(), bad)
+		assert.ErrorIs(t, err, analytics_services.ErrInvalidAggregationQuery)
+	}
+}
@@ -3,7 +3,10 @@ package analytics_services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	analytics_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/db"
@@ -11,6 +14,25 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Anomaly detection defaults.
+const (
+	DefaultAnomalySensitivity = 3.0
+	DefaultAnomalyWindow      = 24
+	DefaultSeasonalWeeks      = 4
+
+	// minBaselinePoints is the fewest baseline points a bucket is judged against.
+	minBaselinePoints = 3
+	// madScale makes the median absolute deviation comparable to a standard
+	// deviation for normally distributed data.
+	madScale = 1.4826
+	// minRelativeSpread keeps a flat baseline from flagging tiny changes: the
+	// spread is at least this fraction of the baseline value.
+	minRelativeSpread = 0.05
+)
+
+// ErrInvalidAnomalyOptions is returned for anomaly options that cannot be used.
+var ErrInvalidAnomalyOptions = errors.New("invalid anomaly options")
+
 // AnomalyService provides methods to detect anomalies.
 type AnomalyService struct {
 	aggregationRepo analytics_db.AggregationRepositoryInterface
@@ -108,3 +130,191 @@ func calculateStats(aggregations []*analytics_models.Aggregation) (mean, stddev
 	stddev = math.Sqrt(variance)
 	return
 }
+
+// DetectAnomaliesWithOptions flags buckets in [start, end] that lie more than
+// opts.Sensitivity deviations from their baseline. The baseline is either the
+// opts.Window preceding buckets or, with opts.Seasonal, the same weekday and
+// hour in the preceding opts.SeasonalWeeks weeks, so regular weekly peaks are
+// not reported. Aggregations before start are read to build the first
+// baselines; buckets with too little history are not judged.
+func (s *AnomalyService) DetectAnomaliesWithOptions(ctx context.Context, metricType analytics_models.MetricType, service string, start, end time.Time, opts analytics_models.AnomalyOptions) ([]*analytics_models.Anomaly, error) {
+	opts, err := NormalizeAnomalyOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	lookback := time.Duration(opts.Window) * time.Hour
+	if opts.Seasonal {
+		lookback = time.Duration(opts.SeasonalWeeks) * 7 * 24 * time.Hour
+	}
+	aggregations, err := s.aggregationRepo.FindByRange(ctx, metricType, service, start.Add(-lookback), end)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to retrieve aggregations")
+		return nil, err
+	}
+
+	anomalies := detectAgainstBaseline(aggregations, start, end, opts)
+	for _, a := range anomalies {
+		a.MetricType = metricType
+		a.Service = service
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"metricType":  metricType,
+		"service":     service,
+		"method":      opts.Method,
+		"sensitivity": opts.Sensitivity,
+		"seasonal":    opts.Seasonal,
+		"points":      len(aggregations),
+		"count":       len(anomalies),
+	}).Info("Anomaly detection completed")
+	return anomalies, nil
+}
+
+// NormalizeAnomalyOptions applies defaults to opts and validates it.
+func NormalizeAnomalyOptions(opts analytics_models.AnomalyOptions) (analytics_models.AnomalyOptions, error) {
+	switch opts.Method {
+	case "":
+		opts.Method = analytics_models.AnomalyMethodZScore
+	case analytics_models.AnomalyMethodZScore, analytics_models.AnomalyMethodMAD:
+	default:
+		return opts, fmt.Errorf("%w: method must be %q or %q", ErrInvalidAnomalyOptions,
+			analytics_models.AnomalyMethodZScore, analytics_models.AnomalyMethodMAD)
+	}
+	if opts.Sensitivity < 0 || opts.Window < 0 || opts.SeasonalWeeks < 0 {
+		return opts, fmt.Errorf("%w: sensitivity, window and seasonal_weeks must not be negative", ErrInvalidAnomalyOptions)
+	}
+	if opts.Sensitivity == 0 {
+		opts.Sensitivity = DefaultAnomalySensitivity
+	}
+	if opts.Window == 0 {
+		opts.Window = DefaultAnomalyWindow
+	}
+	if opts.SeasonalWeeks == 0 {
+		opts.SeasonalWeeks = DefaultSeasonalWeeks
+	}
+	if !opts.Seasonal && opts.Window < minBaselinePoints {
+		return opts, fmt.Errorf("%w: window must be at least %d", ErrInvalidAnomalyOptions, minBaselinePoints)
+	}
+	return opts, nil
+}
+
+// detectAgainstBaseline judges each bucket of series within [start, end]
+// against its baseline. series must be in time order.
+func detectAgainstBaseline(series []*analytics_models.Aggregation, start, end time.Time, opts analytics_models.AnomalyOptions) []*analytics_models.Anomaly {
+	var anomalies []*analytics_models.Anomaly
+	now := time.Now().UTC()
+	for i, agg := range series {
+		if agg.TimeBucket.Before(start) || agg.TimeBucket.After(end) {
+			continue
+		}
+
+		var baseline []float64
+		if opts.Seasonal {
+			baseline = seasonalBaseline(series[:i], agg.TimeBucket, opts.SeasonalWeeks)
+		} else {
+			baseline = rollingBaseline(series[:i], opts.Window)
+		}
+		if len(baseline) < minBaselinePoints {
+			continue
+		}
+
+		center, spread := baselineStats(baseline, opts.Method)
+		score := (agg.Value - center) / spread
+		if math.Abs(score) <= opts.Sensitivity {
+			continue
+		}
+		anomalies = append(anomalies, &analytics_models.Anomaly{
+			TimeBucket:   agg.TimeBucket,
+			DetectedAt:   now,
+			Value:        agg.Value,
+			ZScore:       score,
+			Severity:     anomalySeverity(score, opts.Sensitivity),
+			Expected:     center,
+			ExpectedLow:  center - opts.Sensitivity*spread,
+			ExpectedHigh: center + opts.Sensitivity*spread,
+		})
+	}
+	return anomalies
+}
+
+// rollingBaseline returns the values of the last window buckets of previous.
+func rollingBaseline(previous []*analytics_models.Aggregation, window int) []float64 {
+	if len(previous) > window {
+		previous = previous[len(previous)-window:]
+	}
+	values := make([]float64, len(previous))
+	for i, agg := range previous {
+		values[i] = agg.Value
+	}
+	return values
+}
+
+// seasonalBaseline returns the values of previous buckets on the same weekday
+// and hour as bucket, up to weeks weeks back.
+func seasonalBaseline(previous []*analytics_models.Aggregation, bucket time.Time, weeks int) []float64 {
+	bucket = bucket.UTC()
+	oldest := bucket.Add(-time.Duration(weeks) * 7 * 24 * time.Hour)
+	var values []float64
+	for i := len(previous) - 1; i >= 0; i-- {
+		t := previous[i].TimeBucket.UTC()
+		if t.Before(oldest) {
+			break
+		}
+		if t.Weekday() == bucket.Weekday() && t.Hour() == bucket.Hour() && !t.Equal(bucket) {
+			values = append(values, previous[i].Value)
+		}
+	}
+	return values
+}
+
+// baselineStats returns the center and spread of values: mean and standard
+// deviation, or median and scaled MAD. The spread never falls below
+// minRelativeSpread of the center (or 1 for a zero baseline).
+func baselineStats(values []float64, method analytics_models.AnomalyMethod) (center, spread float64) {
+	if method == analytics_models.AnomalyMethodMAD {
+		center = median(values)
+		deviations := make([]float64, len(values))
+		for i, v := range values {
+			deviations[i] = math.Abs(v - center)
+		}
+		spread = madScale * median(deviations)
+	} else {
+		var sum, sumSquares float64
+		for _, v := range values {
+			sum += v
+			sumSquares += v * v
+		}
+		n := float64(len(values))
+		center = sum / n
+		spread = math.Sqrt(math.Max(sumSquares/n-center*center, 0))
+	}
+
+	floor := minRelativeSpread * math.Abs(center)
+	if floor == 0 {
+		floor = 1
+	}
+	return center, math.Max(spread, floor)
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// anomalySeverity grades how far past the threshold a score is.
+func anomalySeverity(score, sensitivity float64) string {
+	switch excess := math.Abs(score) / sensitivity; {
+	case excess >= 2:
+		return "high"
+	case excess >= 1.5:
+		return "medium"
+	default:
+		return "low"
+	}
+}
@@ -9,8 +9,10 @@ import (
 	analytics_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/services"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/testutils"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAnomalyService_DetectAnomalies(t *testing.T) {
@@ -40,3 +42,138 @@ func TestAnomalyService_DetectAnomalies(t *testing.T) {
 	}
 	mockRepo.AssertExpectations(t)
 }
+
+// weeklySeries builds hourly buckets starting Monday 2025-10-06 with a small
+// repeating jitter around 100 and a Monday-morning peak of 300 (09:00-11:59).
+func weeklySeries(weeks int) []*analytics_models.Aggregation {
+	start := time.Date(2025, 10, 6, 0, 0, 0, 0, time.UTC)
+	var series []*analytics_models.Aggregation
+	for i := 0; i < weeks*7*24; i++ {
+		bucket := start.Add(time.Duration(i) * time.Hour)
+		value := 100 + float64(i%5-2)
+		if bucket.Weekday() == time.Monday && bucket.Hour() >= 9 && bucket.Hour() < 12 {
+			value = 300 + float64(i%3-1)
+		}
+		series = append(series, &analytics_models.Aggregation{TimeBucket: bucket, Value: value})
+	}
+	return series
+}
+
+func detectWithOptions(t *testing.T, series []*analytics_models.Aggregation, start, end time.Time, opts analytics_models.AnomalyOptions) []*analytics_models.Anomaly {
+	t.Helper()
+	mockRepo := new(testutils.MockAggregationRepository)
+	mockRepo.On("FindByRange", mock.Anything, analytics_models.ErrorFrequency, "portal", mock.Anything, end).Return(series, nil)
+	logger, _ := test.NewNullLogger()
+
+	anomalies, err := analytics_services.NewAnomalyService(mockRepo, logger).
+		DetectAnomaliesWithOptions(context.Background(), analytics_models.ErrorFrequency, "portal", start, end, opts)
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	return anomalies
+}
+
+func anomalyBuckets(anomalies []*analytics_models.Anomaly) []time.Time {
+	buckets := make([]time.Time, len(anomalies))
+	for i, a := range anomalies {
+		buckets[i] = a.TimeBucket
+	}
+	return buckets
+}
+
+func TestAnomalyService_Seasonal_FlagsSpikeButNotWeeklyPeak(t *testing.T) {
+	series := weeklySeries(5)
+	spike := time.Date(2025, 11, 5, 14, 0, 0, 0, time.UTC) // Wednesday of week 5
+	for _, agg := range series {
+		if agg.TimeBucket.Equal(spike) {
+			agg.Value = 400
+		}
+	}
+	lastWeek := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
+	end := lastWeek.Add(7*24*time.Hour - time.Hour)
+
+	anomalies := detectWithOptions(t, series, lastWeek, end, analytics_models.AnomalyOptions{Seasonal: true})
+
+	require.Equal(t, []time.Time{spike}, anomalyBuckets(anomalies), "only the injected spike is anomalous")
+	a := anomalies[0]
+	assert.Equal(t, 400.0, a.Value)
+	assert.Equal(t, "portal", a.Service)
+	assert.Equal(t, analytics_models.ErrorFrequency, a.MetricType)
+	assert.InDelta(t, 100, a.Expected, 3)
+	assert.Less(t, a.ExpectedHigh, 400.0)
+	assert.Greater(t, a.ExpectedLow, 50.0)
+	assert.Equal(t, "high", a.Severity)
+}
+
+func TestAnomalyService_Rolling_FlagsWeeklyPeak(t *testing.T) {
+	series := weeklySeries(2)
+	monday := time.Date(2025, 10, 13, 0, 0, 0, 0, time.UTC)
+
+	anomalies := detectWithOptions(t, series, monday, monday.Add(23*time.Hour), analytics_models.AnomalyOptions{})
+
+	require.NotEmpty(t, anomalies, "without seasonality the Monday peak looks anomalous")
+	assert.Equal(t, monday.Add(9*time.Hour), anomalies[0].TimeBucket)
+	for _, a := range anomalies {
+		assert.True(t, a.TimeBucket.Hour() >= 9 && a.TimeBucket.Hour() <= 12,
+			"normal variance at %s is not flagged", a.TimeBucket)
+	}
+}
+
+func TestAnomalyService_MAD_IgnoresEarlierOutlier(t *testing.T) {
+	start := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+	var series []*analytics_models.Aggregation
+	for i := 0; i < 30; i++ {
+		value := 100 + float64(i%3-1)
+		switch i {
+		case 10:
+			value = 1000 // an outage earlier in the baseline
+		case 25:
+			value = 250
+		}
+		series = append(series, &analytics_models.Aggregation{TimeBucket: start.Add(time.Duration(i) * time.Hour), Value: value})
+	}
+	from, end := start.Add(20*time.Hour), start.Add(29*time.Hour)
+
+	zscore := detectWithOptions(t, series, from, end, analytics_models.AnomalyOptions{Method: analytics_models.AnomalyMethodZScore})
+	mad := detectWithOptions(t, series, from, end, analytics_models.AnomalyOptions{Method: analytics_models.AnomalyMethodMAD})
+
+	assert.Empty(t, zscore, "the outlier inflates the standard deviation and masks the spike")
+	assert.Equal(t, []time.Time{start.Add(25 * time.Hour)}, anomalyBuckets(mad))
+}
+
+func TestAnomalyService_SensitivityIsConfigurable(t *testing.T) {
+	start := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+	var series []*analytics_models.Aggregation
+	for i := 0; i < 12; i++ {
+		value := 100.0
+		if i == 11 {
+			value = 120 // four times the minimum spread of 5
+		}
+		series = append(series, &analytics_models.Aggregation{TimeBucket: start.Add(time.Duration(i) * time.Hour), Value: value})
+	}
+	end := start.Add(11 * time.Hour)
+
+	assert.Len(t, detectWithOptions(t, series, start, end, analytics_models.AnomalyOptions{Sensitivity: 3}), 1)
+	assert.Empty(t, detectWithOptions(t, series, start, end, analytics_models.AnomalyOptions{Sensitivity: 5}))
+}
+
+func TestNormalizeAnomalyOptions(t *testing.T) {
+	opts, err := analytics_services.NormalizeAnomalyOptions(analytics_models.AnomalyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, analytics_models.AnomalyOptions{
+		Method:        analytics_models.AnomalyMethodZScore,
+		Sensitivity:   analytics_services.DefaultAnomalySensitivity,
+		Window:        analytics_services.DefaultAnomalyWindow,
+		SeasonalWeeks: analytics_services.DefaultSeasonalWeeks,
+	}, opts)
+
+	for name, bad := range map[string]analytics_models.AnomalyOptions{
+		"unknown method":       {Method: "iforest"},
+		"negative sensitivity": {Sensitivity: -1},
+		"tiny window":          {Window: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := analytics_services.NormalizeAnomalyOptions(bad)
+			assert.ErrorIs(t, err, analytics_services.ErrInvalidAnomalyOptions)
+		})
+	}
+}
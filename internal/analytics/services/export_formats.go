@@ -0,0 +1,267 @@
+package analytics_services
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
+)
+
+// Export formats supported by StreamExport.
+const (
+	ExportFormatJSON = "json"
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+// ErrUnsupportedExportFormat is returned for export formats other than json, csv and xlsx.
+var ErrUnsupportedExportFormat = errors.New("unsupported export format")
+
+// exportFormats maps each format to its Content-Type.
+var exportFormats = map[string]string{
+	ExportFormatJSON: "application/json",
+	ExportFormatCSV:  "text/csv; charset=utf-8",
+	ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ExportContentType returns the Content-Type for an export format.
+func ExportContentType(format string) (string, error) {
+	contentType, ok := exportFormats[format]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedExportFormat, format)
+	}
+	return contentType, nil
+}
+
+// exportColumn is one exported field of Aggregation.
+type exportColumn struct {
+	header string
+	index  int
+}
+
+// aggregationColumns are derived from the json tags of Aggregation so that
+// exports follow the model as it changes.
+var aggregationColumns = func() []exportColumn {
+	t := reflect.TypeOf(analytics_models.Aggregation{})
+	columns := make([]exportColumn, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		columns = append(columns, exportColumn{header: name, index: i})
+	}
+	return columns
+}()
+
+func aggregationHeaders() []string {
+	headers := make([]string, len(aggregationColumns))
+	for i, col := range aggregationColumns {
+		headers[i] = col.header
+	}
+	return headers
+}
+
+// aggregationCell formats one field of agg. numeric reports whether the
+// value should be stored as a number in spreadsheets.
+func aggregationCell(agg *analytics_models.Aggregation, col exportColumn) (value string, numeric bool) {
+	field := reflect.ValueOf(agg).Elem().Field(col.index)
+	switch v := field.Interface().(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339), false
+	}
+	switch field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, 64), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), true
+	case reflect.String:
+		return field.String(), false
+	}
+	return fmt.Sprint(field.Interface()), false
+}
+
+// aggregationWriter writes aggregations one at a time in an export format.
+type aggregationWriter interface {
+	Write(agg *analytics_models.Aggregation) error
+	// Close writes any trailer; it does not close the underlying writer.
+	Close() error
+}
+
+func newAggregationWriter(format string, w io.Writer) (aggregationWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		return newCSVAggregationWriter(w)
+	case ExportFormatJSON:
+		return &jsonAggregationWriter{w: w}, nil
+	case ExportFormatXLSX:
+		return newXLSXAggregationWriter(w)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedExportFormat, format)
+}
+
+type csvAggregationWriter struct {
+	w   *csv.Writer
+	row []string
+}
+
+func newCSVAggregationWriter(w io.Writer) (*csvAggregationWriter, error) {
+	cw := &csvAggregationWriter{w: csv.NewWriter(w), row: make([]string, len(aggregationColumns))}
+	if err := cw.w.Write(aggregationHeaders()); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvAggregationWriter) Write(agg *analytics_models.Aggregation) error {
+	for i, col := range aggregationColumns {
+		cw.row[i], _ = aggregationCell(agg, col)
+	}
+	return cw.w.Write(cw.row)
+}
+
+func (cw *csvAggregationWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonAggregationWriter writes a JSON array one element at a time.
+type jsonAggregationWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (jw *jsonAggregationWriter) Write(agg *analytics_models.Aggregation) error {
+	sep := ","
+	if jw.count == 0 {
+		sep = "["
+	}
+	data, err := json.Marshal(agg)
+	if err != nil {
+		return err
+	}
+	jw.count++
+	if _, err := io.WriteString(jw.w, sep); err != nil {
+		return err
+	}
+	_, err = jw.w.Write(data)
+	return err
+}
+
+func (jw *jsonAggregationWriter) Close() error {
+	closing := "]\n"
+	if jw.count == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(jw.w, closing)
+	return err
+}
+
+// xlsxAggregationWriter writes a single-sheet Office Open XML workbook.
+// Rows are streamed into the zip entry for the sheet, using inline strings
+// so no shared string table has to be held in memory.
+type xlsxAggregationWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+// xlsxParts are the fixed parts of the workbook besides the sheet itself.
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Aggregations" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+func newXLSXAggregationWriter(w io.Writer) (*xlsxAggregationWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxAggregationWriter{zw: zw, sheet: bufio.NewWriter(f)}
+	_, _ = xw.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	headers := aggregationHeaders()
+	cells := make([]xlsxCell, len(headers))
+	for i, h := range headers {
+		cells[i] = xlsxCell{value: h}
+	}
+	if err := xw.writeRow(cells); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+type xlsxCell struct {
+	value   string
+	numeric bool
+}
+
+func (xw *xlsxAggregationWriter) Write(agg *analytics_models.Aggregation) error {
+	cells := make([]xlsxCell, len(aggregationColumns))
+	for i, col := range aggregationColumns {
+		cells[i].value, cells[i].numeric = aggregationCell(agg, col)
+	}
+	return xw.writeRow(cells)
+}
+
+func (xw *xlsxAggregationWriter) writeRow(cells []xlsxCell) error {
+	xw.rows++
+	fmt.Fprintf(xw.sheet, `<row r="%d">`, xw.rows)
+	for _, cell := range cells {
+		if cell.numeric {
+			fmt.Fprintf(xw.sheet, `<c><v>%s</v></c>`, cell.value)
+			continue
+		}
+		_, _ = xw.sheet.WriteString(`<c t="inlineStr"><is><t>`)
+		if err := xml.EscapeText(xw.sheet, []byte(cell.value)); err != nil {
+			return err
+		}
+		_, _ = xw.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := xw.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (xw *xlsxAggregationWriter) Close() error {
+	if _, err := xw.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
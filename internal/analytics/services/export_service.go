@@ -2,10 +2,12 @@
 package analytics_services
 
 import (
+	"bufio"
 	"context"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -170,6 +172,50 @@ func (s *ExportService) ExportData(ctx context.Context, metricType analytics_mod
 	return fmt.Errorf("unsupported file extension: %s", filePath)
 }
 
+// exportBufferSize is how much export output is held before it is written
+// out. An export that fails before filling it has written nothing.
+const exportBufferSize = 32 << 10
+
+// StreamExport writes the aggregations in a time range to w as json, csv or
+// xlsx. Rows are written as they are read from the database rather than
+// collected first, so large ranges do not build up in memory. The format is
+// checked before anything is read or written.
+func (s *ExportService) StreamExport(ctx context.Context, w io.Writer, metricType analytics_models.MetricType, service string, start, end time.Time, format string) error {
+	if _, err := ExportContentType(format); err != nil {
+		return err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"metricType": metricType,
+		"service":    service,
+		"format":     format,
+	}).Info("Streaming export")
+
+	buf := bufio.NewWriterSize(w, exportBufferSize)
+	out, err := newAggregationWriter(format, buf)
+	if err != nil {
+		return err
+	}
+
+	rows := 0
+	err = s.aggregationRepo.StreamByRange(ctx, metricType, service, start, end, func(agg *analytics_models.Aggregation) error {
+		rows++
+		return out.Write(agg)
+	})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to stream export")
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+
+	s.logger.WithField("rows", rows).Info("Export streamed successfully")
+	return nil
+}
+
 // isValidFilePath validates the file path to prevent potential file inclusion vulnerabilities.
 func isValidFilePath(filePath string) bool {
 	// Example validation: Ensure the file path is within a specific directory
@@ -1,9 +1,16 @@
 package analytics_services_test
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
 	analytics_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/services"
@@ -11,6 +18,7 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExportService_ExportData(t *testing.T) {
@@ -42,3 +50,131 @@ func TestExportService_ExportData(t *testing.T) {
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
+
+func seededExportService(t *testing.T, count int) (*analytics_services.ExportService, *testutils.MockAggregationRepository) {
+	t.Helper()
+	mockRepo := new(testutils.MockAggregationRepository)
+	logger, _ := test.NewNullLogger()
+
+	bucket := time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC)
+	aggregations := make([]*analytics_models.Aggregation, count)
+	for i := range aggregations {
+		aggregations[i] = &analytics_models.Aggregation{
+			ID:         int64(i + 1),
+			MetricType: analytics_models.ErrorFrequency,
+			Service:    "review",
+			Value:      float64(i) + 0.5,
+			TimeBucket: bucket.Add(time.Duration(i) * time.Hour),
+			CreatedAt:  bucket.Add(time.Duration(i)*time.Hour + time.Minute),
+		}
+	}
+	mockRepo.On("FindByRange", mock.Anything, analytics_models.ErrorFrequency, "review", mock.Anything, mock.Anything).
+		Return(aggregations, nil)
+	return analytics_services.NewExportService(mockRepo, logger), mockRepo
+}
+
+func streamExport(t *testing.T, service *analytics_services.ExportService, format string) ([]byte, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	err := service.StreamExport(context.Background(), &buf, analytics_models.ErrorFrequency, "review",
+		time.Time{}, time.Now(), format)
+	return buf.Bytes(), err
+}
+
+func TestExportService_StreamExport_CSV(t *testing.T) {
+	service, mockRepo := seededExportService(t, 24)
+
+	data, err := streamExport(t, service, analytics_services.ExportFormatCSV)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 25, "header row plus one row per aggregation")
+	assert.Equal(t, []string{"time_bucket", "created_at", "metric_type", "service", "value", "id"}, records[0])
+	assert.Equal(t, []string{"2025-11-13T00:00:00Z", "2025-11-13T00:01:00Z", "error_frequency", "review", "0.5", "1"}, records[1])
+	assert.Equal(t, "24", records[24][5])
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportService_StreamExport_EmptyCSVHasHeader(t *testing.T) {
+	service, _ := seededExportService(t, 0)
+
+	data, err := streamExport(t, service, analytics_services.ExportFormatCSV)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
+
+func TestExportService_StreamExport_JSON(t *testing.T) {
+	service, _ := seededExportService(t, 3)
+
+	data, err := streamExport(t, service, analytics_services.ExportFormatJSON)
+	require.NoError(t, err)
+
+	var decoded []analytics_models.Aggregation
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Len(t, decoded, 3)
+	assert.Equal(t, 2.5, decoded[2].Value)
+
+	empty, _ := seededExportService(t, 0)
+	data, err = streamExport(t, empty, analytics_services.ExportFormatJSON)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(data))
+}
+
+func TestExportService_StreamExport_XLSX(t *testing.T) {
+	service, _ := seededExportService(t, 5)
+
+	data, err := streamExport(t, service, analytics_services.ExportFormatXLSX)
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	parts := map[string]*zip.File{}
+	for _, f := range zr.File {
+		parts[f.Name] = f
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"} {
+		assert.Contains(t, parts, name)
+	}
+	require.Contains(t, parts, "xl/worksheets/sheet1.xml")
+
+	rc, err := parts["xl/worksheets/sheet1.xml"].Open()
+	require.NoError(t, err)
+	defer rc.Close()
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	require.NoError(t, xml.NewDecoder(rc).Decode(&sheet))
+
+	require.Len(t, sheet.Rows, 6, "header row plus one row per aggregation")
+	assert.Equal(t, "time_bucket", sheet.Rows[0].Cells[0].Inline)
+	assert.Equal(t, "review", sheet.Rows[1].Cells[3].Inline)
+	assert.Empty(t, sheet.Rows[1].Cells[4].Type, "values are numeric cells")
+	assert.Equal(t, "0.5", sheet.Rows[1].Cells[4].Value)
+}
+
+func TestExportService_StreamExport_Errors(t *testing.T) {
+	service, mockRepo := seededExportService(t, 3)
+
+	data, err := streamExport(t, service, "pdf")
+	assert.ErrorIs(t, err, analytics_services.ErrUnsupportedExportFormat)
+	assert.Empty(t, data)
+	mockRepo.AssertNotCalled(t, "FindByRange", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	failing := new(testutils.MockAggregationRepository)
+	failing.On("FindByRange", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("connection reset"))
+	logger, _ := test.NewNullLogger()
+	data, err = streamExport(t, analytics_services.NewExportService(failing, logger), analytics_services.ExportFormatCSV)
+	assert.Error(t, err)
+	assert.Empty(t, data, "nothing is written when the query fails")
+}
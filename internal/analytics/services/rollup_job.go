@@ -0,0 +1,106 @@
+package analytics_services
+
+import (
+	"context"
+	"time"
+
+	analytics_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/db"
+	"github.com/sirupsen/logrus"
+)
+
+// Rollup job defaults.
+const (
+	// DefaultRollupInterval is how often the rollup job runs.
+	DefaultRollupInterval = 5 * time.Minute
+	// rollupBackfillHours and rollupBackfillDays bound the first run, when
+	// there is no watermark yet.
+	rollupBackfillHours = 7 * 24
+	rollupBackfillDays  = 90
+	// rollupMaxBuckets bounds how many buckets one transaction rolls up, so a
+	// long outage is caught up in several steps.
+	rollupMaxBuckets = 24
+)
+
+// RollupJob periodically rolls raw logs up into hourly and daily buckets.
+// Only complete buckets are rolled up; the bucket in progress is left to
+// raw queries.
+type RollupJob struct {
+	repo   analytics_db.RollupRepositoryInterface
+	logger *logrus.Logger
+}
+
+// NewRollupJob creates a new instance of RollupJob.
+func NewRollupJob(repo analytics_db.RollupRepositoryInterface, logger *logrus.Logger) *RollupJob {
+	return &RollupJob{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Start runs the job every interval until ctx is canceled, beginning
+// immediately.
+func (j *RollupJob) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRollupInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.RunOnce(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			j.logger.WithError(err).Error("Log rollup failed")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce rolls up every hourly and daily bucket completed by now that is
+// after the watermark of its size.
+func (j *RollupJob) RunOnce(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	for _, size := range []string{BucketHour, BucketDay} {
+		if err := j.rollUpSize(ctx, size, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *RollupJob) rollUpSize(ctx context.Context, size string, now time.Time) error {
+	step, backfill := time.Hour, time.Duration(rollupBackfillHours)*time.Hour
+	if size == BucketDay {
+		step, backfill = 24*time.Hour, time.Duration(rollupBackfillDays)*24*time.Hour
+	}
+	current := now.Truncate(step)
+
+	from, err := j.repo.Watermark(ctx, size)
+	if err != nil {
+		return err
+	}
+	if from.IsZero() {
+		from = current.Add(-backfill)
+	}
+
+	for from.Before(current) {
+		to := from.Add(rollupMaxBuckets * step)
+		if to.After(current) {
+			to = current
+		}
+		rows, err := j.repo.RollUp(ctx, size, from, to)
+		if err != nil {
+			return err
+		}
+		j.logger.WithFields(logrus.Fields{
+			"bucket": size,
+			"from":   from,
+			"to":     to,
+			"rows":   rows,
+		}).Debug("Rolled up logs")
+		from = to
+	}
+	return nil
+}
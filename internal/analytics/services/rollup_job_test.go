@@ -0,0 +1,227 @@
+package analytics_services_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	analytics_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/models"
+	analytics_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/services"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/testutils"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rawLog struct {
+	service, level, issueType string
+	at                        time.Time
+}
+
+// memoryRollupRepo rolls up seeded raw logs in memory the way
+// RollupRepository does in SQL.
+type memoryRollupRepo struct {
+	mu         sync.Mutex
+	raw        []rawLog
+	rollups    map[string][]analytics_models.LogRollup // by bucket size
+	watermarks map[string]time.Time
+	rollUps    [][2]time.Time
+	rawQueries [][2]time.Time
+}
+
+func newMemoryRollupRepo(raw []rawLog) *memoryRollupRepo {
+	return &memoryRollupRepo{raw: raw, rollups: map[string][]analytics_models.LogRollup{}, watermarks: map[string]time.Time{}}
+}
+
+func bucketSize(size string) time.Duration {
+	if size == analytics_services.BucketDay {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+func (r *memoryRollupRepo) count(service, size string, start, end time.Time) []analytics_models.LogRollup {
+	counts := map[analytics_models.LogRollup]int64{}
+	for _, l := range r.raw {
+		if l.at.Before(start) || !l.at.Before(end) || (service != "" && l.service != service) {
+			continue
+		}
+		key := analytics_models.LogRollup{BucketSize: size, BucketStart: l.at.Truncate(bucketSize(size)),
+			Service: l.service, Level: strings.ToLower(l.level), IssueType: l.issueType}
+		counts[key]++
+	}
+	var rollups []analytics_models.LogRollup
+	for key, n := range counts {
+		key.Count = n
+		rollups = append(rollups, key)
+	}
+	sortRollups(rollups)
+	return rollups
+}
+
+func sortRollups(rollups []analytics_models.LogRollup) {
+	sort.Slice(rollups, func(i, j int) bool {
+		a, b := rollups[i], rollups[j]
+		if !a.BucketStart.Equal(b.BucketStart) {
+			return a.BucketStart.Before(b.BucketStart)
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Level != b.Level {
+			return a.Level < b.Level
+		}
+		return a.IssueType < b.IssueType
+	})
+}
+
+func (r *memoryRollupRepo) RollUp(_ context.Context, size string, start, end time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rollUps = append(r.rollUps, [2]time.Time{start, end})
+
+	kept := r.rollups[size][:0]
+	for _, rollup := range r.rollups[size] {
+		if rollup.BucketStart.Before(start) || !rollup.BucketStart.Before(end) {
+			kept = append(kept, rollup)
+		}
+	}
+	fresh := r.count("", size, start, end)
+	r.rollups[size] = append(kept, fresh...)
+	if end.After(r.watermarks[size]) {
+		r.watermarks[size] = end
+	}
+	return int64(len(fresh)), nil
+}
+
+func (r *memoryRollupRepo) Watermark(_ context.Context, size string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.watermarks[size], nil
+}
+
+func (r *memoryRollupRepo) FindRollups(_ context.Context, service, size string, start, end time.Time) ([]analytics_models.LogRollup, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []analytics_models.LogRollup
+	for _, rollup := range r.rollups[size] {
+		if rollup.Service == service && !rollup.BucketStart.Before(start) && rollup.BucketStart.Before(end) {
+			found = append(found, rollup)
+		}
+	}
+	sortRollups(found)
+	return found, nil
+}
+
+func (r *memoryRollupRepo) CountRaw(_ context.Context, service, size string, start, end time.Time) ([]analytics_models.LogRollup, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rawQueries = append(r.rawQueries, [2]time.Time{start, end})
+	return r.count(service, size, start, end), nil
+}
+
+var rollupDay = time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC)
+
+// seededRawLogs has review errors in hours 0-2 of rollupDay and the current
+// hour 3, plus one portal warning.
+func seededRawLogs() []rawLog {
+	var logs []rawLog
+	add := func(n int, service, level, issueType string, at time.Time) {
+		for i := 0; i < n; i++ {
+			logs = append(logs, rawLog{service, level, issueType, at.Add(time.Duration(i) * time.Minute)})
+		}
+	}
+	add(3, "review", "error", "db_connection", rollupDay.Add(10*time.Minute))
+	add(2, "review", "ERROR", "", rollupDay.Add(30*time.Minute))
+	add(4, "review", "error", "db_connection", rollupDay.Add(time.Hour+5*time.Minute))
+	add(1, "portal", "warn", "", rollupDay.Add(2*time.Hour))
+	add(5, "review", "error", "rate_limit", rollupDay.Add(3*time.Hour+time.Minute)) // current hour
+	return logs
+}
+
+func rollupCounts(rollups []analytics_models.LogRollup) map[string]int64 {
+	counts := map[string]int64{}
+	for _, r := range rollups {
+		counts[r.BucketStart.Format("15:04")+" "+r.Service+" "+r.Level+" "+r.IssueType] += r.Count
+	}
+	return counts
+}
+
+func TestRollupJob_RollsUpCompleteHourlyBuckets(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	repo := newMemoryRollupRepo(seededRawLogs())
+	repo.watermarks[analytics_services.BucketHour] = rollupDay
+	repo.watermarks[analytics_services.BucketDay] = rollupDay
+	job := analytics_services.NewRollupJob(repo, logger)
+	now := rollupDay.Add(3*time.Hour + 20*time.Minute)
+
+	require.NoError(t, job.RunOnce(context.Background(), now))
+
+	assert.Equal(t, map[string]int64{
+		"00:00 review error db_connection": 3,
+		"00:00 review error ":              2,
+		"01:00 review error db_connection": 4,
+		"02:00 portal warn ":               1,
+	}, rollupCounts(repo.rollups[analytics_services.BucketHour]), "the hour in progress is not rolled up")
+	assert.Equal(t, rollupDay.Add(3*time.Hour), repo.watermarks[analytics_services.BucketHour])
+	assert.Empty(t, repo.rollups[analytics_services.BucketDay], "the day is not complete yet")
+
+	// A log arriving late for a rolled-up hour is picked up on the next day's run
+	repo.raw = append(repo.raw, rawLog{"review", "error", "db_connection", rollupDay.Add(time.Hour + 50*time.Minute)})
+	require.NoError(t, job.RunOnce(context.Background(), rollupDay.Add(24*time.Hour+time.Minute)))
+
+	assert.Equal(t, map[string]int64{
+		"00:00 review error db_connection": 8,
+		"00:00 review error ":              2,
+		"00:00 review error rate_limit":    5,
+		"00:00 portal warn ":               1,
+	}, rollupCounts(repo.rollups[analytics_services.BucketDay]))
+	assert.Equal(t, rollupDay.Add(24*time.Hour), repo.watermarks[analytics_services.BucketDay])
+}
+
+func TestRollupJob_BackfillsInChunks(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	repo := newMemoryRollupRepo(nil)
+	job := analytics_services.NewRollupJob(repo, logger)
+
+	require.NoError(t, job.RunOnce(context.Background(), rollupDay.Add(30*time.Minute)))
+
+	// 7 days of hours in chunks of 24 hours, then 90 days in chunks of 24 days
+	require.Len(t, repo.rollUps, 7+4)
+	for _, window := range repo.rollUps[:7] {
+		assert.Equal(t, 24*time.Hour, window[1].Sub(window[0]))
+	}
+	assert.Equal(t, rollupDay.Add(-90*24*time.Hour), repo.rollUps[7][0])
+	assert.Equal(t, rollupDay, repo.watermarks[analytics_services.BucketHour])
+	assert.Equal(t, rollupDay, repo.watermarks[analytics_services.BucketDay])
+}
+
+func TestAggregatorService_GetLogCounts_RawOnlyAfterWatermark(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	repo := newMemoryRollupRepo(seededRawLogs())
+	repo.watermarks[analytics_services.BucketHour] = rollupDay
+	job := analytics_services.NewRollupJob(repo, logger)
+	require.NoError(t, job.RunOnce(context.Background(), rollupDay.Add(3*time.Hour+20*time.Minute)))
+
+	service := analytics_services.NewAggregatorService(new(testutils.MockAggregationRepository), new(testutils.MockLogReader), logger)
+	_, err := service.GetLogCounts(context.Background(), "review", "", rollupDay, rollupDay.Add(4*time.Hour))
+	assert.ErrorIs(t, err, analytics_services.ErrRollupsNotConfigured)
+
+	service.SetRollupRepository(repo)
+	repo.rawQueries = nil
+	counts, err := service.GetLogCounts(context.Background(), "review", analytics_services.BucketHour,
+		rollupDay, rollupDay.Add(3*time.Hour+20*time.Minute))
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		"00:00 review error db_connection": 3,
+		"00:00 review error ":              2,
+		"01:00 review error db_connection": 4,
+		"03:00 review error rate_limit":    5,
+	}, rollupCounts(counts))
+	assert.Equal(t, [][2]time.Time{{rollupDay.Add(3 * time.Hour), rollupDay.Add(4 * time.Hour)}}, repo.rawQueries,
+		"only the bucket in progress is counted from raw logs")
+}
@@ -29,6 +29,21 @@ func (m *MockAggregationRepository) FindByRange(ctx context.Context, metricType
 	return aggregations, args.Error(1)
 }
 
+// StreamByRange calls fn for each aggregation the FindByRange expectation returns.
+// It simulates the behavior of the actual repository method.
+func (m *MockAggregationRepository) StreamByRange(ctx context.Context, metricType analytics_models.MetricType, service string, start, end time.Time, fn func(*analytics_models.Aggregation) error) error {
+	aggregations, err := m.FindByRange(ctx, metricType, service, start, end)
+	if err != nil {
+		return err
+	}
+	for _, agg := range aggregations {
+		if err := fn(agg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // FindTopIssues retrieves the top issues based on the specified criteria.
 func (m *MockAggregationRepository) FindTopIssues(ctx context.Context, metricType analytics_models.MetricType, service string, start, end time.Time, limit int) ([]*analytics_models.Aggregation, error) {
 	args := m.Called(ctx, metricType, service, start, end, limit)
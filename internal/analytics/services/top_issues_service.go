@@ -3,6 +3,8 @@ package analytics_services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	analytics_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/db"
@@ -10,6 +12,18 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// DefaultTopIssuesLimit is the page size when none is requested.
+	DefaultTopIssuesLimit = 20
+	// MaxTopIssuesLimit caps the page size of a top issues query.
+	MaxTopIssuesLimit = 200
+	// DefaultTopIssuesWindow is the time range used when no start is given.
+	DefaultTopIssuesWindow = 24 * time.Hour
+)
+
+// ErrInvalidTopIssuesQuery is returned for top issues queries that cannot be run.
+var ErrInvalidTopIssuesQuery = errors.New("invalid top issues query")
+
 // TopIssuesService provides methods to retrieve top issues.
 type TopIssuesService struct {
 	logReader analytics_db.LogReaderInterface
@@ -107,3 +121,76 @@ func (s *TopIssuesService) GetTopIssues(ctx context.Context, service, level stri
 	s.logger.WithField("count", len(issues)).Info("Top issues fetched successfully")
 	return issues, nil
 }
+
+// QueryTopIssues returns one page of issue groups matching q along with the
+// total number of matching groups. Missing values are defaulted: the range
+// ends now and covers DefaultTopIssuesWindow, the page holds
+// DefaultTopIssuesLimit groups and issues are ordered by count.
+func (s *TopIssuesService) QueryTopIssues(ctx context.Context, q analytics_models.TopIssuesQuery) (*analytics_models.TopIssuesResponse, error) {
+	q, err := normalizeTopIssuesQuery(q, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	issues, total, err := s.logReader.FindTopIssues(ctx, q)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to query top issues")
+		return nil, err
+	}
+	if issues == nil {
+		issues = []analytics_models.IssueItem{}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"service": q.Service,
+		"level":   q.Level,
+		"order":   q.OrderBy,
+		"offset":  q.Offset,
+		"count":   len(issues),
+		"total":   total,
+	}).Debug("Top issues queried")
+
+	return &analytics_models.TopIssuesResponse{
+		TimeRange: analytics_models.TimeRange{Start: q.Start, End: q.End},
+		Issues:    issues,
+		Total:     total,
+		Limit:     q.Limit,
+		Offset:    q.Offset,
+	}, nil
+}
+
+// normalizeTopIssuesQuery applies defaults to q and validates it.
+func normalizeTopIssuesQuery(q analytics_models.TopIssuesQuery, now time.Time) (analytics_models.TopIssuesQuery, error) {
+	if q.End.IsZero() {
+		q.End = now
+	}
+	if q.Start.IsZero() {
+		q.Start = q.End.Add(-DefaultTopIssuesWindow)
+	}
+	if !q.Start.Before(q.End) {
+		return q, fmt.Errorf("%w: start must be before end", ErrInvalidTopIssuesQuery)
+	}
+
+	switch q.OrderBy {
+	case "":
+		q.OrderBy = analytics_models.IssueOrderCount
+	case analytics_models.IssueOrderCount, analytics_models.IssueOrderRecent:
+	default:
+		return q, fmt.Errorf("%w: order must be %q or %q", ErrInvalidTopIssuesQuery,
+			analytics_models.IssueOrderCount, analytics_models.IssueOrderRecent)
+	}
+
+	if q.Limit < 0 || q.Offset < 0 || q.MinCount < 0 {
+		return q, fmt.Errorf("%w: limit, offset and min_count must not be negative", ErrInvalidTopIssuesQuery)
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultTopIssuesLimit
+	}
+	if q.Limit > MaxTopIssuesLimit {
+		q.Limit = MaxTopIssuesLimit
+	}
+	if q.MinCount == 0 {
+		q.MinCount = 1
+	}
+	return q, nil
+}
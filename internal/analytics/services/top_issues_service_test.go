@@ -75,3 +75,69 @@ func TestTopIssuesService_GetTopIssues(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestTopIssuesService_QueryTopIssues_Defaults(t *testing.T) {
+	mockRepo := &testutils.MockLogReader{}
+	logger, _ := test.NewNullLogger()
+	service := analytics_services.NewTopIssuesService(mockRepo, logger)
+
+	issues := []analytics_models.IssueItem{{Service: "review", Level: "error", Message: "model timeout", Count: 12}}
+	mockRepo.On("FindTopIssues", mock.Anything, mock.MatchedBy(func(q analytics_models.TopIssuesQuery) bool {
+		return q.Service == "review" &&
+			q.OrderBy == analytics_models.IssueOrderCount &&
+			q.Limit == analytics_services.DefaultTopIssuesLimit &&
+			q.MinCount == 1 &&
+			q.End.Sub(q.Start) == analytics_services.DefaultTopIssuesWindow
+	})).Return(issues, 37, nil)
+
+	resp, err := service.QueryTopIssues(context.Background(), analytics_models.TopIssuesQuery{Service: "review"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, issues, resp.Issues)
+	assert.Equal(t, 37, resp.Total)
+	assert.Equal(t, analytics_services.DefaultTopIssuesLimit, resp.Limit)
+	assert.Equal(t, 0, resp.Offset)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTopIssuesService_QueryTopIssues_CapsLimit(t *testing.T) {
+	mockRepo := &testutils.MockLogReader{}
+	logger, _ := test.NewNullLogger()
+	service := analytics_services.NewTopIssuesService(mockRepo, logger)
+
+	mockRepo.On("FindTopIssues", mock.Anything, mock.MatchedBy(func(q analytics_models.TopIssuesQuery) bool {
+		return q.Limit == analytics_services.MaxTopIssuesLimit && q.Offset == 400 &&
+			q.OrderBy == analytics_models.IssueOrderRecent
+	})).Return(nil, 410, nil)
+
+	resp, err := service.QueryTopIssues(context.Background(), analytics_models.TopIssuesQuery{
+		Limit: 1000, Offset: 400, OrderBy: analytics_models.IssueOrderRecent,
+	})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp.Issues, "an empty page serializes as []")
+	assert.Empty(t, resp.Issues)
+	assert.Equal(t, 410, resp.Total)
+}
+
+func TestTopIssuesService_QueryTopIssues_Invalid(t *testing.T) {
+	now := time.Now()
+	tests := map[string]analytics_models.TopIssuesQuery{
+		"unknown order":    {OrderBy: "severity"},
+		"end before start": {Start: now, End: now.Add(-time.Hour)},
+		"negative offset":  {Offset: -1},
+		"negative limit":   {Limit: -5},
+	}
+	for name, q := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := &testutils.MockLogReader{}
+			logger, _ := test.NewNullLogger()
+			service := analytics_services.NewTopIssuesService(mockRepo, logger)
+
+			_, err := service.QueryTopIssues(context.Background(), q)
+
+			assert.ErrorIs(t, err, analytics_services.ErrInvalidTopIssuesQuery)
+			mockRepo.AssertNotCalled(t, "FindTopIssues", mock.Anything, mock.Anything)
+		})
+	}
+}
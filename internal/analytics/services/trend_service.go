@@ -2,6 +2,9 @@ package analytics_services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"time"
 
 	analytics_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/analytics/db"
@@ -9,6 +12,26 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Forecast limits and defaults, in days.
+const (
+	DefaultForecastHorizon = 7
+	MaxForecastHorizon     = 90
+	DefaultForecastHistory = 28
+	MaxForecastHistory     = 365
+	// MinForecastHistory is the fewest days of history a forecast is fitted on.
+	MinForecastHistory = 7
+)
+
+// forecastZ is the normal quantile for a 95% prediction interval.
+const forecastZ = 1.96
+
+var (
+	// ErrInsufficientHistory is returned when a series is too short to forecast.
+	ErrInsufficientHistory = errors.New("insufficient history")
+	// ErrInvalidForecast is returned for horizons or history lengths out of range.
+	ErrInvalidForecast = errors.New("invalid forecast request")
+)
+
 // TrendService provides methods to analyze trends.
 type TrendService struct {
 	aggregationRepo analytics_db.AggregationRepositoryInterface
@@ -109,3 +132,129 @@ func (s *TrendService) GetTrends(ctx context.Context, metricType analytics_model
 	s.logger.WithField("count", len(aggregations)).Info("Trends fetched successfully")
 	return response, nil
 }
+
+// ForecastTrend fits a least-squares line to the daily totals of a metric
+// over the historyDays full days before asOf and projects it horizon days
+// ahead with a 95% prediction interval. Days without aggregations count as
+// zero once the series has started. It returns ErrInsufficientHistory when
+// fewer than MinForecastHistory days are available.
+func (s *TrendService) ForecastTrend(ctx context.Context, metricType analytics_models.MetricType, service string, asOf time.Time, horizon, historyDays int) (*analytics_models.TrendForecast, error) {
+	if horizon < 1 || horizon > MaxForecastHorizon {
+		return nil, fmt.Errorf("%w: horizon must be between 1 and %d days", ErrInvalidForecast, MaxForecastHorizon)
+	}
+	if historyDays < MinForecastHistory || historyDays > MaxForecastHistory {
+		return nil, fmt.Errorf("%w: history must be between %d and %d days", ErrInvalidForecast, MinForecastHistory, MaxForecastHistory)
+	}
+
+	// Only complete days are used; today is still accumulating
+	today := asOf.UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -historyDays)
+	aggregations, err := s.aggregationRepo.FindByRange(ctx, metricType, service, from, today.Add(-time.Nanosecond))
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to retrieve aggregations")
+		return nil, err
+	}
+
+	daily := dailyTotals(aggregations, from, today)
+	if len(daily) < MinForecastHistory {
+		return nil, fmt.Errorf("%w: need %d days of data, have %d", ErrInsufficientHistory, MinForecastHistory, len(daily))
+	}
+
+	fit := fitLine(daily)
+	forecast := &analytics_models.TrendForecast{
+		GeneratedAt: asOf,
+		MetricType:  metricType,
+		Service:     service,
+		Horizon:     horizon,
+		HistoryDays: len(daily),
+		Slope:       fit.slope,
+		Intercept:   fit.intercept,
+		RSquared:    fit.rSquared,
+		Points:      make([]analytics_models.ForecastPoint, 0, horizon),
+	}
+	for d := 0; d < horizon; d++ {
+		x := float64(len(daily) + d)
+		predicted := fit.intercept + fit.slope*x
+		margin := forecastZ * fit.predictionError(x)
+		forecast.Points = append(forecast.Points, analytics_models.ForecastPoint{
+			Date:      today.AddDate(0, 0, d),
+			Predicted: math.Max(predicted, 0),
+			Lower:     math.Max(predicted-margin, 0),
+			Upper:     math.Max(predicted+margin, 0),
+		})
+		forecast.ProjectedTotal += math.Max(predicted, 0)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"metricType":     metricType,
+		"service":        service,
+		"historyDays":    len(daily),
+		"slope":          fit.slope,
+		"projectedTotal": forecast.ProjectedTotal,
+	}).Info("Trend forecast completed")
+	return forecast, nil
+}
+
+// dailyTotals sums aggregations per UTC day in [from, to), starting at the
+// first day with data and filling later gaps with zero.
+func dailyTotals(aggregations []*analytics_models.Aggregation, from, to time.Time) []float64 {
+	days := int(to.Sub(from) / (24 * time.Hour))
+	totals := make([]float64, days)
+	first := days
+	for _, agg := range aggregations {
+		d := int(agg.TimeBucket.UTC().Sub(from) / (24 * time.Hour))
+		if d < 0 || d >= days {
+			continue
+		}
+		totals[d] += agg.Value
+		if d < first {
+			first = d
+		}
+	}
+	return totals[first:]
+}
+
+// lineFit is an ordinary least-squares fit of y against x = 0, 1, 2, ...
+type lineFit struct {
+	slope, intercept, rSquared float64
+	n                          float64
+	meanX, sxx                 float64
+	residualStdErr             float64
+}
+
+func fitLine(ys []float64) lineFit {
+	n := float64(len(ys))
+	fit := lineFit{n: n, meanX: (n - 1) / 2}
+
+	var meanY float64
+	for _, y := range ys {
+		meanY += y
+	}
+	meanY /= n
+
+	var sxy, syy float64
+	for i, y := range ys {
+		dx, dy := float64(i)-fit.meanX, y-meanY
+		fit.sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	fit.slope = sxy / fit.sxx
+	fit.intercept = meanY - fit.slope*fit.meanX
+
+	var sse float64
+	for i, y := range ys {
+		r := y - (fit.intercept + fit.slope*float64(i))
+		sse += r * r
+	}
+	if syy > 0 {
+		fit.rSquared = 1 - sse/syy
+	}
+	fit.residualStdErr = math.Sqrt(sse / (n - 2))
+	return fit
+}
+
+// predictionError is the standard error of a new observation at x.
+func (f lineFit) predictionError(x float64) float64 {
+	return f.residualStdErr * math.Sqrt(1+1/f.n+(x-f.meanX)*(x-f.meanX)/f.sxx)
+}
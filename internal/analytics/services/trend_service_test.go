@@ -11,6 +11,7 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTrendService_AnalyzeTrends(t *testing.T) {
@@ -91,3 +92,102 @@ func TestTrendService_GetTrends(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+// risingSeries returns hourly aggregations for the days before asOf whose
+// daily totals grow by 10 a day from 100, with alternating noise of ±3.
+func risingSeries(asOf time.Time, days int) []*analytics_models.Aggregation {
+	first := asOf.Truncate(24*time.Hour).AddDate(0, 0, -days)
+	var series []*analytics_models.Aggregation
+	for d := 0; d < days; d++ {
+		total := 100 + 10*float64(d)
+		if d%2 == 0 {
+			total += 3
+		} else {
+			total -= 3
+		}
+		for h := 0; h < 24; h++ {
+			series = append(series, &analytics_models.Aggregation{
+				MetricType: analytics_models.ErrorFrequency,
+				Service:    "review",
+				TimeBucket: first.AddDate(0, 0, d).Add(time.Duration(h) * time.Hour),
+				Value:      total / 24,
+			})
+		}
+	}
+	return series
+}
+
+func forecastService(series []*analytics_models.Aggregation) *analytics_services.TrendService {
+	logger, _ := test.NewNullLogger()
+	mockAggRepo := &testutils.MockAggregationRepository{}
+	mockAggRepo.On("FindByRange", mock.Anything, analytics_models.ErrorFrequency, "review", mock.Anything, mock.Anything).Return(series, nil)
+	return analytics_services.NewTrendService(mockAggRepo, logger)
+}
+
+func TestTrendService_ForecastTrend_RisingSeries(t *testing.T) {
+	asOf := time.Date(2025, 11, 13, 15, 30, 0, 0, time.UTC)
+	service := forecastService(risingSeries(asOf, 28))
+
+	forecast, err := service.ForecastTrend(context.Background(), analytics_models.ErrorFrequency, "review", asOf, 7, 28)
+
+	require.NoError(t, err)
+	assert.Equal(t, 28, forecast.HistoryDays)
+	assert.InDelta(t, 10, forecast.Slope, 0.5, "slope is the daily growth")
+	assert.Greater(t, forecast.RSquared, 0.95)
+	require.Len(t, forecast.Points, 7)
+
+	today := time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC)
+	var total float64
+	for i, p := range forecast.Points {
+		assert.Equal(t, today.AddDate(0, 0, i), p.Date)
+		assert.InDelta(t, 100+10*float64(28+i), p.Predicted, 5)
+		assert.Less(t, p.Lower, p.Predicted)
+		assert.Greater(t, p.Upper, p.Predicted)
+		if i > 0 {
+			assert.Greater(t, p.Predicted, forecast.Points[i-1].Predicted)
+			assert.Greater(t, p.Upper-p.Lower, forecast.Points[i-1].Upper-forecast.Points[i-1].Lower,
+				"the band widens further from the data")
+		}
+		total += p.Predicted
+	}
+	assert.InDelta(t, total, forecast.ProjectedTotal, 1e-9)
+}
+
+func TestTrendService_ForecastTrend_InsufficientHistory(t *testing.T) {
+	asOf := time.Date(2025, 11, 13, 9, 0, 0, 0, time.UTC)
+	service := forecastService(risingSeries(asOf, 4))
+
+	forecast, err := service.ForecastTrend(context.Background(), analytics_models.ErrorFrequency, "review", asOf, 7, 28)
+
+	assert.Nil(t, forecast)
+	assert.ErrorIs(t, err, analytics_services.ErrInsufficientHistory)
+	assert.Contains(t, err.Error(), "have 4")
+}
+
+func TestTrendService_ForecastTrend_QuietDaysCountAsZero(t *testing.T) {
+	asOf := time.Date(2025, 11, 13, 9, 0, 0, 0, time.UTC)
+	series := risingSeries(asOf, 10)
+	// Drop days 3 and 4: no errors were logged
+	var gappy []*analytics_models.Aggregation
+	for _, agg := range series {
+		day := int(agg.TimeBucket.Sub(series[0].TimeBucket) / (24 * time.Hour))
+		if day != 3 && day != 4 {
+			gappy = append(gappy, agg)
+		}
+	}
+	service := forecastService(gappy)
+
+	forecast, err := service.ForecastTrend(context.Background(), analytics_models.ErrorFrequency, "review", asOf, 3, 28)
+
+	require.NoError(t, err)
+	assert.Equal(t, 10, forecast.HistoryDays, "history starts at the first day with data")
+	assert.Greater(t, forecast.Slope, 10.0, "the quiet days pull early values down")
+}
+
+func TestTrendService_ForecastTrend_InvalidRange(t *testing.T) {
+	service := forecastService(nil)
+	for _, tc := range []struct{ horizon, history int }{{0, 28}, {91, 28}, {7, 3}, {7, 400}} {
+		_, err := service.ForecastTrend(context.Background(), analytics_models.ErrorFrequency, "review", time.Now(), tc.horizon, tc.history)
+		assert.ErrorIs(t, err, analytics_services.ErrInvalidForecast)
+	}
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAIRetry is used for AI retry settings that are not set in the
+// environment
+var DefaultAIRetry = AIRetryConfig{
+	MaxAttempts:  3,
+	InitialDelay: 500 * time.Millisecond,
+	MaxDelay:     5 * time.Second,
+}
+
+// Accepted ranges for the AI retry settings
+const (
+	MaxAIRetryAttempts = 10
+	MaxAIRetryDelay    = time.Minute
+)
+
+// AIRetryConfig tunes retries of transient AI failures (429, 5xx, network)
+type AIRetryConfig struct {
+	MaxAttempts  int           // Total attempts per call; 1 disables retries
+	InitialDelay time.Duration // Backoff before the second attempt, doubled after each failure
+	MaxDelay     time.Duration // Cap on a single backoff
+}
+
+// LoadAIRetryConfig reads AI_RETRY_MAX_ATTEMPTS, AI_RETRY_INITIAL_DELAY and
+// AI_RETRY_MAX_DELAY, falling back to DefaultAIRetry for unset variables.
+// Delays are durations ("250ms", "2s") or a number of seconds.
+func LoadAIRetryConfig() (AIRetryConfig, error) {
+	cfg := DefaultAIRetry
+
+	if v := strings.TrimSpace(os.Getenv("AI_RETRY_MAX_ATTEMPTS")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxAIRetryAttempts {
+			return AIRetryConfig{}, fmt.Errorf("invalid AI_RETRY_MAX_ATTEMPTS %q: must be 1-%d", v, MaxAIRetryAttempts)
+		}
+		cfg.MaxAttempts = n
+	}
+	if v := strings.TrimSpace(os.Getenv("AI_RETRY_INITIAL_DELAY")); v != "" {
+		delay, err := parseSecondsOrDuration(v)
+		if err != nil || delay <= 0 || delay > MaxAIRetryDelay {
+			return AIRetryConfig{}, fmt.Errorf("invalid AI_RETRY_INITIAL_DELAY %q: must be positive and at most %s", v, MaxAIRetryDelay)
+		}
+		cfg.InitialDelay = delay
+	}
+	if v := strings.TrimSpace(os.Getenv("AI_RETRY_MAX_DELAY")); v != "" {
+		delay, err := parseSecondsOrDuration(v)
+		if err != nil || delay <= 0 || delay > MaxAIRetryDelay {
+			return AIRetryConfig{}, fmt.Errorf("invalid AI_RETRY_MAX_DELAY %q: must be positive and at most %s", v, MaxAIRetryDelay)
+		}
+		cfg.MaxDelay = delay
+	}
+	if cfg.MaxDelay < cfg.InitialDelay {
+		return AIRetryConfig{}, fmt.Errorf("AI_RETRY_MAX_DELAY (%s) is shorter than AI_RETRY_INITIAL_DELAY (%s)", cfg.MaxDelay, cfg.InitialDelay)
+	}
+
+	return cfg, nil
+}
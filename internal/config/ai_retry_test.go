@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAIRetryConfig(t *testing.T) {
+	t.Setenv("AI_RETRY_MAX_ATTEMPTS", "")
+	t.Setenv("AI_RETRY_INITIAL_DELAY", "")
+	t.Setenv("AI_RETRY_MAX_DELAY", "")
+	cfg, err := LoadAIRetryConfig()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultAIRetry, cfg)
+
+	t.Setenv("AI_RETRY_MAX_ATTEMPTS", "1")
+	t.Setenv("AI_RETRY_INITIAL_DELAY", "250ms")
+	t.Setenv("AI_RETRY_MAX_DELAY", "2")
+	cfg, err = LoadAIRetryConfig()
+	require.NoError(t, err)
+	assert.Equal(t, AIRetryConfig{MaxAttempts: 1, InitialDelay: 250 * time.Millisecond, MaxDelay: 2 * time.Second}, cfg)
+}
+
+func TestLoadAIRetryConfig_RejectsInvalid(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{"AI_RETRY_MAX_ATTEMPTS", "0"},
+		{"AI_RETRY_MAX_ATTEMPTS", "11"},
+		{"AI_RETRY_MAX_ATTEMPTS", "three"},
+		{"AI_RETRY_INITIAL_DELAY", "0"},
+		{"AI_RETRY_INITIAL_DELAY", "2m"},
+		{"AI_RETRY_MAX_DELAY", "-1s"},
+		{"AI_RETRY_MAX_DELAY", "100ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			t.Setenv("AI_RETRY_MAX_ATTEMPTS", "")
+			t.Setenv("AI_RETRY_INITIAL_DELAY", "")
+			t.Setenv("AI_RETRY_MAX_DELAY", "")
+			t.Setenv(tt.key, tt.value)
+			_, err := LoadAIRetryConfig()
+			require.Error(t, err)
+		})
+	}
+}
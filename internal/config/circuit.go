@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCircuitBreaker is used for AI circuit breaker settings that are not
+// set in the environment
+var DefaultCircuitBreaker = CircuitBreakerConfig{
+	Threshold:   5,
+	Timeout:     60 * time.Second,
+	HalfOpenMax: 3,
+}
+
+// Accepted ranges for the AI circuit breaker settings
+const (
+	MaxCircuitThreshold   = 100
+	MaxCircuitHalfOpenMax = 100
+	MinCircuitTimeout     = time.Second
+	MaxCircuitTimeout     = time.Hour
+)
+
+// CircuitBreakerConfig tunes the breaker that guards AI calls
+type CircuitBreakerConfig struct {
+	Threshold   uint32        // Consecutive failures that open the breaker
+	Timeout     time.Duration // How long it stays open before probing
+	HalfOpenMax uint32        // Probe requests allowed while half-open; that many successes close it
+}
+
+// LoadCircuitBreakerConfig reads AI_CIRCUIT_THRESHOLD, AI_CIRCUIT_TIMEOUT
+// and AI_CIRCUIT_HALFOPEN_MAX, falling back to DefaultCircuitBreaker for
+// unset variables. The timeout is a duration ("90s", "2m") or a number of
+// seconds. Values outside the accepted ranges are errors, so a typo fails
+// startup instead of silently disabling the breaker.
+func LoadCircuitBreakerConfig() (CircuitBreakerConfig, error) {
+	cfg := DefaultCircuitBreaker
+
+	if v := strings.TrimSpace(os.Getenv("AI_CIRCUIT_THRESHOLD")); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || n < 1 || n > MaxCircuitThreshold {
+			return CircuitBreakerConfig{}, fmt.Errorf("invalid AI_CIRCUIT_THRESHOLD %q: must be 1-%d", v, MaxCircuitThreshold)
+		}
+		cfg.Threshold = uint32(n)
+	}
+	if v := strings.TrimSpace(os.Getenv("AI_CIRCUIT_TIMEOUT")); v != "" {
+		timeout, err := parseSecondsOrDuration(v)
+		if err != nil || timeout < MinCircuitTimeout || timeout > MaxCircuitTimeout {
+			return CircuitBreakerConfig{}, fmt.Errorf("invalid AI_CIRCUIT_TIMEOUT %q: must be between %s and %s", v, MinCircuitTimeout, MaxCircuitTimeout)
+		}
+		cfg.Timeout = timeout
+	}
+	if v := strings.TrimSpace(os.Getenv("AI_CIRCUIT_HALFOPEN_MAX")); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || n < 1 || n > MaxCircuitHalfOpenMax {
+			return CircuitBreakerConfig{}, fmt.Errorf("invalid AI_CIRCUIT_HALFOPEN_MAX %q: must be 1-%d", v, MaxCircuitHalfOpenMax)
+		}
+		cfg.HalfOpenMax = uint32(n)
+	}
+
+	return cfg, nil
+}
+
+func parseSecondsOrDuration(v string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(v); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(v)
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCircuitBreakerConfig(t *testing.T) {
+	t.Setenv("AI_CIRCUIT_THRESHOLD", "")
+	t.Setenv("AI_CIRCUIT_TIMEOUT", "")
+	t.Setenv("AI_CIRCUIT_HALFOPEN_MAX", "")
+	cfg, err := LoadCircuitBreakerConfig()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCircuitBreaker, cfg)
+
+	t.Setenv("AI_CIRCUIT_THRESHOLD", "2")
+	t.Setenv("AI_CIRCUIT_TIMEOUT", "90")
+	t.Setenv("AI_CIRCUIT_HALFOPEN_MAX", "1")
+	cfg, err = LoadCircuitBreakerConfig()
+	require.NoError(t, err)
+	assert.Equal(t, CircuitBreakerConfig{Threshold: 2, Timeout: 90 * time.Second, HalfOpenMax: 1}, cfg)
+
+	t.Setenv("AI_CIRCUIT_TIMEOUT", "2m")
+	cfg, err = LoadCircuitBreakerConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, cfg.Timeout)
+}
+
+func TestLoadCircuitBreakerConfig_RejectsOutOfRange(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{"AI_CIRCUIT_THRESHOLD", "0"},
+		{"AI_CIRCUIT_THRESHOLD", "101"},
+		{"AI_CIRCUIT_THRESHOLD", "-1"},
+		{"AI_CIRCUIT_TIMEOUT", "500ms"},
+		{"AI_CIRCUIT_TIMEOUT", "2h"},
+		{"AI_CIRCUIT_TIMEOUT", "soon"},
+		{"AI_CIRCUIT_HALFOPEN_MAX", "0"},
+		{"AI_CIRCUIT_HALFOPEN_MAX", "many"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			t.Setenv("AI_CIRCUIT_THRESHOLD", "")
+			t.Setenv("AI_CIRCUIT_TIMEOUT", "")
+			t.Setenv("AI_CIRCUIT_HALFOPEN_MAX", "")
+			t.Setenv(tt.key, tt.value)
+
+			_, err := LoadCircuitBreakerConfig()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.key)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults for CORS settings not set in the environment
+var (
+	defaultCORSMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	defaultCORSHeaders = []string{"Content-Type", "Authorization", "X-CSRF-Token", "X-Request-ID"}
+)
+
+// defaultCORSMaxAge is how long browsers may cache a preflight response
+const defaultCORSMaxAge = 10 * time.Minute
+
+// CORSConfig is the cross-origin policy shared by every service
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	MaxAge           time.Duration
+	AllowCredentials bool
+}
+
+// LoadCORSConfig reads the CORS policy from comma-separated environment
+// variables:
+//
+//	CORS_ALLOWED_ORIGINS   (default: the gateway URL)
+//	CORS_ALLOWED_METHODS   (default: GET, POST, PUT, PATCH, DELETE, OPTIONS)
+//	CORS_ALLOWED_HEADERS   (default: Content-Type, Authorization, X-CSRF-Token, X-Request-ID)
+//	CORS_ALLOW_CREDENTIALS (default: true)
+//	CORS_MAX_AGE_SECONDS   (default: 600)
+//
+// The "*" origin is only accepted with credentials disabled, since browsers
+// refuse credentialed responses for a wildcard origin and reflecting every
+// origin instead would let any site make authenticated calls.
+func LoadCORSConfig() (CORSConfig, error) {
+	cfg := CORSConfig{
+		AllowedOrigins:   splitList(os.Getenv("CORS_ALLOWED_ORIGINS")),
+		AllowedMethods:   splitList(os.Getenv("CORS_ALLOWED_METHODS")),
+		AllowedHeaders:   splitList(os.Getenv("CORS_ALLOWED_HEADERS")),
+		AllowCredentials: true,
+		MaxAge:           defaultCORSMaxAge,
+	}
+	if len(cfg.AllowedOrigins) == 0 {
+		cfg.AllowedOrigins = []string{GetGatewayURL()}
+	}
+	if len(cfg.AllowedMethods) == 0 {
+		cfg.AllowedMethods = defaultCORSMethods
+	}
+	if len(cfg.AllowedHeaders) == 0 {
+		cfg.AllowedHeaders = defaultCORSHeaders
+	}
+
+	if v := strings.TrimSpace(os.Getenv("CORS_ALLOW_CREDENTIALS")); v != "" {
+		allow, err := strconv.ParseBool(v)
+		if err != nil {
+			return CORSConfig{}, fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS %q: %w", v, err)
+		}
+		cfg.AllowCredentials = allow
+	}
+	if v := strings.TrimSpace(os.Getenv("CORS_MAX_AGE_SECONDS")); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 {
+			return CORSConfig{}, fmt.Errorf("invalid CORS_MAX_AGE_SECONDS %q", v)
+		}
+		cfg.MaxAge = time.Duration(seconds) * time.Second
+	}
+
+	for i, origin := range cfg.AllowedOrigins {
+		if origin == "*" {
+			if cfg.AllowCredentials {
+				return CORSConfig{}, fmt.Errorf("CORS_ALLOWED_ORIGINS cannot be * while CORS_ALLOW_CREDENTIALS is true")
+			}
+			continue
+		}
+		// Browsers send origins without a trailing slash
+		cfg.AllowedOrigins[i] = strings.TrimRight(origin, "/")
+	}
+
+	return cfg, nil
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadCORSConfig_FromEnv(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "http://localhost:3000/, https://devsmith.example.com")
+	t.Setenv("CORS_ALLOWED_METHODS", "GET,POST")
+	t.Setenv("CORS_ALLOWED_HEADERS", "Content-Type, X-CSRF-Token")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "")
+	t.Setenv("CORS_MAX_AGE_SECONDS", "120")
+
+	cfg, err := LoadCORSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://localhost:3000", "https://devsmith.example.com"}, cfg.AllowedOrigins)
+	assert.Equal(t, []string{"GET", "POST"}, cfg.AllowedMethods)
+	assert.Equal(t, []string{"Content-Type", "X-CSRF-Token"}, cfg.AllowedHeaders)
+	assert.True(t, cfg.AllowCredentials)
+	assert.Equal(t, 2*time.Minute, cfg.MaxAge)
+}
+
+func TestLoadCORSConfig_DefaultsToGateway(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+	t.Setenv("CORS_ALLOWED_METHODS", "")
+	t.Setenv("CORS_ALLOWED_HEADERS", "")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "")
+	t.Setenv("CORS_MAX_AGE_SECONDS", "")
+	t.Setenv("GATEWAY_URL", "http://localhost:3000")
+
+	cfg, err := LoadCORSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://localhost:3000"}, cfg.AllowedOrigins)
+	assert.Contains(t, cfg.AllowedMethods, "DELETE")
+	assert.Contains(t, cfg.AllowedHeaders, "X-CSRF-Token")
+}
+
+func TestLoadCORSConfig_WildcardRequiresNoCredentials(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "*")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	_, err := LoadCORSConfig()
+	assert.Error(t, err)
+
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "false")
+	cfg, err := LoadCORSConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"*"}, cfg.AllowedOrigins)
+	assert.False(t, cfg.AllowCredentials)
+}
+
+func TestLoadCORSConfig_InvalidValues(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "http://localhost:3000")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "sometimes")
+	_, err := LoadCORSConfig()
+	assert.Error(t, err)
+
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "")
+	t.Setenv("CORS_MAX_AGE_SECONDS", "-1")
+	_, err = LoadCORSConfig()
+	assert.Error(t, err)
+}
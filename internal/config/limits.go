@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRequestLimits apply to routes without their own limits
+var DefaultRequestLimits = RequestLimits{
+	MaxBodyBytes: 1 << 20, // 1 MiB
+	Timeout:      30 * time.Second,
+}
+
+// RequestLimits caps the size of a request body and how long its handler
+// may run. A zero value disables that limit.
+type RequestLimits struct {
+	MaxBodyBytes int64
+	Timeout      time.Duration
+}
+
+// LoadRequestLimits reads limits from <prefix>MAX_BODY_BYTES and
+// <prefix>TIMEOUT_SECONDS, falling back to defaults for unset variables.
+// The service-wide limits use the REQUEST_ prefix.
+func LoadRequestLimits(prefix string, defaults RequestLimits) (RequestLimits, error) {
+	limits := defaults
+
+	if v := strings.TrimSpace(os.Getenv(prefix + "MAX_BODY_BYTES")); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return RequestLimits{}, fmt.Errorf("invalid %sMAX_BODY_BYTES %q", prefix, v)
+		}
+		limits.MaxBodyBytes = n
+	}
+	if v := strings.TrimSpace(os.Getenv(prefix + "TIMEOUT_SECONDS")); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds < 0 {
+			return RequestLimits{}, fmt.Errorf("invalid %sTIMEOUT_SECONDS %q", prefix, v)
+		}
+		limits.Timeout = time.Duration(seconds) * time.Second
+	}
+
+	return limits, nil
+}
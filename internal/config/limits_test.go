@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRequestLimits(t *testing.T) {
+	t.Setenv("REQUEST_MAX_BODY_BYTES", "")
+	t.Setenv("REQUEST_TIMEOUT_SECONDS", "")
+	limits, err := LoadRequestLimits("REQUEST_", DefaultRequestLimits)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRequestLimits, limits)
+
+	t.Setenv("REVIEW_ANALYSIS_MAX_BODY_BYTES", "2048")
+	t.Setenv("REVIEW_ANALYSIS_TIMEOUT_SECONDS", "0")
+	limits, err = LoadRequestLimits("REVIEW_ANALYSIS_", DefaultRequestLimits)
+	require.NoError(t, err)
+	assert.Equal(t, RequestLimits{MaxBodyBytes: 2048}, limits)
+
+	t.Setenv("REQUEST_TIMEOUT_SECONDS", "soon")
+	_, err = LoadRequestLimits("REQUEST_", DefaultRequestLimits)
+	assert.Error(t, err)
+
+	t.Setenv("REQUEST_TIMEOUT_SECONDS", "")
+	t.Setenv("REQUEST_MAX_BODY_BYTES", "-1")
+	_, err = LoadRequestLimits("REQUEST_", DefaultRequestLimits)
+	assert.Error(t, err)
+}
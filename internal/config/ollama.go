@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DefaultOllamaEndpoint is used when neither OLLAMA_ENDPOINTS nor
+// OLLAMA_ENDPOINT is set
+const DefaultOllamaEndpoint = "http://host.docker.internal:11434"
+
+// LoadOllamaEndpoints reads the Ollama instances to balance across from the
+// comma-separated OLLAMA_ENDPOINTS, falling back to the single
+// OLLAMA_ENDPOINT and then DefaultOllamaEndpoint. Each must be an http(s)
+// URL; duplicates are dropped.
+func LoadOllamaEndpoints() ([]string, error) {
+	raw := os.Getenv("OLLAMA_ENDPOINTS")
+	if strings.TrimSpace(raw) == "" {
+		raw = os.Getenv("OLLAMA_ENDPOINT")
+	}
+	if strings.TrimSpace(raw) == "" {
+		return []string{DefaultOllamaEndpoint}, nil
+	}
+
+	var endpoints []string
+	seen := make(map[string]bool)
+	for _, endpoint := range strings.Split(raw, ",") {
+		endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
+		if endpoint == "" || seen[endpoint] {
+			continue
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid Ollama endpoint %q: must be an http or https URL", endpoint)
+		}
+		seen[endpoint] = true
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("OLLAMA_ENDPOINTS %q lists no endpoints", raw)
+	}
+	return endpoints, nil
+}
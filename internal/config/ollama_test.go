@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOllamaEndpoints(t *testing.T) {
+	t.Setenv("OLLAMA_ENDPOINTS", "")
+	t.Setenv("OLLAMA_ENDPOINT", "")
+	endpoints, err := LoadOllamaEndpoints()
+	require.NoError(t, err)
+	assert.Equal(t, []string{DefaultOllamaEndpoint}, endpoints)
+
+	t.Setenv("OLLAMA_ENDPOINT", "http://ollama:11434/")
+	endpoints, err = LoadOllamaEndpoints()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://ollama:11434"}, endpoints)
+
+	t.Setenv("OLLAMA_ENDPOINTS", " http://gpu-1:11434, http://gpu-2:11434,,http://gpu-1:11434/ ")
+	endpoints, err = LoadOllamaEndpoints()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://gpu-1:11434", "http://gpu-2:11434"}, endpoints, "OLLAMA_ENDPOINTS wins, duplicates dropped")
+}
+
+func TestLoadOllamaEndpoints_RejectsInvalid(t *testing.T) {
+	t.Setenv("OLLAMA_ENDPOINT", "")
+	for _, value := range []string{"gpu-1:11434", "ftp://gpu-1", "http://", ", ,"} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("OLLAMA_ENDPOINTS", value)
+			_, err := LoadOllamaEndpoints()
+			require.Error(t, err)
+		})
+	}
+}
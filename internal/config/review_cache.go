@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultReviewCacheTTL is how long identical review analyses are served
+// from the response cache when REVIEW_CACHE_TTL is unset
+const DefaultReviewCacheTTL = 24 * time.Hour
+
+// MaxReviewCacheTTL bounds REVIEW_CACHE_TTL
+const MaxReviewCacheTTL = 30 * 24 * time.Hour
+
+// LoadReviewCacheTTL reads REVIEW_CACHE_TTL, a duration ("6h") or a number
+// of seconds. 0 disables the response cache; unset means
+// DefaultReviewCacheTTL.
+func LoadReviewCacheTTL() (time.Duration, error) {
+	v := strings.TrimSpace(os.Getenv("REVIEW_CACHE_TTL"))
+	if v == "" {
+		return DefaultReviewCacheTTL, nil
+	}
+	ttl, err := parseSecondsOrDuration(v)
+	if err != nil || ttl < 0 || ttl > MaxReviewCacheTTL {
+		return 0, fmt.Errorf("invalid REVIEW_CACHE_TTL %q: must be between 0 and %s", v, MaxReviewCacheTTL)
+	}
+	return ttl, nil
+}
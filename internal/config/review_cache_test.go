@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadReviewCacheTTL(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", DefaultReviewCacheTTL},
+		{"3600", time.Hour},
+		{"6h", 6 * time.Hour},
+		{"0", 0},
+	}
+	for _, tt := range tests {
+		t.Setenv("REVIEW_CACHE_TTL", tt.value)
+		ttl, err := LoadReviewCacheTTL()
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, ttl, tt.value)
+	}
+}
+
+func TestLoadReviewCacheTTL_RejectsInvalid(t *testing.T) {
+	for _, v := range []string{"-5", "soon", "1000h"} {
+		t.Setenv("REVIEW_CACHE_TTL", v)
+		_, err := LoadReviewCacheTTL()
+		assert.Error(t, err, v)
+	}
+}
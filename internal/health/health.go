@@ -0,0 +1,159 @@
+// Package health provides the health endpoint shared by all DevSmith
+// services, so monitoring sees the same response shape everywhere.
+package health
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/version"
+)
+
+// Status is the health of a service or one of its dependencies
+type Status string
+
+// Health statuses, from best to worst
+const (
+	StatusHealthy   Status = "healthy"
+	StatusDegraded  Status = "degraded"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// checkTimeout bounds all checks of one health request
+const checkTimeout = 5 * time.Second
+
+// Result is the outcome of a single check
+type Result struct {
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Check reports the health of one dependency
+type Check func(ctx context.Context) Result
+
+// CheckSet reports the health of several dependencies at once, keyed by name
+type CheckSet func(ctx context.Context) map[string]Result
+
+// Response is the body of every health endpoint
+type Response struct {
+	Checks  map[string]Result `json:"checks"`
+	Service string            `json:"service"`
+	Status  Status            `json:"status"`
+	Version string            `json:"version"`
+	Uptime  int64             `json:"uptime"` // seconds since the handler was created
+}
+
+// Handler serves the health of a service
+type Handler struct {
+	now     func() time.Time
+	started time.Time
+	checks  map[string]Check
+	service string
+	sets    []CheckSet
+}
+
+// NewHandler creates a health handler for service. Its uptime counts from
+// now, so create it at startup.
+func NewHandler(service string) *Handler {
+	return &Handler{
+		now:     time.Now,
+		started: time.Now(),
+		checks:  make(map[string]Check),
+		service: service,
+	}
+}
+
+// AddCheck adds a named check. Checks must be added before serving.
+func (h *Handler) AddCheck(name string, check Check) {
+	h.checks[name] = check
+}
+
+// AddCheckSet adds checks that are evaluated together. Sets must be added
+// before serving.
+func (h *Handler) AddCheckSet(set CheckSet) {
+	h.sets = append(h.sets, set)
+}
+
+// Register serves the handler for GET and HEAD on path
+func (h *Handler) Register(r gin.IRoutes, path string) {
+	r.GET(path, h.Handle)
+	r.HEAD(path, h.Handle)
+}
+
+// Handle runs the checks and writes the Response. Unhealthy services answer
+// 503 so load balancers take them out of rotation; HEAD requests get the
+// status code only.
+func (h *Handler) Handle(c *gin.Context) {
+	resp := h.Evaluate(c.Request.Context())
+
+	code := http.StatusOK
+	if resp.Status == StatusUnhealthy {
+		code = http.StatusServiceUnavailable
+	}
+	if c.Request.Method == http.MethodHead {
+		c.Status(code)
+		return
+	}
+	c.JSON(code, resp)
+}
+
+// Evaluate runs the checks. The service status is the worst check status.
+func (h *Handler) Evaluate(ctx context.Context) Response {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	results := make(map[string]Result, len(h.checks))
+	for name, check := range h.checks {
+		results[name] = check(ctx)
+	}
+	for _, set := range h.sets {
+		for name, result := range set(ctx) {
+			results[name] = result
+		}
+	}
+
+	status := StatusHealthy
+	for _, result := range results {
+		status = worse(status, result.Status)
+	}
+
+	return Response{
+		Service: h.service,
+		Status:  status,
+		Version: version.ShortVersion(),
+		Uptime:  int64(h.now().Sub(h.started).Seconds()),
+		Checks:  results,
+	}
+}
+
+// Ping adapts a connectivity probe such as (*sql.DB).PingContext to a Check:
+// an error makes the dependency unhealthy.
+func Ping(ping func(ctx context.Context) error) Check {
+	return func(ctx context.Context) Result {
+		if err := ping(ctx); err != nil {
+			return Result{Status: StatusUnhealthy, Message: err.Error()}
+		}
+		return Result{Status: StatusHealthy}
+	}
+}
+
+func worse(a, b Status) Status {
+	if rank(b) > rank(a) {
+		return b
+	}
+	return a
+}
+
+func rank(s Status) int {
+	switch s {
+	case StatusHealthy:
+		return 0
+	case StatusDegraded:
+		return 1
+	default:
+		// Unknown statuses are treated as failures
+		return 2
+	}
+}
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveHealth(h *Handler, method string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h.Register(router, "/api/logs/health")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, "/api/logs/health", http.NoBody))
+	return w
+}
+
+func healthy(context.Context) Result { return Result{Status: StatusHealthy} }
+
+func TestHandler_JSONShape(t *testing.T) {
+	h := NewHandler("logs")
+	h.started = time.Now().Add(-90 * time.Second)
+	h.AddCheck("database", healthy)
+
+	w := serveHealth(h, http.MethodGet)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	var body map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.ElementsMatch(t, []string{"service", "status", "version", "uptime", "checks"}, keys(body))
+	assert.JSONEq(t, `"logs"`, string(body["service"]))
+	assert.JSONEq(t, `"healthy"`, string(body["status"]))
+	assert.JSONEq(t, `"`+version.ShortVersion()+`"`, string(body["version"]))
+	assert.JSONEq(t, `90`, string(body["uptime"]))
+	assert.JSONEq(t, `{"database":{"status":"healthy"}}`, string(body["checks"]))
+}
+
+func TestHandler_NoChecks(t *testing.T) {
+	w := serveHealth(NewHandler("portal"), http.MethodGet)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, StatusHealthy, resp.Status)
+	assert.NotNil(t, resp.Checks, "checks is always an object")
+	assert.Contains(t, w.Body.String(), `"checks":{}`)
+}
+
+func TestHandler_HEADHasNoBody(t *testing.T) {
+	h := NewHandler("logs")
+	h.AddCheck("database", healthy)
+
+	w := serveHealth(h, http.MethodHead)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestHandler_WorstCheckWins(t *testing.T) {
+	h := NewHandler("review")
+	h.AddCheck("database", healthy)
+	h.AddCheckSet(func(context.Context) map[string]Result {
+		return map[string]Result{
+			"ollama_model": {Status: StatusDegraded, Message: "model not pulled"},
+		}
+	})
+
+	w := serveHealth(h, http.MethodGet)
+	require.Equal(t, http.StatusOK, w.Code, "degraded services keep serving traffic")
+	var resp Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, StatusDegraded, resp.Status)
+	assert.Equal(t, "model not pulled", resp.Checks["ollama_model"].Message)
+
+	h.AddCheck("redis", Ping(func(context.Context) error { return errors.New("connection refused") }))
+	w = serveHealth(h, http.MethodGet)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, StatusUnhealthy, resp.Status)
+	assert.Equal(t, Result{Status: StatusUnhealthy, Message: "connection refused"}, resp.Checks["redis"])
+
+	w = serveHealth(h, http.MethodHead)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func keys(m map[string]json.RawMessage) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
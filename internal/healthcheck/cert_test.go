@@ -0,0 +1,129 @@
+package healthcheck
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCertServer starts a TLS server with a self-signed certificate valid
+// from notBefore to notAfter
+func newCertServer(t *testing.T, notBefore, notAfter time.Time) *httptest.Server {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "internal.devsmith.test"},
+		DNSNames:     []string{"internal.devsmith.test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCertChecker_Expiry(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		expected CheckStatus
+		message  string
+	}{
+		{"valid", now.Add(90 * 24 * time.Hour), StatusPass, "valid for 89 days"},
+		{"within warn threshold", now.Add(7*24*time.Hour + time.Hour), StatusWarn, "expires in 7 days"},
+		{"within fail threshold", now.Add(36 * time.Hour), StatusFail, "expires in 1 days"},
+		{"expired", now.Add(-time.Hour), StatusFail, "expired on"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newCertServer(t, now.Add(-30*24*time.Hour), tt.notAfter)
+			checker := &CertChecker{
+				CheckName:          "cert_test",
+				Address:            server.Listener.Addr().String(),
+				InsecureSkipVerify: true,
+			}
+
+			result := checker.Check()
+
+			if result.Status != tt.expected {
+				t.Errorf("Expected status %s, got %s (%s: %s)", tt.expected, result.Status, result.Message, result.Error)
+			}
+			if !strings.Contains(result.Message, tt.message) {
+				t.Errorf("Expected message containing %q, got %q", tt.message, result.Message)
+			}
+			if result.Details["not_after"] != tt.notAfter.UTC().Format(time.RFC3339) {
+				t.Errorf("Expected not_after %s, got %v", tt.notAfter.UTC().Format(time.RFC3339), result.Details["not_after"])
+			}
+			if _, ok := result.Details["days_remaining"].(int); !ok {
+				t.Errorf("Expected days_remaining in details, got %v", result.Details)
+			}
+		})
+	}
+}
+
+func TestCertChecker_CustomThresholds(t *testing.T) {
+	server := newCertServer(t, time.Now().Add(-time.Hour), time.Now().Add(20*24*time.Hour))
+	checker := &CertChecker{
+		CheckName:          "cert_test",
+		Address:            server.Listener.Addr().String(),
+		WarnDays:           30,
+		InsecureSkipVerify: true,
+	}
+
+	if result := checker.Check(); result.Status != StatusWarn {
+		t.Errorf("Expected status %s with a 30 day threshold, got %s", StatusWarn, result.Status)
+	}
+}
+
+func TestCertChecker_VerifiesByDefault(t *testing.T) {
+	server := newCertServer(t, time.Now().Add(-time.Hour), time.Now().Add(90*24*time.Hour))
+	checker := &CertChecker{CheckName: "cert_test", Address: server.Listener.Addr().String()}
+
+	result := checker.Check()
+
+	if result.Status != StatusFail {
+		t.Errorf("Expected a self-signed certificate to fail verification, got %s", result.Status)
+	}
+	if !strings.Contains(result.Message, "TLS handshake failed") {
+		t.Errorf("Expected handshake failure, got %q", result.Message)
+	}
+}
+
+func TestCertChecker_TestServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	checker := &CertChecker{CheckName: "cert_test", Address: server.Listener.Addr().String(), InsecureSkipVerify: true}
+
+	result := checker.Check()
+
+	if result.Status != StatusPass {
+		t.Errorf("Expected status %s, got %s (%s)", StatusPass, result.Status, result.Error)
+	}
+	if result.Details["chain_length"] != 1 {
+		t.Errorf("Expected chain length 1, got %v", result.Details["chain_length"])
+	}
+}
@@ -0,0 +1,273 @@
+package healthcheck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Checker types accepted in a config file
+const (
+	CheckTypeDocker       = "docker"
+	CheckTypeHTTP         = "http"
+	CheckTypeDatabase     = "database"
+	CheckTypeGateway      = "gateway"
+	CheckTypeMetrics      = "metrics"
+	CheckTypeDependencies = "dependencies"
+	CheckTypeTrivy        = "trivy"
+	CheckTypeCert         = "cert"
+)
+
+// ErrInvalidConfig is returned when a config file fails validation
+var ErrInvalidConfig = errors.New("invalid healthcheck config")
+
+// Config describes the checks to run, as read from a YAML file.
+// String values may reference environment variables as ${NAME}.
+type Config struct {
+	Timeout time.Duration `yaml:"timeout"`
+	Checks  []CheckConfig `yaml:"checks"`
+}
+
+// CheckConfig configures one checker. Which fields apply depends on Type.
+type CheckConfig struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	// Advanced checks are skipped when advanced diagnostics are disabled
+	Advanced bool `yaml:"advanced"`
+
+	// http, database, gateway
+	URL string `yaml:"url"`
+	// http: consecutive failures before failing, and the first retry delay
+	Attempts int           `yaml:"attempts"`
+	Backoff  time.Duration `yaml:"backoff"`
+	// docker
+	Project  string   `yaml:"project"`
+	Services []string `yaml:"services"`
+	// gateway
+	ConfigPath string `yaml:"config_path"`
+	// metrics
+	Endpoints  []MetricEndpointConfig `yaml:"endpoints"`
+	Thresholds ThresholdConfig        `yaml:"thresholds"`
+	// dependencies
+	Dependencies map[string][]string `yaml:"dependencies"`
+	HealthChecks map[string]string   `yaml:"health_checks"`
+	// cert
+	Address            string `yaml:"address"`
+	ServerName         string `yaml:"server_name"`
+	WarnDays           int    `yaml:"warn_days"`
+	FailDays           int    `yaml:"fail_days"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	// trivy
+	ScanType  string   `yaml:"scan_type"`
+	TrivyPath string   `yaml:"trivy_path"`
+	Targets   []string `yaml:"targets"`
+}
+
+// MetricEndpointConfig is one endpoint measured by a metrics check
+type MetricEndpointConfig struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// ThresholdConfig sets response time thresholds for a metrics check
+type ThresholdConfig struct {
+	FastMS int64 `yaml:"fast_ms"`
+	SlowMS int64 `yaml:"slow_ms"`
+}
+
+// LoadConfig reads and validates a config file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read healthcheck config: %w", err)
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses and validates YAML config. Unknown fields are rejected.
+func ParseConfig(data []byte) (*Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader([]byte(os.ExpandEnv(string(data)))))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks that every checker has a known type, a unique name and
+// the fields its type requires, and that dependencies only name services
+// with health checks.
+func (c *Config) Validate() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("%w: timeout must not be negative", ErrInvalidConfig)
+	}
+	if len(c.Checks) == 0 {
+		return fmt.Errorf("%w: no checks configured", ErrInvalidConfig)
+	}
+
+	names := make(map[string]bool, len(c.Checks))
+	for i := range c.Checks {
+		check := &c.Checks[i]
+		if err := check.validate(); err != nil {
+			return fmt.Errorf("%w: check %d (%s): %v", ErrInvalidConfig, i+1, check.checkName(), err)
+		}
+		name := check.checkName()
+		if names[name] {
+			return fmt.Errorf("%w: duplicate check name %q", ErrInvalidConfig, name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+func (cc *CheckConfig) checkName() string {
+	if cc.Type == CheckTypeDocker {
+		return (&DockerChecker{}).Name()
+	}
+	return cc.Name
+}
+
+func (cc *CheckConfig) validate() error {
+	if cc.Type != CheckTypeDocker && cc.Name == "" {
+		return errors.New("name is required")
+	}
+
+	switch cc.Type {
+	case CheckTypeDocker:
+		if cc.Project == "" || len(cc.Services) == 0 {
+			return errors.New("project and services are required")
+		}
+	case CheckTypeHTTP, CheckTypeDatabase:
+		if cc.URL == "" {
+			return errors.New("url is required")
+		}
+		if cc.Attempts < 0 || cc.Backoff < 0 {
+			return errors.New("attempts and backoff must not be negative")
+		}
+	case CheckTypeGateway:
+		if cc.ConfigPath == "" {
+			return errors.New("config_path is required")
+		}
+	case CheckTypeMetrics:
+		if len(cc.Endpoints) == 0 {
+			return errors.New("endpoints are required")
+		}
+		for _, e := range cc.Endpoints {
+			if e.Name == "" || e.URL == "" {
+				return errors.New("endpoints need a name and url")
+			}
+		}
+		if cc.Thresholds.FastMS < 0 || cc.Thresholds.SlowMS < 0 ||
+			(cc.Thresholds.SlowMS > 0 && cc.Thresholds.FastMS > cc.Thresholds.SlowMS) {
+			return errors.New("thresholds must satisfy 0 <= fast_ms <= slow_ms")
+		}
+	case CheckTypeDependencies:
+		if len(cc.Dependencies) == 0 {
+			return errors.New("dependencies are required")
+		}
+		for _, service := range sortedKeys(cc.Dependencies) {
+			if _, ok := cc.HealthChecks[service]; !ok {
+				return fmt.Errorf("service %q has no health check", service)
+			}
+			for _, dep := range cc.Dependencies[service] {
+				if _, ok := cc.HealthChecks[dep]; !ok {
+					return fmt.Errorf("dependency %q of %q has no health check", dep, service)
+				}
+			}
+		}
+		if cycle := findDependencyCycle(cc.Dependencies); cycle != nil {
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " → "))
+		}
+	case CheckTypeCert:
+		if cc.Address == "" {
+			return errors.New("address is required")
+		}
+		if cc.WarnDays < 0 || cc.FailDays < 0 || (cc.WarnDays > 0 && cc.FailDays > cc.WarnDays) {
+			return errors.New("days must satisfy 0 <= fail_days <= warn_days")
+		}
+	case CheckTypeTrivy:
+		if cc.ScanType == "" || cc.TrivyPath == "" {
+			return errors.New("scan_type and trivy_path are required")
+		}
+	default:
+		return fmt.Errorf("unknown checker type %q", cc.Type)
+	}
+	return nil
+}
+
+// NewRunner builds a runner from the config. Checks marked advanced are
+// only added when advanced is true.
+func (c *Config) NewRunner(advanced bool) (*Runner, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	runner := NewRunner()
+	if c.Timeout > 0 {
+		runner.Timeout = c.Timeout
+	}
+	for i := range c.Checks {
+		if c.Checks[i].Advanced && !advanced {
+			continue
+		}
+		runner.AddChecker(c.Checks[i].newChecker())
+	}
+	return runner, nil
+}
+
+func (cc *CheckConfig) newChecker() Checker {
+	switch cc.Type {
+	case CheckTypeDocker:
+		return &DockerChecker{ProjectName: cc.Project, Services: cc.Services}
+	case CheckTypeHTTP:
+		return &HTTPChecker{CheckName: cc.Name, URL: cc.URL, Attempts: cc.Attempts, Backoff: cc.Backoff}
+	case CheckTypeDatabase:
+		return &DatabaseChecker{CheckName: cc.Name, ConnectionURL: cc.URL}
+	case CheckTypeGateway:
+		return &GatewayChecker{CheckName: cc.Name, ConfigPath: cc.ConfigPath, GatewayURL: cc.URL}
+	case CheckTypeMetrics:
+		endpoints := make([]MetricEndpoint, len(cc.Endpoints))
+		for i, e := range cc.Endpoints {
+			endpoints[i] = MetricEndpoint(e)
+		}
+		return &MetricsChecker{
+			CheckName:       cc.Name,
+			Endpoints:       endpoints,
+			FastThresholdMS: cc.Thresholds.FastMS,
+			SlowThresholdMS: cc.Thresholds.SlowMS,
+		}
+	case CheckTypeDependencies:
+		return &DependencyChecker{CheckName: cc.Name, Dependencies: cc.Dependencies, HealthChecks: cc.HealthChecks}
+	case CheckTypeCert:
+		return &CertChecker{
+			CheckName:          cc.Name,
+			Address:            cc.Address,
+			ServerName:         cc.ServerName,
+			WarnDays:           cc.WarnDays,
+			FailDays:           cc.FailDays,
+			InsecureSkipVerify: cc.InsecureSkipVerify,
+		}
+	case CheckTypeTrivy:
+		return &TrivyChecker{CheckName: cc.Name, ScanType: cc.ScanType, TrivyPath: cc.TrivyPath, Targets: cc.Targets}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,157 @@
+package healthcheck
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleConfig = `
+timeout: 3s
+checks:
+  - type: docker
+    project: devsmith-modular-platform
+    services: [portal, postgres]
+  - name: http_portal
+    type: http
+    url: http://localhost:8080/health
+    attempts: 3
+    backoff: 200ms
+  - name: database
+    type: database
+    url: ${HEALTHCHECK_TEST_DB}
+  - name: cert_gateway
+    type: cert
+    address: localhost:443
+    warn_days: 30
+    insecure_skip_verify: true
+  - name: performance_metrics
+    type: metrics
+    advanced: true
+    endpoints:
+      - {name: portal, url: http://localhost:8080/health}
+    thresholds: {fast_ms: 50, slow_ms: 500}
+  - name: service_dependencies
+    type: dependencies
+    advanced: true
+    dependencies:
+      review: [portal]
+      portal: []
+    health_checks:
+      portal: http://localhost:8080/health
+      review: http://localhost:8081/health
+`
+
+func TestParseConfigBuildsRunner(t *testing.T) {
+	t.Setenv("HEALTHCHECK_TEST_DB", "postgres://localhost/devsmith")
+
+	cfg, err := ParseConfig([]byte(sampleConfig))
+	if err != nil {
+		t.Fatalf("Expected config to parse, got %v", err)
+	}
+	runner, err := cfg.NewRunner(true)
+	if err != nil {
+		t.Fatalf("Expected runner, got %v", err)
+	}
+
+	if runner.Timeout != 3*time.Second {
+		t.Errorf("Expected timeout 3s, got %s", runner.Timeout)
+	}
+	names := []string{}
+	for _, checker := range runner.Checkers {
+		names = append(names, checker.Name())
+	}
+	expected := "docker_containers,http_portal,database,cert_gateway,performance_metrics,service_dependencies"
+	if strings.Join(names, ",") != expected {
+		t.Errorf("Expected checkers %s, got %v", expected, names)
+	}
+
+	httpChecker, ok := runner.Checkers[1].(*HTTPChecker)
+	if !ok || httpChecker.Attempts != 3 || httpChecker.Backoff != 200*time.Millisecond {
+		t.Errorf("Expected http checker with retries, got %#v", runner.Checkers[1])
+	}
+	db, ok := runner.Checkers[2].(*DatabaseChecker)
+	if !ok || db.ConnectionURL != "postgres://localhost/devsmith" {
+		t.Errorf("Expected database checker with expanded URL, got %#v", runner.Checkers[2])
+	}
+	cert, ok := runner.Checkers[3].(*CertChecker)
+	if !ok || cert.Address != "localhost:443" || cert.WarnDays != 30 || !cert.InsecureSkipVerify {
+		t.Errorf("Expected cert checker, got %#v", runner.Checkers[3])
+	}
+	metrics, ok := runner.Checkers[4].(*MetricsChecker)
+	if !ok || metrics.FastThresholdMS != 50 || metrics.SlowThresholdMS != 500 || len(metrics.Endpoints) != 1 {
+		t.Errorf("Expected metrics checker with thresholds, got %#v", runner.Checkers[4])
+	}
+	deps, ok := runner.Checkers[5].(*DependencyChecker)
+	if !ok || len(deps.Dependencies["review"]) != 1 || deps.HealthChecks["review"] == "" {
+		t.Errorf("Expected dependency graph, got %#v", runner.Checkers[5])
+	}
+}
+
+func TestConfigSkipsAdvancedChecks(t *testing.T) {
+	t.Setenv("HEALTHCHECK_TEST_DB", "postgres://localhost/devsmith")
+	cfg, err := ParseConfig([]byte(sampleConfig))
+	if err != nil {
+		t.Fatalf("Expected config to parse, got %v", err)
+	}
+	runner, err := cfg.NewRunner(false)
+	if err != nil {
+		t.Fatalf("Expected runner, got %v", err)
+	}
+	if len(runner.Checkers) != 4 {
+		t.Errorf("Expected 4 basic checkers, got %d", len(runner.Checkers))
+	}
+}
+
+func TestParseConfigRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		errMsg string
+	}{
+		{"unknown checker type", "checks:\n  - {name: x, type: ping, url: http://x}", `unknown checker type "ping"`},
+		{"missing url", "checks:\n  - {name: x, type: http}", "url is required"},
+		{"duplicate name", "checks:\n  - {name: x, type: http, url: http://a}\n  - {name: x, type: http, url: http://b}", "duplicate check name"},
+		{"unknown field", "checks:\n  - {name: x, type: http, uri: http://a}", "field uri not found"},
+		{"unset environment variable", "checks:\n  - {name: db, type: database, url: ${HEALTHCHECK_UNSET_VAR}}", "url is required"},
+		{"no checks", "timeout: 1s", "no checks configured"},
+		{"dependency without health check", "checks:\n  - name: deps\n    type: dependencies\n    dependencies: {review: [portal]}\n    health_checks: {review: http://r}", `dependency "portal" of "review"`},
+		{"dependency cycle", "checks:\n  - name: deps\n    type: dependencies\n    dependencies: {review: [logs], logs: [review]}\n    health_checks: {review: http://r, logs: http://l}", "logs → review → logs"},
+		{"inverted thresholds", "checks:\n  - name: m\n    type: metrics\n    endpoints: [{name: a, url: http://a}]\n    thresholds: {fast_ms: 900, slow_ms: 100}", "thresholds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.config))
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %q", tt.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("HEALTHCHECK_TEST_DB", "postgres://localhost/devsmith")
+	path := filepath.Join(t.TempDir(), "healthcheck.yaml")
+	if err := os.WriteFile(path, []byte(sampleConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); err != nil {
+		t.Errorf("Expected config to load, got %v", err)
+	}
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestExampleConfigIsValid(t *testing.T) {
+	if _, err := LoadConfig("../../cmd/healthcheck/healthcheck.example.yaml"); err != nil {
+		t.Errorf("Expected example config to be valid, got %v", err)
+	}
+}
@@ -2,6 +2,8 @@ package healthcheck
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -35,6 +37,16 @@ func (c *DependencyChecker) Check() CheckResult {
 		Details:   make(map[string]interface{}),
 	}
 
+	// A cyclic dependency graph is a config mistake; fail before probing
+	if cycle := findDependencyCycle(c.Dependencies); cycle != nil {
+		result.Status = StatusFail
+		result.Message = fmt.Sprintf("Dependency cycle detected: %s", strings.Join(cycle, " → "))
+		result.Error = "service dependencies must not form a cycle"
+		result.Details["cycle"] = cycle
+		result.Duration = time.Since(start)
+		return result
+	}
+
 	// Check health of all services first
 	serviceHealth := make(map[string]bool)
 	for service, healthURL := range c.HealthChecks {
@@ -108,3 +120,55 @@ func (c *DependencyChecker) isServiceHealthy(healthURL string) bool {
 	checkResult := checker.Check()
 	return checkResult.Status == StatusPass
 }
+
+// findDependencyCycle returns the first cycle found by a depth-first search
+// of deps, as a path that starts and ends with the same service, or nil if
+// the graph is acyclic. Services are visited in sorted order so the result
+// is deterministic.
+func findDependencyCycle(deps map[string][]string) []string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int, len(deps))
+	var path []string
+
+	var visit func(service string) []string
+	visit = func(service string) []string {
+		switch state[service] {
+		case inProgress:
+			// service is on the current path; the cycle runs from there
+			for i, s := range path {
+				if s == service {
+					return append(append([]string{}, path[i:]...), service)
+				}
+			}
+		case done:
+			return nil
+		}
+
+		state[service] = inProgress
+		path = append(path, service)
+		for _, dep := range deps[service] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[service] = done
+		return nil
+	}
+
+	services := make([]string, 0, len(deps))
+	for service := range deps {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		if cycle := visit(service); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package healthcheck
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -119,3 +121,68 @@ func TestDependencyChecker_Check_AllHealthy(t *testing.T) {
 		t.Errorf("Expected status pass when all healthy, got %s", result.Status)
 	}
 }
+
+func TestFindDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		deps     map[string][]string
+		expected []string
+	}{
+		{
+			name:     "acyclic",
+			deps:     map[string][]string{"portal": {}, "review": {"portal", "logs"}, "logs": {"portal"}, "analytics": {"logs"}},
+			expected: nil,
+		},
+		{
+			name:     "two services",
+			deps:     map[string][]string{"review": {"logs"}, "logs": {"review"}},
+			expected: []string{"logs", "review", "logs"},
+		},
+		{
+			name:     "longer cycle behind an acyclic prefix",
+			deps:     map[string][]string{"analytics": {"review"}, "review": {"logs"}, "logs": {"portal"}, "portal": {"review"}},
+			expected: []string{"review", "logs", "portal", "review"},
+		},
+		{
+			name:     "self dependency",
+			deps:     map[string][]string{"portal": {"portal"}},
+			expected: []string{"portal", "portal"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycle := findDependencyCycle(tt.deps)
+			if !reflect.DeepEqual(cycle, tt.expected) {
+				t.Errorf("Expected cycle %v, got %v", tt.expected, cycle)
+			}
+		})
+	}
+}
+
+func TestDependencyChecker_CheckFailsOnCycle(t *testing.T) {
+	probed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probed = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := &DependencyChecker{
+		CheckName:    "test_deps",
+		Dependencies: map[string][]string{"review": {"logs"}, "logs": {"review"}},
+		HealthChecks: map[string]string{"review": server.URL, "logs": server.URL},
+	}
+
+	result := checker.Check()
+
+	if result.Status != StatusFail {
+		t.Errorf("Expected status %s, got %s", StatusFail, result.Status)
+	}
+	if !strings.Contains(result.Message, "logs → review → logs") {
+		t.Errorf("Expected cycle path in message, got %q", result.Message)
+	}
+	if probed {
+		t.Error("Expected a cyclic config to fail before probing services")
+	}
+}
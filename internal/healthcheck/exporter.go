@@ -0,0 +1,146 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultExportInterval is how often the exporter re-runs checks
+const DefaultExportInterval = 30 * time.Second
+
+// Exporter re-runs a Runner in the background and serves the latest report
+// as JSON at /healthz and as Prometheus gauges at /metrics
+type Exporter struct {
+	runner   *Runner
+	interval time.Duration
+
+	mu     sync.RWMutex
+	report *HealthReport
+
+	registry *prometheus.Registry
+	status   *prometheus.GaugeVec
+	duration *prometheus.GaugeVec
+	overall  prometheus.Gauge
+	lastRun  prometheus.Gauge
+}
+
+// NewExporter creates an exporter for runner. A non-positive interval
+// means DefaultExportInterval.
+func NewExporter(runner *Runner, interval time.Duration) *Exporter {
+	if interval <= 0 {
+		interval = DefaultExportInterval
+	}
+	e := &Exporter{
+		runner:   runner,
+		interval: interval,
+		registry: prometheus.NewRegistry(),
+		status: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "healthcheck_status",
+			Help: "Result of each health check: 1 pass, 0.5 warn, 0 fail or unknown.",
+		}, []string{"check"}),
+		duration: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "healthcheck_duration_seconds",
+			Help: "Time taken by each health check in the last run.",
+		}, []string{"check"}),
+		overall: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "healthcheck_overall_status",
+			Help: "Overall result of the last run: 1 pass, 0.5 warn, 0 fail.",
+		}),
+		lastRun: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "healthcheck_last_run_timestamp_seconds",
+			Help: "Unix time the last run started.",
+		}),
+	}
+	e.registry.MustRegister(e.status, e.duration, e.overall, e.lastRun)
+	return e
+}
+
+// statusValue maps a check status to its gauge value
+func statusValue(status CheckStatus) float64 {
+	switch status {
+	case StatusPass:
+		return 1
+	case StatusWarn:
+		return 0.5
+	}
+	return 0
+}
+
+// Refresh runs all checks once and publishes the report
+func (e *Exporter) Refresh(ctx context.Context) HealthReport {
+	report := e.runner.RunContext(ctx)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.report = &report
+
+	// Reset so checks removed from the runner do not linger
+	e.status.Reset()
+	e.duration.Reset()
+	for _, check := range report.Checks {
+		e.status.WithLabelValues(check.Name).Set(statusValue(check.Status))
+		e.duration.WithLabelValues(check.Name).Set(check.Duration.Seconds())
+	}
+	e.overall.Set(statusValue(report.Status))
+	e.lastRun.Set(float64(report.Timestamp.Unix()))
+	return report
+}
+
+// Start refreshes immediately and then every interval until ctx is canceled
+func (e *Exporter) Start(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Report returns the latest report, or nil before the first run completes
+func (e *Exporter) Report() *HealthReport {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.report
+}
+
+// Handler serves /healthz and /metrics
+func (e *Exporter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", e.serveHealthz)
+	mux.Handle("/metrics", promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{Registry: e.registry}))
+	return mux
+}
+
+// serveHealthz writes the latest report as JSON, with 503 when it failed or
+// no run has completed yet
+func (e *Exporter) serveHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	report := e.Report()
+	if report == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"status":"unknown","message":"no health check has completed yet"}`))
+		return
+	}
+
+	body, err := FormatJSON(report)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status":"unknown","message":"failed to format report"}`))
+		return
+	}
+	if report.Status == StatusFail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_, _ = w.Write([]byte(body))
+}
@@ -0,0 +1,99 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestExporterBeforeFirstRun(t *testing.T) {
+	server := httptest.NewServer(NewExporter(NewRunner(), 0).Handler())
+	defer server.Close()
+
+	status, body := get(t, server.URL+"/healthz")
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before the first run, got %d", status)
+	}
+	if !strings.Contains(body, `"unknown"`) {
+		t.Errorf("Expected unknown status, got %s", body)
+	}
+}
+
+func TestExporterReportsFailingCheck(t *testing.T) {
+	runner := NewRunner()
+	runner.AddChecker(&MockChecker{name: "database", status: StatusFail, err: "connection refused"})
+	runner.AddChecker(&MockChecker{name: "http_portal", status: StatusPass})
+	runner.AddChecker(&MockChecker{name: "performance_metrics", status: StatusWarn})
+
+	exporter := NewExporter(runner, 0)
+	exporter.Refresh(context.Background())
+	server := httptest.NewServer(exporter.Handler())
+	defer server.Close()
+
+	status, body := get(t, server.URL+"/metrics")
+	if status != http.StatusOK {
+		t.Fatalf("Expected 200 from /metrics, got %d", status)
+	}
+	for _, want := range []string{
+		`healthcheck_status{check="database"} 0`,
+		`healthcheck_status{check="http_portal"} 1`,
+		`healthcheck_status{check="performance_metrics"} 0.5`,
+		`healthcheck_duration_seconds{check="database"} 0.01`,
+		`healthcheck_overall_status 0`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+
+	status, body = get(t, server.URL+"/healthz")
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 for a failing report, got %d", status)
+	}
+	var report HealthReport
+	if err := json.Unmarshal([]byte(body), &report); err != nil {
+		t.Fatalf("Expected JSON report, got %v", err)
+	}
+	if report.Summary.Failed != 1 || report.Checks[0].Error != "connection refused" {
+		t.Errorf("Expected failing database check in report, got %+v", report)
+	}
+}
+
+func TestExporterDropsRemovedChecks(t *testing.T) {
+	runner := NewRunner()
+	runner.AddChecker(&MockChecker{name: "old", status: StatusPass})
+	exporter := NewExporter(runner, 0)
+	exporter.Refresh(context.Background())
+
+	runner.Checkers = []Checker{&MockChecker{name: "new", status: StatusPass}}
+	exporter.Refresh(context.Background())
+	server := httptest.NewServer(exporter.Handler())
+	defer server.Close()
+
+	status, _ := get(t, server.URL+"/healthz")
+	if status != http.StatusOK {
+		t.Errorf("Expected 200 for a passing report, got %d", status)
+	}
+	_, body := get(t, server.URL+"/metrics")
+	if strings.Contains(body, `check="old"`) {
+		t.Errorf("Expected removed check to be dropped, got:\n%s", body)
+	}
+}
@@ -8,10 +8,19 @@ import (
 	"time"
 )
 
+// DefaultHTTPRetryBackoff is the wait before the first retry when Attempts > 1
+// and no Backoff is set
+const DefaultHTTPRetryBackoff = 500 * time.Millisecond
+
 // HTTPChecker validates HTTP endpoints are responding
 type HTTPChecker struct {
 	CheckName string
 	URL       string
+	// Attempts is how many consecutive failures (request errors or 5xx) it
+	// takes to fail the check; zero means a single attempt
+	Attempts int
+	// Backoff is the wait before the first retry, doubled for each one after
+	Backoff time.Duration
 }
 
 // Name returns the checker name
@@ -21,6 +30,57 @@ func (c *HTTPChecker) Name() string {
 
 // Check validates the HTTP endpoint
 func (c *HTTPChecker) Check() CheckResult {
+	return c.CheckContext(context.Background())
+}
+
+// CheckContext validates the HTTP endpoint, giving up when ctx is done.
+// Failed attempts are retried up to Attempts; a pass after a retry is
+// reported as a warning so an unstable endpoint doesn't look healthy.
+func (c *HTTPChecker) CheckContext(ctx context.Context) CheckResult {
+	start := time.Now()
+	attempts := c.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := c.Backoff
+	if backoff <= 0 {
+		backoff = DefaultHTTPRetryBackoff
+	}
+
+	var result CheckResult
+	attempt := 1
+	for ; ; attempt++ {
+		result = c.probe(ctx)
+		if result.Status != StatusFail || attempt >= attempts || !waitBackoff(ctx, backoff) {
+			break
+		}
+		backoff *= 2
+	}
+
+	result.Timestamp = start
+	result.Details["attempts"] = attempt
+	if attempt > 1 && result.Status == StatusPass {
+		result.Status = StatusWarn
+		result.Message = fmt.Sprintf("%s after %d attempts", result.Message, attempt)
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// waitBackoff waits for d, returning false if ctx is done first
+func waitBackoff(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// probe makes a single request to the endpoint
+func (c *HTTPChecker) probe(ctx context.Context) CheckResult {
 	start := time.Now()
 	result := CheckResult{
 		Name:      c.CheckName,
@@ -28,7 +88,7 @@ func (c *HTTPChecker) Check() CheckResult {
 		Details:   make(map[string]interface{}),
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", c.URL, http.NoBody)
@@ -0,0 +1,104 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails the first failures requests with 503, then returns 200
+func flakyServer(failures int32) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, &requests
+}
+
+func TestHTTPChecker_SingleAttemptByDefault(t *testing.T) {
+	server, requests := flakyServer(1)
+	defer server.Close()
+
+	result := (&HTTPChecker{CheckName: "http_test", URL: server.URL}).Check()
+
+	if result.Status != StatusFail {
+		t.Errorf("Expected status %s, got %s", StatusFail, result.Status)
+	}
+	if requests.Load() != 1 || result.Details["attempts"] != 1 {
+		t.Errorf("Expected 1 attempt, got %d requests and details %v", requests.Load(), result.Details)
+	}
+}
+
+func TestHTTPChecker_RetriesTransientFailure(t *testing.T) {
+	server, requests := flakyServer(1)
+	defer server.Close()
+
+	checker := &HTTPChecker{CheckName: "http_test", URL: server.URL, Attempts: 3, Backoff: 10 * time.Millisecond}
+	result := checker.Check()
+
+	if result.Status != StatusWarn {
+		t.Errorf("Expected pass after retry to be %s, got %s (%s)", StatusWarn, result.Status, result.Message)
+	}
+	if result.Details["attempts"] != 2 {
+		t.Errorf("Expected 2 attempts, got %v", result.Details["attempts"])
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestHTTPChecker_PassOnFirstAttempt(t *testing.T) {
+	server, requests := flakyServer(0)
+	defer server.Close()
+
+	checker := &HTTPChecker{CheckName: "http_test", URL: server.URL, Attempts: 3, Backoff: 10 * time.Millisecond}
+	result := checker.Check()
+
+	if result.Status != StatusPass {
+		t.Errorf("Expected status %s, got %s (%s)", StatusPass, result.Status, result.Message)
+	}
+	if result.Details["attempts"] != 1 || requests.Load() != 1 {
+		t.Errorf("Expected 1 attempt, got %v (%d requests)", result.Details["attempts"], requests.Load())
+	}
+}
+
+func TestHTTPChecker_FailsAfterConsecutiveFailures(t *testing.T) {
+	server, requests := flakyServer(5)
+	defer server.Close()
+
+	checker := &HTTPChecker{CheckName: "http_test", URL: server.URL, Attempts: 3, Backoff: 10 * time.Millisecond}
+	result := checker.Check()
+
+	if result.Status != StatusFail {
+		t.Errorf("Expected status %s, got %s", StatusFail, result.Status)
+	}
+	if result.Details["attempts"] != 3 || requests.Load() != 3 {
+		t.Errorf("Expected 3 attempts, got %v (%d requests)", result.Details["attempts"], requests.Load())
+	}
+}
+
+func TestHTTPChecker_StopsRetryingWhenContextDone(t *testing.T) {
+	server, requests := flakyServer(5)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	checker := &HTTPChecker{CheckName: "http_test", URL: server.URL, Attempts: 5, Backoff: time.Second}
+
+	start := time.Now()
+	result := checker.CheckContext(ctx)
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected retries to stop with the context, took %s", elapsed)
+	}
+	if result.Status != StatusFail || requests.Load() != 1 {
+		t.Errorf("Expected a single failed attempt, got %s after %d requests", result.Status, requests.Load())
+	}
+}
@@ -12,6 +12,10 @@ import (
 type MetricsChecker struct {
 	CheckName string
 	Endpoints []MetricEndpoint
+	// FastThresholdMS and SlowThresholdMS classify response times; zero
+	// means 100ms and 1s
+	FastThresholdMS int64
+	SlowThresholdMS int64
 }
 
 // MetricEndpoint represents a service endpoint to measure
@@ -48,10 +52,14 @@ func (c *MetricsChecker) Check() CheckResult {
 	fastEndpoints := []string{}
 
 	// Performance thresholds
-	const (
-		fastThreshold = 100  // < 100ms is fast
-		slowThreshold = 1000 // > 1s is slow
-	)
+	fastThreshold := int64(100) // < 100ms is fast
+	if c.FastThresholdMS > 0 {
+		fastThreshold = c.FastThresholdMS
+	}
+	slowThreshold := int64(1000) // > 1s is slow
+	if c.SlowThresholdMS > 0 {
+		slowThreshold = c.SlowThresholdMS
+	}
 
 	for _, endpoint := range c.Endpoints {
 		metric := c.measureEndpoint(endpoint)
@@ -80,7 +88,7 @@ func (c *MetricsChecker) Check() CheckResult {
 	switch {
 	case len(slowEndpoints) > len(c.Endpoints)/2:
 		result.Status = StatusWarn
-		result.Message = fmt.Sprintf("Performance degraded: %d/%d endpoints slow (>1s)", len(slowEndpoints), len(c.Endpoints))
+		result.Message = fmt.Sprintf("Performance degraded: %d/%d endpoints slow (>%dms)", len(slowEndpoints), len(c.Endpoints), slowThreshold)
 	case len(slowEndpoints) > 0:
 		result.Status = StatusWarn
 		result.Message = fmt.Sprintf("Some slow endpoints detected: avg %dms", avgTime)
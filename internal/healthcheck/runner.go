@@ -1,20 +1,29 @@
 package healthcheck
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"runtime"
+	"sort"
 	"time"
 )
 
+// DefaultCheckTimeout bounds how long a single check may run
+const DefaultCheckTimeout = 10 * time.Second
+
 // Runner executes health checks and generates reports
 type Runner struct {
 	Checkers []Checker
+	// Timeout bounds each check; zero means DefaultCheckTimeout
+	Timeout time.Duration
 }
 
 // NewRunner creates a new health check runner
 func NewRunner() *Runner {
 	return &Runner{
 		Checkers: []Checker{},
+		Timeout:  DefaultCheckTimeout,
 	}
 }
 
@@ -25,6 +34,12 @@ func (r *Runner) AddChecker(checker Checker) {
 
 // Run executes all health checks and returns a report
 func (r *Runner) Run() HealthReport {
+	return r.RunContext(context.Background())
+}
+
+// RunContext executes all health checks concurrently, each bounded by the
+// runner timeout, and returns a report with checks sorted by name.
+func (r *Runner) RunContext(ctx context.Context) HealthReport {
 	start := time.Now()
 
 	report := HealthReport{
@@ -34,10 +49,18 @@ func (r *Runner) Run() HealthReport {
 	}
 
 	// Run all checks
+	results := make(chan CheckResult, len(r.Checkers))
 	for _, checker := range r.Checkers {
-		result := checker.Check()
-		report.Checks = append(report.Checks, result)
+		go func(checker Checker) {
+			results <- r.runCheck(ctx, checker)
+		}(checker)
 	}
+	for range r.Checkers {
+		report.Checks = append(report.Checks, <-results)
+	}
+	sort.SliceStable(report.Checks, func(i, j int) bool {
+		return report.Checks[i].Name < report.Checks[j].Name
+	})
 
 	// Calculate summary
 	report.Summary = calculateSummary(report.Checks)
@@ -50,6 +73,43 @@ func (r *Runner) Run() HealthReport {
 	return report
 }
 
+// runCheck runs one check, failing it if it does not finish within the
+// runner timeout. A timed-out check keeps running in the background, unless
+// it implements ContextChecker and honors cancellation.
+func (r *Runner) runCheck(ctx context.Context, checker Checker) CheckResult {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan CheckResult, 1)
+	go func() {
+		if cc, ok := checker.(ContextChecker); ok {
+			done <- cc.CheckContext(ctx)
+			return
+		}
+		done <- checker.Check()
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		return CheckResult{
+			Name:      checker.Name(),
+			Status:    StatusFail,
+			Message:   fmt.Sprintf("Check timed out after %s", timeout),
+			Error:     ctx.Err().Error(),
+			Duration:  time.Since(start),
+			Timestamp: start,
+			Details:   map[string]interface{}{"timeout": true},
+		}
+	}
+}
+
 // calculateSummary generates aggregate statistics
 func calculateSummary(checks []CheckResult) Summary {
 	summary := Summary{
@@ -1,6 +1,7 @@
 package healthcheck
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -28,6 +29,36 @@ func (m *MockChecker) Check() CheckResult {
 	}
 }
 
+// SlowChecker is a test checker that takes delay to complete
+type SlowChecker struct {
+	name  string
+	delay time.Duration
+}
+
+func (s *SlowChecker) Name() string {
+	return s.name
+}
+
+func (s *SlowChecker) Check() CheckResult {
+	time.Sleep(s.delay)
+	return CheckResult{Name: s.name, Status: StatusPass, Timestamp: time.Now()}
+}
+
+// ContextSlowChecker stops when its context is canceled
+type ContextSlowChecker struct {
+	SlowChecker
+	canceled chan struct{}
+}
+
+func (s *ContextSlowChecker) CheckContext(ctx context.Context) CheckResult {
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		close(s.canceled)
+	}
+	return CheckResult{Name: s.name, Status: StatusFail, Error: "canceled", Timestamp: time.Now()}
+}
+
 func TestRunnerWithNoCheckers(t *testing.T) {
 	runner := NewRunner()
 	report := runner.Run()
@@ -174,3 +205,75 @@ func TestCalculateSummary(t *testing.T) {
 		t.Errorf("Expected 1 unknown, got %d", summary.Unknown)
 	}
 }
+
+func TestRunnerRunsChecksConcurrently(t *testing.T) {
+	runner := NewRunner()
+	for _, name := range []string{"c", "a", "b"} {
+		runner.AddChecker(&SlowChecker{name: name, delay: 100 * time.Millisecond})
+	}
+
+	start := time.Now()
+	report := runner.Run()
+	elapsed := time.Since(start)
+
+	if elapsed >= 250*time.Millisecond {
+		t.Errorf("Expected checks to run concurrently, took %s", elapsed)
+	}
+	if report.Summary.Passed != 3 {
+		t.Errorf("Expected 3 passed, got %d", report.Summary.Passed)
+	}
+	for i, name := range []string{"a", "b", "c"} {
+		if report.Checks[i].Name != name {
+			t.Errorf("Expected check %d to be %s, got %s", i, name, report.Checks[i].Name)
+		}
+	}
+}
+
+func TestRunnerTimesOutSlowCheck(t *testing.T) {
+	runner := NewRunner()
+	runner.Timeout = 50 * time.Millisecond
+	runner.AddChecker(&SlowChecker{name: "slow", delay: 5 * time.Second})
+	runner.AddChecker(&MockChecker{name: "fast", status: StatusPass})
+
+	start := time.Now()
+	report := runner.Run()
+	elapsed := time.Since(start)
+
+	if elapsed >= time.Second {
+		t.Errorf("Expected run to be bounded by the check timeout, took %s", elapsed)
+	}
+	if report.Status != StatusFail {
+		t.Errorf("Expected status %s, got %s", StatusFail, report.Status)
+	}
+	if report.Checks[0].Name != "fast" || report.Checks[0].Status != StatusPass {
+		t.Errorf("Expected fast check to pass, got %+v", report.Checks[0])
+	}
+	slow := report.Checks[1]
+	if slow.Name != "slow" || slow.Status != StatusFail {
+		t.Errorf("Expected slow check to fail, got %+v", slow)
+	}
+	if slow.Details["timeout"] != true {
+		t.Errorf("Expected slow check to be marked as timed out, got %v", slow.Details)
+	}
+}
+
+func TestRunnerCancelsContextChecker(t *testing.T) {
+	checker := &ContextSlowChecker{
+		SlowChecker: SlowChecker{name: "slow", delay: 5 * time.Second},
+		canceled:    make(chan struct{}),
+	}
+	runner := NewRunner()
+	runner.Timeout = 50 * time.Millisecond
+	runner.AddChecker(checker)
+
+	report := runner.Run()
+
+	if report.Checks[0].Details["timeout"] != true {
+		t.Errorf("Expected check to be reported as timed out, got %+v", report.Checks[0])
+	}
+	select {
+	case <-checker.canceled:
+	case <-time.After(time.Second):
+		t.Error("Expected the check context to be canceled")
+	}
+}
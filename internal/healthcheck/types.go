@@ -1,6 +1,9 @@
 package healthcheck
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // CheckResult represents the result of a single health check
 type CheckResult struct {
@@ -56,3 +59,10 @@ type Checker interface {
 	Name() string
 	Check() CheckResult
 }
+
+// ContextChecker is implemented by checkers that can stop early when the
+// runner's per-check timeout expires
+type ContextChecker interface {
+	Checker
+	CheckContext(ctx context.Context) CheckResult
+}
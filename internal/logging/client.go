@@ -8,13 +8,46 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Buffering and retry defaults for Enqueue and Flush
+const (
+	DefaultMaxBuffer     = 1000
+	DefaultRetryAttempts = 3
+	DefaultRetryDelay    = 200 * time.Millisecond
+
+	// DefaultCloseTimeout bounds the final flush on shutdown when the
+	// caller's context has no deadline of its own
+	DefaultCloseTimeout = 5 * time.Second
 )
 
-// Client sends logs to the logging service via HTTP.
+// Client sends logs to the logging service via HTTP. Post sends one payload
+// synchronously; Enqueue buffers payloads for Flush (or Start) to send in the
+// background. The buffer is bounded: when the logs service is down and it
+// fills up, the oldest payloads are dropped and counted rather than blocking
+// callers or growing without limit.
 type Client struct {
 	httpClient *http.Client
 	endpoint   string
+
+	mu            sync.Mutex
+	buffer        []map[string]interface{}
+	maxBuffer     int
+	retryAttempts int
+	retryDelay    time.Duration
+	onFailure     func(err error, pending int)
+	dropped       atomic.Uint64
+	flushing      sync.Mutex
+
+	done      chan struct{} // Closed by Close to stop the Start goroutine
+	stopped   chan struct{} // Closed when the Start goroutine has exited
+	cancelRun context.CancelFunc
+	closeOnce sync.Once
 }
 
 // NewClient creates a new logging client that posts to the provided endpoint.
@@ -24,9 +57,44 @@ func NewClient(endpoint string) *Client {
 		httpClient: &http.Client{
 			Timeout: 5 * time.Second,
 		},
+		maxBuffer:     DefaultMaxBuffer,
+		retryAttempts: DefaultRetryAttempts,
+		retryDelay:    DefaultRetryDelay,
+		done:          make(chan struct{}),
+	}
+}
+
+// SetMaxBuffer caps how many payloads Enqueue holds while waiting to be sent
+func (c *Client) SetMaxBuffer(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n > 0 {
+		c.maxBuffer = n
+		c.trimLocked()
 	}
 }
 
+// SetRetry sets how many times Flush tries each payload and the delay before
+// the first retry, which doubles after each failure
+func (c *Client) SetRetry(attempts int, initialDelay time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if attempts > 0 {
+		c.retryAttempts = attempts
+	}
+	if initialDelay > 0 {
+		c.retryDelay = initialDelay
+	}
+}
+
+// SetFailureHandler registers fn to be called when a Flush gives up, with
+// the error and the number of payloads still buffered
+func (c *Client) SetFailureHandler(fn func(err error, pending int)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onFailure = fn
+}
+
 // Post sends a JSON payload to the logs service. payload will be marshaled to JSON.
 func (c *Client) Post(ctx context.Context, data map[string]interface{}) error {
 	if c == nil {
@@ -56,3 +124,166 @@ func (c *Client) Post(ctx context.Context, data map[string]interface{}) error {
 	}
 	return nil
 }
+
+// Enqueue buffers a payload for the next Flush without blocking. When the
+// buffer is full the oldest payload is dropped.
+func (c *Client) Enqueue(data map[string]interface{}) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buffer = append(c.buffer, data)
+	c.trimLocked()
+}
+
+// trimLocked drops the oldest payloads beyond maxBuffer. c.mu must be held.
+func (c *Client) trimLocked() {
+	if over := len(c.buffer) - c.maxBuffer; over > 0 {
+		c.buffer = append(c.buffer[:0:0], c.buffer[over:]...)
+		c.dropped.Add(uint64(over))
+	}
+}
+
+// Flush sends buffered payloads in order, retrying each with backoff. When a
+// payload still fails it and everything after it go back to the front of
+// the buffer (subject to the cap), the failure handler is called and the
+// error returned.
+func (c *Client) Flush(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	c.flushing.Lock()
+	defer c.flushing.Unlock()
+
+	c.mu.Lock()
+	pending := c.buffer
+	c.buffer = nil
+	c.mu.Unlock()
+
+	for i, data := range pending {
+		if err := c.postWithRetry(ctx, data); err != nil {
+			c.mu.Lock()
+			c.buffer = append(pending[i:len(pending):len(pending)], c.buffer...)
+			c.trimLocked()
+			onFailure, remaining := c.onFailure, len(c.buffer)
+			c.mu.Unlock()
+
+			if onFailure != nil {
+				onFailure(err, remaining)
+			}
+			return fmt.Errorf("flush logs: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) postWithRetry(ctx context.Context, data map[string]interface{}) error {
+	c.mu.Lock()
+	attempts, delay := c.retryAttempts, c.retryDelay
+	c.mu.Unlock()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = c.Post(ctx, data); err == nil || attempt >= attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// Start flushes the buffer every interval until ctx is cancelled or Close is
+// called. On cancellation it makes a final flush bounded by
+// DefaultCloseTimeout; Close does its own final flush under the caller's
+// deadline. Start must be called at most once.
+func (c *Client) Start(ctx context.Context, interval time.Duration) {
+	runCtx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	c.mu.Lock()
+	c.stopped, c.cancelRun = stopped, cancel
+	c.mu.Unlock()
+
+	go func() {
+		defer close(stopped)
+		defer cancel()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ctx.Done():
+				flushCtx, cancel := context.WithTimeout(context.Background(), DefaultCloseTimeout)
+				_ = c.Flush(flushCtx) //nolint:errcheck // failure handler has been told
+				cancel()
+				return
+			case <-ticker.C:
+				_ = c.Flush(runCtx) //nolint:errcheck // failure handler has been told
+			}
+		}
+	}()
+}
+
+// Close stops the Start goroutine, waits for it to exit and flushes what is
+// left. ctx bounds the whole shutdown; without a deadline it is capped at
+// DefaultCloseTimeout. Calling Close again is a no-op.
+func (c *Client) Close(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultCloseTimeout)
+		defer cancel()
+	}
+
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.mu.Lock()
+		stopped, cancelRun := c.stopped, c.cancelRun
+		c.mu.Unlock()
+		if stopped != nil {
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				// Abandon the flush in progress rather than overrun the deadline
+				cancelRun()
+				<-stopped
+			}
+		}
+		err = c.Flush(ctx)
+	})
+	return err
+}
+
+// Buffered returns the number of payloads waiting to be sent
+func (c *Client) Buffered() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.buffer)
+}
+
+// Dropped returns how many payloads have been dropped because the buffer was full
+func (c *Client) Dropped() uint64 {
+	return c.dropped.Load()
+}
+
+// RegisterMetrics exposes the buffer size and drop count to Prometheus
+func (c *Client) RegisterMetrics(reg prometheus.Registerer) {
+	reg.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "logging_client_buffered",
+			Help: "Log payloads waiting to be sent to the logs service.",
+		}, func() float64 { return float64(c.Buffered()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "logging_client_dropped_total",
+			Help: "Log payloads dropped because the buffer was full.",
+		}, func() float64 { return float64(c.Dropped()) }),
+	)
+}
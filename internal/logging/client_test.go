@@ -2,10 +2,14 @@ package logging
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
+
+	"go.uber.org/goleak"
 )
 
 // TestClient_Post verifies that Client.Post succeeds on 2xx responses and
@@ -45,3 +49,150 @@ func TestClient_Post_SuccessAndFailure(t *testing.T) {
 		}
 	})
 }
+
+// downEndpoint returns the URL of a logs service that is no longer listening
+func downEndpoint() string {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+func TestClient_Enqueue_DropsOldestWhenFull(t *testing.T) {
+	c := NewClient(downEndpoint())
+	c.SetMaxBuffer(5)
+	c.SetRetry(2, time.Millisecond)
+	var failures, lastPending int
+	c.SetFailureHandler(func(err error, pending int) {
+		failures++
+		lastPending = pending
+	})
+
+	for i := 0; i < 20; i++ {
+		c.Enqueue(map[string]interface{}{"seq": i})
+	}
+	if got := c.Buffered(); got != 5 {
+		t.Fatalf("buffer grew past the cap: %d entries", got)
+	}
+	if got := c.Dropped(); got != 15 {
+		t.Fatalf("expected 15 drops, got %d", got)
+	}
+
+	if err := c.Flush(context.Background()); err == nil {
+		t.Fatal("expected flush to a down endpoint to fail")
+	}
+	if failures != 1 || lastPending != 5 {
+		t.Fatalf("expected one failure callback with 5 pending, got %d callbacks, %d pending", failures, lastPending)
+	}
+
+	c.Enqueue(map[string]interface{}{"seq": 20})
+	if got := c.Buffered(); got != 5 {
+		t.Fatalf("buffer grew past the cap after a failed flush: %d entries", got)
+	}
+	if got := c.Dropped(); got != 16 {
+		t.Fatalf("expected 16 drops, got %d", got)
+	}
+}
+
+func TestClient_Flush_RetriesAndSendsInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var received []float64
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable) // Recovers on retry
+			return
+		}
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received = append(received, payload["seq"].(float64))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	c.SetRetry(3, time.Millisecond)
+	for i := 0; i < 3; i++ {
+		c.Enqueue(map[string]interface{}{"seq": i})
+	}
+	if err := c.Flush(context.Background()); err != nil {
+		t.Fatalf("expected flush to succeed after a retry, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 3 || received[0] != 0 || received[1] != 1 || received[2] != 2 {
+		t.Fatalf("expected payloads 0,1,2 in order, got %v", received)
+	}
+	if c.Buffered() != 0 || c.Dropped() != 0 {
+		t.Fatalf("expected empty buffer and no drops, got %d buffered, %d dropped", c.Buffered(), c.Dropped())
+	}
+}
+
+func TestClient_Close_FlushesRemainingAndStopsGoroutine(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent()) // Runs after the server is closed
+
+	var mu sync.Mutex
+	received := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received++
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	c.Start(context.Background(), time.Hour) // Only Close will flush
+	for i := 0; i < 3; i++ {
+		c.Enqueue(map[string]interface{}{"seq": i})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := c.Close(ctx); err != nil {
+		t.Fatalf("expected close to flush cleanly, got %v", err)
+	}
+	if err := c.Close(ctx); err != nil {
+		t.Fatalf("expected second close to be a no-op, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if received != 3 || c.Buffered() != 0 {
+		t.Fatalf("expected 3 payloads sent and none buffered, got %d sent, %d buffered", received, c.Buffered())
+	}
+}
+
+func TestClient_Close_BoundedByDeadline(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := NewClient(srv.URL)
+	c.SetRetry(1, time.Millisecond)
+	c.Start(context.Background(), 10*time.Millisecond)
+	c.Enqueue(map[string]interface{}{"event": "stuck"})
+	time.Sleep(30 * time.Millisecond) // Let the ticker start a flush that hangs
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := c.Close(ctx); err == nil {
+		t.Fatal("expected close to report the undelivered payload")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected close to respect its deadline, took %v", elapsed)
+	}
+	if c.Buffered() != 1 {
+		t.Fatalf("expected the payload to stay buffered, got %d", c.Buffered())
+	}
+}
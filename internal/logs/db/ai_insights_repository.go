@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/lib/pq"
+
 	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
 )
 
@@ -114,3 +116,85 @@ func (r *AIInsightsRepository) Upsert(ctx context.Context, insight *logs_models.
 
 	return &result, nil
 }
+
+// GetCorrelated retrieves a cached correlated insight by its cache key.
+func (r *AIInsightsRepository) GetCorrelated(ctx context.Context, cacheKey string) (*logs_models.CorrelatedInsight, error) {
+	query := `
+		SELECT id, cache_key, log_ids, analysis, COALESCE(root_cause, ''), suggestions, model_used, truncated, generated_at
+		FROM logs.ai_correlated_insights
+		WHERE cache_key = $1
+	`
+
+	var insight logs_models.CorrelatedInsight
+	var suggestionsJSON []byte
+
+	err := r.db.QueryRowContext(ctx, query, cacheKey).Scan(
+		&insight.ID,
+		&insight.CacheKey,
+		pq.Array(&insight.LogIDs),
+		&insight.Analysis,
+		&insight.RootCause,
+		&suggestionsJSON,
+		&insight.ModelUsed,
+		&insight.Truncated,
+		&insight.GeneratedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not cached (not an error)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query correlated insight: %w", err)
+	}
+
+	if len(suggestionsJSON) > 0 {
+		if err := json.Unmarshal(suggestionsJSON, &insight.Suggestions); err != nil {
+			return nil, fmt.Errorf("failed to parse suggestions: %w", err)
+		}
+	}
+
+	return &insight, nil
+}
+
+// UpsertCorrelated stores a correlated insight, replacing any cached result
+// for the same key.
+func (r *AIInsightsRepository) UpsertCorrelated(ctx context.Context, insight *logs_models.CorrelatedInsight) (*logs_models.CorrelatedInsight, error) {
+	suggestionsJSON, err := json.Marshal(insight.Suggestions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal suggestions: %w", err)
+	}
+
+	query := `
+		INSERT INTO logs.ai_correlated_insights (cache_key, log_ids, analysis, root_cause, suggestions, model_used, truncated, generated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (cache_key)
+		DO UPDATE SET
+			log_ids = EXCLUDED.log_ids,
+			analysis = EXCLUDED.analysis,
+			root_cause = EXCLUDED.root_cause,
+			suggestions = EXCLUDED.suggestions,
+			model_used = EXCLUDED.model_used,
+			truncated = EXCLUDED.truncated,
+			generated_at = EXCLUDED.generated_at
+		RETURNING id
+	`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		insight.CacheKey,
+		pq.Array(insight.LogIDs),
+		insight.Analysis,
+		insight.RootCause,
+		suggestionsJSON,
+		insight.ModelUsed,
+		insight.Truncated,
+		insight.GeneratedAt,
+	).Scan(&insight.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert correlated insight: %w", err)
+	}
+
+	return insight, nil
+}
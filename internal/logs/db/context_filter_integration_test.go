@@ -0,0 +1,83 @@
+package logs_db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupContextFilterDB starts Postgres and creates the logs.entries table.
+func setupContextFilterDB(t *testing.T) *sql.DB {
+	db, container := setupTestPostgres(t)
+	t.Cleanup(func() {
+		db.Close()
+		cleanupTestPostgres(t, container)
+	})
+
+	_, err := db.Exec(`
+		CREATE TABLE logs.entries (
+			id BIGSERIAL PRIMARY KEY,
+			service TEXT NOT NULL,
+			level TEXT NOT NULL,
+			message TEXT NOT NULL,
+			metadata JSONB,
+			correlation_id TEXT,
+			issue_type VARCHAR(50),
+			fingerprint VARCHAR(16),
+			deleted_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestLogRepository_Query_ContextFilters(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := setupContextFilterDB(t)
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	for _, md := range []map[string]interface{}{
+		{"user_id": 1000, "status_code": 200},
+		{"user_id": 1000, "status_code": 503},
+		{"user_id": 42, "status_code": 404},
+		{"status_code": "not-a-number"},
+	} {
+		_, err := repo.Save(ctx, &LogEntry{Service: "portal", Level: "info", Message: "request", Metadata: md, CreatedAt: time.Now()})
+		require.NoError(t, err)
+	}
+
+	page := PageOptions{Limit: 10}
+
+	t.Run("Equality", func(t *testing.T) {
+		entries, err := repo.Query(ctx, &QueryFilters{
+			Context: []ContextFilter{{Path: []string{"user_id"}, Op: "=", Value: "1000"}},
+		}, page)
+		require.NoError(t, err)
+		assert.Len(t, entries, 2)
+	})
+
+	t.Run("NumericRange", func(t *testing.T) {
+		entries, err := repo.Query(ctx, &QueryFilters{
+			Context: []ContextFilter{{Path: []string{"status_code"}, Op: ">=", Value: "400"}},
+		}, page)
+		require.NoError(t, err)
+		assert.Len(t, entries, 2, "non-numeric status_code must be skipped, not fail the query")
+	})
+
+	t.Run("MissingKeyReturnsZeroRows", func(t *testing.T) {
+		entries, err := repo.Query(ctx, &QueryFilters{
+			Context: []ContextFilter{{Path: []string{"tenant", "id"}, Op: "=", Value: "1"}},
+		}, page)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,49 @@
+package logs_db
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	ts := time.Date(2025, 11, 12, 10, 30, 0, 123456000, time.UTC)
+	token := Cursor{CreatedAt: ts, ID: 42}.Encode()
+
+	decoded, err := DecodeCursor(token)
+	require.NoError(t, err)
+	assert.True(t, ts.Equal(decoded.CreatedAt))
+	assert.Equal(t, int64(42), decoded.ID)
+}
+
+func TestCursor_SameTimestampDifferentIDs(t *testing.T) {
+	ts := time.Date(2025, 11, 12, 10, 30, 0, 0, time.UTC)
+
+	a := Cursor{CreatedAt: ts, ID: 1}.Encode()
+	b := Cursor{CreatedAt: ts, ID: 2}.Encode()
+
+	assert.NotEqual(t, a, b, "cursor must encode the id tiebreaker")
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "%%%"},
+		{"missing separator", base64.RawURLEncoding.EncodeToString([]byte("2025-11-12T10:30:00Z"))},
+		{"bad timestamp", base64.RawURLEncoding.EncodeToString([]byte("yesterday|5"))},
+		{"bad id", base64.RawURLEncoding.EncodeToString([]byte("2025-11-12T10:30:00Z|abc"))},
+		{"non-positive id", base64.RawURLEncoding.EncodeToString([]byte("2025-11-12T10:30:00Z|0"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeCursor(tt.token)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -65,6 +65,8 @@ func setupIntegrationDB(ctx context.Context, t *testing.T) *sql.DB {
 			level TEXT NOT NULL,
 			message TEXT NOT NULL,
 			metadata JSONB NOT NULL DEFAULT '{}',
+			correlation_id TEXT,
+			deleted_at TIMESTAMPTZ,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		)
 	`)
@@ -0,0 +1,186 @@
+package logs_db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRepository_SoftDeleteMatching(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := setupNeighborsDB(t)
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	base := time.Date(2025, 11, 25, 10, 0, 0, 0, time.UTC)
+	for _, e := range []struct {
+		project int
+		level   string
+		at      time.Time
+	}{
+		{3, "DEBUG", base},
+		{3, "DEBUG", base.Add(time.Minute)},
+		{3, "ERROR", base.Add(2 * time.Minute)},
+		{3, "DEBUG", base.Add(time.Hour)},
+		{4, "DEBUG", base}, // Another project
+	} {
+		_, err := db.Exec(`
+			INSERT INTO logs.entries (project_id, service, level, message, metadata, created_at)
+			VALUES ($1, 'orders', $2, 'msg', '{}', $3)`, e.project, e.level, e.at)
+		require.NoError(t, err)
+	}
+
+	filters := &QueryFilters{Level: "DEBUG", To: base.Add(30 * time.Minute)}
+
+	count, err := repo.CountMatching(ctx, 3, filters)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	deleted, err := repo.SoftDeleteMatching(ctx, 3, filters)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted, "a filtered delete removes what the dry run counted")
+
+	var stored int64
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM logs.entries`).Scan(&stored))
+	assert.Equal(t, int64(5), stored, "soft-deleted rows stay in the table")
+
+	entries, err := repo.Query(ctx, &QueryFilters{Service: "orders"}, PageOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, entries, 3, "soft-deleted rows are hidden from queries")
+	count, err = repo.CountMatching(ctx, 3, filters)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// Only the project's entries are deleted, even with no other filter
+	deleted, err = repo.SoftDeleteMatching(ctx, 3, &QueryFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	count, err = repo.CountMatching(ctx, 4, &QueryFilters{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestLogRepository_TrashRestoreAndPurge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := setupNeighborsDB(t)
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	now := time.Now()
+	grace := 7 * 24 * time.Hour
+	for _, e := range []struct {
+		message   string
+		deletedAt interface{}
+	}{
+		{"live", nil},
+		{"deleted an hour ago", now.Add(-time.Hour)},
+		{"also deleted an hour ago", now.Add(-time.Hour)},
+		{"deleted yesterday", now.Add(-24 * time.Hour)},
+		{"past the grace period", now.Add(-8 * 24 * time.Hour)},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO logs.entries (project_id, service, level, message, metadata, deleted_at)
+			VALUES (3, 'orders', 'INFO', $1, '{}', $2)`, e.message, e.deletedAt)
+		require.NoError(t, err)
+	}
+	since := now.Add(-grace)
+
+	batches, err := repo.ListTrash(ctx, 3, since)
+	require.NoError(t, err)
+	require.Len(t, batches, 2, "expired deletions are not listed")
+	assert.Equal(t, int64(2), batches[0].Count)
+	assert.Equal(t, int64(1), batches[1].Count)
+
+	// Restore one deletion, then the rest within the grace period
+	restored, err := repo.RestoreDeleted(ctx, 3, since, &batches[1].DeletedAt)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), restored)
+	restored, err = repo.RestoreDeleted(ctx, 4, since, nil)
+	require.NoError(t, err)
+	assert.Zero(t, restored, "another project's trash")
+	restored, err = repo.RestoreDeleted(ctx, 3, since, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), restored, "the expired deletion can't be restored")
+
+	entries, err := repo.Query(ctx, &QueryFilters{}, PageOptions{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, entries, 4)
+
+	purged, err := repo.PurgeDeletedBatch(ctx, since, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	var stored int64
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM logs.entries`).Scan(&stored))
+	assert.Equal(t, int64(4), stored)
+}
+
+func TestLogEntryRepository_HidesSoftDeleted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, container := setupTestPostgres(t)
+	t.Cleanup(func() {
+		db.Close()
+		cleanupTestPostgres(t, container)
+	})
+	_, err := db.Exec(`
+		CREATE TABLE logs.entries (
+			id BIGSERIAL PRIMARY KEY,
+			user_id INT,
+			project_id INT,
+			service TEXT NOT NULL,
+			service_name TEXT,
+			level TEXT NOT NULL,
+			message TEXT NOT NULL,
+			metadata JSONB NOT NULL DEFAULT '{}',
+			tags TEXT[] DEFAULT '{}',
+			deleted_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	require.NoError(t, err)
+
+	now := time.Now()
+	var liveID, deletedID int64
+	require.NoError(t, db.QueryRow(`
+		INSERT INTO logs.entries (service, level, message) VALUES ('orders', 'ERROR', 'live') RETURNING id`).Scan(&liveID))
+	require.NoError(t, db.QueryRow(`
+		INSERT INTO logs.entries (service, level, message, deleted_at) VALUES ('orders', 'ERROR', 'deleted', $1) RETURNING id`,
+		now).Scan(&deletedID))
+
+	repo := NewLogEntryRepository(db)
+	ctx := context.Background()
+
+	entry, err := repo.GetByID(ctx, deletedID)
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+	entry, err = repo.GetByID(ctx, liveID)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+
+	recent, err := repo.GetRecent(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, recent, 1)
+	window, err := repo.FindInWindow(ctx, WindowFilter{Start: now.Add(-time.Hour), End: now.Add(time.Hour)}, 10)
+	require.NoError(t, err)
+	assert.Len(t, window, 1)
+	replay, err := repo.FindAfterID(ctx, 0, ReplayFilter{}, 10)
+	require.NoError(t, err)
+	assert.Len(t, replay, 1)
+
+	count, err := repo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	stats, err := repo.GetStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"ERROR": 1}, stats["by_level"])
+}
@@ -0,0 +1,260 @@
+package logs_db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createBatchEntriesTable creates the logs.entries columns CreateBatch writes.
+func createBatchEntriesTable(t *testing.T, db *sql.DB) {
+	t.Helper()
+	_, err := db.Exec(`
+		CREATE TABLE logs.entries (
+			id BIGSERIAL PRIMARY KEY,
+			project_id INT,
+			service_name VARCHAR(100),
+			level TEXT NOT NULL,
+			message TEXT NOT NULL,
+			metadata JSONB NOT NULL DEFAULT '{}',
+			tags TEXT[] DEFAULT '{}',
+			timestamp TIMESTAMP,
+			idempotency_key TEXT,
+			correlation_id TEXT,
+			issue_type VARCHAR(50),
+			fingerprint VARCHAR(16),
+			deleted_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE UNIQUE INDEX idx_entries_project_idempotency_key
+		ON logs.entries(project_id, idempotency_key)
+		WHERE idempotency_key IS NOT NULL;`)
+	require.NoError(t, err)
+}
+
+func TestLogEntryRepository_CreateBatch_IdempotentRetry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, container := setupTestPostgres(t)
+	defer cleanupTestPostgres(t, container)
+	defer db.Close()
+
+	createBatchEntriesTable(t, db)
+
+	repo := NewLogEntryRepository(db)
+	ctx := context.Background()
+	projectID := int64(7)
+	otherProjectID := int64(8)
+
+	batch := func(project *int64) []*logs_models.LogEntry {
+		return []*logs_models.LogEntry{
+			{ProjectID: project, Level: "info", Message: "one", Timestamp: time.Now(), IdempotencyKey: "req-1:0"},
+			{ProjectID: project, Level: "info", Message: "two", Timestamp: time.Now(), IdempotencyKey: "req-1:1"},
+			{ProjectID: project, Level: "error", Message: "three", Timestamp: time.Now(), IdempotencyKey: "req-1:2"},
+		}
+	}
+
+	first, err := repo.CreateBatch(ctx, batch(&projectID))
+	require.NoError(t, err)
+	assert.Equal(t, 3, first.Inserted)
+	assert.Equal(t, 0, first.Deduped)
+	assert.Equal(t, map[string]int{"INFO": 2, "ERROR": 1}, first.InsertedByLevel)
+
+	// Client retries the same batch after a timeout
+	second, err := repo.CreateBatch(ctx, batch(&projectID))
+	require.NoError(t, err)
+	assert.Equal(t, 0, second.Inserted)
+	assert.Equal(t, 3, second.Deduped)
+
+	// Keys are scoped per project
+	other, err := repo.CreateBatch(ctx, batch(&otherProjectID))
+	require.NoError(t, err)
+	assert.Equal(t, 3, other.Inserted)
+	assert.Equal(t, 0, other.Deduped)
+
+	// Entries without a key are never deduplicated
+	keyless := []*logs_models.LogEntry{{ProjectID: &projectID, Level: "info", Message: "no key", Timestamp: time.Now()}}
+	for i := 0; i < 2; i++ {
+		res, err := repo.CreateBatch(ctx, keyless)
+		require.NoError(t, err)
+		assert.Equal(t, 1, res.Inserted)
+		assert.Equal(t, 0, res.Deduped)
+	}
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM logs.entries`).Scan(&count))
+	assert.Equal(t, 8, count)
+}
+
+func TestLogEntryRepository_CreateBatch_AppliesProjectTagRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, container := setupTestPostgres(t)
+	defer cleanupTestPostgres(t, container)
+	defer db.Close()
+
+	createBatchEntriesTable(t, db)
+	_, err := db.Exec(`
+		CREATE TABLE logs.projects (id INT PRIMARY KEY, user_id INT NOT NULL);
+		INSERT INTO logs.projects (id, user_id) VALUES (3, 1), (4, 1);
+		CREATE TABLE logs.project_tag_rules (
+			id BIGSERIAL PRIMARY KEY,
+			project_id INT NOT NULL,
+			message_pattern TEXT NOT NULL DEFAULT '',
+			level TEXT NOT NULL DEFAULT '',
+			service TEXT NOT NULL DEFAULT '',
+			tag TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);`)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	projectID := int64(3)
+	tagRules := NewTagRuleRepository(db)
+	for _, rule := range []*logs_models.ProjectTagRule{
+		{ProjectID: projectID, Level: "error", Service: "payments", Tag: "critical"},
+		{ProjectID: projectID, MessagePattern: `(?i)timeout|deadline`, Tag: "slow"},
+		{ProjectID: projectID + 1, MessagePattern: `.*`, Tag: "other-project"},
+	} {
+		require.NoError(t, rule.Validate())
+		_, err := tagRules.Create(ctx, rule, 1)
+		require.NoError(t, err)
+	}
+
+	repo := NewLogEntryRepository(db)
+	repo.SetTagRules(tagRules)
+
+	_, err = repo.CreateBatch(ctx, []*logs_models.LogEntry{
+		{ProjectID: &projectID, ServiceName: "payments", Level: "ERROR", Message: "charge failed: upstream timeout", Timestamp: time.Now()},
+		{ProjectID: &projectID, ServiceName: "payments", Level: "INFO", Message: "charge ok", Timestamp: time.Now()},
+		{ProjectID: &projectID, ServiceName: "search", Level: "ERROR", Message: "index missing", Timestamp: time.Now()},
+	})
+	require.NoError(t, err)
+
+	tagsByMessage := map[string][]string{}
+	rows, err := db.Query(`SELECT message, tags FROM logs.entries`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var message string
+		var tags []string
+		require.NoError(t, rows.Scan(&message, pq.Array(&tags)))
+		tagsByMessage[message] = tags
+	}
+	require.NoError(t, rows.Err())
+
+	assert.Equal(t, []string{"critical", "slow"}, tagsByMessage["charge failed: upstream timeout"])
+	assert.Empty(t, tagsByMessage["charge ok"])
+	assert.Empty(t, tagsByMessage["index missing"])
+
+	// Edits invalidate the cached rules for the project
+	rules, err := tagRules.ListByProject(ctx, projectID, 1)
+	require.NoError(t, err)
+	_, err = tagRules.ListByProject(ctx, projectID, 2)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "rules are scoped to the project owner")
+	assert.ErrorIs(t, tagRules.Delete(ctx, projectID, rules[0].ID, 2), sql.ErrNoRows)
+	require.NoError(t, tagRules.Delete(ctx, projectID, rules[0].ID, 1))
+
+	_, err = repo.CreateBatch(ctx, []*logs_models.LogEntry{
+		{ProjectID: &projectID, ServiceName: "payments", Level: "ERROR", Message: "declined", Timestamp: time.Now()},
+	})
+	require.NoError(t, err)
+
+	var tags []string
+	require.NoError(t, db.QueryRow(`SELECT tags FROM logs.entries WHERE message = 'declined'`).Scan(pq.Array(&tags)))
+	assert.Empty(t, tags)
+}
+
+func TestLogEntryRepository_CreateBatch_ClassifiesIssueType(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, container := setupTestPostgres(t)
+	defer cleanupTestPostgres(t, container)
+	defer db.Close()
+
+	createBatchEntriesTable(t, db)
+
+	repo := NewLogEntryRepository(db)
+	repo.SetIssueClassifier(classifierFunc(func(message string) string {
+		if strings.Contains(message, "refused") {
+			return "db_connection"
+		}
+		return "unknown"
+	}))
+
+	_, err := repo.CreateBatch(context.Background(), []*logs_models.LogEntry{
+		{Level: "ERROR", Message: "dial tcp: connection refused", Timestamp: time.Now()},
+		{Level: "INFO", Message: "user signed in", Timestamp: time.Now()},
+		{Level: "ERROR", Message: "slow down", IssueType: "rate_limit", Timestamp: time.Now()},
+	})
+	require.NoError(t, err)
+
+	issueTypes := map[string]string{}
+	rows, err := db.Query(`SELECT message, issue_type FROM logs.entries`)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var message, issueType string
+		require.NoError(t, rows.Scan(&message, &issueType))
+		issueTypes[message] = issueType
+	}
+	require.NoError(t, rows.Err())
+
+	assert.Equal(t, map[string]string{
+		"dial tcp: connection refused": "db_connection",
+		"user signed in":               "unknown",
+		"slow down":                    "rate_limit",
+	}, issueTypes)
+}
+
+func TestLogEntryRepository_CreateBatch_CorrelationIDRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, container := setupTestPostgres(t)
+	defer cleanupTestPostgres(t, container)
+	defer db.Close()
+
+	createBatchEntriesTable(t, db)
+	// LogRepository reads the legacy service column
+	_, err := db.Exec(`ALTER TABLE logs.entries ADD COLUMN service TEXT NOT NULL DEFAULT 'external'`)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	projectID := int64(1)
+	_, err = NewLogEntryRepository(db).CreateBatch(ctx, []*logs_models.LogEntry{
+		{ProjectID: &projectID, Level: "info", Message: "checkout started", Timestamp: time.Now(), CorrelationID: "req-1"},
+		{ProjectID: &projectID, Level: "error", Message: "payment declined", Timestamp: time.Now(), CorrelationID: "req-1"},
+		{ProjectID: &projectID, Level: "info", Message: "other request", Timestamp: time.Now(), CorrelationID: "req-2"},
+		{ProjectID: &projectID, Level: "info", Message: "uncorrelated", Timestamp: time.Now()},
+	})
+	require.NoError(t, err)
+
+	entries, err := NewLogRepository(db).Query(ctx, &QueryFilters{CorrelationID: "req-1"}, PageOptions{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	messages := []string{entries[0].Message, entries[1].Message}
+	assert.ElementsMatch(t, []string{"checkout started", "payment declined"}, messages)
+	for _, e := range entries {
+		assert.Equal(t, "req-1", e.CorrelationID)
+	}
+
+	var nullCount int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM logs.entries WHERE correlation_id IS NULL`).Scan(&nullCount))
+	assert.Equal(t, 1, nullCount, "entries without an ID are stored as NULL")
+}
@@ -158,9 +158,21 @@ func ValidateLogEntryForCreate(entry *logs_models.LogEntry) error {
 	return nil
 }
 
+// TagRuleApplier adds rule-derived tags to entries before they are stored.
+type TagRuleApplier interface {
+	ApplyTagRules(ctx context.Context, entries []*logs_models.LogEntry) error
+}
+
+// IssueClassifier derives an entry's issue_type from its message at ingestion.
+type IssueClassifier interface {
+	Classify(message string) string
+}
+
 // LogEntryRepository handles CRUD operations for log entries.
 type LogEntryRepository struct {
-	db *sql.DB
+	db         *sql.DB
+	tagRules   TagRuleApplier
+	classifier IssueClassifier
 }
 
 // NewLogEntryRepository creates a new LogEntryRepository with the given database connection.
@@ -168,6 +180,26 @@ func NewLogEntryRepository(db *sql.DB) *LogEntryRepository {
 	return &LogEntryRepository{db: db}
 }
 
+// SetTagRules enables per-project tag rules for entries stored by CreateBatch.
+func (r *LogEntryRepository) SetTagRules(rules TagRuleApplier) {
+	r.tagRules = rules
+}
+
+// SetIssueClassifier enables issue_type classification for entries stored by
+// CreateBatch. Entries that already carry an issue type keep it.
+func (r *LogEntryRepository) SetIssueClassifier(classifier IssueClassifier) {
+	r.classifier = classifier
+}
+
+// issueType returns the issue_type to store for entry, NULL when there is
+// neither a given type nor a classifier.
+func issueType(classifier IssueClassifier, issueType, message string) sql.NullString {
+	if issueType == "" && classifier != nil {
+		issueType = classifier.Classify(message)
+	}
+	return sql.NullString{String: issueType, Valid: issueType != ""}
+}
+
 // queryLogEntries executes a query and returns scanned log entries.
 func (r *LogEntryRepository) queryLogEntries(ctx context.Context, query string, args ...interface{}) ([]logs_models.LogEntry, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -225,20 +257,43 @@ func (r *LogEntryRepository) Create(ctx context.Context, entry *logs_models.LogE
 	return entry, nil
 }
 
+// BatchInsertResult summarizes a CreateBatch call.
+type BatchInsertResult struct {
+	InsertedByLevel map[string]int // Rows actually written, per (uppercase) level
+	Inserted        int            // Rows actually written
+	Deduped         int            // Entries skipped because their idempotency key already existed
+}
+
 // CreateBatch inserts multiple log entries in a single optimized query.
 // This method is designed for the cross-repo logging batch endpoint and supports
 // project_id and service_name fields. It's 100x faster than individual inserts.
 //
+// Entries carrying an IdempotencyKey are deduplicated per project: if the key
+// was already ingested (e.g. a client retried after a timeout) the entry is
+// skipped and counted in Deduped instead of being inserted again.
+//
+// When tag rules are set, each entry's tags are extended with the tags of its
+// project's matching rules; the auto_generate_tags trigger still adds its own.
+// When an issue classifier is set, entries are stored with their issue_type.
+// Every entry is stored with the fingerprint of its message.
+//
 // Performance: 100 logs in ~50ms (vs 3000ms for individual inserts)
-func (r *LogEntryRepository) CreateBatch(ctx context.Context, entries []*logs_models.LogEntry) error {
+func (r *LogEntryRepository) CreateBatch(ctx context.Context, entries []*logs_models.LogEntry) (BatchInsertResult, error) {
 	if len(entries) == 0 {
-		return nil
+		return BatchInsertResult{}, nil
+	}
+
+	if r.tagRules != nil {
+		if err := r.tagRules.ApplyTagRules(ctx, entries); err != nil {
+			return BatchInsertResult{}, fmt.Errorf("db: failed to apply tag rules: %w", err)
+		}
 	}
 
 	// Build parameterized INSERT statement with multiple value rows
 	// Using a single query with multiple VALUES reduces network overhead and transaction cost
 	valueStrings := make([]string, len(entries))
-	valueArgs := make([]interface{}, 0, len(entries)*6) // 6 fields per entry
+	valueArgs := make([]interface{}, 0, len(entries)*11) // 11 fields per entry
+	keyed := 0
 
 	for i, entry := range entries {
 		// Prepare metadata as bytes
@@ -250,9 +305,26 @@ func (r *LogEntryRepository) CreateBatch(ctx context.Context, entries []*logs_mo
 		// Normalize level to uppercase
 		level := strings.ToUpper(entry.Level)
 
-		// Each entry requires 6 parameters: project_id, service_name, level, message, metadata, timestamp
-		valueStrings[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)",
-			i*6+1, i*6+2, i*6+3, i*6+4, i*6+5, i*6+6)
+		// Empty key means "no dedup" and is stored as NULL so it never conflicts
+		var idempotencyKey sql.NullString
+		if entry.IdempotencyKey != "" {
+			idempotencyKey = sql.NullString{String: entry.IdempotencyKey, Valid: true}
+			keyed++
+		}
+
+		var correlationID sql.NullString
+		if entry.CorrelationID != "" {
+			correlationID = sql.NullString{String: entry.CorrelationID, Valid: true}
+		}
+
+		tags := entry.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		// Each entry requires 11 parameters: project_id, service_name, level, message, metadata, tags, timestamp, idempotency_key, correlation_id, issue_type, fingerprint
+		valueStrings[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			i*11+1, i*11+2, i*11+3, i*11+4, i*11+5, i*11+6, i*11+7, i*11+8, i*11+9, i*11+10, i*11+11)
 
 		valueArgs = append(valueArgs,
 			entry.ProjectID,
@@ -260,29 +332,59 @@ func (r *LogEntryRepository) CreateBatch(ctx context.Context, entries []*logs_mo
 			level,
 			entry.Message,
 			metadataBytes,
+			pq.Array(tags),
 			entry.Timestamp,
+			idempotencyKey,
+			correlationID,
+			issueType(r.classifier, entry.IssueType, entry.Message),
+			logs_models.Fingerprint(entry.Message),
 		)
 	}
 
-	// Build query safely using parameterized placeholders (no SQL injection risk)
+	// Build query safely using parameterized placeholders (no SQL injection risk).
+	// RETURNING only yields rows that were really inserted, so conflicts skipped
+	// by ON CONFLICT DO NOTHING are not counted.
 	//nolint:gosec // All values are parameterized, no user input in query structure
 	query := fmt.Sprintf(`
-		INSERT INTO logs.entries (project_id, service_name, level, message, metadata, timestamp)
+		INSERT INTO logs.entries (project_id, service_name, level, message, metadata, tags, timestamp, idempotency_key, correlation_id, issue_type, fingerprint)
 		VALUES %s
+		ON CONFLICT (project_id, idempotency_key) WHERE idempotency_key IS NOT NULL DO NOTHING
+		RETURNING level, idempotency_key IS NOT NULL
 	`, strings.Join(valueStrings, ","))
 
-	_, err := r.db.ExecContext(ctx, query, valueArgs...)
+	rows, err := r.db.QueryContext(ctx, query, valueArgs...)
 	if err != nil {
-		return fmt.Errorf("db: batch insert failed: %w", err)
+		return BatchInsertResult{}, fmt.Errorf("db: batch insert failed: %w", err)
 	}
+	defer rows.Close()
 
-	return nil
+	result := BatchInsertResult{InsertedByLevel: make(map[string]int)}
+	insertedKeyed := 0
+	for rows.Next() {
+		var level string
+		var hadKey bool
+		if err := rows.Scan(&level, &hadKey); err != nil {
+			return BatchInsertResult{}, fmt.Errorf("db: batch insert scan failed: %w", err)
+		}
+		result.Inserted++
+		result.InsertedByLevel[level]++
+		if hadKey {
+			insertedKeyed++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return BatchInsertResult{}, fmt.Errorf("db: batch insert failed: %w", err)
+	}
+
+	result.Deduped = keyed - insertedKeyed
+	return result, nil
 }
 
-// GetByID retrieves a log entry by its ID.
+// GetByID retrieves a log entry by its ID. Soft-deleted entries are not
+// found, here or in the other reads below.
 func (r *LogEntryRepository) GetByID(ctx context.Context, id int64) (*logs_models.LogEntry, error) {
 	row := r.db.QueryRowContext(ctx,
-		`SELECT id, user_id, service, level, message, metadata, created_at FROM logs.entries WHERE id = $1`,
+		`SELECT id, user_id, service, level, message, metadata, created_at FROM logs.entries WHERE id = $1 AND deleted_at IS NULL`,
 		id,
 	)
 
@@ -301,7 +403,7 @@ func (r *LogEntryRepository) GetByID(ctx context.Context, id int64) (*logs_model
 // GetByService retrieves log entries filtered by service name.
 func (r *LogEntryRepository) GetByService(ctx context.Context, service string, limit, offset int) ([]logs_models.LogEntry, error) {
 	query := `SELECT id, user_id, service, level, message, metadata, created_at FROM logs.entries 
-	         WHERE service = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+	         WHERE service = $1 AND deleted_at IS NULL ORDER BY created_at DESC LIMIT $2 OFFSET $3`
 	entries, err := r.queryLogEntries(ctx, query, service, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("db: failed to query log entries by service: %w", err)
@@ -312,7 +414,7 @@ func (r *LogEntryRepository) GetByService(ctx context.Context, service string, l
 // GetByLevel retrieves log entries filtered by level.
 func (r *LogEntryRepository) GetByLevel(ctx context.Context, level string, limit, offset int) ([]logs_models.LogEntry, error) {
 	query := `SELECT id, user_id, service, level, message, metadata, created_at FROM logs.entries 
-	         WHERE level = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+	         WHERE level = $1 AND deleted_at IS NULL ORDER BY created_at DESC LIMIT $2 OFFSET $3`
 	entries, err := r.queryLogEntries(ctx, query, level, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("db: failed to query log entries by level: %w", err)
@@ -323,7 +425,7 @@ func (r *LogEntryRepository) GetByLevel(ctx context.Context, level string, limit
 // GetByUser retrieves log entries for a specific user.
 func (r *LogEntryRepository) GetByUser(ctx context.Context, userID int64, limit, offset int) ([]logs_models.LogEntry, error) {
 	query := `SELECT id, user_id, service, level, message, metadata, created_at FROM logs.entries 
-	         WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+	         WHERE user_id = $1 AND deleted_at IS NULL ORDER BY created_at DESC LIMIT $2 OFFSET $3`
 	entries, err := r.queryLogEntries(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("db: failed to query log entries by user: %w", err)
@@ -334,7 +436,7 @@ func (r *LogEntryRepository) GetByUser(ctx context.Context, userID int64, limit,
 // GetRecent retrieves the most recent log entries.
 func (r *LogEntryRepository) GetRecent(ctx context.Context, limit int) ([]logs_models.LogEntry, error) {
 	query := `SELECT id, user_id, service, level, message, metadata, created_at FROM logs.entries 
-	         ORDER BY created_at DESC LIMIT $1`
+	         WHERE deleted_at IS NULL ORDER BY created_at DESC LIMIT $1`
 	entries, err := r.queryLogEntries(ctx, query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("db: failed to query recent log entries: %w", err)
@@ -342,13 +444,136 @@ func (r *LogEntryRepository) GetRecent(ctx context.Context, limit int) ([]logs_m
 	return entries, nil
 }
 
+// WindowFilter selects log entries for FindInWindow. Empty fields match everything.
+type WindowFilter struct {
+	Start     time.Time
+	End       time.Time
+	ProjectID *int64
+	Service   string // Matches service or service_name
+	Level     string
+	// OwnerUserID limits entries to that user's projects; 0 is unscoped
+	OwnerUserID int
+}
+
+// FindInWindow returns up to limit entries created in [Start, End) that match
+// the filter, newest first. Service is reported from service_name when set,
+// so batch-ingested entries show the microservice that sent them.
+func (r *LogEntryRepository) FindInWindow(ctx context.Context, filter WindowFilter, limit int) ([]logs_models.LogEntry, error) {
+	conditions := []string{"created_at >= $1", "created_at < $2", notDeleted}
+	args := []interface{}{filter.Start, filter.End}
+
+	if filter.ProjectID != nil {
+		args = append(args, *filter.ProjectID)
+		conditions = append(conditions, fmt.Sprintf("project_id = $%d", len(args)))
+	}
+	if filter.Service != "" {
+		args = append(args, filter.Service)
+		conditions = append(conditions, fmt.Sprintf("(service = $%d OR service_name = $%d)", len(args), len(args)))
+	}
+	if filter.Level != "" {
+		args = append(args, strings.ToUpper(filter.Level))
+		conditions = append(conditions, fmt.Sprintf("UPPER(level) = $%d", len(args)))
+	}
+	if filter.OwnerUserID != 0 {
+		args = append(args, filter.OwnerUserID)
+		conditions = append(conditions, fmt.Sprintf(ownedProjectsFragment, len(args)))
+	}
+	args = append(args, limit)
+
+	//nolint:gosec // Conditions are fixed strings; all values are parameterized
+	query := fmt.Sprintf(`
+		SELECT id, COALESCE(user_id, 0), COALESCE(NULLIF(service_name, ''), service), level, message, metadata, created_at
+		FROM logs.entries
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	entries, err := r.queryLogEntries(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to query log entries in window: %w", err)
+	}
+	return entries, nil
+}
+
+// ReplayFilter selects log entries for FindAfterID. Empty fields match everything.
+type ReplayFilter struct {
+	Level   string
+	Service string // Matches service or service_name
+	Tag     string
+	// OwnerUserID limits entries to that user's projects; 0 is unscoped
+	OwnerUserID int
+}
+
+// FindAfterID returns up to limit entries with id > afterID that match the
+// filter, newest first. Used to replay logs a stream client missed while it
+// was disconnected.
+func (r *LogEntryRepository) FindAfterID(ctx context.Context, afterID int64, filter ReplayFilter, limit int) ([]logs_models.LogEntry, error) {
+	if r.db == nil {
+		return []logs_models.LogEntry{}, nil
+	}
+
+	conditions := []string{"id > $1", notDeleted}
+	args := []interface{}{afterID}
+
+	if filter.Level != "" {
+		args = append(args, strings.ToUpper(filter.Level))
+		conditions = append(conditions, fmt.Sprintf("UPPER(level) = $%d", len(args)))
+	}
+	if filter.Service != "" {
+		args = append(args, filter.Service)
+		conditions = append(conditions, fmt.Sprintf("(service = $%d OR service_name = $%d)", len(args), len(args)))
+	}
+	if filter.Tag != "" {
+		args = append(args, filter.Tag)
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(tags)", len(args)))
+	}
+	if filter.OwnerUserID != 0 {
+		args = append(args, filter.OwnerUserID)
+		conditions = append(conditions, fmt.Sprintf(ownedProjectsFragment, len(args)))
+	}
+	args = append(args, limit)
+
+	//nolint:gosec // Conditions are fixed strings; all values are parameterized
+	query := fmt.Sprintf(`
+		SELECT id, COALESCE(user_id, 0), service, COALESCE(service_name, ''), level, message, metadata,
+		       COALESCE(tags, '{}'), project_id, created_at
+		FROM logs.entries
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT $%d`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to query log entries after id: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []logs_models.LogEntry{}
+	for rows.Next() {
+		var entry logs_models.LogEntry
+		var projectID sql.NullInt64
+		if err := rows.Scan(&entry.ID, &entry.UserID, &entry.Service, &entry.ServiceName, &entry.Level,
+			&entry.Message, &entry.Metadata, pq.Array(&entry.Tags), &projectID, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("db: failed to scan log entry: %w", err)
+		}
+		if projectID.Valid {
+			entry.ProjectID = &projectID.Int64
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db: rows iteration error: %w", err)
+	}
+	return entries, nil
+}
+
 // GetStats returns statistics on log entries by level and service.
 func (r *LogEntryRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
 	levelCounts := make(map[string]int)
 	rows, err := r.db.QueryContext(ctx,
-		`SELECT level, COUNT(*) as count FROM logs.entries GROUP BY level`,
+		`SELECT level, COUNT(*) as count FROM logs.entries WHERE deleted_at IS NULL GROUP BY level`,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("db: failed to query level stats: %w", err)
@@ -377,7 +602,7 @@ func (r *LogEntryRepository) GetStats(ctx context.Context) (map[string]interface
 
 	serviceCounts := make(map[string]int)
 	rows, err = r.db.QueryContext(ctx,
-		`SELECT service, COUNT(*) as count FROM logs.entries GROUP BY service`,
+		`SELECT service, COUNT(*) as count FROM logs.entries WHERE deleted_at IS NULL GROUP BY service`,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("db: failed to query service stats: %w", err)
@@ -409,7 +634,7 @@ func (r *LogEntryRepository) GetStats(ctx context.Context) (map[string]interface
 // Count returns the total number of log entries.
 func (r *LogEntryRepository) Count(ctx context.Context) (int64, error) {
 	var count int64
-	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM logs.entries`).Scan(&count)
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM logs.entries WHERE deleted_at IS NULL`).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("db: failed to count log entries: %w", err)
 	}
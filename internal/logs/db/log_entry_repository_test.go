@@ -1,7 +1,9 @@
 package logs_db
 
 import (
+	"database/sql"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
@@ -148,3 +150,22 @@ func TestLogEntry_MetadataHandling(t *testing.T) {
 	assert.NotNil(t, entry.Metadata)
 	assert.Len(t, entry.Metadata, len(metadataJSON))
 }
+
+// classifierFunc adapts a function to IssueClassifier.
+type classifierFunc func(message string) string
+
+func (f classifierFunc) Classify(message string) string { return f(message) }
+
+func TestIssueType(t *testing.T) {
+	classifier := classifierFunc(func(message string) string {
+		if strings.Contains(message, "refused") {
+			return "db_connection"
+		}
+		return "unknown"
+	})
+
+	assert.Equal(t, sql.NullString{String: "db_connection", Valid: true}, issueType(classifier, "", "connection refused"))
+	assert.Equal(t, sql.NullString{String: "unknown", Valid: true}, issueType(classifier, "", "user signed in"))
+	assert.Equal(t, sql.NullString{String: "rate_limit", Valid: true}, issueType(classifier, "rate_limit", "connection refused"), "a given issue type is kept")
+	assert.Equal(t, sql.NullString{}, issueType(nil, "", "connection refused"), "stored as NULL without a classifier")
+}
@@ -0,0 +1,101 @@
+package logs_db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRepository_ListFingerprints(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, container := setupTestPostgres(t)
+	t.Cleanup(func() {
+		db.Close()
+		cleanupTestPostgres(t, container)
+	})
+
+	_, err := db.Exec(`
+		CREATE TABLE logs.entries (
+			id BIGSERIAL PRIMARY KEY,
+			service TEXT NOT NULL,
+			level TEXT NOT NULL,
+			message TEXT NOT NULL,
+			metadata JSONB,
+			issue_type VARCHAR(50),
+			fingerprint VARCHAR(16),
+			deleted_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	require.NoError(t, err)
+
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+	base := time.Date(2025, 11, 25, 10, 0, 0, 0, time.UTC)
+
+	save := func(service, level, message string, at time.Time) {
+		_, err := repo.Save(ctx, &LogEntry{Service: service, Level: level, Message: message, CreatedAt: at})
+		require.NoError(t, err)
+	}
+	save("orders", "ERROR", "order 981 not found", base)
+	save("orders", "ERROR", "order 12 not found", base.Add(time.Minute))
+	save("orders", "ERROR", "order 7 not found", base.Add(2*time.Minute))
+	save("payments", "ERROR", "card 4411 declined", base.Add(time.Minute))
+	save("orders", "WARN", "slow query took 812ms", base.Add(3*time.Minute))
+	// Outside the range
+	save("orders", "ERROR", "order 5 not found", base.Add(-time.Hour))
+	// Stored before fingerprints existed
+	_, err = db.Exec(`INSERT INTO logs.entries (service, level, message, created_at) VALUES ('orders', 'ERROR', 'order 1 not found', $1)`, base)
+	require.NoError(t, err)
+
+	q := logs_models.FingerprintQuery{From: base, To: base.Add(time.Hour), Limit: 10}
+
+	t.Run("GroupsNearDuplicates", func(t *testing.T) {
+		groups, err := repo.ListFingerprints(ctx, q)
+		require.NoError(t, err)
+		require.Len(t, groups, 3)
+
+		assert.Equal(t, logs_models.Fingerprint("order 1 not found"), groups[0].Fingerprint)
+		assert.Equal(t, int64(3), groups[0].Count)
+		assert.True(t, groups[0].FirstSeen.Equal(base))
+		assert.True(t, groups[0].LastSeen.Equal(base.Add(2*time.Minute)))
+		assert.Equal(t, "order 7 not found", groups[0].Message, "the most recent message represents the group")
+		assert.Equal(t, "ERROR", groups[0].Level)
+		assert.Equal(t, "orders", groups[0].Service)
+
+		// Single entries, most recently seen first
+		assert.Equal(t, "slow query took 812ms", groups[1].Message)
+		assert.Equal(t, "card 4411 declined", groups[2].Message)
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		filtered := q
+		filtered.Service = "payments"
+		groups, err := repo.ListFingerprints(ctx, filtered)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "card 4411 declined", groups[0].Message)
+
+		filtered = q
+		filtered.Level = "WARN"
+		groups, err = repo.ListFingerprints(ctx, filtered)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "slow query took 812ms", groups[0].Message)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		limited := q
+		limited.Limit = 1
+		groups, err := repo.ListFingerprints(ctx, limited)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, int64(3), groups[0].Count)
+	})
+}
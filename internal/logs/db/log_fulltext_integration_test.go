@@ -0,0 +1,89 @@
+package logs_db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRepository_Query_FullText(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, container := setupTestPostgres(t)
+	t.Cleanup(func() {
+		db.Close()
+		cleanupTestPostgres(t, container)
+	})
+
+	_, err := db.Exec(`
+		CREATE TABLE logs.entries (
+			id BIGSERIAL PRIMARY KEY,
+			service TEXT NOT NULL,
+			level TEXT NOT NULL,
+			message TEXT NOT NULL,
+			metadata JSONB,
+			correlation_id TEXT,
+			issue_type VARCHAR(50),
+			fingerprint VARCHAR(16),
+			deleted_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			message_tsv tsvector GENERATED ALWAYS AS (to_tsvector('english', coalesce(message, ''))) STORED
+		)`)
+	require.NoError(t, err)
+
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	for _, msg := range []string{
+		"database connection timeout after 30s",
+		"timeout waiting for database connection",
+		"connection established",
+		"request timeout",
+		"timeout: database connection timeout, connection dropped",
+	} {
+		_, err := repo.Save(ctx, &LogEntry{Service: "portal", Level: "error", Message: msg, CreatedAt: time.Now()})
+		require.NoError(t, err)
+	}
+
+	t.Run("MatchesAllTermsInAnyOrder", func(t *testing.T) {
+		entries, err := repo.Query(ctx, &QueryFilters{FullText: "connection timeout"}, PageOptions{Limit: 10})
+		require.NoError(t, err)
+
+		messages := make([]string, len(entries))
+		for i, e := range entries {
+			messages[i] = e.Message
+			assert.Greater(t, e.Score, 0.0)
+		}
+		assert.ElementsMatch(t, []string{
+			"database connection timeout after 30s",
+			"timeout waiting for database connection",
+			"timeout: database connection timeout, connection dropped",
+		}, messages)
+	})
+
+	t.Run("OrderedByRelevance", func(t *testing.T) {
+		entries, err := repo.Query(ctx, &QueryFilters{FullText: "connection timeout"}, PageOptions{Limit: 10})
+		require.NoError(t, err)
+		require.NotEmpty(t, entries)
+
+		assert.Equal(t, "timeout: database connection timeout, connection dropped", entries[0].Message)
+		for i := 1; i < len(entries); i++ {
+			assert.GreaterOrEqual(t, entries[i-1].Score, entries[i].Score)
+		}
+	})
+
+	t.Run("SubstringSearchStillAvailable", func(t *testing.T) {
+		entries, err := repo.Query(ctx, &QueryFilters{Search: "connection timeout"}, PageOptions{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		for _, e := range entries {
+			assert.Contains(t, e.Message, "connection timeout")
+			assert.Zero(t, e.Score)
+		}
+	})
+}
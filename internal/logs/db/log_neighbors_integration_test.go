@@ -0,0 +1,103 @@
+package logs_db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupNeighborsDB creates logs.entries with the service_name and project_id
+// columns GetNeighbors scopes by.
+func setupNeighborsDB(t *testing.T) *sql.DB {
+	db, container := setupTestPostgres(t)
+	t.Cleanup(func() {
+		db.Close()
+		cleanupTestPostgres(t, container)
+	})
+
+	_, err := db.Exec(`
+		CREATE TABLE logs.entries (
+			id BIGSERIAL PRIMARY KEY,
+			project_id INT,
+			service TEXT NOT NULL,
+			service_name TEXT,
+			level TEXT NOT NULL,
+			message TEXT NOT NULL,
+			metadata JSONB,
+			correlation_id TEXT,
+			deleted_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	require.NoError(t, err)
+	return db
+}
+
+func insertNeighbor(t *testing.T, db *sql.DB, service string, projectID int, message string, at time.Time) int64 {
+	var id int64
+	err := db.QueryRow(`
+		INSERT INTO logs.entries (project_id, service, level, message, metadata, created_at)
+		VALUES ($1, $2, 'INFO', $3, '{}', $4) RETURNING id`,
+		projectID, service, message, at).Scan(&id)
+	require.NoError(t, err)
+	return id
+}
+
+func neighborMessages(entries []*LogEntry) []string {
+	messages := make([]string, len(entries))
+	for i, e := range entries {
+		messages[i] = e.Message
+	}
+	return messages
+}
+
+func TestLogRepository_GetNeighbors(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := setupNeighborsDB(t)
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	base := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+	ids := make([]int64, 0, 5)
+	// m2 and m3 share a timestamp, so only id orders them
+	for i, at := range []time.Time{base, base.Add(time.Second), base.Add(2 * time.Second), base.Add(2 * time.Second), base.Add(3 * time.Second)} {
+		ids = append(ids, insertNeighbor(t, db, "portal", 1, "m"+string(rune('0'+i)), at))
+	}
+	insertNeighbor(t, db, "review", 1, "other service", base.Add(2*time.Second))
+	insertNeighbor(t, db, "portal", 2, "other project", base.Add(2*time.Second))
+
+	t.Run("Middle", func(t *testing.T) {
+		entries, err := repo.GetNeighbors(ctx, ids[2], 1, 1, true, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m1", "m2", "m3"}, neighborMessages(entries))
+	})
+
+	t.Run("OldestHasNoBefore", func(t *testing.T) {
+		entries, err := repo.GetNeighbors(ctx, ids[0], 3, 2, true, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m0", "m1", "m2"}, neighborMessages(entries))
+	})
+
+	t.Run("NewestHasNoAfter", func(t *testing.T) {
+		entries, err := repo.GetNeighbors(ctx, ids[4], 2, 3, true, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m2", "m3", "m4"}, neighborMessages(entries))
+	})
+
+	t.Run("OtherProjectsIncludedUnlessScoped", func(t *testing.T) {
+		entries, err := repo.GetNeighbors(ctx, ids[4], 10, 0, false, 0)
+		require.NoError(t, err)
+		assert.Len(t, entries, 6, "all portal entries, including project 2's")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := repo.GetNeighbors(ctx, 999999, 1, 1, false, 0)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
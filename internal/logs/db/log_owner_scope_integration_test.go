@@ -0,0 +1,69 @@
+package logs_db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRepository_Query_OwnerScope(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := setupNeighborsDB(t)
+	_, err := db.Exec(`
+		CREATE TABLE logs.projects (
+			id INT PRIMARY KEY,
+			user_id INT
+		);
+		INSERT INTO logs.projects (id, user_id) VALUES (1, 7), (2, 8), (3, NULL);`)
+	require.NoError(t, err)
+
+	for _, e := range []struct {
+		project interface{}
+		message string
+	}{
+		{1, "user 7's order failed"},
+		{2, "user 8's order failed"},
+		{3, "unclaimed order failed"},
+		{nil, "platform order failed"},
+	} {
+		_, err := db.Exec(`
+			INSERT INTO logs.entries (project_id, service, level, message, metadata)
+			VALUES ($1, 'orders', 'ERROR', $2, '{}')`, e.project, e.message)
+		require.NoError(t, err)
+	}
+
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+	messages := func(filters *QueryFilters) []string {
+		entries, err := repo.Query(ctx, filters, PageOptions{Limit: 10})
+		require.NoError(t, err)
+		out := make([]string, len(entries))
+		for i, e := range entries {
+			out[i] = e.Message
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"user 7's order failed"}, messages(&QueryFilters{OwnerUserID: 7}),
+		"a user sees neither another user's nor unclaimed projects' logs")
+	assert.Equal(t, []string{"user 8's order failed"}, messages(&QueryFilters{OwnerUserID: 8, Search: "order"}))
+	assert.Empty(t, messages(&QueryFilters{OwnerUserID: 9}))
+	assert.Len(t, messages(&QueryFilters{}), 4, "unscoped (admin) queries see everything")
+
+	suggestions, err := repo.SuggestTerms(ctx, "ordr", 9, 5)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions, "suggestions don't leak other projects' messages")
+
+	// Stream replays are scoped the same way
+	_, err = db.Exec(`ALTER TABLE logs.entries ADD COLUMN user_id INT, ADD COLUMN tags TEXT[]`)
+	require.NoError(t, err)
+	replayed, err := NewLogEntryRepository(db).FindAfterID(ctx, 0, ReplayFilter{OwnerUserID: 7}, 10)
+	require.NoError(t, err)
+	require.Len(t, replayed, 1)
+	assert.Equal(t, "user 7's order failed", replayed[0].Message)
+}
@@ -11,39 +11,83 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
 )
 
 // LogEntry represents a log entry in the database.
 type LogEntry struct {
-	ID        int64
-	CreatedAt time.Time
-	Metadata  map[string]interface{}
-	Tags      []string // Auto-generated and manual tags
-	Message   string
-	Service   string
-	Level     string
+	ID            int64
+	CreatedAt     time.Time
+	Metadata      map[string]interface{}
+	Tags          []string // Auto-generated and manual tags
+	Message       string
+	Service       string
+	Level         string
+	Score         float64 // Full-text relevance; only set for QueryFilters.FullText queries
+	CorrelationID string  // Empty when the entry was not logged with one
+	IssueType     string  // Classified at ingestion when empty; see SetIssueClassifier
 }
 
 // QueryFilters represents filtering options for log queries.
 type QueryFilters struct {
-	From       time.Time         // Filter logs created at or after this time
-	To         time.Time         // Filter logs created at or before this time
-	MetaEquals map[string]string // Filter logs where metadata keys equal given values
-	Service    string            // Filter logs by service name
-	Level      string            // Filter logs by level (e.g., "error", "info")
-	Search     string            // Full-text search on message field (ILIKE)
+	From          time.Time         // Filter logs created at or after this time
+	To            time.Time         // Filter logs created at or before this time
+	MetaEquals    map[string]string // Filter logs where metadata keys equal given values
+	Service       string            // Filter logs by service name
+	Level         string            // Filter logs by level (e.g., "error", "info")
+	Search        string            // Substring search on message field (ILIKE)
+	FullText      string            // Ranked full-text search on message_tsv (plainto_tsquery)
+	CorrelationID string            // Filter logs by exact correlation_id
+	Context       []ContextFilter   // Structured comparisons against metadata key paths
+	OwnerUserID   int               // Only entries of projects this user owns; 0 is unscoped
+}
+
+// ownedProjectsFragment matches entries of projects owned by the user in
+// the given parameter. Entries of unclaimed projects (user_id NULL) and
+// without a project never match.
+const ownedProjectsFragment = "project_id IN (SELECT id FROM logs.projects WHERE user_id = $%d)"
+
+// ContextFilter compares the value at a metadata key path, e.g. Path
+// ["request", "status_code"] with Op ">=" and Value "400". Entries missing
+// the key never match. Path segments must be validated by the caller.
+type ContextFilter struct {
+	Op    string   // One of =, !=, >, >=, <, <=
+	Value string   // Comparison value; numeric for range operators
+	Path  []string // Key path inside metadata
+}
+
+// contextFilterSQLOps maps ContextFilter operators to their SQL spelling.
+var contextFilterSQLOps = map[string]string{
+	"=":  "=",
+	"!=": "<>",
+	">":  ">",
+	">=": ">=",
+	"<":  "<",
+	"<=": "<=",
+}
+
+// IsNumeric reports whether the filter compares numerically (range operators).
+func (f ContextFilter) IsNumeric() bool {
+	switch f.Op {
+	case ">", ">=", "<", "<=":
+		return true
+	}
+	return false
 }
 
 // PageOptions holds pagination parameters for query results.
+// When After is set, keyset pagination is used and Offset is ignored.
 type PageOptions struct {
-	Limit  int // Number of results to return (must be > 0)
-	Offset int // Number of results to skip (must be >= 0)
+	After  *Cursor // Return entries strictly after this position (keyset mode)
+	Limit  int     // Number of results to return (must be > 0)
+	Offset int     // Number of results to skip (must be >= 0). Deprecated: use After.
 }
 
 // LogRepository handles CRUD operations for log entries.
 type LogRepository struct {
-	db *sql.DB
+	db         *sql.DB
+	classifier IssueClassifier
 }
 
 // NewLogRepository creates a new LogRepository.
@@ -51,6 +95,12 @@ func NewLogRepository(db *sql.DB) *LogRepository {
 	return &LogRepository{db: db}
 }
 
+// SetIssueClassifier enables issue_type classification for entries stored by
+// Save. Entries that already carry an issue type keep it.
+func (r *LogRepository) SetIssueClassifier(classifier IssueClassifier) {
+	r.classifier = classifier
+}
+
 // Save inserts a new log entry and returns its ID.
 func (r *LogRepository) Save(ctx context.Context, entry *LogEntry) (int64, error) {
 	if entry == nil {
@@ -102,12 +152,13 @@ func (r *LogRepository) Save(ctx context.Context, entry *LogEntry) (int64, error
 	}
 
 	// Insert and return ID
-	query := `INSERT INTO logs.entries (service, level, message, metadata, created_at)
-	         VALUES ($1, $2, $3, $4::jsonb, $5)
+	query := `INSERT INTO logs.entries (service, level, message, metadata, created_at, issue_type, fingerprint)
+	         VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7)
 	         RETURNING id`
 
 	var id int64
-	err := r.db.QueryRowContext(ctx, query, entry.Service, entry.Level, entry.Message, metadataJSON, entry.CreatedAt).Scan(&id)
+	err := r.db.QueryRowContext(ctx, query, entry.Service, entry.Level, entry.Message, metadataJSON, entry.CreatedAt,
+		issueType(r.classifier, entry.IssueType, entry.Message), logs_models.Fingerprint(entry.Message)).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("failed to insert log entry: %w", err)
 	}
@@ -150,12 +201,30 @@ func buildWhereClause(filters *QueryFilters) ([]string, []interface{}, int) {
 		argNum++
 	}
 
+	if filters.CorrelationID != "" {
+		fragments = append(fragments, fmt.Sprintf("correlation_id = $%d", argNum))
+		args = append(args, filters.CorrelationID)
+		argNum++
+	}
+
+	if filters.OwnerUserID != 0 {
+		fragments = append(fragments, fmt.Sprintf(ownedProjectsFragment, argNum))
+		args = append(args, filters.OwnerUserID)
+		argNum++
+	}
+
 	if filters.Search != "" {
 		fragments = append(fragments, fmt.Sprintf("message ILIKE $%d", argNum))
 		args = append(args, "%"+filters.Search+"%")
 		argNum++
 	}
 
+	if filters.FullText != "" {
+		fragments = append(fragments, fmt.Sprintf("message_tsv @@ plainto_tsquery('english', $%d)", argNum))
+		args = append(args, filters.FullText)
+		argNum++
+	}
+
 	if len(filters.MetaEquals) > 0 {
 		for k, v := range filters.MetaEquals {
 			fragments = append(fragments, fmt.Sprintf("metadata @> jsonb_build_object($%d::text, $%d::text)::jsonb", argNum, argNum+1))
@@ -164,6 +233,26 @@ func buildWhereClause(filters *QueryFilters) ([]string, []interface{}, int) {
 		}
 	}
 
+	for _, cf := range filters.Context {
+		op, ok := contextFilterSQLOps[cf.Op]
+		if !ok {
+			// Never interpolate an unvetted operator; match nothing instead
+			fragments = append(fragments, "FALSE")
+			continue
+		}
+		if cf.IsNumeric() {
+			// CASE guards the cast so non-numeric values are skipped instead of
+			// failing the whole query; missing keys yield NULL and never match
+			fragments = append(fragments, fmt.Sprintf(
+				"(CASE WHEN jsonb_typeof(metadata #> $%d::text[]) = 'number' THEN (metadata #>> $%d::text[])::numeric END) %s $%d::numeric",
+				argNum, argNum, op, argNum+1))
+		} else {
+			fragments = append(fragments, fmt.Sprintf("metadata #>> $%d::text[] %s $%d", argNum, op, argNum+1))
+		}
+		args = append(args, pq.Array(cf.Path), cf.Value)
+		argNum += 2
+	}
+
 	return fragments, args, argNum
 }
 
@@ -192,14 +281,40 @@ func (r *LogRepository) Query(ctx context.Context, filters *QueryFilters, page P
 
 	// Build WHERE clause
 	whereFragments, args, argNum := buildWhereClause(filters)
-	args = append(args, page.Limit, page.Offset)
+	whereFragments = append(whereFragments, notDeleted)
+
+	// Keyset pagination: row comparison keeps ties on created_at deterministic
+	if page.After != nil {
+		whereFragments = append(whereFragments, fmt.Sprintf("(created_at, id) < ($%d, $%d)", argNum, argNum+1))
+		args = append(args, page.After.CreatedAt, page.After.ID)
+		argNum += 2
+	}
 
 	// Build query - select actual columns (no tags column exists)
-	query := "SELECT id, service, level, message, metadata, created_at FROM logs.entries"
+	columns := entryColumns
+	ranked := filters != nil && filters.FullText != ""
+	if ranked {
+		columns += fmt.Sprintf(", ts_rank(message_tsv, plainto_tsquery('english', $%d)) AS score", argNum)
+		args = append(args, filters.FullText)
+		argNum++
+	}
+	query := "SELECT " + columns + " FROM logs.entries"
 	if len(whereFragments) > 0 {
 		query += " WHERE " + strings.Join(whereFragments, " AND ")
 	}
-	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argNum, argNum+1)
+	if page.After != nil {
+		// Cursors are positions in recency order, so keyset pages stay sorted
+		// by time even for full-text queries; the score is still returned
+		args = append(args, page.Limit)
+		query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", argNum)
+	} else {
+		orderBy := "created_at DESC, id DESC"
+		if ranked {
+			orderBy = "score DESC, " + orderBy
+		}
+		args = append(args, page.Limit, page.Offset)
+		query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", orderBy, argNum, argNum+1)
+	}
 
 	// Execute query
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -217,50 +332,270 @@ func (r *LogRepository) Query(ctx context.Context, filters *QueryFilters, page P
 	// Scan results
 	var entries []*LogEntry
 	for rows.Next() {
-		var id int64
-		var service, level, message string
-		var metadataJSON sql.NullString
-		var createdAt time.Time
+		var score float64
+		var extra []interface{}
+		if ranked {
+			extra = append(extra, &score)
+		}
+		entry, err := scanLogEntry(rows, extra...)
+		if err != nil {
+			return nil, err
+		}
+		entry.Score = score
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if entries == nil {
+		entries = []*LogEntry{}
+	}
+
+	return entries, nil
+}
+
+// notDeleted excludes soft-deleted entries, which stay in the table until
+// the trash purge job removes them.
+const notDeleted = "deleted_at IS NULL"
+
+// entryColumns are the columns scanLogEntry reads, in order.
+const entryColumns = "id, service, level, message, metadata, created_at, correlation_id"
 
-		if err := rows.Scan(&id, &service, &level, &message, &metadataJSON, &createdAt); err != nil {
-			return nil, fmt.Errorf("failed to scan log entry: %w", err)
+// scanLogEntry scans one row of entryColumns, followed by any extra columns
+// into the given destinations.
+func scanLogEntry(rows *sql.Rows, extra ...interface{}) (*LogEntry, error) {
+	var id int64
+	var service, level, message string
+	var metadataJSON, correlationID sql.NullString
+	var createdAt time.Time
+
+	dest := append([]interface{}{&id, &service, &level, &message, &metadataJSON, &createdAt, &correlationID}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return nil, fmt.Errorf("failed to scan log entry: %w", err)
+	}
+
+	entry := &LogEntry{
+		ID:            id,
+		Service:       service,
+		Level:         level,
+		Message:       message,
+		Tags:          []string{}, // No tags column in schema
+		CreatedAt:     createdAt,
+		Metadata:      make(map[string]interface{}),
+		CorrelationID: correlationID.String,
+	}
+
+	// Parse metadata JSON if it exists
+	if metadataJSON.Valid && metadataJSON.String != "" {
+		if err := json.Unmarshal([]byte(metadataJSON.String), &entry.Metadata); err != nil {
+			// Log the error but continue with empty metadata
+			log.Printf("Failed to unmarshal metadata for log entry %d: %v", entry.ID, err)
+			entry.Metadata = make(map[string]interface{})
 		}
+	}
 
-		entry := &LogEntry{
-			ID:        id,
-			Service:   service,
-			Level:     level,
-			Message:   message,
-			Tags:      []string{}, // No tags column in schema
-			CreatedAt: createdAt,
-			Metadata:  make(map[string]interface{}),
+	return entry, nil
+}
+
+// StreamQuery calls fn for every entry matching filters, newest first, scanning
+// one row at a time so arbitrarily large result sets never sit in memory.
+// limit <= 0 means no limit. Iteration stops at the first error returned by fn
+// or when ctx is cancelled, which also cancels the server-side query.
+func (r *LogRepository) StreamQuery(ctx context.Context, filters *QueryFilters, limit int, fn func(*LogEntry) error) error {
+	if fn == nil {
+		return fmt.Errorf("callback cannot be nil")
+	}
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("context cancelled: %w", ctx.Err())
+	default:
+	}
+
+	// If no database connection, there is nothing to stream
+	if r.db == nil {
+		return nil
+	}
+
+	whereFragments, args, argNum := buildWhereClause(filters)
+	whereFragments = append(whereFragments, notDeleted)
+
+	query := "SELECT " + entryColumns + " FROM logs.entries WHERE " + strings.Join(whereFragments, " AND ")
+	query += " ORDER BY created_at DESC, id DESC"
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", argNum)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to stream log entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanLogEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
 		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
 
-		// Parse metadata JSON if it exists
+	return nil
+}
+
+// FuzzyMatch is a log entry returned by the trigram fallback search,
+// paired with its word similarity (0-1) against the search term.
+type FuzzyMatch struct {
+	Entry      *LogEntry
+	Similarity float64
+}
+
+// FuzzySearch finds entries whose message is similar to filters.Search using
+// pg_trgm word similarity. It is meant as a fallback when the exact search
+// returns nothing, so callers should keep limit small.
+func (r *LogRepository) FuzzySearch(ctx context.Context, filters *QueryFilters, limit int) ([]FuzzyMatch, error) {
+	if filters == nil || strings.TrimSpace(filters.Search) == "" {
+		return nil, fmt.Errorf("search term is required")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
+	default:
+	}
+
+	if r.db == nil {
+		return []FuzzyMatch{}, nil
+	}
+
+	// Reuse the standard filters, minus the ILIKE search which already failed
+	nonSearch := *filters
+	nonSearch.Search = ""
+	whereFragments, args, argNum := buildWhereClause(&nonSearch)
+
+	termArg := argNum
+	whereFragments = append(whereFragments, fmt.Sprintf("$%d <%% message", termArg), notDeleted)
+	args = append(args, filters.Search, limit)
+
+	//nolint:gosec // WHERE fragments are built from fixed column names with parameterized values
+	query := fmt.Sprintf(`SELECT %s, word_similarity($%d, message) AS score
+		FROM logs.entries
+		WHERE %s
+		ORDER BY score DESC, created_at DESC, id DESC
+		LIMIT $%d`, entryColumns, termArg, strings.Join(whereFragments, " AND "), termArg+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run fuzzy search: %w", err)
+	}
+	//nolint:errcheck // Best effort to close rows
+	defer rows.Close()
+
+	matches := []FuzzyMatch{}
+	for rows.Next() {
+		var metadataJSON sql.NullString
+		var score float64
+		entry := &LogEntry{Tags: []string{}, Metadata: make(map[string]interface{})}
+
+		if err := rows.Scan(&entry.ID, &entry.Service, &entry.Level, &entry.Message, &metadataJSON, &entry.CreatedAt, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan fuzzy match: %w", err)
+		}
 		if metadataJSON.Valid && metadataJSON.String != "" {
 			if err := json.Unmarshal([]byte(metadataJSON.String), &entry.Metadata); err != nil {
-				// Log the error but continue with empty metadata
 				log.Printf("Failed to unmarshal metadata for log entry %d: %v", entry.ID, err)
 				entry.Metadata = make(map[string]interface{})
 			}
 		}
-
-		entries = append(entries, entry)
+		matches = append(matches, FuzzyMatch{Entry: entry, Similarity: score})
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	if entries == nil {
-		entries = []*LogEntry{}
+	return matches, nil
+}
+
+// SuggestTerms returns frequent message tokens that look like a misspelling
+// of a word in term, for "did you mean" hints. Only recent entries are
+// tokenized to keep the query cheap. A non-zero ownerUserID limits them to
+// that user's projects, as QueryFilters.OwnerUserID does.
+func (r *LogRepository) SuggestTerms(ctx context.Context, term string, ownerUserID, limit int) ([]string, error) {
+	words := strings.Fields(strings.ToLower(term))
+	if len(words) == 0 || limit <= 0 {
+		return []string{}, nil
 	}
 
-	return entries, nil
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
+	default:
+	}
+
+	if r.db == nil {
+		return []string{}, nil
+	}
+
+	query := `
+		WITH recent AS (
+			SELECT message FROM logs.entries
+			WHERE deleted_at IS NULL
+			  AND ($3 = 0 OR project_id IN (SELECT id FROM logs.projects WHERE user_id = $3))
+			ORDER BY created_at DESC LIMIT 5000
+		), tokens AS (
+			SELECT regexp_split_to_table(lower(message), '[^a-z0-9_]+') AS token FROM recent
+		), input AS (
+			SELECT unnest($1::text[]) AS word
+		)
+		SELECT t.token
+		FROM tokens t
+		CROSS JOIN input i
+		WHERE length(t.token) >= 3
+		  AND t.token <> i.word
+		  AND similarity(t.token, i.word) >= 0.3
+		GROUP BY t.token
+		ORDER BY MAX(similarity(t.token, i.word)) DESC, COUNT(*) DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(words), limit, ownerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest terms: %w", err)
+	}
+	//nolint:errcheck // Best effort to close rows
+	defer rows.Close()
+
+	suggestions := []string{}
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			return nil, fmt.Errorf("failed to scan suggestion: %w", err)
+		}
+		suggestions = append(suggestions, token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return suggestions, nil
 }
 
-// GetByID retrieves a single log entry by ID.
-func (r *LogRepository) GetByID(ctx context.Context, id int64) (*LogEntry, error) {
+// GetByID retrieves a single log entry by ID. A non-zero ownerUserID only
+// finds entries of that user's projects, as QueryFilters.OwnerUserID does.
+func (r *LogRepository) GetByID(ctx context.Context, id int64, ownerUserID int) (*LogEntry, error) {
 	// Validate ID
 	if id <= 0 {
 		return nil, fmt.Errorf("id must be greater than 0")
@@ -279,28 +614,34 @@ func (r *LogRepository) GetByID(ctx context.Context, id int64) (*LogEntry, error
 	}
 
 	// Query single entry
-	query := "SELECT id, service, level, message, metadata, created_at FROM logs.entries WHERE id = $1"
+	query := "SELECT " + entryColumns + " FROM logs.entries WHERE id = $1 AND " + notDeleted
+	args := []interface{}{id}
+	if ownerUserID != 0 {
+		query += " AND " + fmt.Sprintf(ownedProjectsFragment, 2)
+		args = append(args, ownerUserID)
+	}
 
 	var id64 int64
 	var service, level, message string
-	var metadataJSON sql.NullString
+	var metadataJSON, correlationID sql.NullString
 	var createdAt time.Time
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&id64, &service, &level, &message, &metadataJSON, &createdAt)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&id64, &service, &level, &message, &metadataJSON, &createdAt, &correlationID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("log entry not found")
+			return nil, fmt.Errorf("log entry not found: %w", err)
 		}
 		return nil, fmt.Errorf("failed to query log entry: %w", err)
 	}
 
 	entry := &LogEntry{
-		ID:        id64,
-		Service:   service,
-		Level:     level,
-		Message:   message,
-		CreatedAt: createdAt,
-		Metadata:  make(map[string]interface{}),
+		ID:            id64,
+		Service:       service,
+		Level:         level,
+		Message:       message,
+		CreatedAt:     createdAt,
+		Metadata:      make(map[string]interface{}),
+		CorrelationID: correlationID.String,
 	}
 
 	// Parse metadata JSON if it exists
@@ -314,25 +655,103 @@ func (r *LogRepository) GetByID(ctx context.Context, id int64) (*LogEntry, error
 	return entry, nil
 }
 
+// GetNeighbors returns the entry with the given id plus up to before older
+// and after newer entries from the same service (and, when sameProject is
+// set, the same project), oldest first. Entries are ordered by
+// (created_at, id), so neighbors with identical timestamps keep a stable
+// order. A non-zero ownerUserID limits the entry and its neighbors to that
+// user's projects. It returns sql.ErrNoRows if the entry does not exist.
+func (r *LogRepository) GetNeighbors(ctx context.Context, id int64, before, after int, sameProject bool, ownerUserID int) ([]*LogEntry, error) {
+	if r.db == nil {
+		return []*LogEntry{}, nil
+	}
+
+	// Each side is a keyset scan from the target in its own direction, so
+	// only before+after+1 rows are read regardless of the service's volume
+	query := `
+		WITH target AS (
+			SELECT id, service, service_name, project_id, created_at
+			FROM logs.entries
+			WHERE id = $1 AND deleted_at IS NULL
+			  AND ($5 = 0 OR project_id IN (SELECT id FROM logs.projects WHERE user_id = $5))
+		),
+		scope AS (
+			SELECT e.id, e.service, e.level, e.message, e.metadata, e.created_at, e.correlation_id
+			FROM logs.entries e, target t
+			WHERE e.service = t.service
+			  AND e.service_name IS NOT DISTINCT FROM t.service_name
+			  AND (NOT $4 OR e.project_id IS NOT DISTINCT FROM t.project_id)
+			  AND e.deleted_at IS NULL
+			  AND ($5 = 0 OR e.project_id IN (SELECT id FROM logs.projects WHERE user_id = $5))
+		)
+		SELECT id, service, level, message, metadata, created_at, correlation_id FROM (
+			(SELECT s.* FROM scope s, target t
+			 WHERE (s.created_at, s.id) < (t.created_at, t.id)
+			 ORDER BY s.created_at DESC, s.id DESC
+			 LIMIT $2)
+			UNION ALL
+			(SELECT s.* FROM scope s WHERE s.id = $1)
+			UNION ALL
+			(SELECT s.* FROM scope s, target t
+			 WHERE (s.created_at, s.id) > (t.created_at, t.id)
+			 ORDER BY s.created_at ASC, s.id ASC
+			 LIMIT $3)
+		) neighbors
+		ORDER BY created_at ASC, id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, id, before, after, sameProject, ownerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log neighbors: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*LogEntry{}
+	found := false
+	for rows.Next() {
+		entry, err := scanLogEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		found = found || entry.ID == id
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	if !found {
+		return nil, sql.ErrNoRows
+	}
+	return entries, nil
+}
+
 // getCountsByLevel aggregates log count grouped by level.
-func (r *LogRepository) getCountsByLevel(ctx context.Context) (map[string]int64, error) {
-	return r.aggregateCount(ctx, "level", "failed to query by level", "failed to scan level stats", "rows iteration error (by_level)")
+func (r *LogRepository) getCountsByLevel(ctx context.Context, ownerUserID int) (map[string]int64, error) {
+	return r.aggregateCount(ctx, "level", ownerUserID, "failed to query by level", "failed to scan level stats", "rows iteration error (by_level)")
 }
 
 // getCountsByService aggregates log count grouped by service.
-func (r *LogRepository) getCountsByService(ctx context.Context) (map[string]int64, error) {
-	return r.aggregateCount(ctx, "service", "failed to query by service", "failed to scan service stats", "rows iteration error (by_service)")
+func (r *LogRepository) getCountsByService(ctx context.Context, ownerUserID int) (map[string]int64, error) {
+	return r.aggregateCount(ctx, "service", ownerUserID, "failed to query by service", "failed to scan service stats", "rows iteration error (by_service)")
+}
+
+// statsWhere returns the WHERE clause and arguments for GetStats' counts.
+func statsWhere(ownerUserID int) (string, []interface{}) {
+	if ownerUserID == 0 {
+		return notDeleted, nil
+	}
+	return notDeleted + " AND " + fmt.Sprintf(ownedProjectsFragment, 1), []interface{}{ownerUserID}
 }
 
 // aggregateCount performs aggregation on a specific column.
 // nolint:gocritic,gosec // return values are self-explanatory; SQL column names are controlled
-func (r *LogRepository) aggregateCount(ctx context.Context, column, queryErr, scanErr, iterErr string) (map[string]int64, error) {
+func (r *LogRepository) aggregateCount(ctx context.Context, column string, ownerUserID int, queryErr, scanErr, iterErr string) (map[string]int64, error) {
 	if r.db == nil {
 		return map[string]int64{}, nil
 	}
 
-	query := fmt.Sprintf("SELECT %s, COUNT(*) FROM logs.entries GROUP BY %s", column, column)
-	rows, err := r.db.QueryContext(ctx, query)
+	where, args := statsWhere(ownerUserID)
+	query := fmt.Sprintf("SELECT %s, COUNT(*) FROM logs.entries WHERE %s GROUP BY %s", column, where, column)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf(queryErr+": %w", err)
 	}
@@ -367,7 +786,7 @@ func (r *LogRepository) FindAllServices(ctx context.Context) ([]string, error) {
 		return []string{}, nil
 	}
 
-	query := "SELECT DISTINCT service FROM logs.entries ORDER BY service"
+	query := "SELECT DISTINCT service FROM logs.entries WHERE " + notDeleted + " ORDER BY service"
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find services: %w", err)
@@ -393,7 +812,7 @@ func (r *LogRepository) CountByServiceAndLevel(ctx context.Context, service, lev
 		return 0, nil
 	}
 
-	query := "SELECT COUNT(*) FROM logs.entries WHERE service = $1 AND level = $2 AND created_at >= $3 AND created_at <= $4"
+	query := "SELECT COUNT(*) FROM logs.entries WHERE service = $1 AND level = $2 AND created_at >= $3 AND created_at <= $4 AND " + notDeleted
 	var count int64
 	err := r.db.QueryRowContext(ctx, query, service, level, start, end).Scan(&count)
 	if err != nil && err != sql.ErrNoRows {
@@ -419,7 +838,7 @@ func (r *LogRepository) FindTopMessages(ctx context.Context, service, level stri
 
 	query := `SELECT message, COUNT(*) as count, MAX(created_at) as last_seen 
 	         FROM logs.entries 
-	         WHERE service = $1 AND level = $2 AND created_at >= $3 AND created_at <= $4
+	         WHERE service = $1 AND level = $2 AND created_at >= $3 AND created_at <= $4 AND deleted_at IS NULL
 	         GROUP BY message 
 	         ORDER BY count DESC 
 	         LIMIT $5`
@@ -451,8 +870,9 @@ func (r *LogRepository) FindTopMessages(ctx context.Context, service, level stri
 	return messages, nil
 }
 
-// GetStats returns aggregated statistics about log entries.
-func (r *LogRepository) GetStats(ctx context.Context) (map[string]interface{}, error) {
+// GetStats returns aggregated statistics about log entries. A non-zero
+// ownerUserID only counts entries of that user's projects.
+func (r *LogRepository) GetStats(ctx context.Context, ownerUserID int) (map[string]interface{}, error) {
 	// Check context
 	select {
 	case <-ctx.Done():
@@ -473,21 +893,22 @@ func (r *LogRepository) GetStats(ctx context.Context) (map[string]interface{}, e
 
 	// Get total count
 	var totalCount int64
-	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM logs.entries").Scan(&totalCount)
+	where, args := statsWhere(ownerUserID)
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM logs.entries WHERE "+where, args...).Scan(&totalCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total count: %w", err)
 	}
 	stats["total"] = totalCount
 
 	// Get counts by level
-	byLevel, err := r.getCountsByLevel(ctx)
+	byLevel, err := r.getCountsByLevel(ctx, ownerUserID)
 	if err != nil {
 		return nil, err
 	}
 	stats["by_level"] = byLevel
 
 	// Get counts by service
-	byService, err := r.getCountsByService(ctx)
+	byService, err := r.getCountsByService(ctx, ownerUserID)
 	if err != nil {
 		return nil, err
 	}
@@ -496,6 +917,263 @@ func (r *LogRepository) GetStats(ctx context.Context) (map[string]interface{}, e
 	return stats, nil
 }
 
+// timeSeriesGroupColumns maps TimeSeriesQuery.GroupBy to the column counted by.
+var timeSeriesGroupColumns = map[string]string{
+	"level":   "level",
+	"service": "service",
+}
+
+// CountTimeSeries counts entries created in [q.From, q.To) per q.Interval
+// bucket (minute, hour or day, truncated in UTC), optionally per level or
+// service. Buckets come from generate_series, so a bucket with no entries is
+// returned with a zero count rather than left out; when grouped, every group
+// seen in the range gets a point in every bucket. Points are ordered by bucket
+// then group. A non-zero q.OwnerUserID only counts that user's projects.
+// nolint:gosec // the group column comes from timeSeriesGroupColumns
+func (r *LogRepository) CountTimeSeries(ctx context.Context, q logs_models.TimeSeriesQuery) ([]logs_models.TimeSeriesPoint, error) {
+	if r.db == nil {
+		return []logs_models.TimeSeriesPoint{}, nil
+	}
+
+	group := "''::text"
+	groups := "SELECT ''::text AS grp"
+	if q.GroupBy != "" {
+		column, ok := timeSeriesGroupColumns[q.GroupBy]
+		if !ok {
+			return nil, fmt.Errorf("unsupported time series grouping %q", q.GroupBy)
+		}
+		group = column
+		groups = "SELECT DISTINCT grp FROM counts"
+	}
+
+	query := fmt.Sprintf(`
+		WITH buckets AS (
+			SELECT generate_series(
+				date_trunc($1, $2::timestamptz AT TIME ZONE 'UTC'),
+				date_trunc($1, ($3::timestamptz AT TIME ZONE 'UTC') - interval '1 microsecond'),
+				('1 ' || $1)::interval
+			) AS bucket
+		),
+		counts AS (
+			SELECT date_trunc($1, created_at AT TIME ZONE 'UTC') AS bucket, %s AS grp, COUNT(*) AS n
+			FROM logs.entries
+			WHERE created_at >= $2 AND created_at < $3 AND deleted_at IS NULL
+			  AND ($4 = 0 OR project_id IN (SELECT id FROM logs.projects WHERE user_id = $4))
+			GROUP BY 1, 2
+		),
+		groups AS (%s)
+		SELECT b.bucket AT TIME ZONE 'UTC', g.grp, COALESCE(c.n, 0)
+		FROM buckets b
+		CROSS JOIN groups g
+		LEFT JOIN counts c ON c.bucket = b.bucket AND c.grp = g.grp
+		ORDER BY b.bucket, g.grp`, group, groups)
+
+	rows, err := r.db.QueryContext(ctx, query, q.Interval, q.From, q.To, q.OwnerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log time series: %w", err)
+	}
+	defer rows.Close()
+
+	points := []logs_models.TimeSeriesPoint{}
+	for rows.Next() {
+		var point logs_models.TimeSeriesPoint
+		var grp string
+		if err := rows.Scan(&point.Bucket, &grp, &point.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan time series point: %w", err)
+		}
+		point.Bucket = point.Bucket.UTC()
+		switch q.GroupBy {
+		case "level":
+			point.Level = grp
+		case "service":
+			point.Service = grp
+		}
+		points = append(points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return points, nil
+}
+
+// ListFingerprints groups entries created in [q.From, q.To) by fingerprint,
+// optionally of one level or service, returning the q.Limit largest groups
+// with when each was first and last seen and its most recent entry's message.
+// Entries stored before fingerprints were added have none and are left out.
+// A non-zero q.OwnerUserID only groups entries of that user's projects.
+func (r *LogRepository) ListFingerprints(ctx context.Context, q logs_models.FingerprintQuery) ([]logs_models.FingerprintGroup, error) {
+	if r.db == nil {
+		return []logs_models.FingerprintGroup{}, nil
+	}
+
+	query := `
+		WITH groups AS (
+			SELECT fingerprint, COUNT(*) AS n, MIN(created_at) AS first_seen, MAX(created_at) AS last_seen, MAX(id) AS last_id
+			FROM logs.entries
+			WHERE fingerprint IS NOT NULL AND deleted_at IS NULL
+			  AND created_at >= $1 AND created_at < $2
+			  AND ($3::text = '' OR level = $3)
+			  AND ($4::text = '' OR service = $4)
+			  AND ($6 = 0 OR project_id IN (SELECT id FROM logs.projects WHERE user_id = $6))
+			GROUP BY fingerprint
+			ORDER BY n DESC, last_seen DESC
+			LIMIT $5
+		)
+		SELECT g.fingerprint, g.n, g.first_seen, g.last_seen, e.message, e.level, COALESCE(e.service, '')
+		FROM groups g
+		JOIN logs.entries e ON e.id = g.last_id
+		ORDER BY g.n DESC, g.last_seen DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, q.From, q.To, q.Level, q.Service, q.Limit, q.OwnerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query log fingerprints: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []logs_models.FingerprintGroup{}
+	for rows.Next() {
+		var group logs_models.FingerprintGroup
+		if err := rows.Scan(&group.Fingerprint, &group.Count, &group.FirstSeen, &group.LastSeen,
+			&group.Message, &group.Level, &group.Service); err != nil {
+			return nil, fmt.Errorf("failed to scan fingerprint group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return groups, nil
+}
+
+// projectFilterWhere returns the WHERE clause and arguments selecting a
+// project's live entries that match filters.
+func projectFilterWhere(projectID int64, filters *QueryFilters) (string, []interface{}) {
+	fragments, args, argNum := buildWhereClause(filters)
+	fragments = append(fragments, fmt.Sprintf("project_id = $%d", argNum), notDeleted)
+	args = append(args, projectID)
+	return strings.Join(fragments, " AND "), args
+}
+
+// CountMatching returns how many of the project's entries match filters.
+func (r *LogRepository) CountMatching(ctx context.Context, projectID int64, filters *QueryFilters) (int64, error) {
+	if r.db == nil {
+		return 0, nil
+	}
+
+	where, args := projectFilterWhere(projectID, filters)
+	var count int64
+	//nolint:gosec // WHERE fragments are built from parameterized placeholders only
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM logs.entries WHERE "+where, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count matching log entries: %w", err)
+	}
+	return count, nil
+}
+
+// SoftDeleteMatching moves the project's entries matching filters to the
+// trash by stamping deleted_at, and returns how many were moved. Entries
+// deleted by one call share the same deleted_at, which identifies the batch
+// for RestoreDeleted.
+func (r *LogRepository) SoftDeleteMatching(ctx context.Context, projectID int64, filters *QueryFilters) (int64, error) {
+	if r.db == nil {
+		return 0, nil
+	}
+
+	where, args := projectFilterWhere(projectID, filters)
+	//nolint:gosec // WHERE fragments are built from parameterized placeholders only
+	result, err := r.db.ExecContext(ctx, "UPDATE logs.entries SET deleted_at = NOW() WHERE "+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete matching log entries: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// ListTrash returns the project's soft-deleted entries deleted after since,
+// grouped by deletion, most recent first.
+func (r *LogRepository) ListTrash(ctx context.Context, projectID int64, since time.Time) ([]logs_models.TrashBatch, error) {
+	if r.db == nil {
+		return []logs_models.TrashBatch{}, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT deleted_at, COUNT(*)
+		FROM logs.entries
+		WHERE project_id = $1 AND deleted_at > $2
+		GROUP BY deleted_at
+		ORDER BY deleted_at DESC`, projectID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %w", err)
+	}
+	//nolint:errcheck // Best effort to close rows
+	defer rows.Close()
+
+	batches := []logs_models.TrashBatch{}
+	for rows.Next() {
+		var b logs_models.TrashBatch
+		if err := rows.Scan(&b.DeletedAt, &b.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan trash batch: %w", err)
+		}
+		batches = append(batches, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return batches, nil
+}
+
+// RestoreDeleted clears deleted_at on the project's entries deleted after
+// since - only those deleted exactly at deletedAt when it is set - and
+// returns how many were restored.
+func (r *LogRepository) RestoreDeleted(ctx context.Context, projectID int64, since time.Time, deletedAt *time.Time) (int64, error) {
+	if r.db == nil {
+		return 0, nil
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE logs.entries SET deleted_at = NULL
+		WHERE project_id = $1 AND deleted_at > $2
+		  AND ($3::timestamptz IS NULL OR deleted_at = $3)`, projectID, since, deletedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore log entries: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// PurgeDeletedBatch permanently deletes at most batchSize entries
+// soft-deleted before cutoff and returns how many were removed. Callers loop
+// until it returns fewer than batchSize.
+func (r *LogRepository) PurgeDeletedBatch(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	if cutoff.IsZero() {
+		return 0, fmt.Errorf("cutoff cannot be zero")
+	}
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("batch size must be greater than 0")
+	}
+	if r.db == nil {
+		return 0, nil
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM logs.entries WHERE id IN (
+		SELECT id FROM logs.entries WHERE deleted_at < $1 LIMIT $2
+	)`, cutoff, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted log entries: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
 // DeleteOld removes log entries older than the given timestamp.
 func (r *LogRepository) DeleteOld(ctx context.Context, ts time.Time) (int64, error) {
 	// Validate timestamp
@@ -529,6 +1207,195 @@ func (r *LogRepository) DeleteOld(ctx context.Context, ts time.Time) (int64, err
 	return rowsAffected, nil
 }
 
+// ListProjectRetention returns projects with their own retention window,
+// keyed by project ID. Projects without an override are omitted.
+func (r *LogRepository) ListProjectRetention(ctx context.Context) (map[int64]int, error) {
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("context cancelled: %w", ctx.Err())
+	default:
+	}
+
+	overrides := make(map[int64]int)
+	if r.db == nil {
+		return overrides, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id, retention_days FROM logs.projects WHERE retention_days IS NOT NULL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project retention: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var days int
+		if err := rows.Scan(&id, &days); err != nil {
+			return nil, fmt.Errorf("failed to scan project retention: %w", err)
+		}
+		overrides[id] = days
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// DeleteExpiredBatch deletes at most batchSize entries created before cutoff
+// and returns how many were removed. Callers loop until it returns fewer
+// than batchSize, which keeps each statement's locks short.
+//
+// With projectID set only that project's entries are considered. With
+// projectID nil it covers entries that follow the default window: those
+// without a project or whose project has no retention_days override.
+func (r *LogRepository) DeleteExpiredBatch(ctx context.Context, cutoff time.Time, projectID *int64, batchSize int) (int64, error) {
+	if cutoff.IsZero() {
+		return 0, fmt.Errorf("cutoff cannot be zero")
+	}
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("batch size must be greater than 0")
+	}
+
+	select {
+	case <-ctx.Done():
+		return 0, fmt.Errorf("context cancelled: %w", ctx.Err())
+	default:
+	}
+
+	if r.db == nil {
+		return 0, nil
+	}
+
+	scope := `(project_id IS NULL OR project_id NOT IN (SELECT id FROM logs.projects WHERE retention_days IS NOT NULL))`
+	args := []interface{}{cutoff, batchSize}
+	if projectID != nil {
+		scope = "project_id = $3"
+		args = append(args, *projectID)
+	}
+
+	//nolint:gosec // scope is one of two constant fragments; values are parameterized
+	query := fmt.Sprintf(`DELETE FROM logs.entries WHERE id IN (
+		SELECT id FROM logs.entries WHERE created_at < $1 AND %s LIMIT $2
+	)`, scope)
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired log entries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// ListUnscoredBatch returns up to limit entries with an id above afterID and
+// no severity_score, in id order, so callers can page through them by
+// passing the last id they saw.
+func (r *LogRepository) ListUnscoredBatch(ctx context.Context, afterID int64, limit int) ([]*LogEntry, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+	if r.db == nil {
+		return []*LogEntry{}, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, service, level, message, COALESCE(issue_type, ''), created_at
+		FROM logs.entries
+		WHERE severity_score IS NULL AND id > $1
+		ORDER BY id
+		LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unscored log entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*LogEntry{}
+	for rows.Next() {
+		entry := &LogEntry{}
+		if err := rows.Scan(&entry.ID, &entry.Service, &entry.Level, &entry.Message, &entry.IssueType, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan unscored log entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return entries, nil
+}
+
+// SetSeverityScores sets severity_score for the given entry ids and returns
+// how many rows changed. Entries already scored (e.g. by AI analysis in the
+// meantime) are left alone, so repeating a call changes nothing.
+func (r *LogRepository) SetSeverityScores(ctx context.Context, scores map[int64]int) (int64, error) {
+	if len(scores) == 0 || r.db == nil {
+		return 0, nil
+	}
+
+	ids := make([]int64, 0, len(scores))
+	values := make([]int64, 0, len(scores))
+	for id, score := range scores {
+		ids = append(ids, id)
+		values = append(values, int64(score))
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE logs.entries e
+		SET severity_score = s.score
+		FROM unnest($1::bigint[], $2::int[]) AS s(id, score)
+		WHERE e.id = s.id AND e.severity_score IS NULL`,
+		pq.Array(ids), pq.Array(values))
+	if err != nil {
+		return 0, fmt.Errorf("failed to set severity scores: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected, nil
+}
+
+// GetBackfillCheckpoint returns the last entry id the named backfill job
+// processed, or 0 if it has not run.
+func (r *LogRepository) GetBackfillCheckpoint(ctx context.Context, job string) (int64, error) {
+	if r.db == nil {
+		return 0, nil
+	}
+
+	var lastID int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT last_id FROM logs.backfill_checkpoints WHERE job = $1`, job).Scan(&lastID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get backfill checkpoint: %w", err)
+	}
+	return lastID, nil
+}
+
+// SetBackfillCheckpoint records the last entry id the named backfill job
+// processed, so an interrupted run resumes after it.
+func (r *LogRepository) SetBackfillCheckpoint(ctx context.Context, job string, lastID int64) error {
+	if r.db == nil {
+		return nil
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO logs.backfill_checkpoints (job, last_id, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (job) DO UPDATE SET last_id = EXCLUDED.last_id, updated_at = NOW()`,
+		job, lastID)
+	if err != nil {
+		return fmt.Errorf("failed to set backfill checkpoint: %w", err)
+	}
+	return nil
+}
+
 // validateBulkEntries validates all entries before insertion.
 func validateBulkEntries(entries []*LogEntry) error {
 	if entries == nil {
@@ -643,6 +1510,7 @@ func (r *LogRepository) GetLogStatsByLevel(ctx context.Context) (map[string]int,
 			LOWER(level) as level,
 			COUNT(*) as count
 		FROM logs.entries
+		WHERE deleted_at IS NULL
 		GROUP BY LOWER(level)
 	`
 
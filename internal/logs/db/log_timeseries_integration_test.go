@@ -0,0 +1,102 @@
+package logs_db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func insertTimeSeriesEntry(t *testing.T, db *sql.DB, service, level string, at time.Time) {
+	_, err := db.Exec(`
+		INSERT INTO logs.entries (service, level, message, metadata, created_at)
+		VALUES ($1, $2, 'msg', '{}', $3)`,
+		service, level, at)
+	require.NoError(t, err)
+}
+
+func TestLogRepository_CountTimeSeries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := setupNeighborsDB(t)
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	base := time.Date(2025, 11, 23, 10, 0, 0, 0, time.UTC)
+	insertTimeSeriesEntry(t, db, "portal", "INFO", base.Add(5*time.Minute))
+	insertTimeSeriesEntry(t, db, "portal", "ERROR", base.Add(59*time.Minute))
+	insertTimeSeriesEntry(t, db, "review", "ERROR", base.Add(30*time.Minute))
+	// Nothing between 11:00 and 12:00
+	insertTimeSeriesEntry(t, db, "review", "ERROR", base.Add(2*time.Hour))
+	// Outside the range on both sides
+	insertTimeSeriesEntry(t, db, "portal", "INFO", base.Add(-time.Second))
+	insertTimeSeriesEntry(t, db, "portal", "INFO", base.Add(3*time.Hour))
+
+	q := logs_models.TimeSeriesQuery{From: base, To: base.Add(3 * time.Hour), Interval: "hour"}
+
+	t.Run("GapIsZeroFilled", func(t *testing.T) {
+		points, err := repo.CountTimeSeries(ctx, q)
+		require.NoError(t, err)
+		assert.Equal(t, []logs_models.TimeSeriesPoint{
+			{Bucket: base, Count: 3},
+			{Bucket: base.Add(time.Hour), Count: 0},
+			{Bucket: base.Add(2 * time.Hour), Count: 1},
+		}, points)
+	})
+
+	t.Run("GroupedByLevel", func(t *testing.T) {
+		grouped := q
+		grouped.GroupBy = "level"
+		points, err := repo.CountTimeSeries(ctx, grouped)
+		require.NoError(t, err)
+		assert.Equal(t, []logs_models.TimeSeriesPoint{
+			{Bucket: base, Level: "ERROR", Count: 2},
+			{Bucket: base, Level: "INFO", Count: 1},
+			{Bucket: base.Add(time.Hour), Level: "ERROR", Count: 0},
+			{Bucket: base.Add(time.Hour), Level: "INFO", Count: 0},
+			{Bucket: base.Add(2 * time.Hour), Level: "ERROR", Count: 1},
+			{Bucket: base.Add(2 * time.Hour), Level: "INFO", Count: 0},
+		}, points)
+	})
+
+	t.Run("GroupedByService", func(t *testing.T) {
+		grouped := q
+		grouped.GroupBy = "service"
+		points, err := repo.CountTimeSeries(ctx, grouped)
+		require.NoError(t, err)
+		require.Len(t, points, 6)
+		assert.Equal(t, logs_models.TimeSeriesPoint{Bucket: base, Service: "portal", Count: 2}, points[0])
+		assert.Equal(t, logs_models.TimeSeriesPoint{Bucket: base.Add(time.Hour), Service: "review", Count: 0}, points[3])
+	})
+
+	t.Run("PartialRangeCoversEveryBucket", func(t *testing.T) {
+		// 10:30 to 11:30 touches two hourly buckets
+		points, err := repo.CountTimeSeries(ctx, logs_models.TimeSeriesQuery{
+			From: base.Add(30 * time.Minute), To: base.Add(90 * time.Minute), Interval: "hour",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []logs_models.TimeSeriesPoint{
+			{Bucket: base, Count: 2},
+			{Bucket: base.Add(time.Hour), Count: 0},
+		}, points)
+	})
+
+	t.Run("NoEntriesInRange", func(t *testing.T) {
+		// Nov 21 10:00 to Nov 22 10:00 spans two days, neither with entries
+		points, err := repo.CountTimeSeries(ctx, logs_models.TimeSeriesQuery{
+			From: base.Add(-48 * time.Hour), To: base.Add(-24 * time.Hour), Interval: "day",
+		})
+		require.NoError(t, err)
+		day := time.Date(2025, 11, 21, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, []logs_models.TimeSeriesPoint{
+			{Bucket: day, Count: 0},
+			{Bucket: day.Add(24 * time.Hour), Count: 0},
+		}, points)
+	})
+}
@@ -0,0 +1,13 @@
+-- Migration: Trigram index for fuzzy log search fallback
+-- Date: 2025-11-13
+-- Purpose: Allow GET /api/logs to fall back to similarity matching when a
+--          message search returns nothing (e.g. the user made a typo)
+
+-- pg_trgm provides similarity(), word_similarity() and the <% operator
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- GIN trigram index so word-similarity lookups don't scan the whole table
+CREATE INDEX IF NOT EXISTS idx_logs_entries_message_trgm
+ON logs.entries USING GIN (message gin_trgm_ops);
+
+COMMENT ON INDEX logs.idx_logs_entries_message_trgm IS 'Trigram index backing the fuzzy search fallback on log messages';
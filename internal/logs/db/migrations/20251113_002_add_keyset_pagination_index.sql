@@ -0,0 +1,7 @@
+-- Migration: Composite index for keyset (cursor) pagination
+-- Date: 2025-11-13
+-- Purpose: Serve WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC
+--          from an index instead of scanning and discarding rows like OFFSET does
+
+CREATE INDEX IF NOT EXISTS idx_logs_entries_created_at_id
+ON logs.entries (created_at DESC, id DESC);
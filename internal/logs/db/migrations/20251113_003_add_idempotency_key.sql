@@ -0,0 +1,16 @@
+-- Migration: Idempotency key for batch log ingestion
+-- Date: 2025-11-13
+-- Purpose: Let clients safely retry POST /api/logs/batch after a timeout
+--          without inserting the same log entries twice
+
+-- Client-supplied dedup key (NULL = entry is never deduplicated)
+ALTER TABLE logs.entries
+    ADD COLUMN IF NOT EXISTS idempotency_key TEXT;
+
+-- Keys are scoped per project: two projects may reuse the same key.
+-- Partial so keyless entries don't pay for the index.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_entries_project_idempotency_key
+ON logs.entries(project_id, idempotency_key)
+WHERE idempotency_key IS NOT NULL;
+
+COMMENT ON COLUMN logs.entries.idempotency_key IS 'Optional client dedup key, unique per project; retried entries with the same key are skipped';
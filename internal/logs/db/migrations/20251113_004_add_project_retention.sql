@@ -0,0 +1,9 @@
+-- Migration: Per-project log retention
+-- Date: 2025-11-13
+-- Purpose: Let the retention job purge logs.entries on a per-project schedule
+
+-- NULL means "use the service default" (LOGS_RETENTION_DAYS)
+ALTER TABLE logs.projects
+    ADD COLUMN IF NOT EXISTS retention_days INT CHECK (retention_days IS NULL OR retention_days > 0);
+
+COMMENT ON COLUMN logs.projects.retention_days IS 'Days to keep this project''s log entries; NULL uses the LOGS_RETENTION_DAYS default';
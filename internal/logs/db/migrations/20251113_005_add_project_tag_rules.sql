@@ -0,0 +1,20 @@
+-- Migration: Per-project tagging rules
+-- Date: 2025-11-13
+-- Purpose: Let project owners add tags at ingest time on top of the built-in
+--          auto_generate_tags() trigger (e.g. level=ERROR + service=payments -> critical)
+
+CREATE TABLE IF NOT EXISTS logs.project_tag_rules (
+    id BIGSERIAL PRIMARY KEY,
+    project_id INT NOT NULL REFERENCES logs.projects(id) ON DELETE CASCADE,
+    message_pattern TEXT NOT NULL DEFAULT '', -- RE2 regex, '' = any message
+    level TEXT NOT NULL DEFAULT '',           -- '' = any level
+    service TEXT NOT NULL DEFAULT '',         -- '' = any service
+    tag TEXT NOT NULL CHECK (tag <> ''),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_tag_rules_project_id
+ON logs.project_tag_rules(project_id);
+
+COMMENT ON TABLE logs.project_tag_rules IS 'Rules applied at ingest: every non-empty condition must match for the tag to be added';
@@ -0,0 +1,22 @@
+-- Migration: AI insights over groups of related logs
+-- Date: 2025-11-13
+-- Purpose: Cache POST /api/logs/insights/correlated results so repeating the
+--          same window + filter doesn't call the LLM again
+
+CREATE TABLE IF NOT EXISTS logs.ai_correlated_insights (
+    id BIGSERIAL PRIMARY KEY,
+    cache_key TEXT NOT NULL UNIQUE, -- SHA-256 of window + filter + model
+    log_ids BIGINT[] NOT NULL DEFAULT '{}',
+    analysis TEXT NOT NULL,
+    root_cause TEXT,
+    suggestions JSONB,
+    model_used VARCHAR(255) NOT NULL,
+    truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    generated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_correlated_insights_generated_at
+ON logs.ai_correlated_insights(generated_at DESC);
+
+COMMENT ON TABLE logs.ai_correlated_insights IS 'AI-generated insights across a window of related log entries';
+COMMENT ON COLUMN logs.ai_correlated_insights.log_ids IS 'Entries sent to the model, so users can verify the analysis';
@@ -0,0 +1,14 @@
+-- Migration: Full-text search over log messages
+-- Date: 2025-11-13
+-- Purpose: Back GET /api/logs?q= with a ranked tsvector match instead of an
+--          ILIKE scan. Substring search stays available via match=substring.
+
+-- Generated column keeps the tsvector in step with message on every write
+ALTER TABLE logs.entries
+ADD COLUMN IF NOT EXISTS message_tsv tsvector
+GENERATED ALWAYS AS (to_tsvector('english', coalesce(message, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_logs_entries_message_tsv
+ON logs.entries USING GIN (message_tsv);
+
+COMMENT ON COLUMN logs.entries.message_tsv IS 'English tsvector of message for ranked full-text search';
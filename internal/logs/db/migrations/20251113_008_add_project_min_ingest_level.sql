@@ -0,0 +1,11 @@
+-- Migration: Per-project minimum ingest level
+-- Date: 2025-11-13
+-- Purpose: Let POST /api/logs/batch drop entries below a project's level
+--          (e.g. DEBUG from noisy production services) before storing them
+
+-- NULL means "store every level"
+ALTER TABLE logs.projects
+    ADD COLUMN IF NOT EXISTS min_ingest_level VARCHAR(10)
+    CHECK (min_ingest_level IS NULL OR min_ingest_level IN ('DEBUG', 'INFO', 'WARN', 'ERROR', 'FATAL'));
+
+COMMENT ON COLUMN logs.projects.min_ingest_level IS 'Batch ingestion drops entries below this level; NULL keeps everything';
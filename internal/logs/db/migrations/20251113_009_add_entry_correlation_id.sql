@@ -0,0 +1,20 @@
+-- Migration: Correlation IDs on batch-ingested entries
+-- Date: 2025-11-13
+-- Purpose: POST /api/logs/batch stores each entry's correlation_id so
+--          GET /api/logs?correlation_id=... returns one request's logs
+--          across services
+
+-- Older databases may predate 20251026_002
+ALTER TABLE logs.entries
+    ADD COLUMN IF NOT EXISTS correlation_id TEXT;
+
+-- Entries ingested before this migration only carried the ID in their context
+UPDATE logs.entries
+SET correlation_id = metadata->>'correlation_id'
+WHERE correlation_id IS NULL
+  AND jsonb_typeof(metadata->'correlation_id') = 'string';
+
+-- Serves the correlation_id filter in GetLogs' (created_at, id) order
+CREATE INDEX IF NOT EXISTS idx_entries_correlation_id_created
+    ON logs.entries(correlation_id, created_at DESC, id DESC)
+    WHERE correlation_id IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Migration: Per-project daily log quota
+-- Date: 2025-11-13
+-- Purpose: Stop one noisy project from filling the database; POST /api/logs/batch
+--          rejects entries past the quota until midnight UTC
+
+-- NULL means "no quota"
+ALTER TABLE logs.projects
+    ADD COLUMN IF NOT EXISTS daily_log_quota BIGINT
+    CHECK (daily_log_quota IS NULL OR daily_log_quota > 0);
+
+COMMENT ON COLUMN logs.projects.daily_log_quota IS 'Log entries batch ingestion accepts per UTC day; NULL is unlimited';
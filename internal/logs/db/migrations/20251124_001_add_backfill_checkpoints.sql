@@ -0,0 +1,18 @@
+-- Migration: Resumable backfill jobs
+-- Date: 2025-11-24
+-- Purpose: Record how far a backfill over logs.entries got (e.g. the
+--          severity_score backfill), so an interrupted run resumes after the
+--          last processed id instead of starting over
+
+CREATE TABLE IF NOT EXISTS logs.backfill_checkpoints (
+    job TEXT PRIMARY KEY,
+    last_id BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The severity backfill pages through unscored entries by id
+CREATE INDEX IF NOT EXISTS idx_logs_entries_unscored
+ON logs.entries(id)
+WHERE severity_score IS NULL;
+
+COMMENT ON TABLE logs.backfill_checkpoints IS 'Last logs.entries id processed by each backfill job';
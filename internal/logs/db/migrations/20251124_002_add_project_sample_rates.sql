@@ -0,0 +1,11 @@
+-- Migration: Per-project ingest sampling
+-- Date: 2025-11-24
+-- Purpose: Let high-volume projects keep one in N DEBUG/INFO entries at
+--          POST /api/logs/batch instead of storing every one
+
+-- NULL means "keep everything"; e.g. {"DEBUG": 10, "INFO": 2}
+ALTER TABLE logs.projects
+    ADD COLUMN IF NOT EXISTS sample_rates JSONB
+    CHECK (sample_rates IS NULL OR jsonb_typeof(sample_rates) = 'object');
+
+COMMENT ON COLUMN logs.projects.sample_rates IS 'Batch ingestion keeps one in N entries of each listed level; NULL keeps all';
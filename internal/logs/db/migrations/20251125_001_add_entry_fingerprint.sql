@@ -0,0 +1,14 @@
+-- Migration: Log message fingerprints
+-- Date: 2025-11-25
+-- Purpose: Group near-duplicate errors that differ only by ids, numbers,
+--          UUIDs, hex values or paths. Ingestion stores the fingerprint of
+--          the normalized message; GET /api/logs/fingerprints groups by it.
+
+-- NULL for entries stored before this migration
+ALTER TABLE logs.entries
+ADD COLUMN IF NOT EXISTS fingerprint VARCHAR(16);
+
+CREATE INDEX IF NOT EXISTS idx_logs_entries_fingerprint
+ON logs.entries(fingerprint, created_at DESC);
+
+COMMENT ON COLUMN logs.entries.fingerprint IS 'Signature of the message with variable parts (ids, numbers, UUIDs, hex, paths) stripped';
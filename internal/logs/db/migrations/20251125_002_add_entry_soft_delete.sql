@@ -0,0 +1,16 @@
+-- Migration: Soft delete for log entries
+-- Date: 2025-11-25
+-- Purpose: DELETE /api/logs moves entries to a trash instead of removing
+--          them. Trashed entries are hidden from queries, can be restored
+--          during the grace period and are purged by a background job after.
+
+-- NULL for live entries
+ALTER TABLE logs.entries
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Only trashed rows are indexed; the purge job and trash listing scan these
+CREATE INDEX IF NOT EXISTS idx_logs_entries_deleted_at
+ON logs.entries(project_id, deleted_at)
+WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN logs.entries.deleted_at IS 'When the entry was soft-deleted; purged once the trash grace period elapses';
@@ -0,0 +1,13 @@
+-- Migration: Zero-downtime API key rotation
+-- Date: 2025-11-26
+-- Purpose: POST /api/logs/projects/:id/rotate-key issues a new key while the
+--          old one keeps authenticating for a grace window, so clients can
+--          switch over without failed batches.
+
+-- The key replaced by the last rotation; NULL when there is none
+ALTER TABLE logs.projects
+    ADD COLUMN IF NOT EXISTS previous_api_key_hash VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS previous_api_key_expires_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN logs.projects.previous_api_key_hash IS 'Hash of the key replaced by the last rotation, accepted until previous_api_key_expires_at';
+COMMENT ON COLUMN logs.projects.previous_api_key_expires_at IS 'When the previous API key stops authenticating';
@@ -519,7 +519,8 @@ func setupPerformanceTestDB(t *testing.T) *sql.DB {
 			tags TEXT[],
 			correlation_id TEXT,
 			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			timestamp TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			deleted_at TIMESTAMPTZ
 		)
 	`)
 	require.NoError(t, err)
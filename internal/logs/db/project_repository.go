@@ -51,7 +51,8 @@ func (r *ProjectRepository) Create(ctx context.Context, project *logs_models.Pro
 func (r *ProjectRepository) GetByID(ctx context.Context, id int, userID int) (*logs_models.Project, error) {
 	query := `
 		SELECT id, user_id, name, slug, description, repository_url, api_key_hash, 
-		       created_at, updated_at, is_active
+		       created_at, updated_at, is_active, min_ingest_level, daily_log_quota, sample_rates,
+		       COALESCE(previous_api_key_hash, ''), previous_api_key_expires_at
 		FROM logs.projects
 		WHERE id = $1 AND user_id = $2
 	`
@@ -68,6 +69,11 @@ func (r *ProjectRepository) GetByID(ctx context.Context, id int, userID int) (*l
 		&project.CreatedAt,
 		&project.UpdatedAt,
 		&project.IsActive,
+		&project.MinIngestLevel,
+		&project.DailyLogQuota,
+		&project.SampleRates,
+		&project.PreviousAPIKeyHash,
+		&project.PreviousAPIKeyExpiresAt,
 	)
 
 	if err != nil {
@@ -85,7 +91,8 @@ func (r *ProjectRepository) GetByID(ctx context.Context, id int, userID int) (*l
 func (r *ProjectRepository) GetByIDGlobal(ctx context.Context, id int) (*logs_models.Project, error) {
 	query := `
 		SELECT id, user_id, name, slug, description, repository_url, api_key_hash, 
-		       created_at, updated_at, is_active
+		       created_at, updated_at, is_active, min_ingest_level, daily_log_quota, sample_rates,
+		       COALESCE(previous_api_key_hash, ''), previous_api_key_expires_at
 		FROM logs.projects
 		WHERE id = $1
 	`
@@ -102,6 +109,11 @@ func (r *ProjectRepository) GetByIDGlobal(ctx context.Context, id int) (*logs_mo
 		&project.CreatedAt,
 		&project.UpdatedAt,
 		&project.IsActive,
+		&project.MinIngestLevel,
+		&project.DailyLogQuota,
+		&project.SampleRates,
+		&project.PreviousAPIKeyHash,
+		&project.PreviousAPIKeyExpiresAt,
 	)
 
 	if err != nil {
@@ -118,7 +130,8 @@ func (r *ProjectRepository) GetByIDGlobal(ctx context.Context, id int) (*logs_mo
 func (r *ProjectRepository) GetBySlug(ctx context.Context, slug string, userID int) (*logs_models.Project, error) {
 	query := `
 		SELECT id, user_id, name, slug, description, repository_url, api_key_hash, 
-		       created_at, updated_at, is_active
+		       created_at, updated_at, is_active, min_ingest_level, daily_log_quota, sample_rates,
+		       COALESCE(previous_api_key_hash, ''), previous_api_key_expires_at
 		FROM logs.projects
 		WHERE slug = $1 AND user_id = $2
 	`
@@ -135,6 +148,11 @@ func (r *ProjectRepository) GetBySlug(ctx context.Context, slug string, userID i
 		&project.CreatedAt,
 		&project.UpdatedAt,
 		&project.IsActive,
+		&project.MinIngestLevel,
+		&project.DailyLogQuota,
+		&project.SampleRates,
+		&project.PreviousAPIKeyHash,
+		&project.PreviousAPIKeyExpiresAt,
 	)
 
 	if err != nil {
@@ -153,7 +171,8 @@ func (r *ProjectRepository) GetBySlug(ctx context.Context, slug string, userID i
 func (r *ProjectRepository) GetBySlugGlobal(ctx context.Context, slug string) (*logs_models.Project, error) {
 	query := `
 		SELECT id, user_id, name, slug, description, repository_url, api_key_hash, 
-		       created_at, updated_at, is_active
+		       created_at, updated_at, is_active, min_ingest_level, daily_log_quota, sample_rates,
+		       COALESCE(previous_api_key_hash, ''), previous_api_key_expires_at
 		FROM logs.projects
 		WHERE slug = $1 AND is_active = true
 	`
@@ -170,6 +189,11 @@ func (r *ProjectRepository) GetBySlugGlobal(ctx context.Context, slug string) (*
 		&project.CreatedAt,
 		&project.UpdatedAt,
 		&project.IsActive,
+		&project.MinIngestLevel,
+		&project.DailyLogQuota,
+		&project.SampleRates,
+		&project.PreviousAPIKeyHash,
+		&project.PreviousAPIKeyExpiresAt,
 	)
 
 	if err != nil {
@@ -193,7 +217,8 @@ func (r *ProjectRepository) FindByAPIToken(ctx context.Context, token string) (*
 	// Get all projects (we'll optimize with Redis later)
 	query := `
 		SELECT id, user_id, name, slug, description, repository_url, api_key_hash, 
-		       created_at, updated_at, is_active
+		       created_at, updated_at, is_active, min_ingest_level, daily_log_quota, sample_rates,
+		       COALESCE(previous_api_key_hash, ''), previous_api_key_expires_at
 		FROM logs.projects
 		ORDER BY created_at DESC
 	`
@@ -221,13 +246,20 @@ func (r *ProjectRepository) FindByAPIToken(ctx context.Context, token string) (*
 			&project.CreatedAt,
 			&project.UpdatedAt,
 			&project.IsActive,
+			&project.MinIngestLevel,
+			&project.DailyLogQuota,
+			&project.SampleRates,
+			&project.PreviousAPIKeyHash,
+			&project.PreviousAPIKeyExpiresAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("db: failed to scan project: %w", err)
 		}
 
-		// Compare provided token with stored hash using bcrypt
-		if ValidateAPIKey(token, project.APIKeyHash) {
+		// Compare provided token with stored hash using bcrypt; the key replaced
+		// by a rotation is accepted until its grace period ends
+		if ValidateAPIKey(token, project.APIKeyHash) ||
+			(project.PreviousKeyActive(time.Now()) && ValidateAPIKey(token, project.PreviousAPIKeyHash)) {
 			fmt.Printf("✅ FindByAPIToken: Found matching project ID=%d, slug=%s (checked %d projects)\n", project.ID, project.Slug, projectCount)
 			return &project, nil
 		}
@@ -260,7 +292,8 @@ func ValidateAPIKey(providedKey, storedHash string) bool {
 func (r *ProjectRepository) ListByUserID(ctx context.Context, userID int) ([]logs_models.Project, error) {
 	query := `
 		SELECT id, user_id, name, slug, description, repository_url, api_key_hash, 
-		       created_at, updated_at, is_active
+		       created_at, updated_at, is_active, min_ingest_level, daily_log_quota, sample_rates,
+		       COALESCE(previous_api_key_hash, ''), previous_api_key_expires_at
 		FROM logs.projects
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -290,6 +323,11 @@ func (r *ProjectRepository) ListByUserID(ctx context.Context, userID int) ([]log
 			&project.CreatedAt,
 			&project.UpdatedAt,
 			&project.IsActive,
+			&project.MinIngestLevel,
+			&project.DailyLogQuota,
+			&project.SampleRates,
+			&project.PreviousAPIKeyHash,
+			&project.PreviousAPIKeyExpiresAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("db: failed to scan project: %w", err)
@@ -308,8 +346,9 @@ func (r *ProjectRepository) ListByUserID(ctx context.Context, userID int) ([]log
 func (r *ProjectRepository) Update(ctx context.Context, project *logs_models.Project) error {
 	query := `
 		UPDATE logs.projects
-		SET name = $1, description = $2, repository_url = $3, is_active = $4, updated_at = $5
-		WHERE id = $6
+		SET name = $1, description = $2, repository_url = $3, is_active = $4, updated_at = $5,
+		    min_ingest_level = $6, daily_log_quota = $7, sample_rates = $8
+		WHERE id = $9
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
@@ -318,6 +357,9 @@ func (r *ProjectRepository) Update(ctx context.Context, project *logs_models.Pro
 		project.RepositoryURL,
 		project.IsActive,
 		time.Now(),
+		project.MinIngestLevel,
+		project.DailyLogQuota,
+		project.SampleRates,
 		project.ID,
 	)
 
@@ -338,10 +380,12 @@ func (r *ProjectRepository) Update(ctx context.Context, project *logs_models.Pro
 }
 
 // UpdateAPIToken updates the API token for a project (for token regeneration).
+// Any key kept by an earlier rotation stops working too.
 func (r *ProjectRepository) UpdateAPIToken(ctx context.Context, projectID int, newAPIToken string) error {
 	query := `
 		UPDATE logs.projects
-		SET api_key_hash = $1, updated_at = $2
+		SET api_key_hash = $1, updated_at = $2,
+		    previous_api_key_hash = NULL, previous_api_key_expires_at = NULL
 		WHERE id = $3
 	`
 
@@ -362,6 +406,33 @@ func (r *ProjectRepository) UpdateAPIToken(ctx context.Context, projectID int, n
 	return nil
 }
 
+// RotateAPIToken replaces a project's API key hash with newHash and keeps the
+// current one as the previous key, accepted until previousExpiresAt.
+func (r *ProjectRepository) RotateAPIToken(ctx context.Context, projectID int, newHash string, previousExpiresAt time.Time) error {
+	query := `
+		UPDATE logs.projects
+		SET previous_api_key_hash = api_key_hash, previous_api_key_expires_at = $2,
+		    api_key_hash = $1, updated_at = NOW()
+		WHERE id = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, newHash, previousExpiresAt, projectID)
+	if err != nil {
+		return fmt.Errorf("db: failed to rotate api token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("db: failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("db: project not found")
+	}
+
+	return nil
+}
+
 // Delete soft-deletes a project by setting is_active to false.
 func (r *ProjectRepository) Delete(ctx context.Context, id int) error {
 	query := `
@@ -0,0 +1,80 @@
+package logs_db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestProjectRepository_RotateAPIToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db, container := setupTestPostgres(t)
+	t.Cleanup(func() {
+		db.Close()
+		cleanupTestPostgres(t, container)
+	})
+
+	_, err := db.Exec(`
+		CREATE TABLE logs.projects (
+			id SERIAL PRIMARY KEY,
+			user_id INT,
+			name TEXT NOT NULL,
+			slug TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			repository_url TEXT NOT NULL DEFAULT '',
+			api_key_hash VARCHAR(255) NOT NULL,
+			is_active BOOLEAN NOT NULL DEFAULT true,
+			min_ingest_level TEXT,
+			daily_log_quota BIGINT,
+			sample_rates JSONB,
+			previous_api_key_hash VARCHAR(255),
+			previous_api_key_expires_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	require.NoError(t, err)
+
+	hash := func(key string) string {
+		h, err := bcrypt.GenerateFromPassword([]byte(key), bcrypt.MinCost)
+		require.NoError(t, err)
+		return string(h)
+	}
+	const oldKey, newKey = "dsk_old", "dsk_new"
+	var projectID int
+	require.NoError(t, db.QueryRow(`
+		INSERT INTO logs.projects (user_id, name, slug, api_key_hash)
+		VALUES (7, 'Orders', 'orders-api', $1) RETURNING id`, hash(oldKey)).Scan(&projectID))
+
+	repo := NewProjectRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.RotateAPIToken(ctx, projectID, hash(newKey), time.Now().Add(time.Hour)))
+
+	// Both keys authenticate during the grace period
+	for _, key := range []string{oldKey, newKey} {
+		project, err := repo.FindByAPIToken(ctx, key)
+		require.NoError(t, err, key)
+		assert.Equal(t, projectID, project.ID)
+	}
+
+	// Once it has expired, the old key is rejected
+	_, err = db.Exec(`UPDATE logs.projects SET previous_api_key_expires_at = NOW() - INTERVAL '1 second'`)
+	require.NoError(t, err)
+	_, err = repo.FindByAPIToken(ctx, oldKey)
+	assert.Error(t, err)
+	_, err = repo.FindByAPIToken(ctx, newKey)
+	assert.NoError(t, err)
+
+	// Regenerating drops the previous key outright
+	require.NoError(t, repo.RotateAPIToken(ctx, projectID, hash("dsk_newer"), time.Now().Add(time.Hour)))
+	require.NoError(t, repo.UpdateAPIToken(ctx, projectID, hash("dsk_newest")))
+	_, err = repo.FindByAPIToken(ctx, "dsk_newer")
+	assert.Error(t, err)
+}
@@ -228,7 +228,7 @@ func TestLogRepository_GetByID_Success(t *testing.T) {
 	repo := &LogRepository{}
 	ctx := context.Background()
 
-	entry, err := repo.GetByID(ctx, 1)
+	entry, err := repo.GetByID(ctx, 1, 0)
 	if err != nil {
 		t.Errorf("GetByID() error = %v", err)
 	}
@@ -242,7 +242,7 @@ func TestLogRepository_GetByID_NotFound(t *testing.T) {
 	repo := &LogRepository{}
 	ctx := context.Background()
 
-	entry, err := repo.GetByID(ctx, 999999)
+	entry, err := repo.GetByID(ctx, 999999, 0)
 	// Should either error or return nil, but not both
 	if err == nil && entry != nil {
 		t.Error("GetByID() should handle not found case properly")
@@ -261,7 +261,7 @@ func TestLogRepository_GetByID_InvalidID(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &LogRepository{}
-			_, err := repo.GetByID(context.Background(), tt.id)
+			_, err := repo.GetByID(context.Background(), tt.id, 0)
 			if err == nil {
 				t.Errorf("GetByID() with %s should validate", tt.name)
 			}
@@ -277,7 +277,7 @@ func TestLogRepository_GetStats_ReturnsAggregates(t *testing.T) {
 	repo := &LogRepository{}
 	ctx := context.Background()
 
-	stats, err := repo.GetStats(ctx)
+	stats, err := repo.GetStats(ctx, 0)
 	if err != nil {
 		t.Errorf("GetStats() error = %v", err)
 	}
@@ -291,7 +291,7 @@ func TestLogRepository_GetStats_ByLevel(t *testing.T) {
 	repo := &LogRepository{}
 	ctx := context.Background()
 
-	stats, err := repo.GetStats(ctx)
+	stats, err := repo.GetStats(ctx, 0)
 	if err != nil {
 		t.Errorf("GetStats() error = %v", err)
 	}
@@ -308,7 +308,7 @@ func TestLogRepository_GetStats_ByService(t *testing.T) {
 	repo := &LogRepository{}
 	ctx := context.Background()
 
-	stats, err := repo.GetStats(ctx)
+	stats, err := repo.GetStats(ctx, 0)
 	if err != nil {
 		t.Errorf("GetStats() error = %v", err)
 	}
@@ -325,7 +325,7 @@ func TestLogRepository_GetStats_TotalCount(t *testing.T) {
 	repo := &LogRepository{}
 	ctx := context.Background()
 
-	stats, err := repo.GetStats(ctx)
+	stats, err := repo.GetStats(ctx, 0)
 	if err != nil {
 		t.Errorf("GetStats() error = %v", err)
 	}
@@ -509,6 +509,46 @@ func TestLogRepository_BulkInsert_LargeDataset(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// FUZZY SEARCH FALLBACK TESTS
+// ============================================================================
+
+func TestLogRepository_FuzzySearch_RequiresTerm(t *testing.T) {
+	repo := &LogRepository{}
+	ctx := context.Background()
+
+	if _, err := repo.FuzzySearch(ctx, &QueryFilters{Search: "  "}, 10); err == nil {
+		t.Error("FuzzySearch() should reject an empty search term")
+	}
+	if _, err := repo.FuzzySearch(ctx, &QueryFilters{Search: "conection"}, 0); err == nil {
+		t.Error("FuzzySearch() should reject a non-positive limit")
+	}
+}
+
+func TestLogRepository_FuzzySearch_NoDB(t *testing.T) {
+	repo := &LogRepository{}
+
+	matches, err := repo.FuzzySearch(context.Background(), &QueryFilters{Search: "conection", Level: "error"}, 10)
+	if err != nil {
+		t.Fatalf("FuzzySearch() error = %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("FuzzySearch() returned %d matches, want 0", len(matches))
+	}
+}
+
+func TestLogRepository_SuggestTerms_EmptyTerm(t *testing.T) {
+	repo := &LogRepository{}
+
+	suggestions, err := repo.SuggestTerms(context.Background(), "   ", 0, 5)
+	if err != nil {
+		t.Fatalf("SuggestTerms() error = %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Errorf("SuggestTerms() returned %v, want none", suggestions)
+	}
+}
+
 // ============================================================================
 // CONTEXT HANDLING
 // ============================================================================
@@ -582,3 +622,78 @@ func TestLogRepository_IndexUsageOnService(t *testing.T) {
 		t.Errorf("Query with service filter error = %v", err)
 	}
 }
+
+// ============================================================================
+// STREAMING EXPORT TESTS
+// ============================================================================
+
+func TestLogRepository_StreamQuery_NoDB(t *testing.T) {
+	repo := &LogRepository{}
+	calls := 0
+
+	err := repo.StreamQuery(context.Background(), &QueryFilters{Service: "portal"}, 0, func(*LogEntry) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamQuery() error = %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("StreamQuery() invoked callback %d times, want 0", calls)
+	}
+}
+
+func TestLogRepository_StreamQuery_Validation(t *testing.T) {
+	repo := &LogRepository{}
+
+	if err := repo.StreamQuery(context.Background(), nil, 0, nil); err == nil {
+		t.Error("StreamQuery() should reject a nil callback")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := repo.StreamQuery(ctx, nil, 0, func(*LogEntry) error { return nil }); err == nil {
+		t.Error("StreamQuery() should fail on a cancelled context")
+	}
+}
+
+// ============================================================================
+// RETENTION TESTS
+// ============================================================================
+
+func TestLogRepository_DeleteExpiredBatch_Validation(t *testing.T) {
+	repo := &LogRepository{}
+	ctx := context.Background()
+
+	if _, err := repo.DeleteExpiredBatch(ctx, time.Time{}, nil, 100); err == nil {
+		t.Error("DeleteExpiredBatch() should reject a zero cutoff")
+	}
+	if _, err := repo.DeleteExpiredBatch(ctx, time.Now(), nil, 0); err == nil {
+		t.Error("DeleteExpiredBatch() should reject a non-positive batch size")
+	}
+}
+
+func TestLogRepository_DeleteExpiredBatch_NoDB(t *testing.T) {
+	repo := &LogRepository{}
+	projectID := int64(3)
+
+	deleted, err := repo.DeleteExpiredBatch(context.Background(), time.Now(), &projectID, 100)
+	if err != nil {
+		t.Fatalf("DeleteExpiredBatch() error = %v", err)
+	}
+	if deleted != 0 {
+		t.Errorf("DeleteExpiredBatch() = %d, want 0", deleted)
+	}
+}
+
+func TestLogRepository_ListProjectRetention_NoDB(t *testing.T) {
+	repo := &LogRepository{}
+
+	overrides, err := repo.ListProjectRetention(context.Background())
+	if err != nil {
+		t.Fatalf("ListProjectRetention() error = %v", err)
+	}
+	if len(overrides) != 0 {
+		t.Errorf("ListProjectRetention() = %v, want empty", overrides)
+	}
+}
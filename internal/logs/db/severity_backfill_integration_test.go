@@ -0,0 +1,108 @@
+package logs_db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSeverityBackfillDB creates the logs.entries columns and checkpoint
+// table the severity backfill reads and writes.
+func setupSeverityBackfillDB(t *testing.T) *sql.DB {
+	db, container := setupTestPostgres(t)
+	t.Cleanup(func() {
+		db.Close()
+		cleanupTestPostgres(t, container)
+	})
+
+	_, err := db.Exec(`
+		CREATE TABLE logs.entries (
+			id BIGSERIAL PRIMARY KEY,
+			service TEXT NOT NULL,
+			level TEXT NOT NULL,
+			message TEXT NOT NULL,
+			metadata JSONB,
+			issue_type VARCHAR(50),
+			severity_score INT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE TABLE logs.backfill_checkpoints (
+			job TEXT PRIMARY KEY,
+			last_id BIGINT NOT NULL DEFAULT 0,
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestLogRepository_SeverityBackfill(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	db := setupSeverityBackfillDB(t)
+	repo := NewLogRepository(db)
+	ctx := context.Background()
+
+	ids := make([]int64, 0, 4)
+	for _, row := range []struct {
+		level, issueType string
+		score            sql.NullInt64
+	}{
+		{"INFO", "", sql.NullInt64{}},
+		{"ERROR", "db_connection", sql.NullInt64{}},
+		{"ERROR", "", sql.NullInt64{Int64: 5, Valid: true}},
+		{"WARN", "", sql.NullInt64{}},
+	} {
+		var id int64
+		require.NoError(t, db.QueryRow(`
+			INSERT INTO logs.entries (service, level, message, issue_type, severity_score, created_at)
+			VALUES ('portal', $1, 'msg', NULLIF($2, ''), $3, $4) RETURNING id`,
+			row.level, row.issueType, row.score, time.Now()).Scan(&id))
+		ids = append(ids, id)
+	}
+
+	t.Run("ListsOnlyUnscoredAfterID", func(t *testing.T) {
+		entries, err := repo.ListUnscoredBatch(ctx, 0, 2)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		assert.Equal(t, ids[0], entries[0].ID)
+		assert.Equal(t, ids[1], entries[1].ID)
+		assert.Equal(t, "db_connection", entries[1].IssueType)
+
+		entries, err = repo.ListUnscoredBatch(ctx, ids[1], 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1, "the scored entry is skipped")
+		assert.Equal(t, ids[3], entries[0].ID)
+	})
+
+	t.Run("SetsOnlyNullScores", func(t *testing.T) {
+		updated, err := repo.SetSeverityScores(ctx, map[int64]int{ids[0]: 1, ids[1]: 4, ids[2]: 3})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated)
+
+		var score int
+		require.NoError(t, db.QueryRow(`SELECT severity_score FROM logs.entries WHERE id = $1`, ids[2]).Scan(&score))
+		assert.Equal(t, 5, score, "an existing score is kept")
+
+		updated, err = repo.SetSeverityScores(ctx, map[int64]int{ids[0]: 2, ids[1]: 2})
+		require.NoError(t, err)
+		assert.Zero(t, updated, "re-running changes nothing")
+	})
+
+	t.Run("Checkpoint", func(t *testing.T) {
+		lastID, err := repo.GetBackfillCheckpoint(ctx, "severity_score")
+		require.NoError(t, err)
+		assert.Zero(t, lastID)
+
+		require.NoError(t, repo.SetBackfillCheckpoint(ctx, "severity_score", ids[1]))
+		require.NoError(t, repo.SetBackfillCheckpoint(ctx, "severity_score", ids[3]))
+		lastID, err = repo.GetBackfillCheckpoint(ctx, "severity_score")
+		require.NoError(t, err)
+		assert.Equal(t, ids[3], lastID)
+	})
+}
@@ -135,3 +135,46 @@ func TestBuildWhereClause_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildWhereClause_ContextFilters(t *testing.T) {
+	filters := &QueryFilters{
+		Context: []ContextFilter{
+			{Path: []string{"user_id"}, Op: "=", Value: "1000"},
+			{Path: []string{"request", "status_code"}, Op: ">=", Value: "400"},
+			{Path: []string{"env"}, Op: "!=", Value: "prod"},
+		},
+	}
+
+	fragments, args, argNum := buildWhereClause(filters)
+
+	require.Len(t, fragments, 3)
+	assert.Equal(t, "metadata #>> $1::text[] = $2", fragments[0])
+	assert.Contains(t, fragments[1], "(metadata #>> $3::text[])::numeric")
+	assert.Contains(t, fragments[1], ">= $4::numeric")
+	assert.Equal(t, "metadata #>> $5::text[] <> $6", fragments[2])
+	assert.Len(t, args, 6)
+	assert.Equal(t, 7, argNum)
+	assert.Equal(t, "400", args[3])
+}
+
+func TestBuildWhereClause_OwnerUserID(t *testing.T) {
+	fragments, args, argNum := buildWhereClause(&QueryFilters{Service: "orders", OwnerUserID: 7})
+
+	assert.Equal(t, []string{
+		"service = $1",
+		"project_id IN (SELECT id FROM logs.projects WHERE user_id = $2)",
+	}, fragments)
+	assert.Equal(t, []interface{}{"orders", 7}, args)
+	assert.Equal(t, 3, argNum)
+}
+
+func TestBuildWhereClause_ContextFilterUnknownOperatorMatchesNothing(t *testing.T) {
+	filters := &QueryFilters{
+		Context: []ContextFilter{{Path: []string{"a"}, Op: "; DROP TABLE logs.entries; --", Value: "1"}},
+	}
+
+	fragments, args, _ := buildWhereClause(filters)
+
+	assert.Equal(t, []string{"FALSE"}, fragments)
+	assert.Empty(t, args)
+}
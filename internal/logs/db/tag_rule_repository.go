@@ -0,0 +1,215 @@
+package logs_db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+)
+
+// TagRuleCacheTTL bounds how long compiled rules are reused before being
+// reloaded, so edits made through another replica are picked up.
+const TagRuleCacheTTL = time.Minute
+
+// TagRuleRepository handles CRUD operations for per-project tag rules and
+// serves compiled rules to the ingest path from a per-project cache.
+type TagRuleRepository struct {
+	db    *sql.DB
+	now   func() time.Time
+	cache map[int64]cachedTagRules
+	mu    sync.RWMutex
+}
+
+type cachedTagRules struct {
+	loadedAt time.Time
+	rules    []*logs_models.CompiledTagRule
+}
+
+// NewTagRuleRepository creates a new TagRuleRepository with the given database connection.
+func NewTagRuleRepository(db *sql.DB) *TagRuleRepository {
+	return &TagRuleRepository{
+		db:    db,
+		now:   time.Now,
+		cache: make(map[int64]cachedTagRules),
+	}
+}
+
+// ListByProject returns a project's rules, oldest first. It returns
+// sql.ErrNoRows if the project does not exist or doesn't belong to userID.
+func (r *TagRuleRepository) ListByProject(ctx context.Context, projectID int64, userID int) ([]logs_models.ProjectTagRule, error) {
+	if r.db == nil {
+		return []logs_models.ProjectTagRule{}, nil
+	}
+
+	var owned int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT 1 FROM logs.projects WHERE id = $1 AND user_id = $2`, projectID, userID).Scan(&owned)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to check project owner: %w", err)
+	}
+
+	return r.listRules(ctx, projectID)
+}
+
+// listRules returns a project's rules, oldest first, whoever owns it.
+// It serves the ingest path, which is already scoped by API key.
+func (r *TagRuleRepository) listRules(ctx context.Context, projectID int64) ([]logs_models.ProjectTagRule, error) {
+	if r.db == nil {
+		return []logs_models.ProjectTagRule{}, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, project_id, message_pattern, level, service, tag, created_at, updated_at
+		FROM logs.project_tag_rules
+		WHERE project_id = $1
+		ORDER BY id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to list tag rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []logs_models.ProjectTagRule{}
+	for rows.Next() {
+		var rule logs_models.ProjectTagRule
+		if err := rows.Scan(&rule.ID, &rule.ProjectID, &rule.MessagePattern, &rule.Level,
+			&rule.Service, &rule.Tag, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("db: failed to scan tag rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db: tag rule rows error: %w", err)
+	}
+	return rules, nil
+}
+
+// Create inserts a rule and returns it with ID and timestamps set.
+// The rule should already have passed Validate. It returns sql.ErrNoRows if
+// the rule's project does not exist or doesn't belong to userID.
+func (r *TagRuleRepository) Create(ctx context.Context, rule *logs_models.ProjectTagRule, userID int) (*logs_models.ProjectTagRule, error) {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO logs.project_tag_rules (project_id, message_pattern, level, service, tag)
+		SELECT id, $2, $3, $4, $5
+		FROM logs.projects
+		WHERE id = $1 AND user_id = $6
+		RETURNING id, created_at, updated_at`,
+		rule.ProjectID, rule.MessagePattern, rule.Level, rule.Service, rule.Tag, userID,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to create tag rule: %w", err)
+	}
+
+	r.invalidate(rule.ProjectID)
+	return rule, nil
+}
+
+// Update replaces a rule's conditions and tag. It returns sql.ErrNoRows if
+// the rule does not exist in the given project or the project doesn't
+// belong to userID.
+func (r *TagRuleRepository) Update(ctx context.Context, rule *logs_models.ProjectTagRule, userID int) (*logs_models.ProjectTagRule, error) {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE logs.project_tag_rules
+		SET message_pattern = $1, level = $2, service = $3, tag = $4, updated_at = NOW()
+		WHERE id = $5 AND project_id = $6
+		  AND project_id IN (SELECT id FROM logs.projects WHERE user_id = $7)
+		RETURNING created_at, updated_at`,
+		rule.MessagePattern, rule.Level, rule.Service, rule.Tag, rule.ID, rule.ProjectID, userID,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to update tag rule: %w", err)
+	}
+
+	r.invalidate(rule.ProjectID)
+	return rule, nil
+}
+
+// Delete removes a rule. It returns sql.ErrNoRows if the rule does not
+// exist in the given project or the project doesn't belong to userID.
+func (r *TagRuleRepository) Delete(ctx context.Context, projectID, id int64, userID int) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM logs.project_tag_rules
+		WHERE id = $1 AND project_id = $2
+		  AND project_id IN (SELECT id FROM logs.projects WHERE user_id = $3)`, id, projectID, userID)
+	if err != nil {
+		return fmt.Errorf("db: failed to delete tag rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("db: failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	r.invalidate(projectID)
+	return nil
+}
+
+// CompiledRules returns a project's rules with their regexes compiled,
+// loading them at most once per TagRuleCacheTTL.
+func (r *TagRuleRepository) CompiledRules(ctx context.Context, projectID int64) ([]*logs_models.CompiledTagRule, error) {
+	r.mu.RLock()
+	cached, ok := r.cache[projectID]
+	r.mu.RUnlock()
+	if ok && r.now().Sub(cached.loadedAt) < TagRuleCacheTTL {
+		return cached.rules, nil
+	}
+
+	rules, err := r.listRules(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := make([]*logs_models.CompiledTagRule, 0, len(rules))
+	for i := range rules {
+		// Rules are validated on write; one that no longer compiles is skipped
+		// rather than blocking ingestion for the whole project
+		if c, err := rules[i].Compile(); err == nil {
+			compiled = append(compiled, c)
+		}
+	}
+
+	r.store(projectID, compiled)
+	return compiled, nil
+}
+
+// ApplyTagRules adds rule-derived tags to entries. Entries without a
+// project are left untouched.
+func (r *TagRuleRepository) ApplyTagRules(ctx context.Context, entries []*logs_models.LogEntry) error {
+	for _, entry := range entries {
+		if entry.ProjectID == nil {
+			continue
+		}
+		rules, err := r.CompiledRules(ctx, *entry.ProjectID)
+		if err != nil {
+			return err
+		}
+		logs_models.ApplyTagRules(rules, entry)
+	}
+	return nil
+}
+
+func (r *TagRuleRepository) store(projectID int64, rules []*logs_models.CompiledTagRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache[projectID] = cachedTagRules{rules: rules, loadedAt: r.now()}
+}
+
+func (r *TagRuleRepository) invalidate(projectID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cache, projectID)
+}
@@ -2,6 +2,7 @@ package internal_logs_handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -62,7 +63,11 @@ func (h *AIInsightsHandler) GenerateInsights(c *gin.Context) {
 	}
 
 	// Generate insights
-	insight, err := h.service.GenerateInsights(c.Request.Context(), logID, req.Model)
+	insight, err := h.service.GenerateInsights(c.Request.Context(), logID, req.Model, c.GetInt("user_id"))
+	if errors.Is(err, logs_services.ErrLogNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log not found"})
+		return
+	}
 	if err != nil {
 		// Log the AI Insights failure to the logs system
 		h.logger.WithFields(logrus.Fields{
@@ -116,7 +121,11 @@ func (h *AIInsightsHandler) GetInsights(c *gin.Context) {
 	}
 
 	// Get insights from database
-	insight, err := h.service.GetInsights(c.Request.Context(), logID)
+	insight, err := h.service.GetInsights(c.Request.Context(), logID, c.GetInt("user_id"))
+	if errors.Is(err, logs_services.ErrLogNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -129,3 +138,57 @@ func (h *AIInsightsHandler) GetInsights(c *gin.Context) {
 
 	c.JSON(http.StatusOK, insight)
 }
+
+// CorrelatedInsightsRequest is the request body for POST /api/logs/insights/correlated.
+type CorrelatedInsightsRequest struct {
+	Start     time.Time `json:"start" binding:"required"` // RFC 3339
+	End       time.Time `json:"end" binding:"required"`   // RFC 3339, exclusive
+	ProjectID *int64    `json:"project_id,omitempty"`
+	Model     string    `json:"model" binding:"required"`
+	Service   string    `json:"service,omitempty"`
+	Level     string    `json:"level,omitempty"`
+	Refresh   bool      `json:"refresh,omitempty"` // Ignore a cached result
+}
+
+// GenerateCorrelatedInsights handles POST /api/logs/insights/correlated
+// Analyzes the logs in a time window (optionally filtered) as one group and
+// returns the insight with the ids of the logs it was based on
+func (h *AIInsightsHandler) GenerateCorrelatedInsights(c *gin.Context) {
+	var req CorrelatedInsightsRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body - start, end and model are required",
+			"details": bindErr.Error(),
+		})
+		return
+	}
+
+	insight, err := h.service.GenerateCorrelatedInsights(c.Request.Context(), logs_services.CorrelatedInsightRequest{
+		Start:     req.Start,
+		End:       req.End,
+		ProjectID: req.ProjectID,
+		Model:     req.Model,
+		Service:   req.Service,
+		Level:     req.Level,
+		Refresh:   req.Refresh,
+		UserID:    c.GetInt("user_id"),
+	})
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, insight)
+	case errors.Is(err, logs_services.ErrInvalidCorrelationWindow):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, logs_services.ErrNoCorrelatedLogs):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, logs_services.ErrCorrelationDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		h.logger.WithFields(logrus.Fields{
+			"start": req.Start,
+			"end":   req.End,
+			"model": req.Model,
+			"error": err.Error(),
+		}).Error("Correlated AI Insights generation failed")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,124 @@
+package internal_logs_handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	logs_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/services"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// ownedInsightLogs serves log 1, which belongs to a project of user 7.
+type ownedInsightLogs struct {
+	windowOwner int
+}
+
+func (o *ownedInsightLogs) GetByID(_ context.Context, id int64, ownerUserID int) (*logs_models.LogEntry, error) {
+	if id != 1 || (ownerUserID != 0 && ownerUserID != 7) {
+		return nil, fmt.Errorf("log entry not found: %w", sql.ErrNoRows)
+	}
+	return &logs_models.LogEntry{ID: 1, Level: "ERROR", Message: "boom"}, nil
+}
+
+func (o *ownedInsightLogs) FindInWindow(_ context.Context, filter logs_db.WindowFilter, _ int) ([]logs_models.LogEntry, error) {
+	o.windowOwner = filter.OwnerUserID
+	return []logs_models.LogEntry{{ID: 1, Level: "ERROR", Message: "boom"}}, nil
+}
+
+// memoryInsights stores insights for both single logs and windows.
+type memoryInsights struct{}
+
+func (memoryInsights) Upsert(_ context.Context, insight *logs_models.AIInsight) (*logs_models.AIInsight, error) {
+	return insight, nil
+}
+
+func (memoryInsights) GetByLogID(_ context.Context, logID int64) (*logs_models.AIInsight, error) {
+	return &logs_models.AIInsight{LogID: logID, Analysis: "stored"}, nil
+}
+
+func (memoryInsights) GetCorrelated(_ context.Context, _ string) (*logs_models.CorrelatedInsight, error) {
+	return nil, nil
+}
+
+func (memoryInsights) UpsertCorrelated(_ context.Context, insight *logs_models.CorrelatedInsight) (*logs_models.CorrelatedInsight, error) {
+	return insight, nil
+}
+
+type fixedInsightAI struct{}
+
+func (fixedInsightAI) Generate(_ context.Context, _ *logs_services.AIRequest) (*logs_services.AIResponse, error) {
+	return &logs_services.AIResponse{Content: `{"analysis":"a","root_cause":"b","suggestions":["c"]}`}, nil
+}
+
+func setupInsightRoutes(userID int) (*gin.Engine, *ownedInsightLogs) {
+	gin.SetMode(gin.TestMode)
+	logs := &ownedInsightLogs{}
+	svc := logs_services.NewAIInsightsService(fixedInsightAI{}, logs, memoryInsights{})
+	svc.SetCorrelation(logs, memoryInsights{})
+	svc.SetAdmins([]int{1})
+	handler := NewAIInsightsHandler(svc, logrus.New(), nil)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", userID) })
+	router.POST("/api/logs/:id/insights", handler.GenerateInsights)
+	router.GET("/api/logs/:id/insights", handler.GetInsights)
+	router.POST("/api/logs/insights/correlated", handler.GenerateCorrelatedInsights)
+	return router, logs
+}
+
+func serveInsights(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGenerateInsights_OnlyOwnedLogs(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		userID int
+		want   int
+	}{
+		{"owner", 7, http.StatusOK},
+		{"admin", 1, http.StatusOK},
+		{"another user", 8, http.StatusNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := setupInsightRoutes(tt.userID)
+			w := serveInsights(router, http.MethodPost, "/api/logs/1/insights", `{"model":"m"}`)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestGetInsights_OnlyOwnedLogs(t *testing.T) {
+	router, _ := setupInsightRoutes(7)
+	assert.Equal(t, http.StatusOK, serveInsights(router, http.MethodGet, "/api/logs/1/insights", "").Code)
+
+	router, _ = setupInsightRoutes(8)
+	w := serveInsights(router, http.MethodGet, "/api/logs/1/insights", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotContains(t, w.Body.String(), "stored")
+}
+
+func TestGenerateCorrelatedInsights_ScopesWindowToCaller(t *testing.T) {
+	body := `{"start":"2025-11-26T09:00:00Z","end":"2025-11-26T10:00:00Z","model":"m"}`
+
+	router, logs := setupInsightRoutes(8)
+	assert.Equal(t, http.StatusOK, serveInsights(router, http.MethodPost, "/api/logs/insights/correlated", body).Code)
+	assert.Equal(t, 8, logs.windowOwner, "without project_id only the caller's projects are analyzed")
+
+	router, logs = setupInsightRoutes(1)
+	assert.Equal(t, http.StatusOK, serveInsights(router, http.MethodPost, "/api/logs/insights/correlated", body).Code)
+	assert.Zero(t, logs.windowOwner, "admins analyze every project")
+}
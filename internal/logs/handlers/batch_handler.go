@@ -4,14 +4,23 @@ package internal_logs_handlers
 import (
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_metrics "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/metrics"
 	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
 	logs_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/services"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // BatchHandler handles batch log ingestion for cross-repo logging.
@@ -19,6 +28,9 @@ type BatchHandler struct {
 	logRepo     *logs_db.LogEntryRepository
 	projectRepo *logs_db.ProjectRepository
 	projectSvc  *logs_services.ProjectService
+	metrics     *logs_metrics.IngestMetrics
+	deadLetter  *logs_services.DeadLetterQueue
+	quota       *logs_services.LogQuota
 }
 
 // NewBatchHandler creates a new BatchHandler.
@@ -34,25 +46,254 @@ func NewBatchHandler(
 	}
 }
 
+// SetMetrics enables Prometheus ingestion metrics for this handler.
+func (h *BatchHandler) SetMetrics(m *logs_metrics.IngestMetrics) {
+	h.metrics = m
+}
+
+// SetDeadLetter makes batches that fail to insert because storage is
+// unavailable go to q for later replay instead of being lost.
+func (h *BatchHandler) SetDeadLetter(q *logs_services.DeadLetterQueue) {
+	h.deadLetter = q
+}
+
+// SetQuota enforces each project's daily_log_quota using q.
+func (h *BatchHandler) SetQuota(q *logs_services.LogQuota) {
+	h.quota = q
+}
+
+// MinIngestLevelHeader overrides the project's min_ingest_level for one
+// batch, e.g. to let DEBUG through while investigating an issue.
+const MinIngestLevelHeader = "X-Min-Ingest-Level"
+
 // BatchLogEntry represents a single log entry in a batch request.
 type BatchLogEntry struct {
-	Timestamp   string                 `json:"timestamp"`              // ISO 8601 timestamp
-	Level       string                 `json:"level"`                  // debug, info, warn, error
-	Message     string                 `json:"message"`                // Log message
-	ServiceName string                 `json:"service_name,omitempty"` // Microservice identifier
-	Context     map[string]interface{} `json:"context,omitempty"`      // Additional context
+	Timestamp      string                 `json:"timestamp"`                 // ISO 8601 timestamp
+	Level          string                 `json:"level"`                     // debug, info, warn, error
+	Message        string                 `json:"message"`                   // Log message
+	ServiceName    string                 `json:"service_name,omitempty"`    // Microservice identifier
+	Context        map[string]interface{} `json:"context,omitempty"`         // Additional context
+	Tags           []string               `json:"tags,omitempty"`            // Optional tags, validated by LogEntry.Validate
+	IdempotencyKey string                 `json:"idempotency_key,omitempty"` // Optional dedup key, unique per project
+	CorrelationID  string                 `json:"correlation_id,omitempty"`  // Optional; falls back to context["correlation_id"]
 }
 
 // BatchLogRequest represents the batch ingestion request payload.
+//
+// IdempotencyKey covers the whole batch: entry i without its own key gets
+// "<batch key>:<i>", so resending the identical batch is a no-op.
 type BatchLogRequest struct {
-	ProjectSlug string          `json:"project_slug" binding:"required"` // Project identifier
-	Logs        []BatchLogEntry `json:"logs" binding:"required,min=1"`   // Array of log entries
+	ProjectSlug    string          `json:"project_slug" binding:"required"` // Project identifier
+	IdempotencyKey string          `json:"idempotency_key,omitempty"`       // Optional per-batch dedup key
+	Logs           []BatchLogEntry `json:"logs" binding:"required,min=1"`   // Array of log entries
 }
 
 // BatchLogResponse represents the batch ingestion response.
+// Accepted is kept for older clients and equals Inserted + Deduped.
 type BatchLogResponse struct {
-	Accepted int    `json:"accepted"` // Number of logs accepted
-	Message  string `json:"message"`
+	Message     string              `json:"message"`
+	Failed      []BatchEntryFailure `json:"failed"`       // Entries that were not stored, with the reason
+	FailedCount int                 `json:"failed_count"` // Number of logs that were not stored; len(Failed)
+	Accepted    int                 `json:"accepted"`     // Number of logs accepted (inserted or already present)
+	Inserted    int                 `json:"inserted"`     // Number of logs written by this request
+	Deduped     int                 `json:"deduped"`      // Number of logs skipped as retries of an idempotency key
+	Filtered    int                 `json:"filtered"`     // Number of logs dropped for being below the minimum ingest level
+	SampledOut  int                 `json:"sampled_out"`  // Number of logs dropped by the project's sample rates
+	Queued      int                 `json:"queued"`       // Number of logs held for replay because storage was unavailable
+}
+
+// BatchEntryFailure explains why the entry at Index of the request was not stored.
+type BatchEntryFailure struct {
+	Reason string `json:"reason"`
+	Index  int    `json:"index"`
+}
+
+// buildBatchEntries converts and validates the request's entries. Invalid
+// entries are left out and reported as failures, in request order.
+// positions maps each built entry back to its index in the request.
+func buildBatchEntries(req *BatchLogRequest, projectID int64) (entries []*logs_models.LogEntry, failed []BatchEntryFailure, positions map[*logs_models.LogEntry]int) {
+	entries = make([]*logs_models.LogEntry, 0, len(req.Logs))
+	failed = []BatchEntryFailure{}
+	positions = make(map[*logs_models.LogEntry]int, len(req.Logs))
+
+	for i, logEntry := range req.Logs {
+		timestamp, err := time.Parse(time.RFC3339, logEntry.Timestamp)
+		if err != nil {
+			failed = append(failed, BatchEntryFailure{Index: i, Reason: "timestamp: must be an ISO 8601 (RFC 3339) time"})
+			continue
+		}
+
+		metadataBytes := []byte("{}")
+		if logEntry.Context != nil {
+			if metadataBytes, err = json.Marshal(logEntry.Context); err != nil {
+				failed = append(failed, BatchEntryFailure{Index: i, Reason: fmt.Sprintf("context: %v", err)})
+				continue
+			}
+		}
+
+		tags := logEntry.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		entry := &logs_models.LogEntry{
+			ProjectID:      &projectID,
+			Service:        "external", // Mark as external log source
+			ServiceName:    logEntry.ServiceName,
+			Level:          strings.ToUpper(logEntry.Level),
+			Message:        logEntry.Message,
+			Metadata:       metadataBytes,
+			Tags:           tags,
+			Timestamp:      timestamp,
+			IdempotencyKey: entryIdempotencyKey(req.IdempotencyKey, i, logEntry),
+			CorrelationID:  entryCorrelationID(logEntry),
+		}
+		if err := entry.Validate(); err != nil {
+			failed = append(failed, BatchEntryFailure{Index: i, Reason: err.Error()})
+			continue
+		}
+
+		entries = append(entries, entry)
+		positions[entry] = i
+	}
+
+	return entries, failed, positions
+}
+
+// withStorageFailures adds a failure for each of entries, which could not be
+// stored because of err, to failed and returns the list in request order.
+func withStorageFailures(failed []BatchEntryFailure, entries []*logs_models.LogEntry, positions map[*logs_models.LogEntry]int, err error) []BatchEntryFailure {
+	reason := fmt.Sprintf("storage: %v", err)
+	for _, entry := range entries {
+		failed = append(failed, BatchEntryFailure{Index: positions[entry], Reason: reason})
+	}
+	sort.Slice(failed, func(i, j int) bool { return failed[i].Index < failed[j].Index })
+	return failed
+}
+
+// resolveMinIngestLevel returns the level below which entries are dropped:
+// the header override when present, otherwise the project setting.
+// An empty result keeps every entry.
+func resolveMinIngestLevel(project *logs_models.Project, override string) (string, error) {
+	if override = strings.TrimSpace(override); override != "" {
+		if _, ok := logs_models.LevelRank(override); !ok {
+			return "", fmt.Errorf("invalid %s %q: must be one of DEBUG, INFO, WARN, ERROR, FATAL", MinIngestLevelHeader, override)
+		}
+		return strings.ToUpper(override), nil
+	}
+	if project.MinIngestLevel != nil {
+		return *project.MinIngestLevel, nil
+	}
+	return "", nil
+}
+
+// filterBelowLevel drops entries below minLevel, returning the kept entries
+// and how many were dropped.
+func filterBelowLevel(entries []*logs_models.LogEntry, minLevel string) (kept []*logs_models.LogEntry, filtered int) {
+	if minLevel == "" {
+		return entries, 0
+	}
+	kept = entries[:0]
+	for _, entry := range entries {
+		if logs_models.LevelAtLeast(entry.Level, minLevel) {
+			kept = append(kept, entry)
+		} else {
+			filtered++
+		}
+	}
+	return kept, filtered
+}
+
+// sampleEntries keeps one in every rates[level] entries, returning the kept
+// entries and how many were dropped. The decision hashes the entry's level,
+// service, message and timestamp, so a resent entry always gets the same
+// outcome rather than being kept on one attempt and dropped on the next.
+func sampleEntries(entries []*logs_models.LogEntry, rates logs_models.SampleRates) (kept []*logs_models.LogEntry, sampledOut int) {
+	if len(rates) == 0 {
+		return entries, 0
+	}
+	kept = entries[:0]
+	for _, entry := range entries {
+		if n := rates[entry.Level]; n > 1 && sampleHash(entry)%uint64(n) != 0 {
+			sampledOut++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	return kept, sampledOut
+}
+
+// sampleHash hashes the fields identifying a logical event with FNV-1a.
+// Only the high 32 bits are returned: FNV's low bits mix poorly, so inputs
+// differing only in a millisecond timestamp would all land on one residue.
+func sampleHash(entry *logs_models.LogEntry) uint64 {
+	h := fnv.New64a()
+	for _, field := range []string{entry.Level, entry.ServiceName, entry.Message, strconv.FormatInt(entry.Timestamp.UnixNano(), 10)} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return h.Sum64() >> 32
+}
+
+// entryIdempotencyKey returns the dedup key for entry i of a batch.
+// Keys are scoped per project by the unique index, not by this function.
+func entryIdempotencyKey(batchKey string, i int, entry BatchLogEntry) string {
+	if entry.IdempotencyKey != "" {
+		return entry.IdempotencyKey
+	}
+	if batchKey != "" {
+		return fmt.Sprintf("%s:%d", batchKey, i)
+	}
+	return ""
+}
+
+// entryCorrelationID returns the entry's correlation ID: the dedicated field
+// when set, otherwise a string context["correlation_id"] as sent by clients
+// that only attach context.
+func entryCorrelationID(entry BatchLogEntry) string {
+	if entry.CorrelationID != "" {
+		return entry.CorrelationID
+	}
+	if id, ok := entry.Context["correlation_id"].(string); ok {
+		return id
+	}
+	return ""
+}
+
+// reserveQuota counts n entries against the project's daily quota and sets
+// the X-Quota-* headers. When the batch doesn't fit it responds 429, with
+// Retry-After pointing at midnight UTC, and returns false. If the quota store
+// is unreachable the batch is let through, as with the rate limiter. The
+// returned usage is what to hand to Release if the batch isn't stored.
+func (h *BatchHandler) reserveQuota(c *gin.Context, project *logs_models.Project, n int) (logs_services.QuotaUsage, bool) {
+	if h.quota == nil || project.DailyLogQuota == nil {
+		return logs_services.QuotaUsage{}, true
+	}
+
+	usage, allowed, err := h.quota.Reserve(c.Request.Context(), project.ID, project.DailyLogQuota, n)
+	if err != nil {
+		fmt.Printf("WARN: Log quota unavailable, allowing batch - project_id=%d, error=%v\n", project.ID, err)
+		return usage, true
+	}
+
+	c.Header("X-Quota-Limit", strconv.FormatInt(*usage.Limit, 10))
+	c.Header("X-Quota-Remaining", strconv.FormatInt(*usage.Remaining, 10))
+	c.Header("X-Quota-Reset", strconv.FormatInt(usage.ResetAt.Unix(), 10))
+	if allowed {
+		return usage, true
+	}
+
+	retryAfter := int(math.Ceil(time.Until(usage.ResetAt).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error": "Daily log quota exceeded",
+		"message": fmt.Sprintf("Project '%s' may ingest %d log entries per day; %d remain, %d were sent. The quota resets at %s.",
+			project.Slug, *usage.Limit, *usage.Remaining, n, usage.ResetAt.Format(time.RFC3339)),
+	})
+	return usage, false
 }
 
 // IngestBatch handles POST /api/logs/batch for batch log ingestion.
@@ -60,6 +301,23 @@ type BatchLogResponse struct {
 //
 // Performance: 100 logs in ~50ms (vs 3000ms for individual requests)
 //
+// Invalid entries don't fail the batch: the valid ones are stored and the
+// response is 207 with a "failed" list of {index, reason}. A batch with no
+// valid entries is rejected with 400. "failed_count" always equals the
+// length of "failed"; when the insert itself fails, every entry that was
+// sent to storage is listed too, with a "storage: ..." reason.
+//
+// Entries below the project's min_ingest_level (or the X-Min-Ingest-Level
+// header, when sent) are dropped silently and counted as "filtered".
+// Projects with sample_rates then keep one in N entries of each sampled
+// level; the rest are counted as "sampled_out".
+//
+// Projects with a daily_log_quota get 429 once a batch would take them past
+// it; the quota resets at midnight UTC.
+//
+// When the insert fails and a dead-letter queue is set, the batch is queued
+// for replay and the response is 202 with the entries counted as "queued".
+//
 // Authentication: None (designed for internal service communication)
 // Future: Add authentication when needed for external services
 func (h *BatchHandler) IngestBatch(c *gin.Context) {
@@ -117,76 +375,102 @@ func (h *BatchHandler) IngestBatch(c *gin.Context) {
 		return
 	}
 
-	// Step 6: Convert batch entries to LogEntry models
-	entries := make([]*logs_models.LogEntry, 0, len(req.Logs))
-	projectID := int64(project.ID)
+	minLevel, err := resolveMinIngestLevel(project, c.GetHeader(MinIngestLevelHeader))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
-	for i, logEntry := range req.Logs {
-		// Parse timestamp
-		timestamp, err := time.Parse(time.RFC3339, logEntry.Timestamp)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": fmt.Sprintf("Invalid timestamp format at index %d: %v", i, err),
-			})
-			return
-		}
+	// Step 6: Convert batch entries to LogEntry models; invalid ones are
+	// reported back instead of failing the whole batch
+	entries, failed, positions := buildBatchEntries(&req, int64(project.ID))
+	if len(entries) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":        "No valid log entries in batch",
+			"failed":       failed,
+			"failed_count": len(failed),
+		})
+		return
+	}
 
-		// Validate level
-		level := strings.ToUpper(logEntry.Level)
-		validLevels := map[string]bool{
-			"DEBUG": true,
-			"INFO":  true,
-			"WARN":  true,
-			"ERROR": true,
-		}
-		if !validLevels[level] {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": fmt.Sprintf("Invalid log level '%s' at index %d. Must be: debug, info, warn, error", logEntry.Level, i),
-			})
-			return
-		}
+	entries, filtered := filterBelowLevel(entries, minLevel)
+	entries, sampledOut := sampleEntries(entries, project.SampleRates)
 
-		// Convert context map to JSON bytes
-		var metadataBytes []byte
-		if logEntry.Context != nil {
-			metadataBytes, err = json.Marshal(logEntry.Context)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error": fmt.Sprintf("Invalid context at index %d: %v", i, err),
+	reserved, ok := h.reserveQuota(c, project, len(entries))
+	if !ok {
+		return
+	}
+
+	// Step 7: Insert batch using optimized CreateBatch method
+	insertCtx, span := otel.Tracer("devsmith-logs").Start(ctx, "logs.batch.insert", trace.WithAttributes(
+		attribute.String("project.slug", project.Slug),
+		attribute.Int("batch.entries", len(entries)),
+	))
+	insertStart := time.Now()
+	result, err := h.logRepo.CreateBatch(insertCtx, entries)
+	h.metrics.ObserveDuration(time.Since(insertStart))
+	h.metrics.ObserveBatch(len(entries))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "insert failed")
+		span.End()
+		fmt.Printf("ERROR: Failed to insert batch logs - project_id=%d, entry_count=%d, error=%v\n", project.ID, len(entries), err)
+		// Only an unavailable database is worth a replay; anything else would
+		// fail the same way again
+		if h.deadLetter != nil && logs_services.IsTransientStorageError(err) {
+			dlqErr := h.deadLetter.Add(ctx, entries, err)
+			if dlqErr == nil {
+				c.JSON(http.StatusAccepted, BatchLogResponse{
+					Queued:      len(entries),
+					Failed:      failed,
+					FailedCount: len(failed),
+					Filtered:    filtered,
+					SampledOut:  sampledOut,
+					Message:     fmt.Sprintf("Storage unavailable: %d log entries queued for replay", len(entries)),
 				})
 				return
 			}
-		} else {
-			metadataBytes = []byte("{}")
+			fmt.Printf("ERROR: Failed to dead-letter batch logs - project_id=%d, error=%v\n", project.ID, dlqErr)
 		}
-
-		// Create LogEntry model
-		entry := &logs_models.LogEntry{
-			ProjectID:   &projectID,
-			Service:     "external", // Mark as external log source
-			ServiceName: logEntry.ServiceName,
-			Level:       level,
-			Message:     logEntry.Message,
-			Metadata:    metadataBytes,
-			Tags:        []string{}, // Empty tags for now
-			Timestamp:   timestamp,
+		if h.quota != nil {
+			// Nothing was stored, so the entries don't count against the quota
+			if releaseErr := h.quota.Release(ctx, reserved, len(entries)); releaseErr != nil {
+				fmt.Printf("WARN: Failed to release log quota - project_id=%d, error=%v\n", project.ID, releaseErr)
+			}
 		}
-
-		entries = append(entries, entry)
-	}
-
-	// Step 7: Insert batch using optimized CreateBatch method
-	if err := h.logRepo.CreateBatch(ctx, entries); err != nil {
-		fmt.Printf("ERROR: Failed to insert batch logs - project_id=%d, entry_count=%d, error=%v\n", project.ID, len(entries), err)
+		failed = withStorageFailures(failed, entries, positions, err)
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to insert logs: %v", err),
+			"error":        fmt.Sprintf("Failed to insert logs: %v", err),
+			"failed":       failed,
+			"failed_count": len(failed),
 		})
 		return
 	}
 
-	// Step 8: Return success response
-	c.JSON(http.StatusCreated, BatchLogResponse{
-		Accepted: len(entries),
-		Message:  fmt.Sprintf("Successfully ingested %d log entries", len(entries)),
+	span.SetAttributes(attribute.Int("batch.inserted", result.Inserted), attribute.Int("batch.deduped", result.Deduped))
+	span.End()
+
+	// Only rows really written are counted; deduped retries were counted the first time
+	for level, n := range result.InsertedByLevel {
+		h.metrics.IncIngested(project.Slug, level, n)
+	}
+
+	// Step 8: Return success response; 207 signals that some entries were rejected
+	status := http.StatusCreated
+	if len(failed) > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, BatchLogResponse{
+		Accepted:    result.Inserted + result.Deduped,
+		Inserted:    result.Inserted,
+		Deduped:     result.Deduped,
+		Failed:      failed,
+		FailedCount: len(failed),
+		Filtered:    filtered,
+		SampledOut:  sampledOut,
+		Message: fmt.Sprintf("Successfully ingested %d log entries (%d duplicates skipped, %d below minimum level, %d sampled out, %d invalid)",
+			result.Inserted, result.Deduped, filtered, sampledOut, len(failed)),
 	})
 }
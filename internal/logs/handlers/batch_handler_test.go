@@ -0,0 +1,267 @@
+package internal_logs_handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	logs_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/services"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		batchKey string
+		entry    BatchLogEntry
+		index    int
+		want     string
+	}{
+		{name: "no keys", want: ""},
+		{name: "entry key wins", batchKey: "batch-1", entry: BatchLogEntry{IdempotencyKey: "evt-42"}, want: "evt-42"},
+		{name: "derived from batch key", batchKey: "batch-1", index: 3, want: "batch-1:3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, entryIdempotencyKey(tt.batchKey, tt.index, tt.entry))
+		})
+	}
+}
+
+func TestFilterBelowLevel_ProjectAtWarn(t *testing.T) {
+	warn := "WARN"
+	project := &logs_models.Project{ID: 1, MinIngestLevel: &warn}
+
+	minLevel, err := resolveMinIngestLevel(project, "")
+	require.NoError(t, err)
+
+	entries := []*logs_models.LogEntry{
+		{Level: "DEBUG", Message: "cache probe"},
+		{Level: "INFO", Message: "request served"},
+		{Level: "ERROR", Message: "payment declined"},
+		{Level: "WARN", Message: "slow query"},
+		{Level: "DEBUG", Message: "cache probe"},
+	}
+
+	kept, filtered := filterBelowLevel(entries, minLevel)
+	assert.Equal(t, 3, filtered)
+	require.Len(t, kept, 2)
+	assert.Equal(t, "ERROR", kept[0].Level)
+	assert.Equal(t, "WARN", kept[1].Level)
+}
+
+func TestFilterBelowLevel_NoMinimumKeepsAll(t *testing.T) {
+	minLevel, err := resolveMinIngestLevel(&logs_models.Project{ID: 1}, "")
+	require.NoError(t, err)
+	assert.Empty(t, minLevel)
+
+	entries := []*logs_models.LogEntry{{Level: "DEBUG"}, {Level: "INFO"}}
+	kept, filtered := filterBelowLevel(entries, minLevel)
+	assert.Zero(t, filtered)
+	assert.Len(t, kept, 2)
+}
+
+func TestResolveMinIngestLevel_HeaderOverride(t *testing.T) {
+	warn := "WARN"
+	project := &logs_models.Project{ID: 1, MinIngestLevel: &warn}
+
+	minLevel, err := resolveMinIngestLevel(project, "debug")
+	require.NoError(t, err)
+	assert.Equal(t, "DEBUG", minLevel)
+
+	_, err = resolveMinIngestLevel(project, "verbose")
+	assert.Error(t, err)
+}
+
+func TestSampleEntries_KeepsAboutOneInN(t *testing.T) {
+	base := time.Date(2025, 11, 24, 12, 0, 0, 0, time.UTC)
+	entries := make([]*logs_models.LogEntry, 0, 20000)
+	for i := 0; i < 10000; i++ {
+		entries = append(entries,
+			&logs_models.LogEntry{Level: "DEBUG", ServiceName: "api", Message: fmt.Sprintf("cache probe %d", i), Timestamp: base.Add(time.Duration(i) * time.Millisecond)},
+			&logs_models.LogEntry{Level: "ERROR", ServiceName: "api", Message: fmt.Sprintf("payment declined %d", i), Timestamp: base},
+		)
+	}
+
+	kept, sampledOut := sampleEntries(entries, logs_models.SampleRates{"DEBUG": 10})
+
+	var debugKept, errorKept int
+	for _, entry := range kept {
+		if entry.Level == "DEBUG" {
+			debugKept++
+		} else {
+			errorKept++
+		}
+	}
+	assert.Equal(t, 10000, errorKept, "levels without a rate are kept in full")
+	assert.InDelta(t, 1000, debugKept, 100, "about 1 in 10 DEBUG entries are kept")
+	assert.Equal(t, 10000-debugKept, sampledOut)
+}
+
+func TestSampleEntries_DeterministicForIdenticalEntries(t *testing.T) {
+	ts := time.Date(2025, 11, 24, 12, 0, 0, 0, time.UTC)
+	newEntries := func() []*logs_models.LogEntry {
+		entries := make([]*logs_models.LogEntry, 0, 200)
+		for i := 0; i < 100; i++ {
+			entries = append(entries,
+				&logs_models.LogEntry{Level: "INFO", ServiceName: "web", Message: "request served", Timestamp: ts},
+				&logs_models.LogEntry{Level: "INFO", ServiceName: "web", Message: fmt.Sprintf("user %d signed in", i), Timestamp: ts},
+			)
+		}
+		return entries
+	}
+	rates := logs_models.SampleRates{"INFO": 4}
+
+	first, _ := sampleEntries(newEntries(), rates)
+	second, _ := sampleEntries(newEntries(), rates)
+
+	var repeated int
+	for _, entry := range first {
+		if entry.Message == "request served" {
+			repeated++
+		}
+	}
+	assert.Contains(t, []int{0, 100}, repeated, "identical entries are all kept or all dropped")
+	require.Len(t, second, len(first), "a resent batch is sampled the same way")
+	for i := range first {
+		assert.Equal(t, first[i].Message, second[i].Message)
+	}
+}
+
+func TestSampleEntries_NoRatesKeepsAll(t *testing.T) {
+	entries := []*logs_models.LogEntry{{Level: "DEBUG"}, {Level: "INFO"}}
+	kept, sampledOut := sampleEntries(entries, nil)
+	assert.Zero(t, sampledOut)
+	assert.Len(t, kept, 2)
+}
+
+func TestBuildBatchEntries_PartialSuccess(t *testing.T) {
+	req := &BatchLogRequest{
+		ProjectSlug: "shop",
+		Logs: []BatchLogEntry{
+			{Timestamp: "2025-11-13T10:00:00Z", Level: "info", Message: "order placed"},
+			{Timestamp: "yesterday", Level: "info", Message: "bad timestamp"},
+			{Timestamp: "2025-11-13T10:00:01Z", Level: "verbose", Message: "bad level"},
+			{Timestamp: "2025-11-13T10:00:02Z", Level: "error", Message: ""},
+			{Timestamp: "2025-11-13T10:00:03Z", Level: "fatal", Message: "disk full", Tags: []string{"infra"}},
+			{Timestamp: "2025-11-13T10:00:04Z", Level: "warn", Message: "tagged", Tags: []string{""}},
+		},
+	}
+
+	entries, failed, _ := buildBatchEntries(req, 7)
+
+	require.Len(t, entries, 2)
+	assert.Equal(t, "order placed", entries[0].Message)
+	assert.Equal(t, "INFO", entries[0].Level)
+	assert.Equal(t, "disk full", entries[1].Message)
+	assert.Equal(t, []string{"infra"}, entries[1].Tags)
+	assert.Equal(t, int64(7), *entries[1].ProjectID)
+
+	require.Len(t, failed, 4)
+	assert.Equal(t, []int{1, 2, 3, 5}, []int{failed[0].Index, failed[1].Index, failed[2].Index, failed[3].Index})
+	assert.Contains(t, failed[0].Reason, "timestamp")
+	assert.Contains(t, failed[1].Reason, `unknown level "VERBOSE"`)
+	assert.Equal(t, "message: is required", failed[2].Reason)
+	assert.Equal(t, "tags[0]: must not be empty", failed[3].Reason)
+}
+
+func TestBuildBatchEntries_IdempotencyKeysKeepRequestIndex(t *testing.T) {
+	req := &BatchLogRequest{
+		IdempotencyKey: "batch-9",
+		Logs: []BatchLogEntry{
+			{Timestamp: "nope", Level: "info", Message: "dropped"},
+			{Timestamp: "2025-11-13T10:00:00Z", Level: "info", Message: "kept"},
+		},
+	}
+
+	entries, failed, _ := buildBatchEntries(req, 1)
+	require.Len(t, entries, 1)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "batch-9:1", entries[0].IdempotencyKey)
+}
+
+func TestBuildBatchEntries_CorrelationID(t *testing.T) {
+	req := &BatchLogRequest{
+		Logs: []BatchLogEntry{
+			{Timestamp: "2025-11-13T10:00:00Z", Level: "info", Message: "field", CorrelationID: "req-1",
+				Context: map[string]interface{}{"correlation_id": "ignored"}},
+			{Timestamp: "2025-11-13T10:00:01Z", Level: "info", Message: "context",
+				Context: map[string]interface{}{"correlation_id": "req-2"}},
+			{Timestamp: "2025-11-13T10:00:02Z", Level: "info", Message: "not a string",
+				Context: map[string]interface{}{"correlation_id": 42}},
+			{Timestamp: "2025-11-13T10:00:03Z", Level: "info", Message: "none"},
+		},
+	}
+
+	entries, failed, _ := buildBatchEntries(req, 1)
+	require.Empty(t, failed)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "req-1", entries[0].CorrelationID)
+	assert.Equal(t, "req-2", entries[1].CorrelationID)
+	assert.Empty(t, entries[2].CorrelationID)
+	assert.Empty(t, entries[3].CorrelationID)
+}
+
+func TestWithStorageFailures_ListsEveryStoredEntryInRequestOrder(t *testing.T) {
+	req := &BatchLogRequest{
+		Logs: []BatchLogEntry{
+			{Timestamp: "2025-11-13T10:00:00Z", Level: "info", Message: "first"},
+			{Timestamp: "nope", Level: "info", Message: "bad timestamp"},
+			{Timestamp: "2025-11-13T10:00:01Z", Level: "error", Message: "third"},
+		},
+	}
+	entries, failed, positions := buildBatchEntries(req, 1)
+	require.Len(t, failed, 1)
+
+	failed = withStorageFailures(failed, entries, positions, errors.New("connection refused"))
+
+	require.Len(t, failed, 3)
+	assert.Equal(t, []int{0, 1, 2}, []int{failed[0].Index, failed[1].Index, failed[2].Index})
+	assert.Equal(t, "storage: connection refused", failed[0].Reason)
+	assert.Contains(t, failed[1].Reason, "timestamp")
+	assert.Equal(t, "storage: connection refused", failed[2].Reason)
+}
+
+func TestReserveQuota_RejectsBatchPastDailyQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mr := miniredis.RunT(t)
+	h := &BatchHandler{}
+	h.SetQuota(logs_services.NewLogQuota(logs_services.NewRedisQuotaStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))))
+	quota := int64(5)
+	project := &logs_models.Project{ID: 7, Slug: "shop", DailyLogQuota: &quota}
+
+	reserve := func(n int) (*httptest.ResponseRecorder, bool) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/logs/batch", http.NoBody)
+		_, ok := h.reserveQuota(c, project, n)
+		return w, ok
+	}
+
+	w, ok := reserve(3)
+	assert.True(t, ok)
+	assert.Equal(t, "5", w.Header().Get("X-Quota-Limit"))
+	assert.Equal(t, "2", w.Header().Get("X-Quota-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("X-Quota-Reset"))
+
+	w, ok = reserve(3)
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Quota-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "Daily log quota exceeded")
+
+	// Projects without a quota are never limited
+	project.DailyLogQuota = nil
+	_, ok = reserve(1000)
+	assert.True(t, ok)
+}
@@ -7,12 +7,19 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	logs_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/services"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/monitoring"
 )
 
+// StreamStatsSource reports live-stream delivery health (see WebSocketHub.Stats).
+type StreamStatsSource interface {
+	Stats() logs_services.HubStats
+}
+
 // MonitoringHandler handles monitoring dashboard API requests
 type MonitoringHandler struct {
 	collector monitoring.MetricsCollector
+	stream    StreamStatsSource
 }
 
 // NewMonitoringHandler creates a new monitoring handler
@@ -22,6 +29,22 @@ func NewMonitoringHandler(collector monitoring.MetricsCollector) *MonitoringHand
 	}
 }
 
+// SetStreamStats enables GetStreamStats.
+func (h *MonitoringHandler) SetStreamStats(stream StreamStatsSource) {
+	h.stream = stream
+}
+
+// GetStreamStats returns live log stream delivery stats: connected clients,
+// logs dropped on full client queues, and the queue high-water mark
+// GET /api/logs/monitoring/stream
+func (h *MonitoringHandler) GetStreamStats(c *gin.Context) {
+	if h.stream == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Live stream stats not available"})
+		return
+	}
+	c.JSON(http.StatusOK, h.stream.Stats())
+}
+
 // MetricsResponse represents the time-series metrics response
 type MetricsResponse struct {
 	TimeRange     string            `json:"time_range"`
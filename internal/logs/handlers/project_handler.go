@@ -2,8 +2,11 @@ package internal_logs_handlers
 
 import (
 	"database/sql"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
@@ -13,6 +16,7 @@ import (
 // ProjectHandler handles HTTP requests for project management
 type ProjectHandler struct {
 	projectSvc *logs_services.ProjectService
+	quota      *logs_services.LogQuota
 }
 
 // NewProjectHandler creates a new ProjectHandler
@@ -22,6 +26,11 @@ func NewProjectHandler(projectSvc *logs_services.ProjectService) *ProjectHandler
 	}
 }
 
+// SetQuota enables GET /api/logs/projects/:id/quota, reporting usage from q.
+func (h *ProjectHandler) SetQuota(q *logs_services.LogQuota) {
+	h.quota = q
+}
+
 // CreateProjectRequest represents the request body for creating a project
 type CreateProjectRequest struct {
 	Name          string `json:"name" binding:"required"`
@@ -138,6 +147,46 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 	})
 }
 
+// GetQuota handles GET /api/logs/projects/:id/quota
+// Returns today's ingestion against the project's daily log quota
+func (h *ProjectHandler) GetQuota(c *gin.Context) {
+	if _, exists := c.Get("user_id"); !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	if h.quota == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Quota tracking is not enabled"})
+		return
+	}
+
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	project, err := h.projectSvc.GetProject(c.Request.Context(), projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get project: " + err.Error()})
+		return
+	}
+	if project == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	}
+
+	usage, err := h.quota.Usage(c.Request.Context(), project.ID, project.DailyLogQuota)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to read quota usage: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project_id": project.ID,
+		"quota":      usage,
+	})
+}
+
 // ListProjects handles GET /api/logs/projects
 func (h *ProjectHandler) ListProjects(c *gin.Context) {
 	// Get user ID from context
@@ -229,6 +278,50 @@ func (h *ProjectHandler) RegenerateAPIKey(c *gin.Context) {
 	})
 }
 
+// RotateAPIKey handles POST /api/logs/projects/:id/rotate-key
+// Issues a new API key while the old one keeps working for the grace period
+// (body: {"grace_period_hours": N}, optional), so clients can switch over
+// without failed requests. Unlike regenerate-key it checks ownership.
+func (h *ProjectHandler) RotateAPIKey(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project ID"})
+		return
+	}
+
+	var req logs_models.RotateKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	var grace *time.Duration
+	if req.GracePeriodHours != nil {
+		d := time.Duration(*req.GracePeriodHours) * time.Hour
+		grace = &d
+	}
+
+	resp, err := h.projectSvc.RotateAPIKey(c.Request.Context(), projectID, userID, grace)
+	switch {
+	case errors.Is(err, logs_services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
+		return
+	case errors.Is(err, logs_services.ErrInvalidRotation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rotate API key: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
 // DeleteProject handles DELETE /api/logs/projects/:id
 func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 	// Get user ID from context (not used in simplified auth model)
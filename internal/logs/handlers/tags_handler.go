@@ -1,16 +1,32 @@
 package internal_logs_handlers
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
 )
 
+// TagRuleStore persists per-project tag rules (see logs_db.TagRuleRepository).
+// Every method is scoped to projects owned by userID and returns
+// sql.ErrNoRows for another user's project; Update and Delete also return it
+// for a rule not in the project.
+type TagRuleStore interface {
+	ListByProject(ctx context.Context, projectID int64, userID int) ([]logs_models.ProjectTagRule, error)
+	Create(ctx context.Context, rule *logs_models.ProjectTagRule, userID int) (*logs_models.ProjectTagRule, error)
+	Update(ctx context.Context, rule *logs_models.ProjectTagRule, userID int) (*logs_models.ProjectTagRule, error)
+	Delete(ctx context.Context, projectID, id int64, userID int) error
+}
+
 // TagsHandler handles tag-related operations
 type TagsHandler struct {
-	repo *logs_db.LogRepository
+	repo  *logs_db.LogRepository
+	rules TagRuleStore
 }
 
 // NewTagsHandler creates a new tags handler
@@ -20,6 +36,11 @@ func NewTagsHandler(repo *logs_db.LogRepository) *TagsHandler {
 	}
 }
 
+// SetTagRuleStore enables the per-project tag rule endpoints.
+func (h *TagsHandler) SetTagRuleStore(rules TagRuleStore) {
+	h.rules = rules
+}
+
 // GetAvailableTags returns all unique tags from the database
 // GET /api/logs/tags
 func (h *TagsHandler) GetAvailableTags(c *gin.Context) {
@@ -113,3 +134,210 @@ func (h *TagsHandler) RemoveTagFromLog(c *gin.Context) {
 		"tag":    tag,
 	})
 }
+
+// TagRuleRequest is the request body for creating or updating a tag rule.
+// Every non-empty condition must match for the tag to be added.
+type TagRuleRequest struct {
+	MessagePattern string `json:"message_pattern"` // RE2 regex matched against the message
+	Level          string `json:"level"`
+	Service        string `json:"service"`
+	Tag            string `json:"tag"`
+}
+
+// ListTagRules returns a project's tag rules
+// GET /api/logs/projects/:id/tag-rules
+func (h *TagsHandler) ListTagRules(c *gin.Context) {
+	userID, projectID, ok := h.tagRuleProjectID(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.rules.ListByProject(c.Request.Context(), projectID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Project not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch tag rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+// CreateTagRule adds a tag rule to a project
+// POST /api/logs/projects/:id/tag-rules
+func (h *TagsHandler) CreateTagRule(c *gin.Context) {
+	userID, projectID, ok := h.tagRuleProjectID(c)
+	if !ok {
+		return
+	}
+
+	rule, ok := bindTagRule(c, projectID)
+	if !ok {
+		return
+	}
+
+	created, err := h.rules.Create(c.Request.Context(), rule, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Project not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create tag rule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"rule": created,
+	})
+}
+
+// UpdateTagRule replaces a tag rule's conditions and tag
+// PUT /api/logs/projects/:id/tag-rules/:rule_id
+func (h *TagsHandler) UpdateTagRule(c *gin.Context) {
+	userID, projectID, ok := h.tagRuleProjectID(c)
+	if !ok {
+		return
+	}
+	ruleID, ok := parseTagRuleID(c)
+	if !ok {
+		return
+	}
+
+	rule, ok := bindTagRule(c, projectID)
+	if !ok {
+		return
+	}
+	rule.ID = ruleID
+
+	updated, err := h.rules.Update(c.Request.Context(), rule, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Tag rule not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update tag rule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rule": updated,
+	})
+}
+
+// DeleteTagRule removes a tag rule from a project
+// DELETE /api/logs/projects/:id/tag-rules/:rule_id
+func (h *TagsHandler) DeleteTagRule(c *gin.Context) {
+	userID, projectID, ok := h.tagRuleProjectID(c)
+	if !ok {
+		return
+	}
+	ruleID, ok := parseTagRuleID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.rules.Delete(c.Request.Context(), projectID, ruleID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Tag rule not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete tag rule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "tag_rule_deleted",
+		"id":     ruleID,
+	})
+}
+
+// tagRuleProjectID returns the session user and the :id project parameter,
+// writing the error response itself when the rules endpoints can't proceed.
+func (h *TagsHandler) tagRuleProjectID(c *gin.Context) (userID int, projectID int64, ok bool) {
+	if h.rules == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Tag rules are not available",
+		})
+		return 0, 0, false
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userIDValue, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return 0, 0, false
+	}
+	userID, ok = userIDValue.(int)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
+		return 0, 0, false
+	}
+
+	projectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || projectID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return 0, 0, false
+	}
+	return userID, projectID, true
+}
+
+func parseTagRuleID(c *gin.Context) (int64, bool) {
+	ruleID, err := strconv.ParseInt(c.Param("rule_id"), 10, 64)
+	if err != nil || ruleID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid tag rule ID",
+		})
+		return 0, false
+	}
+	return ruleID, true
+}
+
+// bindTagRule reads and validates a TagRuleRequest. Invalid rules, including
+// a message_pattern that isn't a valid regex, are rejected with 400.
+func bindTagRule(c *gin.Context, projectID int64) (*logs_models.ProjectTagRule, bool) {
+	var req TagRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return nil, false
+	}
+
+	rule := &logs_models.ProjectTagRule{
+		ProjectID:      projectID,
+		MessagePattern: req.MessagePattern,
+		Level:          req.Level,
+		Service:        req.Service,
+		Tag:            req.Tag,
+	}
+	if err := rule.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+	return rule, true
+}
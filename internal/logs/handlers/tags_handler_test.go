@@ -0,0 +1,177 @@
+package internal_logs_handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTagRuleStore is an in-memory TagRuleStore. Projects 1 and 2 belong
+// to user 1, project 3 to user 2.
+type memoryTagRuleStore struct {
+	rules  map[int64]logs_models.ProjectTagRule
+	owners map[int64]int
+	nextID int64
+}
+
+func newMemoryTagRuleStore() *memoryTagRuleStore {
+	return &memoryTagRuleStore{
+		rules:  make(map[int64]logs_models.ProjectTagRule),
+		owners: map[int64]int{1: 1, 2: 1, 3: 2},
+	}
+}
+
+func (m *memoryTagRuleStore) ListByProject(_ context.Context, projectID int64, userID int) ([]logs_models.ProjectTagRule, error) {
+	if m.owners[projectID] != userID {
+		return nil, sql.ErrNoRows
+	}
+	rules := []logs_models.ProjectTagRule{}
+	for id := int64(1); id <= m.nextID; id++ {
+		if rule, ok := m.rules[id]; ok && rule.ProjectID == projectID {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (m *memoryTagRuleStore) Create(_ context.Context, rule *logs_models.ProjectTagRule, userID int) (*logs_models.ProjectTagRule, error) {
+	if m.owners[rule.ProjectID] != userID {
+		return nil, sql.ErrNoRows
+	}
+	m.nextID++
+	rule.ID = m.nextID
+	m.rules[rule.ID] = *rule
+	return rule, nil
+}
+
+func (m *memoryTagRuleStore) Update(_ context.Context, rule *logs_models.ProjectTagRule, userID int) (*logs_models.ProjectTagRule, error) {
+	if existing, ok := m.rules[rule.ID]; !ok || existing.ProjectID != rule.ProjectID || m.owners[rule.ProjectID] != userID {
+		return nil, sql.ErrNoRows
+	}
+	m.rules[rule.ID] = *rule
+	return rule, nil
+}
+
+func (m *memoryTagRuleStore) Delete(_ context.Context, projectID, id int64, userID int) error {
+	if existing, ok := m.rules[id]; !ok || existing.ProjectID != projectID || m.owners[projectID] != userID {
+		return sql.ErrNoRows
+	}
+	delete(m.rules, id)
+	return nil
+}
+
+func setupTagRuleRouter(store TagRuleStore) *gin.Engine {
+	return setupTagRuleRouterAs(store, 1)
+}
+
+func setupTagRuleRouterAs(store TagRuleStore, userID int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewTagsHandler(nil)
+	h.SetTagRuleStore(store)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", userID) })
+	router.GET("/api/logs/projects/:id/tag-rules", h.ListTagRules)
+	router.POST("/api/logs/projects/:id/tag-rules", h.CreateTagRule)
+	router.PUT("/api/logs/projects/:id/tag-rules/:rule_id", h.UpdateTagRule)
+	router.DELETE("/api/logs/projects/:id/tag-rules/:rule_id", h.DeleteTagRule)
+	return router
+}
+
+func doTagRuleRequest(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTagsHandler_TagRuleCRUD(t *testing.T) {
+	router := setupTagRuleRouter(newMemoryTagRuleStore())
+
+	w := doTagRuleRequest(router, http.MethodPost, "/api/logs/projects/1/tag-rules",
+		`{"level":"error","service":"payments","tag":"critical"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created struct {
+		Rule logs_models.ProjectTagRule `json:"rule"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, int64(1), created.Rule.ProjectID)
+	assert.Equal(t, "ERROR", created.Rule.Level)
+
+	w = doTagRuleRequest(router, http.MethodPut, "/api/logs/projects/1/tag-rules/1",
+		`{"message_pattern":"declined|fraud","tag":"payments-risk"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = doTagRuleRequest(router, http.MethodGet, "/api/logs/projects/1/tag-rules", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"payments-risk"`)
+
+	// Rules are scoped to their project
+	w = doTagRuleRequest(router, http.MethodDelete, "/api/logs/projects/2/tag-rules/1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doTagRuleRequest(router, http.MethodDelete, "/api/logs/projects/1/tag-rules/1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestTagsHandler_TagRulesScopedToProjectOwner(t *testing.T) {
+	store := newMemoryTagRuleStore()
+	owner := setupTagRuleRouterAs(store, 1)
+	other := setupTagRuleRouterAs(store, 2)
+
+	w := doTagRuleRequest(owner, http.MethodPost, "/api/logs/projects/1/tag-rules", `{"level":"error","tag":"critical"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = doTagRuleRequest(other, http.MethodGet, "/api/logs/projects/1/tag-rules", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doTagRuleRequest(other, http.MethodPost, "/api/logs/projects/1/tag-rules", `{"level":"warn","tag":"mine"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doTagRuleRequest(other, http.MethodPut, "/api/logs/projects/1/tag-rules/1", `{"level":"warn","tag":"hijacked"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doTagRuleRequest(other, http.MethodDelete, "/api/logs/projects/1/tag-rules/1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	require.Len(t, store.rules, 1)
+	assert.Equal(t, "critical", store.rules[1].Tag)
+}
+
+func TestTagsHandler_TagRulesRequireSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewTagsHandler(nil)
+	h.SetTagRuleStore(newMemoryTagRuleStore())
+	router := gin.New()
+	router.GET("/api/logs/projects/:id/tag-rules", h.ListTagRules)
+
+	w := doTagRuleRequest(router, http.MethodGet, "/api/logs/projects/1/tag-rules", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestTagsHandler_CreateTagRule_InvalidRegex(t *testing.T) {
+	store := newMemoryTagRuleStore()
+	router := setupTagRuleRouter(store)
+
+	w := doTagRuleRequest(router, http.MethodPost, "/api/logs/projects/1/tag-rules",
+		`{"message_pattern":"timeout (\\d+","tag":"slow"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "message_pattern is not a valid regular expression")
+	assert.Empty(t, store.rules)
+}
+
+func TestTagsHandler_TagRulesUnavailable(t *testing.T) {
+	router := setupTagRuleRouter(nil)
+
+	w := doTagRuleRequest(router, http.MethodGet, "/api/logs/projects/1/tag-rules", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,92 @@
+// Package logs_metrics exposes Prometheus metrics for the logs service.
+package logs_metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// UnassignedProject labels entries ingested without a project (e.g. POST /api/logs).
+const UnassignedProject = "unassigned"
+
+// IngestMetrics records log ingestion. A nil *IngestMetrics is valid and
+// records nothing, so callers never need to check whether metrics are wired.
+type IngestMetrics struct {
+	ingested  *prometheus.CounterVec
+	batchSize prometheus.Histogram
+	duration  prometheus.Histogram
+}
+
+// NewIngestMetrics creates the ingestion metrics and registers them with reg.
+func NewIngestMetrics(reg prometheus.Registerer) *IngestMetrics {
+	m := &IngestMetrics{
+		ingested: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "logs_ingested_total",
+			Help: "Log entries stored, by project and level.",
+		}, []string{"project", "level"}),
+		batchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "logs_ingest_batch_size",
+			Help:    "Number of entries per batch ingestion request.",
+			Buckets: []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000},
+		}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "logs_ingest_duration_seconds",
+			Help:    "Time spent storing an ingestion request.",
+			Buckets: prometheus.DefBuckets,
+		}),
+	}
+	reg.MustRegister(m.ingested, m.batchSize, m.duration)
+	return m
+}
+
+// IncIngested counts n entries stored for project at level.
+func (m *IngestMetrics) IncIngested(project, level string, n int) {
+	if m == nil || n <= 0 {
+		return
+	}
+	if project == "" {
+		project = UnassignedProject
+	}
+	m.ingested.WithLabelValues(project, strings.ToLower(level)).Add(float64(n))
+}
+
+// ObserveBatch records the size of one batch request.
+func (m *IngestMetrics) ObserveBatch(size int) {
+	if m == nil {
+		return
+	}
+	m.batchSize.Observe(float64(size))
+}
+
+// ObserveDuration records how long storing an ingestion request took.
+func (m *IngestMetrics) ObserveDuration(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.duration.Observe(d.Seconds())
+}
+
+// NewRegistry returns a registry with Go runtime, process and, when db is
+// non-nil, connection pool (go_sql_*) collectors already registered.
+func NewRegistry(db *sql.DB) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if db != nil {
+		reg.MustRegister(collectors.NewDBStatsCollector(db, "logs"))
+	}
+	return reg
+}
+
+// Handler serves reg in the Prometheus text exposition format.
+func Handler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg})
+}
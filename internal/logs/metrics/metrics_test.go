@@ -0,0 +1,50 @@
+package logs_metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, h http.Handler) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestIngestMetrics_Exposition(t *testing.T) {
+	reg := NewRegistry(nil)
+	m := NewIngestMetrics(reg)
+
+	m.IncIngested("shop-api", "ERROR", 3)
+	m.IncIngested("", "info", 1)
+	m.ObserveBatch(3)
+	m.ObserveDuration(20 * time.Millisecond)
+
+	out := scrape(t, Handler(reg))
+
+	assert.Contains(t, out, `logs_ingested_total{level="error",project="shop-api"} 3`)
+	assert.Contains(t, out, `logs_ingested_total{level="info",project="unassigned"} 1`)
+	assert.Contains(t, out, "logs_ingest_batch_size_count 1")
+	assert.Contains(t, out, "logs_ingest_duration_seconds_count 1")
+	assert.Contains(t, out, "go_goroutines")
+}
+
+func TestIngestMetrics_NilIsNoop(t *testing.T) {
+	var m *IngestMetrics
+
+	assert.NotPanics(t, func() {
+		m.IncIngested("p", "info", 1)
+		m.ObserveBatch(10)
+		m.ObserveDuration(time.Second)
+	})
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxDecompressedBytes caps how large a gzipped request body may
+// grow when decompressed. A full batch of 1000 entries fits comfortably.
+const DefaultMaxDecompressedBytes = 10 << 20 // 10 MiB
+
+// DecompressGzip transparently decompresses request bodies sent with
+// Content-Encoding: gzip, so handlers can decode JSON as usual. Bodies
+// without an encoding (or "identity") pass through untouched.
+//
+// Decompression stops at maxBytes: a body that would expand beyond it is
+// rejected with 413 before the handler runs, so a small compressed payload
+// can't exhaust memory (a "zip bomb"). A corrupt gzip stream is a 400, and
+// any other encoding a 415.
+//
+// The compressed size is still limited by RequestLimitsMiddleware; this
+// guards what it expands to.
+func DecompressGzip(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		switch encoding {
+		case "", "identity":
+			c.Next()
+			return
+		case "gzip", "x-gzip":
+		default:
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{
+				"error": fmt.Sprintf("Unsupported Content-Encoding %q: only gzip is accepted", encoding),
+			})
+			return
+		}
+
+		body, err := gunzipLimited(c.Request.Body, maxBytes)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			switch {
+			case errors.Is(err, errDecompressedTooLarge):
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+					"error": fmt.Sprintf("Decompressed request body exceeds %d bytes", maxBytes),
+				})
+				return
+			case errors.As(err, &maxBytesErr):
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Invalid gzip request body: %v", err),
+			})
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		c.Next()
+	}
+}
+
+var errDecompressedTooLarge = errors.New("decompressed body too large")
+
+// gunzipLimited decompresses r, reading at most one byte past maxBytes to
+// detect oversized bodies without decompressing them in full
+func gunzipLimited(r io.Reader, maxBytes int64) ([]byte, error) {
+	if r == nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	body, err := io.ReadAll(io.LimitReader(zr, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxBytes {
+		return nil, errDecompressedTooLarge
+	}
+	return body, nil
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGzipTestRouter serves a batch endpoint that reports how many entries it
+// decoded
+func newGzipTestRouter(maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/logs/batch", DecompressGzip(maxBytes), func(c *gin.Context) {
+		var req struct {
+			ProjectSlug string           `json:"project_slug"`
+			Logs        []map[string]any `json:"logs"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"project_slug": req.ProjectSlug, "accepted": len(req.Logs)})
+	})
+	return router
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func batchJSON(n int) []byte {
+	logs := make([]string, n)
+	for i := range logs {
+		logs[i] = fmt.Sprintf(`{"timestamp":"2025-11-13T10:00:00Z","level":"info","message":"request %d served"}`, i)
+	}
+	return []byte(`{"project_slug":"shop","logs":[` + strings.Join(logs, ",") + `]}`)
+}
+
+func TestDecompressGzip_GzippedBatchIsDecoded(t *testing.T) {
+	router := newGzipTestRouter(DefaultMaxDecompressedBytes)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/batch", bytes.NewReader(gzipBytes(t, batchJSON(500))))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"project_slug":"shop","accepted":500}`, w.Body.String())
+}
+
+func TestDecompressGzip_PlainBodyPassesThrough(t *testing.T) {
+	router := newGzipTestRouter(DefaultMaxDecompressedBytes)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/logs/batch", bytes.NewReader(batchJSON(3))))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"project_slug":"shop","accepted":3}`, w.Body.String())
+}
+
+func TestDecompressGzip_OversizedDecompressionIsRejected(t *testing.T) {
+	router := newGzipTestRouter(64 << 10)
+
+	// 10 MiB of zeros compresses to a few KiB
+	bomb := gzipBytes(t, make([]byte, 10<<20))
+	require.Less(t, len(bomb), 64<<10)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/batch", bytes.NewReader(bomb))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "exceeds 65536 bytes")
+}
+
+func TestDecompressGzip_InvalidStreamAndEncoding(t *testing.T) {
+	router := newGzipTestRouter(DefaultMaxDecompressedBytes)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/batch", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/logs/batch", bytes.NewReader(batchJSON(1)))
+	req.Header.Set("Content-Encoding", "br")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Default batch ingestion limit per API key.
+const (
+	DefaultRateLimit  = 100
+	DefaultRateWindow = time.Minute
+)
+
+// RateLimitStore records requests in a sliding window and decides whether
+// another one fits. now is supplied by the caller so the window is driven by
+// the limiter's clock rather than the store's.
+type RateLimitStore interface {
+	// Allow records a request for key at now if fewer than limit requests were
+	// recorded in (now-window, now]. When the request is rejected, retryAfter
+	// is how long until the oldest recorded request leaves the window.
+	Allow(ctx context.Context, key string, now time.Time, window time.Duration, limit int) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// RateLimiter enforces a per-project request limit over a sliding window.
+type RateLimiter struct {
+	store  RateLimitStore
+	now    func() time.Time
+	window time.Duration
+	limit  int
+}
+
+// NewRateLimiter creates a RateLimiter allowing limit requests per window.
+// Non-positive values fall back to DefaultRateLimit / DefaultRateWindow.
+func NewRateLimiter(store RateLimitStore, limit int, window time.Duration) *RateLimiter {
+	if limit <= 0 {
+		limit = DefaultRateLimit
+	}
+	if window <= 0 {
+		window = DefaultRateWindow
+	}
+	return &RateLimiter{
+		store:  store,
+		now:    time.Now,
+		window: window,
+		limit:  limit,
+	}
+}
+
+// APIKeyRateLimit limits requests per project API key. It must run after
+// SimpleAPITokenAuth, which resolves the key to a project and stores it in
+// the context; requests without a project are passed through untouched.
+//
+// Over the limit, it responds 429 with a Retry-After header (whole seconds).
+// If the store is unreachable the request is allowed: losing log ingestion
+// because Redis blipped is worse than briefly not limiting.
+func APIKeyRateLimit(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get("project")
+		if !ok {
+			c.Next()
+			return
+		}
+		project, ok := value.(*logs_models.Project)
+		if !ok || project == nil {
+			c.Next()
+			return
+		}
+
+		key := fmt.Sprintf("ratelimit:logs:batch:project:%d", project.ID)
+		allowed, retryAfter, err := limiter.store.Allow(c.Request.Context(), key, limiter.now(), limiter.window, limiter.limit)
+		if err != nil {
+			log.Printf("[WARN] Rate limiter unavailable, allowing request: project_id=%d, error=%v", project.ID, err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limiter.limit))
+		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":   "Rate limit exceeded",
+				"message": fmt.Sprintf("Limit is %d requests per %s for this API key. Retry in %d seconds.", limiter.limit, limiter.window, seconds),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// slidingWindowScript trims entries older than the window, then admits the
+// request if there is room. Scores and the returned retry-after are in ms.
+// Running it as one script keeps check-and-add atomic across replicas.
+var slidingWindowScript = redis.NewScript(`
+local key    = KEYS[1]
+local now    = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit  = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+if redis.call('ZCARD', key) < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, 0}
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+return {0, tonumber(oldest[2]) + window - now}
+`)
+
+// RedisRateLimitStore is a RateLimitStore backed by a Redis sorted set per key.
+type RedisRateLimitStore struct {
+	client redis.Scripter
+	seq    atomic.Uint64
+}
+
+// NewRedisRateLimitStore creates a RedisRateLimitStore.
+func NewRedisRateLimitStore(client redis.Scripter) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client}
+}
+
+// Allow implements RateLimitStore.
+func (s *RedisRateLimitStore) Allow(ctx context.Context, key string, now time.Time, window time.Duration, limit int) (allowed bool, retryAfter time.Duration, err error) {
+	// Members must be unique or requests in the same millisecond collapse into one
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), s.seq.Add(1))
+
+	res, err := slidingWindowScript.Run(ctx, s.client, []string{key},
+		now.UnixMilli(), window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("rate limit script failed: %w", err)
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("rate limit script returned %d values", len(res))
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
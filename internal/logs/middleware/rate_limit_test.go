@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced clock for deterministic window tests.
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) Now() time.Time          { return f.t }
+func (f *fakeClock) Advance(d time.Duration) { f.t = f.t.Add(d) }
+
+// memoryRateLimitStore mirrors the Redis sliding window in memory.
+type memoryRateLimitStore struct {
+	hits map[string][]time.Time
+	err  error
+}
+
+func newMemoryRateLimitStore() *memoryRateLimitStore {
+	return &memoryRateLimitStore{hits: make(map[string][]time.Time)}
+}
+
+func (m *memoryRateLimitStore) Allow(_ context.Context, key string, now time.Time, window time.Duration, limit int) (bool, time.Duration, error) {
+	if m.err != nil {
+		return false, 0, m.err
+	}
+
+	kept := m.hits[key][:0]
+	for _, t := range m.hits[key] {
+		if t.After(now.Add(-window)) {
+			kept = append(kept, t)
+		}
+	}
+	m.hits[key] = kept
+
+	if len(kept) < limit {
+		m.hits[key] = append(kept, now)
+		return true, 0, nil
+	}
+	return false, kept[0].Add(window).Sub(now), nil
+}
+
+func setupRateLimitRouter(limiter *RateLimiter, projectID int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/logs/batch", func(c *gin.Context) {
+		if projectID != 0 {
+			c.Set("project", &logs_models.Project{ID: projectID, IsActive: true})
+		}
+		c.Next()
+	}, APIKeyRateLimit(limiter), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	return router
+}
+
+func doBatchRequest(router *gin.Engine) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/logs/batch", http.NoBody))
+	return w
+}
+
+func TestAPIKeyRateLimit_SlidingWindow(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(newMemoryRateLimitStore(), 3, time.Minute)
+	limiter.now = clock.Now
+	router := setupRateLimitRouter(limiter, 1)
+
+	// t=0s, 10s, 20s: all within the limit
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusCreated, doBatchRequest(router).Code)
+		clock.Advance(10 * time.Second)
+	}
+
+	// t=30s: fourth request in the window is rejected until t=60s
+	w := doBatchRequest(router)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	// t=59s: still inside the window of the first request
+	clock.Advance(29 * time.Second)
+	assert.Equal(t, http.StatusTooManyRequests, doBatchRequest(router).Code)
+
+	// t=61s: the first request slid out, so exactly one slot opens
+	clock.Advance(2 * time.Second)
+	assert.Equal(t, http.StatusCreated, doBatchRequest(router).Code)
+	assert.Equal(t, http.StatusTooManyRequests, doBatchRequest(router).Code)
+}
+
+func TestAPIKeyRateLimit_PerProject(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	store := newMemoryRateLimitStore()
+	limiter := NewRateLimiter(store, 1, time.Minute)
+	limiter.now = clock.Now
+
+	assert.Equal(t, http.StatusCreated, doBatchRequest(setupRateLimitRouter(limiter, 1)).Code)
+	assert.Equal(t, http.StatusTooManyRequests, doBatchRequest(setupRateLimitRouter(limiter, 1)).Code)
+	assert.Equal(t, http.StatusCreated, doBatchRequest(setupRateLimitRouter(limiter, 2)).Code)
+
+	// Keys are per project, not per API key, so rotating a key doesn't reset the limit
+	assert.Len(t, store.hits, 2)
+	assert.Contains(t, store.hits, "ratelimit:logs:batch:project:1")
+	assert.Contains(t, store.hits, "ratelimit:logs:batch:project:2")
+}
+
+func TestAPIKeyRateLimit_NoProjectPassesThrough(t *testing.T) {
+	limiter := NewRateLimiter(newMemoryRateLimitStore(), 1, time.Minute)
+	router := setupRateLimitRouter(limiter, 0)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusCreated, doBatchRequest(router).Code)
+	}
+}
+
+func TestAPIKeyRateLimit_StoreErrorFailsOpen(t *testing.T) {
+	store := newMemoryRateLimitStore()
+	store.err = errors.New("connection refused")
+	router := setupRateLimitRouter(NewRateLimiter(store, 1, time.Minute), 1)
+
+	assert.Equal(t, http.StatusCreated, doBatchRequest(router).Code)
+	assert.Equal(t, http.StatusCreated, doBatchRequest(router).Code)
+}
+
+func TestNewRateLimiter_Defaults(t *testing.T) {
+	limiter := NewRateLimiter(newMemoryRateLimitStore(), 0, 0)
+	assert.Equal(t, DefaultRateLimit, limiter.limit)
+	assert.Equal(t, DefaultRateWindow, limiter.window)
+}
+
+func TestRedisRateLimitStore_SlidingWindow(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	ctx := context.Background()
+	key := "ratelimit:logs:batch:project:1"
+
+	store := NewRedisRateLimitStore(client)
+	start := time.Now()
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := store.Allow(ctx, key, start.Add(time.Duration(i)*time.Second), time.Minute, 2)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := store.Allow(ctx, key, start.Add(2*time.Second), time.Minute, 2)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, 58*time.Second, retryAfter)
+
+	allowed, _, err = store.Allow(ctx, key, start.Add(61*time.Second), time.Minute, 2)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
@@ -13,3 +13,19 @@ type AIInsight struct {
 	GeneratedAt time.Time `json:"generated_at" db:"generated_at"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
+
+// CorrelatedInsight is AI analysis of a group of related logs, e.g. an error
+// burst within a time window. LogIDs lists the entries that were sent to the
+// model so the analysis can be checked against them.
+type CorrelatedInsight struct {
+	ID          int64     `json:"id" db:"id"`
+	CacheKey    string    `json:"cache_key" db:"cache_key"`
+	LogIDs      []int64   `json:"log_ids" db:"log_ids"`
+	Analysis    string    `json:"analysis" db:"analysis"`
+	RootCause   string    `json:"root_cause" db:"root_cause"`
+	Suggestions []string  `json:"suggestions" db:"suggestions"`
+	ModelUsed   string    `json:"model_used" db:"model_used"`
+	Truncated   bool      `json:"truncated" db:"truncated"` // More logs matched than were sent to the model
+	Cached      bool      `json:"cached" db:"-"`            // Served from the cache rather than generated now
+	GeneratedAt time.Time `json:"generated_at" db:"generated_at"`
+}
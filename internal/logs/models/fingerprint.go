@@ -0,0 +1,69 @@
+package logs_models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FingerprintLength is the length of the hex signature returned by Fingerprint.
+const FingerprintLength = 16
+
+// fingerprintRules replace the variable parts of a message with placeholders,
+// most specific first: a UUID would otherwise be split into hex and numbers.
+var fingerprintRules = []struct {
+	pattern     *regexp.Regexp
+	placeholder string
+}{
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`(?:[A-Za-z]:)?(?:[\\/][\w.@~+-]+){2,}[\\/]?`), "<path>"},
+	{regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b`), "<hex>"},
+	// Hex tokens mixing letters and digits; all-digit ones are numbers
+	{regexp.MustCompile(`(?i)\b[0-9a-f]*[a-f][0-9a-f]*[0-9][0-9a-f]*\b|\b[0-9a-f]*[0-9][0-9a-f]*[a-f][0-9a-f]*\b`), "<hex>"},
+	{regexp.MustCompile(`\d+(?:\.\d+)?`), "<num>"},
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// NormalizeMessage strips the parts of a log message that vary between
+// occurrences of the same error - UUIDs, file paths, hex values and numbers
+// (which covers ids, durations and timestamps) - leaving its shape, e.g.
+// "user 42 not found in /srv/app/users.go" becomes
+// "user <num> not found in <path>".
+func NormalizeMessage(message string) string {
+	normalized := message
+	for _, rule := range fingerprintRules {
+		normalized = rule.pattern.ReplaceAllString(normalized, rule.placeholder)
+	}
+	return strings.TrimSpace(whitespace.ReplaceAllString(normalized, " "))
+}
+
+// Fingerprint returns a stable signature of the normalized message, shared
+// by messages that differ only by ids, numbers and the like.
+func Fingerprint(message string) string {
+	sum := sha256.Sum256([]byte(NormalizeMessage(message)))
+	return hex.EncodeToString(sum[:])[:FingerprintLength]
+}
+
+// FingerprintQuery selects fingerprint groups of entries created in [From, To).
+type FingerprintQuery struct {
+	From        time.Time
+	To          time.Time
+	Level       string // optional filter
+	Service     string // optional filter
+	Limit       int
+	OwnerUserID int // Only entries of projects this user owns; 0 is unscoped
+}
+
+// FingerprintGroup counts the entries sharing a fingerprint.
+type FingerprintGroup struct {
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	Fingerprint string    `json:"fingerprint"`
+	Message     string    `json:"message"` // The most recent entry's message
+	Level       string    `json:"level"`
+	Service     string    `json:"service"`
+	Count       int64     `json:"count"`
+}
@@ -0,0 +1,45 @@
+package logs_models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	tests := map[string]string{
+		"user 42 not found in /srv/app/users.go":                               "user <num> not found in <path>",
+		"request 7c9e6679-7425-40de-944b-e07fc1f90ae7 failed":                  "request <uuid> failed",
+		"panic at 0x7ffd5e8c and again at deadbeef01":                          "panic at <hex> and again at <hex>",
+		"query took 12.5ms   (limit 10)":                                       "query took <num>ms (limit <num>)",
+		`open C:\Users\svc\config.yaml: access denied`:                         "open <path>: access denied",
+		"connection refused":                                                   "connection refused",
+		"deadline exceeded at 2025-11-25T10:00:00Z, retry 3 of 5 for order 19": "deadline exceeded at <num>-<num>-<num>T<num>:<num>:<num>Z, retry <num> of <num> for order <num>",
+	}
+	for message, want := range tests {
+		assert.Equal(t, want, NormalizeMessage(message), message)
+	}
+}
+
+func TestFingerprint_IgnoresIDsAndNumbers(t *testing.T) {
+	same := [][2]string{
+		{"order 981 not found", "order 12 not found"},
+		{"session 7c9e6679-7425-40de-944b-e07fc1f90ae7 expired", "session 16fd2706-8baf-433b-82eb-8c7fada847da expired"},
+		{"timeout after 30s calling /api/v1/users/42", "timeout after 5s calling /api/v1/users/1337"},
+		{"cache miss for key a3f9c2d1e8", "cache miss for key 0b7e4d6f21"},
+	}
+	for _, pair := range same {
+		assert.Equal(t, Fingerprint(pair[0]), Fingerprint(pair[1]), "%q vs %q", pair[0], pair[1])
+	}
+
+	different := [][2]string{
+		{"order 981 not found", "user 981 not found"},
+		{"connection refused", "connection reset"},
+		{"payment declined", "payment accepted"},
+	}
+	for _, pair := range different {
+		assert.NotEqual(t, Fingerprint(pair[0]), Fingerprint(pair[1]), "%q vs %q", pair[0], pair[1])
+	}
+
+	assert.Len(t, Fingerprint("order 981 not found"), FingerprintLength)
+}
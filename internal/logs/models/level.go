@@ -0,0 +1,43 @@
+package logs_models
+
+import "strings"
+
+// Log levels in ascending order of severity.
+const (
+	LevelDebug = "DEBUG"
+	LevelInfo  = "INFO"
+	LevelWarn  = "WARN"
+	LevelError = "ERROR"
+	LevelFatal = "FATAL"
+)
+
+// levelRanks orders levels DEBUG < INFO < WARN < ERROR < FATAL.
+var levelRanks = map[string]int{
+	LevelDebug: 0,
+	LevelInfo:  1,
+	LevelWarn:  2,
+	LevelError: 3,
+	LevelFatal: 4,
+}
+
+// LevelRank returns the position of level in the canonical ordering.
+// Matching is case-insensitive; ok is false for unknown levels.
+func LevelRank(level string) (rank int, ok bool) {
+	rank, ok = levelRanks[strings.ToUpper(strings.TrimSpace(level))]
+	return rank, ok
+}
+
+// LevelAtLeast reports whether level is at or above minLevel. An empty or
+// unknown minLevel lets everything through; an unknown level is kept too,
+// so nothing is dropped because of a spelling we do not recognize.
+func LevelAtLeast(level, minLevel string) bool {
+	minRank, ok := LevelRank(minLevel)
+	if !ok {
+		return true
+	}
+	rank, ok := LevelRank(level)
+	if !ok {
+		return true
+	}
+	return rank >= minRank
+}
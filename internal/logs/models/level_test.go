@@ -0,0 +1,44 @@
+package logs_models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelRank_Ordering(t *testing.T) {
+	ordered := []string{LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal}
+	for i := 1; i < len(ordered); i++ {
+		lo, ok := LevelRank(ordered[i-1])
+		assert.True(t, ok)
+		hi, ok := LevelRank(ordered[i])
+		assert.True(t, ok)
+		assert.Less(t, lo, hi, "%s should rank below %s", ordered[i-1], ordered[i])
+	}
+
+	rank, ok := LevelRank(" warn ")
+	assert.True(t, ok)
+	assert.Equal(t, 2, rank)
+
+	_, ok = LevelRank("verbose")
+	assert.False(t, ok)
+}
+
+func TestLevelAtLeast(t *testing.T) {
+	tests := []struct {
+		level, min string
+		want       bool
+	}{
+		{level: "DEBUG", min: "WARN", want: false},
+		{level: "info", min: "WARN", want: false},
+		{level: "WARN", min: "WARN", want: true},
+		{level: "ERROR", min: "warn", want: true},
+		{level: "DEBUG", min: "", want: true},
+		{level: "DEBUG", min: "bogus", want: true},
+		{level: "bogus", min: "ERROR", want: true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, LevelAtLeast(tt.level, tt.min), "LevelAtLeast(%q, %q)", tt.level, tt.min)
+	}
+}
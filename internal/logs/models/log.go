@@ -8,21 +8,23 @@ import "time"
 //
 //nolint:govet // fieldalignment: organized by type for readability
 type LogEntry struct {
-	CreatedAt     time.Time           `json:"created_at"`
-	Timestamp     time.Time           `json:"timestamp"`
-	Context       *CorrelationContext `json:"context,omitempty"`
-	Service       string              `json:"service"`
-	Level         string              `json:"level"`
-	Message       string              `json:"message"`
-	IssueType     string              `json:"issue_type,omitempty"`
-	ServiceName   string              `json:"service_name,omitempty"` // Microservice identifier (cross-repo logging)
-	Metadata      []byte              `json:"metadata"`
-	AIAnalysis    []byte              `json:"ai_analysis,omitempty"`
-	Tags          []string            `json:"tags"`
-	ID            int64               `json:"id"`
-	UserID        int64               `json:"user_id"`
-	ProjectID     *int64              `json:"project_id,omitempty"` // Cross-repo project reference (nullable)
-	SeverityScore int                 `json:"severity_score,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	Timestamp      time.Time           `json:"timestamp"`
+	Context        *CorrelationContext `json:"context,omitempty"`
+	Service        string              `json:"service"`
+	Level          string              `json:"level"`
+	Message        string              `json:"message"`
+	IssueType      string              `json:"issue_type,omitempty"`
+	ServiceName    string              `json:"service_name,omitempty"`    // Microservice identifier (cross-repo logging)
+	IdempotencyKey string              `json:"idempotency_key,omitempty"` // Batch dedup key, unique per project
+	CorrelationID  string              `json:"correlation_id,omitempty"`  // Groups the entries of one request across services
+	Metadata       []byte              `json:"metadata"`
+	AIAnalysis     []byte              `json:"ai_analysis,omitempty"`
+	Tags           []string            `json:"tags"`
+	ID             int64               `json:"id"`
+	UserID         int64               `json:"user_id"`
+	ProjectID      *int64              `json:"project_id,omitempty"` // Cross-repo project reference (nullable)
+	SeverityScore  int                 `json:"severity_score,omitempty"`
 }
 
 // LogStats represents aggregated statistics for logs in a time window.
@@ -138,3 +140,29 @@ type LogMessage struct {
 	Level    string
 	Count    int
 }
+
+// TimeSeriesQuery selects log counts bucketed over [From, To).
+type TimeSeriesQuery struct {
+	From        time.Time
+	To          time.Time
+	Interval    string // minute, hour or day
+	GroupBy     string // empty, level or service
+	OwnerUserID int    // Only entries of projects this user owns; 0 is unscoped
+}
+
+// TimeSeriesPoint is the number of logs in one bucket, per level or service
+// when the series is grouped.
+type TimeSeriesPoint struct {
+	Bucket  time.Time `json:"bucket"`
+	Level   string    `json:"level,omitempty"`
+	Service string    `json:"service,omitempty"`
+	Count   int64     `json:"count"`
+}
+
+// TrashBatch counts the entries removed by one soft delete. They can be
+// restored together until PurgeAt, when the purge job removes them.
+type TrashBatch struct {
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+	Count     int64     `json:"count"`
+}
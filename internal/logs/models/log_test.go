@@ -2,6 +2,8 @@ package logs_models_test
 
 //nolint:govet // Test file: struct literals need fields for assertions
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -252,3 +254,62 @@ func TestLogStats(t *testing.T) { //nolint:govet // struct fields needed for ass
 	assert.Equal(t, 0.0, stats.ErrorRate)
 	assert.Nil(t, stats.CountByLevel)
 }
+
+func TestLogEntry_Validate(t *testing.T) {
+	valid := func() *logs_models.LogEntry {
+		return &logs_models.LogEntry{
+			Level:     "ERROR",
+			Message:   "payment declined",
+			Timestamp: time.Now(),
+			Tags:      []string{"billing"},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(e *logs_models.LogEntry)
+		wantField string
+	}{
+		{name: "valid", mutate: func(e *logs_models.LogEntry) {}},
+		{name: "lowercase level", mutate: func(e *logs_models.LogEntry) { e.Level = "fatal" }},
+		{name: "unknown level", mutate: func(e *logs_models.LogEntry) { e.Level = "verbose" }, wantField: "level"},
+		{name: "missing timestamp", mutate: func(e *logs_models.LogEntry) { e.Timestamp = time.Time{} }, wantField: "timestamp"},
+		{name: "blank message", mutate: func(e *logs_models.LogEntry) { e.Message = "   " }, wantField: "message"},
+		{name: "message too long", mutate: func(e *logs_models.LogEntry) {
+			e.Message = strings.Repeat("x", logs_models.MaxMessageLength+1)
+		}, wantField: "message"},
+		{name: "too many tags", mutate: func(e *logs_models.LogEntry) {
+			e.Tags = make([]string, logs_models.MaxTagsPerEntry+1)
+			for i := range e.Tags {
+				e.Tags[i] = "t"
+			}
+		}, wantField: "tags"},
+		{name: "empty tag", mutate: func(e *logs_models.LogEntry) { e.Tags = []string{"ok", ""} }, wantField: "tags[1]"},
+		{name: "long tag", mutate: func(e *logs_models.LogEntry) {
+			e.Tags = []string{strings.Repeat("t", logs_models.MaxTagLength+1)}
+		}, wantField: "tags[0]"},
+		{name: "long correlation id", mutate: func(e *logs_models.LogEntry) {
+			e.CorrelationID = strings.Repeat("c", logs_models.MaxCorrelationIDLength+1)
+		}, wantField: "correlation_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := valid()
+			tt.mutate(entry)
+
+			err := entry.Validate()
+			if tt.wantField == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var fieldErr *logs_models.FieldError
+			if assert.True(t, errors.As(err, &fieldErr)) {
+				assert.Equal(t, tt.wantField, fieldErr.Field)
+				assert.NotEmpty(t, fieldErr.Reason)
+			}
+			assert.True(t, errors.Is(err, logs_models.ErrInvalidLogEntry))
+		})
+	}
+}
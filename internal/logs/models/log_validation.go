@@ -0,0 +1,67 @@
+package logs_models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits enforced by LogEntry.Validate.
+const (
+	MaxMessageLength       = 10000 // characters
+	MaxTagsPerEntry        = 20
+	MaxCorrelationIDLength = 255
+)
+
+// ErrInvalidLogEntry is wrapped by every error returned from LogEntry.Validate.
+var ErrInvalidLogEntry = errors.New("invalid log entry")
+
+// FieldError reports the first invalid field of a log entry.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+// Unwrap lets callers match any FieldError with errors.Is(err, ErrInvalidLogEntry).
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidLogEntry
+}
+
+// Validate checks an entry before it is stored: a known level, a timestamp,
+// a non-empty message within MaxMessageLength, and at most MaxTagsPerEntry
+// non-empty tags of up to MaxTagLength characters. A correlation ID is
+// optional but limited to MaxCorrelationIDLength. The error is a *FieldError.
+func (e *LogEntry) Validate() error {
+	if _, ok := LevelRank(e.Level); !ok {
+		return &FieldError{Field: "level", Reason: fmt.Sprintf("unknown level %q; must be one of debug, info, warn, error, fatal", e.Level)}
+	}
+	if e.Timestamp.IsZero() {
+		return &FieldError{Field: "timestamp", Reason: "is required"}
+	}
+	if strings.TrimSpace(e.Message) == "" {
+		return &FieldError{Field: "message", Reason: "is required"}
+	}
+	if n := utf8.RuneCountInString(e.Message); n > MaxMessageLength {
+		return &FieldError{Field: "message", Reason: fmt.Sprintf("is %d characters; the limit is %d", n, MaxMessageLength)}
+	}
+	if len(e.Tags) > MaxTagsPerEntry {
+		return &FieldError{Field: "tags", Reason: fmt.Sprintf("has %d tags; the limit is %d", len(e.Tags), MaxTagsPerEntry)}
+	}
+	for i, tag := range e.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return &FieldError{Field: fmt.Sprintf("tags[%d]", i), Reason: "must not be empty"}
+		}
+		if len(tag) > MaxTagLength {
+			return &FieldError{Field: fmt.Sprintf("tags[%d]", i), Reason: fmt.Sprintf("must be at most %d characters", MaxTagLength)}
+		}
+	}
+	if len(e.CorrelationID) > MaxCorrelationIDLength {
+		return &FieldError{Field: "correlation_id", Reason: fmt.Sprintf("must be at most %d characters", MaxCorrelationIDLength)}
+	}
+	return nil
+}
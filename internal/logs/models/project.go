@@ -15,6 +15,20 @@ type Project struct {
 	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 	IsActive      bool      `json:"is_active" db:"is_active"`
 
+	// MinIngestLevel makes batch ingestion drop entries below it; nil keeps all levels
+	MinIngestLevel *string `json:"min_ingest_level,omitempty" db:"min_ingest_level"`
+
+	// DailyLogQuota caps the entries batch ingestion accepts per UTC day; nil is unlimited
+	DailyLogQuota *int64 `json:"daily_log_quota,omitempty" db:"daily_log_quota"`
+
+	// SampleRates makes batch ingestion keep one in N DEBUG/INFO entries; nil keeps all
+	SampleRates SampleRates `json:"sample_rates,omitempty" db:"sample_rates"`
+
+	// PreviousAPIKeyHash is the key replaced by the last rotation. It keeps
+	// authenticating until PreviousAPIKeyExpiresAt so clients can switch over
+	PreviousAPIKeyHash      string     `json:"-" db:"previous_api_key_hash"`
+	PreviousAPIKeyExpiresAt *time.Time `json:"previous_api_key_expires_at,omitempty" db:"previous_api_key_expires_at"`
+
 	// Computed fields (from joins/aggregations)
 	LogCount     int        `json:"log_count,omitempty" db:"total_logs"`
 	ErrorCount   int        `json:"error_count,omitempty" db:"error_count"`
@@ -25,6 +39,12 @@ type Project struct {
 	ServiceCount int        `json:"service_count,omitempty" db:"service_count"`
 }
 
+// PreviousKeyActive reports whether the key replaced by the last rotation is
+// still accepted at now.
+func (p *Project) PreviousKeyActive(now time.Time) bool {
+	return p.PreviousAPIKeyHash != "" && p.PreviousAPIKeyExpiresAt != nil && now.Before(*p.PreviousAPIKeyExpiresAt)
+}
+
 // CreateProjectRequest is the request body for creating a new project
 type CreateProjectRequest struct {
 	Name          string `json:"name" binding:"required,min=1,max=255"`
@@ -46,6 +66,12 @@ type UpdateProjectRequest struct {
 	Description   *string `json:"description" binding:"omitempty,max=1000"`
 	RepositoryURL *string `json:"repository_url" binding:"omitempty,url"`
 	IsActive      *bool   `json:"is_active"`
+	// MinIngestLevel sets the lowest level batch ingestion stores; "" clears it
+	MinIngestLevel *string `json:"min_ingest_level"`
+	// DailyLogQuota sets the entries accepted per UTC day; 0 removes the quota
+	DailyLogQuota *int64 `json:"daily_log_quota"`
+	// SampleRates replaces the per-level sample rates; {} removes sampling
+	SampleRates SampleRates `json:"sample_rates"`
 }
 
 // RegenerateKeyResponse includes the new API key
@@ -53,3 +79,16 @@ type RegenerateKeyResponse struct {
 	APIKey  string `json:"api_key"`
 	Message string `json:"message"`
 }
+
+// RotateKeyRequest is the optional body of POST /api/logs/projects/:id/rotate-key
+type RotateKeyRequest struct {
+	// GracePeriodHours is how long the old key keeps working; omitted uses the default
+	GracePeriodHours *int `json:"grace_period_hours"`
+}
+
+// RotateKeyResponse includes the new API key and when the old one stops working
+type RotateKeyResponse struct {
+	PreviousKeyExpiresAt time.Time `json:"previous_key_expires_at"`
+	APIKey               string    `json:"api_key"`
+	Message              string    `json:"message"`
+}
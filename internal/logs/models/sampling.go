@@ -0,0 +1,72 @@
+package logs_models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// sampledLevels are the levels a project may sample. Warnings and errors
+// are always kept in full.
+var sampledLevels = map[string]bool{
+	LevelDebug: true,
+	LevelInfo:  true,
+}
+
+// SampleRates maps a level to N: batch ingestion keeps one in every N
+// entries of that level. Levels not listed are kept in full.
+type SampleRates map[string]int
+
+// Normalize uppercases the levels and drops rates of 1, which keep every
+// entry. It returns an error for levels that can't be sampled and for rates
+// below 1.
+func (s SampleRates) Normalize() (SampleRates, error) {
+	normalized := SampleRates{}
+	for level, n := range s {
+		upper := strings.ToUpper(strings.TrimSpace(level))
+		if !sampledLevels[upper] {
+			return nil, fmt.Errorf("invalid sample_rates level %q: must be DEBUG or INFO", level)
+		}
+		if n < 1 {
+			return nil, fmt.Errorf("invalid sample_rates rate %d for %s: must be at least 1", n, upper)
+		}
+		if n > 1 {
+			normalized[upper] = n
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// Value implements driver.Valuer; an empty map is stored as NULL.
+func (s SampleRates) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(map[string]int(s))
+}
+
+// Scan implements sql.Scanner; NULL scans to nil.
+func (s *SampleRates) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into SampleRates", value)
+	}
+	rates := map[string]int{}
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return err
+	}
+	*s = rates
+	return nil
+}
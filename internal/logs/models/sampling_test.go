@@ -0,0 +1,40 @@
+package logs_models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleRates_Normalize(t *testing.T) {
+	rates, err := SampleRates{"debug": 10, " Info ": 1}.Normalize()
+	require.NoError(t, err)
+	assert.Equal(t, SampleRates{"DEBUG": 10}, rates, "a rate of 1 keeps everything and is dropped")
+
+	rates, err = SampleRates{}.Normalize()
+	require.NoError(t, err)
+	assert.Nil(t, rates)
+
+	_, err = SampleRates{"ERROR": 10}.Normalize()
+	assert.Error(t, err, "errors are never sampled")
+
+	_, err = SampleRates{"DEBUG": 0}.Normalize()
+	assert.Error(t, err)
+}
+
+func TestSampleRates_ScanValue(t *testing.T) {
+	value, err := SampleRates{"DEBUG": 10}.Value()
+	require.NoError(t, err)
+
+	var rates SampleRates
+	require.NoError(t, rates.Scan(value))
+	assert.Equal(t, SampleRates{"DEBUG": 10}, rates)
+
+	require.NoError(t, rates.Scan(nil))
+	assert.Nil(t, rates)
+
+	value, err = SampleRates(nil).Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
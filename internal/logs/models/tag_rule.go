@@ -0,0 +1,122 @@
+package logs_models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxTagLength caps the length of a tag produced by a ProjectTagRule.
+const MaxTagLength = 64
+
+// ErrInvalidTagRule is returned when a ProjectTagRule fails validation.
+var ErrInvalidTagRule = errors.New("invalid tag rule")
+
+// validRuleLevels are the levels a rule may match on.
+var validRuleLevels = map[string]bool{
+	"DEBUG": true,
+	"INFO":  true,
+	"WARN":  true,
+	"ERROR": true,
+}
+
+// ProjectTagRule adds Tag to every ingested log of a project that matches
+// all of its non-empty conditions, e.g. Level=ERROR and Service=payments
+// adds "critical".
+type ProjectTagRule struct {
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	MessagePattern string    `json:"message_pattern,omitempty"` // RE2 regex matched against the message
+	Level          string    `json:"level,omitempty"`           // Exact level, stored uppercase
+	Service        string    `json:"service,omitempty"`         // Exact service name
+	Tag            string    `json:"tag"`
+	ID             int64     `json:"id"`
+	ProjectID      int64     `json:"project_id"`
+}
+
+// Validate normalizes the rule and checks that it can be compiled: a tag,
+// at least one condition, a known level and a valid regex.
+func (r *ProjectTagRule) Validate() error {
+	_, err := r.Compile()
+	return err
+}
+
+// Compile normalizes and validates the rule and returns it ready for matching.
+func (r *ProjectTagRule) Compile() (*CompiledTagRule, error) {
+	r.Tag = strings.TrimSpace(r.Tag)
+	r.Level = strings.ToUpper(strings.TrimSpace(r.Level))
+	r.Service = strings.TrimSpace(r.Service)
+
+	if r.Tag == "" {
+		return nil, fmt.Errorf("%w: tag is required", ErrInvalidTagRule)
+	}
+	if len(r.Tag) > MaxTagLength {
+		return nil, fmt.Errorf("%w: tag must be at most %d characters", ErrInvalidTagRule, MaxTagLength)
+	}
+	if r.MessagePattern == "" && r.Level == "" && r.Service == "" {
+		return nil, fmt.Errorf("%w: at least one of message_pattern, level or service is required", ErrInvalidTagRule)
+	}
+	if r.Level != "" && !validRuleLevels[r.Level] {
+		return nil, fmt.Errorf("%w: level %q must be one of DEBUG, INFO, WARN, ERROR", ErrInvalidTagRule, r.Level)
+	}
+
+	compiled := &CompiledTagRule{Rule: *r}
+	if r.MessagePattern != "" {
+		pattern, err := regexp.Compile(r.MessagePattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: message_pattern is not a valid regular expression: %v", ErrInvalidTagRule, err)
+		}
+		compiled.pattern = pattern
+	}
+	return compiled, nil
+}
+
+// CompiledTagRule is a validated ProjectTagRule with its regex compiled once.
+type CompiledTagRule struct {
+	pattern *regexp.Regexp
+	Rule    ProjectTagRule
+}
+
+// Matches reports whether every condition of the rule holds for entry.
+// Batch-ingested entries carry their service in ServiceName, so that is
+// preferred over Service.
+func (c *CompiledTagRule) Matches(entry *LogEntry) bool {
+	if c.Rule.Level != "" && !strings.EqualFold(c.Rule.Level, entry.Level) {
+		return false
+	}
+	if c.Rule.Service != "" {
+		service := entry.ServiceName
+		if service == "" {
+			service = entry.Service
+		}
+		if c.Rule.Service != service {
+			return false
+		}
+	}
+	if c.pattern != nil && !c.pattern.MatchString(entry.Message) {
+		return false
+	}
+	return true
+}
+
+// ApplyTagRules appends the tag of every matching rule to entry.Tags,
+// skipping tags the entry already has.
+func ApplyTagRules(rules []*CompiledTagRule, entry *LogEntry) {
+	for _, rule := range rules {
+		if !rule.Matches(entry) || hasTag(entry.Tags, rule.Rule.Tag) {
+			continue
+		}
+		entry.Tags = append(entry.Tags, rule.Rule.Tag)
+	}
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
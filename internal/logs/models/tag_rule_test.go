@@ -0,0 +1,65 @@
+package logs_models_test
+
+import (
+	"errors"
+	"testing"
+
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectTagRule_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    logs_models.ProjectTagRule
+		wantErr bool
+	}{
+		{name: "regex only", rule: logs_models.ProjectTagRule{MessagePattern: `timeout \d+ms`, Tag: "slow"}},
+		{name: "level and service", rule: logs_models.ProjectTagRule{Level: "error", Service: "payments", Tag: "critical"}},
+		{name: "invalid regex", rule: logs_models.ProjectTagRule{MessagePattern: `(unclosed`, Tag: "x"}, wantErr: true},
+		{name: "missing tag", rule: logs_models.ProjectTagRule{Level: "ERROR"}, wantErr: true},
+		{name: "no conditions", rule: logs_models.ProjectTagRule{Tag: "all"}, wantErr: true},
+		{name: "unknown level", rule: logs_models.ProjectTagRule{Level: "FATAL", Tag: "x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.True(t, errors.Is(err, logs_models.ErrInvalidTagRule))
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestProjectTagRule_ValidateNormalizesLevel(t *testing.T) {
+	rule := logs_models.ProjectTagRule{Level: " warn ", Tag: " noisy "}
+	require.NoError(t, rule.Validate())
+	assert.Equal(t, "WARN", rule.Level)
+	assert.Equal(t, "noisy", rule.Tag)
+}
+
+func TestApplyTagRules(t *testing.T) {
+	compile := func(rule logs_models.ProjectTagRule) *logs_models.CompiledTagRule {
+		c, err := rule.Compile()
+		require.NoError(t, err)
+		return c
+	}
+	rules := []*logs_models.CompiledTagRule{
+		compile(logs_models.ProjectTagRule{Level: "ERROR", Service: "payments", Tag: "critical"}),
+		compile(logs_models.ProjectTagRule{MessagePattern: `(?i)timeout`, Tag: "slow"}),
+		compile(logs_models.ProjectTagRule{MessagePattern: `card`, Tag: "slow"}),
+	}
+
+	entry := &logs_models.LogEntry{ServiceName: "payments", Level: "error", Message: "card charge Timeout"}
+	logs_models.ApplyTagRules(rules, entry)
+	assert.Equal(t, []string{"critical", "slow"}, entry.Tags)
+
+	other := &logs_models.LogEntry{Service: "search", Level: "ERROR", Message: "index missing"}
+	logs_models.ApplyTagRules(rules, other)
+	assert.Empty(t, other.Tags)
+}
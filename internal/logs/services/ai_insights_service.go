@@ -2,19 +2,33 @@ package logs_services
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
 	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // AIInsightsService handles AI-powered log analysis
 type AIInsightsService struct {
-	aiClient AIProvider
-	logRepo  LogRepository
-	repo     AIInsightsRepository
+	aiClient   AIProvider
+	logRepo    LogRepository
+	repo       AIInsightsRepository
+	windowLogs WindowLogSource
+	correlated CorrelatedInsightsRepository
+	admins     Admins
+	usage      ai.UsageRecorder
+	usageModel *ai.ModelInfo // Provider, and the model used when a request names none
+	logger     *logrus.Logger
 }
 
 // AIProvider interface for AI model integration
@@ -28,14 +42,19 @@ type AIRequest struct {
 	Prompt string `json:"prompt"`
 }
 
-// AIResponse represents AI model response
+// AIResponse represents AI model response. Token counts and cost are as
+// reported by the provider.
 type AIResponse struct {
-	Content string `json:"content"`
+	Content      string  `json:"content"`
+	InputTokens  int     `json:"input_tokens,omitempty"`
+	OutputTokens int     `json:"output_tokens,omitempty"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
 }
 
-// LogRepository interface for fetching logs
+// LogRepository interface for fetching logs. A non-zero ownerUserID only
+// finds entries of that user's projects.
 type LogRepository interface {
-	GetByID(ctx context.Context, id int64) (*logs_models.LogEntry, error)
+	GetByID(ctx context.Context, id int64, ownerUserID int) (*logs_models.LogEntry, error)
 }
 
 // AIInsightsRepository interface for database operations
@@ -53,22 +72,39 @@ func NewAIInsightsService(aiClient AIProvider, logRepo LogRepository, insightsRe
 	}
 }
 
-// GenerateInsights generates AI insights for a log entry
-func (s *AIInsightsService) GenerateInsights(ctx context.Context, logID int64, model string) (*logs_models.AIInsight, error) {
+// SetAdmins sets the users who can analyze logs of every project; everyone
+// else is limited to the projects they own.
+func (s *AIInsightsService) SetAdmins(userIDs []int) {
+	s.admins = NewAdmins(userIDs)
+}
+
+// SetUsageRecorder records the tokens, latency and cost of every insight
+// against the requesting user. model describes the configured provider, as
+// returned by its GetModelInfo. Recording failures are logged to logger.
+func (s *AIInsightsService) SetUsageRecorder(recorder ai.UsageRecorder, model *ai.ModelInfo, logger *logrus.Logger) {
+	s.usage = recorder
+	s.usageModel = model
+	s.logger = logger
+}
+
+// GenerateInsights generates AI insights for a log entry. It fails with
+// ErrLogNotFound if the entry isn't in one of userID's projects (unless
+// userID is an admin).
+func (s *AIInsightsService) GenerateInsights(ctx context.Context, logID int64, model string, userID int) (*logs_models.AIInsight, error) {
 	// 1. Fetch log entry
-	log, err := s.logRepo.GetByID(ctx, logID)
+	log, err := s.fetchLog(ctx, logID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch log: %w", err)
+		return nil, err
 	}
 
 	// 2. Build prompt
 	prompt := s.buildAnalysisPrompt(log)
 
 	// 3. Call AI
-	response, err := s.aiClient.Generate(ctx, &AIRequest{
+	response, err := s.generate(ctx, userID, &AIRequest{
 		Model:  model,
 		Prompt: prompt,
-	})
+	}, attribute.Int64("log.id", logID))
 	if err != nil {
 		return nil, fmt.Errorf("AI generation failed: %w", err)
 	}
@@ -92,11 +128,75 @@ func (s *AIInsightsService) GenerateInsights(ctx context.Context, logID int64, m
 	return savedInsight, nil
 }
 
-// GetInsights retrieves existing AI insights for a log
-func (s *AIInsightsService) GetInsights(ctx context.Context, logID int64) (*logs_models.AIInsight, error) {
+// generate calls the AI provider for userID in a child span, so model
+// latency shows up in the request's trace
+func (s *AIInsightsService) generate(ctx context.Context, userID int, req *AIRequest, attrs ...attribute.KeyValue) (*AIResponse, error) {
+	attrs = append(attrs, attribute.String("ai.model", req.Model))
+	ctx, span := otel.Tracer("devsmith-logs").Start(ctx, "logs.insights.generate", trace.WithAttributes(attrs...))
+	defer span.End()
+
+	start := time.Now()
+	response, err := s.aiClient.Generate(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "generation failed")
+	}
+	s.recordUsage(ctx, userID, req.Model, response, err, time.Since(start))
+	return response, err
+}
+
+// recordUsage stores one usage row for a call. Recording is best-effort; a
+// failure is logged and never fails the call.
+func (s *AIInsightsService) recordUsage(ctx context.Context, userID int, model string, resp *AIResponse, callErr error, latency time.Duration) {
+	if s.usage == nil {
+		return
+	}
+	if model == "" {
+		model = s.usageModel.Model
+	}
+	record := &ai.UsageRecord{
+		UserID:   userID,
+		AppName:  "logs",
+		Provider: s.usageModel.Provider,
+		Model:    model,
+		Latency:  latency,
+		Success:  callErr == nil && resp != nil,
+	}
+	if resp != nil {
+		record.InputTokens = resp.InputTokens
+		record.OutputTokens = resp.OutputTokens
+		record.CostUSD = resp.CostUSD
+	}
+	if callErr != nil {
+		record.Error = callErr.Error()
+	}
+	// The caller's context may already be cancelled
+	if err := s.usage.Record(context.WithoutCancel(ctx), record); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{"user_id": userID, "model": model}).Warn("Failed to record LLM usage")
+	}
+}
+
+// GetInsights retrieves existing AI insights for a log, failing with
+// ErrLogNotFound like GenerateInsights
+func (s *AIInsightsService) GetInsights(ctx context.Context, logID int64, userID int) (*logs_models.AIInsight, error) {
+	if _, err := s.fetchLog(ctx, logID, userID); err != nil {
+		return nil, err
+	}
 	return s.repo.GetByLogID(ctx, logID)
 }
 
+// fetchLog loads a log entry userID may read
+func (s *AIInsightsService) fetchLog(ctx context.Context, logID int64, userID int) (*logs_models.LogEntry, error) {
+	log, err := s.logRepo.GetByID(ctx, logID, s.admins.OwnerScope(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLogNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch log: %w", err)
+	}
+	return log, nil
+}
+
 // buildAnalysisPrompt constructs the AI prompt for log analysis
 func (s *AIInsightsService) buildAnalysisPrompt(log *logs_models.LogEntry) string {
 	metadataJSON := "{}"
@@ -36,6 +36,9 @@ func (a *AnthropicAdapter) Generate(ctx context.Context, request *AIRequest) (*A
 
 	// Convert ai.Response to AIResponse
 	return &AIResponse{
-		Content: aiResp.Content,
+		Content:      aiResp.Content,
+		InputTokens:  aiResp.InputTokens,
+		OutputTokens: aiResp.OutputTokens,
+		CostUSD:      aiResp.CostUSD,
 	}, nil
 }
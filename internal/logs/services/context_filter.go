@@ -0,0 +1,85 @@
+package logs_services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+)
+
+// MaxContextFilters caps how many context_filter expressions one query may carry.
+const MaxContextFilters = 10
+
+// ErrInvalidContextFilter is returned when a context_filter expression is
+// malformed, uses an unknown operator, or references a disallowed key path.
+var ErrInvalidContextFilter = errors.New("invalid context filter")
+
+// contextFilterPattern splits "context.<path><op><value>". The operator is
+// captured loosely so unknown operators can be reported as such.
+var contextFilterPattern = regexp.MustCompile(`^context\.([^=!<>~]+)([=!<>~]+)(.*)$`)
+
+// contextPathPattern is the allowlist for metadata key paths: dot-separated
+// identifiers only, so nothing but plain keys ever reaches the JSONB path.
+var contextPathPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// contextFilterOps lists the supported operators.
+var contextFilterOps = map[string]bool{
+	"=":  true,
+	"!=": true,
+	">":  true,
+	">=": true,
+	"<":  true,
+	"<=": true,
+}
+
+// ParseContextFilter parses an expression such as "context.user_id=1000" or
+// "context.status_code>=400" into a repository filter. Range operators
+// require a numeric value.
+func ParseContextFilter(expr string) (logs_db.ContextFilter, error) {
+	m := contextFilterPattern.FindStringSubmatch(strings.TrimSpace(expr))
+	if m == nil {
+		return logs_db.ContextFilter{}, fmt.Errorf("%w: %q must look like context.<key><op><value>", ErrInvalidContextFilter, expr)
+	}
+
+	path, op, value := strings.TrimSpace(m[1]), m[2], strings.TrimSpace(m[3])
+
+	if !contextPathPattern.MatchString(path) {
+		return logs_db.ContextFilter{}, fmt.Errorf("%w: key path %q is not allowed", ErrInvalidContextFilter, path)
+	}
+	if !contextFilterOps[op] {
+		return logs_db.ContextFilter{}, fmt.Errorf("%w: unknown operator %q", ErrInvalidContextFilter, op)
+	}
+
+	filter := logs_db.ContextFilter{
+		Path:  strings.Split(path, "."),
+		Op:    op,
+		Value: value,
+	}
+	if filter.IsNumeric() {
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return logs_db.ContextFilter{}, fmt.Errorf("%w: operator %q requires a numeric value, got %q", ErrInvalidContextFilter, op, value)
+		}
+	}
+
+	return filter, nil
+}
+
+// parseContextFilters parses every expression, failing on the first bad one.
+func parseContextFilters(exprs []string) ([]logs_db.ContextFilter, error) {
+	if len(exprs) > MaxContextFilters {
+		return nil, fmt.Errorf("%w: at most %d filters allowed", ErrInvalidContextFilter, MaxContextFilters)
+	}
+
+	parsed := make([]logs_db.ContextFilter, 0, len(exprs))
+	for _, expr := range exprs {
+		f, err := ParseContextFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, f)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,84 @@
+package logs_services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseContextFilter_Equality(t *testing.T) {
+	f, err := ParseContextFilter("context.user_id=1000")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_id"}, f.Path)
+	assert.Equal(t, "=", f.Op)
+	assert.Equal(t, "1000", f.Value)
+	assert.False(t, f.IsNumeric())
+}
+
+func TestParseContextFilter_NumericRange(t *testing.T) {
+	f, err := ParseContextFilter("context.request.status_code>=400")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"request", "status_code"}, f.Path)
+	assert.Equal(t, ">=", f.Op)
+	assert.Equal(t, "400", f.Value)
+	assert.True(t, f.IsNumeric())
+}
+
+func TestParseContextFilter_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{name: "unknown operator", expr: "context.status_code=~400"},
+		{name: "double equals", expr: "context.status_code==400"},
+		{name: "missing context prefix", expr: "user_id=1000"},
+		{name: "missing operator", expr: "context.user_id"},
+		{name: "path with quote", expr: "context.user'id=1"},
+		{name: "path with dash", expr: "context.user-id=1"},
+		{name: "empty path segment", expr: "context.user..id=1"},
+		{name: "non-numeric range value", expr: "context.status_code>abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseContextFilter(tt.expr)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrInvalidContextFilter))
+		})
+	}
+}
+
+func TestParseContextFilter_ValueMayContainOperators(t *testing.T) {
+	f, err := ParseContextFilter("context.query=a=b")
+	require.NoError(t, err)
+	assert.Equal(t, "=", f.Op)
+	assert.Equal(t, "a=b", f.Value)
+}
+
+func TestRestLogService_Query_InvalidContextFilter(t *testing.T) {
+	svc := NewRestLogService(logs_db.NewLogRepository(nil), logrus.New())
+
+	_, err := svc.Query(context.Background(), map[string]interface{}{
+		"context_filter": []string{"context.status_code~400"},
+	}, map[string]int{"limit": 10})
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidContextFilter))
+}
+
+func TestRestLogService_Query_TooManyContextFilters(t *testing.T) {
+	svc := NewRestLogService(logs_db.NewLogRepository(nil), logrus.New())
+
+	exprs := make([]string, MaxContextFilters+1)
+	for i := range exprs {
+		exprs[i] = "context.user_id=1"
+	}
+
+	_, err := svc.Query(context.Background(), map[string]interface{}{"context_filter": exprs}, map[string]int{"limit": 10})
+	assert.True(t, errors.Is(err, ErrInvalidContextFilter))
+}
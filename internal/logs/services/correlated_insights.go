@@ -0,0 +1,211 @@
+package logs_services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Limits for correlated insights, keeping the prompt within model token limits.
+const (
+	MaxCorrelatedLogs       = 50
+	MaxCorrelatedMessageLen = 500 // Runes per message sent to the model
+	MaxCorrelationWindow    = 24 * time.Hour
+)
+
+// Correlated insight errors
+var (
+	ErrInvalidCorrelationWindow = errors.New("invalid correlation window")
+	ErrNoCorrelatedLogs         = errors.New("no logs matched the correlation window")
+	ErrCorrelationDisabled      = errors.New("correlated insights are not configured")
+)
+
+// WindowLogSource fetches the logs a correlated insight is built from.
+type WindowLogSource interface {
+	FindInWindow(ctx context.Context, filter logs_db.WindowFilter, limit int) ([]logs_models.LogEntry, error)
+}
+
+// CorrelatedInsightsRepository caches correlated insights by request hash.
+type CorrelatedInsightsRepository interface {
+	GetCorrelated(ctx context.Context, cacheKey string) (*logs_models.CorrelatedInsight, error)
+	UpsertCorrelated(ctx context.Context, insight *logs_models.CorrelatedInsight) (*logs_models.CorrelatedInsight, error)
+}
+
+// CorrelatedInsightRequest selects the logs to analyze together.
+type CorrelatedInsightRequest struct {
+	Start     time.Time
+	End       time.Time
+	ProjectID *int64
+	Model     string
+	Service   string
+	Level     string
+	Refresh   bool // Bypass the cache and regenerate
+	UserID    int  // The caller; only their projects are analyzed unless an admin
+}
+
+// SetCorrelation enables GenerateCorrelatedInsights.
+func (s *AIInsightsService) SetCorrelation(logs WindowLogSource, cache CorrelatedInsightsRepository) {
+	s.windowLogs = logs
+	s.correlated = cache
+}
+
+// GenerateCorrelatedInsights analyzes the logs matching a time window and
+// filter as one group, so the model can reason about an error burst rather
+// than a single line. At most MaxCorrelatedLogs of the newest matches are
+// sent. Results are cached per window + filter + model unless Refresh is set.
+func (s *AIInsightsService) GenerateCorrelatedInsights(ctx context.Context, req CorrelatedInsightRequest) (*logs_models.CorrelatedInsight, error) {
+	if s.windowLogs == nil || s.correlated == nil {
+		return nil, ErrCorrelationDisabled
+	}
+	if !req.Start.Before(req.End) {
+		return nil, fmt.Errorf("%w: start must be before end", ErrInvalidCorrelationWindow)
+	}
+	if req.End.Sub(req.Start) > MaxCorrelationWindow {
+		return nil, fmt.Errorf("%w: window must be at most %s", ErrInvalidCorrelationWindow, MaxCorrelationWindow)
+	}
+
+	owner := s.admins.OwnerScope(req.UserID)
+	key := correlationCacheKey(req, owner)
+	if !req.Refresh {
+		cached, err := s.correlated.GetCorrelated(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cached insight: %w", err)
+		}
+		if cached != nil {
+			cached.Cached = true
+			return cached, nil
+		}
+	}
+
+	// One extra row tells us whether the window held more than we send
+	entries, err := s.windowLogs.FindInWindow(ctx, logs_db.WindowFilter{
+		Start:       req.Start,
+		End:         req.End,
+		ProjectID:   req.ProjectID,
+		Service:     req.Service,
+		Level:       req.Level,
+		OwnerUserID: owner,
+	}, MaxCorrelatedLogs+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch logs: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, ErrNoCorrelatedLogs
+	}
+
+	truncated := len(entries) > MaxCorrelatedLogs
+	if truncated {
+		entries = entries[:MaxCorrelatedLogs]
+	}
+	// Newest-first from the repository; the model reads a timeline better oldest-first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	response, err := s.generate(ctx, req.UserID, &AIRequest{
+		Model:  req.Model,
+		Prompt: buildCorrelatedPrompt(entries, truncated),
+	}, attribute.Int("log.count", len(entries)), attribute.Bool("log.truncated", truncated))
+	if err != nil {
+		return nil, fmt.Errorf("AI generation failed: %w", err)
+	}
+
+	parsed, err := s.parseAIResponse(response.Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse AI response: %w", err)
+	}
+
+	logIDs := make([]int64, len(entries))
+	for i := range entries {
+		logIDs[i] = entries[i].ID
+	}
+
+	saved, err := s.correlated.UpsertCorrelated(ctx, &logs_models.CorrelatedInsight{
+		CacheKey:    key,
+		LogIDs:      logIDs,
+		Analysis:    parsed.Analysis,
+		RootCause:   parsed.RootCause,
+		Suggestions: parsed.Suggestions,
+		ModelUsed:   req.Model,
+		Truncated:   truncated,
+		GeneratedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save insight: %w", err)
+	}
+
+	return saved, nil
+}
+
+// correlationCacheKey hashes everything that determines the model's input
+// and output, so equal requests share one cached insight.
+func correlationCacheKey(req CorrelatedInsightRequest, ownerUserID int) string {
+	key := struct {
+		Start     string `json:"start"`
+		End       string `json:"end"`
+		ProjectID *int64 `json:"project_id"`
+		Service   string `json:"service"`
+		Level     string `json:"level"`
+		Model     string `json:"model"`
+		Owner     int    `json:"owner"`
+	}{
+		Start:     req.Start.UTC().Format(time.RFC3339Nano),
+		End:       req.End.UTC().Format(time.RFC3339Nano),
+		ProjectID: req.ProjectID,
+		Service:   req.Service,
+		Level:     strings.ToUpper(req.Level),
+		Model:     req.Model,
+		Owner:     ownerUserID,
+	}
+	raw, _ := json.Marshal(key) //nolint:errcheck // Marshaling plain strings and ints cannot fail
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// buildCorrelatedPrompt constructs the AI prompt for a group of related logs.
+func buildCorrelatedPrompt(entries []logs_models.LogEntry, truncated bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Analyze these %d related log entries together and find what connects them:\n\n", len(entries))
+	for i := range entries {
+		e := &entries[i]
+		fmt.Fprintf(&b, "[id=%d] %s %s %s: %s\n",
+			e.ID, e.CreatedAt.Format(time.RFC3339), e.Level, e.Service, truncateRunes(e.Message, MaxCorrelatedMessageLen))
+	}
+	if truncated {
+		fmt.Fprintf(&b, "\n(Only the newest %d matching entries are shown.)\n", len(entries))
+	}
+
+	b.WriteString(`
+Please provide:
+1. Analysis: What do these logs indicate as a whole? (2-4 sentences)
+2. Root Cause: What most likely triggered them? Cite log ids. (1-2 sentences, leave empty if unclear)
+3. Suggestions: How to fix or prevent this? (3-5 actionable items)
+
+Format your response as JSON:
+{
+  "analysis": "Brief analysis of what these logs indicate together",
+  "root_cause": "Brief explanation of the root cause (or empty string if unclear)",
+  "suggestions": ["Actionable suggestion 1", "Actionable suggestion 2", "Actionable suggestion 3"]
+}
+
+Respond ONLY with valid JSON, no additional text.`)
+	return b.String()
+}
+
+// truncateRunes shortens s to at most limit runes, marking the cut.
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit]) + "…"
+}
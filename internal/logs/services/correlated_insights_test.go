@@ -0,0 +1,202 @@
+package logs_services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubAIProvider struct {
+	prompts []string
+}
+
+func (s *stubAIProvider) Generate(_ context.Context, req *AIRequest) (*AIResponse, error) {
+	s.prompts = append(s.prompts, req.Prompt)
+	return &AIResponse{Content: "```json\n" +
+		`{"analysis":"Pool exhausted","root_cause":"id=2 leaked connections","suggestions":["Raise pool size"]}` +
+		"\n```", InputTokens: 1200, OutputTokens: 300, CostUSD: 0.0081}, nil
+}
+
+type stubWindowLogs struct {
+	entries []logs_models.LogEntry
+	filter  logs_db.WindowFilter
+	limit   int
+}
+
+func (s *stubWindowLogs) FindInWindow(_ context.Context, filter logs_db.WindowFilter, limit int) ([]logs_models.LogEntry, error) {
+	s.filter, s.limit = filter, limit
+	if len(s.entries) > limit {
+		return s.entries[:limit], nil
+	}
+	return s.entries, nil
+}
+
+type memoryCorrelatedInsights struct {
+	byKey map[string]logs_models.CorrelatedInsight
+}
+
+func (m *memoryCorrelatedInsights) GetCorrelated(_ context.Context, key string) (*logs_models.CorrelatedInsight, error) {
+	if insight, ok := m.byKey[key]; ok {
+		return &insight, nil
+	}
+	return nil, nil
+}
+
+func (m *memoryCorrelatedInsights) UpsertCorrelated(_ context.Context, insight *logs_models.CorrelatedInsight) (*logs_models.CorrelatedInsight, error) {
+	m.byKey[insight.CacheKey] = *insight
+	return insight, nil
+}
+
+// newestFirst returns n entries the way the repository does: newest first.
+func newestFirst(n int, message string) []logs_models.LogEntry {
+	start := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+	entries := make([]logs_models.LogEntry, n)
+	for i := range entries {
+		id := int64(n - i)
+		entries[i] = logs_models.LogEntry{
+			ID:        id,
+			Level:     "ERROR",
+			Service:   "payments",
+			Message:   message,
+			CreatedAt: start.Add(time.Duration(id) * time.Second),
+		}
+	}
+	return entries
+}
+
+func setupCorrelatedService(entries []logs_models.LogEntry) (*AIInsightsService, *stubAIProvider, *stubWindowLogs) {
+	provider := &stubAIProvider{}
+	source := &stubWindowLogs{entries: entries}
+	svc := NewAIInsightsService(provider, nil, nil)
+	svc.SetCorrelation(source, &memoryCorrelatedInsights{byKey: map[string]logs_models.CorrelatedInsight{}})
+	return svc, provider, source
+}
+
+// usageRecords collects usage records in memory
+type usageRecords []*ai.UsageRecord
+
+func (r *usageRecords) Record(_ context.Context, record *ai.UsageRecord) error {
+	*r = append(*r, record)
+	return nil
+}
+
+func correlatedRequest() CorrelatedInsightRequest {
+	start := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+	return CorrelatedInsightRequest{Start: start, End: start.Add(5 * time.Minute), Service: "payments", Level: "error", Model: "test-model"}
+}
+
+func TestGenerateCorrelatedInsights_ReturnsContributingLogIDs(t *testing.T) {
+	svc, ai, source := setupCorrelatedService(newestFirst(3, "connection refused"))
+
+	insight, err := svc.GenerateCorrelatedInsights(context.Background(), correlatedRequest())
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{1, 2, 3}, insight.LogIDs) // oldest first
+	assert.Equal(t, "Pool exhausted", insight.Analysis)
+	assert.False(t, insight.Truncated)
+	assert.False(t, insight.Cached)
+	assert.Equal(t, "payments", source.filter.Service)
+	require.Len(t, ai.prompts, 1)
+	assert.Less(t, strings.Index(ai.prompts[0], "[id=1]"), strings.Index(ai.prompts[0], "[id=3]"))
+}
+
+func TestGenerateCorrelatedInsights_CapsLogsAndTruncatesMessages(t *testing.T) {
+	svc, ai, source := setupCorrelatedService(newestFirst(MaxCorrelatedLogs+20, strings.Repeat("x", 2*MaxCorrelatedMessageLen)))
+
+	insight, err := svc.GenerateCorrelatedInsights(context.Background(), correlatedRequest())
+	require.NoError(t, err)
+
+	assert.Equal(t, MaxCorrelatedLogs+1, source.limit)
+	assert.Len(t, insight.LogIDs, MaxCorrelatedLogs)
+	assert.True(t, insight.Truncated)
+	assert.NotContains(t, ai.prompts[0], strings.Repeat("x", MaxCorrelatedMessageLen+1))
+	assert.Contains(t, ai.prompts[0], strings.Repeat("x", MaxCorrelatedMessageLen)+"…")
+}
+
+func TestGenerateCorrelatedInsights_CachesByWindowAndFilter(t *testing.T) {
+	svc, ai, _ := setupCorrelatedService(newestFirst(2, "timeout"))
+	ctx := context.Background()
+
+	_, err := svc.GenerateCorrelatedInsights(ctx, correlatedRequest())
+	require.NoError(t, err)
+
+	cached, err := svc.GenerateCorrelatedInsights(ctx, correlatedRequest())
+	require.NoError(t, err)
+	assert.True(t, cached.Cached)
+	assert.Len(t, ai.prompts, 1)
+
+	// A different filter is a different cache entry
+	other := correlatedRequest()
+	other.Service = "search"
+	_, err = svc.GenerateCorrelatedInsights(ctx, other)
+	require.NoError(t, err)
+	assert.Len(t, ai.prompts, 2)
+
+	// Refresh bypasses the cache
+	refresh := correlatedRequest()
+	refresh.Refresh = true
+	fresh, err := svc.GenerateCorrelatedInsights(ctx, refresh)
+	require.NoError(t, err)
+	assert.False(t, fresh.Cached)
+	assert.Len(t, ai.prompts, 3)
+}
+
+func TestGenerateCorrelatedInsights_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	svc, _, _ := setupCorrelatedService(nil)
+	_, err := svc.GenerateCorrelatedInsights(ctx, correlatedRequest())
+	assert.True(t, errors.Is(err, ErrNoCorrelatedLogs))
+
+	inverted := correlatedRequest()
+	inverted.Start, inverted.End = inverted.End, inverted.Start
+	_, err = svc.GenerateCorrelatedInsights(ctx, inverted)
+	assert.True(t, errors.Is(err, ErrInvalidCorrelationWindow))
+
+	tooWide := correlatedRequest()
+	tooWide.End = tooWide.Start.Add(MaxCorrelationWindow + time.Minute)
+	_, err = svc.GenerateCorrelatedInsights(ctx, tooWide)
+	assert.True(t, errors.Is(err, ErrInvalidCorrelationWindow))
+
+	_, err = NewAIInsightsService(&stubAIProvider{}, nil, nil).GenerateCorrelatedInsights(ctx, correlatedRequest())
+	assert.True(t, errors.Is(err, ErrCorrelationDisabled))
+}
+
+func TestGenerateCorrelatedInsights_RecordsUsage(t *testing.T) {
+	svc, _, _ := setupCorrelatedService(newestFirst(3, "connection refused"))
+	records := &usageRecords{}
+	svc.SetUsageRecorder(records, &ai.ModelInfo{Provider: "anthropic", Model: "default-model"}, logrus.New())
+
+	req := correlatedRequest()
+	req.UserID = 7
+	_, err := svc.GenerateCorrelatedInsights(context.Background(), req)
+	require.NoError(t, err)
+
+	require.Len(t, *records, 1)
+	record := (*records)[0]
+	assert.Equal(t, 7, record.UserID)
+	assert.Equal(t, "logs", record.AppName)
+	assert.Equal(t, "anthropic", record.Provider)
+	assert.Equal(t, "test-model", record.Model)
+	assert.Equal(t, 1200, record.InputTokens)
+	assert.Equal(t, 300, record.OutputTokens)
+	assert.InDelta(t, 0.0081, record.CostUSD, 1e-9, "the provider's estimate")
+	assert.True(t, record.Success)
+
+	// Without a model in the request, the configured one is recorded
+	req.Model = ""
+	req.Start = req.Start.Add(time.Minute) // Not served from the cache
+	_, err = svc.GenerateCorrelatedInsights(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, *records, 2)
+	assert.Equal(t, "default-model", (*records)[1].Model)
+}
@@ -0,0 +1,377 @@
+package logs_services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+)
+
+// Dead-letter defaults. A batch that still fails after
+// DefaultDeadLetterMaxAttempts replays (an hour at the default interval) is
+// parked.
+const (
+	DefaultDeadLetterKey            = "logs:dead_letter"
+	DefaultDeadLetterParkedKey      = "logs:dead_letter:parked"
+	DefaultDeadLetterReplayInterval = time.Minute
+	DefaultDeadLetterMaxAttempts    = 60
+)
+
+// Dead-letter metrics, published under /debug/vars.
+var (
+	deadLetterQueued   = expvar.NewInt("logs_dead_letter_batches_queued_total")
+	deadLetterReplayed = expvar.NewInt("logs_dead_letter_batches_replayed_total")
+	deadLetterParked   = expvar.NewInt("logs_dead_letter_batches_parked_total")
+)
+
+// ErrDeadLetterReplayRunning is returned when a replay is requested while one
+// is in progress.
+var ErrDeadLetterReplayRunning = errors.New("dead-letter replay already in progress")
+
+// ErrDeadLetterCorrupt is returned by DeadLetterStore.Oldest, along with the
+// raw batch, when the stored batch can't be decoded.
+var ErrDeadLetterCorrupt = errors.New("dead-letter batch can't be decoded")
+
+// IsTransientStorageError reports whether err means the database couldn't be
+// reached or was briefly unable to serve the insert, so the same batch may
+// succeed later. Constraint violations, bad data and the like are final:
+// replaying them would fail forever.
+func IsTransientStorageError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code.Class() == "08", // Connection exception
+			pqErr.Code.Class() == "53", // Insufficient resources
+			pqErr.Code.Class() == "57", // Operator intervention, e.g. shutdown
+			pqErr.Code == "40001",      // Serialization failure
+			pqErr.Code == "40P01":      // Deadlock
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// BatchInserter stores a batch of log entries, skipping entries whose
+// idempotency key was already ingested.
+type BatchInserter interface {
+	CreateBatch(ctx context.Context, entries []*logs_models.LogEntry) (logs_db.BatchInsertResult, error)
+}
+
+// DeadLetterBatch is a batch that could not be stored, held for replay.
+type DeadLetterBatch struct {
+	FailedAt time.Time               `json:"failed_at"`
+	Error    string                  `json:"error"`
+	Attempts int                     `json:"attempts,omitempty"` // Failed replays
+	Entries  []*logs_models.LogEntry `json:"entries"`
+	raw      string                  // Stored form, set by DeadLetterStore.Oldest
+}
+
+// DeadLetterStore is a durable FIFO of batches waiting to be replayed, with
+// a separate list of parked batches that are no longer retried.
+type DeadLetterStore interface {
+	Push(ctx context.Context, batch *DeadLetterBatch) error
+	// Oldest returns the batch queued first, or nil when the store is empty.
+	// A batch that can't be decoded is returned with ErrDeadLetterCorrupt.
+	Oldest(ctx context.Context) (*DeadLetterBatch, error)
+	// Remove deletes a batch returned by Oldest.
+	Remove(ctx context.Context, batch *DeadLetterBatch) error
+	// Update stores batch, e.g. its new attempt count, in place of the batch
+	// Oldest returned.
+	Update(ctx context.Context, batch *DeadLetterBatch) error
+	// Park moves a batch returned by Oldest to the parked list.
+	Park(ctx context.Context, batch *DeadLetterBatch) error
+	Len(ctx context.Context) (int64, error)
+}
+
+// RedisDeadLetterStore keeps dead-lettered batches in a Redis list, newest
+// at the head, and parked batches in a second list.
+type RedisDeadLetterStore struct {
+	client    *redis.Client
+	key       string
+	parkedKey string
+}
+
+// NewRedisDeadLetterStore creates a store on the DefaultDeadLetterKey and
+// DefaultDeadLetterParkedKey lists.
+func NewRedisDeadLetterStore(client *redis.Client) *RedisDeadLetterStore {
+	return &RedisDeadLetterStore{client: client, key: DefaultDeadLetterKey, parkedKey: DefaultDeadLetterParkedKey}
+}
+
+// replaceDeadLetter swaps the stored batch ARGV[1] for ARGV[2], putting the
+// new one at the tail of KEYS[2] (the queue itself, to keep its place as the
+// oldest, or the parked list). Nothing happens if another replica already
+// removed the batch.
+var replaceDeadLetter = redis.NewScript(`
+if redis.call('LREM', KEYS[1], -1, ARGV[1]) == 1 then
+	redis.call('RPUSH', KEYS[2], ARGV[2])
+end
+return 0`)
+
+// Push appends batch to the queue.
+func (s *RedisDeadLetterStore) Push(ctx context.Context, batch *DeadLetterBatch) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("marshal dead-letter batch: %w", err)
+	}
+	return s.client.LPush(ctx, s.key, data).Err()
+}
+
+// Oldest returns the batch at the tail of the list without removing it.
+func (s *RedisDeadLetterStore) Oldest(ctx context.Context) (*DeadLetterBatch, error) {
+	raw, err := s.client.LIndex(ctx, s.key, -1).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var batch DeadLetterBatch
+	if err := json.Unmarshal([]byte(raw), &batch); err != nil {
+		return &DeadLetterBatch{raw: raw}, fmt.Errorf("%w: %v", ErrDeadLetterCorrupt, err)
+	}
+	batch.raw = raw
+	return &batch, nil
+}
+
+// Update replaces the stored batch, keeping its place at the tail.
+func (s *RedisDeadLetterStore) Update(ctx context.Context, batch *DeadLetterBatch) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("marshal dead-letter batch: %w", err)
+	}
+	return replaceDeadLetter.Run(ctx, s.client, []string{s.key, s.key}, batch.raw, data).Err()
+}
+
+// Park moves the stored batch, unchanged, to the parked list.
+func (s *RedisDeadLetterStore) Park(ctx context.Context, batch *DeadLetterBatch) error {
+	return replaceDeadLetter.Run(ctx, s.client, []string{s.key, s.parkedKey}, batch.raw, batch.raw).Err()
+}
+
+// Remove deletes exactly the stored batch, so a replica replaying the same
+// batch concurrently can't make this one remove a different batch.
+func (s *RedisDeadLetterStore) Remove(ctx context.Context, batch *DeadLetterBatch) error {
+	return s.client.LRem(ctx, s.key, -1, batch.raw).Err()
+}
+
+// Len returns the number of queued batches.
+func (s *RedisDeadLetterStore) Len(ctx context.Context) (int64, error) {
+	return s.client.LLen(ctx, s.key).Result()
+}
+
+// DeadLetterReplayResult summarizes one replay.
+type DeadLetterReplayResult struct {
+	Replayed  int   `json:"replayed"` // Batches stored and removed from the queue
+	Inserted  int   `json:"inserted"`
+	Deduped   int   `json:"deduped"`   // Entries that had already been stored
+	Parked    int   `json:"parked"`    // Batches moved to the parked list
+	Remaining int64 `json:"remaining"` // Batches still queued
+}
+
+// DeadLetterQueue holds batches the database couldn't store because it was
+// unavailable, and replays them once it recovers. Every queued entry
+// carries an idempotency key, so a batch replayed twice (after a crash, or
+// by two replicas) is stored once. A batch that can't be decoded, fails
+// with a non-transient error or keeps failing is parked, so it can't block
+// the batches behind it.
+type DeadLetterQueue struct {
+	store       DeadLetterStore
+	inserter    BatchInserter
+	logger      *logrus.Logger
+	maxAttempts int
+	now         func() time.Time
+	mu          sync.Mutex
+}
+
+// NewDeadLetterQueue creates a DeadLetterQueue replaying into inserter.
+func NewDeadLetterQueue(store DeadLetterStore, inserter BatchInserter, logger *logrus.Logger) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		store:       store,
+		inserter:    inserter,
+		logger:      logger,
+		maxAttempts: DefaultDeadLetterMaxAttempts,
+		now:         time.Now,
+	}
+}
+
+// SetMaxAttempts sets how many failed replays park a batch. Values below 1
+// are ignored.
+func (q *DeadLetterQueue) SetMaxAttempts(n int) {
+	if n > 0 {
+		q.maxAttempts = n
+	}
+}
+
+// Add queues entries that failed to insert with cause. Entries without an
+// idempotency key are given one first.
+func (q *DeadLetterQueue) Add(ctx context.Context, entries []*logs_models.LogEntry, cause error) error {
+	batchKey, err := newDeadLetterKey()
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		if entry.IdempotencyKey == "" {
+			entry.IdempotencyKey = fmt.Sprintf("%s:%d", batchKey, i)
+		}
+	}
+
+	batch := &DeadLetterBatch{FailedAt: q.now().UTC(), Entries: entries}
+	if cause != nil {
+		batch.Error = cause.Error()
+	}
+	if err := q.store.Push(ctx, batch); err != nil {
+		return fmt.Errorf("queue dead-letter batch: %w", err)
+	}
+	deadLetterQueued.Add(1)
+	return nil
+}
+
+// Count returns the number of batches waiting to be replayed.
+func (q *DeadLetterQueue) Count(ctx context.Context) (int64, error) {
+	return q.store.Len(ctx)
+}
+
+// Replay inserts queued batches oldest first, stopping at the first
+// transient failure since the database is most likely still unavailable.
+// Only one replay runs at a time; a concurrent call returns
+// ErrDeadLetterReplayRunning.
+func (q *DeadLetterQueue) Replay(ctx context.Context) (DeadLetterReplayResult, error) {
+	if !q.mu.TryLock() {
+		return DeadLetterReplayResult{}, ErrDeadLetterReplayRunning
+	}
+	defer q.mu.Unlock()
+
+	var result DeadLetterReplayResult
+	err := q.replay(ctx, &result)
+	if remaining, lenErr := q.store.Len(ctx); lenErr == nil {
+		result.Remaining = remaining
+	}
+	return result, err
+}
+
+func (q *DeadLetterQueue) replay(ctx context.Context, result *DeadLetterReplayResult) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch, err := q.store.Oldest(ctx)
+		if errors.Is(err, ErrDeadLetterCorrupt) && batch != nil {
+			if parkErr := q.park(ctx, batch, err, result); parkErr != nil {
+				return parkErr
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read dead-letter queue: %w", err)
+		}
+		if batch == nil {
+			return nil
+		}
+
+		inserted, err := q.inserter.CreateBatch(ctx, batch.Entries)
+		if err != nil {
+			batch.Attempts++
+			if IsTransientStorageError(err) && batch.Attempts < q.maxAttempts {
+				if updateErr := q.store.Update(ctx, batch); updateErr != nil {
+					q.logger.WithError(updateErr).Warn("Failed to record dead-letter replay attempt")
+				}
+				return fmt.Errorf("replay dead-letter batch from %s: %w", batch.FailedAt.Format(time.RFC3339), err)
+			}
+			if parkErr := q.park(ctx, batch, err, result); parkErr != nil {
+				return parkErr
+			}
+			continue
+		}
+		if err := q.store.Remove(ctx, batch); err != nil {
+			return fmt.Errorf("remove replayed dead-letter batch: %w", err)
+		}
+		deadLetterReplayed.Add(1)
+		result.Replayed++
+		result.Inserted += inserted.Inserted
+		result.Deduped += inserted.Deduped
+	}
+}
+
+// park moves batch out of the way of the rest of the queue.
+func (q *DeadLetterQueue) park(ctx context.Context, batch *DeadLetterBatch, cause error, result *DeadLetterReplayResult) error {
+	if err := q.store.Park(ctx, batch); err != nil {
+		return fmt.Errorf("park dead-letter batch: %w", err)
+	}
+	deadLetterParked.Add(1)
+	result.Parked++
+	q.logger.WithFields(logrus.Fields{
+		"failed_at": batch.FailedAt,
+		"attempts":  batch.Attempts,
+		"entries":   len(batch.Entries),
+	}).WithError(cause).Warn("Dead-letter batch parked")
+	return nil
+}
+
+// Start replays the queue every interval until ctx is cancelled.
+// It returns immediately.
+func (q *DeadLetterQueue) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if n, err := q.store.Len(ctx); err != nil || n == 0 {
+					continue
+				}
+				result, err := q.Replay(ctx)
+				fields := logrus.Fields{
+					"replayed":  result.Replayed,
+					"inserted":  result.Inserted,
+					"deduped":   result.Deduped,
+					"parked":    result.Parked,
+					"remaining": result.Remaining,
+				}
+				switch {
+				case errors.Is(err, ErrDeadLetterReplayRunning):
+				case err != nil:
+					q.logger.WithFields(fields).WithError(err).Warn("Dead-letter replay stopped early")
+				default:
+					q.logger.WithFields(fields).Info("Dead-letter replay completed")
+				}
+			}
+		}
+	}()
+}
+
+func newDeadLetterKey() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate dead-letter key: %w", err)
+	}
+	return "dlq-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,234 @@
+package logs_services
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+)
+
+// fakeBatchInserter fails while err is set and otherwise stores entries,
+// skipping idempotency keys it has seen like the repository does.
+type fakeBatchInserter struct {
+	err    error
+	stored []*logs_models.LogEntry
+	keys   map[string]bool
+}
+
+func (f *fakeBatchInserter) CreateBatch(ctx context.Context, entries []*logs_models.LogEntry) (logs_db.BatchInsertResult, error) {
+	if f.err != nil {
+		return logs_db.BatchInsertResult{}, f.err
+	}
+	if f.keys == nil {
+		f.keys = make(map[string]bool)
+	}
+	var result logs_db.BatchInsertResult
+	for _, entry := range entries {
+		if entry.IdempotencyKey != "" && f.keys[entry.IdempotencyKey] {
+			result.Deduped++
+			continue
+		}
+		f.keys[entry.IdempotencyKey] = true
+		f.stored = append(f.stored, entry)
+		result.Inserted++
+	}
+	return result, nil
+}
+
+// errConnRefused is what the insert returns while Postgres is down.
+var errConnRefused = fmt.Errorf("db: batch insert failed: %w",
+	&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)})
+
+func newTestDeadLetterQueue(t *testing.T, inserter BatchInserter) (*DeadLetterQueue, *RedisDeadLetterStore) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	store := NewRedisDeadLetterStore(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	return NewDeadLetterQueue(store, inserter, logrus.New()), store
+}
+
+func deadLetterEntries(projectID int64, messages ...string) []*logs_models.LogEntry {
+	entries := make([]*logs_models.LogEntry, 0, len(messages))
+	for _, msg := range messages {
+		entries = append(entries, &logs_models.LogEntry{
+			ProjectID: &projectID,
+			Service:   "external",
+			Level:     "INFO",
+			Message:   msg,
+			Metadata:  []byte(`{"path":"/api/orders"}`),
+			Tags:      []string{},
+			Timestamp: time.Date(2025, 11, 13, 10, 0, 0, 0, time.UTC),
+		})
+	}
+	return entries
+}
+
+func TestDeadLetterQueue_FailedBatchIsReplayedOnRecovery(t *testing.T) {
+	ctx := context.Background()
+	inserter := &fakeBatchInserter{err: errConnRefused}
+	queue, store := newTestDeadLetterQueue(t, inserter)
+
+	// The insert fails, so the handler dead-letters the batch
+	entries := deadLetterEntries(7, "order placed", "payment captured")
+	_, err := inserter.CreateBatch(ctx, entries)
+	require.Error(t, err)
+	require.NoError(t, queue.Add(ctx, entries, err))
+
+	count, err := queue.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// While the database is still down, replay keeps the batch
+	result, err := queue.Replay(ctx)
+	require.Error(t, err)
+	assert.Equal(t, 0, result.Replayed)
+	assert.Equal(t, int64(1), result.Remaining)
+	batch, err := store.Oldest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, batch.Attempts, "the failed attempt is recorded")
+
+	inserter.err = nil
+	result, err = queue.Replay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, DeadLetterReplayResult{Replayed: 1, Inserted: 2, Remaining: 0}, result)
+	require.Len(t, inserter.stored, 2)
+	assert.Equal(t, "order placed", inserter.stored[0].Message)
+	assert.Equal(t, int64(7), *inserter.stored[0].ProjectID)
+	assert.JSONEq(t, `{"path":"/api/orders"}`, string(inserter.stored[0].Metadata))
+}
+
+func TestDeadLetterQueue_ReplayDoesNotDoubleInsert(t *testing.T) {
+	ctx := context.Background()
+	inserter := &fakeBatchInserter{}
+	queue, store := newTestDeadLetterQueue(t, inserter)
+
+	entries := deadLetterEntries(7, "order placed", "payment captured")
+	entries[1].IdempotencyKey = "client-key"
+	require.NoError(t, queue.Add(ctx, entries, errors.New("timeout")))
+	assert.NotEmpty(t, entries[0].IdempotencyKey, "entries without a key get one")
+	assert.Equal(t, "client-key", entries[1].IdempotencyKey, "client keys are kept")
+
+	// A replay that stored the batch but crashed before removing it
+	batch, err := store.Oldest(ctx)
+	require.NoError(t, err)
+	_, err = inserter.CreateBatch(ctx, batch.Entries)
+	require.NoError(t, err)
+
+	result, err := queue.Replay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Replayed)
+	assert.Equal(t, 0, result.Inserted)
+	assert.Equal(t, 2, result.Deduped)
+	assert.Len(t, inserter.stored, 2)
+}
+
+func TestDeadLetterQueue_ReplaysOldestFirst(t *testing.T) {
+	ctx := context.Background()
+	inserter := &fakeBatchInserter{}
+	queue, _ := newTestDeadLetterQueue(t, inserter)
+
+	require.NoError(t, queue.Add(ctx, deadLetterEntries(1, "first"), nil))
+	require.NoError(t, queue.Add(ctx, deadLetterEntries(1, "second"), nil))
+
+	result, err := queue.Replay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Replayed)
+	require.Len(t, inserter.stored, 2)
+	assert.Equal(t, "first", inserter.stored[0].Message)
+	assert.Equal(t, "second", inserter.stored[1].Message)
+}
+
+func TestDeadLetterQueue_ParksBatchesThatCantSucceed(t *testing.T) {
+	ctx := context.Background()
+	inserter := &fakeBatchInserter{}
+	queue, store := newTestDeadLetterQueue(t, inserter)
+	client := store.client
+
+	// A batch that can't be decoded, then one the database rejects for good
+	require.NoError(t, client.LPush(ctx, DefaultDeadLetterKey, "{not json").Err())
+	require.NoError(t, queue.Add(ctx, deadLetterEntries(1, "rejected"), nil))
+	require.NoError(t, queue.Add(ctx, deadLetterEntries(1, "fine"), nil))
+
+	rejecting := &rejectFirstInserter{next: inserter, err: &pq.Error{Code: "23502"}}
+	queue.inserter = rejecting
+	result, err := queue.Replay(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Parked)
+	assert.Equal(t, 1, result.Replayed, "parked batches don't hold up the rest")
+	assert.Zero(t, result.Remaining)
+	require.Len(t, inserter.stored, 1)
+	assert.Equal(t, "fine", inserter.stored[0].Message)
+	parked, err := client.LLen(ctx, DefaultDeadLetterParkedKey).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), parked)
+}
+
+func TestDeadLetterQueue_ParksBatchAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	inserter := &fakeBatchInserter{err: errConnRefused}
+	queue, _ := newTestDeadLetterQueue(t, inserter)
+	queue.SetMaxAttempts(3)
+	require.NoError(t, queue.Add(ctx, deadLetterEntries(1, "stuck"), errConnRefused))
+
+	for i := 0; i < 2; i++ {
+		result, err := queue.Replay(ctx)
+		require.Error(t, err)
+		assert.Equal(t, int64(1), result.Remaining)
+	}
+	result, err := queue.Replay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Parked)
+	assert.Zero(t, result.Remaining)
+}
+
+func TestIsTransientStorageError(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", errConnRefused, true},
+		{"bad connection", fmt.Errorf("db: batch insert failed: %w", driver.ErrBadConn), true},
+		{"timeout", context.DeadlineExceeded, true},
+		{"server shutting down", &pq.Error{Code: "57P01"}, true},
+		{"too many connections", &pq.Error{Code: "53300"}, true},
+		{"not null violation", fmt.Errorf("db: batch insert failed: %w", &pq.Error{Code: "23502"}), false},
+		{"invalid input", &pq.Error{Code: "22P02"}, false},
+		{"other", errors.New("db: failed to apply tag rules"), false},
+		{"nil", nil, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTransientStorageError(tt.err))
+		})
+	}
+}
+
+// rejectFirstInserter fails the first batch it is given with err.
+type rejectFirstInserter struct {
+	next     BatchInserter
+	err      error
+	rejected bool
+}
+
+func (r *rejectFirstInserter) CreateBatch(ctx context.Context, entries []*logs_models.LogEntry) (logs_db.BatchInsertResult, error) {
+	if !r.rejected {
+		r.rejected = true
+		return logs_db.BatchInsertResult{}, r.err
+	}
+	return r.next.CreateBatch(ctx, entries)
+}
@@ -0,0 +1,156 @@
+package logs_services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// QuotaUsage is a project's log quota for the current UTC day.
+type QuotaUsage struct {
+	ResetAt   time.Time `json:"reset_at"`
+	Limit     *int64    `json:"limit"` // nil when the project has no quota
+	Used      int64     `json:"used"`
+	Remaining *int64    `json:"remaining,omitempty"`
+
+	key string // counter a successful Reserve added to, for Release
+}
+
+// QuotaStore keeps per-key counters that expire at a given time.
+type QuotaStore interface {
+	// Reserve adds n to key if the total stays within limit, and returns the
+	// resulting (or, when rejected, unchanged) count.
+	Reserve(ctx context.Context, key string, n, limit int64, expireAt time.Time) (used int64, allowed bool, err error)
+	// Release subtracts n from key, undoing a Reserve.
+	Release(ctx context.Context, key string, n int64) error
+	Get(ctx context.Context, key string) (int64, error)
+}
+
+// LogQuota counts the entries each project ingests per UTC day against the
+// project's daily_log_quota. Counters reset at midnight UTC.
+type LogQuota struct {
+	store QuotaStore
+	now   func() time.Time
+}
+
+// NewLogQuota creates a LogQuota backed by store.
+func NewLogQuota(store QuotaStore) *LogQuota {
+	return &LogQuota{store: store, now: time.Now}
+}
+
+// window returns the counter key for projectID today and when it resets.
+func (q *LogQuota) window(projectID int) (key string, resetAt time.Time) {
+	now := q.now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return fmt.Sprintf("quota:logs:project:%d:%s", projectID, day.Format("2006-01-02")), day.AddDate(0, 0, 1)
+}
+
+// Reserve counts n entries against the project's quota. When they don't
+// fit, nothing is counted and allowed is false. A nil limit always allows.
+func (q *LogQuota) Reserve(ctx context.Context, projectID int, limit *int64, n int) (usage QuotaUsage, allowed bool, err error) {
+	key, resetAt := q.window(projectID)
+	usage = QuotaUsage{Limit: limit, ResetAt: resetAt}
+	if limit == nil {
+		return usage, true, nil
+	}
+
+	// Keep the counter a little past the reset so a late Release still finds it
+	used, allowed, err := q.store.Reserve(ctx, key, int64(n), *limit, resetAt.Add(time.Hour))
+	if err != nil {
+		return usage, false, fmt.Errorf("reserve log quota: %w", err)
+	}
+	usage.Used = used
+	usage.Remaining = remaining(*limit, used)
+	if allowed {
+		usage.key = key
+	}
+	return usage, allowed, nil
+}
+
+// Release gives back n entries counted by the Reserve that returned usage,
+// e.g. for a batch that could not be stored. The entries go back to the day
+// they were reserved on, even if midnight has passed since. It does nothing
+// when that Reserve counted nothing.
+func (q *LogQuota) Release(ctx context.Context, usage QuotaUsage, n int) error {
+	if usage.key == "" {
+		return nil
+	}
+	return q.store.Release(ctx, usage.key, int64(n))
+}
+
+// Usage reports today's usage for a project with the given limit.
+func (q *LogQuota) Usage(ctx context.Context, projectID int, limit *int64) (QuotaUsage, error) {
+	key, resetAt := q.window(projectID)
+	used, err := q.store.Get(ctx, key)
+	if err != nil {
+		return QuotaUsage{}, fmt.Errorf("read log quota: %w", err)
+	}
+	usage := QuotaUsage{Limit: limit, Used: used, ResetAt: resetAt}
+	if limit != nil {
+		usage.Remaining = remaining(*limit, used)
+	}
+	return usage, nil
+}
+
+func remaining(limit, used int64) *int64 {
+	left := limit - used
+	if left < 0 {
+		left = 0
+	}
+	return &left
+}
+
+// reserveScript adds ARGV[1] to the counter unless that would pass the
+// limit in ARGV[2], setting the expiry (unix seconds, ARGV[3]) on first use.
+// Running it as one script keeps check-and-add atomic across replicas.
+var reserveScript = redis.NewScript(`
+local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+local n = tonumber(ARGV[1])
+if used + n > tonumber(ARGV[2]) then
+	return {used, 0}
+end
+used = redis.call('INCRBY', KEYS[1], n)
+if used == n then
+	redis.call('EXPIREAT', KEYS[1], ARGV[3])
+end
+return {used, 1}
+`)
+
+// RedisQuotaStore keeps quota counters in Redis.
+type RedisQuotaStore struct {
+	client *redis.Client
+}
+
+// NewRedisQuotaStore creates a RedisQuotaStore.
+func NewRedisQuotaStore(client *redis.Client) *RedisQuotaStore {
+	return &RedisQuotaStore{client: client}
+}
+
+// Reserve implements QuotaStore.
+func (s *RedisQuotaStore) Reserve(ctx context.Context, key string, n, limit int64, expireAt time.Time) (int64, bool, error) {
+	res, err := reserveScript.Run(ctx, s.client, []string{key}, n, limit, expireAt.Unix()).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	if len(res) != 2 {
+		return 0, false, fmt.Errorf("unexpected quota script result %v", res)
+	}
+	return res[0], res[1] == 1, nil
+}
+
+// Release implements QuotaStore.
+func (s *RedisQuotaStore) Release(ctx context.Context, key string, n int64) error {
+	return s.client.DecrBy(ctx, key, n).Err()
+}
+
+// Get implements QuotaStore.
+func (s *RedisQuotaStore) Get(ctx context.Context, key string) (int64, error) {
+	used, err := s.client.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return used, err
+}
@@ -0,0 +1,130 @@
+package logs_services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogQuota(t *testing.T, now time.Time) (*LogQuota, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	mr.SetTime(now) // Counters expire by the same clock
+	quota := NewLogQuota(NewRedisQuotaStore(redis.NewClient(&redis.Options{Addr: mr.Addr()})))
+	quota.now = func() time.Time { return now }
+	return quota, mr
+}
+
+func TestLogQuota_BlocksAfterQuotaAndResetsAtMidnight(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 11, 13, 23, 30, 0, 0, time.UTC)
+	quota, _ := newTestLogQuota(t, now)
+	limit := int64(100)
+
+	usage, allowed, err := quota.Reserve(ctx, 7, &limit, 60)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, int64(60), usage.Used)
+	assert.Equal(t, int64(40), *usage.Remaining)
+	assert.Equal(t, time.Date(2025, 11, 14, 0, 0, 0, 0, time.UTC), usage.ResetAt)
+
+	// A batch that doesn't fit is rejected whole and not counted
+	usage, allowed, err = quota.Reserve(ctx, 7, &limit, 41)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, int64(60), usage.Used)
+
+	_, allowed, err = quota.Reserve(ctx, 7, &limit, 40)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	_, allowed, err = quota.Reserve(ctx, 7, &limit, 1)
+	require.NoError(t, err)
+	assert.False(t, allowed, "quota is used up")
+
+	// Other projects have their own counters
+	_, allowed, err = quota.Reserve(ctx, 8, &limit, 1)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// After midnight UTC the count starts again
+	quota.now = func() time.Time { return now.Add(time.Hour) }
+	usage, allowed, err = quota.Reserve(ctx, 7, &limit, 1)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, int64(1), usage.Used)
+}
+
+func TestLogQuota_ReserveIsAtomic(t *testing.T) {
+	quota, _ := newTestLogQuota(t, time.Now())
+	limit := int64(50)
+
+	var accepted atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, allowed, err := quota.Reserve(context.Background(), 7, &limit, 1)
+			assert.NoError(t, err)
+			if allowed {
+				accepted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(50), accepted.Load())
+	usage, err := quota.Usage(context.Background(), 7, &limit)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), usage.Used)
+	assert.Equal(t, int64(0), *usage.Remaining)
+}
+
+func TestLogQuota_ReleaseAndUnlimited(t *testing.T) {
+	ctx := context.Background()
+	quota, mr := newTestLogQuota(t, time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC))
+	limit := int64(10)
+
+	reserved, _, err := quota.Reserve(ctx, 7, &limit, 10)
+	require.NoError(t, err)
+	require.NoError(t, quota.Release(ctx, reserved, 4))
+	usage, err := quota.Usage(ctx, 7, &limit)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), usage.Used)
+	assert.True(t, mr.TTL("quota:logs:project:7:2025-11-13") > 0, "counters expire")
+
+	usage, allowed, err := quota.Reserve(ctx, 9, nil, 1000)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Nil(t, usage.Limit)
+	assert.False(t, mr.Exists("quota:logs:project:9:2025-11-13"), "projects without a quota aren't counted")
+	require.NoError(t, quota.Release(ctx, usage, 1000))
+	assert.False(t, mr.Exists("quota:logs:project:9:2025-11-13"), "releasing an uncounted batch is a no-op")
+}
+
+func TestLogQuota_ReleaseAfterMidnightReturnsToReservedDay(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 11, 13, 23, 59, 59, 0, time.UTC)
+	quota, mr := newTestLogQuota(t, now)
+	limit := int64(10)
+
+	reserved, allowed, err := quota.Reserve(ctx, 7, &limit, 6)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	// The insert fails after the day has rolled over
+	quota.now = func() time.Time { return now.Add(2 * time.Second) }
+	require.NoError(t, quota.Release(ctx, reserved, 6))
+
+	used, err := mr.Get("quota:logs:project:7:2025-11-13")
+	require.NoError(t, err)
+	assert.Equal(t, "0", used)
+	assert.False(t, mr.Exists("quota:logs:project:7:2025-11-14"), "the new day's counter is untouched")
+}
@@ -19,9 +19,9 @@ func NewLogRepositoryAdapter(repo *logs_db.LogRepository) LogRepository {
 }
 
 // GetByID adapts the repository GetByID method
-func (a *LogRepositoryAdapter) GetByID(ctx context.Context, id int64) (*logs_models.LogEntry, error) {
+func (a *LogRepositoryAdapter) GetByID(ctx context.Context, id int64, ownerUserID int) (*logs_models.LogEntry, error) {
 	// Call the original repository
-	dbEntry, err := a.repo.GetByID(ctx, id)
+	dbEntry, err := a.repo.GetByID(ctx, id, ownerUserID)
 	if err != nil {
 		return nil, err
 	}
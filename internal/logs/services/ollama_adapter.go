@@ -33,6 +33,9 @@ func (a *OllamaAdapter) Generate(ctx context.Context, request *AIRequest) (*AIRe
 
 	// Convert ai.Response to AIResponse
 	return &AIResponse{
-		Content: aiResp.Content,
+		Content:      aiResp.Content,
+		InputTokens:  aiResp.InputTokens,
+		OutputTokens: aiResp.OutputTokens,
+		CostUSD:      aiResp.CostUSD,
 	}, nil
 }
@@ -2,60 +2,118 @@
 package logs_services
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
 	"regexp"
 	"strings"
+	"sync"
 )
 
-// PatternMatcher classifies log messages based on error patterns
+// UnknownIssueType is the issue type of messages no pattern matches.
+const UnknownIssueType = "unknown"
+
+// IssueRule maps log messages matching Pattern (a Go regular expression) to
+// IssueType. It is the format of the rules file read by LoadRulesFile.
+type IssueRule struct {
+	IssueType string `json:"issue_type"`
+	Pattern   string `json:"pattern"`
+}
+
+// PatternMatcher classifies log messages based on error patterns.
+// It is cheap enough to run on every entry at ingestion; the AI analysis
+// can refine the issue type later.
 type PatternMatcher struct {
 	patterns map[string]*regexp.Regexp
+	order    []string // Issue types in the order they are checked
+	mu       sync.RWMutex
 }
 
 // NewPatternMatcher creates a new pattern matcher with predefined patterns
 func NewPatternMatcher() *PatternMatcher {
-	return &PatternMatcher{
-		patterns: map[string]*regexp.Regexp{
-			"db_connection":   regexp.MustCompile(`(?i)(connection refused|database.*timeout|pg.*connect|pq.*connection)`),
-			"auth_failure":    regexp.MustCompile(`(?i)(unauthorized|authentication.*failed|invalid.*token|JWT.*validation)`),
-			"null_pointer":    regexp.MustCompile(`(?i)(nil pointer|null reference|undefined|panic.*nil pointer)`),
-			"rate_limit":      regexp.MustCompile(`(?i)(rate limit|too many requests|429|API.*rate)`),
-			"network_timeout": regexp.MustCompile(`(?i)(timeout|i/o timeout|context deadline|request timeout)`),
-		},
-	}
+	p := &PatternMatcher{patterns: map[string]*regexp.Regexp{}}
+	// Order matters - more specific patterns should come first
+	p.AddPattern("db_connection", regexp.MustCompile(`(?i)(connection refused|database.*timeout|pg.*connect|pq.*connection)`))
+	p.AddPattern("auth_failure", regexp.MustCompile(`(?i)(unauthorized|authentication.*failed|invalid.*token|JWT.*validation)`))
+	p.AddPattern("null_pointer", regexp.MustCompile(`(?i)(nil pointer|null reference|undefined|panic.*nil pointer)`))
+	p.AddPattern("rate_limit", regexp.MustCompile(`(?i)(rate limit|too many requests|429|API.*rate)`))
+	p.AddPattern("network_timeout", regexp.MustCompile(`(?i)(timeout|i/o timeout|context deadline|request timeout)`))
+	return p
 }
 
-// Classify determines the issue type based on the log message
+// Classify determines the issue type based on the log message.
+// Patterns are checked in order and the first match wins; messages matching
+// none are UnknownIssueType.
 func (p *PatternMatcher) Classify(logMsg string) string {
 	// Normalize the message for matching
 	normalizedMsg := strings.TrimSpace(logMsg)
 
-	// Check patterns in order (first match wins)
-	// Order matters - more specific patterns should come first
-	checkOrder := []string{
-		"db_connection",
-		"auth_failure",
-		"null_pointer",
-		"rate_limit",
-		"network_timeout",
-	}
-
-	for _, issueType := range checkOrder {
-		if pattern, exists := p.patterns[issueType]; exists {
-			if pattern.MatchString(normalizedMsg) {
-				return issueType
-			}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, issueType := range p.order {
+		if p.patterns[issueType].MatchString(normalizedMsg) {
+			return issueType
 		}
 	}
 
-	return "unknown"
+	return UnknownIssueType
 }
 
-// AddPattern adds a new pattern to the matcher
+// AddPattern adds a new pattern to the matcher. A new issue type is checked
+// after the existing ones; an existing one keeps its place with the new pattern.
 func (p *PatternMatcher) AddPattern(issueType string, pattern *regexp.Regexp) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.patterns[issueType]; !exists {
+		p.order = append(p.order, issueType)
+	}
 	p.patterns[issueType] = pattern
 }
 
+// LoadRules adds the JSON array of IssueRule read from r, in order. Nothing
+// is added if any rule is invalid.
+func (p *PatternMatcher) LoadRules(r io.Reader) error {
+	var rules []IssueRule
+	if err := json.NewDecoder(r).Decode(&rules); err != nil {
+		return fmt.Errorf("failed to decode issue rules: %w", err)
+	}
+
+	compiled := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.IssueType) == "" || rule.IssueType == UnknownIssueType {
+			return fmt.Errorf("issue rule %d: issue_type must be set and not %q", i, UnknownIssueType)
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil || rule.Pattern == "" {
+			return fmt.Errorf("issue rule %d (%s): invalid pattern %q", i, rule.IssueType, rule.Pattern)
+		}
+		compiled[i] = pattern
+	}
+
+	for i, rule := range rules {
+		p.AddPattern(rule.IssueType, compiled[i])
+	}
+	return nil
+}
+
+// LoadRulesFile adds the rules in the JSON file at path; see LoadRules.
+func (p *PatternMatcher) LoadRulesFile(path string) error {
+	f, err := os.Open(path) //nolint:gosec // path comes from operator configuration
+	if err != nil {
+		return fmt.Errorf("failed to open issue rules: %w", err)
+	}
+	defer f.Close()
+	return p.LoadRules(f)
+}
+
 // GetPatterns returns all configured patterns
 func (p *PatternMatcher) GetPatterns() map[string]*regexp.Regexp {
-	return p.patterns
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	patterns := make(map[string]*regexp.Regexp, len(p.patterns))
+	for issueType, pattern := range p.patterns {
+		patterns[issueType] = pattern
+	}
+	return patterns
 }
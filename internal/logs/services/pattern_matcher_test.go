@@ -1,9 +1,12 @@
 package logs_services
 
 import (
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestNewPatternMatcher tests pattern matcher creation
@@ -164,3 +167,84 @@ func TestClassify_FirstMatchWins(t *testing.T) {
 	// Should match db_connection since it's checked first
 	assert.Equal(t, "db_connection", result)
 }
+
+// TestClassify_RepresentativeMessages tests real-world messages seen at ingestion
+func TestClassify_RepresentativeMessages(t *testing.T) {
+	matcher := NewPatternMatcher()
+
+	testCases := []struct {
+		message  string
+		expected string
+	}{
+		{`dial tcp 10.0.0.5:5432: connect: connection refused`, "db_connection"},
+		{`pq: connection to server at "postgres" failed: FATAL: too many clients`, "db_connection"},
+		{"401 Unauthorized: session expired", "auth_failure"},
+		{"JWT validation error: token is expired", "auth_failure"},
+		{"panic: runtime error: invalid memory address or nil pointer dereference", "null_pointer"},
+		{"TypeError: Cannot read properties of undefined (reading 'id')", "null_pointer"},
+		{"upstream returned 429, retrying in 2s", "rate_limit"},
+		{"GitHub API rate limit exceeded for installation", "rate_limit"},
+		{"Get \"http://review:8081/health\": context deadline exceeded", "network_timeout"},
+		{"read tcp 172.18.0.4:41234->172.18.0.9:11434: i/o timeout", "network_timeout"},
+		{"user 42 updated their profile", UnknownIssueType},
+		{"", UnknownIssueType},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			assert.Equal(t, tc.expected, matcher.Classify(tc.message))
+		})
+	}
+}
+
+// TestAddPattern_NewIssueTypeIsMatched tests that added patterns are checked after the built-in ones
+func TestAddPattern_NewIssueTypeIsMatched(t *testing.T) {
+	matcher := NewPatternMatcher()
+	matcher.AddPattern("disk_full", regexp.MustCompile(`(?i)no space left on device`))
+
+	assert.Equal(t, "disk_full", matcher.Classify("write /data/wal: no space left on device"))
+	assert.Equal(t, "db_connection", matcher.Classify("connection refused; no space left on device"), "built-in rules come first")
+	assert.Contains(t, matcher.GetPatterns(), "disk_full")
+}
+
+// TestLoadRules tests extending and overriding the built-in rules from JSON
+func TestLoadRules(t *testing.T) {
+	matcher := NewPatternMatcher()
+
+	err := matcher.LoadRules(strings.NewReader(`[
+		{"issue_type": "disk_full", "pattern": "(?i)no space left on device"},
+		{"issue_type": "rate_limit", "pattern": "(?i)throttled"}
+	]`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "disk_full", matcher.Classify("No space left on device"))
+	assert.Equal(t, "rate_limit", matcher.Classify("request throttled by upstream"))
+	assert.Equal(t, UnknownIssueType, matcher.Classify("too many requests"), "a loaded rule replaces the built-in pattern")
+	assert.Equal(t, "db_connection", matcher.Classify("connection refused"))
+}
+
+// TestLoadRules_Invalid tests that a bad rules file adds nothing
+func TestLoadRules_Invalid(t *testing.T) {
+	for name, rules := range map[string]string{
+		"not json":        `issue_type=disk_full`,
+		"bad pattern":     `[{"issue_type": "disk_full", "pattern": "(unclosed"}]`,
+		"empty pattern":   `[{"issue_type": "disk_full", "pattern": ""}]`,
+		"no issue type":   `[{"pattern": "disk"}]`,
+		"unknown as type": `[{"issue_type": "unknown", "pattern": "disk"}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			matcher := NewPatternMatcher()
+			err := matcher.LoadRules(strings.NewReader(rules))
+			assert.Error(t, err)
+			assert.Len(t, matcher.GetPatterns(), 5)
+		})
+	}
+
+	matcher := NewPatternMatcher()
+	err := matcher.LoadRules(strings.NewReader(`[
+		{"issue_type": "disk_full", "pattern": "disk"},
+		{"issue_type": "oom", "pattern": "(bad"}
+	]`))
+	assert.Error(t, err)
+	assert.Equal(t, UnknownIssueType, matcher.Classify("disk"), "valid rules before a bad one are not added")
+}
@@ -13,18 +13,34 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
 	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
 )
 
+// Key rotation grace periods for RotateAPIKey
+const (
+	DefaultKeyRotationGrace = 24 * time.Hour
+	MaxKeyRotationGrace     = 30 * 24 * time.Hour
+)
+
+// Key rotation errors
+var (
+	ErrProjectNotFound = errors.New("project not found")
+	ErrInvalidRotation = errors.New("invalid key rotation")
+)
+
 // ProjectService handles project management operations
 type ProjectService struct {
-	repo ProjectRepository
+	repo          ProjectRepository
+	now           func() time.Time
+	rotationGrace time.Duration
 }
 
 // ProjectRepository defines the interface for project data access
@@ -38,12 +54,26 @@ type ProjectRepository interface {
 	ListByUserID(ctx context.Context, userID int) ([]logs_models.Project, error)
 	Update(ctx context.Context, project *logs_models.Project) error
 	UpdateAPIToken(ctx context.Context, projectID int, newAPIToken string) error
+	RotateAPIToken(ctx context.Context, projectID int, newHash string, previousExpiresAt time.Time) error
 	Delete(ctx context.Context, id int) error
 }
 
 // NewProjectService creates a new project service
 func NewProjectService(repo ProjectRepository) *ProjectService {
-	return &ProjectService{repo: repo}
+	return &ProjectService{
+		repo:          repo,
+		now:           time.Now,
+		rotationGrace: DefaultKeyRotationGrace,
+	}
+}
+
+// SetKeyRotationGrace sets how long RotateAPIKey keeps the old key working
+// when the request doesn't say. Values outside (0, MaxKeyRotationGrace] are
+// ignored.
+func (s *ProjectService) SetKeyRotationGrace(grace time.Duration) {
+	if grace > 0 && grace <= MaxKeyRotationGrace {
+		s.rotationGrace = grace
+	}
 }
 
 // GenerateAPIKey generates a new API key and returns both the plain key and bcrypt hash
@@ -124,8 +154,10 @@ func (s *ProjectService) ValidateAPIKeyForSlug(ctx context.Context, slug, apiKey
 		return nil, fmt.Errorf("project is inactive")
 	}
 
-	// Validate API key against stored hash using bcrypt
-	if !ValidateAPIKey(apiKey, project.APIKeyHash) {
+	// Validate API key against stored hash using bcrypt; a rotated-out key
+	// is accepted until its grace period ends
+	if !ValidateAPIKey(apiKey, project.APIKeyHash) &&
+		(!project.PreviousKeyActive(s.now()) || !ValidateAPIKey(apiKey, project.PreviousAPIKeyHash)) {
 		return nil, fmt.Errorf("invalid API key")
 	}
 
@@ -222,6 +254,33 @@ func (s *ProjectService) UpdateProject(ctx context.Context, projectID int, req *
 	if req.IsActive != nil {
 		project.IsActive = *req.IsActive
 	}
+	if req.MinIngestLevel != nil {
+		level := strings.ToUpper(strings.TrimSpace(*req.MinIngestLevel))
+		if level == "" {
+			project.MinIngestLevel = nil
+		} else if _, ok := logs_models.LevelRank(level); ok {
+			project.MinIngestLevel = &level
+		} else {
+			return nil, fmt.Errorf("invalid min_ingest_level %q: must be one of DEBUG, INFO, WARN, ERROR, FATAL", *req.MinIngestLevel)
+		}
+	}
+	if req.DailyLogQuota != nil {
+		switch quota := *req.DailyLogQuota; {
+		case quota == 0:
+			project.DailyLogQuota = nil
+		case quota > 0:
+			project.DailyLogQuota = &quota
+		default:
+			return nil, fmt.Errorf("invalid daily_log_quota %d: must be positive, or 0 for no quota", quota)
+		}
+	}
+	if req.SampleRates != nil {
+		rates, err := req.SampleRates.Normalize()
+		if err != nil {
+			return nil, err
+		}
+		project.SampleRates = rates
+	}
 
 	// Save changes
 	if err := s.repo.Update(ctx, project); err != nil {
@@ -256,6 +315,46 @@ func (s *ProjectService) RegenerateAPIKey(ctx context.Context, projectID int) (*
 	}, nil
 }
 
+// RotateAPIKey issues a new API key for one of the user's projects while the
+// current key keeps working for grace, so clients can switch without
+// downtime. A nil grace uses the configured default; zero expires the old
+// key immediately. It fails with ErrProjectNotFound when the project doesn't
+// exist or isn't the user's, and ErrInvalidRotation for a grace outside
+// [0, MaxKeyRotationGrace].
+func (s *ProjectService) RotateAPIKey(ctx context.Context, projectID, userID int, grace *time.Duration) (*logs_models.RotateKeyResponse, error) {
+	window := s.rotationGrace
+	if grace != nil {
+		window = *grace
+	}
+	if window < 0 || window > MaxKeyRotationGrace {
+		return nil, fmt.Errorf("%w: grace period must be between 0 and %s", ErrInvalidRotation, MaxKeyRotationGrace)
+	}
+
+	project, err := s.repo.GetByID(ctx, projectID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("look up project: %w", err)
+	}
+	if project == nil {
+		return nil, ErrProjectNotFound
+	}
+
+	plainKey, hash, err := GenerateAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	expiresAt := s.now().Add(window)
+	if err := s.repo.RotateAPIToken(ctx, project.ID, hash, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	return &logs_models.RotateKeyResponse{
+		APIKey:               plainKey,
+		PreviousKeyExpiresAt: expiresAt,
+		Message:              "API key rotated. The previous key keeps working until previous_key_expires_at; update your applications before then.",
+	}, nil
+}
+
 // DeactivateProject soft-deletes a project
 func (s *ProjectService) DeactivateProject(ctx context.Context, projectID int) error {
 	project, err := s.repo.GetByIDGlobal(ctx, projectID)
@@ -0,0 +1,138 @@
+package logs_services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProjectRepo stores projects by id; only the methods key rotation and
+// API key validation use do anything.
+type fakeProjectRepo struct {
+	projects map[int]*logs_models.Project
+}
+
+func (f *fakeProjectRepo) Create(ctx context.Context, project *logs_models.Project) (*logs_models.Project, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeProjectRepo) GetByID(ctx context.Context, id int, userID int) (*logs_models.Project, error) {
+	if p, ok := f.projects[id]; ok && p.UserID != nil && *p.UserID == userID {
+		copied := *p
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeProjectRepo) GetByIDGlobal(ctx context.Context, id int) (*logs_models.Project, error) {
+	return f.projects[id], nil
+}
+
+func (f *fakeProjectRepo) GetBySlug(ctx context.Context, slug string, userID int) (*logs_models.Project, error) {
+	return nil, nil
+}
+
+func (f *fakeProjectRepo) GetBySlugGlobal(ctx context.Context, slug string) (*logs_models.Project, error) {
+	for _, p := range f.projects {
+		if p.Slug == slug {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("project not found")
+}
+
+func (f *fakeProjectRepo) FindByAPIToken(ctx context.Context, token string) (*logs_models.Project, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeProjectRepo) ListByUserID(ctx context.Context, userID int) ([]logs_models.Project, error) {
+	return nil, nil
+}
+
+func (f *fakeProjectRepo) Update(ctx context.Context, project *logs_models.Project) error {
+	return nil
+}
+
+func (f *fakeProjectRepo) UpdateAPIToken(ctx context.Context, projectID int, newAPIToken string) error {
+	return nil
+}
+
+func (f *fakeProjectRepo) RotateAPIToken(ctx context.Context, projectID int, newHash string, previousExpiresAt time.Time) error {
+	p := f.projects[projectID]
+	p.PreviousAPIKeyHash = p.APIKeyHash
+	p.PreviousAPIKeyExpiresAt = &previousExpiresAt
+	p.APIKeyHash = newHash
+	return nil
+}
+
+func (f *fakeProjectRepo) Delete(ctx context.Context, id int) error {
+	return nil
+}
+
+func TestProjectService_RotateAPIKey_BothKeysWorkDuringGrace(t *testing.T) {
+	oldKey, oldHash, err := GenerateAPIKey()
+	require.NoError(t, err)
+	owner := 7
+	repo := &fakeProjectRepo{projects: map[int]*logs_models.Project{
+		3: {ID: 3, UserID: &owner, Slug: "orders-api", APIKeyHash: oldHash, IsActive: true},
+	}}
+	now := time.Date(2025, 11, 26, 9, 0, 0, 0, time.UTC)
+	svc := NewProjectService(repo)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	grace := 2 * time.Hour
+	resp, err := svc.RotateAPIKey(ctx, 3, 7, &grace)
+	require.NoError(t, err)
+	assert.NotEqual(t, oldKey, resp.APIKey)
+	assert.Equal(t, now.Add(grace), resp.PreviousKeyExpiresAt)
+
+	// Within the window both keys authenticate
+	now = now.Add(time.Hour)
+	_, err = svc.ValidateAPIKeyForSlug(ctx, "orders-api", resp.APIKey)
+	assert.NoError(t, err, "new key")
+	_, err = svc.ValidateAPIKeyForSlug(ctx, "orders-api", oldKey)
+	assert.NoError(t, err, "old key during the grace period")
+
+	// Afterwards only the new one does
+	now = now.Add(time.Hour)
+	_, err = svc.ValidateAPIKeyForSlug(ctx, "orders-api", resp.APIKey)
+	assert.NoError(t, err)
+	_, err = svc.ValidateAPIKeyForSlug(ctx, "orders-api", oldKey)
+	assert.Error(t, err, "old key after the grace period")
+}
+
+func TestProjectService_RotateAPIKey_Guards(t *testing.T) {
+	_, hash, err := GenerateAPIKey()
+	require.NoError(t, err)
+	owner := 7
+	repo := &fakeProjectRepo{projects: map[int]*logs_models.Project{
+		3: {ID: 3, UserID: &owner, APIKeyHash: hash, IsActive: true},
+	}}
+	svc := NewProjectService(repo)
+	ctx := context.Background()
+
+	_, err = svc.RotateAPIKey(ctx, 3, 8, nil)
+	assert.ErrorIs(t, err, ErrProjectNotFound, "another user's project")
+	_, err = svc.RotateAPIKey(ctx, 4, 7, nil)
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+
+	tooLong := MaxKeyRotationGrace + time.Hour
+	_, err = svc.RotateAPIKey(ctx, 3, 7, &tooLong)
+	assert.ErrorIs(t, err, ErrInvalidRotation)
+	negative := -time.Hour
+	_, err = svc.RotateAPIKey(ctx, 3, 7, &negative)
+	assert.ErrorIs(t, err, ErrInvalidRotation)
+
+	svc.SetKeyRotationGrace(6 * time.Hour)
+	before := time.Now()
+	resp, err := svc.RotateAPIKey(ctx, 3, 7, nil)
+	require.NoError(t, err)
+	assert.WithinDuration(t, before.Add(6*time.Hour), resp.PreviousKeyExpiresAt, time.Minute, "the default grace period")
+}
@@ -3,13 +3,18 @@ package logs_services
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_metrics "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/metrics"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,17 +25,172 @@ const (
 	MaxTotalSize    = 15 * 1024 * 1024 // 15MB max total entry size
 )
 
+// ExportFlushEvery is how many NDJSON records Export writes between flushes.
+const ExportFlushEvery = 500
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// ErrLogNotFound is returned when a requested log entry does not exist.
+var ErrLogNotFound = errors.New("log entry not found")
+
+// ErrInvalidTimeSeries is returned when a time series query is malformed.
+var ErrInvalidTimeSeries = errors.New("invalid time series query")
+
+// Time series limits for TimeSeries
+const (
+	DefaultTimeSeriesInterval = "hour"
+	DefaultTimeSeriesRange    = 24 * time.Hour
+	MaxTimeSeriesBuckets      = 1000
+)
+
+// timeSeriesIntervals are the supported bucket sizes.
+var timeSeriesIntervals = map[string]time.Duration{
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+}
+
+// ErrInvalidFingerprintQuery is returned when a fingerprint query is malformed.
+var ErrInvalidFingerprintQuery = errors.New("invalid fingerprint query")
+
+// Fingerprint group limits for Fingerprints
+const (
+	DefaultFingerprintLimit = 50
+	MaxFingerprintLimit     = 500
+	DefaultFingerprintRange = 24 * time.Hour
+)
+
+// Delete errors
+var (
+	ErrInvalidDelete    = errors.New("invalid delete request")
+	ErrUnfilteredDelete = errors.New("refusing to delete all of the project's logs without confirm=all")
+	ErrDeleteForbidden  = errors.New("project not found")
+	ErrNothingToRestore = errors.New("no deleted logs to restore")
+)
+
+// DefaultTrashGracePeriod is how long soft-deleted entries can be restored
+// before the purge job removes them.
+const DefaultTrashGracePeriod = 7 * 24 * time.Hour
+
+// deleteFilterKeys are the filters Delete accepts.
+var deleteFilterKeys = map[string]bool{
+	"service":        true,
+	"level":          true,
+	"search":         true,
+	"q":              true,
+	"correlation_id": true,
+	"from":           true,
+	"to":             true,
+}
+
+// DeleteLogsRequest selects the entries Delete removes from one project.
+type DeleteLogsRequest struct {
+	Filters    map[string]interface{} // service, level, search, q, correlation_id, from, to
+	ProjectID  int
+	UserID     int  // Must own the project
+	ConfirmAll bool // Allows a delete with no filter besides the project
+	DryRun     bool // Count the matching entries without deleting them
+}
+
+// CallerFilterKey is the filters key holding the authenticated caller's user
+// ID (an int). Query, QueryCursor, FuzzyQuery and Export then only return
+// entries of projects the caller owns, unless the caller is an admin.
+const CallerFilterKey = "user_id"
+
+// Admins is the set of users whose log reads span every project.
+type Admins map[int]bool
+
+// NewAdmins returns the Admins holding userIDs.
+func NewAdmins(userIDs []int) Admins {
+	admins := make(Admins, len(userIDs))
+	for _, id := range userIDs {
+		admins[id] = true
+	}
+	return admins
+}
+
+// Contains reports whether userID is an admin
+func (a Admins) Contains(userID int) bool {
+	return userID != 0 && a[userID]
+}
+
+// OwnerScope returns the owner filter for reads by userID: userID itself,
+// or 0 (every project) for admins and internal calls without a user.
+func (a Admins) OwnerScope(userID int) int {
+	if userID == 0 || a[userID] {
+		return 0
+	}
+	return userID
+}
+
+// ProjectLookup finds one of a user's projects; ProjectRepository implements it.
+type ProjectLookup interface {
+	GetByID(ctx context.Context, id int, userID int) (*logs_models.Project, error)
+}
+
+// Neighbor limits for GetContext
+const (
+	DefaultContextNeighbors = 10
+	MaxContextNeighbors     = 100
+)
+
+// Fuzzy search fallback limits
+const (
+	MaxFuzzyResults     = 25 // Cap on similarity matches returned by the fallback
+	MaxFuzzySuggestions = 5  // Cap on "did you mean" suggestions
+)
+
 // RestLogService implements REST API operations for logs.
 type RestLogService struct {
-	repo   *logs_db.LogRepository
-	logger *logrus.Logger
+	repo          *logs_db.LogRepository
+	logger        *logrus.Logger
+	metrics       *logs_metrics.IngestMetrics
+	projects      ProjectLookup
+	admins        Admins
+	trashGrace    time.Duration
+	fuzzyFallback bool
 }
 
 // NewRestLogService creates a new RestLogService.
+// The fuzzy search fallback is enabled by default.
 func NewRestLogService(repo *logs_db.LogRepository, logger *logrus.Logger) *RestLogService {
 	return &RestLogService{
-		repo:   repo,
-		logger: logger,
+		repo:          repo,
+		logger:        logger,
+		trashGrace:    DefaultTrashGracePeriod,
+		fuzzyFallback: true,
+	}
+}
+
+// SetFuzzyFallback enables or disables the trigram similarity fallback used
+// when a message search returns no results.
+func (s *RestLogService) SetFuzzyFallback(enabled bool) {
+	s.fuzzyFallback = enabled
+}
+
+// SetMetrics enables Prometheus ingestion metrics for Insert.
+func (s *RestLogService) SetMetrics(m *logs_metrics.IngestMetrics) {
+	s.metrics = m
+}
+
+// SetProjects enables Delete, Trash and Restore, which check project
+// ownership with p.
+func (s *RestLogService) SetProjects(p ProjectLookup) {
+	s.projects = p
+}
+
+// SetAdmins sets the users whose log reads are not limited to the projects
+// they own.
+func (s *RestLogService) SetAdmins(userIDs []int) {
+	s.admins = NewAdmins(userIDs)
+}
+
+// SetTrashGracePeriod sets how long deleted entries stay restorable; it
+// should match the purge job's grace period. Non-positive values are ignored.
+func (s *RestLogService) SetTrashGracePeriod(grace time.Duration) {
+	if grace > 0 {
+		s.trashGrace = grace
 	}
 }
 
@@ -91,10 +251,13 @@ func (s *RestLogService) Insert(ctx context.Context, entry map[string]interface{
 		CreatedAt: time.Now(),
 	}
 
+	saveStart := time.Now()
 	id, err := s.repo.Save(ctx, logEntry)
+	s.metrics.ObserveDuration(time.Since(saveStart))
 	if err != nil {
 		return 0, fmt.Errorf("insert failed: %w", err)
 	}
+	s.metrics.IncIngested(logs_metrics.UnassignedProject, logEntry.Level, 1)
 
 	return id, nil
 }
@@ -118,19 +281,16 @@ func (s *RestLogService) Query(
 		offset = o
 	}
 
-	queryFilters := &logs_db.QueryFilters{
-		Service: extractString(filters, "service"),
-		Level:   extractString(filters, "level"),
-		Search:  extractString(filters, "search"),
-		From:    parseTime(extractString(filters, "from")),
-		To:      parseTime(extractString(filters, "to")),
-	}
-
 	pageOpts := logs_db.PageOptions{
 		Limit:  limit,
 		Offset: offset,
 	}
 
+	queryFilters, err := s.scopedQueryFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
 	entries, err := s.repo.Query(ctx, queryFilters, pageOpts)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
@@ -138,19 +298,191 @@ func (s *RestLogService) Query(
 
 	result := make([]interface{}, len(entries))
 	for i, entry := range entries {
-		result[i] = mapLogEntryToInterface(entry)
+		mapped := mapLogEntryToInterface(entry)
+		if queryFilters.FullText != "" {
+			mapped["score"] = entry.Score
+		}
+		result[i] = mapped
 	}
 
 	return result, nil
 }
 
-// GetByID retrieves a single log entry by ID.
-func (s *RestLogService) GetByID(ctx context.Context, id int64) (interface{}, error) {
+// QueryCursor retrieves logs using keyset pagination. after is the opaque
+// cursor from a previous page (empty for the first page). nextCursor is nil
+// once there are no more entries.
+func (s *RestLogService) QueryCursor(
+	ctx context.Context,
+	filters map[string]interface{},
+	limit int,
+	after string,
+) (entries []interface{}, nextCursor *string, err error) {
+	if s.repo == nil {
+		return nil, nil, errors.New("repository not configured")
+	}
+
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	pageOpts := logs_db.PageOptions{Limit: limit + 1} // one extra row tells us if another page exists
+	if after != "" {
+		cursor, decodeErr := logs_db.DecodeCursor(after)
+		if decodeErr != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidCursor, decodeErr)
+		}
+		pageOpts.After = cursor
+	}
+
+	queryFilters, err := s.scopedQueryFilters(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := s.repo.Query(ctx, queryFilters, pageOpts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query failed: %w", err)
+	}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		token := logs_db.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+		nextCursor = &token
+	}
+
+	entries = make([]interface{}, len(rows))
+	for i, entry := range rows {
+		mapped := mapLogEntryToInterface(entry)
+		if queryFilters.FullText != "" {
+			mapped["score"] = entry.Score
+		}
+		entries[i] = mapped
+	}
+
+	return entries, nextCursor, nil
+}
+
+// FuzzyQuery runs the similarity fallback for a message search that found
+// nothing. Matches are flagged with "fuzzy": true and a "similarity" score,
+// and suggestions holds "did you mean" tokens from common log messages.
+// Both are empty when the fallback is disabled or no search term was given.
+func (s *RestLogService) FuzzyQuery(
+	ctx context.Context,
+	filters map[string]interface{},
+	limit int,
+) (entries []interface{}, suggestions []string, err error) {
+	if s.repo == nil {
+		return nil, nil, errors.New("repository not configured")
+	}
+
+	search := extractString(filters, "search")
+	if !s.fuzzyFallback || search == "" {
+		return []interface{}{}, []string{}, nil
+	}
+
+	if limit <= 0 || limit > MaxFuzzyResults {
+		limit = MaxFuzzyResults
+	}
+
+	queryFilters, err := s.scopedQueryFilters(filters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matches, err := s.repo.FuzzySearch(ctx, queryFilters, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fuzzy search failed: %w", err)
+	}
+
+	entries = make([]interface{}, len(matches))
+	for i, match := range matches {
+		mapped := mapLogEntryToInterface(match.Entry)
+		mapped["fuzzy"] = true
+		mapped["similarity"] = match.Similarity
+		entries[i] = mapped
+	}
+
+	suggestions, err = s.repo.SuggestTerms(ctx, search, queryFilters.OwnerUserID, MaxFuzzySuggestions)
+	if err != nil {
+		// Suggestions are a nicety; fuzzy matches are still useful without them
+		s.logger.WithError(err).Warn("Failed to build search suggestions")
+		suggestions = []string{}
+	}
+
+	return entries, suggestions, nil
+}
+
+// Flusher is implemented by writers that buffer output, such as
+// gin.ResponseWriter, so Export can push records to the client as it goes.
+type Flusher interface {
+	Flush()
+}
+
+// Export writes every log entry matching filters to w as newline-delimited
+// JSON, newest first. Rows are streamed from the database one at a time and
+// w is flushed every ExportFlushEvery records if it implements Flusher.
+// Cancelling ctx (e.g. the client disconnects) stops the query promptly.
+// It returns the number of records written.
+func (s *RestLogService) Export(ctx context.Context, filters map[string]interface{}, w io.Writer) (int64, error) {
+	if s.repo == nil {
+		return 0, errors.New("repository not configured")
+	}
+
+	queryFilters, err := s.scopedQueryFilters(filters)
+	if err != nil {
+		return 0, err
+	}
+
+	written, err := writeNDJSON(ctx, w, func(fn func(*logs_db.LogEntry) error) error {
+		return s.repo.StreamQuery(ctx, queryFilters, 0, fn)
+	})
+	if err != nil {
+		return written, fmt.Errorf("export failed: %w", err)
+	}
+	return written, nil
+}
+
+// writeNDJSON writes each entry stream passes to its callback as one NDJSON
+// record, flushing every ExportFlushEvery records. On success anything left
+// is flushed; on error nothing more is, so a failure before the first record
+// leaves the response unwritten for the caller to report.
+func writeNDJSON(ctx context.Context, w io.Writer, stream func(func(*logs_db.LogEntry) error) error) (int64, error) {
+	flusher, _ := w.(Flusher)
+	enc := json.NewEncoder(w) // Encode appends the newline NDJSON needs
+	var written int64
+
+	err := stream(func(entry *logs_db.LogEntry) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := enc.Encode(mapLogEntryToInterface(entry)); err != nil {
+			return fmt.Errorf("write record: %w", err)
+		}
+		written++
+		if flusher != nil && written%ExportFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return written, err
+	}
+
+	if flusher != nil && written > 0 && written%ExportFlushEvery != 0 {
+		flusher.Flush()
+	}
+	return written, nil
+}
+
+// GetByID retrieves a single log entry by ID. Unless userID is an admin,
+// entries outside the user's projects are not found.
+func (s *RestLogService) GetByID(ctx context.Context, id int64, userID int) (interface{}, error) {
 	if s.repo == nil {
 		return nil, errors.New("repository not configured")
 	}
 
-	entry, err := s.repo.GetByID(ctx, id)
+	entry, err := s.repo.GetByID(ctx, id, s.admins.OwnerScope(userID))
 	if err != nil {
 		return nil, fmt.Errorf("get by id failed: %w", err)
 	}
@@ -158,13 +490,40 @@ func (s *RestLogService) GetByID(ctx context.Context, id int64) (interface{}, er
 	return mapLogEntryToInterface(entry), nil
 }
 
-// Stats returns aggregated log statistics.
-func (s *RestLogService) Stats(ctx context.Context) (map[string]interface{}, error) {
+// GetContext returns the log entry with the given id and up to before/after
+// neighboring entries from the same service (and project, if sameProject),
+// in chronological order. The requested entry has "target" set to true.
+// Unless userID is an admin, only the user's projects are considered.
+func (s *RestLogService) GetContext(ctx context.Context, id int64, before, after int, sameProject bool, userID int) ([]interface{}, error) {
 	if s.repo == nil {
 		return nil, errors.New("repository not configured")
 	}
 
-	stats, err := s.repo.GetStats(ctx)
+	entries, err := s.repo.GetNeighbors(ctx, id, before, after, sameProject, s.admins.OwnerScope(userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLogNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get context failed: %w", err)
+	}
+
+	results := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		m := mapLogEntryToInterface(entry)
+		m["target"] = entry.ID == id
+		results[i] = m
+	}
+	return results, nil
+}
+
+// Stats returns aggregated log statistics over userID's projects, or every
+// project for an admin.
+func (s *RestLogService) Stats(ctx context.Context, userID int) (map[string]interface{}, error) {
+	if s.repo == nil {
+		return nil, errors.New("repository not configured")
+	}
+
+	stats, err := s.repo.GetStats(ctx, s.admins.OwnerScope(userID))
 	if err != nil {
 		return nil, fmt.Errorf("get stats failed: %w", err)
 	}
@@ -173,6 +532,92 @@ func (s *RestLogService) Stats(ctx context.Context) (map[string]interface{}, err
 	return stats, nil
 }
 
+// TimeSeries returns log counts bucketed by q.Interval for charting. An
+// empty interval means hourly buckets, a zero To means now and a zero From
+// means DefaultTimeSeriesRange before To. It fails with ErrInvalidTimeSeries
+// for an unknown interval or grouping, an empty range, or a range spanning
+// more than MaxTimeSeriesBuckets buckets. Unless userID is an admin, only the
+// user's projects are counted.
+func (s *RestLogService) TimeSeries(ctx context.Context, q logs_models.TimeSeriesQuery, userID int) ([]logs_models.TimeSeriesPoint, error) {
+	if s.repo == nil {
+		return nil, errors.New("repository not configured")
+	}
+
+	if q.Interval == "" {
+		q.Interval = DefaultTimeSeriesInterval
+	}
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-DefaultTimeSeriesRange)
+	}
+
+	step, ok := timeSeriesIntervals[q.Interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: interval must be minute, hour or day", ErrInvalidTimeSeries)
+	}
+	if q.GroupBy != "" && q.GroupBy != "level" && q.GroupBy != "service" {
+		return nil, fmt.Errorf("%w: group_by must be level or service", ErrInvalidTimeSeries)
+	}
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidTimeSeries)
+	}
+	// Allow one extra bucket for a range that doesn't start on a boundary
+	if q.To.Sub(q.From) > step*(MaxTimeSeriesBuckets-1) {
+		return nil, fmt.Errorf("%w: range spans more than %d %s buckets", ErrInvalidTimeSeries, MaxTimeSeriesBuckets, q.Interval)
+	}
+
+	q.OwnerUserID = s.admins.OwnerScope(userID)
+	points, err := s.repo.CountTimeSeries(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("time series failed: %w", err)
+	}
+	return points, nil
+}
+
+// Fingerprints groups recent entries by message fingerprint, largest groups
+// first. A zero To means now, a zero From means DefaultFingerprintRange
+// before To, and a zero Limit means DefaultFingerprintLimit. It fails with
+// ErrInvalidFingerprintQuery for an empty range, an unknown level or a
+// limit outside 1..MaxFingerprintLimit. Unless userID is an admin, only the
+// user's projects are grouped.
+func (s *RestLogService) Fingerprints(ctx context.Context, q logs_models.FingerprintQuery, userID int) ([]logs_models.FingerprintGroup, error) {
+	if s.repo == nil {
+		return nil, errors.New("repository not configured")
+	}
+
+	if q.To.IsZero() {
+		q.To = time.Now()
+	}
+	if q.From.IsZero() {
+		q.From = q.To.Add(-DefaultFingerprintRange)
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultFingerprintLimit
+	}
+
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidFingerprintQuery)
+	}
+	if q.Limit < 1 || q.Limit > MaxFingerprintLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFingerprintQuery, MaxFingerprintLimit)
+	}
+	if q.Level != "" {
+		if _, ok := logs_models.LevelRank(q.Level); !ok {
+			return nil, fmt.Errorf("%w: level must be one of DEBUG, INFO, WARN, ERROR, FATAL", ErrInvalidFingerprintQuery)
+		}
+		q.Level = strings.ToUpper(strings.TrimSpace(q.Level))
+	}
+
+	q.OwnerUserID = s.admins.OwnerScope(userID)
+	groups, err := s.repo.ListFingerprints(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("list fingerprints failed: %w", err)
+	}
+	return groups, nil
+}
+
 // DeleteByID deletes a log entry by ID.
 func (s *RestLogService) DeleteByID(ctx context.Context, id int64) error {
 	if s.repo == nil {
@@ -183,19 +628,186 @@ func (s *RestLogService) DeleteByID(ctx context.Context, id int64) error {
 	return errors.New("delete by ID not supported")
 }
 
-// Delete deletes logs matching filters.
-func (s *RestLogService) Delete(ctx context.Context, filters map[string]interface{}) (int64, error) {
+// Delete moves the entries of one of the user's projects that match
+// req.Filters to the trash, or with req.DryRun only counts them. Trashed
+// entries are hidden from queries and can be restored with Restore until the
+// grace period elapses. A request with no filter
+// besides the project would wipe the project's logs, so it fails with
+// ErrUnfilteredDelete unless req.ConfirmAll is set. It fails with
+// ErrDeleteForbidden when the project doesn't exist or isn't the user's, and
+// ErrInvalidDelete for a missing project, an unknown filter or a bad time.
+func (s *RestLogService) Delete(ctx context.Context, req DeleteLogsRequest) (int64, error) {
 	if s.repo == nil {
 		return 0, errors.New("repository not configured")
 	}
+	if s.projects == nil {
+		return 0, errors.New("project lookup not configured")
+	}
 
-	// LogRepository only supports DeleteOld with a timestamp
-	// For now, return not implemented
-	return 0, errors.New("delete by filters not supported")
+	if req.ProjectID <= 0 {
+		return 0, fmt.Errorf("%w: project_id is required", ErrInvalidDelete)
+	}
+	for key, value := range req.Filters {
+		if !deleteFilterKeys[key] {
+			return 0, fmt.Errorf("%w: unknown filter %q", ErrInvalidDelete, key)
+		}
+		if key == "from" || key == "to" {
+			if raw, _ := value.(string); parseTime(raw).IsZero() {
+				return 0, fmt.Errorf("%w: %s must be an RFC3339 or Unix timestamp", ErrInvalidDelete, key)
+			}
+		}
+	}
+	filters, err := toQueryFilters(req.Filters)
+	if err != nil {
+		return 0, err
+	}
+	if isEmptyFilter(filters) && !req.ConfirmAll {
+		return 0, ErrUnfilteredDelete
+	}
+
+	project, err := s.ownedProject(ctx, req.ProjectID, req.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	if req.DryRun {
+		count, err := s.repo.CountMatching(ctx, int64(project.ID), filters)
+		if err != nil {
+			return 0, fmt.Errorf("count logs to delete: %w", err)
+		}
+		return count, nil
+	}
+
+	deleted, err := s.repo.SoftDeleteMatching(ctx, int64(project.ID), filters)
+	if err != nil {
+		return 0, fmt.Errorf("delete logs: %w", err)
+	}
+	s.logger.WithFields(logrus.Fields{
+		"user_id":    req.UserID,
+		"project_id": project.ID,
+		"filters":    req.Filters,
+		"deleted":    deleted,
+	}).Warn("Moved project logs to trash")
+	return deleted, nil
+}
+
+// Trash lists the deletions of one of the user's projects that can still be
+// restored, most recent first. It fails with ErrDeleteForbidden when the
+// project doesn't exist or isn't the user's.
+func (s *RestLogService) Trash(ctx context.Context, userID, projectID int) ([]logs_models.TrashBatch, error) {
+	if s.repo == nil {
+		return nil, errors.New("repository not configured")
+	}
+	if s.projects == nil {
+		return nil, errors.New("project lookup not configured")
+	}
+
+	project, err := s.ownedProject(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := s.repo.ListTrash(ctx, int64(project.ID), time.Now().Add(-s.trashGrace))
+	if err != nil {
+		return nil, fmt.Errorf("list trash: %w", err)
+	}
+	for i := range batches {
+		batches[i].PurgeAt = batches[i].DeletedAt.Add(s.trashGrace)
+	}
+	return batches, nil
+}
+
+// Restore brings back entries of one of the user's projects deleted within
+// the grace period: those of the deletion at deletedAt, or all of them when
+// deletedAt is nil. It returns how many were restored, and fails with
+// ErrNothingToRestore when none were.
+func (s *RestLogService) Restore(ctx context.Context, userID, projectID int, deletedAt *time.Time) (int64, error) {
+	if s.repo == nil {
+		return 0, errors.New("repository not configured")
+	}
+	if s.projects == nil {
+		return 0, errors.New("project lookup not configured")
+	}
+
+	project, err := s.ownedProject(ctx, projectID, userID)
+	if err != nil {
+		return 0, err
+	}
+	restored, err := s.repo.RestoreDeleted(ctx, int64(project.ID), time.Now().Add(-s.trashGrace), deletedAt)
+	if err != nil {
+		return 0, fmt.Errorf("restore logs: %w", err)
+	}
+	if restored == 0 {
+		return 0, ErrNothingToRestore
+	}
+	s.logger.WithFields(logrus.Fields{
+		"user_id":    userID,
+		"project_id": project.ID,
+		"restored":   restored,
+	}).Info("Restored project logs from trash")
+	return restored, nil
+}
+
+// ownedProject returns the user's project, or ErrDeleteForbidden when it
+// doesn't exist or belongs to someone else.
+func (s *RestLogService) ownedProject(ctx context.Context, projectID, userID int) (*logs_models.Project, error) {
+	if projectID <= 0 {
+		return nil, fmt.Errorf("%w: project_id is required", ErrInvalidDelete)
+	}
+	project, err := s.projects.GetByID(ctx, projectID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("look up project: %w", err)
+	}
+	if project == nil {
+		return nil, ErrDeleteForbidden
+	}
+	return project, nil
+}
+
+// isEmptyFilter reports whether filters would match every entry.
+func isEmptyFilter(f *logs_db.QueryFilters) bool {
+	return f.Service == "" && f.Level == "" && f.Search == "" && f.FullText == "" &&
+		f.CorrelationID == "" && f.From.IsZero() && f.To.IsZero() &&
+		len(f.MetaEquals) == 0 && len(f.Context) == 0
+}
+
+// scopedQueryFilters converts filters like toQueryFilters and limits them to
+// the caller's projects when filters carries a non-admin CallerFilterKey.
+func (s *RestLogService) scopedQueryFilters(filters map[string]interface{}) (*logs_db.QueryFilters, error) {
+	queryFilters, err := toQueryFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := filters[CallerFilterKey].(int)
+	queryFilters.OwnerUserID = s.admins.OwnerScope(userID)
+	return queryFilters, nil
 }
 
 // Helper functions
 
+// toQueryFilters converts the handler's filter map into repository filters.
+// It fails with ErrInvalidContextFilter if a context_filter expression is bad.
+func toQueryFilters(filters map[string]interface{}) (*logs_db.QueryFilters, error) {
+	var contextExprs []string
+	if v, ok := filters["context_filter"].([]string); ok {
+		contextExprs = v
+	}
+	contextFilters, err := parseContextFilters(contextExprs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logs_db.QueryFilters{
+		Service:       extractString(filters, "service"),
+		Level:         extractString(filters, "level"),
+		Search:        extractString(filters, "search"),
+		FullText:      extractString(filters, "q"),
+		CorrelationID: extractString(filters, "correlation_id"),
+		From:          parseTime(extractString(filters, "from")),
+		To:            parseTime(extractString(filters, "to")),
+		Context:       contextFilters,
+	}, nil
+}
+
 func extractString(data map[string]interface{}, key string) string {
 	if v, ok := data[key]; ok {
 		if s, ok := v.(string); ok {
@@ -233,7 +845,7 @@ func parseTime(s string) time.Time {
 }
 
 func mapLogEntryToInterface(entry *logs_db.LogEntry) map[string]interface{} {
-	return map[string]interface{}{
+	m := map[string]interface{}{
 		"id":         entry.ID,
 		"service":    entry.Service,
 		"level":      entry.Level,
@@ -241,4 +853,8 @@ func mapLogEntryToInterface(entry *logs_db.LogEntry) map[string]interface{} {
 		"metadata":   entry.Metadata,
 		"created_at": entry.CreatedAt,
 	}
+	if entry.CorrelationID != "" {
+		m["correlation_id"] = entry.CorrelationID
+	}
+	return m
 }
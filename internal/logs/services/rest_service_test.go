@@ -0,0 +1,249 @@
+package logs_services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flushRecorder struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (f *flushRecorder) Flush() { f.flushes++ }
+
+func TestRestLogService_Export_NoRows(t *testing.T) {
+	svc := NewRestLogService(logs_db.NewLogRepository(nil), logrus.New())
+	w := &flushRecorder{}
+
+	written, err := svc.Export(context.Background(), map[string]interface{}{"service": "portal"}, w)
+
+	require.NoError(t, err)
+	assert.Zero(t, written)
+	assert.Empty(t, w.String())
+	assert.Zero(t, w.flushes, "nothing is flushed before the first record")
+}
+
+// streamEntries passes n entries to fn, the way StreamQuery does.
+func streamEntries(n int, onEach func(i int)) func(func(*logs_db.LogEntry) error) error {
+	return func(fn func(*logs_db.LogEntry) error) error {
+		for i := 1; i <= n; i++ {
+			if onEach != nil {
+				onEach(i)
+			}
+			if err := fn(&logs_db.LogEntry{ID: int64(i), Level: "ERROR", Message: "stored"}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestWriteNDJSON_FlushesEveryN(t *testing.T) {
+	w := &flushRecorder{}
+
+	written, err := writeNDJSON(context.Background(), w, streamEntries(2*ExportFlushEvery+1, nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2*ExportFlushEvery+1), written)
+	assert.Equal(t, 2*ExportFlushEvery+1, bytes.Count(w.Bytes(), []byte("\n")))
+	assert.Equal(t, 3, w.flushes, "two full batches and the remainder")
+
+	w = &flushRecorder{}
+	_, err = writeNDJSON(context.Background(), w, streamEntries(ExportFlushEvery, nil))
+	require.NoError(t, err)
+	assert.Equal(t, 1, w.flushes, "an exact batch isn't flushed twice")
+}
+
+func TestWriteNDJSON_StopsWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &flushRecorder{}
+
+	written, err := writeNDJSON(ctx, w, streamEntries(10, func(i int) {
+		if i == 4 {
+			cancel() // The client went away after three records
+		}
+	}))
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int64(3), written)
+	assert.Zero(t, w.flushes, "a failed export is not flushed")
+}
+
+func TestWriteNDJSON_ErrorBeforeFirstRecordWritesNothing(t *testing.T) {
+	w := &flushRecorder{}
+
+	written, err := writeNDJSON(context.Background(), w, func(func(*logs_db.LogEntry) error) error {
+		return errors.New("connection refused")
+	})
+
+	assert.EqualError(t, err, "connection refused")
+	assert.Zero(t, written)
+	assert.Empty(t, w.String())
+	assert.Zero(t, w.flushes)
+}
+
+func TestRestLogService_Export_InvalidFilter(t *testing.T) {
+	svc := NewRestLogService(logs_db.NewLogRepository(nil), logrus.New())
+
+	_, err := svc.Export(context.Background(), map[string]interface{}{
+		"context_filter": []string{"context.a~1"},
+	}, &bytes.Buffer{})
+
+	assert.True(t, errors.Is(err, ErrInvalidContextFilter))
+}
+
+func TestRestLogService_Export_CancelledContext(t *testing.T) {
+	svc := NewRestLogService(logs_db.NewLogRepository(nil), logrus.New())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := svc.Export(ctx, map[string]interface{}{}, &bytes.Buffer{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRestLogService_TimeSeries_Validation(t *testing.T) {
+	svc := NewRestLogService(logs_db.NewLogRepository(nil), logrus.New())
+	ctx := context.Background()
+	to := time.Date(2025, 11, 23, 12, 0, 0, 0, time.UTC)
+
+	_, err := svc.TimeSeries(ctx, logs_models.TimeSeriesQuery{To: to}, 0)
+	require.NoError(t, err, "defaults to the last day in hourly buckets")
+	_, err = svc.TimeSeries(ctx, logs_models.TimeSeriesQuery{From: to.Add(-MaxTimeSeriesBuckets * time.Hour / 2), To: to, GroupBy: "level"}, 0)
+	require.NoError(t, err)
+
+	for name, q := range map[string]logs_models.TimeSeriesQuery{
+		"unknown interval": {To: to, Interval: "week"},
+		"unknown group":    {To: to, GroupBy: "message"},
+		"empty range":      {From: to, To: to},
+		"reversed range":   {From: to, To: to.Add(-time.Hour)},
+		"too many buckets": {From: to.Add(-48 * time.Hour), To: to, Interval: "minute"},
+	} {
+		_, err := svc.TimeSeries(ctx, q, 0)
+		assert.ErrorIs(t, err, ErrInvalidTimeSeries, name)
+	}
+}
+
+func TestRestLogService_Fingerprints_Validation(t *testing.T) {
+	svc := NewRestLogService(logs_db.NewLogRepository(nil), logrus.New())
+	ctx := context.Background()
+	to := time.Date(2025, 11, 25, 12, 0, 0, 0, time.UTC)
+
+	_, err := svc.Fingerprints(ctx, logs_models.FingerprintQuery{To: to}, 0)
+	require.NoError(t, err, "defaults to the last day")
+	_, err = svc.Fingerprints(ctx, logs_models.FingerprintQuery{To: to, Level: "error", Limit: MaxFingerprintLimit}, 0)
+	require.NoError(t, err)
+
+	for name, q := range map[string]logs_models.FingerprintQuery{
+		"empty range":    {From: to, To: to},
+		"reversed range": {From: to, To: to.Add(-time.Hour)},
+		"unknown level":  {To: to, Level: "verbose"},
+		"negative limit": {To: to, Limit: -1},
+		"limit too big":  {To: to, Limit: MaxFingerprintLimit + 1},
+	} {
+		_, err := svc.Fingerprints(ctx, q, 0)
+		assert.ErrorIs(t, err, ErrInvalidFingerprintQuery, name)
+	}
+}
+
+// ownedProjects resolves the projects each user owns.
+type ownedProjects map[int]int // project ID -> owner
+
+func (o ownedProjects) GetByID(ctx context.Context, id int, userID int) (*logs_models.Project, error) {
+	if owner, ok := o[id]; ok && owner == userID {
+		return &logs_models.Project{ID: id, UserID: &owner}, nil
+	}
+	return nil, nil
+}
+
+func TestRestLogService_Delete_Guards(t *testing.T) {
+	svc := NewRestLogService(logs_db.NewLogRepository(nil), logrus.New())
+	svc.SetProjects(ownedProjects{3: 7})
+	ctx := context.Background()
+
+	_, err := svc.Delete(ctx, DeleteLogsRequest{ProjectID: 3, UserID: 7})
+	assert.ErrorIs(t, err, ErrUnfilteredDelete, "no filters and no confirmation")
+	_, err = svc.Delete(ctx, DeleteLogsRequest{ProjectID: 3, UserID: 7, Filters: map[string]interface{}{}, DryRun: true})
+	assert.ErrorIs(t, err, ErrUnfilteredDelete, "a dry run is guarded too")
+
+	_, err = svc.Delete(ctx, DeleteLogsRequest{ProjectID: 3, UserID: 7, ConfirmAll: true})
+	require.NoError(t, err)
+	_, err = svc.Delete(ctx, DeleteLogsRequest{ProjectID: 3, UserID: 7, Filters: map[string]interface{}{"level": "DEBUG"}})
+	require.NoError(t, err)
+
+	_, err = svc.Delete(ctx, DeleteLogsRequest{ProjectID: 3, UserID: 8, Filters: map[string]interface{}{"level": "DEBUG"}})
+	assert.ErrorIs(t, err, ErrDeleteForbidden, "another user's project")
+	_, err = svc.Delete(ctx, DeleteLogsRequest{ProjectID: 4, UserID: 7, ConfirmAll: true})
+	assert.ErrorIs(t, err, ErrDeleteForbidden, "missing project")
+
+	for name, req := range map[string]DeleteLogsRequest{
+		"no project":     {UserID: 7, ConfirmAll: true},
+		"unknown filter": {ProjectID: 3, UserID: 7, Filters: map[string]interface{}{"before": "2025-01-01"}},
+		"bad time":       {ProjectID: 3, UserID: 7, Filters: map[string]interface{}{"to": "yesterday"}},
+	} {
+		_, err := svc.Delete(ctx, req)
+		assert.ErrorIs(t, err, ErrInvalidDelete, name)
+	}
+}
+
+func TestRestLogService_Trash_Guards(t *testing.T) {
+	svc := NewRestLogService(logs_db.NewLogRepository(nil), logrus.New())
+	svc.SetProjects(ownedProjects{3: 7})
+	ctx := context.Background()
+
+	batches, err := svc.Trash(ctx, 7, 3)
+	require.NoError(t, err)
+	assert.Empty(t, batches)
+
+	_, err = svc.Trash(ctx, 8, 3)
+	assert.ErrorIs(t, err, ErrDeleteForbidden, "another user's project")
+	_, err = svc.Restore(ctx, 8, 3, nil)
+	assert.ErrorIs(t, err, ErrDeleteForbidden)
+	_, err = svc.Restore(ctx, 7, 0, nil)
+	assert.ErrorIs(t, err, ErrInvalidDelete)
+
+	_, err = svc.Restore(ctx, 7, 3, nil)
+	assert.ErrorIs(t, err, ErrNothingToRestore)
+}
+
+func TestRestLogService_SetTrashGracePeriod(t *testing.T) {
+	svc := NewRestLogService(nil, logrus.New())
+	assert.Equal(t, DefaultTrashGracePeriod, svc.trashGrace)
+	svc.SetTrashGracePeriod(time.Hour)
+	assert.Equal(t, time.Hour, svc.trashGrace)
+	svc.SetTrashGracePeriod(0)
+	assert.Equal(t, time.Hour, svc.trashGrace)
+}
+
+func TestRestLogService_ScopedQueryFilters(t *testing.T) {
+	svc := NewRestLogService(nil, logrus.New())
+	svc.SetAdmins([]int{1})
+
+	f, err := svc.scopedQueryFilters(map[string]interface{}{"service": "orders", CallerFilterKey: 7})
+	require.NoError(t, err)
+	assert.Equal(t, 7, f.OwnerUserID, "users only see their own projects")
+	assert.Equal(t, "orders", f.Service)
+
+	f, err = svc.scopedQueryFilters(map[string]interface{}{CallerFilterKey: 1})
+	require.NoError(t, err)
+	assert.Zero(t, f.OwnerUserID, "admins see every project")
+
+	f, err = svc.scopedQueryFilters(map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Zero(t, f.OwnerUserID)
+
+	f, err = svc.scopedQueryFilters(map[string]interface{}{CallerFilterKey: "7"})
+	require.NoError(t, err)
+	assert.Zero(t, f.OwnerUserID, "only the handler's int user ID is trusted")
+}
@@ -0,0 +1,173 @@
+package logs_services
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Retention defaults
+const (
+	DefaultRetentionDays      = 90
+	DefaultRetentionBatchSize = 10000
+)
+
+// Retention metrics, published under /debug/vars.
+var (
+	retentionRowsDeleted   = expvar.NewInt("logs_retention_rows_deleted_total")
+	retentionLastRunRows   = expvar.NewInt("logs_retention_last_run_rows_deleted")
+	retentionLastRunMillis = expvar.NewInt("logs_retention_last_run_duration_ms")
+	retentionRunsFailed    = expvar.NewInt("logs_retention_runs_failed_total")
+)
+
+// ErrRetentionRunning is returned when a run is requested while one is in progress.
+var ErrRetentionRunning = errors.New("retention run already in progress")
+
+// LogRetentionRepository is the storage the retention job purges.
+type LogRetentionRepository interface {
+	ListProjectRetention(ctx context.Context) (map[int64]int, error)
+	DeleteExpiredBatch(ctx context.Context, cutoff time.Time, projectID *int64, batchSize int) (int64, error)
+}
+
+// RetentionResult summarizes one retention run.
+type RetentionResult struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+	Deleted   int64         `json:"deleted"`
+	Projects  int           `json:"projects"` // Projects with their own retention window
+}
+
+// RetentionJob deletes log entries older than their retention window:
+// the per-project retention_days when set, otherwise the default.
+type RetentionJob struct {
+	repo        LogRetentionRepository
+	logger      *logrus.Logger
+	now         func() time.Time
+	mu          sync.Mutex
+	defaultDays int
+	batchSize   int
+}
+
+// NewRetentionJob creates a RetentionJob. Non-positive defaultDays or
+// batchSize fall back to DefaultRetentionDays / DefaultRetentionBatchSize.
+func NewRetentionJob(repo LogRetentionRepository, defaultDays, batchSize int, logger *logrus.Logger) *RetentionJob {
+	if defaultDays <= 0 {
+		defaultDays = DefaultRetentionDays
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultRetentionBatchSize
+	}
+	return &RetentionJob{
+		repo:        repo,
+		logger:      logger,
+		now:         time.Now,
+		defaultDays: defaultDays,
+		batchSize:   batchSize,
+	}
+}
+
+// Start runs the job every interval until ctx is cancelled.
+// It returns immediately; cancelling ctx also aborts a run in progress.
+func (j *RetentionJob) Start(ctx context.Context, interval time.Duration) {
+	if j.repo == nil {
+		j.logger.Warn("Retention job: repository is nil; retention disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		j.logger.WithFields(logrus.Fields{
+			"default_days": j.defaultDays,
+			"interval":     interval.String(),
+		}).Info("Log retention job started")
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				j.logger.Info("Log retention job stopping due to context cancellation")
+				return
+			case <-ticker.C:
+				if _, err := j.RunOnce(ctx); err != nil && !errors.Is(err, ErrRetentionRunning) {
+					j.logger.WithError(err).Error("Log retention run failed")
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce performs a single retention pass. Only one pass runs at a time;
+// a concurrent call returns ErrRetentionRunning.
+func (j *RetentionJob) RunOnce(ctx context.Context) (RetentionResult, error) {
+	if !j.mu.TryLock() {
+		return RetentionResult{}, ErrRetentionRunning
+	}
+	defer j.mu.Unlock()
+
+	began := time.Now()
+	result := RetentionResult{StartedAt: j.now()}
+	err := j.run(ctx, &result)
+	result.Duration = time.Since(began)
+
+	retentionRowsDeleted.Add(result.Deleted)
+	retentionLastRunRows.Set(result.Deleted)
+	retentionLastRunMillis.Set(result.Duration.Milliseconds())
+
+	fields := logrus.Fields{
+		"deleted":  result.Deleted,
+		"projects": result.Projects,
+		"duration": result.Duration.String(),
+	}
+	if err != nil {
+		retentionRunsFailed.Add(1)
+		j.logger.WithFields(fields).WithError(err).Warn("Log retention run stopped early")
+		return result, err
+	}
+	j.logger.WithFields(fields).Info("Log retention run completed")
+	return result, nil
+}
+
+func (j *RetentionJob) run(ctx context.Context, result *RetentionResult) error {
+	overrides, err := j.repo.ListProjectRetention(ctx)
+	if err != nil {
+		return fmt.Errorf("load project retention: %w", err)
+	}
+	result.Projects = len(overrides)
+
+	for projectID, days := range overrides {
+		id := projectID
+		if err := j.purge(ctx, retentionCutoff(result.StartedAt, days), &id, result); err != nil {
+			return fmt.Errorf("purge project %d: %w", projectID, err)
+		}
+	}
+
+	if err := j.purge(ctx, retentionCutoff(result.StartedAt, j.defaultDays), nil, result); err != nil {
+		return fmt.Errorf("purge default scope: %w", err)
+	}
+	return nil
+}
+
+// purge deletes in batches until a short batch shows nothing is left.
+func (j *RetentionJob) purge(ctx context.Context, cutoff time.Time, projectID *int64, result *RetentionResult) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := j.repo.DeleteExpiredBatch(ctx, cutoff, projectID, j.batchSize)
+		if err != nil {
+			return err
+		}
+		result.Deleted += n
+		if n < int64(j.batchSize) {
+			return nil
+		}
+	}
+}
+
+func retentionCutoff(now time.Time, days int) time.Time {
+	return now.Add(-time.Duration(days) * 24 * time.Hour)
+}
@@ -0,0 +1,106 @@
+package logs_services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type deleteCall struct {
+	cutoff    time.Time
+	projectID *int64
+}
+
+// fakeRetentionRepo holds a number of expired rows per scope (0 = default).
+type fakeRetentionRepo struct {
+	overrides map[int64]int
+	expired   map[int64]int64
+	calls     []deleteCall
+	err       error
+}
+
+func (f *fakeRetentionRepo) ListProjectRetention(ctx context.Context) (map[int64]int, error) {
+	return f.overrides, nil
+}
+
+func (f *fakeRetentionRepo) DeleteExpiredBatch(ctx context.Context, cutoff time.Time, projectID *int64, batchSize int) (int64, error) {
+	f.calls = append(f.calls, deleteCall{cutoff: cutoff, projectID: projectID})
+	if f.err != nil {
+		return 0, f.err
+	}
+	var scope int64
+	if projectID != nil {
+		scope = *projectID
+	}
+	n := f.expired[scope]
+	if n > int64(batchSize) {
+		n = int64(batchSize)
+	}
+	f.expired[scope] -= n
+	return n, nil
+}
+
+func TestRetentionJob_RunOnce_BatchesAndScopes(t *testing.T) {
+	now := time.Date(2025, 11, 13, 3, 0, 0, 0, time.UTC)
+	repo := &fakeRetentionRepo{
+		overrides: map[int64]int{7: 7},
+		expired:   map[int64]int64{0: 25, 7: 10},
+	}
+	job := NewRetentionJob(repo, 30, 10, logrus.New())
+	job.now = func() time.Time { return now }
+
+	result, err := job.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(35), result.Deleted)
+	assert.Equal(t, 1, result.Projects)
+
+	// Project 7: 10 (full batch) then 0; default: 10, 10, 5
+	require.Len(t, repo.calls, 5)
+	assert.Equal(t, int64(7), *repo.calls[0].projectID)
+	assert.Equal(t, now.AddDate(0, 0, -7), repo.calls[0].cutoff)
+	assert.Nil(t, repo.calls[2].projectID)
+	assert.Equal(t, now.AddDate(0, 0, -30), repo.calls[2].cutoff)
+}
+
+func TestRetentionJob_RunOnce_StopsOnCancel(t *testing.T) {
+	repo := &fakeRetentionRepo{expired: map[int64]int64{0: 1000}}
+	job := NewRetentionJob(repo, 30, 10, logrus.New())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := job.RunOnce(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, repo.calls)
+}
+
+func TestRetentionJob_RunOnce_RepoError(t *testing.T) {
+	repo := &fakeRetentionRepo{expired: map[int64]int64{}, err: errors.New("lock timeout")}
+	job := NewRetentionJob(repo, 30, 10, logrus.New())
+
+	_, err := job.RunOnce(context.Background())
+
+	assert.ErrorContains(t, err, "lock timeout")
+}
+
+func TestRetentionJob_RunOnce_RejectsConcurrentRun(t *testing.T) {
+	job := NewRetentionJob(&fakeRetentionRepo{expired: map[int64]int64{}}, 30, 10, logrus.New())
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	_, err := job.RunOnce(context.Background())
+
+	assert.ErrorIs(t, err, ErrRetentionRunning)
+}
+
+func TestNewRetentionJob_Defaults(t *testing.T) {
+	job := NewRetentionJob(&fakeRetentionRepo{}, 0, 0, logrus.New())
+	assert.Equal(t, DefaultRetentionDays, job.defaultDays)
+	assert.Equal(t, DefaultRetentionBatchSize, job.batchSize)
+}
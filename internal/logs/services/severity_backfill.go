@@ -0,0 +1,264 @@
+package logs_services
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Severity backfill defaults
+const (
+	DefaultSeverityBackfillBatchSize = 1000
+	SeverityBackfillJobName          = "severity_score"
+)
+
+// Severity backfill metrics, published under /debug/vars.
+var (
+	severityBackfillRowsUpdated = expvar.NewInt("logs_severity_backfill_rows_updated_total")
+	severityBackfillLastID      = expvar.NewInt("logs_severity_backfill_last_id")
+	severityBackfillRunsFailed  = expvar.NewInt("logs_severity_backfill_runs_failed_total")
+)
+
+// ErrSeverityBackfillRunning is returned when a run is requested while one is in progress.
+var ErrSeverityBackfillRunning = errors.New("severity backfill already in progress")
+
+// SeverityBackfillRepository is the storage the severity backfill scores.
+type SeverityBackfillRepository interface {
+	ListUnscoredBatch(ctx context.Context, afterID int64, limit int) ([]*logs_db.LogEntry, error)
+	SetSeverityScores(ctx context.Context, scores map[int64]int) (int64, error)
+	GetBackfillCheckpoint(ctx context.Context, job string) (int64, error)
+	SetBackfillCheckpoint(ctx context.Context, job string, lastID int64) error
+}
+
+// SeverityAnalyzer scores log entries with AI; AIAnalyzer implements it.
+type SeverityAnalyzer interface {
+	Analyze(ctx context.Context, req AnalysisRequest) (*AnalysisResult, error)
+}
+
+// SeverityBackfillResult summarizes one backfill run.
+type SeverityBackfillResult struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+	Scanned   int64         `json:"scanned"`   // Unscored entries read
+	Updated   int64         `json:"updated"`   // Entries given a severity_score
+	AIScored  int64         `json:"ai_scored"` // Entries scored by AI rather than rules
+	LastID    int64         `json:"last_id"`   // Checkpoint after the run
+}
+
+// levelSeverity is the rule-based severity of each log level (1-5).
+var levelSeverity = map[string]int{
+	"TRACE":    1,
+	"DEBUG":    1,
+	"INFO":     1,
+	"WARN":     2,
+	"WARNING":  2,
+	"ERROR":    3,
+	"CRITICAL": 5,
+	"FATAL":    5,
+	"PANIC":    5,
+}
+
+// issueSeverity raises the severity of warnings and errors of an issue type.
+var issueSeverity = map[string]int{
+	"db_connection":   4,
+	"null_pointer":    4,
+	"auth_failure":    3,
+	"network_timeout": 3,
+	"rate_limit":      3,
+}
+
+// RuleSeverity scores an entry from 1 (info) to 5 (critical) by its level,
+// raised for warnings and errors of a serious issue type. Unknown levels
+// score 1.
+func RuleSeverity(level, issueType string) int {
+	score, ok := levelSeverity[strings.ToUpper(strings.TrimSpace(level))]
+	if !ok {
+		return 1
+	}
+	if score >= levelSeverity["WARN"] && issueSeverity[issueType] > score {
+		score = issueSeverity[issueType]
+	}
+	return score
+}
+
+// SeverityBackfillJob gives entries with no severity_score (ingested before
+// AI analysis, or by paths that skip it) a score, so severity dashboards
+// cover them. Entries are processed in id order in batches, and the last id
+// of each batch is checkpointed, so an interrupted run resumes where it
+// stopped and a repeated run only picks up entries ingested since.
+type SeverityBackfillJob struct {
+	repo       SeverityBackfillRepository
+	classifier *PatternMatcher
+	ai         SeverityAnalyzer
+	logger     *logrus.Logger
+	now        func() time.Time
+	mu         sync.Mutex
+	batchSize  int
+	aiEvery    int
+}
+
+// NewSeverityBackfillJob creates a SeverityBackfillJob scoring entries by
+// rules, classifying entries without an issue_type with classifier (the
+// built-in rules if nil). A non-positive batchSize falls back to
+// DefaultSeverityBackfillBatchSize.
+func NewSeverityBackfillJob(repo SeverityBackfillRepository, classifier *PatternMatcher, batchSize int, logger *logrus.Logger) *SeverityBackfillJob {
+	if classifier == nil {
+		classifier = NewPatternMatcher()
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultSeverityBackfillBatchSize
+	}
+	return &SeverityBackfillJob{
+		repo:       repo,
+		classifier: classifier,
+		logger:     logger,
+		now:        time.Now,
+		batchSize:  batchSize,
+	}
+}
+
+// SetAISampling scores one in every `every` warning-or-worse entries with
+// analyzer instead of rules. Rules are used whenever the AI call fails.
+func (j *SeverityBackfillJob) SetAISampling(analyzer SeverityAnalyzer, every int) {
+	j.ai = analyzer
+	j.aiEvery = every
+}
+
+// Start runs the backfill in the background and returns immediately, or
+// returns ErrSeverityBackfillRunning if a run is in progress. Cancelling ctx
+// stops the run after the current batch's checkpoint.
+func (j *SeverityBackfillJob) Start(ctx context.Context) error {
+	if !j.mu.TryLock() {
+		return ErrSeverityBackfillRunning
+	}
+	go func() {
+		defer j.mu.Unlock()
+		//nolint:errcheck // runLocked logs the outcome
+		j.runLocked(ctx)
+	}()
+	return nil
+}
+
+// RunOnce runs the backfill to completion. Only one run happens at a time;
+// a concurrent call returns ErrSeverityBackfillRunning.
+func (j *SeverityBackfillJob) RunOnce(ctx context.Context) (SeverityBackfillResult, error) {
+	if !j.mu.TryLock() {
+		return SeverityBackfillResult{}, ErrSeverityBackfillRunning
+	}
+	defer j.mu.Unlock()
+	return j.runLocked(ctx)
+}
+
+func (j *SeverityBackfillJob) runLocked(ctx context.Context) (SeverityBackfillResult, error) {
+	began := time.Now()
+	result := SeverityBackfillResult{StartedAt: j.now()}
+	err := j.run(ctx, &result)
+	result.Duration = time.Since(began)
+
+	severityBackfillRowsUpdated.Add(result.Updated)
+	severityBackfillLastID.Set(result.LastID)
+
+	fields := logrus.Fields{
+		"scanned":   result.Scanned,
+		"updated":   result.Updated,
+		"ai_scored": result.AIScored,
+		"last_id":   result.LastID,
+		"duration":  result.Duration.String(),
+	}
+	if err != nil {
+		severityBackfillRunsFailed.Add(1)
+		j.logger.WithFields(fields).WithError(err).Warn("Severity backfill stopped early")
+		return result, err
+	}
+	j.logger.WithFields(fields).Info("Severity backfill completed")
+	return result, nil
+}
+
+func (j *SeverityBackfillJob) run(ctx context.Context, result *SeverityBackfillResult) error {
+	lastID, err := j.repo.GetBackfillCheckpoint(ctx, SeverityBackfillJobName)
+	if err != nil {
+		return fmt.Errorf("load checkpoint: %w", err)
+	}
+	result.LastID = lastID
+
+	var sampled int
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entries, err := j.repo.ListUnscoredBatch(ctx, result.LastID, j.batchSize)
+		if err != nil {
+			return fmt.Errorf("list unscored entries after %d: %w", result.LastID, err)
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		scores := make(map[int64]int, len(entries))
+		for _, entry := range entries {
+			issueType := entry.IssueType
+			if issueType == "" {
+				issueType = j.classifier.Classify(entry.Message)
+			}
+			score := RuleSeverity(entry.Level, issueType)
+			if j.ai != nil && j.aiEvery > 0 && score >= levelSeverity["WARN"] {
+				if sampled%j.aiEvery == 0 {
+					if aiScore, ok := j.aiSeverity(ctx, entry); ok {
+						score = aiScore
+						result.AIScored++
+					}
+				}
+				sampled++
+			}
+			scores[entry.ID] = score
+		}
+
+		updated, err := j.repo.SetSeverityScores(ctx, scores)
+		if err != nil {
+			return fmt.Errorf("set severity scores: %w", err)
+		}
+		batchLastID := entries[len(entries)-1].ID
+		if err := j.repo.SetBackfillCheckpoint(ctx, SeverityBackfillJobName, batchLastID); err != nil {
+			return fmt.Errorf("save checkpoint: %w", err)
+		}
+		result.Scanned += int64(len(entries))
+		result.Updated += updated
+		result.LastID = batchLastID
+
+		if len(entries) < j.batchSize {
+			return nil
+		}
+	}
+}
+
+// aiSeverity asks the AI for entry's severity, reporting false if the call
+// fails or the score is out of range.
+func (j *SeverityBackfillJob) aiSeverity(ctx context.Context, entry *logs_db.LogEntry) (int, bool) {
+	analysis, err := j.ai.Analyze(ctx, AnalysisRequest{
+		LogEntries: []logs_models.LogEntry{{
+			ID:        entry.ID,
+			Service:   entry.Service,
+			Level:     entry.Level,
+			Message:   entry.Message,
+			IssueType: entry.IssueType,
+			CreatedAt: entry.CreatedAt,
+		}},
+		Context: strings.ToLower(entry.Level),
+	})
+	if err != nil {
+		j.logger.WithError(err).WithField("log_id", entry.ID).Debug("AI severity failed, using rules")
+		return 0, false
+	}
+	if analysis == nil || analysis.Severity < 1 || analysis.Severity > 5 {
+		return 0, false
+	}
+	return analysis.Severity, true
+}
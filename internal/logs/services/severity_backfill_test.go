@@ -0,0 +1,233 @@
+package logs_services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSeverityRepo holds entries by id; a nil score is unscored.
+type fakeSeverityRepo struct {
+	entries    map[int64]*logs_db.LogEntry
+	scores     map[int64]*int
+	checkpoint int64
+	listCalls  int
+	failAfter  int // Fail ListUnscoredBatch once this many calls succeeded; 0 never
+}
+
+func newFakeSeverityRepo(entries ...*logs_db.LogEntry) *fakeSeverityRepo {
+	f := &fakeSeverityRepo{entries: map[int64]*logs_db.LogEntry{}, scores: map[int64]*int{}}
+	for _, e := range entries {
+		f.entries[e.ID] = e
+		f.scores[e.ID] = nil
+	}
+	return f
+}
+
+func (f *fakeSeverityRepo) ListUnscoredBatch(ctx context.Context, afterID int64, limit int) ([]*logs_db.LogEntry, error) {
+	if f.failAfter > 0 && f.listCalls == f.failAfter {
+		f.failAfter = 0
+		return nil, errors.New("connection reset")
+	}
+	f.listCalls++
+	ids := make([]int64, 0, len(f.entries))
+	for id := range f.entries {
+		if id > afterID && f.scores[id] == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	batch := make([]*logs_db.LogEntry, len(ids))
+	for i, id := range ids {
+		batch[i] = f.entries[id]
+	}
+	return batch, nil
+}
+
+func (f *fakeSeverityRepo) SetSeverityScores(ctx context.Context, scores map[int64]int) (int64, error) {
+	var updated int64
+	for id, score := range scores {
+		if f.scores[id] == nil {
+			s := score
+			f.scores[id] = &s
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (f *fakeSeverityRepo) GetBackfillCheckpoint(ctx context.Context, job string) (int64, error) {
+	return f.checkpoint, nil
+}
+
+func (f *fakeSeverityRepo) SetBackfillCheckpoint(ctx context.Context, job string, lastID int64) error {
+	f.checkpoint = lastID
+	return nil
+}
+
+func (f *fakeSeverityRepo) score(id int64) int {
+	if f.scores[id] == nil {
+		return 0
+	}
+	return *f.scores[id]
+}
+
+// fakeSeverityAnalyzer returns a fixed severity and counts calls.
+type fakeSeverityAnalyzer struct {
+	severity int
+	err      error
+	calls    int
+}
+
+func (f *fakeSeverityAnalyzer) Analyze(ctx context.Context, req AnalysisRequest) (*AnalysisResult, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &AnalysisResult{Severity: f.severity}, nil
+}
+
+func backfillEntries() []*logs_db.LogEntry {
+	return []*logs_db.LogEntry{
+		{ID: 1, Level: "INFO", Message: "user signed in"},
+		{ID: 2, Level: "ERROR", Message: "dial tcp: connection refused"},
+		{ID: 3, Level: "warn", Message: "upstream returned 429"},
+		{ID: 4, Level: "ERROR", Message: "template not found"},
+		{ID: 5, Level: "FATAL", Message: "out of memory"},
+		{ID: 6, Level: "ERROR", Message: "anything", IssueType: "null_pointer"},
+		{ID: 7, Level: "INFO", Message: "request timeout set to 30s"},
+	}
+}
+
+func TestRuleSeverity(t *testing.T) {
+	assert.Equal(t, 1, RuleSeverity("info", "unknown"))
+	assert.Equal(t, 1, RuleSeverity("INFO", "network_timeout"), "only warnings and errors are raised by issue type")
+	assert.Equal(t, 2, RuleSeverity("WARN", "unknown"))
+	assert.Equal(t, 3, RuleSeverity("warning", "rate_limit"))
+	assert.Equal(t, 3, RuleSeverity("ERROR", "unknown"))
+	assert.Equal(t, 4, RuleSeverity("error", "db_connection"))
+	assert.Equal(t, 5, RuleSeverity("FATAL", "db_connection"))
+	assert.Equal(t, 1, RuleSeverity("verbose", "db_connection"))
+}
+
+func TestSeverityBackfillJob_ScoresNullRows(t *testing.T) {
+	repo := newFakeSeverityRepo(backfillEntries()...)
+	already := 5
+	repo.scores[4] = &already // Scored by AI analysis
+	job := NewSeverityBackfillJob(repo, nil, 2, logrus.New())
+
+	result, err := job.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), result.Scanned)
+	assert.Equal(t, int64(6), result.Updated)
+	assert.Equal(t, int64(7), result.LastID)
+	assert.Equal(t, int64(7), repo.checkpoint)
+
+	for id, want := range map[int64]int{1: 1, 2: 4, 3: 3, 4: 5, 5: 5, 6: 4, 7: 1} {
+		assert.Equal(t, want, repo.score(id), "entry %d", id)
+	}
+}
+
+func TestSeverityBackfillJob_IdempotentOnRerun(t *testing.T) {
+	repo := newFakeSeverityRepo(backfillEntries()...)
+	job := NewSeverityBackfillJob(repo, nil, 3, logrus.New())
+
+	_, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	scored := map[int64]int{}
+	for id := range repo.entries {
+		scored[id] = repo.score(id)
+	}
+
+	result, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, result.Scanned)
+	assert.Zero(t, result.Updated)
+	assert.Equal(t, int64(7), result.LastID)
+
+	// Even from a reset checkpoint, nothing already scored is touched
+	repo.checkpoint = 0
+	result, err = job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, result.Updated)
+	for id, score := range scored {
+		assert.Equal(t, score, repo.score(id))
+	}
+
+	// Entries ingested later are picked up on the next run
+	repo.entries[8] = &logs_db.LogEntry{ID: 8, Level: "ERROR", Message: "unauthorized"}
+	repo.scores[8] = nil
+	result, err = job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Updated)
+	assert.Equal(t, 3, repo.score(8))
+}
+
+func TestSeverityBackfillJob_ResumesFromCheckpoint(t *testing.T) {
+	repo := newFakeSeverityRepo(backfillEntries()...)
+	repo.failAfter = 2
+	job := NewSeverityBackfillJob(repo, nil, 2, logrus.New())
+
+	result, err := job.RunOnce(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int64(4), result.Updated)
+	assert.Equal(t, int64(4), repo.checkpoint)
+
+	result, err = job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Scanned, "the second run starts after the checkpoint")
+	assert.Equal(t, int64(3), result.Updated)
+	for id := range repo.entries {
+		assert.NotZero(t, repo.score(id), "entry %d", id)
+	}
+}
+
+func TestSeverityBackfillJob_AISampling(t *testing.T) {
+	repo := newFakeSeverityRepo(backfillEntries()...)
+	ai := &fakeSeverityAnalyzer{severity: 2}
+	job := NewSeverityBackfillJob(repo, nil, 10, logrus.New())
+	job.SetAISampling(ai, 2)
+
+	result, err := job.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	// Warnings and errors are 2, 3, 4, 5, 6; every second one goes to AI
+	assert.Equal(t, 3, ai.calls)
+	assert.Equal(t, int64(3), result.AIScored)
+	assert.Equal(t, 2, repo.score(2))
+	assert.Equal(t, 3, repo.score(3), "not sampled, scored by rules")
+	assert.Equal(t, 2, repo.score(4))
+	assert.Equal(t, 1, repo.score(1), "info entries are never sent to AI")
+}
+
+func TestSeverityBackfillJob_AIFailureFallsBackToRules(t *testing.T) {
+	repo := newFakeSeverityRepo(backfillEntries()...)
+	job := NewSeverityBackfillJob(repo, nil, 10, logrus.New())
+	job.SetAISampling(&fakeSeverityAnalyzer{err: errors.New("model unavailable")}, 1)
+
+	result, err := job.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Zero(t, result.AIScored)
+	assert.Equal(t, 4, repo.score(2))
+}
+
+func TestSeverityBackfillJob_RejectsConcurrentRun(t *testing.T) {
+	job := NewSeverityBackfillJob(newFakeSeverityRepo(), nil, 0, logrus.New())
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	_, err := job.RunOnce(context.Background())
+	assert.ErrorIs(t, err, ErrSeverityBackfillRunning)
+	assert.ErrorIs(t, job.Start(context.Background()), ErrSeverityBackfillRunning)
+}
@@ -0,0 +1,101 @@
+package logs_services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SSEHeartbeatInterval is how often an idle SSE stream sends a heartbeat
+// event so proxies don't close the connection.
+const SSEHeartbeatInterval = 15 * time.Second
+
+// LogStreamSource is the hub side of a non-WebSocket log stream.
+type LogStreamSource interface {
+	Subscribe(filters map[string]string, isAuth bool, scope *ProjectScope) *LogSubscription
+	Unsubscribe(sub *LogSubscription)
+}
+
+// SSEHandler streams live logs as Server-Sent Events, for clients behind
+// proxies that block WebSocket upgrades.
+type SSEHandler struct {
+	hub       LogStreamSource
+	access    *StreamAccess
+	heartbeat time.Duration
+}
+
+// NewSSEHandler creates a new SSE handler on the given hub, streaming each
+// user the logs access allows them.
+func NewSSEHandler(hub LogStreamSource, access *StreamAccess) *SSEHandler {
+	return &SSEHandler{hub: hub, access: access, heartbeat: SSEHeartbeatInterval}
+}
+
+// RegisterSSERoutes registers the SSE endpoint. The routes must already have
+// session authentication applied, which sets user_id.
+func RegisterSSERoutes(routes gin.IRoutes, hub LogStreamSource, access *StreamAccess) {
+	handler := NewSSEHandler(hub, access)
+	routes.GET("/api/logs/stream", handler.HandleStream)
+}
+
+// HandleStream subscribes to the hub and writes each matching log as an
+// "event: log" frame, with an "event: heartbeat" frame when idle.
+// Filters (level, service, tags), authentication and project scope are the
+// same as for the WebSocket endpoint. The subscription ends when the client
+// disconnects.
+func (h *SSEHandler) HandleStream(c *gin.Context) {
+	scope, ok := streamScope(c, h.access)
+	if !ok {
+		return
+	}
+
+	sub := h.hub.Subscribe(parseLogFilterParams(c), true, scope)
+	defer h.hub.Unsubscribe(sub)
+
+	// The stream outlives the server's WriteTimeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil && err != http.ErrNotSupported {
+		log.Printf("SSE: failed to clear write deadline: %v", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx response buffering
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case entry, ok := <-sub.C:
+			if !ok {
+				// Hub shut down; EventSource clients reconnect on their own
+				return
+			}
+			data, err := json.Marshal(entry)
+			if err != nil {
+				log.Printf("SSE: failed to encode log %d: %v", entry.ID, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(c.Writer, "event: log\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			c.Writer.Flush()
+
+		case now := <-heartbeat.C:
+			if _, err := fmt.Fprintf(c.Writer, "event: heartbeat\ndata: %d\n\n", now.Unix()); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
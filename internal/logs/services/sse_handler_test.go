@@ -0,0 +1,152 @@
+package logs_services
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sseTestFixture struct {
+	hub    *WebSocketHub
+	server *httptest.Server
+}
+
+func newSSETestFixture(t *testing.T, heartbeat time.Duration) *sseTestFixture {
+	gin.SetMode(gin.TestMode)
+	hub := NewWebSocketHub(nil)
+	go hub.Run()
+
+	handler := NewSSEHandler(hub, testStreamAccess())
+	handler.heartbeat = heartbeat
+	router := gin.New()
+	router.GET("/api/logs/stream", testSession, handler.HandleStream)
+	server := httptest.NewServer(router)
+
+	t.Cleanup(func() {
+		server.Close()
+		hub.Stop()
+	})
+	return &sseTestFixture{hub: hub, server: server}
+}
+
+// open starts an admin's stream and returns a reader over its lines.
+func (f *sseTestFixture) open(t *testing.T, ctx context.Context, query string) *bufio.Scanner {
+	return f.openAs(t, ctx, streamAdminID, query)
+}
+
+// openAs starts a stream for userID and returns a reader over its lines.
+func (f *sseTestFixture) openAs(t *testing.T, ctx context.Context, userID int, query string) *bufio.Scanner {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.server.URL+"/api/logs/stream?"+query, http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("X-User-ID", strconv.Itoa(userID))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	return bufio.NewScanner(resp.Body)
+}
+
+func (f *sseTestFixture) subscriberCount() int {
+	f.hub.mu.RLock()
+	defer f.hub.mu.RUnlock()
+	return len(f.hub.subscribers)
+}
+
+// nextEvent reads one SSE frame and returns its event name and data.
+func nextEvent(t *testing.T, lines *bufio.Scanner) (event, data string) {
+	for lines.Scan() {
+		line := lines.Text()
+		switch {
+		case line == "":
+			return event, data
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+	t.Fatalf("stream ended: %v", lines.Err())
+	return "", ""
+}
+
+func TestSSEHandler_StreamsFilteredLogs(t *testing.T) {
+	fixture := newSSETestFixture(t, time.Hour)
+	lines := fixture.open(t, context.Background(), "level=ERROR&service=review")
+	require.Eventually(t, func() bool { return fixture.subscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	fixture.hub.broadcast <- &logs_models.LogEntry{Level: "INFO", Service: "review", Message: "skipped level"}
+	fixture.hub.broadcast <- &logs_models.LogEntry{Level: "ERROR", Service: "portal", Message: "skipped service"}
+	fixture.hub.broadcast <- &logs_models.LogEntry{Level: "ERROR", Service: "review", Message: "delivered"}
+
+	event, data := nextEvent(t, lines)
+	assert.Equal(t, "log", event)
+	assert.Contains(t, data, `"message":"delivered"`)
+}
+
+func TestSSEHandler_SendsHeartbeat(t *testing.T) {
+	fixture := newSSETestFixture(t, 20*time.Millisecond)
+	lines := fixture.open(t, context.Background(), "")
+
+	event, _ := nextEvent(t, lines)
+	assert.Equal(t, "heartbeat", event)
+}
+
+func TestSSEHandler_UnsubscribesOnDisconnect(t *testing.T) {
+	fixture := newSSETestFixture(t, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	fixture.open(t, ctx, "")
+	require.Eventually(t, func() bool { return fixture.subscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.Eventually(t, func() bool { return fixture.subscriberCount() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestSSEHandler_RequiresAuthentication(t *testing.T) {
+	fixture := newSSETestFixture(t, time.Hour)
+
+	resp, err := http.Get(fixture.server.URL + "/api/logs/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestSSEHandler_StreamsOnlyOwnProjects(t *testing.T) {
+	fixture := newSSETestFixture(t, time.Hour)
+	lines := fixture.openAs(t, context.Background(), 3, "")
+	require.Eventually(t, func() bool { return fixture.subscriberCount() == 1 }, time.Second, 10*time.Millisecond)
+
+	fixture.hub.broadcast <- projectLog(1, 20)
+	fixture.hub.broadcast <- &logs_models.LogEntry{ID: 2, Level: "ERROR"}
+	fixture.hub.broadcast <- projectLog(3, 30)
+
+	event, data := nextEvent(t, lines)
+	assert.Equal(t, "log", event)
+	assert.Contains(t, data, `"id":3,`, "user 3 gets neither user 2's logs nor logs without a project")
+}
+
+func TestMatchesLogFilters_SameForAllTransports(t *testing.T) {
+	entry := &logs_models.LogEntry{Level: "ERROR", Service: "review", ServiceName: "review-api", Tags: []string{"critical"}}
+
+	assert.True(t, matchesLogFilters(map[string]string{}, entry))
+	assert.True(t, matchesLogFilters(map[string]string{"level": "ERROR", "service": "review", "tags": "critical"}, entry))
+	assert.False(t, matchesLogFilters(map[string]string{"tags": "network"}, entry))
+	assert.True(t, matchesLogFilters(map[string]string{"level": "error", "service": "review-api"}, entry),
+		"level and service match like logs_db.ReplayFilter")
+
+	hub := NewWebSocketHub(nil)
+	filters := map[string]string{"service": "portal"}
+	assert.Equal(t, matchesLogFilters(filters, entry), hub.delivers(true, nil, filters, entry))
+	assert.False(t, hub.delivers(false, nil, map[string]string{}, entry), "unauthenticated streams only get public logs")
+}
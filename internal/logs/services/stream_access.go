@@ -0,0 +1,73 @@
+package logs_services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+)
+
+// OwnedProjectLister lists a user's projects; ProjectRepository implements it.
+type OwnedProjectLister interface {
+	ListByUserID(ctx context.Context, userID int) ([]logs_models.Project, error)
+}
+
+// ProjectScope is the set of projects whose logs a stream may deliver. A
+// nil ProjectScope delivers every project's logs, as admins may read them.
+type ProjectScope struct {
+	projects    map[int64]bool
+	ownerUserID int
+}
+
+// OwnerUserID returns the user whose projects the scope covers, or 0 for
+// every project; it is the owner filter for queries backing the stream.
+func (s *ProjectScope) OwnerUserID() int {
+	if s == nil {
+		return 0
+	}
+	return s.ownerUserID
+}
+
+// Allows reports whether the scope covers the log's project. Logs without
+// a project are never in a user's scope.
+func (s *ProjectScope) Allows(log *logs_models.LogEntry) bool {
+	if s == nil {
+		return true
+	}
+	return log.ProjectID != nil && s.projects[*log.ProjectID]
+}
+
+// StreamAccess limits the WebSocket and SSE log streams to the projects the
+// session user owns, unless they are an admin, the same as log queries.
+type StreamAccess struct {
+	admins   Admins
+	projects OwnedProjectLister
+}
+
+// NewStreamAccess creates a StreamAccess over the given admins and projects.
+func NewStreamAccess(admins Admins, projects OwnedProjectLister) *StreamAccess {
+	return &StreamAccess{admins: admins, projects: projects}
+}
+
+// Scope returns the projects userID may stream. Projects the user creates
+// later are picked up when the stream reconnects.
+func (a *StreamAccess) Scope(ctx context.Context, userID int) (*ProjectScope, error) {
+	if userID <= 0 {
+		return nil, errors.New("log streams require a signed-in user")
+	}
+	owner := a.admins.OwnerScope(userID)
+	if owner == 0 {
+		return nil, nil
+	}
+
+	projects, err := a.projects.ListByUserID(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("list projects of user %d: %w", owner, err)
+	}
+	scope := &ProjectScope{projects: make(map[int64]bool, len(projects)), ownerUserID: owner}
+	for i := range projects {
+		scope.projects[int64(projects[i].ID)] = true
+	}
+	return scope, nil
+}
@@ -0,0 +1,64 @@
+package logs_services
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamAdminID is an admin in testStreamAccess; its streams are unscoped.
+const streamAdminID = 1
+
+// memoryProjectLister maps user IDs to the IDs of the projects they own.
+type memoryProjectLister map[int][]int
+
+func (m memoryProjectLister) ListByUserID(_ context.Context, userID int) ([]logs_models.Project, error) {
+	projects := []logs_models.Project{}
+	for _, id := range m[userID] {
+		projects = append(projects, logs_models.Project{ID: id})
+	}
+	return projects, nil
+}
+
+// testStreamAccess makes user 1 an admin; user 2 owns project 20 and user 3 project 30.
+func testStreamAccess() *StreamAccess {
+	return NewStreamAccess(NewAdmins([]int{streamAdminID}), memoryProjectLister{2: {20}, 3: {30}})
+}
+
+// testSession stands in for the session middleware: an X-User-ID header
+// becomes the user_id it would set.
+func testSession(c *gin.Context) {
+	if id, err := strconv.Atoi(c.GetHeader("X-User-ID")); err == nil {
+		c.Set("user_id", id)
+	}
+}
+
+func projectLog(id, projectID int64) *logs_models.LogEntry {
+	return &logs_models.LogEntry{ID: id, Level: "ERROR", ProjectID: &projectID}
+}
+
+func TestStreamAccess_Scope(t *testing.T) {
+	access := testStreamAccess()
+	ctx := context.Background()
+
+	scope, err := access.Scope(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, scope.OwnerUserID())
+	assert.True(t, scope.Allows(projectLog(1, 20)))
+	assert.False(t, scope.Allows(projectLog(2, 30)), "another user's project")
+	assert.False(t, scope.Allows(&logs_models.LogEntry{ID: 3}), "logs without a project")
+
+	scope, err = access.Scope(ctx, streamAdminID)
+	require.NoError(t, err)
+	assert.Nil(t, scope, "admins stream every project")
+	assert.True(t, scope.Allows(projectLog(2, 30)))
+	assert.Equal(t, 0, scope.OwnerUserID())
+
+	_, err = access.Scope(ctx, 0)
+	assert.Error(t, err, "a stream without a user is never unscoped")
+}
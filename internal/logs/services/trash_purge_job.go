@@ -0,0 +1,139 @@
+package logs_services
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Trash purge metrics, published under /debug/vars.
+var (
+	trashPurgedRows      = expvar.NewInt("logs_trash_rows_purged_total")
+	trashLastRunRows     = expvar.NewInt("logs_trash_last_run_rows_purged")
+	trashLastRunMillis   = expvar.NewInt("logs_trash_last_run_duration_ms")
+	trashPurgeRunsFailed = expvar.NewInt("logs_trash_runs_failed_total")
+)
+
+// ErrTrashPurgeRunning is returned when a purge is requested while one is in progress.
+var ErrTrashPurgeRunning = errors.New("trash purge already in progress")
+
+// TrashPurgeRepository is the storage the trash purge job removes entries from.
+type TrashPurgeRepository interface {
+	PurgeDeletedBatch(ctx context.Context, cutoff time.Time, batchSize int) (int64, error)
+}
+
+// TrashPurgeResult summarizes one purge run.
+type TrashPurgeResult struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+	Purged    int64         `json:"purged"`
+}
+
+// TrashPurgeJob permanently deletes soft-deleted entries once their grace
+// period has elapsed. Until then they can be restored.
+type TrashPurgeJob struct {
+	repo      TrashPurgeRepository
+	logger    *logrus.Logger
+	now       func() time.Time
+	mu        sync.Mutex
+	grace     time.Duration
+	batchSize int
+}
+
+// NewTrashPurgeJob creates a TrashPurgeJob. Non-positive grace or batchSize
+// fall back to DefaultTrashGracePeriod / DefaultRetentionBatchSize.
+func NewTrashPurgeJob(repo TrashPurgeRepository, grace time.Duration, batchSize int, logger *logrus.Logger) *TrashPurgeJob {
+	if grace <= 0 {
+		grace = DefaultTrashGracePeriod
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultRetentionBatchSize
+	}
+	return &TrashPurgeJob{
+		repo:      repo,
+		logger:    logger,
+		now:       time.Now,
+		grace:     grace,
+		batchSize: batchSize,
+	}
+}
+
+// Start runs the job every interval until ctx is cancelled.
+// It returns immediately; cancelling ctx also aborts a run in progress.
+func (j *TrashPurgeJob) Start(ctx context.Context, interval time.Duration) {
+	if j.repo == nil {
+		j.logger.Warn("Trash purge job: repository is nil; purge disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		j.logger.WithFields(logrus.Fields{
+			"grace":    j.grace.String(),
+			"interval": interval.String(),
+		}).Info("Log trash purge job started")
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				j.logger.Info("Log trash purge job stopping due to context cancellation")
+				return
+			case <-ticker.C:
+				if _, err := j.RunOnce(ctx); err != nil && !errors.Is(err, ErrTrashPurgeRunning) {
+					j.logger.WithError(err).Error("Log trash purge run failed")
+				}
+			}
+		}
+	}()
+}
+
+// RunOnce purges every entry deleted more than the grace period ago. Only
+// one pass runs at a time; a concurrent call returns ErrTrashPurgeRunning.
+func (j *TrashPurgeJob) RunOnce(ctx context.Context) (TrashPurgeResult, error) {
+	if !j.mu.TryLock() {
+		return TrashPurgeResult{}, ErrTrashPurgeRunning
+	}
+	defer j.mu.Unlock()
+
+	began := time.Now()
+	result := TrashPurgeResult{StartedAt: j.now()}
+	err := j.purge(ctx, result.StartedAt.Add(-j.grace), &result)
+	result.Duration = time.Since(began)
+
+	trashPurgedRows.Add(result.Purged)
+	trashLastRunRows.Set(result.Purged)
+	trashLastRunMillis.Set(result.Duration.Milliseconds())
+
+	fields := logrus.Fields{
+		"purged":   result.Purged,
+		"duration": result.Duration.String(),
+	}
+	if err != nil {
+		trashPurgeRunsFailed.Add(1)
+		j.logger.WithFields(fields).WithError(err).Warn("Log trash purge stopped early")
+		return result, err
+	}
+	j.logger.WithFields(fields).Info("Log trash purge completed")
+	return result, nil
+}
+
+// purge deletes in batches until a short batch shows nothing is left.
+func (j *TrashPurgeJob) purge(ctx context.Context, cutoff time.Time, result *TrashPurgeResult) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := j.repo.PurgeDeletedBatch(ctx, cutoff, j.batchSize)
+		if err != nil {
+			return err
+		}
+		result.Purged += n
+		if n < int64(j.batchSize) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package logs_services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTrashRepo holds the deletion times of trashed entries.
+type fakeTrashRepo struct {
+	deletedAt []time.Time
+	cutoffs   []time.Time
+}
+
+func (f *fakeTrashRepo) PurgeDeletedBatch(ctx context.Context, cutoff time.Time, batchSize int) (int64, error) {
+	f.cutoffs = append(f.cutoffs, cutoff)
+	kept := f.deletedAt[:0]
+	var purged int64
+	for _, at := range f.deletedAt {
+		if at.Before(cutoff) && purged < int64(batchSize) {
+			purged++
+			continue
+		}
+		kept = append(kept, at)
+	}
+	f.deletedAt = kept
+	return purged, nil
+}
+
+func TestTrashPurgeJob_RunOnce_PurgesPastGrace(t *testing.T) {
+	now := time.Date(2025, 11, 25, 3, 0, 0, 0, time.UTC)
+	repo := &fakeTrashRepo{}
+	for i := 0; i < 12; i++ {
+		repo.deletedAt = append(repo.deletedAt, now.Add(-8*24*time.Hour))
+	}
+	repo.deletedAt = append(repo.deletedAt, now.Add(-time.Hour)) // Still restorable
+	job := NewTrashPurgeJob(repo, 7*24*time.Hour, 5, logrus.New())
+	job.now = func() time.Time { return now }
+
+	result, err := job.RunOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), result.Purged)
+	assert.Len(t, repo.deletedAt, 1)
+	// 5, 5, 2
+	require.Len(t, repo.cutoffs, 3)
+	assert.Equal(t, now.AddDate(0, 0, -7), repo.cutoffs[0])
+}
+
+func TestTrashPurgeJob_RunOnce_RejectsConcurrentRun(t *testing.T) {
+	job := NewTrashPurgeJob(&fakeTrashRepo{}, 0, 0, logrus.New())
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	_, err := job.RunOnce(context.Background())
+
+	assert.ErrorIs(t, err, ErrTrashPurgeRunning)
+}
+
+func TestNewTrashPurgeJob_Defaults(t *testing.T) {
+	job := NewTrashPurgeJob(&fakeTrashRepo{}, 0, 0, logrus.New())
+	assert.Equal(t, DefaultTrashGracePeriod, job.grace)
+	assert.Equal(t, DefaultRetentionBatchSize, job.batchSize)
+}
@@ -3,8 +3,10 @@
 package logs_services
 
 import (
+	"fmt"
+	"log"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,18 +16,21 @@ import (
 
 // WebSocketHandler handles HTTP to WebSocket upgrade and connection setup.
 type WebSocketHandler struct {
-	hub *WebSocketHub
+	hub    *WebSocketHub
+	access *StreamAccess
 }
 
-// NewWebSocketHandler creates a new WebSocket handler with the given hub.
-func NewWebSocketHandler(hub *WebSocketHub) *WebSocketHandler {
-	return &WebSocketHandler{hub: hub}
+// NewWebSocketHandler creates a new WebSocket handler with the given hub,
+// streaming each user the logs access allows them.
+func NewWebSocketHandler(hub *WebSocketHub, access *StreamAccess) *WebSocketHandler {
+	return &WebSocketHandler{hub: hub, access: access}
 }
 
-// RegisterWebSocketRoutes registers the WebSocket endpoint on a Gin router.
-func RegisterWebSocketRoutes(router *gin.Engine, hub *WebSocketHub) {
-	handler := NewWebSocketHandler(hub)
-	router.GET("/ws/logs", handler.HandleWebSocket)
+// RegisterWebSocketRoutes registers the WebSocket endpoint. The routes must
+// already have session authentication applied, which sets user_id.
+func RegisterWebSocketRoutes(routes gin.IRoutes, hub *WebSocketHub, access *StreamAccess) {
+	handler := NewWebSocketHandler(hub, access)
+	routes.GET("/ws/logs", handler.HandleWebSocket)
 }
 
 // HandleWebSocket upgrades an HTTP connection to WebSocket and registers the client.
@@ -33,23 +38,26 @@ func RegisterWebSocketRoutes(router *gin.Engine, hub *WebSocketHub) {
 //   - level: Log level filter (e.g., ERROR, WARN, INFO)
 //   - service: Service name filter (e.g., portal, review)
 //   - tags: Tag filter (exact match, single tag)
+//   - since: Last log ID the client saw; missed logs after it are replayed
+//     before live streaming starts (requires a replay source on the hub)
 //
-// Authentication is checked via the Authorization header (Bearer token).
-// Unauthenticated connections are rejected with HTTP 401.
+// Connections without a session user are rejected with HTTP 401. Live and
+// replayed logs are limited to the user's projects unless they are an admin.
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
-	// Parse and validate authentication
-	authHeader := c.GetHeader("Authorization")
-	isAuthenticated := h.validateAuth(authHeader)
-
-	// Require authentication - reject unauthenticated connections
-	if !isAuthenticated {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+	scope, ok := streamScope(c, h.access)
+	if !ok {
 		return
 	}
 
 	// Parse filter parameters from query string
 	filters := h.parseFilterParams(c)
 
+	since, replay, err := parseSinceParam(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Upgrade HTTP connection to WebSocket
 	upgrader := websocket.Upgrader{
 		CheckOrigin:     func(r *http.Request) bool { return true },
@@ -68,6 +76,7 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		Conn:         conn,
 		Send:         make(chan *logs_models.LogEntry, 256),
 		Filters:      filters,
+		Scope:        scope,
 		IsAuth:       true, // Always true since we reject unauthenticated above
 		IsPublic:     false,
 		LastActivity: time.Now(),
@@ -77,11 +86,10 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		done: make(chan struct{}),
 	}
 
-	// Register client with hub and start message pumps
+	// Register client with hub and start message pumps. Live logs queue in
+	// Send from here on, so nothing emitted during a replay is lost.
 	h.hub.Register(client)
-	// Start pumps
 	go client.ReadPump(h.hub)
-	go client.WritePump(h.hub)
 
 	// Wait for hub registration to complete to avoid races where tests
 	// broadcast immediately after dialing and the hub hasn't yet added
@@ -95,33 +103,63 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 			// timed out; continue anyway
 		}
 	}
-}
 
-// validateAuth checks if authentication header contains a valid Bearer token.
-// Returns true if a valid Bearer token is present, false otherwise.
-// Does NOT validate JWT signature (placeholder for future JWT validation).
-func (h *WebSocketHandler) validateAuth(authHeader string) bool {
-	if authHeader == "" {
-		return false
+	if replay && h.hub.replaySource != nil {
+		through, err := h.hub.replay(c.Request.Context(), client, since)
+		if err != nil {
+			// The live stream is still worth having without the catch-up
+			log.Printf("WebSocket replay after log %d failed: %v", since, err)
+		}
+		client.replayedThrough = through
 	}
+	go client.WritePump(h.hub)
+}
 
-	// Check for Bearer token format
-	if !strings.HasPrefix(authHeader, "Bearer ") {
-		return false
+// parseSinceParam reads the optional since query parameter, a log ID.
+func parseSinceParam(c *gin.Context) (since int64, ok bool, err error) {
+	raw, ok := c.GetQuery("since")
+	if !ok {
+		return 0, false, nil
+	}
+	since, err = strconv.ParseInt(raw, 10, 64)
+	if err != nil || since < 0 {
+		return 0, false, fmt.Errorf("since must be a non-negative log ID")
 	}
+	return since, true, nil
+}
 
-	token := strings.TrimPrefix(authHeader, "Bearer ")
-	if token == "" {
-		return false
+// streamScope returns the project scope of the session user set by the auth
+// middleware. Without one it responds with an error and returns false.
+func streamScope(c *gin.Context, access *StreamAccess) (*ProjectScope, bool) {
+	userIDValue, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return nil, false
+	}
+	userID, ok := userIDValue.(int)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID type"})
+		return nil, false
 	}
 
-	// Reject explicitly expired tokens (placeholder for full JWT validation)
-	return token != "expired_token"
+	scope, err := access.Scope(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("Log stream: failed to load projects of user %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load projects"})
+		return nil, false
+	}
+	return scope, true
 }
 
 // parseFilterParams extracts and returns filter parameters from the request query string.
 // Supports: level, service, tags
 func (h *WebSocketHandler) parseFilterParams(c *gin.Context) map[string]string {
+	return parseLogFilterParams(c)
+}
+
+// parseLogFilterParams reads the level, service and tags query parameters
+// shared by the WebSocket and SSE endpoints.
+func parseLogFilterParams(c *gin.Context) map[string]string {
 	filters := make(map[string]string)
 
 	if level := c.Query("level"); level != "" {
@@ -956,7 +956,7 @@ func newWSTestFixture(t *testing.T) *wsTestFixture {
 	_ = os.Setenv("LOGS_WEBSOCKET_PUBLIC_ALL", "1")
 
 	// Create isolated hub for this test
-	hub := NewWebSocketHub()
+	hub := NewWebSocketHub(nil)
 	go hub.Run()
 
 	// DEPRECATED: Set global for backward compatibility with unmigrated tests
@@ -1156,7 +1156,7 @@ func handleWebSocketLogsConnection(w http.ResponseWriter, r *http.Request, hub *
 func setupAuthenticatedWebSocketServer(t *testing.T) http.Handler {
 	// Create a hub specifically for authenticated tests so test code can
 	// publish via currentTestHub.broadcast.
-	hub := NewWebSocketHub()
+	hub := NewWebSocketHub(nil)
 	go hub.Run()
 	currentTestHub = hub
 
@@ -1180,7 +1180,7 @@ func setupAuthenticatedWebSocketServer(t *testing.T) http.Handler {
 }
 
 func setupPublicWebSocketServer() http.Handler {
-	hub := NewWebSocketHub()
+	hub := NewWebSocketHub(nil)
 	go hub.Run()
 	currentTestHub = hub
 
@@ -1310,7 +1310,7 @@ func setupWebSocketWithRedis(t *testing.T, redis interface{}) http.Handler {
 
 	// Create hub and wire it to the in-memory pubsub
 	// The hub will receive cross-instance messages from pub.Subscribe()
-	hub := NewWebSocketHub()
+	hub := NewWebSocketHub(nil)
 	go hub.Run()
 	currentTestHub = hub
 
@@ -3,25 +3,68 @@ package logs_services
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/redis/go-redis/v9"
 )
 
+// DropPolicy decides which log is lost when a consumer's queue is full.
+type DropPolicy string
+
+// Drop policies
+const (
+	DropNewest DropPolicy = "drop_newest" // Discard the incoming log (default)
+	DropOldest DropPolicy = "drop_oldest" // Discard the oldest queued log to make room
+)
+
+// ParseDropPolicy parses a DropPolicy name; empty means DropNewest.
+func ParseDropPolicy(s string) (DropPolicy, error) {
+	switch DropPolicy(s) {
+	case "", DropNewest:
+		return DropNewest, nil
+	case DropOldest:
+		return DropOldest, nil
+	default:
+		return "", fmt.Errorf("unknown drop policy %q (want %s or %s)", s, DropNewest, DropOldest)
+	}
+}
+
+// HubStats is a snapshot of the hub's delivery health.
+type HubStats struct {
+	DropPolicy     DropPolicy `json:"drop_policy"`
+	Clients        int        `json:"clients"`          // Connected WebSocket clients
+	Subscribers    int        `json:"subscribers"`      // Other streams, e.g. SSE
+	Dropped        uint64     `json:"dropped"`          // Logs dropped on full queues since start
+	QueueHighWater int        `json:"queue_high_water"` // Deepest any consumer queue has been
+}
+
 // WebSocketHub manages WebSocket clients and broadcasts log entries to them.
 // It handles client registration, unregistration, filtering, and heartbeat management.
 // nolint:govet // Field order optimized for performance, not memory alignment
 type WebSocketHub struct {
 	clients         map[*Client]bool
+	subscribers     map[*LogSubscription]bool // Non-WebSocket consumers, e.g. SSE streams
 	broadcast       chan *logs_models.LogEntry
 	analysisResults chan *AnalysisNotification // Phase 1: AI analysis notifications
 	register        chan *Client
 	unregister      chan *Client
 	stop            chan struct{}
+	redis           redis.UniversalClient // nil = this replica's clients only
+	instanceID      string                // Identifies this hub's pub/sub messages
+	replaySource    LogReplaySource       // nil = no ?since replay
+	maxReplay       int
+	dropPolicy      DropPolicy
+	dropped         atomic.Uint64
+	highWater       atomic.Int64
 	mu              sync.RWMutex
 }
 
@@ -42,13 +85,20 @@ type Client struct {
 	Conn         *websocket.Conn
 	Send         chan *logs_models.LogEntry
 	Filters      map[string]string
+	// Scope limits the client to its user's projects; nil is every project
+	Scope *ProjectScope
 	// Registered channel is closed by the hub after the client
 	// has been added to the active clients map. Tests wait on this
 	// to ensure registration is complete before sending messages.
 	Registered chan struct{}
 	// done channel signals WritePump to exit gracefully
 	done chan struct{}
-	mu   sync.Mutex
+	// Dropped counts logs this client lost because Send was full
+	Dropped atomic.Uint64
+	// replayedThrough is the last log ID replayed on connect; WritePump
+	// skips queued live logs up to it. Set before WritePump starts.
+	replayedThrough int64
+	mu              sync.Mutex
 	// writeMu serializes concurrent writes to the websocket connection
 	writeMu  sync.Mutex
 	IsAuth   bool
@@ -58,14 +108,78 @@ type Client struct {
 }
 
 // NewWebSocketHub creates and returns a new WebSocketHub instance.
-func NewWebSocketHub() *WebSocketHub {
+// With a Redis client, logs passed to Broadcast reach the clients of every
+// replica sharing that Redis; with nil, only this replica's clients.
+func NewWebSocketHub(redisClient redis.UniversalClient) *WebSocketHub {
 	return &WebSocketHub{
 		clients:         make(map[*Client]bool),
+		subscribers:     make(map[*LogSubscription]bool),
 		broadcast:       make(chan *logs_models.LogEntry, 256),
 		analysisResults: make(chan *AnalysisNotification, 128),
 		register:        make(chan *Client),
 		unregister:      make(chan *Client),
 		stop:            make(chan struct{}),
+		redis:           redisClient,
+		instanceID:      uuid.NewString(),
+		dropPolicy:      DropNewest,
+	}
+}
+
+// SetDropPolicy sets what happens when a consumer's queue is full.
+// Call it before Run.
+func (h *WebSocketHub) SetDropPolicy(policy DropPolicy) {
+	h.dropPolicy = policy
+}
+
+// Stats returns a snapshot of connected consumers and drop counters.
+func (h *WebSocketHub) Stats() HubStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return HubStats{
+		DropPolicy:     h.dropPolicy,
+		Clients:        len(h.clients),
+		Subscribers:    len(h.subscribers),
+		Dropped:        h.dropped.Load(),
+		QueueHighWater: int(h.highWater.Load()),
+	}
+}
+
+// enqueue offers log to a consumer queue without blocking, applying the
+// drop policy when it is full. It reports whether a log was dropped.
+func (h *WebSocketHub) enqueue(queue chan *logs_models.LogEntry, log *logs_models.LogEntry) (dropped bool) {
+	select {
+	case queue <- log:
+		h.recordDepth(len(queue))
+		return false
+	default:
+	}
+
+	h.dropped.Add(1)
+	if h.dropPolicy != DropOldest {
+		return true
+	}
+
+	// Make room by discarding the oldest queued log. The consumer may have
+	// drained the queue meanwhile, so neither step is allowed to block.
+	select {
+	case <-queue:
+	default:
+	}
+	select {
+	case queue <- log:
+		h.recordDepth(len(queue))
+	default:
+	}
+	return true
+}
+
+// recordDepth raises the queue high-water mark to depth if it is higher.
+func (h *WebSocketHub) recordDepth(depth int) {
+	for {
+		current := h.highWater.Load()
+		if int64(depth) <= current || h.highWater.CompareAndSwap(current, int64(depth)) {
+			return
+		}
 	}
 }
 
@@ -77,10 +191,21 @@ func NewWebSocketHub() *WebSocketHub {
 //   - broadcast: routes log entry to matching clients
 //   - heartbeat tick: sends ping to clients, disconnects inactive ones
 //   - stop: signal to shut down the hub gracefully
+//
+// With Redis configured it also relays logs broadcast by other replicas.
 func (h *WebSocketHub) Run() {
 	heartbeatTicker := time.NewTicker(30 * time.Second)
 	defer heartbeatTicker.Stop()
 
+	if h.redis != nil {
+		pubsub := h.subscribeRemote()
+		defer func() {
+			if err := pubsub.Close(); err != nil {
+				log.Printf("Error closing Redis pub/sub: %v", err)
+			}
+		}()
+	}
+
 	for {
 		select {
 		case <-h.stop:
@@ -90,6 +215,10 @@ func (h *WebSocketHub) Run() {
 				close(client.Send)
 			}
 			h.clients = make(map[*Client]bool)
+			for sub := range h.subscribers {
+				close(sub.ch)
+			}
+			h.subscribers = make(map[*LogSubscription]bool)
 			h.mu.Unlock()
 			return
 
@@ -150,28 +279,26 @@ func (h *WebSocketHub) broadcastToClients(log *logs_models.LogEntry) {
 	defer h.mu.RUnlock()
 
 	for client := range h.clients {
-		// Check visibility: authenticated users see all, unauthenticated see only public
-		if !client.IsAuth && !h.isPublicLog(log) {
+		if !h.delivers(client.IsAuth, client.Scope, client.Filters, log) {
 			continue
 		}
 
-		// Check filters
-		if !h.matchesFilters(client, log) {
+		// Never block the hub on one slow client: a full queue costs that
+		// client a log (per the drop policy), not everyone else their stream
+		if h.enqueue(client.Send, log) {
+			client.Dropped.Add(1)
 			continue
 		}
+		client.mu.Lock()
+		client.LastActivity = time.Now()
+		client.mu.Unlock()
+	}
 
-		// Attempt to send with backpressure handling
-		select {
-		case client.Send <- log:
-			client.mu.Lock()
-			client.LastActivity = time.Now()
-			client.mu.Unlock()
-		default:
-			// Backpressure: client buffer full, close connection
-			h.mu.RUnlock()
-			go h.closeClient(client)
-			h.mu.RLock()
+	for sub := range h.subscribers {
+		if !h.delivers(sub.isAuth, sub.scope, sub.filters, log) {
+			continue
 		}
+		h.enqueue(sub.ch, log)
 	}
 }
 
@@ -328,22 +455,39 @@ func (h *WebSocketHub) closeClient(client *Client) {
 	h.mu.Unlock()
 }
 
-// matchesFilters checks if a log entry matches all filters set by a client.
-// Returns true only if the log matches ALL active filters (AND logic).
-func (h *WebSocketHub) matchesFilters(client *Client, log *logs_models.LogEntry) bool {
+// delivers reports whether log goes to a stream with the given
+// authentication state, project scope and filters: authenticated streams see
+// every log in their scope, unauthenticated ones only public logs. Live
+// broadcasts and replays both go through it, so a reconnecting client gets
+// what it would have seen live.
+func (h *WebSocketHub) delivers(isAuth bool, scope *ProjectScope, filters map[string]string, log *logs_models.LogEntry) bool {
+	if !isAuth && !h.isPublicLog(log) {
+		return false
+	}
+	if !scope.Allows(log) {
+		return false
+	}
+	return matchesLogFilters(filters, log)
+}
+
+// matchesLogFilters applies level/service/tags filters (AND logic). It is
+// shared by every transport so WebSocket and SSE streams behave the same.
+// Levels compare case-insensitively and service matches either the service
+// or the service_name of the log, as logs_db.ReplayFilter does.
+func matchesLogFilters(filters map[string]string, log *logs_models.LogEntry) bool {
 	// Check level filter
-	if level, ok := client.Filters["level"]; ok && level != log.Level {
+	if level, ok := filters["level"]; ok && !strings.EqualFold(level, log.Level) {
 		return false
 	}
 
 	// Check service filter
-	if service, ok := client.Filters["service"]; ok && service != log.Service {
+	if service, ok := filters["service"]; ok && service != log.Service && service != log.ServiceName {
 		return false
 	}
 
 	// Check tags filter
-	if tagFilter, ok := client.Filters["tags"]; ok {
-		if !h.logHasTag(log, tagFilter) {
+	if tagFilter, ok := filters["tags"]; ok {
+		if !logHasTag(log, tagFilter) {
 			return false
 		}
 	}
@@ -352,7 +496,7 @@ func (h *WebSocketHub) matchesFilters(client *Client, log *logs_models.LogEntry)
 }
 
 // logHasTag checks if a log entry contains a specific tag.
-func (h *WebSocketHub) logHasTag(log *logs_models.LogEntry, tag string) bool {
+func logHasTag(log *logs_models.LogEntry, tag string) bool {
 	if log.Tags == nil {
 		return false
 	}
@@ -378,6 +522,40 @@ func (h *WebSocketHub) Register(client *Client) {
 	h.register <- client
 }
 
+// LogSubscription receives broadcast logs for a transport other than
+// WebSocket. A full queue loses logs per the hub's drop policy, as for
+// WebSocket clients. C is closed by Unsubscribe or Stop.
+type LogSubscription struct {
+	C       <-chan *logs_models.LogEntry
+	ch      chan *logs_models.LogEntry
+	filters map[string]string
+	scope   *ProjectScope
+	isAuth  bool
+}
+
+// Subscribe registers a subscriber that receives the logs in scope matching
+// filters (same keys as Client.Filters). Unauthenticated subscribers only
+// receive public logs.
+func (h *WebSocketHub) Subscribe(filters map[string]string, isAuth bool, scope *ProjectScope) *LogSubscription {
+	ch := make(chan *logs_models.LogEntry, 256)
+	sub := &LogSubscription{C: ch, ch: ch, filters: filters, scope: scope, isAuth: isAuth}
+
+	h.mu.Lock()
+	h.subscribers[sub] = true
+	h.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its channel. Safe to call more than once.
+func (h *WebSocketHub) Unsubscribe(sub *LogSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
 // WritePump sends messages from the client's Send channel to the WebSocket connection.
 // It runs in its own goroutine for each client and closes when the connection is lost.
 func (c *Client) WritePump(hub *WebSocketHub) {
@@ -403,6 +581,9 @@ func (c *Client) WritePump(hub *WebSocketHub) {
 				// Send channel closed, exit gracefully
 				return
 			}
+			if log.ID > 0 && log.ID <= c.replayedThrough {
+				continue // Already sent by the reconnect replay
+			}
 			// Serialize writes to avoid concurrent WriteMessage/WriteJSON calls
 			c.writeMu.Lock()
 			if err := c.Conn.WriteJSON(log); err != nil {
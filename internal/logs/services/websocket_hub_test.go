@@ -0,0 +1,86 @@
+package logs_services
+
+import (
+	"fmt"
+	"testing"
+
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStalledClient registers a client whose queue is never drained.
+func newStalledClient(hub *WebSocketHub, queue int) *Client {
+	client := &Client{Send: make(chan *logs_models.LogEntry, queue), IsAuth: true, Filters: map[string]string{}}
+	hub.clients[client] = true
+	return client
+}
+
+func broadcastN(hub *WebSocketHub, n int) {
+	for i := 0; i < n; i++ {
+		hub.broadcastToClients(&logs_models.LogEntry{Level: "INFO", Message: fmt.Sprintf("msg %d", i)})
+	}
+}
+
+func drain(queue chan *logs_models.LogEntry) []string {
+	var messages []string
+	for len(queue) > 0 {
+		messages = append(messages, (<-queue).Message)
+	}
+	return messages
+}
+
+func TestWebSocketHub_CountsDropsUnderBackpressure(t *testing.T) {
+	hub := NewWebSocketHub(nil)
+	slow := newStalledClient(hub, 2)
+	fast := newStalledClient(hub, 10)
+
+	broadcastN(hub, 5)
+
+	assert.Equal(t, uint64(3), slow.Dropped.Load())
+	assert.Equal(t, uint64(0), fast.Dropped.Load())
+	assert.Equal(t, []string{"msg 0", "msg 1"}, drain(slow.Send), "drop newest keeps the queued logs")
+	assert.Len(t, fast.Send, 5, "a slow client must not hold back the others")
+
+	stats := hub.Stats()
+	assert.Equal(t, 2, stats.Clients)
+	assert.Equal(t, uint64(3), stats.Dropped)
+	assert.Equal(t, 5, stats.QueueHighWater)
+	assert.Equal(t, DropNewest, stats.DropPolicy)
+}
+
+func TestWebSocketHub_DropOldest(t *testing.T) {
+	hub := NewWebSocketHub(nil)
+	hub.SetDropPolicy(DropOldest)
+	slow := newStalledClient(hub, 2)
+
+	broadcastN(hub, 5)
+
+	assert.Equal(t, uint64(3), slow.Dropped.Load())
+	assert.Equal(t, []string{"msg 3", "msg 4"}, drain(slow.Send), "drop oldest keeps the newest logs")
+	assert.Equal(t, uint64(3), hub.Stats().Dropped)
+}
+
+func TestWebSocketHub_SubscribersShareDropPolicy(t *testing.T) {
+	hub := NewWebSocketHub(nil)
+	sub := hub.Subscribe(map[string]string{}, true, nil)
+	defer hub.Unsubscribe(sub)
+
+	broadcastN(hub, cap(sub.ch)+4)
+
+	assert.Equal(t, uint64(4), hub.Stats().Dropped)
+	assert.Equal(t, 1, hub.Stats().Subscribers)
+}
+
+func TestParseDropPolicy(t *testing.T) {
+	policy, err := ParseDropPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, DropNewest, policy)
+
+	policy, err = ParseDropPolicy("drop_oldest")
+	require.NoError(t, err)
+	assert.Equal(t, DropOldest, policy)
+
+	_, err = ParseDropPolicy("block")
+	assert.Error(t, err)
+}
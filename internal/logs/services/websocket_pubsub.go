@@ -0,0 +1,84 @@
+package logs_services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// HubPubSubChannel is the Redis channel replicas use to share broadcast logs.
+const HubPubSubChannel = "logs:stream:broadcast"
+
+// hubPublishTimeout bounds how long Broadcast waits on Redis.
+const hubPublishTimeout = 2 * time.Second
+
+// hubEnvelope is the pub/sub payload. Origin is the publishing hub's
+// instance id, so a hub can ignore its own messages.
+type hubEnvelope struct {
+	Entry  *logs_models.LogEntry `json:"entry"`
+	Origin string                `json:"origin"`
+}
+
+// Broadcast delivers entry to this hub's clients and, when the hub has a
+// Redis client, publishes it so every other replica delivers it to theirs.
+func (h *WebSocketHub) Broadcast(entry *logs_models.LogEntry) {
+	select {
+	case h.broadcast <- entry:
+	default:
+		h.dropped.Add(1)
+		log.Printf("Warning: broadcast channel full, dropping log %d", entry.ID)
+	}
+
+	if h.redis == nil {
+		return
+	}
+	payload, err := json.Marshal(hubEnvelope{Entry: entry, Origin: h.instanceID})
+	if err != nil {
+		log.Printf("Failed to encode log %d for pub/sub: %v", entry.ID, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hubPublishTimeout)
+	defer cancel()
+	if err := h.redis.Publish(ctx, HubPubSubChannel, payload).Err(); err != nil {
+		log.Printf("Failed to publish log %d to other replicas: %v", entry.ID, err)
+	}
+}
+
+// subscribeRemote subscribes to the replica channel and relays messages from
+// other hubs into the local broadcast channel until the returned PubSub is
+// closed. Relayed entries never reach Broadcast, so they are not published
+// again; together with the origin check this keeps replicas from echoing.
+func (h *WebSocketHub) subscribeRemote() *redis.PubSub {
+	pubsub := h.redis.Subscribe(context.Background(), HubPubSubChannel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), hubPublishTimeout)
+	defer cancel()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		// go-redis keeps retrying in the background; until then only local clients get logs
+		log.Printf("Warning: Redis pub/sub subscribe failed, cross-replica broadcast delayed: %v", err)
+	}
+
+	go func(messages <-chan *redis.Message) {
+		for msg := range messages {
+			var env hubEnvelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil || env.Entry == nil {
+				log.Printf("Ignoring malformed pub/sub log message: %v", err)
+				continue
+			}
+			if env.Origin == h.instanceID {
+				continue // Already delivered locally by Broadcast
+			}
+			select {
+			case h.broadcast <- env.Entry:
+			case <-h.stop:
+				return
+			}
+		}
+	}(pubsub.Channel())
+
+	return pubsub
+}
@@ -0,0 +1,66 @@
+package logs_services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startRedisHub starts a hub backed by mr and returns it with a subscription.
+func startRedisHub(t *testing.T, mr *miniredis.Miniredis) (*WebSocketHub, *LogSubscription) {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	hub := NewWebSocketHub(client)
+	sub := hub.Subscribe(map[string]string{}, true, nil)
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+	return hub, sub
+}
+
+func receiveAll(sub *LogSubscription, wait time.Duration) []string {
+	var messages []string
+	timeout := time.After(wait)
+	for {
+		select {
+		case entry := <-sub.C:
+			messages = append(messages, entry.Message)
+		case <-timeout:
+			return messages
+		}
+	}
+}
+
+func TestWebSocketHub_RedisBroadcastReachesOtherReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	hubA, subA := startRedisHub(t, mr)
+	_, subB := startRedisHub(t, mr)
+
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub(HubPubSubChannel)[HubPubSubChannel] == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	hubA.Broadcast(&logs_models.LogEntry{ID: 1, Level: "ERROR", Message: "disk full"})
+
+	// Each replica delivers the log exactly once: the origin never re-delivers
+	// its own echo, and the receiver never publishes it back
+	assert.Equal(t, []string{"disk full"}, receiveAll(subA, 300*time.Millisecond))
+	assert.Equal(t, []string{"disk full"}, receiveAll(subB, 300*time.Millisecond))
+}
+
+func TestWebSocketHub_NilRedisIsLocalOnly(t *testing.T) {
+	hub := NewWebSocketHub(nil)
+	sub := hub.Subscribe(map[string]string{}, true, nil)
+	go hub.Run()
+	defer hub.Stop()
+
+	hub.Broadcast(&logs_models.LogEntry{ID: 1, Level: "INFO", Message: "local"})
+
+	assert.Equal(t, []string{"local"}, receiveAll(sub, 100*time.Millisecond))
+}
@@ -0,0 +1,83 @@
+package logs_services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+)
+
+// DefaultMaxReplay caps how many missed logs a reconnecting client is sent.
+const DefaultMaxReplay = 500
+
+// replayTimeout bounds the lookup of missed logs on connect.
+const replayTimeout = 5 * time.Second
+
+// ReplayTruncatedEvent is sent ahead of a replay that had to skip older logs.
+type ReplayTruncatedEvent struct {
+	Event string `json:"event"` // Always "replay_truncated"
+}
+
+// LogReplaySource looks up logs a reconnecting client missed, newest first.
+type LogReplaySource interface {
+	FindAfterID(ctx context.Context, afterID int64, filter logs_db.ReplayFilter, limit int) ([]logs_models.LogEntry, error)
+}
+
+// SetReplaySource lets clients connecting with ?since=<log_id> catch up on
+// logs they missed. At most maxReplay logs are replayed; non-positive means
+// DefaultMaxReplay. Call it before serving connections.
+func (h *WebSocketHub) SetReplaySource(source LogReplaySource, maxReplay int) {
+	if maxReplay <= 0 {
+		maxReplay = DefaultMaxReplay
+	}
+	h.replaySource = source
+	h.maxReplay = maxReplay
+}
+
+// replay writes logs after since that the client would have received live
+// directly to its connection, oldest first, before its WritePump starts. The
+// query narrows by the client's filters and project scope, and every entry is then checked with
+// delivers, the same as a live broadcast. When more than maxReplay were
+// missed only the newest are sent, preceded by a ReplayTruncatedEvent so the
+// client knows there is a gap. It returns the highest ID replayed, so live
+// logs queued meanwhile are not sent twice.
+func (h *WebSocketHub) replay(ctx context.Context, client *Client, since int64) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, replayTimeout)
+	defer cancel()
+
+	filter := logs_db.ReplayFilter{
+		Level:       client.Filters["level"],
+		Service:     client.Filters["service"],
+		Tag:         client.Filters["tags"],
+		OwnerUserID: client.Scope.OwnerUserID(),
+	}
+	entries, err := h.replaySource.FindAfterID(ctx, since, filter, h.maxReplay+1)
+	if err != nil {
+		return since, fmt.Errorf("load missed logs: %w", err)
+	}
+
+	client.writeMu.Lock()
+	defer client.writeMu.Unlock()
+
+	if len(entries) > h.maxReplay {
+		entries = entries[:h.maxReplay]
+		if err := client.Conn.WriteJSON(ReplayTruncatedEvent{Event: "replay_truncated"}); err != nil {
+			return since, fmt.Errorf("write replay marker: %w", err)
+		}
+	}
+
+	through := since
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !h.delivers(client.IsAuth, client.Scope, client.Filters, &entries[i]) {
+			through = entries[i].ID
+			continue
+		}
+		if err := client.Conn.WriteJSON(&entries[i]); err != nil {
+			return through, fmt.Errorf("write replayed log %d: %w", entries[i].ID, err)
+		}
+		through = entries[i].ID
+	}
+	return through, nil
+}
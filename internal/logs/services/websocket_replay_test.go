@@ -0,0 +1,175 @@
+package logs_services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	logs_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/db"
+	logs_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryReplaySource serves stored logs the way the repository does.
+type memoryReplaySource struct {
+	entries    []logs_models.LogEntry // Ascending ID
+	onFind     func()
+	lastFilter logs_db.ReplayFilter
+}
+
+func (m *memoryReplaySource) FindAfterID(_ context.Context, afterID int64, filter logs_db.ReplayFilter, limit int) ([]logs_models.LogEntry, error) {
+	m.lastFilter = filter
+	if m.onFind != nil {
+		m.onFind()
+	}
+	result := []logs_models.LogEntry{}
+	for i := len(m.entries) - 1; i >= 0 && len(result) < limit; i-- {
+		e := m.entries[i]
+		if e.ID > afterID && (filter.Level == "" || strings.EqualFold(e.Level, filter.Level)) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func storedLogs(n int) []logs_models.LogEntry {
+	entries := make([]logs_models.LogEntry, n)
+	for i := range entries {
+		entries[i] = logs_models.LogEntry{ID: int64(i + 1), Level: "ERROR", Message: "stored"}
+	}
+	return entries
+}
+
+func newReplayServer(t *testing.T, source LogReplaySource, maxReplay int) (*WebSocketHub, string) {
+	gin.SetMode(gin.TestMode)
+	hub := NewWebSocketHub(nil)
+	hub.SetReplaySource(source, maxReplay)
+	go hub.Run()
+
+	router := gin.New()
+	RegisterWebSocketRoutes(router.Group("", testSession), hub, testStreamAccess())
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		server.Close()
+		hub.Stop()
+	})
+	return hub, "ws" + strings.TrimPrefix(server.URL, "http") + wsLogsPath
+}
+
+// dialReplay connects as an admin, whose stream covers every project.
+func dialReplay(t *testing.T, url string) *websocket.Conn {
+	return dialReplayAs(t, url, streamAdminID)
+}
+
+func dialReplayAs(t *testing.T, url string, userID int) *websocket.Conn {
+	header := http.Header{"X-User-ID": []string{strconv.Itoa(userID)}}
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readFrames reads n JSON frames, returning log IDs, or the event name for markers.
+func readFrames(t *testing.T, conn *websocket.Conn, n int) []interface{} {
+	var frames []interface{}
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	for i := 0; i < n; i++ {
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		var frame map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &frame))
+		if event, ok := frame["event"]; ok {
+			frames = append(frames, event)
+		} else {
+			frames = append(frames, int64(frame["id"].(float64)))
+		}
+	}
+	return frames
+}
+
+func TestWebSocketReplay_SendsMissedLogsInOrderThenLive(t *testing.T) {
+	source := &memoryReplaySource{entries: storedLogs(5)}
+	hub, url := newReplayServer(t, source, 0)
+	// Log 5 is also broadcast live while the replay runs; it must arrive once
+	source.onFind = func() { hub.broadcast <- &logs_models.LogEntry{ID: 5, Level: "ERROR"} }
+
+	conn := dialReplay(t, url+"?since=2")
+	hub.broadcast <- &logs_models.LogEntry{ID: 6, Level: "ERROR"}
+
+	assert.Equal(t, []interface{}{int64(3), int64(4), int64(5), int64(6)}, readFrames(t, conn, 4))
+}
+
+func TestWebSocketReplay_TruncatesToNewest(t *testing.T) {
+	_, url := newReplayServer(t, &memoryReplaySource{entries: storedLogs(5)}, 2)
+
+	conn := dialReplay(t, url+"?since=0")
+
+	assert.Equal(t, []interface{}{"replay_truncated", int64(4), int64(5)}, readFrames(t, conn, 3))
+}
+
+func TestWebSocketReplay_AppliesFilters(t *testing.T) {
+	source := &memoryReplaySource{entries: storedLogs(3)}
+	source.entries[1].Level = "INFO"
+	_, url := newReplayServer(t, source, 0)
+
+	conn := dialReplay(t, url+"?since=0&level=ERROR")
+
+	assert.Equal(t, []interface{}{int64(1), int64(3)}, readFrames(t, conn, 2))
+}
+
+func TestWebSocketReplay_FiltersLikeLiveBroadcast(t *testing.T) {
+	source := &memoryReplaySource{entries: storedLogs(3)}
+	source.entries[1].Service = "external"
+	source.entries[1].ServiceName = "billing"
+	hub, url := newReplayServer(t, source, 0)
+
+	conn := dialReplay(t, url+"?since=0&service=billing&level=error")
+	hub.broadcast <- &logs_models.LogEntry{ID: 4, Level: "ERROR", Service: "portal"}
+	hub.broadcast <- &logs_models.LogEntry{ID: 5, Level: "ERROR", Service: "external", ServiceName: "billing"}
+
+	assert.Equal(t, []interface{}{int64(2), int64(5)}, readFrames(t, conn, 2))
+}
+
+func TestWebSocketReplay_RejectsInvalidSince(t *testing.T) {
+	_, url := newReplayServer(t, &memoryReplaySource{}, 0)
+
+	header := http.Header{"X-User-ID": []string{strconv.Itoa(streamAdminID)}}
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?since=abc", header)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestWebSocketReplay_OnlyOwnProjects(t *testing.T) {
+	source := &memoryReplaySource{entries: []logs_models.LogEntry{*projectLog(1, 20), *projectLog(2, 30), *projectLog(3, 20)}}
+	hub, url := newReplayServer(t, source, 0)
+
+	// User 3 reconnects from the start: user 2's project 20 is neither
+	// replayed nor streamed live
+	conn := dialReplayAs(t, url+"?since=0", 3)
+	hub.broadcast <- projectLog(4, 20)
+	hub.broadcast <- projectLog(5, 30)
+
+	assert.Equal(t, []interface{}{int64(2), int64(5)}, readFrames(t, conn, 2))
+	assert.Equal(t, 3, source.lastFilter.OwnerUserID, "the replay query is scoped to the user's projects")
+}
+
+func TestWebSocketReplay_RequiresSession(t *testing.T) {
+	_, url := newReplayServer(t, &memoryReplaySource{entries: storedLogs(3)}, 0)
+
+	_, resp, err := websocket.DefaultDialer.Dial(url+"?since=0", nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -1,10 +1,6 @@
 package metrics
 
 import (
-	"bufio"
-	"encoding/json"
-	"os"
-	"path/filepath"
 	"sort"
 	"time"
 )
@@ -52,7 +48,7 @@ type Analyzer struct {
 // NewAnalyzer creates a new metrics analyzer
 func NewAnalyzer() *Analyzer {
 	return &Analyzer{
-		metricsDir: filepath.Join("test-results", "metrics"),
+		metricsDir: DefaultMetricsDir,
 	}
 }
 
@@ -116,41 +112,7 @@ func (a *Analyzer) Analyze(timeRange TimeRange) (*MetricsSummary, error) {
 
 // loadMetrics reads all metrics from the time range
 func (a *Analyzer) loadMetrics(timeRange TimeRange) ([]Metric, error) {
-	var allMetrics []Metric
-
-	// Iterate through each day in the range
-	for d := timeRange.Start; d.Before(timeRange.End) || d.Equal(timeRange.End); d = d.AddDate(0, 0, 1) {
-		filename := filepath.Join(a.metricsDir, d.Format("2006-01-02")+".jsonl")
-
-		file, err := os.Open(filename)
-		if err != nil {
-			if os.IsNotExist(err) {
-				continue // No metrics for this day
-			}
-			return nil, err
-		}
-
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			var metric Metric
-			if err := json.Unmarshal(scanner.Bytes(), &metric); err != nil {
-				file.Close()
-				return nil, err
-			}
-
-			// Filter by time range
-			if !metric.Timestamp.Before(timeRange.Start) && !metric.Timestamp.After(timeRange.End) {
-				allMetrics = append(allMetrics, metric)
-			}
-		}
-		file.Close()
-
-		if err := scanner.Err(); err != nil {
-			return nil, err
-		}
-	}
-
-	return allMetrics, nil
+	return readMetrics(a.metricsDir, timeRange)
 }
 
 // filterByType returns metrics of a specific type
@@ -1,9 +1,7 @@
 package metrics
 
 import (
-	"encoding/json"
-	"os"
-	"path/filepath"
+	"sort"
 	"time"
 )
 
@@ -29,44 +27,52 @@ type Metric struct {
 
 // Collector handles metric collection and storage
 type Collector struct {
-	metricsDir string
+	store Store
 }
 
-// NewCollector creates a new metrics collector
-func NewCollector() *Collector {
-	metricsDir := filepath.Join("test-results", "metrics")
-	os.MkdirAll(metricsDir, 0755)
-
+// NewCollector creates a new metrics collector backed by store. A nil store
+// means daily JSONL files under test-results/metrics.
+func NewCollector(store Store) *Collector {
+	if store == nil {
+		store = NewFileStore(DefaultMetricsDir)
+	}
 	return &Collector{
-		metricsDir: metricsDir,
+		store: store,
 	}
 }
 
-// Record saves a metric to disk (JSONL format)
+// Record saves a metric to the store
 func (c *Collector) Record(metric Metric) error {
 	if metric.Timestamp.IsZero() {
 		metric.Timestamp = time.Now()
 	}
+	return c.store.Append(metric)
+}
 
-	// One file per day: test-results/metrics/2025-11-14.jsonl
-	filename := filepath.Join(
-		c.metricsDir,
-		metric.Timestamp.Format("2006-01-02")+".jsonl",
-	)
-
-	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// Query returns the metrics recorded within the time range (inclusive),
+// oldest first, optionally limited to the given types
+func (c *Collector) Query(timeRange TimeRange, types ...MetricType) ([]Metric, error) {
+	metrics, err := c.store.Query(timeRange)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
-
-	data, err := json.Marshal(metric)
-	if err != nil {
-		return err
+	if len(types) > 0 {
+		wanted := make(map[MetricType]bool, len(types))
+		for _, t := range types {
+			wanted[t] = true
+		}
+		filtered := metrics[:0]
+		for _, m := range metrics {
+			if wanted[m.Type] {
+				filtered = append(filtered, m)
+			}
+		}
+		metrics = filtered
 	}
-
-	_, err = file.Write(append(data, '\n'))
-	return err
+	sort.SliceStable(metrics, func(i, j int) bool {
+		return metrics[i].Timestamp.Before(metrics[j].Timestamp)
+	})
+	return metrics, nil
 }
 
 // RecordTestRun records a test execution
@@ -147,7 +153,7 @@ func (c *Collector) RecordServiceHealth(service string, healthy bool, responseTi
 		Value:   value,
 		Success: healthy,
 		Metadata: map[string]interface{}{
-			"service":           service,
+			"service":          service,
 			"response_time_ms": responseTime.Milliseconds(),
 		},
 	})
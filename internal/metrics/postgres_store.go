@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// postgresSchema creates the metrics tables; it is safe to run repeatedly
+const postgresSchema = `
+CREATE SCHEMA IF NOT EXISTS metrics;
+
+CREATE TABLE IF NOT EXISTS metrics.events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    success BOOLEAN NOT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb
+);
+
+CREATE INDEX IF NOT EXISTS idx_metrics_events_recorded_at ON metrics.events(recorded_at);
+CREATE INDEX IF NOT EXISTS idx_metrics_events_type_recorded_at ON metrics.events(type, recorded_at);
+`
+
+// queryTimeout bounds each statement, since the Store API takes no context
+const queryTimeout = 10 * time.Second
+
+// PostgresStore keeps metrics in the metrics.events table
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a store on db. Call Migrate before first use.
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// Migrate creates the metrics schema and tables if they do not exist
+func (s *PostgresStore) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, postgresSchema)
+	return err
+}
+
+// Append saves one metric
+func (s *PostgresStore) Append(metric Metric) error {
+	metadata := []byte("{}")
+	if metric.Metadata != nil {
+		var err error
+		if metadata, err = json.Marshal(metric.Metadata); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO metrics.events (type, recorded_at, value, success, metadata)
+		VALUES ($1, $2, $3, $4, $5)
+	`, string(metric.Type), metric.Timestamp, metric.Value, metric.Success, metadata)
+	return err
+}
+
+// Query returns the metrics recorded within the time range (inclusive), oldest first
+func (s *PostgresStore) Query(timeRange TimeRange) ([]Metric, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT type, recorded_at, value, success, metadata
+		FROM metrics.events
+		WHERE recorded_at >= $1 AND recorded_at <= $2
+		ORDER BY recorded_at, id
+	`, timeRange.Start, timeRange.End)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var metrics []Metric
+	for rows.Next() {
+		var (
+			metric     Metric
+			metricType string
+			metadata   []byte
+		)
+		if err := rows.Scan(&metricType, &metric.Timestamp, &metric.Value, &metric.Success, &metadata); err != nil {
+			return nil, err
+		}
+		metric.Type = MetricType(metricType)
+		if err := json.Unmarshal(metadata, &metric.Metadata); err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics, rows.Err()
+}
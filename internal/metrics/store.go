@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultMetricsDir is where FileStore keeps metrics by default
+var DefaultMetricsDir = filepath.Join("test-results", "metrics")
+
+// Store persists metrics for a Collector
+type Store interface {
+	// Append saves one metric
+	Append(metric Metric) error
+	// Query returns the metrics recorded within the time range (inclusive)
+	Query(timeRange TimeRange) ([]Metric, error)
+}
+
+// FileStore keeps metrics in one JSONL file per day
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a file store in dir, creating it if needed
+func NewFileStore(dir string) *FileStore {
+	os.MkdirAll(dir, 0755)
+	return &FileStore{dir: dir}
+}
+
+// Append saves a metric to disk (JSONL format)
+func (s *FileStore) Append(metric Metric) error {
+	// One file per day: test-results/metrics/2025-11-14.jsonl
+	filename := filepath.Join(
+		s.dir,
+		metric.Timestamp.Format("2006-01-02")+".jsonl",
+	)
+
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := json.Marshal(metric)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// Query reads the daily files covering the time range
+func (s *FileStore) Query(timeRange TimeRange) ([]Metric, error) {
+	return readMetrics(s.dir, timeRange)
+}
+
+// readMetrics reads the daily JSONL files in dir covering the time range
+func readMetrics(dir string, timeRange TimeRange) ([]Metric, error) {
+	var allMetrics []Metric
+
+	// Iterate through each day in the range, from midnight so the last
+	// day's file is read even when it starts later in the day than Start
+	y, m, day := timeRange.Start.Date()
+	first := time.Date(y, m, day, 0, 0, 0, 0, timeRange.Start.Location())
+	for d := first; !d.After(timeRange.End); d = d.AddDate(0, 0, 1) {
+		filename := filepath.Join(dir, d.Format("2006-01-02")+".jsonl")
+
+		file, err := os.Open(filename)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // No metrics for this day
+			}
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var metric Metric
+			if err := json.Unmarshal(scanner.Bytes(), &metric); err != nil {
+				file.Close()
+				return nil, err
+			}
+
+			// Filter by time range
+			if !metric.Timestamp.Before(timeRange.Start) && !metric.Timestamp.After(timeRange.End) {
+				allMetrics = append(allMetrics, metric)
+			}
+		}
+		file.Close()
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return allMetrics, nil
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// Summary aggregates the metrics recorded over a time range
+type Summary struct {
+	Start                  time.Time        `json:"start"`
+	End                    time.Time        `json:"end"`
+	TestRuns               int              `json:"test_runs"`
+	TestsPassed            int              `json:"tests_passed"`
+	TestsFailed            int              `json:"tests_failed"`
+	TestPassRate           float64          `json:"test_pass_rate"`        // Percent of individual tests
+	RunPassRate            float64          `json:"run_pass_rate"`         // Percent of runs with no failures
+	AvgTestDurationSec     float64          `json:"avg_test_duration_sec"` // Per run
+	Deployments            int              `json:"deployments"`
+	DeploymentSuccessRate  float64          `json:"deployment_success_rate"` // Percent
+	Certificates           int              `json:"certificates"`
+	CertificateSuccessRate float64          `json:"certificate_success_rate"` // Percent
+	HealthChecks           int              `json:"health_checks"`
+	ServiceAvailability    float64          `json:"service_availability"` // Percent
+	TotalViolations        int              `json:"total_violations"`
+	Violations             []ViolationCount `json:"violations"` // By count, descending
+}
+
+// ViolationCount is the number of violations of a rule at one severity
+type ViolationCount struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Count    int    `json:"count"`
+}
+
+// Summarize computes a Summary of metrics recorded within timeRange
+func Summarize(metrics []Metric, timeRange TimeRange) *Summary {
+	summary := &Summary{Start: timeRange.Start, End: timeRange.End, Violations: []ViolationCount{}}
+
+	var runsPassed, deploysOK, certsOK, healthy int
+	var totalDuration float64
+	violations := make(map[ViolationCount]int)
+
+	for _, m := range metrics {
+		switch m.Type {
+		case MetricTestRun:
+			summary.TestRuns++
+			summary.TestsPassed += int(metadataFloat(m, "passed"))
+			summary.TestsFailed += int(metadataFloat(m, "failed"))
+			totalDuration += metadataFloat(m, "duration_sec")
+			if m.Success {
+				runsPassed++
+			}
+		case MetricDeployment:
+			summary.Deployments++
+			if m.Success {
+				deploysOK++
+			}
+		case MetricCertificate:
+			summary.Certificates++
+			if m.Success {
+				certsOK++
+			}
+		case MetricServiceHealth:
+			summary.HealthChecks++
+			if m.Success {
+				healthy++
+			}
+		case MetricRuleViolation:
+			summary.TotalViolations++
+			rule, _ := m.Metadata["rule"].(string)
+			severity, _ := m.Metadata["severity"].(string)
+			violations[ViolationCount{Rule: rule, Severity: severity}]++
+		}
+	}
+
+	summary.TestPassRate = percent(summary.TestsPassed, summary.TestsPassed+summary.TestsFailed)
+	summary.RunPassRate = percent(runsPassed, summary.TestRuns)
+	if summary.TestRuns > 0 {
+		summary.AvgTestDurationSec = totalDuration / float64(summary.TestRuns)
+	}
+	summary.DeploymentSuccessRate = percent(deploysOK, summary.Deployments)
+	summary.CertificateSuccessRate = percent(certsOK, summary.Certificates)
+	summary.ServiceAvailability = percent(healthy, summary.HealthChecks)
+
+	for v, count := range violations {
+		v.Count = count
+		summary.Violations = append(summary.Violations, v)
+	}
+	sort.Slice(summary.Violations, func(i, j int) bool {
+		a, b := summary.Violations[i], summary.Violations[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Severity < b.Severity
+	})
+
+	return summary
+}
+
+// percent returns n as a percentage of total, or 0 when total is 0
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
+
+// metadataFloat reads a numeric metadata value, which is an int when
+// recorded in-process and a float64 once read back from JSON
+func metadataFloat(m Metric, key string) float64 {
+	switch v := m.Metadata[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
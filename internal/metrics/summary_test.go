@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCollector(t *testing.T) *Collector {
+	t.Helper()
+	return NewCollector(NewFileStore(t.TempDir()))
+}
+
+func TestCollectorQuery_ReadsBackRecordedMetrics(t *testing.T) {
+	c := newTestCollector(t)
+	day := time.Date(2025, 11, 14, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, c.Record(Metric{Type: MetricDeployment, Timestamp: day.Add(-48 * time.Hour), Success: true}))
+	require.NoError(t, c.Record(Metric{Type: MetricDeployment, Timestamp: day.Add(-20 * time.Hour), Success: true}))
+	require.NoError(t, c.Record(Metric{Type: MetricCertificate, Timestamp: day.Add(-2 * time.Hour), Success: true}))
+	require.NoError(t, c.Record(Metric{Type: MetricDeployment, Timestamp: day.Add(-time.Hour), Success: false}))
+
+	// Starts later in the day than it ends, so both days' files are read
+	window := TimeRange{Start: day.Add(-22 * time.Hour), End: day}
+
+	all, err := c.Query(window)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	deployments, err := c.Query(window, MetricDeployment)
+	require.NoError(t, err)
+	require.Len(t, deployments, 2)
+	assert.True(t, deployments[0].Timestamp.Before(deployments[1].Timestamp), "oldest first")
+}
+
+func TestSummarize_RecordedEvents(t *testing.T) {
+	c := newTestCollector(t)
+	require.NoError(t, c.RecordTestRun(90, 10, 30*time.Second))
+	require.NoError(t, c.RecordTestRun(100, 0, 50*time.Second))
+	require.NoError(t, c.RecordTestRun(48, 2, 10*time.Second))
+	require.NoError(t, c.RecordDeployment("review", true, time.Minute))
+	require.NoError(t, c.RecordDeployment("logs", true, time.Minute))
+	require.NoError(t, c.RecordDeployment("portal", true, time.Minute))
+	require.NoError(t, c.RecordDeployment("review", false, time.Minute))
+	require.NoError(t, c.RecordCertificateGeneration(true))
+	require.NoError(t, c.RecordServiceHealth("logs", true, 20*time.Millisecond))
+	require.NoError(t, c.RecordServiceHealth("logs", false, 5*time.Second))
+	require.NoError(t, c.RecordRuleViolation("no-direct-db", "high"))
+	require.NoError(t, c.RecordRuleViolation("no-direct-db", "high"))
+	require.NoError(t, c.RecordRuleViolation("no-direct-db", "low"))
+	require.NoError(t, c.RecordRuleViolation("tdd-required", "medium"))
+
+	now := time.Now()
+	window := TimeRange{Start: now.Add(-time.Hour), End: now.Add(time.Minute)}
+	recorded, err := c.Query(window)
+	require.NoError(t, err)
+
+	summary := Summarize(recorded, window)
+
+	assert.Equal(t, 3, summary.TestRuns)
+	assert.Equal(t, 238, summary.TestsPassed)
+	assert.Equal(t, 12, summary.TestsFailed)
+	assert.InDelta(t, 95.2, summary.TestPassRate, 0.001)
+	assert.InDelta(t, 100.0/3, summary.RunPassRate, 0.001)
+	assert.InDelta(t, 30, summary.AvgTestDurationSec, 0.001)
+	assert.Equal(t, 4, summary.Deployments)
+	assert.InDelta(t, 75, summary.DeploymentSuccessRate, 0.001)
+	assert.Equal(t, 1, summary.Certificates)
+	assert.InDelta(t, 100, summary.CertificateSuccessRate, 0.001)
+	assert.Equal(t, 2, summary.HealthChecks)
+	assert.InDelta(t, 50, summary.ServiceAvailability, 0.001)
+	assert.Equal(t, 4, summary.TotalViolations)
+	assert.Equal(t, []ViolationCount{
+		{Rule: "no-direct-db", Severity: "high", Count: 2},
+		{Rule: "no-direct-db", Severity: "low", Count: 1},
+		{Rule: "tdd-required", Severity: "medium", Count: 1},
+	}, summary.Violations)
+}
+
+func TestSummarize_Empty(t *testing.T) {
+	summary := Summarize(nil, TimeRange{})
+
+	assert.Zero(t, summary.TestPassRate)
+	assert.Zero(t, summary.DeploymentSuccessRate)
+	data, err := json.Marshal(summary)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"violations":[]`)
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// ViolationTrendReport counts rule violations per time bucket and flags
+// rules that are getting worse
+type ViolationTrendReport struct {
+	Buckets     []TimeRange      `json:"buckets"` // Oldest first
+	Threshold   int              `json:"threshold"`
+	Rules       []ViolationTrend `json:"rules"`
+	Regressions []string         `json:"regressions"` // Rules flagged as regressing
+}
+
+// ViolationTrend is one rule's violation counts per bucket
+type ViolationTrend struct {
+	Rule       string `json:"rule"`
+	Counts     []int  `json:"counts"` // Aligned with the report's buckets
+	Change     int    `json:"change"` // Last bucket minus the one before
+	Regressing bool   `json:"regressing"`
+}
+
+// ViolationTrends splits timeRange into buckets of size bucket, ending at
+// timeRange.End, and counts violations per rule in each. A rule regresses
+// when its last bucket has more than threshold violations over the bucket
+// before it. Any part of the range shorter than a bucket at the start is
+// left out, so every bucket covers the same length of time.
+func ViolationTrends(metrics []Metric, timeRange TimeRange, bucket time.Duration, threshold int) *ViolationTrendReport {
+	report := &ViolationTrendReport{Threshold: threshold, Rules: []ViolationTrend{}, Regressions: []string{}}
+	if bucket <= 0 {
+		return report
+	}
+	n := int(timeRange.End.Sub(timeRange.Start) / bucket)
+	if n < 1 {
+		return report
+	}
+	start := timeRange.End.Add(-time.Duration(n) * bucket)
+	for i := 0; i < n; i++ {
+		report.Buckets = append(report.Buckets, TimeRange{
+			Start: start.Add(time.Duration(i) * bucket),
+			End:   start.Add(time.Duration(i+1) * bucket),
+		})
+	}
+
+	counts := make(map[string][]int)
+	for _, m := range metrics {
+		if m.Type != MetricRuleViolation || m.Timestamp.Before(start) || !m.Timestamp.Before(timeRange.End) {
+			continue
+		}
+		rule, _ := m.Metadata["rule"].(string)
+		if counts[rule] == nil {
+			counts[rule] = make([]int, n)
+		}
+		counts[rule][int(m.Timestamp.Sub(start)/bucket)]++
+	}
+
+	for rule, ruleCounts := range counts {
+		trend := ViolationTrend{Rule: rule, Counts: ruleCounts}
+		if n >= 2 {
+			trend.Change = ruleCounts[n-1] - ruleCounts[n-2]
+			trend.Regressing = trend.Change > threshold
+		}
+		report.Rules = append(report.Rules, trend)
+	}
+	sort.Slice(report.Rules, func(i, j int) bool {
+		a, b := report.Rules[i], report.Rules[j]
+		if a.Change != b.Change {
+			return a.Change > b.Change
+		}
+		return a.Rule < b.Rule
+	})
+	for _, trend := range report.Rules {
+		if trend.Regressing {
+			report.Regressions = append(report.Regressions, trend.Rule)
+		}
+	}
+	return report
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func violation(rule string, at time.Time) Metric {
+	return Metric{Type: MetricRuleViolation, Timestamp: at, Value: 1, Metadata: map[string]interface{}{"rule": rule, "severity": "high"}}
+}
+
+func TestViolationTrends(t *testing.T) {
+	end := time.Date(2025, 11, 14, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	previous, last := end.Add(-2*week+time.Hour), end.Add(-week+time.Hour)
+
+	var recorded []Metric
+	for i := 0; i < 5; i++ {
+		recorded = append(recorded, violation("no-direct-db", previous), violation("tdd-required", previous))
+	}
+	for i := 0; i < 2; i++ {
+		recorded = append(recorded, violation("no-direct-db", last))
+	}
+	for i := 0; i < 9; i++ {
+		recorded = append(recorded, violation("tdd-required", last))
+	}
+	recorded = append(recorded,
+		violation("no-secrets", last),
+		violation("ignored-outside-range", end.Add(-3*week)),
+		Metric{Type: MetricDeployment, Timestamp: last},
+	)
+
+	report := ViolationTrends(recorded, TimeRange{Start: end.Add(-2*week - time.Hour), End: end}, week, 1)
+
+	require.Len(t, report.Buckets, 2, "the partial hour before the first full bucket is left out")
+	assert.Equal(t, end.Add(-2*week), report.Buckets[0].Start)
+	assert.Equal(t, []ViolationTrend{
+		{Rule: "tdd-required", Counts: []int{5, 9}, Change: 4, Regressing: true},
+		{Rule: "no-secrets", Counts: []int{0, 1}, Change: 1, Regressing: false},
+		{Rule: "no-direct-db", Counts: []int{5, 2}, Change: -3, Regressing: false},
+	}, report.Rules)
+	assert.Equal(t, []string{"tdd-required"}, report.Regressions)
+}
+
+func TestViolationTrends_RangeShorterThanBucket(t *testing.T) {
+	end := time.Now()
+	report := ViolationTrends([]Metric{violation("r", end.Add(-time.Minute))}, TimeRange{Start: end.Add(-time.Hour), End: end}, 24*time.Hour, 0)
+
+	assert.Empty(t, report.Buckets)
+	assert.Empty(t, report.Rules)
+	assert.Empty(t, report.Regressions)
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSTestRouter(cfg config.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORSMiddleware(cfg))
+	router.GET("/api/portal/llm-configs", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+var testCORSConfig = config.CORSConfig{
+	AllowedOrigins:   []string{"http://localhost:3000"},
+	AllowedMethods:   []string{"GET", "POST", "DELETE"},
+	AllowedHeaders:   []string{"Content-Type", "X-CSRF-Token"},
+	AllowCredentials: true,
+	MaxAge:           10 * time.Minute,
+}
+
+func preflight(router *gin.Engine, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodOptions, "/api/portal/llm-configs", http.NoBody)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", "DELETE")
+	req.Header.Set("Access-Control-Request-Headers", "X-CSRF-Token")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	w := preflight(newCORSTestRouter(testCORSConfig), "http://localhost:3000")
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET, POST, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, X-CSRF-Token", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+}
+
+func TestCORSMiddleware_DisallowedOriginGetsNoHeaders(t *testing.T) {
+	router := newCORSTestRouter(testCORSConfig)
+
+	w := preflight(router, "https://evil.example.com")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/portal/llm-configs", http.NoBody)
+	req.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "the browser, not the server, blocks the response")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORSMiddleware_ReflectsAllowedOrigin(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/portal/llm-configs", http.NoBody)
+	req.Header.Set("Origin", "http://localhost:3000")
+	w := httptest.NewRecorder()
+	newCORSTestRouter(testCORSConfig).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"), "only preflights list methods")
+}
+
+func TestCORSMiddleware_WildcardNeverWithCredentials(t *testing.T) {
+	cfg := testCORSConfig
+	cfg.AllowedOrigins = []string{"*"}
+
+	w := preflight(newCORSTestRouter(cfg), "https://any.example.com")
+	assert.Equal(t, http.StatusForbidden, w.Code, "a wildcard is ignored for credentialed CORS")
+
+	cfg.AllowCredentials = false
+	w = preflight(newCORSTestRouter(cfg), "https://any.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+}
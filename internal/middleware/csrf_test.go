@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCSRFTestRouter serves a project API as sessionID, standing in for the
+// session auth middleware
+func newCSRFTestRouter(t *testing.T, sessionID string) *gin.Engine {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "csrf-test-secret")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if sessionID != "" {
+			c.Set("session_id", sessionID)
+		}
+		c.Next()
+	})
+	router.Use(CSRFMiddleware())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/api/logs/projects", ok)
+	router.POST("/api/logs/projects", ok)
+	router.DELETE("/api/logs/projects/:id", ok)
+	return router
+}
+
+// issuedToken loads the project list and returns the CSRF cookie it sets
+func issuedToken(t *testing.T, router *gin.Engine) *http.Cookie {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/logs/projects", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == CSRFCookieName {
+			assert.False(t, cookie.HttpOnly, "the frontend has to read the token")
+			return cookie
+		}
+	}
+	t.Fatal("no CSRF cookie issued")
+	return nil
+}
+
+func TestCSRFMiddleware_RejectsMutationWithoutHeader(t *testing.T) {
+	router := newCSRFTestRouter(t, "session-1")
+	cookie := issuedToken(t, router)
+
+	for _, method := range []string{http.MethodPost, http.MethodDelete} {
+		path := "/api/logs/projects"
+		if method == http.MethodDelete {
+			path += "/7"
+		}
+		req := httptest.NewRequest(method, path, http.NoBody)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code, method)
+	}
+}
+
+func TestCSRFMiddleware_AcceptsMatchingHeader(t *testing.T) {
+	router := newCSRFTestRouter(t, "session-1")
+	cookie := issuedToken(t, router)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/projects", http.NoBody)
+	req.AddCookie(cookie)
+	req.Header.Set(CSRFHeaderName, cookie.Value)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCSRFMiddleware_RejectsTokenFromAnotherSession(t *testing.T) {
+	other := issuedToken(t, newCSRFTestRouter(t, "session-2"))
+	router := newCSRFTestRouter(t, "session-1")
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/logs/projects/7", http.NoBody)
+	req.AddCookie(other)
+	req.Header.Set(CSRFHeaderName, other.Value)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var reissued bool
+	for _, cookie := range w.Result().Cookies() {
+		reissued = reissued || cookie.Name == CSRFCookieName
+	}
+	assert.True(t, reissued, "a token for the current session is issued for a retry")
+}
+
+func TestCSRFMiddleware_RejectsMismatchedHeader(t *testing.T) {
+	router := newCSRFTestRouter(t, "session-1")
+	cookie := issuedToken(t, router)
+	second := issuedToken(t, router)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/projects", http.NoBody)
+	req.AddCookie(cookie)
+	req.Header.Set(CSRFHeaderName, second.Value)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "the header has to echo the cookie")
+}
+
+func TestCSRFMiddleware_SkipsRequestsWithoutSession(t *testing.T) {
+	router := newCSRFTestRouter(t, "")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/logs/projects", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCSRFMiddleware_SkipsHeaderAuthenticatedRequests(t *testing.T) {
+	t.Setenv("JWT_SECRET", "csrf-test-secret")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("session_id", "session-1")
+		c.Set("auth_source", AuthSourceHeader)
+		c.Next()
+	})
+	router.Use(CSRFMiddleware())
+	router.POST("/api/logs/projects", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/logs/projects", http.NoBody))
+	assert.Equal(t, http.StatusOK, w.Code, "a bearer token is never sent by the browser on its own")
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+)
+
+// RequestLimitsMiddleware enforces a maximum request body size and a handler
+// timeout. routes overrides the defaults for specific route patterns, as
+// registered with gin (e.g. "/api/review/modes/:mode/stream").
+//
+// Bodies that declare a larger Content-Length are rejected with 413 before
+// the handler runs; chunked bodies are cut off at the limit, so reading them
+// fails in the handler.
+//
+// The timeout is applied to the request context, so it stops work that
+// honours the context. If the handler gives up without writing a response,
+// the client gets a 504. WebSocket upgrades and event streams are long-lived
+// by design and get no timeout.
+func RequestLimitsMiddleware(defaults config.RequestLimits, routes map[string]config.RequestLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limits := defaults
+		if override, ok := routes[c.FullPath()]; ok {
+			limits = override
+		}
+
+		if limits.MaxBodyBytes > 0 && c.Request.Body != nil {
+			if c.Request.ContentLength > limits.MaxBodyBytes {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+				return
+			}
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limits.MaxBodyBytes)
+		}
+
+		if limits.Timeout <= 0 || isStreamingRequest(c.Request) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), limits.Timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"error": "request timed out"})
+		}
+	}
+}
+
+// isStreamingRequest reports whether r opens a WebSocket or an event stream
+func isStreamingRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLimitsTestRouter(defaults config.RequestLimits, routes map[string]config.RequestLimits) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestLimitsMiddleware(defaults, routes))
+	echo := func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.String(http.StatusOK, "%d", len(body))
+	}
+	router.POST("/api/logs", echo)
+	router.POST("/api/review/modes/:mode/stream", echo)
+	router.GET("/slow", func(c *gin.Context) {
+		select {
+		case <-c.Request.Context().Done():
+		case <-time.After(time.Second):
+			c.Status(http.StatusOK)
+		}
+	})
+	return router
+}
+
+func TestRequestLimitsMiddleware_OversizedBodyIs413(t *testing.T) {
+	router := newLimitsTestRouter(config.RequestLimits{MaxBodyBytes: 16}, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(strings.Repeat("x", 17))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.JSONEq(t, `{"error":"request body too large"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(strings.Repeat("x", 16))))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "16", w.Body.String())
+}
+
+func TestRequestLimitsMiddleware_ChunkedBodyIsCutOff(t *testing.T) {
+	router := newLimitsTestRouter(config.RequestLimits{MaxBodyBytes: 16}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs", strings.NewReader(strings.Repeat("x", 64)))
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "request body too large")
+}
+
+func TestRequestLimitsMiddleware_RouteOverride(t *testing.T) {
+	router := newLimitsTestRouter(config.RequestLimits{MaxBodyBytes: 16}, map[string]config.RequestLimits{
+		"/api/review/modes/:mode/stream": {MaxBodyBytes: 1024},
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/review/modes/scan/stream", strings.NewReader(strings.Repeat("x", 512))))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "512", w.Body.String())
+}
+
+func TestRequestLimitsMiddleware_TimeoutIs504(t *testing.T) {
+	router := newLimitsTestRouter(config.RequestLimits{Timeout: 20 * time.Millisecond}, nil)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", http.NoBody))
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.JSONEq(t, `{"error":"request timed out"}`, w.Body.String())
+	assert.Less(t, time.Since(start), time.Second, "the handler is cancelled, not waited out")
+}
+
+func TestRequestLimitsMiddleware_EventStreamHasNoTimeout(t *testing.T) {
+	router := newLimitsTestRouter(config.RequestLimits{Timeout: 20 * time.Millisecond}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/slow", http.NoBody)
+	req.Header.Set("Accept", "text/event-stream")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequestLimitsMiddleware_OverrideWithoutTimeout(t *testing.T) {
+	router := newLimitsTestRouter(config.RequestLimits{MaxBodyBytes: 16, Timeout: 20 * time.Millisecond}, map[string]config.RequestLimits{
+		"/slow": {MaxBodyBytes: 16},
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, w.Code, "a streaming export runs until it is done")
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -10,85 +11,57 @@ import (
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
 )
 
-// RedisSessionAuthMiddleware validates JWT and retrieves session from Redis
-func RedisSessionAuthMiddleware(sessionStore *session.RedisStore) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Get JWT from cookie
-		tokenString, err := c.Cookie("devsmith_token")
-		if err != nil {
-			// No cookie found - redirect to login
-			if isHTMLRequest(c) {
-				c.Redirect(http.StatusFound, "/auth/github/login")
-			} else {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
-			}
-			c.Abort()
-			return
-		}
+// SessionCookieName is the cookie the portal sets on login
+const SessionCookieName = "devsmith_token"
 
-		// Parse JWT
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return security.GetJWTSecret(), nil
-		})
-
-		if err != nil || !token.Valid {
-			// Invalid token - redirect to login
-			if isHTMLRequest(c) {
-				c.Redirect(http.StatusFound, "/auth/github/login")
-			} else {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			}
-			c.Abort()
-			return
-		}
+// Where the session JWT of a request came from, stored under "auth_source"
+const (
+	AuthSourceHeader = "header"
+	AuthSourceCookie = "cookie"
+)
 
-		// Extract session_id from claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			if isHTMLRequest(c) {
-				c.Redirect(http.StatusFound, "/auth/github/login")
-			} else {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			}
-			c.Abort()
+// loginPath is where HTML requests are sent when they need to sign in
+const loginPath = "/auth/github/login"
+
+var (
+	errTokenInvalid     = errors.New("invalid token")
+	errSessionIDMissing = errors.New("missing session_id")
+)
+
+// RedisSessionAuthMiddleware validates the session JWT and loads the session
+// from Redis. The JWT is read from an "Authorization: Bearer" header, as the
+// React frontend sends it, or from the devsmith_token cookie set on login;
+// the header wins when both are present.
+//
+// Unauthenticated HTML requests are redirected to the login page, everything
+// else gets a 401 JSON error.
+func RedisSessionAuthMiddleware(sessionStore *session.RedisStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, source := sessionToken(c)
+		if tokenString == "" {
+			rejectUnauthenticated(c, http.StatusUnauthorized, "Authentication required")
 			return
 		}
 
-		sessionID, ok := claims["session_id"].(string)
-		if !ok || sessionID == "" {
-			if isHTMLRequest(c) {
-				c.Redirect(http.StatusFound, "/auth/github/login")
-			} else {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing session_id"})
+		sessionID, err := parseSessionID(tokenString)
+		if err != nil {
+			message := "Invalid token"
+			if errors.Is(err, errSessionIDMissing) {
+				message = "Missing session_id"
 			}
-			c.Abort()
+			rejectUnauthenticated(c, http.StatusUnauthorized, message)
 			return
 		}
 
 		// Retrieve session from Redis
 		sess, err := sessionStore.Get(c.Request.Context(), sessionID)
 		if err != nil {
-			if isHTMLRequest(c) {
-				c.Redirect(http.StatusFound, "/auth/github/login")
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Session retrieval failed"})
-			}
-			c.Abort()
+			rejectUnauthenticated(c, http.StatusInternalServerError, "Session retrieval failed")
 			return
 		}
-
 		if sess == nil {
-			// Session not found (expired or deleted) - redirect to login
-			if isHTMLRequest(c) {
-				c.Redirect(http.StatusFound, "/auth/github/login")
-			} else {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired"})
-			}
-			c.Abort()
+			// Session not found (expired or deleted)
+			rejectUnauthenticated(c, http.StatusUnauthorized, "Session expired")
 			return
 		}
 
@@ -98,21 +71,70 @@ func RedisSessionAuthMiddleware(sessionStore *session.RedisStore) gin.HandlerFun
 		c.Set("github_token", sess.GitHubToken)
 		c.Set("session_id", sessionID)
 		c.Set("session_token", tokenString) // Store JWT for Portal AI Factory API calls
+		c.Set("auth_source", source)
 
 		// Store full session for handlers that need metadata
 		c.Set("session", sess)
 
-		// Refresh session TTL on each request
-		if err := sessionStore.RefreshTTL(c.Request.Context(), sessionID); err != nil {
-			// Log but don't fail - session will eventually expire
+		c.Next()
+	}
+}
+
+// sessionToken returns the request's session JWT and where it was found, or
+// an empty token if there is none
+func sessionToken(c *gin.Context) (token, source string) {
+	if scheme, value, ok := strings.Cut(c.GetHeader("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		if value = strings.TrimSpace(value); value != "" {
+			return value, AuthSourceHeader
+		}
+	}
+	if cookie, err := c.Cookie(SessionCookieName); err == nil && cookie != "" {
+		return cookie, AuthSourceCookie
+	}
+	return "", ""
+}
+
+// parseSessionID validates tokenString and returns its session_id claim
+func parseSessionID(tokenString string) (string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Validate signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
 		}
+		return security.GetJWTSecret(), nil
+	})
+	if err != nil || !token.Valid {
+		return "", errTokenInvalid
+	}
 
-		c.Next()
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", errTokenInvalid
+	}
+	sessionID, ok := claims["session_id"].(string)
+	if !ok || sessionID == "" {
+		return "", errSessionIDMissing
 	}
+	return sessionID, nil
 }
 
-// isHTMLRequest checks if the request expects HTML response
+// rejectUnauthenticated redirects HTML requests to the login page and answers
+// everything else with a JSON error
+func rejectUnauthenticated(c *gin.Context, status int, message string) {
+	if isHTMLRequest(c) {
+		c.Redirect(http.StatusFound, loginPath)
+		c.Abort()
+		return
+	}
+	c.AbortWithStatusJSON(status, gin.H{"error": message})
+}
+
+// isHTMLRequest checks if the request expects HTML response. Requests without
+// an Accept header are treated as page loads unless they target the API.
 func isHTMLRequest(c *gin.Context) bool {
 	accept := c.GetHeader("Accept")
-	return strings.Contains(accept, "text/html") || accept == ""
+	if accept == "" {
+		return !strings.HasPrefix(c.Request.URL.Path, "/api/")
+	}
+	return strings.Contains(accept, "text/html")
 }
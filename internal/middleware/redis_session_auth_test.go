@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sessionAuthTestSecret = "session-auth-test-secret"
+
+// newSessionAuthTestRouter serves /api/me and /dashboard behind the session
+// middleware and returns it with a session for octocat and a JWT for it
+func newSessionAuthTestRouter(t *testing.T) (*gin.Engine, *session.RedisStore, string) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", sessionAuthTestSecret)
+	gin.SetMode(gin.TestMode)
+
+	store, err := session.NewRedisStore(miniredis.RunT(t).Addr(), time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	sessionID, err := store.Create(context.Background(), &session.Session{
+		UserID:         42,
+		GitHubUsername: "octocat",
+		GitHubToken:    "gho_octocat",
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(RedisSessionAuthMiddleware(store))
+	whoami := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":         c.GetInt("user_id"),
+			"github_username": c.GetString("github_username"),
+			"session_id":      c.GetString("session_id"),
+			"session_token":   c.GetString("session_token"),
+			"auth_source":     c.GetString("auth_source"),
+		})
+	}
+	router.GET("/api/me", whoami)
+	router.GET("/dashboard", whoami)
+	return router, store, signSessionJWT(t, sessionID)
+}
+
+func signSessionJWT(t *testing.T, sessionID string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"session_id": sessionID,
+		"exp":        time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(sessionAuthTestSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func serveSessionAuth(router *gin.Engine, path, header, cookie, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+	if header != "" {
+		req.Header.Set("Authorization", "Bearer "+header)
+	}
+	if cookie != "" {
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: cookie})
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRedisSessionAuth_HeaderOnly(t *testing.T) {
+	router, _, token := newSessionAuthTestRouter(t)
+
+	w := serveSessionAuth(router, "/api/me", token, "", "application/json")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"user_id":42`)
+	assert.Contains(t, w.Body.String(), `"github_username":"octocat"`)
+	assert.Contains(t, w.Body.String(), `"session_token":"`+token+`"`)
+	assert.Contains(t, w.Body.String(), `"auth_source":"header"`)
+}
+
+func TestRedisSessionAuth_CookieOnly(t *testing.T) {
+	router, _, token := newSessionAuthTestRouter(t)
+
+	w := serveSessionAuth(router, "/api/me", "", token, "application/json")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"user_id":42`)
+	assert.Contains(t, w.Body.String(), `"session_token":"`+token+`"`)
+	assert.Contains(t, w.Body.String(), `"auth_source":"cookie"`)
+}
+
+func TestRedisSessionAuth_HeaderWinsOverCookie(t *testing.T) {
+	router, store, token := newSessionAuthTestRouter(t)
+	otherID, err := store.Create(context.Background(), &session.Session{UserID: 7, GitHubUsername: "hubot"})
+	require.NoError(t, err)
+	cookieToken := signSessionJWT(t, otherID)
+
+	w := serveSessionAuth(router, "/api/me", token, cookieToken, "application/json")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"github_username":"octocat"`)
+	assert.Contains(t, w.Body.String(), `"auth_source":"header"`)
+
+	// An invalid header is not rescued by a valid cookie
+	w = serveSessionAuth(router, "/api/me", "not-a-jwt", cookieToken, "application/json")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error":"Invalid token"}`, w.Body.String())
+}
+
+func TestRedisSessionAuth_Neither(t *testing.T) {
+	router, _, _ := newSessionAuthTestRouter(t)
+
+	w := serveSessionAuth(router, "/api/me", "", "", "application/json")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error":"Authentication required"}`, w.Body.String())
+
+	// API calls without an Accept header still get JSON
+	w = serveSessionAuth(router, "/api/me", "", "", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serveSessionAuth(router, "/dashboard", "", "", "text/html,application/xhtml+xml")
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/auth/github/login", w.Header().Get("Location"))
+}
+
+func TestRedisSessionAuth_ExpiredSession(t *testing.T) {
+	router, store, token := newSessionAuthTestRouter(t)
+	sessionID, err := parseSessionID(token)
+	require.NoError(t, err)
+	require.NoError(t, store.Delete(context.Background(), sessionID))
+
+	w := serveSessionAuth(router, "/api/me", token, "", "application/json")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error":"Session expired"}`, w.Body.String())
+
+	w = serveSessionAuth(router, "/dashboard", "", token, "text/html")
+	assert.Equal(t, http.StatusFound, w.Code)
+}
+
+func TestRedisSessionAuth_MissingSessionID(t *testing.T) {
+	router, _, _ := newSessionAuthTestRouter(t)
+
+	w := serveSessionAuth(router, "/api/me", signSessionJWT(t, ""), "", "application/json")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error":"Missing session_id"}`, w.Body.String())
+}
@@ -13,6 +13,8 @@ import (
 type AlertEngine struct {
 	db                 *sql.DB
 	thresholds         AlertThresholds
+	spikeRule          *SpikeRule // nil when spike detection is disabled
+	notifiers          []Notifier
 	evaluationInterval time.Duration
 	ticker             *time.Ticker
 	stopChan           chan struct{}
@@ -32,12 +34,18 @@ func NewAlertEngine(db *sql.DB, thresholds AlertThresholds, evaluationInterval t
 	return &AlertEngine{
 		db:                 db,
 		thresholds:         thresholds,
+		spikeRule:          NewSpikeRule(NewSQLMetricsCollector(db), thresholds),
 		evaluationInterval: evaluationInterval,
 		stopChan:           make(chan struct{}),
 		logger:             logger,
 	}
 }
 
+// SetNotifiers sets where newly created alerts are sent. Call it before Start.
+func (e *AlertEngine) SetNotifiers(notifiers ...Notifier) {
+	e.notifiers = notifiers
+}
+
 // Start begins monitoring metrics and generating alerts.
 func (e *AlertEngine) Start() {
 	e.logger.Println("Alert engine started")
@@ -75,6 +83,9 @@ func (e *AlertEngine) evaluateMetrics() {
 	// Check error rate
 	e.checkErrorRate(ctx)
 
+	// Check for error spikes against the trailing baseline
+	e.checkErrorSpike(ctx)
+
 	// Check response times
 	e.checkResponseTimes(ctx)
 
@@ -124,6 +135,26 @@ func (e *AlertEngine) checkErrorRate(ctx context.Context) {
 	}
 }
 
+// checkErrorSpike creates an error_spike alert while errors run well above
+// their recent baseline, and clears it once they settle.
+func (e *AlertEngine) checkErrorSpike(ctx context.Context) {
+	if e.spikeRule == nil {
+		return
+	}
+
+	alert, err := e.spikeRule.Evaluate(ctx)
+	if err != nil {
+		e.logger.Printf("Failed to evaluate error spike: %v", err)
+		return
+	}
+
+	if alert != nil {
+		e.createAlert(ctx, *alert)
+	} else {
+		e.clearAlert(ctx, "error_spike", "all_services")
+	}
+}
+
 // checkResponseTimes evaluates P95 response time and creates alert if threshold exceeded.
 func (e *AlertEngine) checkResponseTimes(ctx context.Context) {
 	window := e.evaluationInterval
@@ -301,6 +332,7 @@ func (e *AlertEngine) createAlert(ctx context.Context, alert Alert) {
 		}
 
 		e.logger.Printf("ALERT CREATED: [%s] %s - %s", alert.Severity, alert.AlertType, alert.Message)
+		e.notify(alert)
 	} else if err == nil {
 		// Update existing alert with new occurrence
 		updateQuery := `
@@ -324,6 +356,20 @@ func (e *AlertEngine) createAlert(ctx context.Context, alert Alert) {
 	}
 }
 
+// notify sends a new alert to every notifier in the background, so a slow
+// or unreachable one never delays evaluation or the other notifiers.
+func (e *AlertEngine) notify(alert Alert) {
+	for _, notifier := range e.notifiers {
+		go func(n Notifier) {
+			ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+			defer cancel()
+			if err := n.Notify(ctx, alert); err != nil {
+				e.logger.Printf("Failed to send %s alert notification: %v", alert.AlertType, err)
+			}
+		}(notifier)
+	}
+}
+
 // clearAlert resolves an active alert if it exists.
 func (e *AlertEngine) clearAlert(ctx context.Context, alertType, serviceName string) {
 	query := `
@@ -143,17 +143,25 @@ func RecordPayloadValidationFailure(c *gin.Context, invalidFields, extraFields,
 
 // AlertThresholds defines when to trigger alerts
 type AlertThresholds struct {
-	APIErrorRate    float64 // errors per minute
-	ResponseTimeP95 int64   // milliseconds
-	ServiceDown     int     // consecutive failures
+	APIErrorRate        float64       // errors per minute
+	ResponseTimeP95     int64         // milliseconds
+	ServiceDown         int           // consecutive failures
+	SpikeMultiplier     float64       // current/baseline error ratio that fires error_spike; <= 0 disables
+	SpikeWindow         time.Duration // current window for spike detection
+	SpikeBaselineWindow time.Duration // trailing window the baseline rate is taken from
+	SpikeMinErrors      int64         // errors needed in SpikeWindow before a spike can fire
 }
 
 // DefaultAlertThresholds returns sensible default alert thresholds
 func DefaultAlertThresholds() AlertThresholds {
 	return AlertThresholds{
-		APIErrorRate:    5.0,  // 5 errors per minute triggers alert
-		ResponseTimeP95: 2000, // 2 second P95 response time triggers alert
-		ServiceDown:     2,    // 2 consecutive health check failures
+		APIErrorRate:        5.0,             // 5 errors per minute triggers alert
+		ResponseTimeP95:     2000,            // 2 second P95 response time triggers alert
+		ServiceDown:         2,               // 2 consecutive health check failures
+		SpikeMultiplier:     3.0,             // 3x the usual error count triggers alert
+		SpikeWindow:         5 * time.Minute, // compared over the last 5 minutes
+		SpikeBaselineWindow: 1 * time.Hour,   // against the hour before that
+		SpikeMinErrors:      10,              // ignore spikes from a near-zero baseline
 	}
 }
 
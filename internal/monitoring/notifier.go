@@ -0,0 +1,118 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Webhook delivery defaults
+const (
+	DefaultWebhookTimeout  = 5 * time.Second
+	DefaultWebhookAttempts = 3
+	DefaultWebhookBackoff  = 500 * time.Millisecond
+)
+
+// notifyTimeout bounds one alert's delivery to a notifier, retries included.
+const notifyTimeout = 15 * time.Second
+
+// Notifier delivers newly created alerts somewhere people will see them.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// webhookPayload is the JSON posted by WebhookNotifier. Text makes it a
+// valid Slack incoming-webhook message; the other fields are for machines.
+type webhookPayload struct {
+	TriggeredAt time.Time `json:"triggered_at"`
+	Text        string    `json:"text"`
+	AlertType   string    `json:"alert_type"`
+	Severity    string    `json:"severity"`
+	ServiceName string    `json:"service_name"`
+	Message     string    `json:"message"`
+	MetricValue float64   `json:"metric_value"`
+	Threshold   float64   `json:"threshold"`
+}
+
+// WebhookNotifier POSTs alerts as JSON to a URL, retrying transient failures.
+type WebhookNotifier struct {
+	client   *http.Client
+	url      string
+	attempts int
+	backoff  time.Duration
+}
+
+// NewWebhookNotifier creates a WebhookNotifier for url with the default
+// timeout, attempts and backoff.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		client:   &http.Client{Timeout: DefaultWebhookTimeout},
+		url:      url,
+		attempts: DefaultWebhookAttempts,
+		backoff:  DefaultWebhookBackoff,
+	}
+}
+
+// Notify implements Notifier. Network errors, 429 and 5xx responses are
+// retried with linear backoff; other non-2xx responses fail immediately.
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(webhookPayload{
+		TriggeredAt: time.Now().UTC(),
+		Text:        fmt.Sprintf("[%s] %s (%s): %s", alert.Severity, alert.AlertType, alert.ServiceName, alert.Message),
+		AlertType:   alert.AlertType,
+		Severity:    alert.Severity,
+		ServiceName: alert.ServiceName,
+		Message:     alert.Message,
+		MetricValue: alert.MetricValue,
+		Threshold:   alert.Threshold,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= n.attempts; attempt++ {
+		retry, err := n.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == n.attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("webhook delivery cancelled: %w", ctx.Err())
+		case <-time.After(time.Duration(attempt) * n.backoff):
+		}
+	}
+	return fmt.Errorf("webhook delivery failed: %w", lastErr)
+}
+
+// post sends one request and reports whether a failure is worth retrying.
+func (n *WebhookNotifier) post(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,126 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAlert = Alert{
+	AlertType:   "error_spike",
+	Severity:    "warning",
+	ServiceName: "all_services",
+	Message:     "Error spike: 40 errors in the last 5m0s",
+	MetricValue: 40,
+	Threshold:   30,
+}
+
+func newTestWebhookNotifier(url string) *WebhookNotifier {
+	n := NewWebhookNotifier(url)
+	n.backoff = time.Millisecond
+	return n
+}
+
+func TestWebhookNotifier_PostsAlertPayload(t *testing.T) {
+	var payload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &payload))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	require.NoError(t, newTestWebhookNotifier(server.URL).Notify(context.Background(), testAlert))
+
+	assert.Equal(t, "error_spike", payload["alert_type"])
+	assert.Equal(t, "warning", payload["severity"])
+	assert.Equal(t, "all_services", payload["service_name"])
+	assert.Equal(t, testAlert.Message, payload["message"])
+	assert.Equal(t, 40.0, payload["metric_value"])
+	assert.Equal(t, 30.0, payload["threshold"])
+	assert.Equal(t, "[warning] error_spike (all_services): "+testAlert.Message, payload["text"])
+	assert.NotEmpty(t, payload["triggered_at"])
+}
+
+func TestWebhookNotifier_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	require.NoError(t, newTestWebhookNotifier(server.URL).Notify(context.Background(), testAlert))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestWebhookNotifier_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	assert.Error(t, newTestWebhookNotifier(server.URL).Notify(context.Background(), testAlert))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestWebhookNotifier_GivesUpAfterAttempts(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	assert.Error(t, newTestWebhookNotifier(server.URL).Notify(context.Background(), testAlert))
+	assert.Equal(t, int32(DefaultWebhookAttempts), calls.Load())
+}
+
+// chanNotifier reports each alert it receives on a channel.
+type chanNotifier struct {
+	alerts chan Alert
+	delay  time.Duration
+}
+
+func (c *chanNotifier) Notify(_ context.Context, alert Alert) error {
+	time.Sleep(c.delay)
+	c.alerts <- alert
+	return nil
+}
+
+func TestAlertEngine_NotifiesAllWithoutBlocking(t *testing.T) {
+	engine := NewAlertEngine(nil, DefaultAlertThresholds(), time.Minute, log.New(io.Discard, "", 0))
+	slow := &chanNotifier{alerts: make(chan Alert, 1), delay: 200 * time.Millisecond}
+	fast := &chanNotifier{alerts: make(chan Alert, 1)}
+	engine.SetNotifiers(slow, fast)
+
+	start := time.Now()
+	engine.notify(testAlert)
+	assert.Less(t, time.Since(start), 100*time.Millisecond, "notify must not wait for notifiers")
+
+	for _, n := range []*chanNotifier{fast, slow} {
+		select {
+		case got := <-n.alerts:
+			assert.Equal(t, testAlert, got)
+		case <-time.After(2 * time.Second):
+			t.Fatal("notifier was not called")
+		}
+	}
+}
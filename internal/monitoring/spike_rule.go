@@ -0,0 +1,85 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrorCountSource counts API error responses in a time range.
+type ErrorCountSource interface {
+	CountErrors(ctx context.Context, since, until time.Time) (int64, error)
+}
+
+// SpikeRule fires when the error count in the current window exceeds the
+// trailing baseline, scaled to the same window length, by a multiple.
+type SpikeRule struct {
+	source         ErrorCountSource
+	now            func() time.Time
+	window         time.Duration
+	baselineWindow time.Duration
+	multiplier     float64
+	minErrors      int64
+}
+
+// NewSpikeRule creates a SpikeRule from the spike fields of thresholds.
+// It returns nil when SpikeMultiplier is not positive (spike alerts disabled).
+func NewSpikeRule(source ErrorCountSource, thresholds AlertThresholds) *SpikeRule {
+	if thresholds.SpikeMultiplier <= 0 {
+		return nil
+	}
+
+	defaults := DefaultAlertThresholds()
+	rule := &SpikeRule{
+		source:         source,
+		now:            time.Now,
+		window:         thresholds.SpikeWindow,
+		baselineWindow: thresholds.SpikeBaselineWindow,
+		multiplier:     thresholds.SpikeMultiplier,
+		minErrors:      thresholds.SpikeMinErrors,
+	}
+	if rule.window <= 0 {
+		rule.window = defaults.SpikeWindow
+	}
+	if rule.baselineWindow <= 0 {
+		rule.baselineWindow = defaults.SpikeBaselineWindow
+	}
+	return rule
+}
+
+// Evaluate compares the current window with the baseline window just before
+// it and returns an error_spike alert if it spiked, or nil if not.
+func (r *SpikeRule) Evaluate(ctx context.Context) (*Alert, error) {
+	now := r.now()
+	windowStart := now.Add(-r.window)
+
+	current, err := r.source.CountErrors(ctx, windowStart, now)
+	if err != nil {
+		return nil, fmt.Errorf("count current errors: %w", err)
+	}
+	if current < r.minErrors {
+		return nil, nil
+	}
+
+	baseline, err := r.source.CountErrors(ctx, windowStart.Add(-r.baselineWindow), windowStart)
+	if err != nil {
+		return nil, fmt.Errorf("count baseline errors: %w", err)
+	}
+
+	// Baseline errors expected in one current-sized window
+	expected := float64(baseline) * r.window.Seconds() / r.baselineWindow.Seconds()
+	threshold := expected * r.multiplier
+	if float64(current) <= threshold {
+		return nil, nil
+	}
+
+	return &Alert{
+		AlertType:   "error_spike",
+		Severity:    "warning",
+		ServiceName: "all_services",
+		Message: fmt.Sprintf("Error spike: %d errors in the last %s vs baseline %.1f per %s over the previous %s (threshold %.1fx)",
+			current, r.window, expected, r.window, r.baselineWindow, r.multiplier),
+		MetricValue: float64(current),
+		Threshold:   threshold,
+	}, nil
+}
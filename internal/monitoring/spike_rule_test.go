@@ -0,0 +1,113 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeErrorCounts serves error counts from synthetic per-minute buckets.
+type fakeErrorCounts struct {
+	perMinute map[time.Time]int64
+	err       error
+}
+
+func (f *fakeErrorCounts) CountErrors(_ context.Context, since, until time.Time) (int64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	var total int64
+	for minute, count := range f.perMinute {
+		if !minute.Before(since) && minute.Before(until) {
+			total += count
+		}
+	}
+	return total, nil
+}
+
+var spikeNow = time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+
+// syntheticErrors records baseline errors/minute for the past hour and
+// current errors/minute for the last five minutes.
+func syntheticErrors(baseline, current int64) *fakeErrorCounts {
+	f := &fakeErrorCounts{perMinute: make(map[time.Time]int64)}
+	for m := 1; m <= 65; m++ {
+		count := baseline
+		if m <= 5 {
+			count = current
+		}
+		f.perMinute[spikeNow.Add(-time.Duration(m)*time.Minute)] = count
+	}
+	return f
+}
+
+func newTestSpikeRule(source ErrorCountSource) *SpikeRule {
+	rule := NewSpikeRule(source, DefaultAlertThresholds())
+	rule.now = func() time.Time { return spikeNow }
+	return rule
+}
+
+func TestSpikeRule_FiresOnSpike(t *testing.T) {
+	// 2/min baseline = 10 per 5 minutes; 8/min now = 40, 4x the baseline
+	alert, err := newTestSpikeRule(syntheticErrors(2, 8)).Evaluate(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, alert)
+
+	assert.Equal(t, "error_spike", alert.AlertType)
+	assert.Equal(t, 40.0, alert.MetricValue)
+	assert.InDelta(t, 30.0, alert.Threshold, 0.001)
+	assert.Contains(t, alert.Message, "40 errors")
+	assert.Contains(t, alert.Message, "baseline 10.0")
+}
+
+func TestSpikeRule_QuietBelowMultiplier(t *testing.T) {
+	// 2/min baseline, 5/min now: 2.5x is under the 3x multiplier
+	alert, err := newTestSpikeRule(syntheticErrors(2, 5)).Evaluate(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, alert)
+}
+
+func TestSpikeRule_IgnoresSmallCounts(t *testing.T) {
+	// From a zero baseline any error is a spike; MinErrors keeps 5 errors quiet
+	alert, err := newTestSpikeRule(syntheticErrors(0, 1)).Evaluate(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, alert)
+
+	alert, err = newTestSpikeRule(syntheticErrors(0, 2)).Evaluate(context.Background())
+	require.NoError(t, err)
+	assert.NotNil(t, alert, "10 errors from a zero baseline is a spike")
+}
+
+func TestSpikeRule_ConfigurableWindows(t *testing.T) {
+	thresholds := DefaultAlertThresholds()
+	thresholds.SpikeMultiplier = 1.5
+	thresholds.SpikeWindow = time.Minute
+	thresholds.SpikeBaselineWindow = 10 * time.Minute
+	thresholds.SpikeMinErrors = 1
+
+	// The last minute has 8 errors; the ten minutes before it hold four
+	// spiking minutes at 8/min and six at 2/min, so the baseline is 4.4/min
+	rule := NewSpikeRule(syntheticErrors(2, 8), thresholds)
+	rule.now = func() time.Time { return spikeNow }
+
+	alert, err := rule.Evaluate(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, alert)
+	assert.Equal(t, 8.0, alert.MetricValue)
+	assert.InDelta(t, 6.6, alert.Threshold, 0.001)
+}
+
+func TestSpikeRule_DisabledWithoutMultiplier(t *testing.T) {
+	thresholds := DefaultAlertThresholds()
+	thresholds.SpikeMultiplier = 0
+	assert.Nil(t, NewSpikeRule(syntheticErrors(0, 100), thresholds))
+}
+
+func TestSpikeRule_SourceError(t *testing.T) {
+	_, err := newTestSpikeRule(&fakeErrorCounts{err: errors.New("connection refused")}).Evaluate(context.Background())
+	assert.Error(t, err)
+}
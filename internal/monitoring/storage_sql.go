@@ -110,3 +110,19 @@ func (c *SQLMetricsCollector) GetResponseTimes(ctx context.Context, window time.
 
 	return responseTimes, nil
 }
+
+// CountErrors returns the number of 4xx/5xx responses recorded in [since, until)
+func (c *SQLMetricsCollector) CountErrors(ctx context.Context, since, until time.Time) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM monitoring.api_metrics
+		WHERE timestamp >= $1 AND timestamp < $2
+		AND status_code >= 400`
+
+	var count int64
+	if err := c.db.QueryRowContext(ctx, query, since, until).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count errors: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,118 @@
+package portal_handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAppPreferencesBytes bounds the settings an app may store per user
+const maxAppPreferencesBytes = 16 << 10
+
+// AppPreferencesStore reads and replaces a user's settings for an app
+type AppPreferencesStore interface {
+	GetAppPreferences(ctx context.Context, userID int, appName string) (json.RawMessage, error)
+	SetAppPreferences(ctx context.Context, userID int, appName string, preferences json.RawMessage) error
+}
+
+// AppPreferencesHandler handles HTTP requests for per-app user settings
+type AppPreferencesHandler struct {
+	store AppPreferencesStore
+}
+
+// NewAppPreferencesHandler creates a new app preferences handler
+func NewAppPreferencesHandler(store AppPreferencesStore) *AppPreferencesHandler {
+	return &AppPreferencesHandler{
+		store: store,
+	}
+}
+
+// preferenceApps are the apps that may store settings in Portal
+var preferenceApps = map[string]bool{
+	"review":    true,
+	"logs":      true,
+	"analytics": true,
+}
+
+// GetPreferences handles GET /api/portal/app-preferences/:app
+// Returns the user's settings for the app, or {} when none are saved
+func (h *AppPreferencesHandler) GetPreferences(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	appName := c.Param("app")
+	if !preferenceApps[appName] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid app name. Must be one of: review, logs, analytics"})
+		return
+	}
+
+	preferences, err := h.store.GetAppPreferences(c.Request.Context(), userID, appName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve preferences"})
+		return
+	}
+	if preferences == nil {
+		preferences = json.RawMessage("{}")
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/json; charset=utf-8", preferences)
+}
+
+// SetPreferences handles PUT /api/portal/app-preferences/:app
+// Body: a JSON object replacing the user's settings for the app. Portal
+// doesn't interpret the keys; the app validates them before saving.
+func (h *AppPreferencesHandler) SetPreferences(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	appName := c.Param("app")
+	if !preferenceApps[appName] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid app name. Must be one of: review, logs, analytics"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAppPreferencesBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if len(body) > maxAppPreferencesBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Preferences are too large"})
+		return
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil || object == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Preferences must be a JSON object"})
+		return
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Preferences must be a JSON object"})
+		return
+	}
+	if err := h.store.SetAppPreferences(c.Request.Context(), userID, appName, compact.Bytes()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save preferences"})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", compact.Bytes())
+}
+
+// RegisterAppPreferencesRoutes registers the app preferences routes with the router group
+// The router group should already have session authentication middleware applied
+func RegisterAppPreferencesRoutes(routerGroup *gin.RouterGroup, store AppPreferencesStore) {
+	handler := NewAppPreferencesHandler(store)
+
+	routerGroup.GET("/app-preferences/:app", handler.GetPreferences)
+	routerGroup.PUT("/app-preferences/:app", handler.SetPreferences)
+}
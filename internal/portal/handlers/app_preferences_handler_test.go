@@ -0,0 +1,74 @@
+package portal_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePreferencesStore keeps settings per user and app
+type fakePreferencesStore struct {
+	saved map[string]json.RawMessage
+}
+
+func (s *fakePreferencesStore) GetAppPreferences(ctx context.Context, userID int, appName string) (json.RawMessage, error) {
+	return s.saved[appName], nil
+}
+
+func (s *fakePreferencesStore) SetAppPreferences(ctx context.Context, userID int, appName string, preferences json.RawMessage) error {
+	s.saved[appName] = preferences
+	return nil
+}
+
+func servePreferences(store AppPreferencesStore, method, path, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("/api/portal")
+	group.Use(func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	})
+	RegisterAppPreferencesRoutes(group, store)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w
+}
+
+func TestAppPreferencesHandler_RoundTrip(t *testing.T) {
+	store := &fakePreferencesStore{saved: map[string]json.RawMessage{}}
+
+	w := servePreferences(store, http.MethodGet, "/api/portal/app-preferences/review", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{}`, w.Body.String(), "nothing saved yet")
+
+	w = servePreferences(store, http.MethodPut, "/api/portal/app-preferences/review", `{"default_mode": "skim", "user_mode": "expert"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = servePreferences(store, http.MethodGet, "/api/portal/app-preferences/review", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"default_mode": "skim", "user_mode": "expert"}`, w.Body.String())
+}
+
+func TestAppPreferencesHandler_RejectsInvalidInput(t *testing.T) {
+	store := &fakePreferencesStore{saved: map[string]json.RawMessage{}}
+
+	w := servePreferences(store, http.MethodGet, "/api/portal/app-preferences/unknown", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	for _, body := range []string{`["skim"]`, `"skim"`, `null`, `{"default_mode":`} {
+		w = servePreferences(store, http.MethodPut, "/api/portal/app-preferences/review", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	w = servePreferences(store, http.MethodPut, "/api/portal/app-preferences/review", `{"notes": "`+strings.Repeat("x", maxAppPreferencesBytes)+`"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Empty(t, store.saved)
+}
@@ -0,0 +1,94 @@
+package portal_handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	portal_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/services"
+)
+
+// BudgetManager reads and changes a user's monthly AI budget
+type BudgetManager interface {
+	Status(ctx context.Context, userID int) (*portal_services.BudgetStatus, error)
+	SetMonthlyLimit(ctx context.Context, userID int, limitUSD *float64) error
+}
+
+// LLMBudgetHandler handles HTTP requests for the monthly AI budget
+type LLMBudgetHandler struct {
+	budgets BudgetManager
+}
+
+// NewLLMBudgetHandler creates a new LLM budget handler
+func NewLLMBudgetHandler(budgets BudgetManager) *LLMBudgetHandler {
+	return &LLMBudgetHandler{
+		budgets: budgets,
+	}
+}
+
+// GetBudget handles GET /api/portal/llm/budget
+// Returns the user's monthly limit (null when unlimited) and spend this month.
+// AI clients check exceeded before each call.
+func (h *LLMBudgetHandler) GetBudget(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	status, err := h.budgets.Status(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve budget"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"monthly_limit_usd": status.LimitUSD,
+		"spent_usd":         status.SpentUSD,
+		"period_start":      status.PeriodStart,
+		"exceeded":          status.Exceeded,
+	})
+}
+
+// SetBudget handles PUT /api/portal/llm/budget
+// Body: {"monthly_limit_usd": 25.0}, or null to remove the limit
+func (h *LLMBudgetHandler) SetBudget(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var req struct {
+		MonthlyLimitUSD *float64 `json:"monthly_limit_usd"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	err := h.budgets.SetMonthlyLimit(c.Request.Context(), userID, req.MonthlyLimitUSD)
+	if errors.Is(err, portal_services.ErrInvalidBudget) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update budget"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":           true,
+		"monthly_limit_usd": req.MonthlyLimitUSD,
+	})
+}
+
+// RegisterLLMBudgetRoutes registers the LLM budget routes with the router group
+// The router group should already have session authentication middleware applied
+func RegisterLLMBudgetRoutes(routerGroup *gin.RouterGroup, budgets BudgetManager) {
+	handler := NewLLMBudgetHandler(budgets)
+
+	routerGroup.GET("/llm/budget", handler.GetBudget)
+	routerGroup.PUT("/llm/budget", handler.SetBudget)
+}
@@ -1,6 +1,8 @@
 package portal_handlers
 
 import (
+	"crypto/subtle"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -388,6 +390,87 @@ func (h *LLMConfigHandler) SetAppPreference(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "App preference set successfully"})
 }
 
+// ServiceTokenHeader carries the shared secret internal services present to
+// fetch a resolved configuration with its decrypted API key.
+const ServiceTokenHeader = "X-Service-Token"
+
+// ResolveAppModel handles GET /api/portal/app-llm-preferences/:app/resolved
+// Returns the configuration the app should use. The API key is never
+// included; has_api_key reports whether one is stored.
+func (h *LLMConfigHandler) ResolveAppModel(c *gin.Context) {
+	h.resolveAppModel(c, false)
+}
+
+// ResolveAppModelForService handles
+// GET /api/portal/internal/app-llm-preferences/:app/resolved
+// Same as ResolveAppModel, plus the decrypted API key, for services calling
+// the AI provider on the user's behalf. Only reachable with the service token.
+func (h *LLMConfigHandler) ResolveAppModelForService(c *gin.Context) {
+	h.resolveAppModel(c, true)
+}
+
+func (h *LLMConfigHandler) resolveAppModel(c *gin.Context, withAPIKey bool) {
+	// Extract user ID
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	// Validate app name
+	appName := c.Param("app")
+	validApps := map[string]bool{
+		"review":    true,
+		"logs":      true,
+		"analytics": true,
+	}
+	if !validApps[appName] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid app name. Must be one of: review, logs, analytics"})
+		return
+	}
+
+	resolved, err := h.service.ResolveModelForApp(c.Request.Context(), userID, appName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve model"})
+		return
+	}
+
+	config := resolved.Config
+	response := gin.H{
+		"id":          config.ID,
+		"user_id":     config.UserID,
+		"provider":    config.Provider,
+		"model_name":  config.ModelName,
+		"is_default":  config.IsDefault,
+		"max_tokens":  config.MaxTokens,
+		"temperature": config.Temperature,
+		"source":      resolved.Source,
+		"has_api_key": resolved.APIKey != "",
+	}
+	if config.APIEndpoint.Valid {
+		response["api_endpoint"] = config.APIEndpoint.String
+	}
+	if withAPIKey && resolved.APIKey != "" {
+		response["api_key"] = resolved.APIKey
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// ServiceTokenMiddleware rejects requests that don't carry token in
+// ServiceTokenHeader. An empty token rejects everything.
+func ServiceTokenMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		presented := c.GetHeader(ServiceTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Service token required"})
+			return
+		}
+		c.Next()
+	}
+}
+
 // GetUsageSummary handles GET /api/portal/llm-usage/summary
 // Returns usage statistics for the authenticated user
 func (h *LLMConfigHandler) GetUsageSummary(c *gin.Context) {
@@ -444,12 +527,17 @@ func (h *LLMConfigHandler) SetDefaultConfig(c *gin.Context) {
 		return
 	}
 
-	// Update via service
-	updates := map[string]interface{}{
-		"is_default": req.IsDefault,
+	// Setting a default clears the user's other defaults in one transaction
+	var err error
+	if req.IsDefault {
+		err = h.service.SetDefault(c.Request.Context(), userID, configID)
+	} else {
+		err = h.service.UpdateConfig(c.Request.Context(), userID, configID, map[string]interface{}{
+			"is_default": false,
+		})
 	}
 
-	if err := h.service.UpdateConfig(c.Request.Context(), userID, configID, updates); err != nil {
+	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Configuration not found"})
 			return
@@ -468,6 +556,44 @@ func (h *LLMConfigHandler) SetDefaultConfig(c *gin.Context) {
 	})
 }
 
+// GetDefaultConfig handles GET /api/portal/llm-configs/default
+// Returns the user's default LLM configuration
+func (h *LLMConfigHandler) GetDefaultConfig(c *gin.Context) {
+	// Extract user ID
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	config, err := h.service.GetDefault(c.Request.Context(), userID)
+	if errors.Is(err, portal_services.ErrNoDefaultConfig) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No default configuration set"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve default configuration"})
+		return
+	}
+
+	// Exclude encrypted API key
+	response := gin.H{
+		"id":          config.ID,
+		"name":        config.Provider + " - " + config.ModelName,
+		"provider":    config.Provider,
+		"model":       config.ModelName,
+		"is_default":  config.IsDefault,
+		"created_at":  config.CreatedAt,
+		"updated_at":  config.UpdatedAt,
+		"has_api_key": config.APIKeyEncrypted.Valid && config.APIKeyEncrypted.String != "",
+	}
+	if config.APIEndpoint.Valid {
+		response["endpoint"] = config.APIEndpoint.String
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // RegisterLLMConfigRoutes registers all LLM configuration routes with the router group
 // The router group should already have authentication middleware applied
 func RegisterLLMConfigRoutes(routerGroup *gin.RouterGroup, service *portal_services.LLMConfigService) {
@@ -476,11 +602,23 @@ func RegisterLLMConfigRoutes(routerGroup *gin.RouterGroup, service *portal_servi
 	// All routes are within the provided group (which already has /api/portal prefix)
 	routerGroup.GET("/llm-configs", handler.ListLLMConfigs)
 	routerGroup.POST("/llm-configs", handler.CreateLLMConfig)
+	routerGroup.GET("/llm-configs/default", handler.GetDefaultConfig)
 	routerGroup.PUT("/llm-configs/:id", handler.UpdateLLMConfig)
 	routerGroup.PUT("/llm-configs/:id/set-default", handler.SetDefaultConfig)
 	routerGroup.DELETE("/llm-configs/:id", handler.DeleteLLMConfig)
 	routerGroup.POST("/llm-configs/test", handler.TestLLMConnection)
 	routerGroup.GET("/app-llm-preferences", handler.GetAppPreferences)
 	routerGroup.PUT("/app-llm-preferences/:app", handler.SetAppPreference)
+	routerGroup.GET("/app-llm-preferences/:app/resolved", handler.ResolveAppModel)
 	routerGroup.GET("/llm-usage/summary", handler.GetUsageSummary)
 }
+
+// RegisterInternalLLMConfigRoutes registers the routes other services use to
+// act on the user's behalf. They need both the user's session and the shared
+// service token, so the decrypted API key never reaches a browser.
+func RegisterInternalLLMConfigRoutes(routerGroup *gin.RouterGroup, service *portal_services.LLMConfigService, serviceToken string) {
+	handler := NewLLMConfigHandler(service)
+
+	internal := routerGroup.Group("/internal", ServiceTokenMiddleware(serviceToken))
+	internal.GET("/app-llm-preferences/:app/resolved", handler.ResolveAppModelForService)
+}
@@ -0,0 +1,41 @@
+package portal_handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceTokenMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		configured string
+		presented  string
+		want       int
+	}{
+		{"matching token", "svc-secret", "svc-secret", http.StatusOK},
+		{"wrong token", "svc-secret", "guess", http.StatusForbidden},
+		{"no token presented", "svc-secret", "", http.StatusForbidden},
+		{"no token configured", "", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/internal", ServiceTokenMiddleware(tt.configured), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/internal", http.NoBody)
+			if tt.presented != "" {
+				req.Header.Set(ServiceTokenHeader, tt.presented)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
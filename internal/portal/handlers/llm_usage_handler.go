@@ -0,0 +1,122 @@
+package portal_handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
+)
+
+// defaultUsagePeriod is the window reported when no from is given
+const defaultUsagePeriod = 30 * 24 * time.Hour
+
+// UsageSummaryStore aggregates recorded LLM usage
+type UsageSummaryStore interface {
+	SummarizeByUser(ctx context.Context, userID int, from, to time.Time) ([]*portal_repositories.LLMUsageSummary, error)
+}
+
+// LLMUsageHandler handles HTTP requests for LLM usage and cost reporting
+type LLMUsageHandler struct {
+	store UsageSummaryStore
+	now   func() time.Time
+}
+
+// NewLLMUsageHandler creates a new LLM usage handler
+func NewLLMUsageHandler(store UsageSummaryStore) *LLMUsageHandler {
+	return &LLMUsageHandler{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// GetUsage handles GET /api/portal/llm/usage?from=&to=
+// Returns the authenticated user's tokens and cost per app and model.
+// from and to are RFC 3339 timestamps or dates; a date for to includes that whole day.
+// Defaults to the last 30 days.
+func (h *LLMUsageHandler) GetUsage(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	to := h.now()
+	if raw := c.Query("to"); raw != "" {
+		parsed, dateOnly, ok := parseUsageTime(raw)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to: use RFC 3339 or YYYY-MM-DD"})
+			return
+		}
+		to = parsed
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+	}
+	from := to.Add(-defaultUsagePeriod)
+	if raw := c.Query("from"); raw != "" {
+		parsed, _, ok := parseUsageTime(raw)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from: use RFC 3339 or YYYY-MM-DD"})
+			return
+		}
+		from = parsed
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	summaries, err := h.store.SummarizeByUser(c.Request.Context(), userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve usage"})
+		return
+	}
+
+	var totalCost float64
+	var totalTokens, totalRequests int64
+	usage := make([]gin.H, 0, len(summaries))
+	for _, summary := range summaries {
+		totalCost += summary.CostUSD
+		totalTokens += summary.TokensUsed
+		totalRequests += summary.Requests
+		usage = append(usage, gin.H{
+			"app":             summary.AppName,
+			"provider":        summary.Provider,
+			"model":           summary.ModelName,
+			"requests":        summary.Requests,
+			"failed_requests": summary.FailedRequests,
+			"tokens_used":     summary.TokensUsed,
+			"cost_usd":        summary.CostUSD,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":           from,
+		"to":             to,
+		"total_cost_usd": totalCost,
+		"total_tokens":   totalTokens,
+		"total_requests": totalRequests,
+		"usage":          usage,
+	})
+}
+
+// parseUsageTime accepts an RFC 3339 timestamp or a UTC date
+func parseUsageTime(raw string) (t time.Time, dateOnly bool, ok bool) {
+	if parsed, err := time.Parse(time.RFC3339, raw); err == nil {
+		return parsed, false, true
+	}
+	if parsed, err := time.Parse(time.DateOnly, raw); err == nil {
+		return parsed, true, true
+	}
+	return time.Time{}, false, false
+}
+
+// RegisterLLMUsageRoutes registers the LLM usage routes with the router group
+// The router group should already have session authentication middleware applied
+func RegisterLLMUsageRoutes(routerGroup *gin.RouterGroup, store UsageSummaryStore) {
+	handler := NewLLMUsageHandler(store)
+
+	routerGroup.GET("/llm/usage", handler.GetUsage)
+}
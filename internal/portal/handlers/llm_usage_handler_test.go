@@ -0,0 +1,90 @@
+package portal_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUsageStore records the query and returns fixed summaries
+type fakeUsageStore struct {
+	userID    int
+	from, to  time.Time
+	summaries []*portal_repositories.LLMUsageSummary
+}
+
+func (s *fakeUsageStore) SummarizeByUser(ctx context.Context, userID int, from, to time.Time) ([]*portal_repositories.LLMUsageSummary, error) {
+	s.userID, s.from, s.to = userID, from, to
+	return s.summaries, nil
+}
+
+func serveUsage(store UsageSummaryStore, query string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("/api/portal")
+	group.Use(func(c *gin.Context) {
+		c.Set("user_id", 7)
+		c.Next()
+	})
+	RegisterLLMUsageRoutes(group, store)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/portal/llm/usage"+query, http.NoBody))
+	return w
+}
+
+func TestLLMUsageHandler_GetUsage(t *testing.T) {
+	store := &fakeUsageStore{summaries: []*portal_repositories.LLMUsageSummary{
+		{AppName: "review", Provider: "openai", ModelName: "gpt-4o", Requests: 3, TokensUsed: 4500, CostUSD: 0.0375},
+		{AppName: "logs", Provider: "ollama", ModelName: "llama3", Requests: 2, FailedRequests: 1, TokensUsed: 800},
+	}}
+
+	w := serveUsage(store, "?from=2025-11-01&to=2025-11-30")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, 7, store.userID)
+	assert.Equal(t, time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), store.from)
+	assert.Equal(t, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), store.to, "a to date includes the whole day")
+
+	var body struct {
+		TotalCost     float64          `json:"total_cost_usd"`
+		TotalTokens   int64            `json:"total_tokens"`
+		TotalRequests int64            `json:"total_requests"`
+		Usage         []map[string]any `json:"usage"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.InDelta(t, 0.0375, body.TotalCost, 1e-9)
+	assert.Equal(t, int64(5300), body.TotalTokens)
+	assert.Equal(t, int64(5), body.TotalRequests)
+	require.Len(t, body.Usage, 2)
+	assert.Equal(t, "gpt-4o", body.Usage[0]["model"])
+	assert.Equal(t, float64(1), body.Usage[1]["failed_requests"])
+}
+
+func TestLLMUsageHandler_GetUsageRange(t *testing.T) {
+	store := &fakeUsageStore{}
+	handlerNow := time.Date(2025, 11, 20, 12, 0, 0, 0, time.UTC)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewLLMUsageHandler(store)
+	handler.now = func() time.Time { return handlerNow }
+	router.GET("/usage", func(c *gin.Context) { c.Set("user_id", 7); handler.GetUsage(c) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/usage", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, handlerNow, store.to)
+	assert.Equal(t, handlerNow.Add(-defaultUsagePeriod), store.from, "defaults to the last 30 days")
+
+	assert.Equal(t, http.StatusBadRequest, serveUsage(store, "?from=yesterday").Code)
+	assert.Equal(t, http.StatusBadRequest, serveUsage(store, "?from=2025-11-30&to=2025-11-01").Code)
+}
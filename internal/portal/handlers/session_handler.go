@@ -0,0 +1,143 @@
+package portal_handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/middleware"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
+)
+
+// SessionStore is the session storage needed to manage a user's sessions
+type SessionStore interface {
+	Get(ctx context.Context, sessionID string) (*session.Session, error)
+	ListByUser(ctx context.Context, userID int) ([]*session.Session, error)
+	Delete(ctx context.Context, sessionID string) error
+}
+
+// SessionHandler handles HTTP requests for users managing their own sessions
+type SessionHandler struct {
+	store SessionStore
+}
+
+// NewSessionHandler creates a new session management handler
+func NewSessionHandler(store SessionStore) *SessionHandler {
+	return &SessionHandler{
+		store: store,
+	}
+}
+
+// ListSessions handles GET /api/portal/auth/sessions
+// Returns the authenticated user's active sessions across devices
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	sessions, err := h.store.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+
+	// Only metadata; never the GitHub token
+	currentID := c.GetString("session_id")
+	response := make([]gin.H, 0, len(sessions))
+	for _, sess := range sessions {
+		userAgent, _ := sess.Metadata["user_agent"].(string)
+		response = append(response, gin.H{
+			"id":               sess.SessionID,
+			"created_at":       sess.CreatedAt,
+			"last_accessed_at": sess.LastAccessedAt,
+			"user_agent":       userAgent,
+			"current":          sess.SessionID == currentID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": response})
+}
+
+// RevokeSession handles DELETE /api/portal/auth/sessions/:id
+// Ends one of the authenticated user's sessions
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	sessionID := c.Param("id")
+	sess, err := h.store.Get(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve session"})
+		return
+	}
+	// Someone else's session is reported the same as a missing one
+	if sess == nil || sess.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := h.store.Delete(c.Request.Context(), sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Session revoked",
+	})
+}
+
+// RevokeOtherSessions handles POST /api/portal/auth/sessions/revoke-others
+// Ends every session of the authenticated user except the current one
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, exists := getUserIDFromContext(c)
+	currentID := c.GetString("session_id")
+	if !exists || currentID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	// Expired sessions are already left out of the list
+	sessions, err := h.store.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sessions"})
+		return
+	}
+
+	revoked := 0
+	for _, sess := range sessions {
+		if sess.SessionID == currentID {
+			continue
+		}
+		if err := h.store.Delete(c.Request.Context(), sess.SessionID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to revoke sessions",
+				"revoked": revoked,
+			})
+			return
+		}
+		revoked++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"revoked": revoked,
+	})
+}
+
+// RegisterSessionRoutes registers the session management routes with the router group
+// The router group should already have session authentication middleware applied
+func RegisterSessionRoutes(routerGroup *gin.RouterGroup, store SessionStore) {
+	handler := NewSessionHandler(store)
+	csrf := middleware.CSRFMiddleware()
+
+	// All routes are within the provided group (which already has /api/portal prefix)
+	routerGroup.GET("/auth/sessions", csrf, handler.ListSessions)
+	routerGroup.DELETE("/auth/sessions/:id", csrf, handler.RevokeSession)
+	routerGroup.POST("/auth/sessions/revoke-others", csrf, handler.RevokeOtherSessions)
+}
@@ -0,0 +1,178 @@
+package portal_handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/middleware"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSessionTestStore returns a session store backed by an in-process Redis
+func newSessionTestStore(t *testing.T) (*session.RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "session-handler-test-secret") // signs CSRF tokens
+	mr := miniredis.RunT(t)
+	store, err := session.NewRedisStore(mr.Addr(), time.Hour)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store, mr
+}
+
+// newSessionTestRouter serves the session routes as the given user and
+// session, standing in for the session auth middleware
+func newSessionTestRouter(store SessionStore, userID int, currentID string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("/api/portal")
+	group.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("session_id", currentID)
+		c.Next()
+	})
+	RegisterSessionRoutes(group, store)
+	return router
+}
+
+// csrfRequest builds a mutating request carrying the CSRF token the router
+// issues when the session list is loaded, like the frontend does
+func csrfRequest(t *testing.T, router *gin.Engine, method, path string) *http.Request {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/portal/auth/sessions", http.NoBody))
+	req := httptest.NewRequest(method, path, http.NoBody)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == middleware.CSRFCookieName {
+			req.AddCookie(cookie)
+			req.Header.Set(middleware.CSRFHeaderName, cookie.Value)
+		}
+	}
+	require.NotEmpty(t, req.Header.Get(middleware.CSRFHeaderName), "the session list issues a CSRF token")
+	return req
+}
+
+// createSessions creates one session per user agent for userID
+func createSessions(t *testing.T, store *session.RedisStore, userID int, agents ...string) []string {
+	t.Helper()
+	ids := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		id, err := store.Create(context.Background(), &session.Session{
+			UserID:      userID,
+			GitHubToken: "gho_secret", // ggignore - test token
+			Metadata:    map[string]interface{}{"user_agent": agent},
+		})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestSessionHandler_ListSessions(t *testing.T) {
+	store, _ := newSessionTestStore(t)
+	ids := createSessions(t, store, 1, "Firefox", "Safari")
+	createSessions(t, store, 2, "Chrome")
+	router := newSessionTestRouter(store, 1, ids[1])
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/portal/auth/sessions", http.NoBody))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "gho_secret", "tokens are never listed")
+
+	var body struct {
+		Sessions []struct {
+			ID             string    `json:"id"`
+			CreatedAt      time.Time `json:"created_at"`
+			LastAccessedAt time.Time `json:"last_accessed_at"`
+			UserAgent      string    `json:"user_agent"`
+			Current        bool      `json:"current"`
+		} `json:"sessions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Sessions, 2)
+	agents := map[string]bool{}
+	for _, s := range body.Sessions {
+		agents[s.UserAgent] = s.Current
+		assert.False(t, s.CreatedAt.IsZero())
+		assert.False(t, s.LastAccessedAt.IsZero())
+	}
+	assert.Equal(t, map[string]bool{"Firefox": false, "Safari": true}, agents)
+}
+
+func TestSessionHandler_RevokeSession(t *testing.T) {
+	store, _ := newSessionTestStore(t)
+	mine := createSessions(t, store, 1, "Firefox", "Safari")
+	theirs := createSessions(t, store, 2, "Chrome")
+	router := newSessionTestRouter(store, 1, mine[1])
+
+	revoke := func(id string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, csrfRequest(t, router, http.MethodDelete, "/api/portal/auth/sessions/"+id))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, revoke(mine[0]))
+	assert.Equal(t, http.StatusNotFound, revoke(mine[0]), "already revoked")
+	assert.Equal(t, http.StatusNotFound, revoke(theirs[0]), "other users' sessions can't be revoked")
+
+	ctx := context.Background()
+	remaining, err := store.ListByUser(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, mine[1], remaining[0].SessionID)
+
+	other, err := store.Get(ctx, theirs[0])
+	require.NoError(t, err)
+	assert.NotNil(t, other)
+}
+
+func TestSessionHandler_RevokeOtherSessions(t *testing.T) {
+	store, mr := newSessionTestStore(t)
+	mine := createSessions(t, store, 1, "Firefox", "Safari", "Edge")
+	theirs := createSessions(t, store, 2, "Chrome")
+	// A session that expired but is still in the index
+	_, err := mr.SAdd("user_sessions:1", "expired-session")
+	require.NoError(t, err)
+	router := newSessionTestRouter(store, 1, mine[1])
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, csrfRequest(t, router, http.MethodPost, "/api/portal/auth/sessions/revoke-others"))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Revoked int `json:"revoked"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 2, body.Revoked)
+
+	ctx := context.Background()
+	remaining, err := store.ListByUser(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, mine[1], remaining[0].SessionID, "the current session survives")
+
+	other, err := store.Get(ctx, theirs[0])
+	require.NoError(t, err)
+	assert.NotNil(t, other, "other users' sessions are untouched")
+}
+
+func TestSessionHandler_RevokeOtherSessionsRequiresCurrentSession(t *testing.T) {
+	store, _ := newSessionTestStore(t)
+	createSessions(t, store, 1, "Firefox")
+	router := newSessionTestRouter(store, 1, "")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/portal/auth/sessions/revoke-others", http.NoBody))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	remaining, err := store.ListByUser(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Len(t, remaining, 1)
+}
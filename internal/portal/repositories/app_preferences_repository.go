@@ -0,0 +1,56 @@
+package portal_repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	querySelectAppPreferences = `SELECT preferences FROM portal.app_preferences WHERE user_id = $1 AND app_name = $2`
+	queryUpsertAppPreferences = `
+		INSERT INTO portal.app_preferences (user_id, app_name, preferences, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (user_id, app_name) DO UPDATE SET preferences = $3, updated_at = $4
+	`
+)
+
+// AppPreferencesRepository stores each user's settings for an app as a JSON object
+type AppPreferencesRepository interface {
+	// GetAppPreferences returns nil when the user has saved nothing for the app
+	GetAppPreferences(ctx context.Context, userID int, appName string) (json.RawMessage, error)
+	SetAppPreferences(ctx context.Context, userID int, appName string, preferences json.RawMessage) error
+}
+
+// PostgresAppPreferencesRepository implements AppPreferencesRepository with PostgreSQL
+type PostgresAppPreferencesRepository struct {
+	db *sql.DB
+}
+
+// NewAppPreferencesRepository creates a new PostgreSQL app preferences repository
+func NewAppPreferencesRepository(db *sql.DB) *PostgresAppPreferencesRepository {
+	return &PostgresAppPreferencesRepository{db: db}
+}
+
+// GetAppPreferences retrieves the user's settings for appName
+func (r *PostgresAppPreferencesRepository) GetAppPreferences(ctx context.Context, userID int, appName string) (json.RawMessage, error) {
+	var preferences []byte
+	err := r.db.QueryRowContext(ctx, querySelectAppPreferences, userID, appName).Scan(&preferences)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s preferences for user %d: %w", appName, userID, err)
+	}
+	return preferences, nil
+}
+
+// SetAppPreferences replaces the user's settings for appName
+func (r *PostgresAppPreferencesRepository) SetAppPreferences(ctx context.Context, userID int, appName string, preferences json.RawMessage) error {
+	if _, err := r.db.ExecContext(ctx, queryUpsertAppPreferences, userID, appName, []byte(preferences), time.Now()); err != nil {
+		return fmt.Errorf("failed to set %s preferences for user %d: %w", appName, userID, err)
+	}
+	return nil
+}
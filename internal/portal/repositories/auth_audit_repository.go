@@ -0,0 +1,76 @@
+package portal_repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const queryInsertAuthAudit = `
+	INSERT INTO portal.auth_audit (event_type, outcome, user_id, github_id, ip_address, user_agent, reason, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+// Authentication audit event types
+const (
+	AuthEventLogin           = "login"
+	AuthEventLogout          = "logout"
+	AuthEventTokenExchange   = "token_exchange"
+	AuthEventStateValidation = "oauth_state_validation"
+)
+
+// Authentication audit outcomes
+const (
+	AuthOutcomeSuccess = "success"
+	AuthOutcomeFailure = "failure"
+)
+
+// AuthAuditEvent is one entry in the authentication audit trail. UserID and
+// GitHubID are zero when not yet known; Reason is only set on failure.
+type AuthAuditEvent struct {
+	CreatedAt time.Time
+	EventType string
+	Outcome   string
+	IPAddress string
+	UserAgent string
+	Reason    string
+	GitHubID  int64
+	UserID    int
+}
+
+// AuthAuditRepository appends authentication events to portal.auth_audit
+type AuthAuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuthAuditRepository creates a new PostgreSQL authentication audit repository
+func NewAuthAuditRepository(db *sql.DB) *AuthAuditRepository {
+	return &AuthAuditRepository{db: db}
+}
+
+// Record inserts event, stamping CreatedAt when it is unset
+func (r *AuthAuditRepository) Record(ctx context.Context, event *AuthAuditEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx, queryInsertAuthAudit,
+		event.EventType,
+		event.Outcome,
+		nullableInt(int64(event.UserID)),
+		nullableInt(event.GitHubID),
+		event.IPAddress,
+		event.UserAgent,
+		sql.NullString{String: event.Reason, Valid: event.Reason != ""},
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record %s audit event: %w", event.EventType, err)
+	}
+	return nil
+}
+
+// nullableInt stores unknown (zero) IDs as NULL
+func nullableInt(v int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: v, Valid: v != 0}
+}
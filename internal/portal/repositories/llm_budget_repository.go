@@ -0,0 +1,65 @@
+package portal_repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+	querySelectBudget = `SELECT monthly_limit_usd::float8 FROM portal.llm_budgets WHERE user_id = $1`
+	queryUpsertBudget = `
+		INSERT INTO portal.llm_budgets (user_id, monthly_limit_usd, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (user_id) DO UPDATE SET monthly_limit_usd = $2, updated_at = $3
+	`
+	queryDeleteBudget = `DELETE FROM portal.llm_budgets WHERE user_id = $1`
+)
+
+// LLMBudgetRepository stores each user's optional monthly AI budget
+type LLMBudgetRepository interface {
+	// GetMonthlyLimit returns nil when the user has no limit
+	GetMonthlyLimit(ctx context.Context, userID int) (*float64, error)
+	SetMonthlyLimit(ctx context.Context, userID int, limitUSD float64) error
+	ClearMonthlyLimit(ctx context.Context, userID int) error
+}
+
+// PostgresLLMBudgetRepository implements LLMBudgetRepository with PostgreSQL
+type PostgresLLMBudgetRepository struct {
+	db *sql.DB
+}
+
+// NewLLMBudgetRepository creates a new PostgreSQL LLM budget repository
+func NewLLMBudgetRepository(db *sql.DB) *PostgresLLMBudgetRepository {
+	return &PostgresLLMBudgetRepository{db: db}
+}
+
+// GetMonthlyLimit retrieves the user's monthly limit in USD
+func (r *PostgresLLMBudgetRepository) GetMonthlyLimit(ctx context.Context, userID int) (*float64, error) {
+	var limit float64
+	err := r.db.QueryRowContext(ctx, querySelectBudget, userID).Scan(&limit)
+	if err == sql.ErrNoRows {
+		return nil, nil // No budget = unlimited, not error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM budget for user %d: %w", userID, err)
+	}
+	return &limit, nil
+}
+
+// SetMonthlyLimit creates or replaces the user's monthly limit
+func (r *PostgresLLMBudgetRepository) SetMonthlyLimit(ctx context.Context, userID int, limitUSD float64) error {
+	if _, err := r.db.ExecContext(ctx, queryUpsertBudget, userID, limitUSD, time.Now()); err != nil {
+		return fmt.Errorf("failed to set LLM budget for user %d: %w", userID, err)
+	}
+	return nil
+}
+
+// ClearMonthlyLimit removes the user's limit, making usage unlimited
+func (r *PostgresLLMBudgetRepository) ClearMonthlyLimit(ctx context.Context, userID int) error {
+	if _, err := r.db.ExecContext(ctx, queryDeleteBudget, userID); err != nil {
+		return fmt.Errorf("failed to clear LLM budget for user %d: %w", userID, err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package portal_repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -51,7 +52,7 @@ const (
 	`
 
 	queryClearDefaults       = `UPDATE portal.llm_configs SET is_default = false WHERE user_id = $1`
-	querySetDefault          = `UPDATE portal.llm_configs SET is_default = true WHERE id = $1`
+	querySetDefault          = `UPDATE portal.llm_configs SET is_default = true WHERE id = $1 AND user_id = $2`
 	querySelectAppPreference = `
 		SELECT id, user_id, app_name, llm_config_id, created_at, updated_at
 		FROM portal.app_llm_preferences
@@ -67,6 +68,20 @@ const (
 
 	queryDeleteAppPreference = `DELETE FROM portal.app_llm_preferences WHERE user_id = $1 AND app_name = $2`
 
+	querySelectConfigsWithAPIKey = `
+		SELECT id, user_id, provider, model_name, api_key_encrypted, api_endpoint,
+		       is_default, max_tokens, temperature, created_at, updated_at
+		FROM portal.llm_configs
+		WHERE api_key_encrypted IS NOT NULL
+		ORDER BY created_at
+	`
+
+	queryReplaceAPIKey = `
+		UPDATE portal.llm_configs
+		SET api_key_encrypted = $3, updated_at = NOW()
+		WHERE id = $1 AND api_key_encrypted = $2
+	`
+
 	querySelectAllAppPreferences = `
 		SELECT id, user_id, app_name, llm_config_id, created_at, updated_at
 		FROM portal.app_llm_preferences
@@ -75,6 +90,9 @@ const (
 	`
 )
 
+// ErrConfigNotFound is returned by SetDefault when the user has no config with the given ID
+var ErrConfigNotFound = errors.New("LLM config not found")
+
 // LLMConfig represents a user's LLM configuration
 type LLMConfig struct {
 	ID              string
@@ -118,6 +136,10 @@ type LLMConfigRepository interface {
 	SetAppPreference(ctx context.Context, userID int, appName string, configID string) error
 	ClearAppPreference(ctx context.Context, userID int, appName string) error
 	GetAllAppPreferences(ctx context.Context, userID int) ([]*AppLLMPreference, error)
+
+	// Key Rotation
+	FindAllWithAPIKey(ctx context.Context) ([]*LLMConfig, error)
+	UpdateAPIKey(ctx context.Context, configID, oldEncrypted, newEncrypted string) (bool, error)
 }
 
 // PostgresLLMConfigRepository implements LLMConfigRepository with PostgreSQL
@@ -131,12 +153,26 @@ func NewLLMConfigRepository(db *sql.DB) LLMConfigRepository {
 }
 
 // Create inserts a new LLM configuration
+// A config created as the default clears the user's other defaults in the
+// same transaction, so a failed insert leaves the old default in place
 func (r *PostgresLLMConfigRepository) Create(ctx context.Context, config *LLMConfig) error {
 	config.ID = uuid.New().String()
 	config.CreatedAt = time.Now()
 	config.UpdatedAt = time.Now()
 
-	_, err := r.db.ExecContext(ctx, queryInsertConfig,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for creating LLM config: %w", err)
+	}
+	defer tx.Rollback()
+
+	if config.IsDefault {
+		if _, err := tx.ExecContext(ctx, queryClearDefaults, config.UserID); err != nil {
+			return fmt.Errorf("failed to clear default configs for user %d: %w", config.UserID, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, queryInsertConfig,
 		config.ID, config.UserID, config.Provider, config.ModelName,
 		config.APIKeyEncrypted, config.APIEndpoint, config.IsDefault,
 		config.MaxTokens, config.Temperature, config.CreatedAt, config.UpdatedAt,
@@ -147,6 +183,10 @@ func (r *PostgresLLMConfigRepository) Create(ctx context.Context, config *LLMCon
 			config.UserID, config.Provider, err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit LLM config transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -247,6 +287,7 @@ func (r *PostgresLLMConfigRepository) FindDefaultByUser(ctx context.Context, use
 }
 
 // SetDefault sets a config as the default, clearing other defaults
+// Leaves the existing default in place when the user has no such config
 func (r *PostgresLLMConfigRepository) SetDefault(ctx context.Context, userID int, configID string) error {
 	// Use transaction to ensure atomicity
 	tx, err := r.db.BeginTx(ctx, nil)
@@ -262,10 +303,17 @@ func (r *PostgresLLMConfigRepository) SetDefault(ctx context.Context, userID int
 	}
 
 	// Set new default
-	_, err = tx.ExecContext(ctx, querySetDefault, configID)
+	result, err := tx.ExecContext(ctx, querySetDefault, configID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to set config %s as default for user %d: %w", configID, userID, err)
 	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to set config %s as default for user %d: %w", configID, userID, err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("config %s for user %d: %w", configID, userID, ErrConfigNotFound)
+	}
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit default config transaction: %w", err)
@@ -339,3 +387,46 @@ func (r *PostgresLLMConfigRepository) GetAllAppPreferences(ctx context.Context,
 
 	return prefs, nil
 }
+
+// FindAllWithAPIKey retrieves every config that stores an encrypted API key
+func (r *PostgresLLMConfigRepository) FindAllWithAPIKey(ctx context.Context) ([]*LLMConfig, error) {
+	rows, err := r.db.QueryContext(ctx, querySelectConfigsWithAPIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query LLM configs with API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var configs []*LLMConfig
+	for rows.Next() {
+		config := &LLMConfig{}
+		err := rows.Scan(
+			&config.ID, &config.UserID, &config.Provider, &config.ModelName,
+			&config.APIKeyEncrypted, &config.APIEndpoint, &config.IsDefault,
+			&config.MaxTokens, &config.Temperature, &config.CreatedAt, &config.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan LLM config with API key: %w", err)
+		}
+		configs = append(configs, config)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating LLM configs with API keys: %w", err)
+	}
+
+	return configs, nil
+}
+
+// UpdateAPIKey replaces a config's encrypted API key, only if it is still
+// oldEncrypted. Returns false when the key changed in the meantime.
+func (r *PostgresLLMConfigRepository) UpdateAPIKey(ctx context.Context, configID, oldEncrypted, newEncrypted string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, queryReplaceAPIKey, configID, oldEncrypted, newEncrypted)
+	if err != nil {
+		return false, fmt.Errorf("failed to update API key for LLM config %s: %w", configID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to update API key for LLM config %s: %w", configID, err)
+	}
+	return affected > 0, nil
+}
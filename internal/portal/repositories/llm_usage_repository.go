@@ -0,0 +1,87 @@
+package portal_repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const querySummarizeUsage = `
+	SELECT app_name, provider, model_name,
+	       COUNT(*),
+	       COUNT(*) FILTER (WHERE success IS FALSE),
+	       COALESCE(SUM(tokens_used), 0),
+	       COALESCE(SUM(cost_usd), 0)::float8
+	FROM portal.llm_usage_logs
+	WHERE user_id = $1 AND created_at >= $2 AND created_at < $3
+	GROUP BY app_name, provider, model_name
+	ORDER BY 7 DESC, app_name, model_name
+`
+
+const querySumCostSince = `
+	SELECT COALESCE(SUM(cost_usd), 0)::float8
+	FROM portal.llm_usage_logs
+	WHERE user_id = $1 AND created_at >= $2
+`
+
+// LLMUsageSummary aggregates a user's LLM calls for one app and model
+type LLMUsageSummary struct {
+	AppName        string
+	Provider       string
+	ModelName      string
+	Requests       int64
+	FailedRequests int64
+	TokensUsed     int64
+	CostUSD        float64
+}
+
+// LLMUsageRepository reads the usage rows written by AI clients
+type LLMUsageRepository interface {
+	SummarizeByUser(ctx context.Context, userID int, from, to time.Time) ([]*LLMUsageSummary, error)
+	SpendSince(ctx context.Context, userID int, since time.Time) (float64, error)
+}
+
+// PostgresLLMUsageRepository implements LLMUsageRepository with PostgreSQL
+type PostgresLLMUsageRepository struct {
+	db *sql.DB
+}
+
+// NewLLMUsageRepository creates a new PostgreSQL LLM usage repository
+func NewLLMUsageRepository(db *sql.DB) *PostgresLLMUsageRepository {
+	return &PostgresLLMUsageRepository{db: db}
+}
+
+// SummarizeByUser aggregates usage in [from, to) per app and model, most expensive first
+func (r *PostgresLLMUsageRepository) SummarizeByUser(ctx context.Context, userID int, from, to time.Time) ([]*LLMUsageSummary, error) {
+	rows, err := r.db.QueryContext(ctx, querySummarizeUsage, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query LLM usage for user %d: %w", userID, err)
+	}
+	defer rows.Close()
+
+	summaries := []*LLMUsageSummary{}
+	for rows.Next() {
+		summary := &LLMUsageSummary{}
+		if err := rows.Scan(
+			&summary.AppName, &summary.Provider, &summary.ModelName,
+			&summary.Requests, &summary.FailedRequests, &summary.TokensUsed, &summary.CostUSD,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan LLM usage summary: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating LLM usage summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+// SpendSince returns the user's total LLM cost in USD since the given time
+func (r *PostgresLLMUsageRepository) SpendSince(ctx context.Context, userID int, since time.Time) (float64, error) {
+	var spent float64
+	if err := r.db.QueryRowContext(ctx, querySumCostSince, userID, since).Scan(&spent); err != nil {
+		return 0, fmt.Errorf("failed to sum LLM cost for user %d: %w", userID, err)
+	}
+	return spent, nil
+}
@@ -0,0 +1,130 @@
+package portal_services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
+)
+
+// DefaultBudgetCacheTTL is how long a budget status is reused before the
+// budget and month-to-date spend are read again
+const DefaultBudgetCacheTTL = 60 * time.Second
+
+// ErrInvalidBudget is returned for a negative monthly limit
+var ErrInvalidBudget = errors.New("monthly budget must not be negative")
+
+// BudgetStatus is a user's monthly AI budget and spend so far
+type BudgetStatus struct {
+	LimitUSD    *float64 // nil means unlimited
+	SpentUSD    float64
+	PeriodStart time.Time // Start of the current calendar month, UTC
+	Exceeded    bool      // Spend is over the limit
+}
+
+// BudgetService checks users' AI spend against their optional monthly budget
+type BudgetService struct {
+	budgets portal_repositories.LLMBudgetRepository
+	usage   portal_repositories.LLMUsageRepository
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[int]budgetEntry
+}
+
+type budgetEntry struct {
+	status   BudgetStatus
+	storedAt time.Time
+}
+
+// NewBudgetService creates a new budget service
+func NewBudgetService(
+	budgets portal_repositories.LLMBudgetRepository,
+	usage portal_repositories.LLMUsageRepository,
+) *BudgetService {
+	return &BudgetService{
+		budgets: budgets,
+		usage:   usage,
+		ttl:     DefaultBudgetCacheTTL,
+		now:     time.Now,
+		entries: make(map[int]budgetEntry),
+	}
+}
+
+// SetCacheTTL changes how long budget statuses are cached. Zero or less
+// disables caching.
+func (s *BudgetService) SetCacheTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl = ttl
+	s.entries = make(map[int]budgetEntry)
+}
+
+// Status returns the user's budget and month-to-date spend. Results are
+// cached briefly, so spend may lag recent calls by up to the cache TTL.
+func (s *BudgetService) Status(ctx context.Context, userID int) (*BudgetStatus, error) {
+	now := s.now()
+	s.mu.Lock()
+	entry, ok := s.entries[userID]
+	s.mu.Unlock()
+	if ok && now.Sub(entry.storedAt) < s.ttl {
+		return entry.status.clone(), nil
+	}
+
+	limit, err := s.budgets.GetMonthlyLimit(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	utc := now.UTC()
+	status := BudgetStatus{
+		LimitUSD:    limit,
+		PeriodStart: time.Date(utc.Year(), utc.Month(), 1, 0, 0, 0, 0, time.UTC),
+	}
+	status.SpentUSD, err = s.usage.SpendSince(ctx, userID, status.PeriodStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spend: %w", err)
+	}
+	status.Exceeded = limit != nil && status.SpentUSD > *limit
+
+	s.mu.Lock()
+	if s.ttl > 0 {
+		s.entries[userID] = budgetEntry{status: *status.clone(), storedAt: now}
+	}
+	s.mu.Unlock()
+	return &status, nil
+}
+
+// SetMonthlyLimit sets the user's monthly limit in USD; nil removes it
+func (s *BudgetService) SetMonthlyLimit(ctx context.Context, userID int, limitUSD *float64) error {
+	var err error
+	switch {
+	case limitUSD == nil:
+		err = s.budgets.ClearMonthlyLimit(ctx, userID)
+	case *limitUSD < 0:
+		return ErrInvalidBudget
+	default:
+		err = s.budgets.SetMonthlyLimit(ctx, userID, *limitUSD)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.entries, userID)
+	s.mu.Unlock()
+	return nil
+}
+
+// clone copies the limit so cached entries cannot be modified by callers
+func (b BudgetStatus) clone() *BudgetStatus {
+	if b.LimitUSD != nil {
+		limit := *b.LimitUSD
+		b.LimitUSD = &limit
+	}
+	return &b
+}
@@ -0,0 +1,150 @@
+package portal_services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBudgetStore keeps limits and spend in memory and counts reads
+type fakeBudgetStore struct {
+	limits map[int]float64
+	spent  map[int]float64
+	since  time.Time
+	reads  int
+}
+
+func newFakeBudgetStore() *fakeBudgetStore {
+	return &fakeBudgetStore{limits: map[int]float64{}, spent: map[int]float64{}}
+}
+
+func (f *fakeBudgetStore) GetMonthlyLimit(ctx context.Context, userID int) (*float64, error) {
+	f.reads++
+	limit, ok := f.limits[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &limit, nil
+}
+
+func (f *fakeBudgetStore) SetMonthlyLimit(ctx context.Context, userID int, limitUSD float64) error {
+	f.limits[userID] = limitUSD
+	return nil
+}
+
+func (f *fakeBudgetStore) ClearMonthlyLimit(ctx context.Context, userID int) error {
+	delete(f.limits, userID)
+	return nil
+}
+
+func (f *fakeBudgetStore) SummarizeByUser(ctx context.Context, userID int, from, to time.Time) ([]*portal_repositories.LLMUsageSummary, error) {
+	return nil, nil
+}
+
+func (f *fakeBudgetStore) SpendSince(ctx context.Context, userID int, since time.Time) (float64, error) {
+	f.since = since
+	return f.spent[userID], nil
+}
+
+func newTestBudgetService() (*BudgetService, *fakeBudgetStore) {
+	store := newFakeBudgetStore()
+	service := NewBudgetService(store, store)
+	service.SetCacheTTL(0)
+	return service, store
+}
+
+func TestBudgetService_TripsOnlyOverLimit(t *testing.T) {
+	service, store := newTestBudgetService()
+	ctx := context.Background()
+	limit := 10.0
+	require.NoError(t, service.SetMonthlyLimit(ctx, 1, &limit))
+
+	cases := []struct {
+		spent    float64
+		exceeded bool
+	}{
+		{spent: 0, exceeded: false},
+		{spent: 9.99, exceeded: false},
+		{spent: 10, exceeded: false}, // Reaching the limit exactly is still allowed
+		{spent: 10.01, exceeded: true},
+	}
+	for _, tc := range cases {
+		store.spent[1] = tc.spent
+		status, err := service.Status(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, tc.exceeded, status.Exceeded, "spent $%.2f of $10", tc.spent)
+		assert.Equal(t, tc.spent, status.SpentUSD)
+	}
+}
+
+func TestBudgetService_UnsetIsUnlimited(t *testing.T) {
+	service, store := newTestBudgetService()
+	ctx := context.Background()
+	store.spent[1] = 5000
+
+	status, err := service.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, status.LimitUSD)
+	assert.False(t, status.Exceeded)
+
+	limit := 100.0
+	require.NoError(t, service.SetMonthlyLimit(ctx, 1, &limit))
+	status, err = service.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, status.Exceeded)
+
+	require.NoError(t, service.SetMonthlyLimit(ctx, 1, nil))
+	status, err = service.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, status.Exceeded, "removing the limit makes usage unlimited again")
+
+	negative := -1.0
+	assert.ErrorIs(t, service.SetMonthlyLimit(ctx, 1, &negative), ErrInvalidBudget)
+}
+
+func TestBudgetService_CountsSpendFromStartOfMonth(t *testing.T) {
+	service, store := newTestBudgetService()
+	service.now = func() time.Time { return time.Date(2025, 11, 20, 15, 30, 0, 0, time.UTC) }
+
+	_, err := service.Status(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), store.since)
+}
+
+func TestBudgetService_CachesStatus(t *testing.T) {
+	store := newFakeBudgetStore()
+	service := NewBudgetService(store, store)
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	limit := 10.0
+	store.limits[1] = limit
+	store.spent[1] = 5
+	status, err := service.Status(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, status.Exceeded)
+
+	// New spend is not seen until the entry expires
+	store.spent[1] = 50
+	status, err = service.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, status.Exceeded)
+	assert.Equal(t, 1, store.reads)
+
+	now = now.Add(DefaultBudgetCacheTTL)
+	status, err = service.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, status.Exceeded)
+
+	// Changing the limit takes effect immediately
+	higher := 100.0
+	require.NoError(t, service.SetMonthlyLimit(ctx, 1, &higher))
+	status, err = service.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, status.Exceeded)
+}
@@ -11,6 +11,7 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
 
 	"golang.org/x/crypto/argon2"
 )
@@ -25,6 +26,14 @@ const (
 	saltLength    = 16        // 128 bits for salt
 )
 
+// DefaultKeyID identifies the primary key when ENCRYPTION_MASTER_KEY_ID is not set
+const DefaultKeyID = "v1"
+
+// keyIDSeparator ends the key ID prefix of a ciphertext; it is not in the
+// base64 alphabet, so unprefixed ciphertexts from before versioning are
+// recognizable
+const keyIDSeparator = ":"
+
 var (
 	// ErrMasterKeyNotSet is returned when ENCRYPTION_MASTER_KEY environment variable is not configured
 	ErrMasterKeyNotSet = errors.New("ENCRYPTION_MASTER_KEY environment variable not set")
@@ -34,20 +43,52 @@ var (
 
 	// ErrDecryptionFailed is returned when decryption fails (wrong key or corrupted data)
 	ErrDecryptionFailed = errors.New("decryption failed - authentication failed or wrong user key")
+
+	// ErrUnknownKeyID is returned when a ciphertext names a key that is not loaded
+	ErrUnknownKeyID = errors.New("ciphertext encrypted with unknown key ID")
 )
 
 // EncryptionService handles encryption/decryption of sensitive data using AES-256-GCM.
 // Each user's data is encrypted with a unique key derived from the master key and user ID.
+//
+// Ciphertexts are prefixed with the ID of the master key that produced them,
+// so the master key can be rotated: the new key becomes primary and is used
+// for all encryption, while old keys stay loaded to decrypt existing data
+// until RotateKeys has re-encrypted it.
 type EncryptionService struct {
 	masterKey []byte
+	keyID     string
+	oldKeys   map[string][]byte
+	oldKeyIDs []string // Load order, for ciphertexts without a key ID
 }
 
-// NewEncryptionService creates a new encryption service using the ENCRYPTION_MASTER_KEY environment variable.
+// NewEncryptionService creates a new encryption service from the environment:
+//   - ENCRYPTION_MASTER_KEY: the primary master key
+//   - ENCRYPTION_MASTER_KEY_ID: its key ID (default "v1")
+//   - ENCRYPTION_OLD_MASTER_KEYS: retired keys still needed for decryption,
+//     as comma-separated id:key pairs
 func NewEncryptionService() *EncryptionService {
-	masterKey := os.Getenv("ENCRYPTION_MASTER_KEY")
-	return &EncryptionService{
-		masterKey: []byte(masterKey),
+	keyID := os.Getenv("ENCRYPTION_MASTER_KEY_ID")
+	if keyID == "" {
+		keyID = DefaultKeyID
+	}
+	s := &EncryptionService{
+		masterKey: []byte(os.Getenv("ENCRYPTION_MASTER_KEY")),
+		keyID:     keyID,
+		oldKeys:   make(map[string][]byte),
+	}
+
+	for _, pair := range strings.Split(os.Getenv("ENCRYPTION_OLD_MASTER_KEYS"), ",") {
+		id, key, ok := strings.Cut(strings.TrimSpace(pair), keyIDSeparator)
+		if !ok || id == "" || key == "" || id == keyID {
+			continue
+		}
+		if _, seen := s.oldKeys[id]; !seen {
+			s.oldKeyIDs = append(s.oldKeyIDs, id)
+		}
+		s.oldKeys[id] = []byte(key)
 	}
+	return s
 }
 
 // ValidateMasterKey checks if the master key is configured.
@@ -59,6 +100,27 @@ func (s *EncryptionService) ValidateMasterKey() error {
 	return nil
 }
 
+// PrimaryKeyID returns the ID of the key used for encryption
+func (s *EncryptionService) PrimaryKeyID() string {
+	return s.keyID
+}
+
+// KeyID returns the ID of the key a ciphertext was encrypted with, or ""
+// for ciphertexts from before key versioning
+func KeyID(encrypted string) string {
+	id, _, ok := strings.Cut(encrypted, keyIDSeparator)
+	if !ok {
+		return ""
+	}
+	return id
+}
+
+// NeedsRotation reports whether a ciphertext was encrypted with a key other
+// than the primary key
+func (s *EncryptionService) NeedsRotation(encrypted string) bool {
+	return KeyID(encrypted) != s.keyID
+}
+
 // EncryptAPIKey encrypts an API key using AES-256-GCM with user-specific key derivation.
 // The same API key will produce different ciphertext each time due to random nonce generation.
 // Each user's data is encrypted with a unique key, preventing cross-user data access.
@@ -68,27 +130,16 @@ func (s *EncryptionService) ValidateMasterKey() error {
 //   - userID: The user ID (used for key derivation)
 //
 // Returns:
-//   - Base64-encoded ciphertext
+//   - Primary key ID and base64-encoded ciphertext, as "id:ciphertext"
 //   - Error if master key is not set or encryption fails
 func (s *EncryptionService) EncryptAPIKey(apiKey string, userID int) (string, error) {
 	if err := s.ValidateMasterKey(); err != nil {
 		return "", err
 	}
 
-	// Derive user-specific encryption key using Argon2
-	salt := s.generateUserSalt(userID)
-	key := argon2.IDKey(s.masterKey, salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
-
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
-	}
-
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
+	gcm, err := s.userCipher(s.masterKey, userID)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return "", err
 	}
 
 	// Generate random nonce
@@ -101,50 +152,74 @@ func (s *EncryptionService) EncryptAPIKey(apiKey string, userID int) (string, er
 	plaintext := []byte(apiKey)
 	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
 
-	// Encode to base64 for storage
+	// Encode to base64 for storage, tagged with the key used
 	encoded := base64.StdEncoding.EncodeToString(ciphertext)
 
-	return encoded, nil
+	return s.keyID + keyIDSeparator + encoded, nil
 }
 
 // DecryptAPIKey decrypts an API key using the user-specific key.
 // This will only succeed if:
-// 1. The correct master key is set
+// 1. The master key the ciphertext names is loaded (primary or old)
 // 2. The correct user ID is provided (same as when encrypted)
 // 3. The ciphertext has not been corrupted
+// Ciphertexts without a key ID are tried with every loaded key.
 //
 // Parameters:
-//   - encrypted: Base64-encoded ciphertext (output from EncryptAPIKey)
+//   - encrypted: Ciphertext (output from EncryptAPIKey)
 //   - userID: The user ID (must match the ID used during encryption)
 //
 // Returns:
 //   - The plaintext API key
-//   - Error if decryption fails (ErrDecryptionFailed) or invalid input
+//   - Error if decryption fails (ErrDecryptionFailed), the key is not loaded
+//     (ErrUnknownKeyID), or invalid input
 func (s *EncryptionService) DecryptAPIKey(encrypted string, userID int) (string, error) {
 	if err := s.ValidateMasterKey(); err != nil {
 		return "", err
 	}
 
+	keyID := KeyID(encrypted)
+	payload := strings.TrimPrefix(encrypted, keyID+keyIDSeparator)
+
 	// Decode from base64
-	ciphertext, err := base64.StdEncoding.DecodeString(encrypted)
+	ciphertext, err := base64.StdEncoding.DecodeString(payload)
 	if err != nil {
 		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
 	}
 
-	// Derive user-specific encryption key
-	salt := s.generateUserSalt(userID)
-	key := argon2.IDKey(s.masterKey, salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	if keyID != "" {
+		masterKey, ok := s.masterKeyByID(keyID)
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrUnknownKeyID, keyID)
+		}
+		return s.open(masterKey, ciphertext, userID)
+	}
 
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+	// Unversioned: try the primary key, then the old ones
+	plaintext, err := s.open(s.masterKey, ciphertext, userID)
+	for _, id := range s.oldKeyIDs {
+		if !errors.Is(err, ErrDecryptionFailed) {
+			break
+		}
+		plaintext, err = s.open(s.oldKeys[id], ciphertext, userID)
 	}
+	return plaintext, err
+}
 
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
+// masterKeyByID returns the loaded master key with the given ID
+func (s *EncryptionService) masterKeyByID(keyID string) ([]byte, bool) {
+	if keyID == s.keyID {
+		return s.masterKey, true
+	}
+	key, ok := s.oldKeys[keyID]
+	return key, ok
+}
+
+// open decrypts nonce-prefixed ciphertext with the user key derived from masterKey
+func (s *EncryptionService) open(masterKey, ciphertext []byte, userID int) (string, error) {
+	gcm, err := s.userCipher(masterKey, userID)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return "", err
 	}
 
 	// Extract nonce from ciphertext
@@ -164,6 +239,26 @@ func (s *EncryptionService) DecryptAPIKey(encrypted string, userID int) (string,
 	return string(plaintext), nil
 }
 
+// userCipher derives the user-specific key from masterKey using Argon2 and
+// returns an AES-GCM cipher for it
+func (s *EncryptionService) userCipher(masterKey []byte, userID int) (cipher.AEAD, error) {
+	salt := s.generateUserSalt(userID)
+	key := argon2.IDKey(masterKey, salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+
+	// Create AES cipher
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	// Create GCM mode
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
 // generateUserSalt creates a deterministic salt based on user ID.
 // This ensures the same user always gets the same encryption key, while different
 // users have different keys (preventing cross-user data access).
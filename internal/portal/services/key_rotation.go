@@ -0,0 +1,62 @@
+package portal_services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
+)
+
+// APIKeyStore is the storage of encrypted API keys that RotateKeys re-encrypts
+type APIKeyStore interface {
+	FindAllWithAPIKey(ctx context.Context) ([]*portal_repositories.LLMConfig, error)
+	UpdateAPIKey(ctx context.Context, configID, oldEncrypted, newEncrypted string) (bool, error)
+}
+
+// RotateKeys re-encrypts every stored API key that is not yet under the
+// primary key. A key changed by someone else since it was read is left
+// alone; it was encrypted with the primary key anyway. Keys that fail to
+// re-encrypt don't stop the rest and are reported together.
+// Returns the number of keys re-encrypted.
+func (s *EncryptionService) RotateKeys(ctx context.Context, store APIKeyStore) (int, error) {
+	if err := s.ValidateMasterKey(); err != nil {
+		return 0, err
+	}
+
+	configs, err := store.FindAllWithAPIKey(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list encrypted API keys: %w", err)
+	}
+
+	rotated := 0
+	var errs []error
+	for _, config := range configs {
+		old := config.APIKeyEncrypted.String
+		if !s.NeedsRotation(old) {
+			continue
+		}
+
+		apiKey, err := s.DecryptAPIKey(old, config.UserID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("config %s: %w", config.ID, err))
+			continue
+		}
+		encrypted, err := s.EncryptAPIKey(apiKey, config.UserID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("config %s: %w", config.ID, err))
+			continue
+		}
+
+		updated, err := store.UpdateAPIKey(ctx, config.ID, old, encrypted)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("config %s: %w", config.ID, err))
+			continue
+		}
+		if updated {
+			rotated++
+		}
+	}
+
+	return rotated, errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package portal_services
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAPIKeyStore holds configs in memory for rotation tests
+type memoryAPIKeyStore struct {
+	configs []*portal_repositories.LLMConfig
+}
+
+func (m *memoryAPIKeyStore) FindAllWithAPIKey(ctx context.Context) ([]*portal_repositories.LLMConfig, error) {
+	var withKey []*portal_repositories.LLMConfig
+	for _, config := range m.configs {
+		if config.APIKeyEncrypted.Valid {
+			copied := *config
+			withKey = append(withKey, &copied)
+		}
+	}
+	return withKey, nil
+}
+
+func (m *memoryAPIKeyStore) UpdateAPIKey(ctx context.Context, configID, oldEncrypted, newEncrypted string) (bool, error) {
+	for _, config := range m.configs {
+		if config.ID == configID && config.APIKeyEncrypted.String == oldEncrypted {
+			config.APIKeyEncrypted.String = newEncrypted
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestEncryptionService_RotateKeys(t *testing.T) {
+	// Encrypt under the original key
+	t.Setenv("ENCRYPTION_MASTER_KEY", "old-master-key-32-bytes-long!!!")
+	t.Setenv("ENCRYPTION_MASTER_KEY_ID", "")
+	t.Setenv("ENCRYPTION_OLD_MASTER_KEYS", "")
+	oldService := NewEncryptionService()
+	versioned, err := oldService.EncryptAPIKey("sk-versioned", 1)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultKeyID, KeyID(versioned))
+	legacy, err := oldService.EncryptAPIKey("sk-legacy", 2)
+	require.NoError(t, err)
+	legacy = strings.TrimPrefix(legacy, DefaultKeyID+":") // As stored before key IDs
+
+	store := &memoryAPIKeyStore{configs: []*portal_repositories.LLMConfig{
+		{ID: "versioned", UserID: 1, APIKeyEncrypted: sql.NullString{String: versioned, Valid: true}},
+		{ID: "legacy", UserID: 2, APIKeyEncrypted: sql.NullString{String: legacy, Valid: true}},
+		{ID: "ollama", UserID: 3},
+	}}
+
+	// Promote a new key, keeping the old one for decryption
+	t.Setenv("ENCRYPTION_MASTER_KEY", "new-master-key-32-bytes-long!!!")
+	t.Setenv("ENCRYPTION_MASTER_KEY_ID", "v2")
+	t.Setenv("ENCRYPTION_OLD_MASTER_KEYS", "v1:old-master-key-32-bytes-long!!!")
+	service := NewEncryptionService()
+
+	decrypted, err := service.DecryptAPIKey(versioned, 1)
+	require.NoError(t, err, "old ciphertexts still decrypt before rotation")
+	assert.Equal(t, "sk-versioned", decrypted)
+	decrypted, err = service.DecryptAPIKey(legacy, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-legacy", decrypted)
+
+	rotated, err := service.RotateKeys(context.Background(), store)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rotated)
+
+	// Once rotated, the old key is no longer needed
+	t.Setenv("ENCRYPTION_OLD_MASTER_KEYS", "")
+	newOnly := NewEncryptionService()
+	for _, config := range store.configs[:2] {
+		assert.Equal(t, "v2", KeyID(config.APIKeyEncrypted.String), "config %s uses the new key", config.ID)
+	}
+	decrypted, err = newOnly.DecryptAPIKey(store.configs[0].APIKeyEncrypted.String, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-versioned", decrypted)
+	decrypted, err = newOnly.DecryptAPIKey(store.configs[1].APIKeyEncrypted.String, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-legacy", decrypted)
+	assert.False(t, store.configs[2].APIKeyEncrypted.Valid, "configs without a key are untouched")
+
+	rotated, err = service.RotateKeys(context.Background(), store)
+	require.NoError(t, err)
+	assert.Zero(t, rotated, "rotation is idempotent")
+}
+
+func TestEncryptionService_RotateKeysReportsUndecryptable(t *testing.T) {
+	t.Setenv("ENCRYPTION_MASTER_KEY", "new-master-key-32-bytes-long!!!")
+	t.Setenv("ENCRYPTION_MASTER_KEY_ID", "v2")
+	t.Setenv("ENCRYPTION_OLD_MASTER_KEYS", "")
+	service := NewEncryptionService()
+	good, err := service.EncryptAPIKey("sk-good", 1)
+	require.NoError(t, err)
+
+	store := &memoryAPIKeyStore{configs: []*portal_repositories.LLMConfig{
+		{ID: "lost-key", UserID: 1, APIKeyEncrypted: sql.NullString{String: "v0:AAAA", Valid: true}},
+		{ID: "current", UserID: 1, APIKeyEncrypted: sql.NullString{String: good, Valid: true}},
+	}}
+
+	rotated, err := service.RotateKeys(context.Background(), store)
+	assert.Zero(t, rotated)
+	require.ErrorIs(t, err, ErrUnknownKeyID)
+	assert.Contains(t, err.Error(), "lost-key")
+	assert.Equal(t, good, store.configs[1].APIKeyEncrypted.String)
+}
@@ -3,6 +3,7 @@ package portal_services
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -22,12 +23,17 @@ const (
 	errFailedToSetDefault   = "failed to set default config"
 	errFailedToSetPref      = "failed to set app preference"
 	errFailedToListConfigs  = "failed to list configs"
+	errFailedToGetDefault   = "failed to get default config"
 )
 
+// ErrNoDefaultConfig is returned by GetDefault when the user has not chosen a default
+var ErrNoDefaultConfig = errors.New("no default LLM config set")
+
 // LLMConfigService provides business logic for managing LLM configurations
 type LLMConfigService struct {
-	repo       portal_repositories.LLMConfigRepository
-	encryption EncryptionServiceInterface
+	repo        portal_repositories.LLMConfigRepository
+	encryption  EncryptionServiceInterface
+	resolutions *resolutionCache
 }
 
 // NewLLMConfigService creates a new LLM configuration service
@@ -36,8 +42,9 @@ func NewLLMConfigService(
 	encryption EncryptionServiceInterface,
 ) *LLMConfigService {
 	return &LLMConfigService{
-		repo:       repo,
-		encryption: encryption,
+		repo:        repo,
+		encryption:  encryption,
+		resolutions: newResolutionCache(DefaultResolutionCacheTTL),
 	}
 }
 
@@ -83,9 +90,9 @@ func (s *LLMConfigService) CreateConfig(
 		UserID:      userID,
 		Provider:    provider,
 		ModelName:   model,
-		IsDefault:   isDefault,
-		MaxTokens:   4096, // Default max tokens
-		Temperature: 0.7,  // Default temperature
+		IsDefault:   isDefault, // The repository clears other defaults in the same transaction
+		MaxTokens:   4096,      // Default max tokens
+		Temperature: 0.7,       // Default temperature
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -112,6 +119,7 @@ func (s *LLMConfigService) CreateConfig(
 		return nil, fmt.Errorf("%s: %w", errFailedToSaveConfig, err)
 	}
 
+	s.resolutions.invalidateUser(userID)
 	return config, nil
 }
 
@@ -151,6 +159,7 @@ func (s *LLMConfigService) UpdateConfig(
 		return fmt.Errorf("%s: %w", errFailedToUpdateConfig, err)
 	}
 
+	s.resolutions.invalidateUser(userID)
 	return nil
 }
 
@@ -171,17 +180,18 @@ func (s *LLMConfigService) DeleteConfig(
 		return fmt.Errorf("%s: %w", errFailedToDeleteConfig, err)
 	}
 
+	s.resolutions.invalidateUser(userID)
 	return nil
 }
 
-// SetDefaultConfig marks a configuration as the user's default
-// Validates that the config belongs to the requesting user
-func (s *LLMConfigService) SetDefaultConfig(
+// SetDefault marks a configuration as the user's only default
+// Validates that the config belongs to the requesting user, then clears
+// the user's other defaults and sets this one in a single transaction
+func (s *LLMConfigService) SetDefault(
 	ctx context.Context,
 	userID int,
 	configID string,
 ) error {
-	// Validate ownership before setting default
 	// Validate ownership before setting default
 	if _, err := s.validateConfigOwnership(ctx, configID, userID); err != nil {
 		return err
@@ -192,9 +202,27 @@ func (s *LLMConfigService) SetDefaultConfig(
 		return fmt.Errorf("%s: %w", errFailedToSetDefault, err)
 	}
 
+	s.resolutions.invalidateUser(userID)
 	return nil
 }
 
+// GetDefault returns the user's default configuration
+// Returns ErrNoDefaultConfig when none is set; unlike GetEffectiveConfig
+// it never falls back to the system default
+func (s *LLMConfigService) GetDefault(
+	ctx context.Context,
+	userID int,
+) (*portal_repositories.LLMConfig, error) {
+	config, err := s.repo.FindDefaultByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errFailedToGetDefault, err)
+	}
+	if config == nil {
+		return nil, fmt.Errorf("user %d: %w", userID, ErrNoDefaultConfig)
+	}
+	return config, nil
+}
+
 // GetEffectiveConfig returns the effective LLM configuration for a user and app
 // Priority order:
 // 1. App-specific preference (if set)
@@ -221,20 +249,7 @@ func (s *LLMConfigService) GetEffectiveConfig(
 	}
 
 	// Priority 3: Return system default (Ollama with deepseek-coder:6.7b)
-	systemDefault := &portal_repositories.LLMConfig{
-		ID:              "system-default",
-		UserID:          userID,
-		Provider:        "ollama",
-		ModelName:       "deepseek-coder:6.7b",
-		APIEndpoint:     sql.NullString{String: "http://localhost:11434", Valid: true},
-		APIKeyEncrypted: sql.NullString{Valid: false}, // NULL for Ollama
-		IsDefault:       false,
-		MaxTokens:       8192,
-		Temperature:     0.7,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
-	}
-	return systemDefault, nil
+	return systemDefaultConfig(userID), nil
 }
 
 // SetAppPreference sets the preferred LLM configuration for a specific app
@@ -255,6 +270,7 @@ func (s *LLMConfigService) SetAppPreference(
 		return fmt.Errorf("failed to set app preference: %w", err)
 	}
 
+	s.resolutions.invalidateUser(userID)
 	return nil
 }
 
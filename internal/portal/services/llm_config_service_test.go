@@ -88,6 +88,19 @@ func (m *MockLLMConfigRepository) GetAllAppPreferences(ctx context.Context, user
 	return args.Get(0).([]*portal_repositories.AppLLMPreference), args.Error(1)
 }
 
+func (m *MockLLMConfigRepository) FindAllWithAPIKey(ctx context.Context) ([]*portal_repositories.LLMConfig, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*portal_repositories.LLMConfig), args.Error(1)
+}
+
+func (m *MockLLMConfigRepository) UpdateAPIKey(ctx context.Context, configID, oldEncrypted, newEncrypted string) (bool, error) {
+	args := m.Called(ctx, configID, oldEncrypted, newEncrypted)
+	return args.Bool(0), args.Error(1)
+}
+
 // MockEncryptionServiceForService mocks the encryption service for service layer tests
 type MockEncryptionServiceForService struct {
 	mock.Mock
@@ -294,8 +307,8 @@ func TestDeleteConfig_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-// TestSetDefaultConfig_Success verifies setting default config
-func TestSetDefaultConfig_Success(t *testing.T) {
+// TestSetDefault_Success verifies setting default config
+func TestSetDefault_Success(t *testing.T) {
 	mockRepo := new(MockLLMConfigRepository)
 	mockEncryption := new(MockEncryptionServiceForService)
 	service := NewLLMConfigService(mockRepo, mockEncryption)
@@ -312,12 +325,62 @@ func TestSetDefaultConfig_Success(t *testing.T) {
 	mockRepo.On("FindByID", ctx, configID).Return(existingConfig, nil)
 	mockRepo.On("SetDefault", ctx, userID, configID).Return(nil)
 
-	err := service.SetDefaultConfig(ctx, userID, configID)
+	err := service.SetDefault(ctx, userID, configID)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
+// TestCreateConfig_DefaultClearsOthers verifies a new default is inserted as
+// the default in one repository call rather than a separate SetDefault
+func TestCreateConfig_DefaultClearsOthers(t *testing.T) {
+	mockRepo := new(MockLLMConfigRepository)
+	mockEncryption := new(MockEncryptionServiceForService)
+	service := NewLLMConfigService(mockRepo, mockEncryption)
+
+	ctx := context.Background()
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(cfg *portal_repositories.LLMConfig) bool {
+		return cfg.IsDefault
+	})).Return(nil)
+
+	config, err := service.CreateConfig(ctx, 123, "ollama", "llama3", "", true, "")
+
+	assert.NoError(t, err)
+	assert.True(t, config.IsDefault)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "SetDefault", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestSetDefault_OtherUsersConfig verifies ownership is checked before any default changes
+func TestSetDefault_OtherUsersConfig(t *testing.T) {
+	mockRepo := new(MockLLMConfigRepository)
+	mockEncryption := new(MockEncryptionServiceForService)
+	service := NewLLMConfigService(mockRepo, mockEncryption)
+
+	ctx := context.Background()
+	mockRepo.On("FindByID", ctx, "other-config").Return(&portal_repositories.LLMConfig{ID: "other-config", UserID: 456}, nil)
+
+	err := service.SetDefault(ctx, 123, "other-config")
+
+	assert.ErrorContains(t, err, "permission denied")
+	mockRepo.AssertNotCalled(t, "SetDefault", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestGetDefault_NoDefault verifies a missing default is a distinct error
+func TestGetDefault_NoDefault(t *testing.T) {
+	mockRepo := new(MockLLMConfigRepository)
+	mockEncryption := new(MockEncryptionServiceForService)
+	service := NewLLMConfigService(mockRepo, mockEncryption)
+
+	ctx := context.Background()
+	mockRepo.On("FindDefaultByUser", ctx, 123).Return(nil, nil)
+
+	config, err := service.GetDefault(ctx, 123)
+
+	assert.Nil(t, config)
+	assert.ErrorIs(t, err, ErrNoDefaultConfig)
+}
+
 // TestGetEffectiveConfig_AppPreference verifies app-specific preference takes priority
 func TestGetEffectiveConfig_AppPreference(t *testing.T) {
 	mockRepo := new(MockLLMConfigRepository)
@@ -0,0 +1,194 @@
+package portal_services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
+)
+
+// DefaultResolutionCacheTTL is how long a resolved app model is reused
+// before the fallback chain is walked again
+const DefaultResolutionCacheTTL = 30 * time.Second
+
+// Where a resolved model came from, most specific first
+const (
+	ResolutionSourceAppPreference = "app_preference"
+	ResolutionSourceUserDefault   = "user_default"
+	ResolutionSourceSystemDefault = "system_default"
+)
+
+// ResolvedModel is the LLM configuration an app should use for a user
+type ResolvedModel struct {
+	Config *portal_repositories.LLMConfig
+	APIKey string // Decrypted; empty for Ollama and the system default
+	Source string
+}
+
+// resolutionCache holds recent resolutions keyed by user and app.
+// Changing any of a user's configs or preferences drops their entries.
+type resolutionCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[resolutionKey]resolutionEntry
+}
+
+type resolutionKey struct {
+	userID  int
+	appName string
+}
+
+type resolutionEntry struct {
+	model    ResolvedModel
+	storedAt time.Time
+}
+
+func newResolutionCache(ttl time.Duration) *resolutionCache {
+	return &resolutionCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[resolutionKey]resolutionEntry),
+	}
+}
+
+func (c *resolutionCache) get(key resolutionKey) (*ResolvedModel, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || c.now().Sub(entry.storedAt) >= c.ttl {
+		return nil, false
+	}
+	return entry.model.clone(), true
+}
+
+func (c *resolutionCache) put(key resolutionKey, model *ResolvedModel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+	c.entries[key] = resolutionEntry{model: *model.clone(), storedAt: c.now()}
+}
+
+func (c *resolutionCache) invalidateUser(userID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.userID == userID {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *resolutionCache) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+	c.entries = make(map[resolutionKey]resolutionEntry)
+}
+
+// clone copies the config so cached entries cannot be modified by callers
+func (m ResolvedModel) clone() *ResolvedModel {
+	config := *m.Config
+	m.Config = &config
+	return &m
+}
+
+// SetResolutionCacheTTL changes how long ResolveModelForApp results are
+// cached. Zero or less disables caching.
+func (s *LLMConfigService) SetResolutionCacheTTL(ttl time.Duration) {
+	s.resolutions.setTTL(ttl)
+}
+
+// ResolveModelForApp returns the configuration appName should use for a user
+// with its API key decrypted, walking the fallback chain:
+// 1. App-specific preference (if set and still owned by the user)
+// 2. User's default configuration (if set)
+// 3. System default (Ollama with deepseek-coder:6.7b)
+// Repository and decryption failures are returned rather than falling back,
+// so a broken config is never silently replaced by another model.
+func (s *LLMConfigService) ResolveModelForApp(
+	ctx context.Context,
+	userID int,
+	appName string,
+) (*ResolvedModel, error) {
+	key := resolutionKey{userID: userID, appName: appName}
+	if cached, ok := s.resolutions.get(key); ok {
+		return cached, nil
+	}
+
+	config, source, err := s.resolveConfig(ctx, userID, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &ResolvedModel{Config: config, Source: source}
+	if config.APIKeyEncrypted.Valid && config.APIKeyEncrypted.String != "" {
+		apiKey, err := s.encryption.DecryptAPIKey(config.APIKeyEncrypted.String, config.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt API key for config %s: %w", config.ID, err)
+		}
+		resolved.APIKey = apiKey
+	}
+
+	s.resolutions.put(key, resolved)
+	return resolved, nil
+}
+
+// resolveConfig walks the fallback chain without decrypting anything
+func (s *LLMConfigService) resolveConfig(
+	ctx context.Context,
+	userID int,
+	appName string,
+) (*portal_repositories.LLMConfig, string, error) {
+	// Priority 1: App-specific preference
+	appPref, err := s.repo.GetAppPreference(ctx, userID, appName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get %s preference: %w", appName, err)
+	}
+	// A deleted config leaves the preference with no config ID
+	if appPref != nil && appPref.LLMConfigID != "" {
+		config, err := s.repo.FindByID(ctx, appPref.LLMConfigID)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", errFailedToFindConfig, err)
+		}
+		if config != nil && config.UserID == userID {
+			return config, ResolutionSourceAppPreference, nil
+		}
+	}
+
+	// Priority 2: User's default configuration
+	defaultConfig, err := s.repo.FindDefaultByUser(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", errFailedToGetDefault, err)
+	}
+	if defaultConfig != nil {
+		return defaultConfig, ResolutionSourceUserDefault, nil
+	}
+
+	// Priority 3: System default
+	return systemDefaultConfig(userID), ResolutionSourceSystemDefault, nil
+}
+
+// systemDefaultConfig is used when a user has configured nothing
+func systemDefaultConfig(userID int) *portal_repositories.LLMConfig {
+	now := time.Now()
+	return &portal_repositories.LLMConfig{
+		ID:              "system-default",
+		UserID:          userID,
+		Provider:        "ollama",
+		ModelName:       "deepseek-coder:6.7b",
+		APIEndpoint:     sql.NullString{String: "http://localhost:11434", Valid: true},
+		APIKeyEncrypted: sql.NullString{Valid: false}, // NULL for Ollama
+		IsDefault:       false,
+		MaxTokens:       8192,
+		Temperature:     0.7,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
@@ -0,0 +1,157 @@
+package portal_services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	portal_repositories "github.com/mikejsmith1985/devsmith-modular-platform/internal/portal/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newResolutionTestService() (*LLMConfigService, *MockLLMConfigRepository, *MockEncryptionServiceForService) {
+	mockRepo := new(MockLLMConfigRepository)
+	mockEncryption := new(MockEncryptionServiceForService)
+	return NewLLMConfigService(mockRepo, mockEncryption), mockRepo, mockEncryption
+}
+
+var resolutionAnthropicConfig = &portal_repositories.LLMConfig{
+	ID:              "anthropic-config",
+	UserID:          123,
+	Provider:        "anthropic",
+	ModelName:       "claude-3-5-sonnet",
+	APIKeyEncrypted: sql.NullString{String: "v1:encrypted", Valid: true},
+}
+
+func TestResolveModelForApp_AppPreference(t *testing.T) {
+	service, mockRepo, mockEncryption := newResolutionTestService()
+	ctx := context.Background()
+
+	mockRepo.On("GetAppPreference", ctx, 123, "review").Return(&portal_repositories.AppLLMPreference{LLMConfigID: "anthropic-config"}, nil)
+	mockRepo.On("FindByID", ctx, "anthropic-config").Return(resolutionAnthropicConfig, nil)
+	mockEncryption.On("DecryptAPIKey", "v1:encrypted", 123).Return("sk-ant-123", nil)
+
+	resolved, err := service.ResolveModelForApp(ctx, 123, "review")
+
+	require.NoError(t, err)
+	assert.Equal(t, ResolutionSourceAppPreference, resolved.Source)
+	assert.Equal(t, "anthropic-config", resolved.Config.ID)
+	assert.Equal(t, "sk-ant-123", resolved.APIKey)
+	mockRepo.AssertNotCalled(t, "FindDefaultByUser", mock.Anything, mock.Anything)
+}
+
+func TestResolveModelForApp_UserDefault(t *testing.T) {
+	service, mockRepo, mockEncryption := newResolutionTestService()
+	ctx := context.Background()
+
+	// A preference whose config was deleted falls through to the default
+	mockRepo.On("GetAppPreference", ctx, 123, "review").Return(&portal_repositories.AppLLMPreference{LLMConfigID: ""}, nil)
+	mockRepo.On("FindDefaultByUser", ctx, 123).Return(resolutionAnthropicConfig, nil)
+	mockEncryption.On("DecryptAPIKey", "v1:encrypted", 123).Return("sk-ant-123", nil)
+
+	resolved, err := service.ResolveModelForApp(ctx, 123, "review")
+
+	require.NoError(t, err)
+	assert.Equal(t, ResolutionSourceUserDefault, resolved.Source)
+	assert.Equal(t, "sk-ant-123", resolved.APIKey)
+}
+
+func TestResolveModelForApp_SkipsOtherUsersConfig(t *testing.T) {
+	service, mockRepo, mockEncryption := newResolutionTestService()
+	ctx := context.Background()
+
+	mockRepo.On("GetAppPreference", ctx, 456, "review").Return(&portal_repositories.AppLLMPreference{LLMConfigID: "anthropic-config"}, nil)
+	mockRepo.On("FindByID", ctx, "anthropic-config").Return(resolutionAnthropicConfig, nil)
+	mockRepo.On("FindDefaultByUser", ctx, 456).Return(nil, nil)
+
+	resolved, err := service.ResolveModelForApp(ctx, 456, "review")
+
+	require.NoError(t, err)
+	assert.Equal(t, ResolutionSourceSystemDefault, resolved.Source)
+	mockEncryption.AssertNotCalled(t, "DecryptAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestResolveModelForApp_NoConfigAtAll(t *testing.T) {
+	service, mockRepo, mockEncryption := newResolutionTestService()
+	ctx := context.Background()
+
+	mockRepo.On("GetAppPreference", ctx, 123, "logs").Return(nil, nil)
+	mockRepo.On("FindDefaultByUser", ctx, 123).Return(nil, nil)
+
+	resolved, err := service.ResolveModelForApp(ctx, 123, "logs")
+
+	require.NoError(t, err)
+	assert.Equal(t, ResolutionSourceSystemDefault, resolved.Source)
+	assert.Equal(t, "ollama", resolved.Config.Provider)
+	assert.Equal(t, "deepseek-coder:6.7b", resolved.Config.ModelName)
+	assert.Empty(t, resolved.APIKey)
+	mockEncryption.AssertNotCalled(t, "DecryptAPIKey", mock.Anything, mock.Anything)
+}
+
+func TestResolveModelForApp_ReturnsErrorsInsteadOfFallingBack(t *testing.T) {
+	service, mockRepo, mockEncryption := newResolutionTestService()
+	ctx := context.Background()
+
+	mockRepo.On("GetAppPreference", ctx, 123, "review").Return(nil, nil)
+	mockRepo.On("FindDefaultByUser", ctx, 123).Return(resolutionAnthropicConfig, nil)
+	mockEncryption.On("DecryptAPIKey", "v1:encrypted", 123).Return("", ErrDecryptionFailed)
+
+	resolved, err := service.ResolveModelForApp(ctx, 123, "review")
+	assert.Nil(t, resolved)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	service, mockRepo, _ = newResolutionTestService()
+	mockRepo.On("GetAppPreference", ctx, 123, "review").Return(nil, fmt.Errorf("connection refused"))
+
+	_, err = service.ResolveModelForApp(ctx, 123, "review")
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestResolveModelForApp_CachesBriefly(t *testing.T) {
+	service, mockRepo, _ := newResolutionTestService()
+	ctx := context.Background()
+	now := time.Now()
+	service.resolutions.now = func() time.Time { return now }
+
+	mockRepo.On("GetAppPreference", ctx, 123, "review").Return(nil, nil).Twice()
+	mockRepo.On("FindDefaultByUser", ctx, 123).Return(nil, nil).Twice()
+
+	first, err := service.ResolveModelForApp(ctx, 123, "review")
+	require.NoError(t, err)
+	first.Config.ModelName = "changed-by-caller"
+
+	cached, err := service.ResolveModelForApp(ctx, 123, "review")
+	require.NoError(t, err)
+	assert.Equal(t, "deepseek-coder:6.7b", cached.Config.ModelName, "cached entries are copies")
+	mockRepo.AssertNumberOfCalls(t, "GetAppPreference", 1)
+
+	now = now.Add(DefaultResolutionCacheTTL)
+	_, err = service.ResolveModelForApp(ctx, 123, "review")
+	require.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "GetAppPreference", 2)
+}
+
+func TestResolveModelForApp_PreferenceChangeInvalidatesCache(t *testing.T) {
+	service, mockRepo, mockEncryption := newResolutionTestService()
+	ctx := context.Background()
+
+	mockRepo.On("GetAppPreference", ctx, 123, "review").Return(nil, nil).Once()
+	mockRepo.On("FindDefaultByUser", ctx, 123).Return(nil, nil).Once()
+	resolved, err := service.ResolveModelForApp(ctx, 123, "review")
+	require.NoError(t, err)
+	require.Equal(t, ResolutionSourceSystemDefault, resolved.Source)
+
+	mockRepo.On("FindByID", ctx, "anthropic-config").Return(resolutionAnthropicConfig, nil)
+	mockRepo.On("SetAppPreference", ctx, 123, "review", "anthropic-config").Return(nil)
+	require.NoError(t, service.SetAppPreference(ctx, 123, "review", "anthropic-config"))
+
+	mockRepo.On("GetAppPreference", ctx, 123, "review").Return(&portal_repositories.AppLLMPreference{LLMConfigID: "anthropic-config"}, nil).Once()
+	mockEncryption.On("DecryptAPIKey", "v1:encrypted", 123).Return("sk-ant-123", nil)
+	resolved, err = service.ResolveModelForApp(ctx, 123, "review")
+	require.NoError(t, err)
+	assert.Equal(t, ResolutionSourceAppPreference, resolved.Source)
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"golang.org/x/sync/singleflight"
+
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+)
+
+// Deduplicator makes concurrent identical analyses from one session share
+// one in-flight AI call. It covers the case the cache can't: a resubmit or a
+// second tab while the first analysis is still running costs one call, and
+// each caller receives its own copy of the result. Calls are never shared
+// across sessions, since the AI client checks the budget, resolves the
+// config and bills under the caller's session. Streaming requests are never
+// shared either, since only one caller could receive the chunks.
+type Deduplicator struct {
+	group singleflight.Group
+}
+
+// NewDeduplicator creates a Deduplicator
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{}
+}
+
+// inflightKey identifies concurrent identical analyses: a hash of mode, the
+// session token, model override and language carried in ctx, and parts.
+func inflightKey(ctx context.Context, mode string, parts ...string) string {
+	session, _ := ctx.Value(reviewcontext.SessionTokenKey).(string)
+	model, _ := ctx.Value(reviewcontext.ModelContextKey).(string)
+	language, _ := ctx.Value(reviewcontext.LanguageContextKey).(string)
+	return hashKey("", append([]string{mode, session, model, language}, parts...)...)
+}
+
+// shared runs analyze once for all concurrent callers with the same key.
+// The call runs under the first caller's ctx; a caller whose ctx is still
+// live when that one is cancelled runs the analysis itself.
+func shared[T any](ctx context.Context, d *Deduplicator, key string, analyze func(context.Context) (*T, error)) (*T, error) {
+	if reviewcontext.ChunkFuncFrom(ctx) != nil {
+		return analyze(ctx)
+	}
+
+	ch := d.group.DoChan(key, func() (interface{}, error) {
+		return analyze(ctx)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			if res.Shared && ctx.Err() == nil && (errors.Is(res.Err, context.Canceled) || errors.Is(res.Err, context.DeadlineExceeded)) {
+				return analyze(ctx)
+			}
+			return nil, res.Err
+		}
+		result := res.Val.(*T)
+		if !res.Shared {
+			return result, nil
+		}
+		// Handlers adjust results (e.g. normalizing grades), so sharers
+		// each get their own copy
+		return clone(result)
+	}
+}
+
+func clone[T any](v *T) (*T, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	out := new(T)
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Preview wraps next so concurrent identical Preview analyses share one call
+func (d *Deduplicator) Preview(next review_services.PreviewAnalyzer) review_services.PreviewAnalyzer {
+	return &dedupPreview{next: next, dedup: d}
+}
+
+// Skim wraps next so concurrent identical Skim analyses share one call
+func (d *Deduplicator) Skim(next review_services.SkimAnalyzer) review_services.SkimAnalyzer {
+	return &dedupSkim{next: next, dedup: d}
+}
+
+// Scan wraps next so concurrent identical Scan analyses share one call
+func (d *Deduplicator) Scan(next review_services.ScanAnalyzer) review_services.ScanAnalyzer {
+	return &dedupScan{next: next, dedup: d}
+}
+
+// Detailed wraps next so concurrent identical Detailed analyses share one call
+func (d *Deduplicator) Detailed(next review_services.DetailedAnalyzer) review_services.DetailedAnalyzer {
+	return &dedupDetailed{next: next, dedup: d}
+}
+
+// Critical wraps next so concurrent identical Critical analyses, of whole
+// files or of diffs, share one call
+func (d *Deduplicator) Critical(next review_services.CriticalAnalyzer) review_services.CriticalAnalyzer {
+	return &dedupCritical{next: next, dedup: d}
+}
+
+type dedupPreview struct {
+	next  review_services.PreviewAnalyzer
+	dedup *Deduplicator
+}
+
+func (p *dedupPreview) AnalyzePreview(ctx context.Context, code, userMode, outputMode string) (*review_models.PreviewModeOutput, error) {
+	key := inflightKey(ctx, review_models.PreviewMode, code, userMode, outputMode)
+	return shared(ctx, p.dedup, key, func(ctx context.Context) (*review_models.PreviewModeOutput, error) {
+		return p.next.AnalyzePreview(ctx, code, userMode, outputMode)
+	})
+}
+
+type dedupSkim struct {
+	next  review_services.SkimAnalyzer
+	dedup *Deduplicator
+}
+
+func (s *dedupSkim) AnalyzeSkim(ctx context.Context, code, userMode, outputMode string) (*review_models.SkimModeOutput, error) {
+	key := inflightKey(ctx, review_models.SkimMode, code, userMode, outputMode)
+	return shared(ctx, s.dedup, key, func(ctx context.Context) (*review_models.SkimModeOutput, error) {
+		return s.next.AnalyzeSkim(ctx, code, userMode, outputMode)
+	})
+}
+
+type dedupScan struct {
+	next  review_services.ScanAnalyzer
+	dedup *Deduplicator
+}
+
+func (s *dedupScan) AnalyzeScan(ctx context.Context, query, code, userMode, outputMode string) (*review_models.ScanModeOutput, error) {
+	key := inflightKey(ctx, review_models.ScanMode, code, userMode, outputMode, query)
+	return shared(ctx, s.dedup, key, func(ctx context.Context) (*review_models.ScanModeOutput, error) {
+		return s.next.AnalyzeScan(ctx, query, code, userMode, outputMode)
+	})
+}
+
+type dedupDetailed struct {
+	next  review_services.DetailedAnalyzer
+	dedup *Deduplicator
+}
+
+func (d *dedupDetailed) AnalyzeDetailed(ctx context.Context, code, target, userMode, outputMode string) (*review_models.DetailedModeOutput, error) {
+	key := inflightKey(ctx, review_models.DetailedMode, code, userMode, outputMode, target)
+	return shared(ctx, d.dedup, key, func(ctx context.Context) (*review_models.DetailedModeOutput, error) {
+		return d.next.AnalyzeDetailed(ctx, code, target, userMode, outputMode)
+	})
+}
+
+type dedupCritical struct {
+	next  review_services.CriticalAnalyzer
+	dedup *Deduplicator
+}
+
+func (cr *dedupCritical) AnalyzeCritical(ctx context.Context, code string) (*review_models.CriticalModeOutput, error) {
+	key := inflightKey(ctx, review_models.CriticalMode, code)
+	return shared(ctx, cr.dedup, key, func(ctx context.Context) (*review_models.CriticalModeOutput, error) {
+		return cr.next.AnalyzeCritical(ctx, code)
+	})
+}
+
+func (cr *dedupCritical) AnalyzeCriticalDiff(ctx context.Context, diff string) (*review_models.CriticalModeOutput, error) {
+	key := inflightKey(ctx, review_models.CriticalMode+":diff", diff)
+	return shared(ctx, cr.dedup, key, func(ctx context.Context) (*review_models.CriticalModeOutput, error) {
+		return cr.next.AnalyzeCriticalDiff(ctx, diff)
+	})
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/testutils"
+)
+
+// blockingAIClient counts Generate calls and holds each one until release is
+// closed
+type blockingAIClient struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (c *blockingAIClient) Generate(ctx context.Context, prompt string) (string, error) {
+	c.calls.Add(1)
+	select {
+	case <-c.release:
+		return `{"summary": "a small program", "bounded_contexts": [], "tech_stack": []}`, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// sessionPreview holds each call until release is closed, then fails for
+// sessions in errs and succeeds for the rest
+type sessionPreview struct {
+	calls   atomic.Int32
+	release chan struct{}
+	errs    map[string]error
+}
+
+func (p *sessionPreview) AnalyzePreview(ctx context.Context, code, _, _ string) (*review_models.PreviewModeOutput, error) {
+	p.calls.Add(1)
+	<-p.release
+	if err := p.errs[ctx.Value(reviewcontext.SessionTokenKey).(string)]; err != nil {
+		return nil, err
+	}
+	return &review_models.PreviewModeOutput{Summary: "summary of " + code}, nil
+}
+
+func TestDeduplicator_ConcurrentIdenticalRequestsShareOneAICall(t *testing.T) {
+	ai := &blockingAIClient{release: make(chan struct{})}
+	preview := NewDeduplicator().Preview(review_services.NewPreviewService(ai, &testutils.MockLogger{}))
+	ctx := context.WithValue(sessionCtx("alice"), reviewcontext.ModelContextKey, "mistral:7b")
+
+	const n = 10
+	results := make([]*review_models.PreviewModeOutput, n)
+	var started, done sync.WaitGroup
+	started.Add(n)
+	done.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			out, err := preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+			assert.NoError(t, err)
+			results[i] = out
+		}(i)
+	}
+	started.Wait()
+	time.Sleep(50 * time.Millisecond) // Let every request join the in-flight call
+	close(ai.release)
+	done.Wait()
+
+	assert.Equal(t, int32(1), ai.calls.Load())
+	for i := 1; i < n; i++ {
+		require.NotNil(t, results[i])
+		assert.Equal(t, "a small program", results[i].Summary)
+		assert.NotSame(t, results[0], results[i], "each caller gets its own copy")
+	}
+}
+
+func TestDeduplicator_DifferentRequestsAreNotShared(t *testing.T) {
+	next := &countingPreview{}
+	preview := NewDeduplicator().Preview(next)
+	ctx := context.Background()
+
+	_, _ = preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+	_, _ = preview.AnalyzePreview(ctx, "package other", "expert", "quick")
+	_, _ = preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+
+	assert.Equal(t, 3, next.calls, "sequential requests each reach the AI")
+}
+
+func TestDeduplicator_SessionsDoNotShareCalls(t *testing.T) {
+	next := &sessionPreview{
+		release: make(chan struct{}),
+		errs:    map[string]error{"alice": ai.ErrBudgetExceeded},
+	}
+	preview := NewDeduplicator().Preview(next)
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := preview.AnalyzePreview(sessionCtx("alice"), "package main", "expert", "quick")
+		leaderErr <- err
+	}()
+	require.Eventually(t, func() bool { return next.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	otherDone := make(chan *review_models.PreviewModeOutput, 1)
+	go func() {
+		out, err := preview.AnalyzePreview(sessionCtx("bob"), "package main", "expert", "quick")
+		assert.NoError(t, err, "bob must not get alice's error")
+		otherDone <- out
+	}()
+	require.Eventually(t, func() bool { return next.calls.Load() == 2 }, time.Second, time.Millisecond,
+		"another session makes its own call")
+
+	close(next.release)
+	assert.ErrorIs(t, <-leaderErr, ai.ErrBudgetExceeded)
+	out := <-otherDone
+	require.NotNil(t, out)
+	assert.Equal(t, "summary of package main", out.Summary)
+}
+
+func TestDeduplicator_FollowerOutlivesCancelledLeader(t *testing.T) {
+	ai := &blockingAIClient{release: make(chan struct{})}
+	preview := NewDeduplicator().Preview(review_services.NewPreviewService(ai, &testutils.MockLogger{}))
+	base := context.WithValue(sessionCtx("alice"), reviewcontext.ModelContextKey, "mistral:7b")
+	leaderCtx, cancelLeader := context.WithCancel(base)
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := preview.AnalyzePreview(leaderCtx, "package main", "expert", "quick")
+		leaderErr <- err
+	}()
+	require.Eventually(t, func() bool { return ai.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	followerDone := make(chan *review_models.PreviewModeOutput, 1)
+	go func() {
+		out, err := preview.AnalyzePreview(base, "package main", "expert", "quick")
+		assert.NoError(t, err)
+		followerDone <- out
+	}()
+	time.Sleep(20 * time.Millisecond) // Let the follower join
+
+	cancelLeader()
+	assert.True(t, errors.Is(<-leaderErr, context.Canceled))
+
+	// The follower runs the analysis itself once the shared call is cancelled
+	require.Eventually(t, func() bool { return ai.calls.Load() == 2 }, time.Second, time.Millisecond)
+	close(ai.release)
+	out := <-followerDone
+	require.NotNil(t, out)
+	assert.Equal(t, "a small program", out.Summary)
+}
@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
+)
+
+// responseKeyPrefix namespaces cached analyzer responses in Redis
+const responseKeyPrefix = "review:response:"
+
+// ModelResolver reports the provider and model that will answer an analysis
+// under ctx. UnifiedAIClient implements it from the user's Portal config.
+type ModelResolver interface {
+	ResolveModel(ctx context.Context) (provider, model string, err error)
+}
+
+// ResponseCache stores successful analyzer results in Redis, keyed by a hash
+// of everything that shapes the AI's answer, so identical requests share one
+// AI call until the TTL expires. Redis errors are logged and treated as
+// misses: the cache never fails an analysis.
+type ResponseCache struct {
+	client   *redis.Client
+	resolver ModelResolver
+	logger   logger.Interface
+	ttl      time.Duration
+}
+
+// NewResponseCache creates a ResponseCache whose entries expire after ttl.
+// resolver supplies the provider and model each key is scoped to.
+func NewResponseCache(client *redis.Client, ttl time.Duration, resolver ModelResolver, logger logger.Interface) *ResponseCache {
+	return &ResponseCache{client: client, ttl: ttl, resolver: resolver, logger: logger}
+}
+
+// ResponseKey returns the cache key for an analysis: a SHA-256 of mode, the
+// provider and model resolver says will answer it, the language carried in
+// ctx, and parts (code, user mode, output mode and any mode-specific input
+// such as the scan query). Users whose Portal configs differ never share an
+// entry, whether or not the request overrides the model.
+func ResponseKey(ctx context.Context, resolver ModelResolver, mode string, parts ...string) (string, error) {
+	provider, model, err := resolver.ResolveModel(ctx)
+	if err != nil {
+		return "", err
+	}
+	language, _ := ctx.Value(reviewcontext.LanguageContextKey).(string)
+	return hashKey(responseKeyPrefix, append([]string{mode, provider, model, language}, parts...)...), nil
+}
+
+// hashKey returns prefix followed by a SHA-256 of values. Each value is
+// length prefixed so different splits of the same bytes never collide.
+func hashKey(prefix string, values ...string) string {
+	h := sha256.New()
+	var size [8]byte
+	for _, value := range values {
+		binary.BigEndian.PutUint64(size[:], uint64(len(value)))
+		h.Write(size[:])
+		h.Write([]byte(value))
+	}
+	return prefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// lookup tracks the cache outcome of one request
+type lookup struct {
+	status atomic.Int32
+}
+
+// Cache outcomes recorded by lookup
+const (
+	lookupNone int32 = iota
+	lookupMiss
+	lookupHit
+)
+
+type lookupKey struct{}
+
+// WithHitTracking returns a context in which cached analyzers record whether
+// they answered from the cache; read the outcome with Hit.
+func WithHitTracking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lookupKey{}, &lookup{})
+}
+
+// Hit reports whether an analysis under ctx was served from the cache.
+// consulted is false when no cached analyzer ran, e.g. caching is disabled or
+// ctx was not prepared with WithHitTracking.
+func Hit(ctx context.Context) (hit, consulted bool) {
+	l, ok := ctx.Value(lookupKey{}).(*lookup)
+	if !ok {
+		return false, false
+	}
+	status := l.status.Load()
+	return status == lookupHit, status != lookupNone
+}
+
+func record(ctx context.Context, status int32) {
+	if l, ok := ctx.Value(lookupKey{}).(*lookup); ok {
+		l.status.Store(status)
+	}
+}
+
+// cached returns the result stored under the key for mode and parts, or runs
+// analyze and stores its result. Errors are returned as-is and never cached.
+// When the model can't be resolved, analyze runs uncached; it will report
+// the same configuration problem.
+func cached[T any](ctx context.Context, c *ResponseCache, mode string, parts []string, analyze func() (*T, error)) (*T, error) {
+	key, err := ResponseKey(ctx, c.resolver, mode, parts...)
+	if err != nil {
+		record(ctx, lookupMiss)
+		return analyze()
+	}
+
+	data, err := c.client.Get(ctx, key).Bytes()
+	switch {
+	case err == nil:
+		var result T
+		if err := json.Unmarshal(data, &result); err == nil {
+			record(ctx, lookupHit)
+			return &result, nil
+		}
+		c.logger.Warn("Discarding unreadable cached review response", "key", key, "error", err.Error())
+	case !errors.Is(err, redis.Nil):
+		c.logger.Warn("Review response cache lookup failed", "error", err.Error())
+	}
+	record(ctx, lookupMiss)
+
+	result, err := analyze()
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(result); err != nil {
+		c.logger.Warn("Failed to encode review response for cache", "error", err.Error())
+	} else if err := c.client.Set(ctx, key, data, c.ttl).Err(); err != nil {
+		c.logger.Warn("Failed to cache review response", "error", err.Error())
+	}
+	return result, nil
+}
+
+// Preview wraps next so identical Preview analyses are served from the cache
+func (c *ResponseCache) Preview(next review_services.PreviewAnalyzer) review_services.PreviewAnalyzer {
+	return &cachedPreview{next: next, cache: c}
+}
+
+// Skim wraps next so identical Skim analyses are served from the cache
+func (c *ResponseCache) Skim(next review_services.SkimAnalyzer) review_services.SkimAnalyzer {
+	return &cachedSkim{next: next, cache: c}
+}
+
+// Scan wraps next so identical Scan analyses are served from the cache
+func (c *ResponseCache) Scan(next review_services.ScanAnalyzer) review_services.ScanAnalyzer {
+	return &cachedScan{next: next, cache: c}
+}
+
+// Detailed wraps next so identical Detailed analyses are served from the cache
+func (c *ResponseCache) Detailed(next review_services.DetailedAnalyzer) review_services.DetailedAnalyzer {
+	return &cachedDetailed{next: next, cache: c}
+}
+
+// Critical wraps next so identical Critical analyses, of whole files or of
+// diffs, are served from the cache
+func (c *ResponseCache) Critical(next review_services.CriticalAnalyzer) review_services.CriticalAnalyzer {
+	return &cachedCritical{next: next, cache: c}
+}
+
+type cachedPreview struct {
+	next  review_services.PreviewAnalyzer
+	cache *ResponseCache
+}
+
+func (p *cachedPreview) AnalyzePreview(ctx context.Context, code, userMode, outputMode string) (*review_models.PreviewModeOutput, error) {
+	return cached(ctx, p.cache, review_models.PreviewMode, []string{code, userMode, outputMode}, func() (*review_models.PreviewModeOutput, error) {
+		return p.next.AnalyzePreview(ctx, code, userMode, outputMode)
+	})
+}
+
+type cachedSkim struct {
+	next  review_services.SkimAnalyzer
+	cache *ResponseCache
+}
+
+func (s *cachedSkim) AnalyzeSkim(ctx context.Context, code, userMode, outputMode string) (*review_models.SkimModeOutput, error) {
+	return cached(ctx, s.cache, review_models.SkimMode, []string{code, userMode, outputMode}, func() (*review_models.SkimModeOutput, error) {
+		return s.next.AnalyzeSkim(ctx, code, userMode, outputMode)
+	})
+}
+
+type cachedScan struct {
+	next  review_services.ScanAnalyzer
+	cache *ResponseCache
+}
+
+func (s *cachedScan) AnalyzeScan(ctx context.Context, query, code, userMode, outputMode string) (*review_models.ScanModeOutput, error) {
+	return cached(ctx, s.cache, review_models.ScanMode, []string{code, userMode, outputMode, query}, func() (*review_models.ScanModeOutput, error) {
+		return s.next.AnalyzeScan(ctx, query, code, userMode, outputMode)
+	})
+}
+
+type cachedDetailed struct {
+	next  review_services.DetailedAnalyzer
+	cache *ResponseCache
+}
+
+func (d *cachedDetailed) AnalyzeDetailed(ctx context.Context, code, target, userMode, outputMode string) (*review_models.DetailedModeOutput, error) {
+	return cached(ctx, d.cache, review_models.DetailedMode, []string{code, userMode, outputMode, target}, func() (*review_models.DetailedModeOutput, error) {
+		return d.next.AnalyzeDetailed(ctx, code, target, userMode, outputMode)
+	})
+}
+
+type cachedCritical struct {
+	next  review_services.CriticalAnalyzer
+	cache *ResponseCache
+}
+
+func (cr *cachedCritical) AnalyzeCritical(ctx context.Context, code string) (*review_models.CriticalModeOutput, error) {
+	return cached(ctx, cr.cache, review_models.CriticalMode, []string{code}, func() (*review_models.CriticalModeOutput, error) {
+		return cr.next.AnalyzeCritical(ctx, code)
+	})
+}
+
+func (cr *cachedCritical) AnalyzeCriticalDiff(ctx context.Context, diff string) (*review_models.CriticalModeOutput, error) {
+	return cached(ctx, cr.cache, review_models.CriticalMode+":diff", []string{diff}, func() (*review_models.CriticalModeOutput, error) {
+		return cr.next.AnalyzeCriticalDiff(ctx, diff)
+	})
+}
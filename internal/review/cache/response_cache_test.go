@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/testutils"
+)
+
+// countingPreview counts AI calls and fails while err is set
+type countingPreview struct {
+	err   error
+	calls int
+}
+
+func (p *countingPreview) AnalyzePreview(_ context.Context, code, _, _ string) (*review_models.PreviewModeOutput, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &review_models.PreviewModeOutput{Summary: "summary of " + code}, nil
+}
+
+// portalConfigs stands in for Portal: it maps session tokens to the
+// "provider/model" of the user's config. A model override in ctx wins.
+type portalConfigs map[string]string
+
+func (p portalConfigs) ResolveModel(ctx context.Context) (provider, model string, err error) {
+	token, _ := ctx.Value(reviewcontext.SessionTokenKey).(string)
+	config, ok := p[token]
+	if !ok {
+		return "", "", errors.New("no AI configuration")
+	}
+	provider, model, _ = strings.Cut(config, "/")
+	if override, _ := ctx.Value(reviewcontext.ModelContextKey).(string); override != "" {
+		model = override
+	}
+	return provider, model, nil
+}
+
+// testConfigs gives alice and carol the same config and bob another
+var testConfigs = portalConfigs{
+	"alice": "ollama/mistral:7b",
+	"bob":   "anthropic/claude-3-5-sonnet",
+	"carol": "ollama/mistral:7b",
+}
+
+// sessionCtx returns a context carrying token as the session token
+func sessionCtx(token string) context.Context {
+	return context.WithValue(context.Background(), reviewcontext.SessionTokenKey, token)
+}
+
+func newTestResponseCache(t *testing.T) (*ResponseCache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return NewResponseCache(client, time.Hour, testConfigs, &testutils.MockLogger{}), mr
+}
+
+func TestResponseCache_IdenticalRequestsShareOneAICall(t *testing.T) {
+	rc, _ := newTestResponseCache(t)
+	next := &countingPreview{}
+	preview := rc.Preview(next)
+	base := sessionCtx("alice")
+
+	ctx := WithHitTracking(base)
+	first, err := preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+	require.NoError(t, err)
+	hit, consulted := Hit(ctx)
+	assert.True(t, consulted)
+	assert.False(t, hit, "first request must reach the AI")
+
+	ctx = WithHitTracking(base)
+	second, err := preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+	require.NoError(t, err)
+	hit, _ = Hit(ctx)
+	assert.True(t, hit, "second identical request must be a cache hit")
+
+	assert.Equal(t, 1, next.calls)
+	assert.Equal(t, first, second)
+}
+
+func TestResponseCache_KeyCoversModelAndOptions(t *testing.T) {
+	rc, _ := newTestResponseCache(t)
+	next := &countingPreview{}
+	preview := rc.Preview(next)
+	ctx := sessionCtx("alice")
+	other := context.WithValue(ctx, reviewcontext.ModelContextKey, "llama3:8b")
+
+	_, _ = preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+	_, _ = preview.AnalyzePreview(ctx, "package main", "beginner", "quick")
+	_, _ = preview.AnalyzePreview(ctx, "package main", "expert", "full")
+	_, _ = preview.AnalyzePreview(ctx, "package other", "expert", "quick")
+	_, _ = preview.AnalyzePreview(other, "package main", "expert", "quick")
+
+	assert.Equal(t, 5, next.calls)
+}
+
+func TestResponseCache_KeyCoversResolvedConfig(t *testing.T) {
+	rc, _ := newTestResponseCache(t)
+	next := &countingPreview{}
+	preview := rc.Preview(next)
+
+	_, _ = preview.AnalyzePreview(sessionCtx("alice"), "package main", "expert", "quick")
+	_, _ = preview.AnalyzePreview(sessionCtx("bob"), "package main", "expert", "quick")
+	assert.Equal(t, 2, next.calls, "users with different Portal configs don't share entries")
+
+	ctx := WithHitTracking(sessionCtx("carol"))
+	_, _ = preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+	hit, _ := Hit(ctx)
+	assert.True(t, hit, "the same resolved provider and model share an entry")
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestResponseCache_UnresolvedModelIsNotCached(t *testing.T) {
+	rc, mr := newTestResponseCache(t)
+	next := &countingPreview{}
+	preview := rc.Preview(next)
+
+	ctx := WithHitTracking(sessionCtx("mallory"))
+	_, err := preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+	require.NoError(t, err)
+	_, _ = preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+
+	assert.Equal(t, 2, next.calls)
+	assert.Empty(t, mr.Keys())
+	hit, consulted := Hit(ctx)
+	assert.True(t, consulted)
+	assert.False(t, hit)
+}
+
+func TestResponseCache_DoesNotCacheErrors(t *testing.T) {
+	rc, mr := newTestResponseCache(t)
+	next := &countingPreview{err: errors.New("model unavailable")}
+	preview := rc.Preview(next)
+
+	_, err := preview.AnalyzePreview(sessionCtx("alice"), "package main", "expert", "quick")
+	require.Error(t, err)
+	assert.Empty(t, mr.Keys())
+
+	next.err = nil
+	ctx := WithHitTracking(sessionCtx("alice"))
+	_, err = preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+	require.NoError(t, err)
+	hit, _ := Hit(ctx)
+	assert.False(t, hit)
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestResponseCache_EntriesExpire(t *testing.T) {
+	rc, mr := newTestResponseCache(t)
+	next := &countingPreview{}
+	preview := rc.Preview(next)
+
+	_, _ = preview.AnalyzePreview(sessionCtx("alice"), "package main", "expert", "quick")
+	mr.FastForward(2 * time.Hour)
+	_, _ = preview.AnalyzePreview(sessionCtx("alice"), "package main", "expert", "quick")
+
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestResponseCache_RedisDownFallsThrough(t *testing.T) {
+	rc, mr := newTestResponseCache(t)
+	next := &countingPreview{}
+	preview := rc.Preview(next)
+	mr.Close()
+
+	ctx := WithHitTracking(sessionCtx("alice"))
+	result, err := preview.AnalyzePreview(ctx, "package main", "expert", "quick")
+	require.NoError(t, err)
+	assert.Equal(t, "summary of package main", result.Summary)
+	hit, _ := Hit(ctx)
+	assert.False(t, hit)
+}
+
+func TestHit_UntrackedContext(t *testing.T) {
+	hit, consulted := Hit(context.Background())
+	assert.False(t, hit)
+	assert.False(t, consulted)
+
+	hit, consulted = Hit(WithHitTracking(context.Background()))
+	assert.False(t, hit)
+	assert.False(t, consulted, "no cached analyzer ran")
+}
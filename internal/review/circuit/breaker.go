@@ -3,49 +3,81 @@ package circuit
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"time"
 
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/health"
 	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sony/gobreaker"
 )
 
 // OllamaCircuitBreaker wraps an Ollama client with circuit breaker protection.
 // Prevents cascading failures when Ollama service is unhealthy.
 type OllamaCircuitBreaker struct {
-	breaker *gobreaker.CircuitBreaker
-	client  review_services.OllamaClientInterface
-	logger  *logger.Logger
+	lastTrippedAt time.Time
+	breaker       *gobreaker.CircuitBreaker
+	client        review_services.OllamaClientInterface
+	logger        *logger.Logger
+	trips         uint64
+	mu            sync.Mutex // Guards lastTrippedAt and trips
+}
+
+// BreakerStatus is a point-in-time view of an OllamaCircuitBreaker for
+// operators. Failure counts reset with the breaker's counting interval and
+// on every state change.
+type BreakerStatus struct {
+	LastTrippedAt       *time.Time `json:"last_tripped_at"` // nil if it never opened
+	State               string     `json:"state"`           // closed, half-open or open
+	Name                string     `json:"name"`
+	Trips               uint64     `json:"trips"` // Times it opened since startup
+	Requests            uint32     `json:"requests"`
+	TotalFailures       uint32     `json:"total_failures"`
+	ConsecutiveFailures uint32     `json:"consecutive_failures"`
 }
 
 // NewOllamaCircuitBreaker creates a circuit breaker wrapper for Ollama client.
-// Configuration:
-// - MaxRequests: 3 (max concurrent half-open requests)
+// Configuration (defaults in config.DefaultCircuitBreaker):
+// - MaxRequests: cfg.HalfOpenMax (max half-open probe requests)
 // - Interval: 60s (window for counting failures)
-// - Timeout: 60s (half-open→open timeout)
-// - ReadyToTrip: 5 consecutive failures triggers open state
-func NewOllamaCircuitBreaker(client review_services.OllamaClientInterface, logger *logger.Logger) *OllamaCircuitBreaker {
+// - Timeout: cfg.Timeout (open→half-open timeout)
+// - ReadyToTrip: cfg.Threshold consecutive failures triggers open state
+// - IsSuccessful: budget refusals are not failures
+func NewOllamaCircuitBreaker(client review_services.OllamaClientInterface, logger *logger.Logger, cfg config.CircuitBreakerConfig) *OllamaCircuitBreaker {
+	cb := &OllamaCircuitBreaker{
+		client: client,
+		logger: logger,
+	}
+
 	settings := gobreaker.Settings{
 		Name:        "ollama",
-		MaxRequests: 3,                // Allow 3 requests in half-open state
+		MaxRequests: cfg.HalfOpenMax,  // Requests allowed in half-open state
 		Interval:    60 * time.Second, // Reset failure count every minute
-		Timeout:     60 * time.Second, // Stay open for 60s before attempting half-open
+		Timeout:     cfg.Timeout,      // Stay open this long before attempting half-open
 		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			// Open circuit after 5 consecutive failures
-			return counts.ConsecutiveFailures >= 5
+			return counts.ConsecutiveFailures >= cfg.Threshold
+		},
+		IsSuccessful: func(err error) bool {
+			// A user over budget says nothing about the AI backend's health
+			return err == nil || errors.Is(err, ai.ErrBudgetExceeded)
 		},
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
 			logger.Warn("Circuit breaker state change", "name", name, "from", from.String(), "to", to.String())
+			if to == gobreaker.StateOpen {
+				cb.mu.Lock()
+				cb.lastTrippedAt = time.Now()
+				cb.trips++
+				cb.mu.Unlock()
+			}
 		},
 	}
 
-	breaker := gobreaker.NewCircuitBreaker(settings)
-
-	return &OllamaCircuitBreaker{
-		breaker: breaker,
-		client:  client,
-		logger:  logger,
-	}
+	cb.breaker = gobreaker.NewCircuitBreaker(settings)
+	return cb
 }
 
 // Generate wraps the Ollama Generate call with circuit breaker protection.
@@ -81,3 +113,65 @@ func (cb *OllamaCircuitBreaker) State() gobreaker.State {
 func (cb *OllamaCircuitBreaker) Counts() gobreaker.Counts {
 	return cb.breaker.Counts()
 }
+
+// Status reports the breaker's current state, failure counts and when it
+// last opened.
+func (cb *OllamaCircuitBreaker) Status() BreakerStatus {
+	// State first: it may move an expired open breaker to half-open
+	state := cb.breaker.State()
+	counts := cb.breaker.Counts()
+
+	status := BreakerStatus{
+		Name:                cb.breaker.Name(),
+		State:               state.String(),
+		Requests:            counts.Requests,
+		TotalFailures:       counts.TotalFailures,
+		ConsecutiveFailures: counts.ConsecutiveFailures,
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	status.Trips = cb.trips
+	if !cb.lastTrippedAt.IsZero() {
+		trippedAt := cb.lastTrippedAt
+		status.LastTrippedAt = &trippedAt
+	}
+	return status
+}
+
+// HealthCheck reports the AI backend as degraded while the breaker is open:
+// the service still runs, but AI analyses fail fast.
+func (cb *OllamaCircuitBreaker) HealthCheck(ctx context.Context) health.Result {
+	if cb.breaker.State() == gobreaker.StateOpen {
+		return health.Result{Status: health.StatusDegraded, Message: "circuit breaker open: AI calls are failing fast"}
+	}
+	return health.Result{Status: health.StatusHealthy}
+}
+
+// RegisterMetrics exposes the breaker's state (0 closed, 1 half-open,
+// 2 open), consecutive failures and trip count on reg, labelled with the
+// breaker name.
+func (cb *OllamaCircuitBreaker) RegisterMetrics(reg prometheus.Registerer) {
+	labels := prometheus.Labels{"breaker": cb.breaker.Name()}
+	reg.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "review_ai_circuit_state",
+			Help:        "AI circuit breaker state: 0 closed, 1 half-open, 2 open.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(cb.breaker.State()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "review_ai_circuit_consecutive_failures",
+			Help:        "Consecutive failed AI calls counted by the circuit breaker.",
+			ConstLabels: labels,
+		}, func() float64 { return float64(cb.breaker.Counts().ConsecutiveFailures) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "review_ai_circuit_trips_total",
+			Help:        "Times the AI circuit breaker opened.",
+			ConstLabels: labels,
+		}, func() float64 {
+			cb.mu.Lock()
+			defer cb.mu.Unlock()
+			return float64(cb.trips)
+		}),
+	)
+}
@@ -0,0 +1,167 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/health"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingClient fails while err is set. When block is set, calls wait on it
+// first, so tests can hold requests in flight.
+type failingClient struct {
+	block chan struct{}
+	err   error
+	mu    sync.Mutex
+}
+
+func (f *failingClient) Generate(ctx context.Context, prompt string) (string, error) {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return "", f.err
+	}
+	return "ok", nil
+}
+
+func (f *failingClient) recover() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = nil
+}
+
+func newTestBreaker(t *testing.T, client *failingClient) *OllamaCircuitBreaker {
+	t.Helper()
+	return newConfiguredBreaker(t, client, config.DefaultCircuitBreaker)
+}
+
+func newConfiguredBreaker(t *testing.T, client *failingClient, cfg config.CircuitBreakerConfig) *OllamaCircuitBreaker {
+	t.Helper()
+	log, err := logger.NewLogger(&logger.Config{ServiceName: "review-test", LogLevel: "error"})
+	require.NoError(t, err)
+	return NewOllamaCircuitBreaker(client, log, cfg)
+}
+
+func TestOllamaCircuitBreaker_StatusReportsTrip(t *testing.T) {
+	client := &failingClient{err: errors.New("connection refused")}
+	cb := newTestBreaker(t, client)
+
+	status := cb.Status()
+	assert.Equal(t, gobreaker.StateClosed.String(), status.State)
+	assert.Nil(t, status.LastTrippedAt)
+	assert.Equal(t, health.StatusHealthy, cb.HealthCheck(context.Background()).Status)
+
+	for i := 0; i < 4; i++ {
+		_, err := cb.Generate(context.Background(), "prompt")
+		require.Error(t, err)
+	}
+	status = cb.Status()
+	assert.Equal(t, gobreaker.StateClosed.String(), status.State)
+	assert.Equal(t, uint32(4), status.ConsecutiveFailures)
+
+	_, err := cb.Generate(context.Background(), "prompt")
+	require.Error(t, err)
+
+	status = cb.Status()
+	assert.Equal(t, gobreaker.StateOpen.String(), status.State)
+	assert.Equal(t, "ollama", status.Name)
+	assert.Equal(t, uint64(1), status.Trips)
+	require.NotNil(t, status.LastTrippedAt)
+
+	result := cb.HealthCheck(context.Background())
+	assert.Equal(t, health.StatusDegraded, result.Status)
+	assert.NotEmpty(t, result.Message)
+
+	_, err = cb.Generate(context.Background(), "prompt")
+	assert.ErrorIs(t, err, gobreaker.ErrOpenState)
+}
+
+func TestOllamaCircuitBreaker_Metrics(t *testing.T) {
+	cb := newTestBreaker(t, &failingClient{err: errors.New("timeout")})
+	reg := prometheus.NewRegistry()
+	cb.RegisterMetrics(reg)
+
+	for i := 0; i < 5; i++ {
+		_, _ = cb.Generate(context.Background(), "prompt") //nolint:errcheck // Driving the breaker open
+	}
+
+	w := httptest.NewRecorder()
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+
+	out := string(body)
+	assert.Contains(t, out, `review_ai_circuit_state{breaker="ollama"} 2`)
+	assert.Contains(t, out, `review_ai_circuit_trips_total{breaker="ollama"} 1`)
+	// Counts reset when the breaker opens
+	assert.Contains(t, out, `review_ai_circuit_consecutive_failures{breaker="ollama"} 0`)
+}
+
+func TestOllamaCircuitBreaker_ConfiguredThreshold(t *testing.T) {
+	cfg := config.DefaultCircuitBreaker
+	cfg.Threshold = 2
+	cb := newConfiguredBreaker(t, &failingClient{err: errors.New("connection refused")}, cfg)
+
+	_, err := cb.Generate(context.Background(), "prompt")
+	require.Error(t, err)
+	assert.Equal(t, gobreaker.StateClosed, cb.State())
+
+	_, err = cb.Generate(context.Background(), "prompt")
+	require.Error(t, err)
+	assert.Equal(t, gobreaker.StateOpen, cb.State())
+}
+
+func TestOllamaCircuitBreaker_HalfOpenProbeLimit(t *testing.T) {
+	cfg := config.CircuitBreakerConfig{Threshold: 1, Timeout: 20 * time.Millisecond, HalfOpenMax: 2}
+	client := &failingClient{err: errors.New("connection refused")}
+	cb := newConfiguredBreaker(t, client, cfg)
+
+	_, err := cb.Generate(context.Background(), "prompt")
+	require.Error(t, err)
+	require.Equal(t, gobreaker.StateOpen, cb.State())
+
+	client.recover()
+	client.block = make(chan struct{})
+	require.Eventually(t, func() bool { return cb.State() == gobreaker.StateHalfOpen }, time.Second, 5*time.Millisecond)
+
+	// Two probes may be in flight; a third is refused until they finish
+	var wg sync.WaitGroup
+	probeErrs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cb.Generate(context.Background(), "probe")
+			probeErrs <- err
+		}()
+	}
+	require.Eventually(t, func() bool { return cb.Counts().Requests == 2 }, time.Second, time.Millisecond)
+
+	_, err = cb.Generate(context.Background(), "probe")
+	assert.ErrorIs(t, err, gobreaker.ErrTooManyRequests)
+
+	close(client.block)
+	wg.Wait()
+	close(probeErrs)
+	for err := range probeErrs {
+		assert.NoError(t, err)
+	}
+	// HalfOpenMax successes close the breaker
+	assert.Equal(t, gobreaker.StateClosed, cb.State())
+}
@@ -9,3 +9,7 @@ const ModelContextKey contextKey = "model"
 // SessionTokenKey is used to pass the user's session token through the request context
 // This is set by RedisSessionAuthMiddleware and used to query Portal's AI Factory
 const SessionTokenKey contextKey = "session_token"
+
+// LanguageContextKey is used to pass the detected programming language of the
+// submitted code to the analyzers, so prompts can be language-specific
+const LanguageContextKey contextKey = "language"
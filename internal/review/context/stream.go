@@ -0,0 +1,23 @@
+package reviewcontext
+
+import "context"
+
+// ChunkFunc receives model output while it is being generated. Returning an
+// error aborts generation.
+type ChunkFunc func(chunk string) error
+
+// chunkFuncKey carries the ChunkFunc set by WithChunkFunc
+const chunkFuncKey contextKey = "chunk_func"
+
+// WithChunkFunc asks the AI client to stream output to fn as it arrives.
+// The complete output is still returned from Generate as usual.
+func WithChunkFunc(ctx context.Context, fn ChunkFunc) context.Context {
+	return context.WithValue(ctx, chunkFuncKey, fn)
+}
+
+// ChunkFuncFrom returns the ChunkFunc set on ctx, or nil if output should
+// not be streamed.
+func ChunkFuncFrom(ctx context.Context) ChunkFunc {
+	fn, _ := ctx.Value(chunkFuncKey).(ChunkFunc)
+	return fn
+}
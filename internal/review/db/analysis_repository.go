@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
@@ -35,15 +36,226 @@ func (r *AnalysisRepository) FindByReviewAndMode(ctx context.Context, reviewID i
 }
 
 // Create inserts a new analysis result into the database.
+// An empty InputHash or Output is stored as NULL.
 func (r *AnalysisRepository) Create(ctx context.Context, result *review_models.AnalysisResult) error {
-	_, err := r.DB.ExecContext(ctx, `INSERT INTO reviews.analysis_results (review_id, mode, prompt, summary, metadata, model_used, raw_output) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
-		result.ReviewID, result.Mode, result.Prompt, result.Summary, result.Metadata, result.ModelUsed, result.RawOutput)
+	_, err := r.DB.ExecContext(ctx, `INSERT INTO reviews.analysis_results (review_id, mode, prompt, summary, metadata, model_used, raw_output, input_hash, output) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, '')::jsonb)`,
+		result.ReviewID, result.Mode, result.Prompt, result.Summary, result.Metadata, result.ModelUsed, result.RawOutput, result.InputHash, result.Output)
 	if err != nil {
 		return fmt.Errorf("db: failed to create analysis result: %w", err)
 	}
 	return nil
 }
 
+// cachedResultColumns are the columns scanned by scanCachedResult.
+const cachedResultColumns = `review_id, mode, COALESCE(summary, ''), COALESCE(metadata::text, '{}'), COALESCE(model_used, ''), input_hash, output::text, created_at`
+
+// FindLatest returns the newest stored mode output for a review whose input
+// hash matches, or nil if there is none.
+func (r *AnalysisRepository) FindLatest(ctx context.Context, reviewID int64, mode, inputHash string) (*review_models.AnalysisResult, error) {
+	row := r.DB.QueryRowContext(ctx, `SELECT `+cachedResultColumns+` FROM reviews.analysis_results
+		WHERE review_id = $1 AND mode = $2 AND input_hash = $3 AND output IS NOT NULL
+		ORDER BY created_at DESC, id DESC LIMIT 1`, reviewID, mode, inputHash)
+	result, err := scanCachedResult(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to get cached analysis result: %w", err)
+	}
+	return result, nil
+}
+
+// ListLatestByReview returns the newest stored mode output of each mode for
+// a review, most recent first.
+func (r *AnalysisRepository) ListLatestByReview(ctx context.Context, reviewID int64) ([]review_models.AnalysisResult, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT * FROM (
+			SELECT DISTINCT ON (mode) `+cachedResultColumns+` FROM reviews.analysis_results
+			WHERE review_id = $1 AND output IS NOT NULL
+			ORDER BY mode, created_at DESC, id DESC
+		) latest ORDER BY created_at DESC`, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to list analysis results: %w", err)
+	}
+	defer rows.Close()
+
+	results := []review_models.AnalysisResult{}
+	for rows.Next() {
+		result, err := scanCachedResult(rows)
+		if err != nil {
+			return nil, fmt.Errorf("db: failed to scan analysis result: %w", err)
+		}
+		results = append(results, *result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db: analysis result rows error: %w", err)
+	}
+	return results, nil
+}
+
+// ListByReview returns every stored result for a review in the order the
+// modes were run. It is the session's mode history.
+func (r *AnalysisRepository) ListByReview(ctx context.Context, reviewID int64) ([]review_models.AnalysisResult, error) {
+	rows, err := r.DB.QueryContext(ctx, `SELECT review_id, mode, COALESCE(summary, ''), COALESCE(model_used, ''), created_at
+		FROM reviews.analysis_results WHERE review_id = $1 ORDER BY created_at, id`, reviewID)
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to list mode history: %w", err)
+	}
+	defer rows.Close()
+
+	results := []review_models.AnalysisResult{}
+	for rows.Next() {
+		var result review_models.AnalysisResult
+		if err := rows.Scan(&result.ReviewID, &result.Mode, &result.Summary, &result.ModelUsed, &result.CreatedAt); err != nil {
+			return nil, fmt.Errorf("db: failed to scan mode history: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db: mode history rows error: %w", err)
+	}
+	return results, nil
+}
+
+// sessionSummaryQuery selects review sessions with the number of distinct
+// modes run against each. A session is completed once all five modes ran.
+const sessionSummaryQuery = `SELECT s.id, COALESCE(s.title, ''), COALESCE(s.code_source, ''), COALESCE(s.github_repo, ''),
+		CASE WHEN COUNT(DISTINCT a.mode) >= 5 THEN 'completed' ELSE 'active' END,
+		COUNT(DISTINCT a.mode), s.created_at, COALESCE(MAX(a.created_at), s.last_accessed, s.created_at)
+	FROM reviews.sessions s
+	LEFT JOIN reviews.analysis_results a ON a.review_id = s.id
+	WHERE s.user_id = $1`
+
+// ListSessionsByUser returns a page of the user's review sessions, newest
+// first. A non-empty search matches title or GitHub repository, ignoring case.
+func (r *AnalysisRepository) ListSessionsByUser(ctx context.Context, userID int64, search string, limit, offset int) ([]*review_models.SessionSummary, error) {
+	query := sessionSummaryQuery
+	args := []interface{}{userID}
+	if search != "" {
+		query += ` AND (s.title ILIKE $2 OR s.github_repo ILIKE $2)`
+		args = append(args, "%"+escapeLike(search)+"%")
+	}
+	query += fmt.Sprintf(` GROUP BY s.id ORDER BY s.created_at DESC, s.id DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*review_models.SessionSummary{}
+	for rows.Next() {
+		session, err := scanSessionSummary(rows)
+		if err != nil {
+			return nil, fmt.Errorf("db: failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db: session rows error: %w", err)
+	}
+	return sessions, nil
+}
+
+// GetSessionByUser returns one of the user's review sessions, or nil if the
+// session does not exist or belongs to someone else.
+func (r *AnalysisRepository) GetSessionByUser(ctx context.Context, userID, sessionID int64) (*review_models.SessionSummary, error) {
+	row := r.DB.QueryRowContext(ctx, sessionSummaryQuery+` AND s.id = $2 GROUP BY s.id`, userID, sessionID)
+	session, err := scanSessionSummary(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to get session: %w", err)
+	}
+	return session, nil
+}
+
+// gradeRankExpr ranks a result's overall_grade from F=1 to A=5, or NULL when
+// it has none. It must match idx_analysis_results_grade_rank.
+const gradeRankExpr = `position(NULLIF(upper(left(a.output->>'overall_grade', 1)), '') IN '` + review_models.Grades + `')`
+
+// SearchAnalyses returns a page of the user's stored mode results matching
+// filter, newest first.
+func (r *AnalysisRepository) SearchAnalyses(ctx context.Context, userID int64, filter review_models.AnalysisSearchFilter) ([]review_models.AnalysisSearchResult, error) {
+	query := `SELECT a.id, s.id, a.created_at, COALESCE(s.title, ''), COALESCE(s.github_repo, ''), a.mode,
+			COALESCE(a.summary, ''), COALESCE(a.output->>'overall_grade', ''), COALESCE(a.model_used, '')
+		FROM reviews.analysis_results a
+		JOIN reviews.sessions s ON s.id = a.review_id
+		WHERE s.user_id = $1`
+	args := []interface{}{userID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Mode != "" {
+		query += ` AND a.mode = ` + arg(filter.Mode)
+	}
+	if filter.Repo != "" {
+		query += ` AND s.github_repo ILIKE ` + arg("%"+escapeLike(filter.Repo)+"%")
+	}
+	if filter.MinGrade != "" || filter.MaxGrade != "" {
+		query += ` AND a.output ? 'overall_grade'`
+	}
+	if rank := review_models.GradeRank(filter.MinGrade); rank > 0 {
+		query += ` AND ` + gradeRankExpr + ` >= ` + arg(rank)
+	}
+	if rank := review_models.GradeRank(filter.MaxGrade); rank > 0 {
+		query += ` AND ` + gradeRankExpr + ` BETWEEN 1 AND ` + arg(rank)
+	}
+	if !filter.DateFrom.IsZero() {
+		query += ` AND a.created_at >= ` + arg(filter.DateFrom)
+	}
+	if !filter.DateTo.IsZero() {
+		query += ` AND a.created_at < ` + arg(filter.DateTo)
+	}
+	query += ` ORDER BY a.created_at DESC, a.id DESC LIMIT ` + arg(filter.Limit) + ` OFFSET ` + arg(filter.Offset)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to search analyses: %w", err)
+	}
+	defer rows.Close()
+
+	results := []review_models.AnalysisSearchResult{}
+	for rows.Next() {
+		var result review_models.AnalysisSearchResult
+		if err := rows.Scan(&result.ID, &result.SessionID, &result.CreatedAt, &result.SessionTitle, &result.GithubRepo,
+			&result.Mode, &result.Summary, &result.Grade, &result.ModelUsed); err != nil {
+			return nil, fmt.Errorf("db: failed to scan analysis search result: %w", err)
+		}
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("db: analysis search rows error: %w", err)
+	}
+	return results, nil
+}
+
+func scanSessionSummary(row interface{ Scan(...interface{}) error }) (*review_models.SessionSummary, error) {
+	var session review_models.SessionSummary
+	if err := row.Scan(&session.ID, &session.Title, &session.CodeSource, &session.GithubRepo,
+		&session.Status, &session.ModeProgress, &session.CreatedAt, &session.LastAccessedAt); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// escapeLike escapes LIKE wildcards so search text matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func scanCachedResult(row interface{ Scan(...interface{}) error }) (*review_models.AnalysisResult, error) {
+	var result review_models.AnalysisResult
+	if err := row.Scan(&result.ReviewID, &result.Mode, &result.Summary, &result.Metadata,
+		&result.ModelUsed, &result.InputHash, &result.Output, &result.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // DeleteOlderThan removes analysis results older than the provided cutoff time.
 func (r *AnalysisRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) error {
 	// NOTE: The table is expected to have a created_at column.
@@ -53,3 +265,22 @@ func (r *AnalysisRepository) DeleteOlderThan(ctx context.Context, cutoff time.Ti
 	}
 	return nil
 }
+
+// GetAnalysisDetail returns a stored result with its session, or nil if
+// there is no such result or it isn't linked to a session.
+func (r *AnalysisRepository) GetAnalysisDetail(ctx context.Context, analysisID int64) (*review_models.AnalysisDetail, error) {
+	var detail review_models.AnalysisDetail
+	err := r.DB.QueryRowContext(ctx, `SELECT a.id, a.review_id, s.user_id, COALESCE(s.title, ''), COALESCE(s.github_repo, ''),
+			a.mode, COALESCE(a.summary, ''), COALESCE(a.model_used, ''), COALESCE(a.output::text, ''), a.created_at
+		FROM reviews.analysis_results a
+		JOIN reviews.sessions s ON s.id = a.review_id
+		WHERE a.id = $1`, analysisID).Scan(&detail.ID, &detail.ReviewID, &detail.UserID, &detail.SessionTitle, &detail.GithubRepo,
+		&detail.Mode, &detail.Summary, &detail.ModelUsed, &detail.Output, &detail.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to get analysis result: %w", err)
+	}
+	return &detail, nil
+}
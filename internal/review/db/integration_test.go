@@ -11,6 +11,7 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
@@ -106,6 +107,35 @@ func setupIntegrationDB(ctx context.Context, t *testing.T) *sql.DB {
 	`)
 	require.NoError(t, err)
 
+	_, err = db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS reviews.analysis_results (
+			id SERIAL PRIMARY KEY,
+			review_id INT NOT NULL REFERENCES reviews.sessions(id) ON DELETE CASCADE,
+			mode VARCHAR(20) NOT NULL,
+			prompt TEXT,
+			summary TEXT,
+			metadata JSONB,
+			model_used VARCHAR(100),
+			raw_output TEXT,
+			input_hash VARCHAR(64),
+			output JSONB,
+			created_at TIMESTAMP DEFAULT NOW()
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS reviews.analysis_shares (
+			id VARCHAR(32) PRIMARY KEY,
+			analysis_id INT NOT NULL REFERENCES reviews.analysis_results(id) ON DELETE CASCADE,
+			user_id BIGINT NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			revoked_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	require.NoError(t, err)
+
 	return db
 }
 
@@ -657,3 +687,133 @@ func TestIntegration_ReviewRepository_FindExpiredSessions(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, expiredSessions, "recent session should not be expired")
 }
+
+// TestIntegration_AnalysisRepository_SearchAnalyses checks that each search
+// filter narrows a user's analysis history and other users' results never
+// appear
+func TestIntegration_AnalysisRepository_SearchAnalyses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	db := setupIntegrationDB(ctx, t)
+	defer db.Close()
+
+	session := func(userID int64, repo string) int64 {
+		var id int64
+		err := db.QueryRowContext(ctx, `INSERT INTO reviews.sessions (user_id, title, code_source, github_repo) VALUES ($1, $2, 'github', $3) RETURNING id`,
+			userID, "Review of "+repo, repo).Scan(&id)
+		require.NoError(t, err)
+		return id
+	}
+	day := func(d int) time.Time { return time.Date(2025, 11, d, 12, 0, 0, 0, time.UTC) }
+	analysis := func(sessionID int64, mode, output string, created time.Time) int64 {
+		var id int64
+		err := db.QueryRowContext(ctx, `INSERT INTO reviews.analysis_results (review_id, mode, summary, model_used, output, created_at)
+			VALUES ($1, $2, $2 || ' done', 'qwen2.5-coder', NULLIF($3, '')::jsonb, $4) RETURNING id`,
+			sessionID, mode, output, created).Scan(&id)
+		require.NoError(t, err)
+		return id
+	}
+
+	shop := session(1, "acme/shop")
+	billing := session(1, "acme/billing")
+	tool := session(1, "other/tool")
+	theirs := session(2, "acme/shop")
+
+	previewTool := analysis(tool, "preview", `{"summary":"cli"}`, day(5))
+	skimShop := analysis(shop, "skim", `{"summary":"shop"}`, day(10))
+	criticalF := analysis(shop, "critical", `{"overall_grade":"F"}`, day(12))
+	criticalC := analysis(billing, "critical", `{"overall_grade":"c+"}`, day(15))
+	criticalA := analysis(shop, "critical", `{"overall_grade":"A"}`, day(18))
+	analysis(theirs, "critical", `{"overall_grade":"A"}`, day(20))
+
+	repo := NewAnalysisRepository(db)
+	search := func(filter review_models.AnalysisSearchFilter) []int64 {
+		t.Helper()
+		if filter.Limit == 0 {
+			filter.Limit = 20
+		}
+		results, err := repo.SearchAnalyses(ctx, 1, filter)
+		require.NoError(t, err)
+		ids := []int64{}
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int64{criticalA, criticalC, criticalF, skimShop, previewTool}, search(review_models.AnalysisSearchFilter{}))
+	assert.Equal(t, []int64{criticalA, criticalC, criticalF}, search(review_models.AnalysisSearchFilter{Mode: "critical"}))
+	assert.Equal(t, []int64{criticalA, criticalF, skimShop}, search(review_models.AnalysisSearchFilter{Repo: "SHOP"}))
+	assert.Empty(t, search(review_models.AnalysisSearchFilter{Repo: "%"}), "LIKE wildcards match literally")
+	assert.Equal(t, []int64{criticalA, criticalC}, search(review_models.AnalysisSearchFilter{MinGrade: "C"}))
+	assert.Equal(t, []int64{criticalC, criticalF}, search(review_models.AnalysisSearchFilter{MaxGrade: "C"}))
+	assert.Equal(t, []int64{criticalC}, search(review_models.AnalysisSearchFilter{MinGrade: "D", MaxGrade: "B"}))
+	assert.Equal(t, []int64{criticalC, criticalF, skimShop}, search(review_models.AnalysisSearchFilter{DateFrom: day(10), DateTo: day(18)}))
+	assert.Equal(t, []int64{criticalA}, search(review_models.AnalysisSearchFilter{Mode: "critical", Repo: "shop", MinGrade: "B"}))
+
+	// Pagination
+	assert.Equal(t, []int64{criticalC, criticalF}, search(review_models.AnalysisSearchFilter{Limit: 2, Offset: 1}))
+
+	// Summaries carry the session they belong to
+	results, err := repo.SearchAnalyses(ctx, 1, review_models.AnalysisSearchFilter{Mode: "critical", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, shop, results[0].SessionID)
+	assert.Equal(t, "acme/shop", results[0].GithubRepo)
+	assert.Equal(t, "Review of acme/shop", results[0].SessionTitle)
+	assert.Equal(t, "A", results[0].Grade)
+	assert.Equal(t, "critical done", results[0].Summary)
+	assert.Equal(t, "qwen2.5-coder", results[0].ModelUsed)
+}
+
+// TestIntegration_ShareRepository covers storing, loading and revoking
+// analysis share links
+func TestIntegration_ShareRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	db := setupIntegrationDB(ctx, t)
+	defer db.Close()
+
+	var sessionID, analysisID int64
+	require.NoError(t, db.QueryRowContext(ctx, `INSERT INTO reviews.sessions (user_id, title, github_repo) VALUES (7, 'Checkout', 'acme/shop') RETURNING id`).Scan(&sessionID))
+	require.NoError(t, db.QueryRowContext(ctx, `INSERT INTO reviews.analysis_results (review_id, mode, summary, output) VALUES ($1, 'critical', 'Two issues', '{"overall_grade":"C"}') RETURNING id`, sessionID).Scan(&analysisID))
+
+	detail, err := NewAnalysisRepository(db).GetAnalysisDetail(ctx, analysisID)
+	require.NoError(t, err)
+	require.NotNil(t, detail)
+	assert.Equal(t, int64(7), detail.UserID)
+	assert.Equal(t, "Checkout", detail.SessionTitle)
+	assert.JSONEq(t, `{"overall_grade":"C"}`, detail.Output)
+
+	repo := NewShareRepository(db)
+	share := &review_models.AnalysisShare{ID: "0123456789abcdef0123456789abcdef", AnalysisID: analysisID, UserID: 7, ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second)}
+	require.NoError(t, repo.CreateShare(ctx, share))
+	assert.False(t, share.CreatedAt.IsZero())
+
+	stored, err := repo.GetShare(ctx, share.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, analysisID, stored.AnalysisID)
+	assert.Nil(t, stored.RevokedAt)
+
+	found, err := repo.RevokeShare(ctx, 8, share.ID)
+	require.NoError(t, err)
+	assert.False(t, found, "another user can't revoke the share")
+
+	found, err = repo.RevokeShare(ctx, 7, share.ID)
+	require.NoError(t, err)
+	assert.True(t, found)
+	stored, err = repo.GetShare(ctx, share.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.RevokedAt)
+
+	missing, err := repo.GetShare(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/review/repositories"
 )
 
 // PromptTemplateRepository handles database operations for prompt templates
@@ -89,48 +90,37 @@ func (r *PromptTemplateRepository) UpdateCustom(ctx context.Context, pt *review_
 }
 
 // Upsert creates or updates a custom prompt (implements the interface)
+// Every save bumps the version and is recorded in the version history kept
+// by repositories.PromptTemplateRepository.
 func (r *PromptTemplateRepository) Upsert(ctx context.Context, template *review_models.PromptTemplate) (*review_models.PromptTemplate, error) {
-	// Serialize variables to JSON
-	variablesJSON, err := json.Marshal(template.Variables)
+	result, err := r.versions().UpsertNextVersion(ctx, template)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal variables: %w", err)
+		return nil, fmt.Errorf("failed to upsert prompt: %w", err)
 	}
+	return result, nil
+}
 
-	query := `
-		INSERT INTO review.prompt_templates 
-		(id, user_id, mode, user_level, output_mode, prompt_text, variables, is_default, version)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		ON CONFLICT (user_id, mode, user_level, output_mode)
-		DO UPDATE SET
-			prompt_text = EXCLUDED.prompt_text,
-			variables = EXCLUDED.variables,
-			version = review.prompt_templates.version + 1,
-			updated_at = NOW()
-		RETURNING id, user_id, mode, user_level, output_mode, prompt_text, variables, is_default, version, created_at, updated_at
-	`
-
-	var result review_models.PromptTemplate
-	var variablesJSONResult []byte
-
-	err = r.DB.QueryRowContext(ctx, query,
-		template.ID, template.UserID, template.Mode, template.UserLevel, template.OutputMode,
-		template.PromptText, variablesJSON, template.IsDefault, template.Version,
-	).Scan(
-		&result.ID, &result.UserID, &result.Mode, &result.UserLevel, &result.OutputMode,
-		&result.PromptText, &variablesJSONResult, &result.IsDefault, &result.Version,
-		&result.CreatedAt, &result.UpdatedAt,
-	)
+// versions returns the repository that owns prompt version history.
+func (r *PromptTemplateRepository) versions() *repositories.PromptTemplateRepository {
+	return repositories.NewPromptTemplateRepository(r.DB)
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to upsert prompt: %w", err)
-	}
+// FindVersion returns a historical prompt version by id, or nil if there is
+// none (implements the interface)
+func (r *PromptTemplateRepository) FindVersion(ctx context.Context, versionID int64) (*review_models.PromptTemplateVersion, error) {
+	return r.versions().FindVersion(ctx, versionID)
+}
 
-	// Parse variables JSON from result
-	if err := json.Unmarshal(variablesJSONResult, &result.Variables); err != nil {
-		return nil, fmt.Errorf("failed to parse variables JSON: %w", err)
-	}
+// ListVersions returns the version history of a user's custom prompt, newest
+// first (implements the interface)
+func (r *PromptTemplateRepository) ListVersions(ctx context.Context, userID int, mode, userLevel, outputMode string) ([]*review_models.PromptTemplateVersion, error) {
+	return r.versions().ListVersions(ctx, userID, mode, userLevel, outputMode)
+}
 
-	return &result, nil
+// RestoreVersion makes a historical version the active prompt again
+// (implements the interface)
+func (r *PromptTemplateRepository) RestoreVersion(ctx context.Context, version *review_models.PromptTemplateVersion) (*review_models.PromptTemplate, error) {
+	return r.versions().RestoreVersion(ctx, version)
 }
 
 // DeleteUserCustom deletes a user's custom prompt (implements the interface)
@@ -0,0 +1,64 @@
+package review_db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// ShareRepository stores read-only share links for analysis results.
+type ShareRepository struct {
+	DB *sql.DB
+}
+
+// NewShareRepository creates a new ShareRepository with the given DB connection.
+func NewShareRepository(db *sql.DB) *ShareRepository {
+	return &ShareRepository{DB: db}
+}
+
+// CreateShare inserts share and sets its CreatedAt.
+func (r *ShareRepository) CreateShare(ctx context.Context, share *review_models.AnalysisShare) error {
+	err := r.DB.QueryRowContext(ctx, `INSERT INTO reviews.analysis_shares (id, analysis_id, user_id, expires_at)
+		VALUES ($1, $2, $3, $4) RETURNING created_at`,
+		share.ID, share.AnalysisID, share.UserID, share.ExpiresAt).Scan(&share.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("db: failed to create share: %w", err)
+	}
+	return nil
+}
+
+// GetShare returns a share by ID, or nil if there is none.
+func (r *ShareRepository) GetShare(ctx context.Context, id string) (*review_models.AnalysisShare, error) {
+	var share review_models.AnalysisShare
+	var revokedAt sql.NullTime
+	err := r.DB.QueryRowContext(ctx, `SELECT id, analysis_id, user_id, expires_at, revoked_at, created_at
+		FROM reviews.analysis_shares WHERE id = $1`, id).Scan(
+		&share.ID, &share.AnalysisID, &share.UserID, &share.ExpiresAt, &revokedAt, &share.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("db: failed to get share: %w", err)
+	}
+	if revokedAt.Valid {
+		share.RevokedAt = &revokedAt.Time
+	}
+	return &share, nil
+}
+
+// RevokeShare revokes one of the user's shares. It reports false if the user
+// has no share with that ID; revoking twice keeps the first revocation time.
+func (r *ShareRepository) RevokeShare(ctx context.Context, userID int64, id string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `UPDATE reviews.analysis_shares SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("db: failed to revoke share: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("db: failed to revoke share: %w", err)
+	}
+	return n > 0, nil
+}
@@ -21,6 +21,11 @@ func (e *InfrastructureError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// Unwrap returns the underlying cause
+func (e *InfrastructureError) Unwrap() error {
+	return e.Cause
+}
+
 // StatusCode returns the HTTP status code for this infrastructure error
 func (e *InfrastructureError) StatusCode() int {
 	if e.HTTPStatus > 0 {
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTokenURL is GitHub's OAuth token endpoint, which also serves
+// refresh exchanges
+const DefaultTokenURL = "https://github.com/login/oauth/access_token"
+
+// ErrReauthRequired is matched by errors meaning the user's GitHub token is
+// expired or revoked and they have to sign in with GitHub again
+var ErrReauthRequired = errors.New("github token expired or revoked")
+
+// Is matches ErrReauthRequired for 401s, so callers can use errors.Is
+// without caring whether the token was rejected by the API or the refresh
+func (e *AuthError) Is(target error) bool {
+	return target == ErrReauthRequired && e.StatusCode == http.StatusUnauthorized
+}
+
+// AuthTransport turns 401 responses from GitHub into an *AuthError, which go-github
+// and net/http pass back to the caller wrapped, so every API call on the
+// client reports a rejected token the same way
+func AuthTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &unauthorizedTransport{base: base}
+}
+
+type unauthorizedTransport struct {
+	base http.RoundTripper
+}
+
+func (t *unauthorizedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	//nolint:errcheck // the message is only informational
+	json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&body)
+	//nolint:errcheck // nothing useful to do with a close error here
+	resp.Body.Close()
+	if body.Message == "" {
+		body.Message = "Bad credentials"
+	}
+	return nil, &AuthError{Message: body.Message, StatusCode: http.StatusUnauthorized}
+}
+
+// Token is a GitHub user access token. Tokens from GitHub Apps with
+// expiring user tokens enabled carry a refresh token and an expiry; OAuth
+// App tokens have neither and never expire.
+type Token struct {
+	Expiry       time.Time
+	AccessToken  string
+	RefreshToken string
+}
+
+// NeedsRefresh reports whether the token expires within margin and can be
+// refreshed
+func (t *Token) NeedsRefresh(now time.Time, margin time.Duration) bool {
+	return t.RefreshToken != "" && !t.Expiry.IsZero() && now.Add(margin).After(t.Expiry)
+}
+
+// TokenRefresher exchanges refresh tokens for new user access tokens
+type TokenRefresher struct {
+	httpClient   *http.Client
+	clientID     string
+	clientSecret string
+	tokenURL     string
+	now          func() time.Time
+}
+
+// NewTokenRefresher creates a refresher for the OAuth app with the given
+// credentials, using GitHub's token endpoint
+func NewTokenRefresher(clientID, clientSecret string) *TokenRefresher {
+	return &TokenRefresher{
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		tokenURL:     DefaultTokenURL,
+		now:          time.Now,
+	}
+}
+
+// SetTokenURL overrides the token endpoint, e.g. for GitHub Enterprise
+func (r *TokenRefresher) SetTokenURL(tokenURL string) {
+	r.tokenURL = tokenURL
+}
+
+// Refresh exchanges refreshToken for a new token. GitHub rotates refresh
+// tokens, so the returned RefreshToken replaces the old one. A refresh
+// token GitHub no longer accepts is reported as an *AuthError matching
+// ErrReauthRequired.
+func (r *TokenRefresher) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	form := url.Values{
+		"client_id":     {r.clientID},
+		"client_secret": {r.clientSecret},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create refresh request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("refresh github token: %w", err)
+	}
+	defer func() {
+		//nolint:errcheck // nothing useful to do with a close error here
+		resp.Body.Close()
+	}()
+
+	// GitHub reports OAuth errors with a 200 and an error field
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		RefreshToken     string `json:"refresh_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+		ExpiresIn        int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode refresh response (status %d): %w", resp.StatusCode, err)
+	}
+	if body.Error == "bad_refresh_token" || resp.StatusCode == http.StatusUnauthorized {
+		return nil, &AuthError{Message: "refresh token rejected: " + body.ErrorDescription, StatusCode: http.StatusUnauthorized}
+	}
+	if body.Error != "" {
+		return nil, fmt.Errorf("refresh github token: %s: %s", body.Error, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return nil, fmt.Errorf("refresh github token: unexpected status %d", resp.StatusCode)
+	}
+
+	token := &Token{AccessToken: body.AccessToken, RefreshToken: body.RefreshToken}
+	if body.ExpiresIn > 0 {
+		token.Expiry = r.now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
@@ -0,0 +1,101 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthTransport_UnauthorizedIsReauthError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"Bad credentials","documentation_url":"https://docs.github.com/rest"}`))
+	}))
+	defer server.Close()
+
+	client := gogithub.NewClient(&http.Client{Transport: AuthTransport(nil)})
+	baseURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = baseURL
+
+	_, _, err = client.Repositories.Get(context.Background(), "golang", "go")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrReauthRequired))
+	var authErr *AuthError
+	require.True(t, errors.As(err, &authErr))
+	assert.Equal(t, "Bad credentials", authErr.Message)
+}
+
+func TestAuthTransport_PassesOtherResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+	}))
+	defer server.Close()
+
+	client := gogithub.NewClient(&http.Client{Transport: AuthTransport(nil)})
+	baseURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = baseURL
+
+	_, _, err = client.Repositories.Get(context.Background(), "golang", "missing")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrReauthRequired))
+	var ghErr *gogithub.ErrorResponse
+	require.True(t, errors.As(err, &ghErr))
+	assert.Equal(t, http.StatusNotFound, ghErr.Response.StatusCode)
+}
+
+func TestAuthError_IsReauthRequiredOnlyFor401(t *testing.T) {
+	assert.True(t, errors.Is(&AuthError{StatusCode: http.StatusUnauthorized}, ErrReauthRequired))
+	assert.False(t, errors.Is(&AuthError{StatusCode: http.StatusForbidden}, ErrReauthRequired))
+}
+
+func TestTokenRefresher_Refresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "client-id", r.PostForm.Get("client_id"))
+		assert.Equal(t, "client-secret", r.PostForm.Get("client_secret"))
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("refresh_token") != "ghr_valid" {
+			_, _ = w.Write([]byte(`{"error":"bad_refresh_token","error_description":"The refresh token passed is incorrect or expired."}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"ghu_new","expires_in":28800,"refresh_token":"ghr_next","token_type":"bearer"}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 11, 20, 9, 0, 0, 0, time.UTC)
+	refresher := NewTokenRefresher("client-id", "client-secret")
+	refresher.SetTokenURL(server.URL)
+	refresher.now = func() time.Time { return now }
+
+	token, err := refresher.Refresh(context.Background(), "ghr_valid")
+	require.NoError(t, err)
+	assert.Equal(t, "ghu_new", token.AccessToken)
+	assert.Equal(t, "ghr_next", token.RefreshToken)
+	assert.Equal(t, now.Add(8*time.Hour), token.Expiry)
+
+	_, err = refresher.Refresh(context.Background(), "ghr_revoked")
+	assert.True(t, errors.Is(err, ErrReauthRequired))
+}
+
+func TestToken_NeedsRefresh(t *testing.T) {
+	now := time.Now()
+	expiring := &Token{AccessToken: "ghu_a", RefreshToken: "ghr_a", Expiry: now.Add(time.Minute)}
+	assert.True(t, expiring.NeedsRefresh(now, 5*time.Minute))
+	assert.False(t, expiring.NeedsRefresh(now.Add(-time.Hour), 5*time.Minute))
+
+	oauthApp := &Token{AccessToken: "gho_a"}
+	assert.False(t, oauthApp.NeedsRefresh(now, 5*time.Minute), "OAuth App tokens do not expire")
+}
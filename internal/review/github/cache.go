@@ -0,0 +1,182 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached GitHub response is served without
+// asking GitHub. After that it is revalidated with its ETag.
+const DefaultCacheTTL = 60 * time.Second
+
+// defaultCacheEntries bounds the number of responses kept in memory.
+const defaultCacheEntries = 500
+
+// Rate limit headers GitHub sends with every response.
+var rateLimitHeaders = []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-RateLimit-Used"}
+
+// ResponseCache caches successful GitHub API GET responses in memory, keyed
+// by the caller's credentials and the request URL (which names the repo, ref
+// and path). Fresh entries are served without a request. Stale entries are
+// revalidated with If-None-Match; GitHub answers 304 when nothing changed,
+// which does not count against the rate limit, and the cached body is served.
+type ResponseCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+type cachedResponse struct {
+	header   http.Header
+	body     []byte
+	etag     string
+	storedAt time.Time
+}
+
+// NewResponseCache creates a cache whose entries are fresh for ttl.
+// A ttl of zero or less uses DefaultCacheTTL.
+func NewResponseCache(ttl time.Duration) *ResponseCache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &ResponseCache{
+		ttl:        ttl,
+		maxEntries: defaultCacheEntries,
+		now:        time.Now,
+		entries:    make(map[string]*cachedResponse),
+	}
+}
+
+// Transport returns a RoundTripper that serves requests from the cache and
+// sends the rest through base (http.DefaultTransport if nil). It must sit
+// below the transport that adds credentials so that users never share
+// cached responses.
+func (rc *ResponseCache) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &cachingTransport{cache: rc, base: base}
+}
+
+type cachingTransport struct {
+	cache *ResponseCache
+	base  http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry, fresh := t.cache.lookup(key)
+	if fresh {
+		return entry.response(req), nil
+	}
+
+	if entry != nil && entry.etag != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		resp.Body.Close()
+		return t.cache.revalidated(key, entry, resp.Header).response(req), nil
+
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		t.cache.store(key, &cachedResponse{
+			header: resp.Header.Clone(),
+			body:   body,
+			etag:   resp.Header.Get("ETag"),
+		})
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	}
+
+	return resp, nil
+}
+
+// cacheKey combines a hash of the request's credentials with its URL.
+func cacheKey(req *http.Request) string {
+	auth := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return hex.EncodeToString(auth[:8]) + " " + req.URL.String()
+}
+
+// lookup returns the entry for key, if any, and whether it is still fresh.
+func (rc *ResponseCache) lookup(key string) (*cachedResponse, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	return entry, rc.now().Sub(entry.storedAt) < rc.ttl
+}
+
+// store saves entry under key, evicting the oldest entry when full.
+func (rc *ResponseCache) store(key string, entry *cachedResponse) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry.storedAt = rc.now()
+	if _, exists := rc.entries[key]; !exists && len(rc.entries) >= rc.maxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for k, e := range rc.entries {
+			if oldestKey == "" || e.storedAt.Before(oldest) {
+				oldestKey, oldest = k, e.storedAt
+			}
+		}
+		delete(rc.entries, oldestKey)
+	}
+	rc.entries[key] = entry
+}
+
+// revalidated marks entry fresh again after a 304 and takes the current
+// rate limit headers from it.
+func (rc *ResponseCache) revalidated(key string, entry *cachedResponse, header http.Header) *cachedResponse {
+	updated := &cachedResponse{header: entry.header.Clone(), body: entry.body, etag: entry.etag}
+	for _, name := range rateLimitHeaders {
+		if v := header.Get(name); v != "" {
+			updated.header.Set(name, v)
+		}
+	}
+	rc.store(key, updated)
+	return updated
+}
+
+// response builds a 200 response for req from the cached entry.
+func (e *cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,176 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v57/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockGitHub serves one file's contents with an ETag, answering 304 to
+// matching If-None-Match requests like the real API.
+type mockGitHub struct {
+	mu          sync.Mutex
+	requests    int
+	conditional int
+	etag        string
+	content     string
+	remaining   int
+}
+
+func (m *mockGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests++
+	w.Header().Set("X-RateLimit-Limit", "60")
+	w.Header().Set("X-RateLimit-Reset", "1763035200")
+	if r.Header.Get("If-None-Match") != "" {
+		m.conditional++
+		if r.Header.Get("If-None-Match") == m.etag {
+			// Conditional hits do not use up the rate limit
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(m.remaining))
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	m.remaining--
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(m.remaining))
+	w.Header().Set("ETag", m.etag)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"type":"file","encoding":"base64","path":"main.go","sha":"abc123","content":"` + m.content + `"}`))
+}
+
+func newCachedGitHubClient(t *testing.T, server *httptest.Server, cache *ResponseCache, token string) *gogithub.Client {
+	t.Helper()
+	client := gogithub.NewClient(&http.Client{Transport: &authTransport{token: token, base: cache.Transport(nil)}})
+	baseURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = baseURL
+	return client
+}
+
+// authTransport adds a bearer token like oauth2.Transport does.
+type authTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (a *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return a.base.RoundTrip(req)
+}
+
+func getMainGo(t *testing.T, client *gogithub.Client) (string, *gogithub.Response) {
+	t.Helper()
+	file, _, resp, err := client.Repositories.GetContents(context.Background(), "acme", "payments", "main.go",
+		&gogithub.RepositoryContentGetOptions{Ref: "main"})
+	require.NoError(t, err)
+	content, err := file.GetContent()
+	require.NoError(t, err)
+	return content, resp
+}
+
+func newTestCache(ttl time.Duration) (*ResponseCache, *time.Time) {
+	clock := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+	cache := NewResponseCache(ttl)
+	cache.now = func() time.Time { return clock }
+	return cache, &clock
+}
+
+func TestResponseCache_FreshEntryServedWithoutRequest(t *testing.T) {
+	mock := &mockGitHub{etag: `"v1"`, content: "cGFja2FnZSBtYWlu", remaining: 60} // "package main"
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	cache, _ := newTestCache(time.Minute)
+	client := newCachedGitHubClient(t, server, cache, "token-a")
+
+	for i := 0; i < 3; i++ {
+		content, _ := getMainGo(t, client)
+		assert.Equal(t, "package main", content)
+	}
+	assert.Equal(t, 1, mock.requests)
+}
+
+func TestResponseCache_NotModifiedServesCachedBody(t *testing.T) {
+	mock := &mockGitHub{etag: `"v1"`, content: "cGFja2FnZSBtYWlu", remaining: 60}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	cache, clock := newTestCache(time.Minute)
+	client := newCachedGitHubClient(t, server, cache, "token-a")
+
+	_, first := getMainGo(t, client)
+	assert.Equal(t, 59, first.Rate.Remaining)
+
+	// Once stale the entry is revalidated; GitHub says nothing changed
+	*clock = clock.Add(2 * time.Minute)
+	mock.content = "" // a full response now would have no content
+	content, resp := getMainGo(t, client)
+
+	assert.Equal(t, "package main", content, "cached body is used on 304")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, mock.requests)
+	assert.Equal(t, 1, mock.conditional)
+	assert.Equal(t, 59, resp.Rate.Remaining, "rate limit comes from the 304")
+	assert.Equal(t, 60, resp.Rate.Limit)
+
+	// The revalidated entry is fresh again
+	_, _ = getMainGo(t, client)
+	assert.Equal(t, 2, mock.requests)
+}
+
+func TestResponseCache_ChangedContentReplacesEntry(t *testing.T) {
+	mock := &mockGitHub{etag: `"v1"`, content: "cGFja2FnZSBtYWlu", remaining: 60}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	cache, clock := newTestCache(time.Minute)
+	client := newCachedGitHubClient(t, server, cache, "token-a")
+	_, _ = getMainGo(t, client)
+
+	*clock = clock.Add(2 * time.Minute)
+	mock.etag, mock.content = `"v2"`, "cGFja2FnZSBhcGk=" // "package api"
+	content, _ := getMainGo(t, client)
+
+	assert.Equal(t, "package api", content)
+	assert.Equal(t, 1, mock.conditional)
+}
+
+func TestResponseCache_KeyedPerToken(t *testing.T) {
+	mock := &mockGitHub{etag: `"v1"`, content: "cGFja2FnZSBtYWlu", remaining: 60}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	cache, _ := newTestCache(time.Minute)
+	_, _ = getMainGo(t, newCachedGitHubClient(t, server, cache, "token-a"))
+	_, _ = getMainGo(t, newCachedGitHubClient(t, server, cache, "token-b"))
+
+	assert.Equal(t, 2, mock.requests, "users never share cached responses")
+}
+
+func TestResponseCache_EvictsOldest(t *testing.T) {
+	cache, clock := newTestCache(time.Minute)
+	cache.maxEntries = 2
+
+	cache.store("a", &cachedResponse{body: []byte("a")})
+	*clock = clock.Add(time.Second)
+	cache.store("b", &cachedResponse{body: []byte("b")})
+	*clock = clock.Add(time.Second)
+	cache.store("c", &cachedResponse{body: []byte("c")})
+
+	_, ok := cache.entries["a"]
+	assert.False(t, ok)
+	assert.Len(t, cache.entries, 2)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	review_circuit "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/circuit"
+)
+
+// CircuitStatusProvider reports the state of an AI circuit breaker
+type CircuitStatusProvider interface {
+	Status() review_circuit.BreakerStatus
+}
+
+// CircuitHandler exposes the AI circuit breaker to operators
+type CircuitHandler struct {
+	breaker CircuitStatusProvider
+}
+
+// NewCircuitHandler creates a new CircuitHandler
+func NewCircuitHandler(breaker CircuitStatusProvider) *CircuitHandler {
+	return &CircuitHandler{breaker: breaker}
+}
+
+// GetCircuit returns the breaker's state, failure counts and last trip time
+// GET /api/review/circuit
+func (h *CircuitHandler) GetCircuit(c *gin.Context) {
+	c.JSON(http.StatusOK, h.breaker.Status())
+}
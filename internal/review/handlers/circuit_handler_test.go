@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/config"
+	review_circuit "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/circuit"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unreachableAI struct{}
+
+func (unreachableAI) Generate(ctx context.Context, prompt string) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func TestCircuitHandler_ReportsOpenBreaker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log, err := logger.NewLogger(&logger.Config{ServiceName: "review-test", LogLevel: "error"})
+	require.NoError(t, err)
+	breaker := review_circuit.NewOllamaCircuitBreaker(unreachableAI{}, log, config.DefaultCircuitBreaker)
+
+	router := gin.New()
+	router.GET("/api/review/circuit", NewCircuitHandler(breaker).GetCircuit)
+
+	get := func() review_circuit.BreakerStatus {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/review/circuit", http.NoBody))
+		require.Equal(t, http.StatusOK, w.Code)
+		var status review_circuit.BreakerStatus
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+		return status
+	}
+
+	assert.Equal(t, gobreaker.StateClosed.String(), get().State)
+
+	for i := 0; i < 5; i++ {
+		_, err := breaker.Generate(context.Background(), "prompt")
+		require.Error(t, err)
+	}
+
+	status := get()
+	assert.Equal(t, gobreaker.StateOpen.String(), status.State)
+	assert.Equal(t, uint64(1), status.Trips)
+	assert.NotNil(t, status.LastTrippedAt)
+}
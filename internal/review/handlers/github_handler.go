@@ -2,32 +2,78 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/go-github/v57/github"
+	reviewgithub "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/github"
 	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
 	"golang.org/x/oauth2"
 )
 
+// tokenRefreshMargin is how close to expiry a GitHub token is refreshed
+const tokenRefreshMargin = 5 * time.Minute
+
+// reauthURL is where users sign in with GitHub again
+const reauthURL = "/auth/github/login"
+
+// GitHubTokenStore persists a session after its GitHub token is refreshed
+// or found to be invalid
+type GitHubTokenStore interface {
+	Update(ctx context.Context, sess *session.Session) error
+}
+
 // GitHubHandler handles GitHub repository integration endpoints
 type GitHubHandler struct {
 	logger         *logger.Logger
 	previewService review_services.PreviewAnalyzer
+	cache          *reviewgithub.ResponseCache
+	tokens         GitHubTokenStore
+	refresher      *reviewgithub.TokenRefresher
+	baseURL        *url.URL
 }
 
-// NewGitHubHandler creates a new GitHub handler
+// NewGitHubHandler creates a new GitHub handler. GitHub responses are cached
+// for reviewgithub.DefaultCacheTTL and then revalidated with their ETags.
 func NewGitHubHandler(logger *logger.Logger, previewService review_services.PreviewAnalyzer) *GitHubHandler {
 	return &GitHubHandler{
 		logger:         logger,
 		previewService: previewService,
+		cache:          reviewgithub.NewResponseCache(reviewgithub.DefaultCacheTTL),
 	}
 }
 
+// SetCache replaces the GitHub response cache, e.g. to change its TTL.
+func (h *GitHubHandler) SetCache(cache *reviewgithub.ResponseCache) {
+	h.cache = cache
+}
+
+// SetTokenStore enables invalidating a session's GitHub token when GitHub
+// rejects it, and saving refreshed tokens
+func (h *GitHubHandler) SetTokenStore(store GitHubTokenStore) {
+	h.tokens = store
+}
+
+// SetTokenRefresher enables refreshing expiring GitHub tokens. Only tokens
+// issued with a refresh token (GitHub Apps) are refreshed.
+func (h *GitHubHandler) SetTokenRefresher(refresher *reviewgithub.TokenRefresher) {
+	h.refresher = refresher
+}
+
+// SetBaseURL points the handler at another GitHub API, e.g. GitHub
+// Enterprise. The URL must end with a slash.
+func (h *GitHubHandler) SetBaseURL(baseURL *url.URL) {
+	h.baseURL = baseURL
+}
+
 // TreeNode represents a node in the file tree
 type TreeNode struct {
 	Name     string      `json:"name"`
@@ -85,20 +131,23 @@ func (h *GitHubHandler) GetRepoTree(c *gin.Context) {
 	}
 
 	// Get GitHub token from session
-	token, exists := c.Get("github_token")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "GitHub authentication required"})
+	token, ok := h.githubToken(c)
+	if !ok {
 		return
 	}
 
 	// Create GitHub client
-	client := createGitHubClient(c.Request.Context(), token.(string))
+	client := h.createGitHubClient(token)
 
 	// If no branch specified, get default branch
 	if branch == "" {
 		repository, _, err := client.Repositories.Get(c.Request.Context(), owner, repo)
 		if err != nil {
 			h.logger.Error("Failed to get repository", "error", err)
+			if errors.Is(err, reviewgithub.ErrReauthRequired) {
+				h.requireReauth(c)
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch repository"})
 			return
 		}
@@ -106,10 +155,11 @@ func (h *GitHubHandler) GetRepoTree(c *gin.Context) {
 	}
 
 	// Get repository tree
-	tree, _, err := client.Git.GetTree(c.Request.Context(), owner, repo, branch, true)
+	tree, resp, err := client.Git.GetTree(c.Request.Context(), owner, repo, branch, true)
+	setRateLimitHeaders(c, resp)
 	if err != nil {
 		h.logger.Error("Failed to get repository tree", "error", err)
-		handleGitHubError(c, err)
+		h.handleGitHubError(c, err)
 		return
 	}
 
@@ -157,14 +207,13 @@ func (h *GitHubHandler) GetRepoFile(c *gin.Context) {
 	}
 
 	// Get GitHub token from session
-	token, exists := c.Get("github_token")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "GitHub authentication required"})
+	token, ok := h.githubToken(c)
+	if !ok {
 		return
 	}
 
 	// Create GitHub client
-	client := createGitHubClient(c.Request.Context(), token.(string))
+	client := h.createGitHubClient(token)
 
 	// Get file content
 	opts := &github.RepositoryContentGetOptions{}
@@ -172,10 +221,11 @@ func (h *GitHubHandler) GetRepoFile(c *gin.Context) {
 		opts.Ref = branch
 	}
 
-	fileContent, _, _, err := client.Repositories.GetContents(c.Request.Context(), owner, repo, path, opts)
+	fileContent, _, resp, err := client.Repositories.GetContents(c.Request.Context(), owner, repo, path, opts)
+	setRateLimitHeaders(c, resp)
 	if err != nil {
 		h.logger.Error("Failed to get file content", "error", err, "path", path)
-		handleGitHubError(c, err)
+		h.handleGitHubError(c, err)
 		return
 	}
 
@@ -221,20 +271,23 @@ func (h *GitHubHandler) QuickRepoScan(c *gin.Context) {
 	}
 
 	// Get GitHub token from session
-	token, exists := c.Get("github_token")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "GitHub authentication required"})
+	token, ok := h.githubToken(c)
+	if !ok {
 		return
 	}
 
 	// Create GitHub client
-	client := createGitHubClient(c.Request.Context(), token.(string))
+	client := h.createGitHubClient(token)
 
 	// If no branch specified, get default branch
 	if branch == "" {
 		repository, _, err := client.Repositories.Get(c.Request.Context(), owner, repo)
 		if err != nil {
 			h.logger.Error("Failed to get repository", "error", err)
+			if errors.Is(err, reviewgithub.ErrReauthRequired) {
+				h.requireReauth(c)
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch repository"})
 			return
 		}
@@ -242,10 +295,11 @@ func (h *GitHubHandler) QuickRepoScan(c *gin.Context) {
 	}
 
 	// Get repository tree to find actual core files
-	tree, _, err := client.Git.GetTree(c.Request.Context(), owner, repo, branch, true)
+	tree, resp, err := client.Git.GetTree(c.Request.Context(), owner, repo, branch, true)
+	setRateLimitHeaders(c, resp)
 	if err != nil {
 		h.logger.Error("Failed to get repository tree", "error", err)
-		handleGitHubError(c, err)
+		h.handleGitHubError(c, err)
 		return
 	}
 
@@ -312,7 +366,8 @@ func (h *GitHubHandler) QuickRepoScan(c *gin.Context) {
 	opts := &github.RepositoryContentGetOptions{Ref: branch}
 
 	for _, path := range coreFilePaths {
-		fileContent, _, _, err := client.Repositories.GetContents(c.Request.Context(), owner, repo, path, opts)
+		fileContent, _, resp, err := client.Repositories.GetContents(c.Request.Context(), owner, repo, path, opts)
+		setRateLimitHeaders(c, resp)
 		if err != nil {
 			h.logger.Warn("Failed to fetch core file", "error", err, "path", path)
 			continue
@@ -433,12 +488,116 @@ func parseGitHubURL(url string) (string, string, error) {
 	return owner, repo, nil
 }
 
-func createGitHubClient(ctx context.Context, token string) *github.Client {
+// createGitHubClient returns a client for token whose GET requests go through
+// the handler's response cache. The cache sits below the auth transport so
+// entries are keyed per token.
+func (h *GitHubHandler) createGitHubClient(token string) *github.Client {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(ctx, ts)
-	return github.NewClient(tc)
+	var base http.RoundTripper
+	if h.cache != nil {
+		base = h.cache.Transport(nil)
+	}
+	tc := &http.Client{Transport: reviewgithub.AuthTransport(&oauth2.Transport{Source: ts, Base: base})}
+	client := github.NewClient(tc)
+	if h.baseURL != nil {
+		client.BaseURL = h.baseURL
+	}
+	return client
+}
+
+// githubToken returns the session's GitHub token, refreshing it first when
+// it is about to expire. When there is no usable token it writes the
+// response and returns false.
+func (h *GitHubHandler) githubToken(c *gin.Context) (string, bool) {
+	value, exists := c.Get("github_token")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "GitHub authentication required"})
+		return "", false
+	}
+	token, _ := value.(string)
+
+	if sess := sessionFromContext(c); sess != nil && h.refresher != nil {
+		current := &reviewgithub.Token{
+			AccessToken:  sess.GitHubToken,
+			RefreshToken: sess.GitHubRefreshToken,
+			Expiry:       sess.GitHubTokenExpiresAt,
+		}
+		if current.NeedsRefresh(time.Now(), tokenRefreshMargin) {
+			refreshed, err := h.refresher.Refresh(c.Request.Context(), current.RefreshToken)
+			switch {
+			case errors.Is(err, reviewgithub.ErrReauthRequired):
+				h.requireReauth(c)
+				return "", false
+			case err != nil:
+				// The current token may still have a few minutes left
+				h.logger.Warn("Failed to refresh GitHub token", "error", err)
+			default:
+				sess.GitHubToken = refreshed.AccessToken
+				sess.GitHubRefreshToken = refreshed.RefreshToken
+				sess.GitHubTokenExpiresAt = refreshed.Expiry
+				h.saveSession(c, sess)
+				token = refreshed.AccessToken
+				c.Set("github_token", token)
+			}
+		}
+	}
+
+	// An empty token was invalidated after GitHub rejected it
+	if token == "" {
+		h.requireReauth(c)
+		return "", false
+	}
+	return token, true
+}
+
+// requireReauth clears the session's GitHub token, so later requests fail
+// fast, and tells the client to sign in with GitHub again
+func (h *GitHubHandler) requireReauth(c *gin.Context) {
+	if sess := sessionFromContext(c); sess != nil && sess.GitHubToken != "" {
+		sess.GitHubToken = ""
+		sess.GitHubRefreshToken = ""
+		sess.GitHubTokenExpiresAt = time.Time{}
+		h.saveSession(c, sess)
+	}
+	c.Set("github_token", "")
+	c.JSON(http.StatusUnauthorized, gin.H{
+		"error":      "GitHub authorization has expired. Please sign in with GitHub again.",
+		"code":       "github_reauth_required",
+		"reauth_url": reauthURL,
+	})
+}
+
+func (h *GitHubHandler) saveSession(c *gin.Context, sess *session.Session) {
+	if h.tokens == nil {
+		return
+	}
+	if err := h.tokens.Update(c.Request.Context(), sess); err != nil {
+		h.logger.Warn("Failed to save session GitHub token", "error", err, "session_id", sess.SessionID)
+	}
+}
+
+// sessionFromContext returns the session set by the session auth middleware
+func sessionFromContext(c *gin.Context) *session.Session {
+	value, exists := c.Get("session")
+	if !exists {
+		return nil
+	}
+	sess, _ := value.(*session.Session)
+	return sess
+}
+
+// setRateLimitHeaders passes GitHub's rate limit on to the caller so the UI
+// can warn before it runs out. Cached responses carry the values from their
+// last (re)validation.
+func setRateLimitHeaders(c *gin.Context, resp *github.Response) {
+	if resp == nil || resp.Rate.Limit == 0 {
+		return
+	}
+	c.Header("X-GitHub-RateLimit-Limit", strconv.Itoa(resp.Rate.Limit))
+	c.Header("X-GitHub-RateLimit-Remaining", strconv.Itoa(resp.Rate.Remaining))
+	c.Header("X-GitHub-RateLimit-Reset", strconv.FormatInt(resp.Rate.Reset.Unix(), 10))
 }
 
 func buildTreeStructure(entries []*github.TreeEntry) []*TreeNode {
@@ -578,7 +737,11 @@ func getFileExtension(path string) string {
 	return ""
 }
 
-func handleGitHubError(c *gin.Context, err error) {
+func (h *GitHubHandler) handleGitHubError(c *gin.Context, err error) {
+	if errors.Is(err, reviewgithub.ErrReauthRequired) {
+		h.requireReauth(c)
+		return
+	}
 	if githubErr, ok := err.(*github.ErrorResponse); ok {
 		switch githubErr.Response.StatusCode {
 		case http.StatusNotFound:
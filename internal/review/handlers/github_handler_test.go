@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	reviewgithub "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/github"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/session"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingTokenStore struct {
+	updates []session.Session
+}
+
+func (s *recordingTokenStore) Update(_ context.Context, sess *session.Session) error {
+	s.updates = append(s.updates, *sess)
+	return nil
+}
+
+// newMockGitHub serves the tree of golang/go for validToken and 401s
+// everything else like GitHub does for an expired or revoked token
+func newMockGitHub(t *testing.T, validToken string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer "+validToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"sha":"abc","tree":[{"path":"main.go","type":"blob","size":10}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestGitHubHandler(t *testing.T, server *httptest.Server, store GitHubTokenStore) *GitHubHandler {
+	t.Helper()
+	log, err := logger.NewLogger(&logger.Config{ServiceName: "review-test"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = log.Close() })
+
+	h := NewGitHubHandler(log, nil)
+	h.SetCache(nil)
+	h.SetTokenStore(store)
+	baseURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	h.SetBaseURL(baseURL)
+	return h
+}
+
+func serveRepoTree(h *GitHubHandler, sess *session.Session) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.GET("/tree", func(c *gin.Context) {
+		c.Set("github_token", sess.GitHubToken)
+		c.Set("session", sess)
+		h.GetRepoTree(c)
+	})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/tree?url=github.com/golang/go&branch=master", http.NoBody)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetRepoTree_ExpiredTokenRequiresReauth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &recordingTokenStore{}
+	h := newTestGitHubHandler(t, newMockGitHub(t, "gho_valid"), store)
+	sess := &session.Session{SessionID: "sess-1", UserID: 7, GitHubToken: "gho_expired"}
+
+	w := serveRepoTree(h, sess)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "github_reauth_required", body["code"])
+	assert.Equal(t, "/auth/github/login", body["reauth_url"])
+
+	require.Len(t, store.updates, 1, "the rejected token is invalidated")
+	assert.Equal(t, "sess-1", store.updates[0].SessionID)
+	assert.Empty(t, store.updates[0].GitHubToken)
+
+	// The invalidated session is turned away without calling GitHub
+	w = serveRepoTree(h, sess)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "github_reauth_required")
+	assert.Len(t, store.updates, 1)
+}
+
+func TestGetRepoTree_RefreshesExpiringToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &recordingTokenStore{}
+	h := newTestGitHubHandler(t, newMockGitHub(t, "ghu_new"), store)
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"ghu_new","expires_in":28800,"refresh_token":"ghr_next"}`))
+	}))
+	defer tokenServer.Close()
+	refresher := reviewgithub.NewTokenRefresher("client-id", "client-secret")
+	refresher.SetTokenURL(tokenServer.URL)
+	h.SetTokenRefresher(refresher)
+
+	sess := &session.Session{
+		SessionID:            "sess-2",
+		GitHubToken:          "ghu_old",
+		GitHubRefreshToken:   "ghr_old",
+		GitHubTokenExpiresAt: time.Now().Add(time.Minute),
+	}
+	w := serveRepoTree(h, sess)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, store.updates, 1)
+	assert.Equal(t, "ghu_new", store.updates[0].GitHubToken)
+	assert.Equal(t, "ghr_next", store.updates[0].GitHubRefreshToken)
+	assert.True(t, store.updates[0].GitHubTokenExpiresAt.After(time.Now().Add(time.Hour)))
+}
+
+func TestGetRepoTree_RejectedRefreshTokenRequiresReauth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := &recordingTokenStore{}
+	h := newTestGitHubHandler(t, newMockGitHub(t, "ghu_new"), store)
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"error":"bad_refresh_token","error_description":"The refresh token passed is incorrect or expired."}`))
+	}))
+	defer tokenServer.Close()
+	refresher := reviewgithub.NewTokenRefresher("client-id", "client-secret")
+	refresher.SetTokenURL(tokenServer.URL)
+	h.SetTokenRefresher(refresher)
+
+	sess := &session.Session{
+		SessionID:            "sess-3",
+		GitHubToken:          "ghu_old",
+		GitHubRefreshToken:   "ghr_old",
+		GitHubTokenExpiresAt: time.Now().Add(-time.Minute),
+	}
+	w := serveRepoTree(h, sess)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "github_reauth_required")
+	require.Len(t, store.updates, 1)
+	assert.Empty(t, store.updates[0].GitHubToken)
+	assert.Empty(t, store.updates[0].GitHubRefreshToken)
+}
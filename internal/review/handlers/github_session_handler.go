@@ -427,6 +427,8 @@ func (h *GitHubSessionHandler) AnalyzeMultipleFiles(c *gin.Context) {
 		SharedAbstractions:   result.SharedAbstractions,
 		ArchitecturePatterns: result.ArchitecturePatterns,
 		Recommendations:      result.Recommendations,
+		Issues:               result.Issues,
+		SessionReview:        result.SessionReview,
 	}
 
 	// Create multi-file analysis record
@@ -448,14 +450,15 @@ func (h *GitHubSessionHandler) AnalyzeMultipleFiles(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"analysis_id":   analysis.ID,
-		"file_paths":    req.FilePaths,
-		"reading_mode":  req.ReadingMode,
-		"ai_response":   aiResponse,
-		"duration_ms":   result.DurationMs,
-		"input_tokens":  result.InputTokens,
-		"output_tokens": result.OutputTokens,
-		"created_at":    analysis.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		"analysis_id":    analysis.ID,
+		"file_paths":     req.FilePaths,
+		"reading_mode":   req.ReadingMode,
+		"ai_response":    aiResponse,
+		"session_review": result.SessionReview,
+		"duration_ms":    result.DurationMs,
+		"input_tokens":   result.InputTokens,
+		"output_tokens":  result.OutputTokens,
+		"created_at":     analysis.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
 
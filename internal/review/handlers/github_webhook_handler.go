@@ -0,0 +1,452 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/db"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/review/github"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
+)
+
+const (
+	// DefaultWebhookReviewTimeout bounds one background webhook review.
+	DefaultWebhookReviewTimeout = 10 * time.Minute
+	// DefaultWebhookMaxConcurrentReviews caps how many webhook reviews run
+	// in the background at once.
+	DefaultWebhookMaxConcurrentReviews = 4
+
+	maxWebhookPayloadBytes = 10 << 20
+	maxWebhookFiles        = 20
+	maxWebhookCodeBytes    = 200 << 10
+)
+
+// ErrInvalidWebhookSignature is returned when X-Hub-Signature-256 is missing
+// or does not match the payload.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// WebhookSessionStore creates the synthetic review session a webhook review
+// is stored against.
+type WebhookSessionStore interface {
+	Create(ctx context.Context, review *review_db.Review) (*review_db.Review, error)
+}
+
+// WebhookResultStore saves the Critical mode result of a webhook review.
+type WebhookResultStore interface {
+	Create(ctx context.Context, result *review_models.AnalysisResult) error
+}
+
+// GitHubWebhookConfig configures automatic reviews triggered by GitHub.
+type GitHubWebhookConfig struct {
+	// Secret is the webhook secret configured on the GitHub side. Requests
+	// are rejected while it is empty.
+	Secret string
+	// Token is the GitHub token used to fetch changed files.
+	Token string
+	// Model is the model the Critical mode analysis runs with.
+	Model string
+	// UserID owns the sessions created for webhook reviews.
+	UserID int64
+	// Timeout bounds each background review; zero uses DefaultWebhookReviewTimeout.
+	Timeout time.Duration
+	// MaxConcurrent caps the background reviews in flight; deliveries past
+	// it are refused with 503. Zero uses DefaultWebhookMaxConcurrentReviews.
+	MaxConcurrent int
+}
+
+// GitHubWebhookHandler runs a Critical mode review of the files changed by
+// GitHub push and pull_request events.
+type GitHubWebhookHandler struct {
+	config   GitHubWebhookConfig
+	client   github.ClientInterface
+	analyzer review_services.CriticalAnalyzer
+	sessions WebhookSessionStore
+	results  WebhookResultStore
+	logger   logger.Interface
+	slots    chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewGitHubWebhookHandler creates a webhook handler.
+func NewGitHubWebhookHandler(
+	config GitHubWebhookConfig,
+	client github.ClientInterface,
+	analyzer review_services.CriticalAnalyzer,
+	sessions WebhookSessionStore,
+	results WebhookResultStore,
+	logger logger.Interface,
+) *GitHubWebhookHandler {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultWebhookReviewTimeout
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = DefaultWebhookMaxConcurrentReviews
+	}
+	return &GitHubWebhookHandler{
+		config:   config,
+		client:   client,
+		analyzer: analyzer,
+		sessions: sessions,
+		results:  results,
+		logger:   logger,
+		slots:    make(chan struct{}, config.MaxConcurrent),
+	}
+}
+
+// WebhookEvent is the part of a push or pull_request event needed to review it.
+type WebhookEvent struct {
+	Kind     string   `json:"kind"` // "push" or "pull_request"
+	Owner    string   `json:"owner"`
+	Repo     string   `json:"repo"`
+	Ref      string   `json:"ref"` // branch name
+	SHA      string   `json:"sha"` // commit the files are read at
+	PRNumber int      `json:"pr_number,omitempty"`
+	Title    string   `json:"title"`
+	Files    []string `json:"files,omitempty"` // changed files; fetched from the PR for pull_request
+}
+
+// pushPayload and pullRequestPayload hold the fields read from GitHub's
+// event payloads.
+type pushPayload struct {
+	Ref        string            `json:"ref"`
+	After      string            `json:"after"`
+	Deleted    bool              `json:"deleted"`
+	Repository webhookRepository `json:"repository"`
+	Commits    []struct {
+		Added    []string `json:"added"`
+		Modified []string `json:"modified"`
+		Removed  []string `json:"removed"`
+	} `json:"commits"`
+}
+
+type pullRequestPayload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Title string `json:"title"`
+		Head  struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Repository webhookRepository `json:"repository"`
+}
+
+type webhookRepository struct {
+	Name  string `json:"name"`
+	Owner struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+}
+
+// reviewedPRActions are the pull_request actions that change the code under review.
+var reviewedPRActions = map[string]bool{"opened": true, "reopened": true, "synchronize": true}
+
+// VerifyWebhookSignature checks an X-Hub-Signature-256 header
+// ("sha256=<hex HMAC of the body>") against secret.
+func VerifyWebhookSignature(secret, signature string, body []byte) error {
+	hexSum, ok := strings.CutPrefix(signature, "sha256=")
+	if secret == "" || !ok {
+		return ErrInvalidWebhookSignature
+	}
+	got, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// ParseWebhookEvent extracts what to review from a GitHub event payload.
+// It returns nil for events that need no review: other event types, branch
+// deletions, pushes without changed files and pull_request actions that do
+// not change code.
+func ParseWebhookEvent(eventType string, body []byte) (*WebhookEvent, error) {
+	switch eventType {
+	case "push":
+		var p pushPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, fmt.Errorf("invalid push payload: %w", err)
+		}
+		if p.Deleted || !strings.HasPrefix(p.Ref, "refs/heads/") {
+			return nil, nil
+		}
+		files := pushedFiles(p)
+		if len(files) == 0 {
+			return nil, nil
+		}
+		branch := strings.TrimPrefix(p.Ref, "refs/heads/")
+		return &WebhookEvent{
+			Kind:  "push",
+			Owner: p.Repository.Owner.Login,
+			Repo:  p.Repository.Name,
+			Ref:   branch,
+			SHA:   p.After,
+			Title: fmt.Sprintf("Push to %s (%s)", branch, shortSHA(p.After)),
+			Files: files,
+		}, nil
+
+	case "pull_request":
+		var p pullRequestPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, fmt.Errorf("invalid pull_request payload: %w", err)
+		}
+		if !reviewedPRActions[p.Action] || p.Number <= 0 {
+			return nil, nil
+		}
+		return &WebhookEvent{
+			Kind:     "pull_request",
+			Owner:    p.Repository.Owner.Login,
+			Repo:     p.Repository.Name,
+			Ref:      p.PullRequest.Head.Ref,
+			SHA:      p.PullRequest.Head.SHA,
+			PRNumber: p.Number,
+			Title:    fmt.Sprintf("PR #%d: %s", p.Number, p.PullRequest.Title),
+		}, nil
+	}
+	return nil, nil
+}
+
+// pushedFiles lists files added or modified by the pushed commits, in order,
+// leaving out files a later commit removed.
+func pushedFiles(p pushPayload) []string {
+	var files []string
+	present := make(map[string]bool)
+	for _, commit := range p.Commits {
+		for _, list := range [][]string{commit.Added, commit.Modified} {
+			for _, file := range list {
+				if _, seen := present[file]; !seen {
+					files = append(files, file)
+				}
+				present[file] = true
+			}
+		}
+		for _, file := range commit.Removed {
+			if _, seen := present[file]; seen {
+				present[file] = false
+			}
+		}
+	}
+
+	kept := files[:0]
+	for _, file := range files {
+		if present[file] {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// HandleWebhook handles POST /api/review/webhooks/github.
+// It verifies the payload signature, answers 202 right away and reviews the
+// changed files in the background with its own timeout, so GitHub's delivery
+// does not wait on the model. At most config.MaxConcurrent reviews run at
+// once; further deliveries get 503 so GitHub records them for redelivery.
+func (h *GitHubWebhookHandler) HandleWebhook(c *gin.Context) {
+	if h.config.Secret == "" {
+		h.logger.Warn("GitHub webhook received but no secret is configured")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "GitHub webhook is not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookPayloadBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read payload"})
+		return
+	}
+	if len(body) > maxWebhookPayloadBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Payload too large"})
+		return
+	}
+
+	if err := VerifyWebhookSignature(h.config.Secret, c.GetHeader("X-Hub-Signature-256"), body); err != nil {
+		h.logger.Warn("Rejected GitHub webhook with invalid signature", "delivery", c.GetHeader("X-GitHub-Delivery"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	eventType := c.GetHeader("X-GitHub-Event")
+	if eventType == "ping" {
+		c.JSON(http.StatusOK, gin.H{"status": "pong"})
+		return
+	}
+
+	event, err := ParseWebhookEvent(eventType, body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusAccepted, gin.H{"status": "ignored", "event": eventType})
+		return
+	}
+
+	delivery := c.GetHeader("X-GitHub-Delivery")
+	select {
+	case h.slots <- struct{}{}:
+	default:
+		h.logger.Warn("GitHub webhook review refused, too many reviews in progress",
+			"delivery", delivery, "limit", cap(h.slots))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many reviews in progress, redeliver later"})
+		return
+	}
+	h.logger.Info("GitHub webhook review queued",
+		"delivery", delivery, "event", event.Kind, "repo", event.Owner+"/"+event.Repo, "sha", event.SHA)
+
+	h.wg.Add(1)
+	go func() {
+		defer func() {
+			<-h.slots
+			h.wg.Done()
+		}()
+		h.review(event, delivery)
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "event": event.Kind})
+}
+
+// Wait blocks until all background reviews have finished.
+func (h *GitHubWebhookHandler) Wait() {
+	h.wg.Wait()
+}
+
+// review fetches the changed files of event, runs Critical mode over them
+// and stores the result against a new session.
+func (h *GitHubWebhookHandler) review(event *WebhookEvent, delivery string) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.config.Timeout)
+	defer cancel()
+	if h.config.Model != "" {
+		ctx = context.WithValue(ctx, reviewcontext.ModelContextKey, h.config.Model)
+	}
+	repo := event.Owner + "/" + event.Repo
+
+	files, err := h.changedFiles(ctx, event)
+	if err != nil {
+		h.logger.Error("Failed to list webhook changed files", "error", err, "repo", repo, "delivery", delivery)
+		return
+	}
+	code, reviewed := h.fetchCode(ctx, event, files)
+	if len(reviewed) == 0 {
+		h.logger.Warn("No changed files could be fetched for webhook review", "repo", repo, "delivery", delivery)
+		return
+	}
+
+	session, err := h.sessions.Create(ctx, &review_db.Review{
+		UserID:       h.config.UserID,
+		Title:        repo + " " + event.Title,
+		CodeSource:   "github",
+		GithubRepo:   repo,
+		GithubBranch: event.Ref,
+		PastedCode:   code,
+	})
+	if err != nil {
+		h.logger.Error("Failed to create webhook review session", "error", err, "repo", repo, "delivery", delivery)
+		return
+	}
+
+	metadata, _ := json.Marshal(map[string]interface{}{
+		"source":    "github_webhook",
+		"delivery":  delivery,
+		"event":     event.Kind,
+		"sha":       event.SHA,
+		"pr_number": event.PRNumber,
+		"files":     reviewed,
+	})
+	stored := &review_models.AnalysisResult{
+		ReviewID:  session.ID,
+		Mode:      review_models.CriticalMode,
+		Metadata:  string(metadata),
+		ModelUsed: h.config.Model,
+	}
+
+	result, analysisErr := h.analyzer.AnalyzeCritical(ctx, code)
+	if analysisErr != nil {
+		h.logger.Error("Webhook review failed", "error", analysisErr, "repo", repo, "session_id", session.ID, "delivery", delivery)
+		stored.Summary = "Automatic review failed: " + analysisErr.Error()
+	} else {
+		output, _ := json.Marshal(result)
+		stored.Summary = result.Summary
+		stored.Output = string(output)
+	}
+
+	if err := h.results.Create(ctx, stored); err != nil {
+		h.logger.Error("Failed to store webhook review", "error", err, "repo", repo, "session_id", session.ID)
+		return
+	}
+	h.logger.Info("GitHub webhook review completed",
+		"repo", repo, "session_id", session.ID, "files", len(reviewed), "failed", analysisErr != nil, "delivery", delivery)
+}
+
+// changedFiles returns the files to review: those listed in a push, or the
+// non-removed files of a pull request.
+func (h *GitHubWebhookHandler) changedFiles(ctx context.Context, event *WebhookEvent) ([]string, error) {
+	if event.Kind != "pull_request" {
+		return event.Files, nil
+	}
+	prFiles, err := h.client.GetPRFiles(ctx, event.Owner, event.Repo, event.PRNumber, h.config.Token)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range prFiles {
+		if f.Status != "removed" {
+			files = append(files, f.Filename)
+		}
+	}
+	return files, nil
+}
+
+// fetchCode reads the changed files at the event's commit and combines them
+// into one document for analysis, within the file and size limits. It
+// returns the document and the files included.
+func (h *GitHubWebhookHandler) fetchCode(ctx context.Context, event *WebhookEvent, files []string) (string, []string) {
+	ref := event.SHA
+	if ref == "" {
+		ref = event.Ref
+	}
+
+	var code strings.Builder
+	var reviewed []string
+	for _, path := range files {
+		if len(reviewed) == maxWebhookFiles {
+			break
+		}
+		file, err := h.client.GetFileContent(ctx, event.Owner, event.Repo, path, ref, h.config.Token)
+		if err != nil {
+			h.logger.Warn("Failed to fetch changed file for webhook review", "error", err, "path", path)
+			continue
+		}
+		if code.Len()+len(file.Content) > maxWebhookCodeBytes {
+			h.logger.Warn("Skipping changed file over webhook review size limit", "path", path, "size", len(file.Content))
+			continue
+		}
+		fmt.Fprintf(&code, "## File: %s\n```%s\n%s\n```\n\n", path, detectLanguageFromPath(path), file.Content)
+		reviewed = append(reviewed, path)
+	}
+	return code.String(), reviewed
+}
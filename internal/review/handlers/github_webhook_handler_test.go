@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	review_db "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/db"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/review/github"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWebhookSecret = "s3cret"
+
+const pushEventPayload = `{
+	"ref": "refs/heads/main",
+	"after": "9f2c1e4b7a0d",
+	"repository": {"name": "payments", "owner": {"login": "acme"}},
+	"commits": [
+		{"added": ["api/handler.go"], "modified": ["README.md"], "removed": []},
+		{"added": ["tmp/debug.go"], "modified": ["api/handler.go", "api/client.go"], "removed": []},
+		{"added": [], "modified": [], "removed": ["tmp/debug.go"]}
+	]
+}`
+
+const pullRequestEventPayload = `{
+	"action": "synchronize",
+	"number": 42,
+	"pull_request": {"title": "Add refunds", "head": {"ref": "feature/refunds", "sha": "c0ffee1234567"}},
+	"repository": {"name": "payments", "owner": {"login": "acme"}}
+}`
+
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// fakeGitHubClient serves file contents and PR files from memory.
+type fakeGitHubClient struct {
+	github.ClientInterface
+	files   map[string]string
+	prFiles []github.PRFile
+
+	mu        sync.Mutex
+	fetchedAt []string
+}
+
+func (f *fakeGitHubClient) GetFileContent(_ context.Context, owner, repo, path, branch, _ string) (*github.FileContent, error) {
+	f.mu.Lock()
+	f.fetchedAt = append(f.fetchedAt, owner+"/"+repo+"@"+branch+":"+path)
+	f.mu.Unlock()
+	content, ok := f.files[path]
+	if !ok {
+		return nil, &github.NotFoundError{Owner: owner, Repo: repo}
+	}
+	return &github.FileContent{Path: path, Content: content}, nil
+}
+
+func (f *fakeGitHubClient) GetPRFiles(_ context.Context, _, _ string, _ int, _ string) ([]github.PRFile, error) {
+	return f.prFiles, nil
+}
+
+type fakeCriticalAnalyzer struct {
+	err  error
+	code string
+}
+
+func (f *fakeCriticalAnalyzer) AnalyzeCritical(_ context.Context, code string) (*review_models.CriticalModeOutput, error) {
+	f.code = code
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &review_models.CriticalModeOutput{
+		OverallGrade: "B",
+		Summary:      "One unchecked error",
+		Issues:       []review_models.CodeIssue{{Severity: "high", Description: "Error ignored"}},
+	}, nil
+}
+
+func (f *fakeCriticalAnalyzer) AnalyzeCriticalDiff(ctx context.Context, diff string) (*review_models.CriticalModeOutput, error) {
+	return f.AnalyzeCritical(ctx, diff)
+}
+
+type fakeWebhookStore struct {
+	sessions []*review_db.Review
+	results  []*review_models.AnalysisResult
+}
+
+func (s *fakeWebhookStore) Create(_ context.Context, review *review_db.Review) (*review_db.Review, error) {
+	review.ID = int64(100 + len(s.sessions))
+	s.sessions = append(s.sessions, review)
+	return review, nil
+}
+
+type fakeResultStore struct{ store *fakeWebhookStore }
+
+func (s fakeResultStore) Create(_ context.Context, result *review_models.AnalysisResult) error {
+	s.store.results = append(s.store.results, result)
+	return nil
+}
+
+func setupWebhook(t *testing.T, client *fakeGitHubClient, analyzer *fakeCriticalAnalyzer) (*GitHubWebhookHandler, *fakeWebhookStore, *gin.Engine) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := &fakeWebhookStore{}
+	handler := NewGitHubWebhookHandler(
+		GitHubWebhookConfig{Secret: testWebhookSecret, Model: "mistral:7b-instruct", UserID: 7, Timeout: 5 * time.Second},
+		client, analyzer, store, fakeResultStore{store}, &testutils.MockLogger{},
+	)
+	router := gin.New()
+	router.POST("/api/review/webhooks/github", handler.HandleWebhook)
+	return handler, store, router
+}
+
+func postWebhook(router *gin.Engine, event, signature string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/review/webhooks/github", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-GitHub-Delivery", "delivery-1")
+	if signature != "" {
+		req.Header.Set("X-Hub-Signature-256", signature)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"zen":"Keep it logically awesome."}`)
+
+	assert.NoError(t, VerifyWebhookSignature(testWebhookSecret, signWebhook(testWebhookSecret, body), body))
+
+	tests := map[string]string{
+		"wrong secret":   signWebhook("other", body),
+		"tampered body":  signWebhook(testWebhookSecret, append(body, ' ')),
+		"missing":        "",
+		"sha1 signature": "sha1=" + hex.EncodeToString([]byte("0123456789abcdef0123")),
+		"not hex":        "sha256=zzzz",
+	}
+	for name, signature := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, VerifyWebhookSignature(testWebhookSecret, signature, body), ErrInvalidWebhookSignature)
+		})
+	}
+
+	assert.ErrorIs(t, VerifyWebhookSignature("", signWebhook("", body), body), ErrInvalidWebhookSignature,
+		"an unconfigured secret accepts nothing")
+}
+
+func TestParseWebhookEvent_Push(t *testing.T) {
+	event, err := ParseWebhookEvent("push", []byte(pushEventPayload))
+
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	assert.Equal(t, "push", event.Kind)
+	assert.Equal(t, "acme", event.Owner)
+	assert.Equal(t, "payments", event.Repo)
+	assert.Equal(t, "main", event.Ref)
+	assert.Equal(t, "9f2c1e4b7a0d", event.SHA)
+	assert.Equal(t, []string{"api/handler.go", "README.md", "api/client.go"}, event.Files,
+		"changed files in order, without the file removed later")
+}
+
+func TestParseWebhookEvent_PullRequest(t *testing.T) {
+	event, err := ParseWebhookEvent("pull_request", []byte(pullRequestEventPayload))
+
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	assert.Equal(t, "pull_request", event.Kind)
+	assert.Equal(t, 42, event.PRNumber)
+	assert.Equal(t, "feature/refunds", event.Ref)
+	assert.Equal(t, "c0ffee1234567", event.SHA)
+	assert.Equal(t, "PR #42: Add refunds", event.Title)
+}
+
+func TestParseWebhookEvent_Ignored(t *testing.T) {
+	tests := map[string]struct {
+		eventType string
+		body      string
+	}{
+		"closed PR":     {"pull_request", `{"action":"closed","number":42,"repository":{"name":"payments","owner":{"login":"acme"}}}`},
+		"branch delete": {"push", `{"ref":"refs/heads/old","deleted":true,"commits":[]}`},
+		"tag push":      {"push", `{"ref":"refs/tags/v1.0.0","commits":[{"added":["main.go"]}]}`},
+		"no files":      {"push", `{"ref":"refs/heads/main","commits":[{"removed":["main.go"]}]}`},
+		"issues event":  {"issues", `{"action":"opened"}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			event, err := ParseWebhookEvent(tt.eventType, []byte(tt.body))
+			assert.NoError(t, err)
+			assert.Nil(t, event)
+		})
+	}
+
+	_, err := ParseWebhookEvent("push", []byte(`{not json`))
+	assert.Error(t, err)
+}
+
+func TestHandleWebhook_RejectsInvalidSignature(t *testing.T) {
+	analyzer := &fakeCriticalAnalyzer{}
+	handler, store, router := setupWebhook(t, &fakeGitHubClient{}, analyzer)
+	body := []byte(pushEventPayload)
+
+	w := postWebhook(router, "push", signWebhook("wrong", body), body)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = postWebhook(router, "push", "", body)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	handler.Wait()
+	assert.Empty(t, store.sessions, "no review is started")
+}
+
+func TestHandleWebhook_PushReviewsChangedFiles(t *testing.T) {
+	client := &fakeGitHubClient{files: map[string]string{
+		"api/handler.go": "package api\n\nfunc Handle() {}",
+		"api/client.go":  "package api\n\ntype Client struct{}",
+		"README.md":      "# Payments",
+	}}
+	analyzer := &fakeCriticalAnalyzer{}
+	handler, store, router := setupWebhook(t, client, analyzer)
+	body := []byte(pushEventPayload)
+
+	w := postWebhook(router, "push", signWebhook(testWebhookSecret, body), body)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	handler.Wait()
+
+	require.Len(t, store.sessions, 1)
+	session := store.sessions[0]
+	assert.Equal(t, int64(7), session.UserID)
+	assert.Equal(t, "github", session.CodeSource)
+	assert.Equal(t, "acme/payments", session.GithubRepo)
+	assert.Equal(t, "main", session.GithubBranch)
+
+	assert.Contains(t, client.fetchedAt, "acme/payments@9f2c1e4b7a0d:api/handler.go", "files are read at the pushed commit")
+	assert.Contains(t, analyzer.code, "## File: api/client.go")
+	assert.Contains(t, analyzer.code, "type Client struct{}")
+
+	require.Len(t, store.results, 1)
+	result := store.results[0]
+	assert.Equal(t, session.ID, result.ReviewID)
+	assert.Equal(t, review_models.CriticalMode, result.Mode)
+	assert.Equal(t, "One unchecked error", result.Summary)
+	assert.Contains(t, result.Output, "Error ignored")
+
+	var metadata map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result.Metadata), &metadata))
+	assert.Equal(t, "github_webhook", metadata["source"])
+	assert.Equal(t, "delivery-1", metadata["delivery"])
+}
+
+func TestHandleWebhook_PullRequestSkipsRemovedFiles(t *testing.T) {
+	client := &fakeGitHubClient{
+		files: map[string]string{"refunds.go": "package payments\n\nfunc Refund() error { return nil }"},
+		prFiles: []github.PRFile{
+			{Filename: "refunds.go", Status: "added"},
+			{Filename: "legacy.go", Status: "removed"},
+		},
+	}
+	analyzer := &fakeCriticalAnalyzer{}
+	handler, store, router := setupWebhook(t, client, analyzer)
+	body := []byte(pullRequestEventPayload)
+
+	w := postWebhook(router, "pull_request", signWebhook(testWebhookSecret, body), body)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	handler.Wait()
+
+	assert.Equal(t, []string{"acme/payments@c0ffee1234567:refunds.go"}, client.fetchedAt)
+	require.Len(t, store.sessions, 1)
+	assert.Contains(t, store.sessions[0].Title, "PR #42")
+	require.Len(t, store.results, 1)
+}
+
+func TestHandleWebhook_StoresFailedAnalysis(t *testing.T) {
+	client := &fakeGitHubClient{files: map[string]string{"api/handler.go": "package api"}}
+	analyzer := &fakeCriticalAnalyzer{err: errors.New("circuit breaker is open")}
+	handler, store, router := setupWebhook(t, client, analyzer)
+	body := []byte(pushEventPayload)
+
+	postWebhook(router, "push", signWebhook(testWebhookSecret, body), body)
+	handler.Wait()
+
+	require.Len(t, store.results, 1)
+	assert.Contains(t, store.results[0].Summary, "circuit breaker is open")
+	assert.Empty(t, store.results[0].Output)
+}
+
+// blockingCriticalAnalyzer holds every analysis until release is closed.
+type blockingCriticalAnalyzer struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingCriticalAnalyzer) AnalyzeCritical(_ context.Context, _ string) (*review_models.CriticalModeOutput, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return &review_models.CriticalModeOutput{OverallGrade: "A"}, nil
+}
+
+func (b *blockingCriticalAnalyzer) AnalyzeCriticalDiff(ctx context.Context, diff string) (*review_models.CriticalModeOutput, error) {
+	return b.AnalyzeCritical(ctx, diff)
+}
+
+func TestHandleWebhook_CapsConcurrentReviews(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	analyzer := &blockingCriticalAnalyzer{started: make(chan struct{}, 2), release: make(chan struct{})}
+	store := &fakeWebhookStore{}
+	handler := NewGitHubWebhookHandler(
+		GitHubWebhookConfig{Secret: testWebhookSecret, Timeout: 5 * time.Second, MaxConcurrent: 1},
+		&fakeGitHubClient{files: map[string]string{"api/handler.go": "package api"}},
+		analyzer, store, fakeResultStore{store}, &testutils.MockLogger{},
+	)
+	router := gin.New()
+	router.POST("/api/review/webhooks/github", handler.HandleWebhook)
+	body := []byte(pushEventPayload)
+
+	w := postWebhook(router, "push", signWebhook(testWebhookSecret, body), body)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	<-analyzer.started
+
+	w = postWebhook(router, "push", signWebhook(testWebhookSecret, body), body)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "a delivery past the cap is refused")
+
+	close(analyzer.release)
+	handler.Wait()
+	require.Len(t, store.results, 1)
+
+	w = postWebhook(router, "push", signWebhook(testWebhookSecret, body), body)
+	assert.Equal(t, http.StatusAccepted, w.Code, "the slot is freed once the review finishes")
+	<-analyzer.started
+	handler.Wait()
+	assert.Len(t, store.results, 2)
+}
+
+func TestHandleWebhook_PingAndIgnoredEvents(t *testing.T) {
+	_, _, router := setupWebhook(t, &fakeGitHubClient{}, &fakeCriticalAnalyzer{})
+
+	ping := []byte(`{"zen":"Design for failure."}`)
+	w := postWebhook(router, "ping", signWebhook(testWebhookSecret, ping), ping)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	closed := []byte(`{"action":"closed","number":1}`)
+	w = postWebhook(router, "pull_request", signWebhook(testWebhookSecret, closed), closed)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), "ignored")
+}
+
+func TestHandleWebhook_UnconfiguredSecret(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewGitHubWebhookHandler(GitHubWebhookConfig{}, &fakeGitHubClient{}, &fakeCriticalAnalyzer{},
+		&fakeWebhookStore{}, fakeResultStore{}, &testutils.MockLogger{})
+	router := gin.New()
+	router.POST("/api/review/webhooks/github", handler.HandleWebhook)
+
+	body := []byte(pushEventPayload)
+	w := postWebhook(router, "push", signWebhook("", body), body)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
 )
 
 // PromptTemplateService defines the interface for prompt template business logic
@@ -16,6 +18,8 @@ type PromptTemplateService interface {
 	FactoryReset(ctx context.Context, userID int, mode, userLevel, outputMode string) error
 	GetExecutionHistory(ctx context.Context, userID int, limit int) ([]*review_models.PromptExecution, error)
 	RateExecution(ctx context.Context, userID int, executionID int64, rating int) error
+	GetVersions(ctx context.Context, userID int, mode, userLevel, outputMode string) ([]*review_models.PromptTemplateVersion, error)
+	RollbackToVersion(ctx context.Context, userID int, versionID int64) (*review_models.PromptTemplate, error)
 }
 
 // PromptHandler handles HTTP requests for prompt management
@@ -221,3 +225,72 @@ func (h *PromptHandler) RateExecution(c *gin.Context) {
 		"message": "Rating updated successfully",
 	})
 }
+
+// GetVersions returns the saved versions of the user's custom prompt
+// GET /api/review/prompts/versions?mode={mode}&user_level={level}&output_mode={output}
+func (h *PromptHandler) GetVersions(c *gin.Context) {
+	// Extract user_id from context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	userID := userIDVal.(int)
+
+	// Extract query parameters
+	mode := c.Query("mode")
+	userLevel := c.Query("user_level")
+	outputMode := c.Query("output_mode")
+
+	// Validate required parameters
+	if mode == "" || userLevel == "" || outputMode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required parameters: mode, user_level, output_mode"})
+		return
+	}
+
+	versions, err := h.service.GetVersions(c.Request.Context(), userID, mode, userLevel, outputMode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve versions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// RollbackPrompt restores an earlier version of the user's custom prompt
+// POST /api/review/prompts/rollback {"version_id": 12}
+func (h *PromptHandler) RollbackPrompt(c *gin.Context) {
+	// Extract user_id from context
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+	userID := userIDVal.(int)
+
+	// Parse request body
+	var req struct {
+		VersionID int64 `json:"version_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	prompt, err := h.service.RollbackToVersion(c.Request.Context(), userID, req.VersionID)
+	switch {
+	case errors.Is(err, review_services.ErrPromptVersionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Prompt version not found"})
+		return
+	case errors.Is(err, review_services.ErrPromptVersionForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot roll back another user's prompt"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to roll back prompt"})
+		return
+	}
+
+	// Return the restored prompt
+	c.JSON(http.StatusOK, prompt)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
 )
 
 // MockPromptTemplateService is a mock for testing
@@ -56,6 +57,22 @@ func (m *MockPromptTemplateService) RateExecution(ctx context.Context, userID in
 	return args.Error(0)
 }
 
+func (m *MockPromptTemplateService) GetVersions(ctx context.Context, userID int, mode, userLevel, outputMode string) ([]*review_models.PromptTemplateVersion, error) {
+	args := m.Called(ctx, userID, mode, userLevel, outputMode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*review_models.PromptTemplateVersion), args.Error(1)
+}
+
+func (m *MockPromptTemplateService) RollbackToVersion(ctx context.Context, userID int, versionID int64) (*review_models.PromptTemplate, error) {
+	args := m.Called(ctx, userID, versionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*review_models.PromptTemplate), args.Error(1)
+}
+
 // setupTestRouter creates a test router with authentication middleware mock
 func setupTestRouter(handler *PromptHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
@@ -76,6 +93,8 @@ func setupTestRouter(handler *PromptHandler) *gin.Engine {
 	router.DELETE("/api/review/prompts", handler.ResetPrompt)
 	router.GET("/api/review/prompts/history", handler.GetHistory)
 	router.POST("/api/review/prompts/:execution_id/rate", handler.RateExecution)
+	router.GET("/api/review/prompts/versions", handler.GetVersions)
+	router.POST("/api/review/prompts/rollback", handler.RollbackPrompt)
 
 	return router
 }
@@ -517,6 +536,116 @@ func TestPromptHandler_RequiresAuthentication(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+// Test: POST /api/review/prompts/rollback - Restores an earlier version
+func TestPromptHandler_RollbackPrompt_Success(t *testing.T) {
+	// GIVEN: Service restores version 12 as the active prompt
+	mockService := new(MockPromptTemplateService)
+	handler := NewPromptHandler(mockService)
+	router := setupTestRouter(handler)
+
+	restored := &review_models.PromptTemplate{
+		ID:         "custom-preview-beginner-quick-1",
+		UserID:     intPtr(1),
+		Mode:       "preview",
+		UserLevel:  "beginner",
+		OutputMode: "quick",
+		PromptText: "Second version with {{code}}",
+		Variables:  []string{"{{code}}"},
+		Version:    4,
+	}
+	mockService.On("RollbackToVersion", mock.Anything, 1, int64(12)).Return(restored, nil)
+
+	// WHEN: User rolls back
+	req := httptest.NewRequest("POST", "/api/review/prompts/rollback", bytes.NewReader([]byte(`{"version_id": 12}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// THEN: Should return 200 with the restored prompt
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response review_models.PromptTemplate
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Second version with {{code}}", response.PromptText)
+	assert.Equal(t, 4, response.Version)
+
+	mockService.AssertExpectations(t)
+}
+
+// Test: POST /api/review/prompts/rollback - Maps service errors to status codes
+func TestPromptHandler_RollbackPrompt_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"not found", review_services.ErrPromptVersionNotFound, http.StatusNotFound},
+		{"other user's version", review_services.ErrPromptVersionForbidden, http.StatusForbidden},
+		{"database error", errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPromptTemplateService)
+			handler := NewPromptHandler(mockService)
+			router := setupTestRouter(handler)
+
+			mockService.On("RollbackToVersion", mock.Anything, 1, int64(7)).Return(nil, tt.err)
+
+			req := httptest.NewRequest("POST", "/api/review/prompts/rollback", bytes.NewReader([]byte(`{"version_id": 7}`)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// Test: POST /api/review/prompts/rollback - Missing version id
+func TestPromptHandler_RollbackPrompt_MissingVersionID(t *testing.T) {
+	mockService := new(MockPromptTemplateService)
+	handler := NewPromptHandler(mockService)
+	router := setupTestRouter(handler)
+
+	req := httptest.NewRequest("POST", "/api/review/prompts/rollback", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "RollbackToVersion")
+}
+
+// Test: GET /api/review/prompts/versions - Lists the user's prompt versions
+func TestPromptHandler_GetVersions_Success(t *testing.T) {
+	mockService := new(MockPromptTemplateService)
+	handler := NewPromptHandler(mockService)
+	router := setupTestRouter(handler)
+
+	versions := []*review_models.PromptTemplateVersion{
+		{ID: 3, UserID: 1, Mode: "preview", PromptText: "Third {{code}}", Version: 3},
+		{ID: 2, UserID: 1, Mode: "preview", PromptText: "Second {{code}}", Version: 2},
+	}
+	mockService.On("GetVersions", mock.Anything, 1, "preview", "beginner", "quick").Return(versions, nil)
+
+	req := httptest.NewRequest("GET", "/api/review/prompts/versions?mode=preview&user_level=beginner&output_mode=quick", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []review_models.PromptTemplateVersion
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response, 2)
+	assert.Equal(t, int64(3), response[0].ID)
+
+	mockService.AssertExpectations(t)
+}
+
 // Helper function to create int pointer
 func intPtr(i int) *int {
 	return &i
@@ -10,6 +10,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/health"
 	review_services "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/services"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
 )
@@ -404,3 +405,17 @@ func (h *ServiceHealthChecker) generateSummary(status HealthStatus, unhealthy in
 		return "Unknown health status"
 	}
 }
+
+// Checks reports each component for the shared health endpoint; see
+// health.Handler.AddCheckSet.
+func (h *ServiceHealthChecker) Checks(ctx context.Context) map[string]health.Result {
+	report, err := h.CheckHealth(ctx)
+	if err != nil {
+		return map[string]health.Result{"review": {Status: health.StatusUnhealthy, Message: err.Error()}}
+	}
+	results := make(map[string]health.Result, len(report.Components))
+	for _, comp := range report.Components {
+		results[comp.Name] = health.Result{Status: health.Status(comp.Status), Message: comp.Message}
+	}
+	return results
+}
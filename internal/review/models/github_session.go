@@ -109,6 +109,7 @@ type AIAnalysisResponse struct {
 	ArchitecturePatterns []ArchitecturePattern `json:"architecture_patterns"`
 	Recommendations      []string              `json:"recommendations"`
 	Issues               []AnalysisIssue       `json:"issues,omitempty"`
+	SessionReview        *SessionReview        `json:"session_review,omitempty"`
 }
 
 // AnalysisIssue represents a specific issue found during analysis.
@@ -119,4 +120,43 @@ type AnalysisIssue struct {
 	Category    string `json:"category"` // "architecture", "security", "performance", etc.
 	Description string `json:"description"`
 	Suggestion  string `json:"suggestion,omitempty"`
+	// RelatedFiles lists other files involved in a cross-file issue, such as
+	// the file defining an interface this one violates.
+	RelatedFiles []string `json:"related_files,omitempty"`
+}
+
+// IssueLocation is one place an aggregated issue occurs. Line is 0 when the
+// issue applies to the file as a whole.
+type IssueLocation struct {
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+}
+
+// AggregatedIssue is an issue reported once per session with every location
+// it was found at. CrossFile is set when the locations span several files.
+type AggregatedIssue struct {
+	Severity    string          `json:"severity"`
+	Category    string          `json:"category"`
+	Description string          `json:"description"`
+	Suggestion  string          `json:"suggestion,omitempty"`
+	Locations   []IssueLocation `json:"locations"`
+	CrossFile   bool            `json:"cross_file"`
+}
+
+// FileIssueBreakdown counts the aggregated issues touching one file.
+type FileIssueBreakdown struct {
+	File       string         `json:"file"`
+	IssueCount int            `json:"issue_count"`
+	BySeverity map[string]int `json:"by_severity"`
+}
+
+// SessionReview is the session-level view of a multi-file analysis: unique
+// issues ranked by severity plus a breakdown per file.
+type SessionReview struct {
+	Summary           string               `json:"summary"`
+	TotalIssues       int                  `json:"total_issues"`
+	CrossFileConcerns int                  `json:"cross_file_concerns"`
+	BySeverity        map[string]int       `json:"by_severity"`
+	Issues            []AggregatedIssue    `json:"issues"`
+	Files             []FileIssueBreakdown `json:"files"`
 }
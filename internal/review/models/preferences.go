@@ -0,0 +1,17 @@
+package review_models
+
+// ReviewPreferences are a user's defaults for new reviews, kept in Portal.
+// Empty fields fall back to the built-in defaults, and values sent with a
+// request always win.
+type ReviewPreferences struct {
+	DefaultMode string `json:"default_mode,omitempty"` // preview, skim, scan, detailed, critical
+	UserMode    string `json:"user_mode,omitempty"`    // beginner, novice, intermediate, expert
+	OutputMode  string `json:"output_mode,omitempty"`  // quick, full
+	Model       string `json:"model,omitempty"`
+}
+
+// UserModes are the experience levels prompts are tailored to
+var UserModes = []string{"beginner", "novice", "intermediate", "expert"}
+
+// OutputModes are the supported levels of output detail
+var OutputModes = []string{"quick", "full"}
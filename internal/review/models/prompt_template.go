@@ -17,6 +17,23 @@ type PromptTemplate struct {
 	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// PromptTemplateVersion is one saved state of a user's custom prompt.
+// RestoredFrom is set when the version was created by rolling back to an
+// earlier one.
+type PromptTemplateVersion struct {
+	ID           int64     `json:"id" db:"id"`
+	TemplateID   string    `json:"template_id" db:"template_id"`
+	UserID       int       `json:"user_id" db:"user_id"`
+	Mode         string    `json:"mode" db:"mode"`
+	UserLevel    string    `json:"user_level" db:"user_level"`
+	OutputMode   string    `json:"output_mode" db:"output_mode"`
+	PromptText   string    `json:"prompt_text" db:"prompt_text"`
+	Variables    []string  `json:"variables" db:"-"`
+	Version      int       `json:"version" db:"version"`
+	RestoredFrom *int64    `json:"restored_from,omitempty" db:"restored_from"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
 // PromptExecution represents a logged execution of a prompt
 type PromptExecution struct {
 	ID             int64     `json:"id" db:"id"`
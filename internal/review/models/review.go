@@ -1,6 +1,8 @@
 // Package review_models contains data structures for review service analysis results and code abstractions.
 package review_models
 
+import "time"
+
 // ====================================================================================
 // MODE OUTPUT STRUCTURES - Five Reading Modes
 // ====================================================================================
@@ -8,14 +10,16 @@ package review_models
 // PreviewModeOutput contains results for Preview Mode analysis.
 // Preview mode provides rapid structural assessment of code.
 type PreviewModeOutput struct {
-	Summary           string     `json:"summary"`
-	FileTree          []FileNode `json:"file_tree"`
-	BoundedContexts   []string   `json:"bounded_contexts"`
-	TechStack         []string   `json:"tech_stack"`
-	ArchitectureStyle string     `json:"architecture_style"`
-	EntryPoints       []string   `json:"entry_points"`
-	ExternalDeps      []string   `json:"external_dependencies"`
-	Stats             CodeStats  `json:"stats"`
+	Summary           string            `json:"summary"`
+	FileTree          []FileNode        `json:"file_tree"`
+	BoundedContexts   []string          `json:"bounded_contexts"`
+	TechStack         []string          `json:"tech_stack"`
+	ArchitectureStyle string            `json:"architecture_style"`
+	EntryPoints       []string          `json:"entry_points"`
+	ExternalDeps      []string          `json:"external_dependencies"`
+	Stats             CodeStats         `json:"stats"`
+	TokenEstimate     *TokenEstimate    `json:"token_estimate,omitempty"`
+	InjectionWarning  *InjectionWarning `json:"injection_warning,omitempty"`
 }
 
 // FileNode represents a file or directory in the code structure
@@ -40,18 +44,22 @@ type CodeStats struct {
 // It includes functions, interfaces, data models, workflows, and a summary.
 type SkimModeOutput struct {
 	// Reordered fields for optimal memory alignment
-	Summary    string
-	Functions  []FunctionSignature `json:"functions"`
-	Interfaces []InterfaceInfo     `json:"interfaces"`
-	DataModels []DataModelInfo     `json:"data_models"`
-	Workflows  []WorkflowInfo      `json:"workflows"`
+	Summary          string
+	Functions        []FunctionSignature `json:"functions"`
+	Interfaces       []InterfaceInfo     `json:"interfaces"`
+	DataModels       []DataModelInfo     `json:"data_models"`
+	Workflows        []WorkflowInfo      `json:"workflows"`
+	TokenEstimate    *TokenEstimate      `json:"token_estimate,omitempty"`
+	InjectionWarning *InjectionWarning   `json:"injection_warning,omitempty"`
 }
 
 // ScanModeOutput contains results for Scan Mode analysis.
 // It includes a summary and a list of code matches.
 type ScanModeOutput struct {
-	Summary string      `json:"summary"`
-	Matches []CodeMatch `json:"matches"`
+	Summary          string            `json:"summary"`
+	Matches          []CodeMatch       `json:"matches"`
+	TokenEstimate    *TokenEstimate    `json:"token_estimate,omitempty"`
+	InjectionWarning *InjectionWarning `json:"injection_warning,omitempty"`
 }
 
 // DetailedModeOutput contains results for Detailed Mode analysis.
@@ -64,6 +72,8 @@ type DetailedModeOutput struct {
 	EdgeCases        []string          `json:"edge_cases"`
 	VariableTracking []VariableState   `json:"variable_tracking"`
 	ControlFlow      []ControlFlowNode `json:"control_flow"`
+	TokenEstimate    *TokenEstimate    `json:"token_estimate,omitempty"`
+	InjectionWarning *InjectionWarning `json:"injection_warning,omitempty"`
 }
 
 // LineExplanation provides explanation for a specific line of code
@@ -91,9 +101,27 @@ type ControlFlowNode struct {
 // CriticalModeOutput contains results for Critical Mode analysis.
 // It includes the overall grade, summary, and a list of issues.
 type CriticalModeOutput struct {
-	OverallGrade string      `json:"overall_grade"`
-	Summary      string      `json:"summary"`
-	Issues       []CodeIssue `json:"issues"`
+	OverallGrade     string            `json:"overall_grade"`
+	Summary          string            `json:"summary"`
+	Issues           []CodeIssue       `json:"issues"`
+	TokenEstimate    *TokenEstimate    `json:"token_estimate,omitempty"`
+	InjectionWarning *InjectionWarning `json:"injection_warning,omitempty"`
+}
+
+// InjectionWarning flags input containing directives aimed at the AI, such
+// as "ignore previous instructions". Prompts fence such input off as content,
+// but the results may still have been targeted.
+type InjectionWarning struct {
+	Message    string   `json:"message"`
+	Directives []string `json:"directives"`
+}
+
+// TokenEstimate reports how much of the model's context window an analysis
+// prompt used. Counts are heuristic (about four characters per token).
+type TokenEstimate struct {
+	Model        string `json:"model,omitempty"`
+	PromptTokens int    `json:"prompt_tokens"`
+	MaxTokens    int    `json:"max_tokens,omitempty"` // Prompt budget for Model; 0 when unknown
 }
 
 // ====================================================================================
@@ -194,13 +222,18 @@ const CriticalMode = "critical"
 
 // AnalysisResult represents a cached or captured analysis result.
 // It includes the analysis mode, prompt, summary, metadata, and other details.
+// Cached mode results also carry InputHash and the JSON Output; failure
+// captures leave both empty.
 type AnalysisResult struct {
+	CreatedAt time.Time
 	Mode      string
 	Prompt    string
 	Summary   string
 	Metadata  string
 	ModelUsed string
 	RawOutput string
+	InputHash string
+	Output    string
 	ReviewID  int64
 }
 
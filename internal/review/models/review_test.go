@@ -197,3 +197,13 @@ func TestMultipleIssues(t *testing.T) {
 	assert.Len(t, output.Issues, 3)
 	assert.Equal(t, "Excellent code", output.Summary)
 }
+
+func TestGradeRank(t *testing.T) {
+	assert.Equal(t, 5, GradeRank("A"))
+	assert.Equal(t, 4, GradeRank("b+"))
+	assert.Equal(t, 3, GradeRank("C-"))
+	assert.Equal(t, 1, GradeRank("F"))
+	assert.Equal(t, 0, GradeRank(""))
+	assert.Equal(t, 0, GradeRank("E"))
+	assert.Equal(t, 0, GradeRank("Good"))
+}
@@ -1,7 +1,10 @@
 // Package review_models contains data structures for review sessions and analysis.
 package review_models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // CodeReviewSession represents a complete code review session.
 // It tracks the current state across all reading modes and maintains history.
@@ -94,7 +97,51 @@ type SessionSummary struct {
 	LastAccessedAt  time.Time
 	Title           string
 	CodeSource      string
+	GithubRepo      string
 	Language        string
 	Status          string
 	CurrentMode     string
 }
+
+// AnalysisSearchFilter narrows a search of a user's analysis history. Zero
+// values leave a filter off.
+type AnalysisSearchFilter struct {
+	DateFrom time.Time // Inclusive
+	DateTo   time.Time // Exclusive
+	Mode     string
+	Repo     string // Substring of the session's GitHub repository, ignoring case
+	MinGrade string // Letter grade A-F; results without a grade never match
+	MaxGrade string
+	Limit    int
+	Offset   int
+}
+
+// AnalysisSearchResult summarizes one stored mode result and its session.
+type AnalysisSearchResult struct {
+	ID           int64     `json:"id"`
+	SessionID    int64     `json:"session_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	SessionTitle string    `json:"session_title"`
+	GithubRepo   string    `json:"github_repo,omitempty"`
+	Mode         string    `json:"mode"`
+	Summary      string    `json:"summary"`
+	Grade        string    `json:"grade,omitempty"`
+	ModelUsed    string    `json:"model_used,omitempty"`
+}
+
+// Grades orders the letter grades from worst to best; a grade's index is its
+// rank.
+const Grades = "FDCBA"
+
+// GradeRank returns the rank of a letter grade (F=1 through A=5), ignoring
+// case and any +/- suffix, or 0 if grade is not one.
+func GradeRank(grade string) int {
+	if grade == "" {
+		return 0
+	}
+	letter := strings.ToUpper(grade[:1])
+	if len(grade) > 1 && strings.Trim(grade[1:], "+-") != "" {
+		return 0
+	}
+	return strings.Index(Grades, letter) + 1
+}
@@ -0,0 +1,25 @@
+package review_models
+
+import "time"
+
+// AnalysisDetail is a stored mode result together with the session it
+// belongs to.
+type AnalysisDetail struct {
+	AnalysisResult
+	ID           int64
+	UserID       int64
+	SessionTitle string
+	GithubRepo   string
+}
+
+// AnalysisShare is a read-only link to one analysis result. The link's token
+// is signed and carries the share ID; the stored share lets its owner revoke
+// it before it expires.
+type AnalysisShare struct {
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ID         string     `json:"id"`
+	AnalysisID int64      `json:"analysis_id"`
+	UserID     int64      `json:"-"`
+}
@@ -23,6 +23,9 @@ type PromptTemplateRepositoryInterface interface {
 	SaveExecution(ctx context.Context, execution *review_models.PromptExecution) error
 	GetExecutionHistory(ctx context.Context, userID int, limit int) ([]*review_models.PromptExecution, error)
 	UpdateExecutionRating(ctx context.Context, executionID int64, userID int, rating int) error
+	FindVersion(ctx context.Context, versionID int64) (*review_models.PromptTemplateVersion, error)
+	ListVersions(ctx context.Context, userID int, mode, userLevel, outputMode string) ([]*review_models.PromptTemplateVersion, error)
+	RestoreVersion(ctx context.Context, version *review_models.PromptTemplateVersion) (*review_models.PromptTemplate, error)
 }
 
 // SQL query constants for maintainability
@@ -47,17 +50,67 @@ const (
 		  AND user_level = $2 
 		  AND output_mode = $3`
 
-	queryUpsertPrompt = `
-		INSERT INTO review.prompt_templates 
-		(id, user_id, mode, user_level, output_mode, prompt_text, variables, is_default, version)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		ON CONFLICT (user_id, mode, user_level, output_mode)
-		DO UPDATE SET
-			prompt_text = EXCLUDED.prompt_text,
-			variables = EXCLUDED.variables,
-			version = EXCLUDED.version,
-			updated_at = NOW()
-		RETURNING ` + selectPromptFields
+	// Custom prompts are also appended to the version history
+	queryUpsertPrompt            = queryUpsertPromptHead + `EXCLUDED.version,` + queryUpsertPromptTail
+	queryUpsertPromptNextVersion = queryUpsertPromptHead + `review.prompt_templates.version + 1,` + queryUpsertPromptTail
+
+	queryUpsertPromptHead = `
+		WITH saved AS (
+			INSERT INTO review.prompt_templates 
+			(id, user_id, mode, user_level, output_mode, prompt_text, variables, is_default, version)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (user_id, mode, user_level, output_mode)
+			DO UPDATE SET
+				prompt_text = EXCLUDED.prompt_text,
+				variables = EXCLUDED.variables,
+				version = `
+
+	queryUpsertPromptTail = `
+				updated_at = NOW()
+			RETURNING ` + selectPromptFields + `
+		), history AS (` + queryInsertVersionFromSaved + `NULL FROM saved WHERE user_id IS NOT NULL
+		)
+		SELECT ` + selectPromptFields + ` FROM saved`
+
+	// Restoring bumps the active version and records which version it restored
+	queryRestoreVersion = `
+		WITH saved AS (
+			INSERT INTO review.prompt_templates 
+			(id, user_id, mode, user_level, output_mode, prompt_text, variables, is_default, version)
+			SELECT template_id, user_id, mode, user_level, output_mode, prompt_text, variables, false, 1
+			FROM review.prompt_template_versions WHERE id = $1
+			ON CONFLICT (user_id, mode, user_level, output_mode)
+			DO UPDATE SET
+				prompt_text = EXCLUDED.prompt_text,
+				variables = EXCLUDED.variables,
+				version = review.prompt_templates.version + 1,
+				updated_at = NOW()
+			RETURNING ` + selectPromptFields + `
+		), history AS (` + queryInsertVersionFromSaved + `$1::int FROM saved
+		)
+		SELECT ` + selectPromptFields + ` FROM saved`
+
+	queryInsertVersionFromSaved = `
+			INSERT INTO review.prompt_template_versions
+			(template_id, user_id, mode, user_level, output_mode, prompt_text, variables, version, restored_from)
+			SELECT id, user_id, mode, user_level, output_mode, prompt_text, variables, version, `
+
+	selectVersionFields = `id, template_id, user_id, mode, user_level, output_mode, prompt_text,
+	                       variables, version, restored_from, created_at`
+
+	queryFindVersion = `
+		SELECT ` + selectVersionFields + `
+		FROM review.prompt_template_versions
+		WHERE id = $1`
+
+	queryListVersions = `
+		SELECT ` + selectVersionFields + `
+		FROM review.prompt_template_versions
+		WHERE user_id = $1 
+		  AND mode = $2 
+		  AND user_level = $3 
+		  AND output_mode = $4
+		ORDER BY created_at DESC, id DESC`
 
 	queryDeleteUserCustom = `
 		DELETE FROM review.prompt_templates
@@ -173,6 +226,23 @@ func (r *PromptTemplateRepository) FindDefaultByMode(
 func (r *PromptTemplateRepository) Upsert(
 	ctx context.Context,
 	template *review_models.PromptTemplate,
+) (*review_models.PromptTemplate, error) {
+	return r.upsert(ctx, queryUpsertPrompt, template)
+}
+
+// UpsertNextVersion is Upsert, except that updating an existing prompt
+// increments its stored version instead of taking template.Version
+func (r *PromptTemplateRepository) UpsertNextVersion(
+	ctx context.Context,
+	template *review_models.PromptTemplate,
+) (*review_models.PromptTemplate, error) {
+	return r.upsert(ctx, queryUpsertPromptNextVersion, template)
+}
+
+func (r *PromptTemplateRepository) upsert(
+	ctx context.Context,
+	query string,
+	template *review_models.PromptTemplate,
 ) (*review_models.PromptTemplate, error) {
 	// Convert variables to JSON
 	variablesJSON, err := json.Marshal(template.Variables)
@@ -180,7 +250,7 @@ func (r *PromptTemplateRepository) Upsert(
 		return nil, fmt.Errorf("failed to marshal variables: %w", err)
 	}
 
-	row := r.db.QueryRowContext(ctx, queryUpsertPrompt,
+	row := r.db.QueryRowContext(ctx, query,
 		template.ID,
 		template.UserID,
 		template.Mode,
@@ -290,6 +360,104 @@ func (r *PromptTemplateRepository) GetExecutionHistory(
 	return executions, nil
 }
 
+// scanPromptVersion scans one row of selectVersionFields
+func scanPromptVersion(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*review_models.PromptTemplateVersion, error) {
+	var version review_models.PromptTemplateVersion
+	var variablesJSON []byte
+	var restoredFrom sql.NullInt64
+
+	err := scanner.Scan(
+		&version.ID,
+		&version.TemplateID,
+		&version.UserID,
+		&version.Mode,
+		&version.UserLevel,
+		&version.OutputMode,
+		&version.PromptText,
+		&variablesJSON,
+		&version.Version,
+		&restoredFrom,
+		&version.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if restoredFrom.Valid {
+		version.RestoredFrom = &restoredFrom.Int64
+	}
+	if err := json.Unmarshal(variablesJSON, &version.Variables); err != nil {
+		return nil, fmt.Errorf("failed to parse variables: %w", err)
+	}
+
+	return &version, nil
+}
+
+// FindVersion retrieves a historical prompt version by id
+// Returns nil if the version does not exist
+func (r *PromptTemplateRepository) FindVersion(
+	ctx context.Context,
+	versionID int64,
+) (*review_models.PromptTemplateVersion, error) {
+	version, err := scanPromptVersion(r.db.QueryRowContext(ctx, queryFindVersion, versionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find prompt version: %w", err)
+	}
+
+	return version, nil
+}
+
+// ListVersions retrieves the version history of a user's custom prompt, newest first
+func (r *PromptTemplateRepository) ListVersions(
+	ctx context.Context,
+	userID int,
+	mode, userLevel, outputMode string,
+) ([]*review_models.PromptTemplateVersion, error) {
+	rows, err := r.db.QueryContext(ctx, queryListVersions, userID, mode, userLevel, outputMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt versions: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Printf("warning: failed to close rows: %v", err)
+		}
+	}()
+
+	versions := []*review_models.PromptTemplateVersion{}
+	for rows.Next() {
+		version, err := scanPromptVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan prompt version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating prompt version rows: %w", err)
+	}
+
+	return versions, nil
+}
+
+// RestoreVersion copies a historical version back to the active prompt,
+// recreating it if it was reset, and records the rollback as a new version
+func (r *PromptTemplateRepository) RestoreVersion(
+	ctx context.Context,
+	version *review_models.PromptTemplateVersion,
+) (*review_models.PromptTemplate, error) {
+	result, err := scanPromptTemplate(r.db.QueryRowContext(ctx, queryRestoreVersion, version.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore prompt version: %w", err)
+	}
+
+	return result, nil
+}
+
 // UpdateExecutionRating updates the user rating for a specific prompt execution
 func (r *PromptTemplateRepository) UpdateExecutionRating(
 	ctx context.Context,
@@ -27,6 +27,8 @@ func setupTestDB(t *testing.T) *sql.DB {
 	ctx := context.Background()
 	_, err = db.ExecContext(ctx, "DELETE FROM review.prompt_executions")
 	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "DELETE FROM review.prompt_template_versions")
+	require.NoError(t, err)
 	_, err = db.ExecContext(ctx, "DELETE FROM review.prompt_templates WHERE user_id IS NOT NULL")
 	require.NoError(t, err)
 
@@ -217,6 +219,103 @@ func TestPromptTemplateRepository_Upsert_Update(t *testing.T) {
 	assert.Equal(t, 1, count, "Should update, not create duplicate")
 }
 
+func TestPromptTemplateRepository_UpsertNextVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	repo := NewPromptTemplateRepository(db)
+	ctx := context.Background()
+
+	prompt := &review_models.PromptTemplate{
+		ID:         "user_222_scan_expert_quick",
+		UserID:     intPtr(222),
+		Mode:       "scan",
+		UserLevel:  "expert",
+		OutputMode: "quick",
+		PromptText: "First scan prompt",
+		Variables:  []string{"{{code}}"},
+		Version:    1,
+	}
+	_, err := repo.UpsertNextVersion(ctx, prompt)
+	require.NoError(t, err)
+
+	// Test: The stored version is bumped whatever the caller sends
+	prompt.PromptText = "Second scan prompt"
+	result, err := repo.UpsertNextVersion(ctx, prompt)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Version)
+
+	versions, err := repo.ListVersions(ctx, 222, "scan", "expert", "quick")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].Version)
+	assert.Equal(t, "Second scan prompt", versions[0].PromptText)
+}
+
+func TestPromptTemplateRepository_RestoreVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	repo := NewPromptTemplateRepository(db)
+	ctx := context.Background()
+
+	// Save three versions of the same prompt
+	prompt := &review_models.PromptTemplate{
+		ID:         "user_111_detailed_beginner_full",
+		UserID:     intPtr(111),
+		Mode:       "detailed",
+		UserLevel:  "beginner",
+		OutputMode: "full",
+		Variables:  []string{"{{code}}"},
+		Version:    1,
+	}
+	for i := 1; i <= 3; i++ {
+		prompt.PromptText = fmt.Sprintf("Detailed prompt v%d for {{code}}", i)
+		prompt.Version = i
+		_, err := repo.Upsert(ctx, prompt)
+		require.NoError(t, err)
+	}
+
+	versions, err := repo.ListVersions(ctx, 111, "detailed", "beginner", "full")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	second := versions[1]
+	assert.Equal(t, "Detailed prompt v2 for {{code}}", second.PromptText)
+
+	found, err := repo.FindVersion(ctx, second.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, 111, found.UserID)
+
+	// Test: Roll back to the second version
+	restored, err := repo.RestoreVersion(ctx, found)
+	require.NoError(t, err)
+	assert.Equal(t, "Detailed prompt v2 for {{code}}", restored.PromptText)
+	assert.Equal(t, 4, restored.Version)
+
+	active, err := repo.FindByUserAndMode(ctx, 111, "detailed", "beginner", "full")
+	require.NoError(t, err)
+	assert.Equal(t, "Detailed prompt v2 for {{code}}", active.PromptText)
+
+	versions, err = repo.ListVersions(ctx, 111, "detailed", "beginner", "full")
+	require.NoError(t, err)
+	require.Len(t, versions, 4, "rollback writes a history row")
+	require.NotNil(t, versions[0].RestoredFrom)
+	assert.Equal(t, second.ID, *versions[0].RestoredFrom)
+
+	missing, err := repo.FindVersion(ctx, second.ID+1000)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
 func TestPromptTemplateRepository_DeleteUserCustom(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
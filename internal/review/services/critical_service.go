@@ -2,7 +2,6 @@ package review_services
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"time"
 
@@ -45,9 +44,71 @@ func (s *CriticalService) AnalyzeCritical(ctx context.Context, code string) (*re
 	s.logger.Info("AnalyzeCritical called", "correlation_id", correlationID, "code_length", len(code))
 
 	// Build prompt using template
-	prompt := BuildCriticalPrompt(code)
+	prompt := withLanguageHint(ctx, BuildCriticalPrompt(code))
+	injection := flagPromptInjection(ctx, s.logger, span, "Critical analysis", code)
+	output, err := s.generate(ctx, span, prompt)
+	if err != nil {
+		return nil, err
+	}
+	output.InjectionWarning = injection
+	return output, nil
+}
+
+// AnalyzeCriticalDiff runs Critical Mode over a unified diff. Only changed
+// lines and DiffContextLines of context around them reach the model, and
+// every issue is anchored to a new-file line of the diff (line 0 when it
+// cannot be placed). An unparseable diff is a BusinessError.
+func (s *CriticalService) AnalyzeCriticalDiff(ctx context.Context, diff string) (*review_models.CriticalModeOutput, error) {
+	tracer := otel.Tracer("devsmith-review")
+	ctx, span := tracer.Start(ctx, "CriticalService.AnalyzeCriticalDiff",
+		trace.WithAttributes(
+			attribute.Int("diff_length", len(diff)),
+		),
+	)
+	defer span.End()
+
+	files, err := ParseUnifiedDiff(diff)
+	if err != nil {
+		diffErr := &review_errors.BusinessError{
+			Code:       "ERR_INVALID_DIFF",
+			Message:    err.Error(),
+			HTTPStatus: http.StatusBadRequest,
+		}
+		span.RecordError(diffErr)
+		span.SetAttributes(attribute.Bool("error", true))
+		return nil, diffErr
+	}
+	span.SetAttributes(attribute.Int("diff_files", len(files)))
+
+	correlationID := ctx.Value(logger.CorrelationIDKey)
+	s.logger.Info("AnalyzeCriticalDiff called", "correlation_id", correlationID, "diff_length", len(diff), "files", len(files))
+
+	prompt := withLanguageHint(ctx, BuildCriticalDiffPrompt(RenderDiffForReview(files, DiffContextLines)))
+	injection := flagPromptInjection(ctx, s.logger, span, "Critical analysis", diff)
+	output, err := s.generate(ctx, span, prompt)
+	if err != nil {
+		return nil, err
+	}
+	output.InjectionWarning = injection
+
+	AnchorIssuesToDiff(files, output.Issues)
+	return output, nil
+}
+
+// generate sends a Critical Mode prompt to the model and parses the result.
+func (s *CriticalService) generate(ctx context.Context, span trace.Span, prompt string) (*review_models.CriticalModeOutput, error) {
+	correlationID := ctx.Value(logger.CorrelationIDKey)
 	span.SetAttributes(attribute.Int("prompt_length", len(prompt)))
 
+	estimate, sizeErr := checkPromptSize(ctx, prompt)
+	span.SetAttributes(attribute.Int("prompt_tokens_est", estimate.PromptTokens))
+	if sizeErr != nil {
+		s.logger.Warn("Critical analysis: input too large for model", "correlation_id", correlationID, "error", sizeErr)
+		span.RecordError(sizeErr)
+		span.SetAttributes(attribute.Bool("error", true))
+		return nil, sizeErr
+	}
+
 	// Call Ollama for real analysis
 	start := time.Now()
 	rawOutput, err := s.ollamaClient.Generate(ctx, prompt)
@@ -87,8 +148,8 @@ func (s *CriticalService) AnalyzeCritical(ctx context.Context, code string) (*re
 	}
 
 	// Parse JSON response
-	var output review_models.CriticalModeOutput
-	if unmarshalErr := json.Unmarshal([]byte(jsonStr), &output); unmarshalErr != nil {
+	output, repaired, unmarshalErr := unmarshalAIOutput[review_models.CriticalModeOutput](jsonStr, rawOutput)
+	if unmarshalErr != nil {
 		s.logger.Error("Failed to unmarshal critical analysis output", "correlation_id", correlationID, "error", unmarshalErr)
 		parseErr := &review_errors.InfrastructureError{
 			Code:       "ERR_AI_RESPONSE_INVALID",
@@ -101,6 +162,11 @@ func (s *CriticalService) AnalyzeCritical(ctx context.Context, code string) (*re
 		return nil, parseErr
 	}
 
+	span.SetAttributes(attribute.Bool("json_repaired", repaired))
+	if repaired {
+		s.logger.Warn("Critical analysis output needed JSON repair", "correlation_id", correlationID)
+	}
+
 	// Validate output structure
 	if output.Summary == "" {
 		s.logger.Warn("Critical analysis returned empty summary", "correlation_id", correlationID)
@@ -115,5 +181,6 @@ func (s *CriticalService) AnalyzeCritical(ctx context.Context, code string) (*re
 	)
 
 	s.logger.Info("Critical analysis completed", "correlation_id", correlationID, "issues_found", len(output.Issues), "grade", output.OverallGrade)
-	return &output, nil
+	output.TokenEstimate = estimate
+	return output, nil
 }
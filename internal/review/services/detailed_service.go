@@ -68,9 +68,19 @@ func (s *DetailedService) AnalyzeDetailed(ctx context.Context, code, target, use
 	}
 
 	// Build prompt using template with user/output modes
-	prompt := BuildDetailedPrompt(code, target, userMode, outputMode)
+	prompt := withLanguageHint(ctx, BuildDetailedPrompt(code, target, userMode, outputMode))
+	injection := flagPromptInjection(ctx, s.logger, span, "DetailedService", code, target)
 	span.SetAttributes(attribute.Int("prompt_length", len(prompt)))
 
+	estimate, sizeErr := checkPromptSize(ctx, prompt)
+	span.SetAttributes(attribute.Int("prompt_tokens_est", estimate.PromptTokens))
+	if sizeErr != nil {
+		s.logger.Warn("DetailedService: input too large for model", "correlation_id", correlationID, "error", sizeErr)
+		span.RecordError(sizeErr)
+		span.SetAttributes(attribute.Bool("error", true))
+		return nil, sizeErr
+	}
+
 	start := time.Now()
 	resp, err := s.ollamaClient.Generate(ctx, prompt)
 	duration := time.Since(start)
@@ -109,6 +119,8 @@ func (s *DetailedService) AnalyzeDetailed(ctx context.Context, code, target, use
 				_ = s.maybePersistAnalysis(ctx, target, prompt, repaired, resp)
 				span.SetAttributes(attribute.Bool("error", false))
 				span.SetAttributes(attribute.Int("line_explanations_count", len(output.LineExplanations)))
+				output.TokenEstimate = estimate
+				output.InjectionWarning = injection
 				return &output, nil
 			} else {
 				// fall through to record repair failure
@@ -136,19 +148,23 @@ func (s *DetailedService) AnalyzeDetailed(ctx context.Context, code, target, use
 		return nil, extractErrWrapped
 	}
 
-	var output review_models.DetailedModeOutput
-	if err := json.Unmarshal([]byte(jsonStr), &output); err != nil {
+	// Fix common JSON mistakes locally before asking the AI to repair them
+	output, repaired, err := unmarshalAIOutput[review_models.DetailedModeOutput](jsonStr, resp)
+	if err != nil {
 		s.logger.Warn("DetailedService: failed to unmarshal output - attempting repair", "correlation_id", correlationID, "error", err)
+		output = &review_models.DetailedModeOutput{}
 
 		// Try to repair the JSON using the AI
-		repaired, repairErr := s.attemptJSONRepair(ctx, resp)
+		aiRepaired, repairErr := s.attemptJSONRepair(ctx, resp)
 		if repairErr == nil {
-			if uerr := json.Unmarshal([]byte(repaired), &output); uerr == nil {
+			if uerr := json.Unmarshal([]byte(aiRepaired), output); uerr == nil {
 				s.logger.Info("DetailedService: repaired AI output and parsed successfully", "correlation_id", correlationID)
-				_ = s.maybePersistAnalysis(ctx, target, prompt, repaired, resp)
-				span.SetAttributes(attribute.Bool("error", false))
+				_ = s.maybePersistAnalysis(ctx, target, prompt, aiRepaired, resp)
+				span.SetAttributes(attribute.Bool("error", false), attribute.Bool("json_repaired", true))
 				span.SetAttributes(attribute.Int("line_explanations_count", len(output.LineExplanations)))
-				return &output, nil
+				output.TokenEstimate = estimate
+				output.InjectionWarning = injection
+				return output, nil
 			} else {
 				s.logger.Error("DetailedService: repaired output still invalid", "correlation_id", correlationID, "error", uerr)
 			}
@@ -173,14 +189,21 @@ func (s *DetailedService) AnalyzeDetailed(ctx context.Context, code, target, use
 		return nil, parseErr
 	}
 
+	if repaired {
+		s.logger.Warn("DetailedService: AI output needed JSON repair", "correlation_id", correlationID)
+	}
+
 	span.SetAttributes(
 		attribute.Bool("error", false),
 		attribute.Bool("success", true),
+		attribute.Bool("json_repaired", repaired),
 		attribute.Int("line_explanations_count", len(output.LineExplanations)),
 	)
 
 	s.logger.Info("DetailedService: analysis completed", "correlation_id", correlationID, "line_explanations_count", len(output.LineExplanations))
-	return &output, nil
+	output.TokenEstimate = estimate
+	output.InjectionWarning = injection
+	return output, nil
 }
 
 // attemptJSONRepair asks the AI to extract/repair JSON from a raw AI response.
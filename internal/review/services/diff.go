@@ -0,0 +1,343 @@
+package review_services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// DiffContextLines is how many unchanged lines around each change are sent
+// to the model when reviewing a diff.
+const DiffContextLines = 3
+
+// ErrInvalidDiff is returned when text cannot be parsed as a unified diff.
+var ErrInvalidDiff = errors.New("invalid unified diff")
+
+// hunkHeader matches "@@ -12,7 +12,9 @@ optional section heading".
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// anyHunkHeader finds a hunk header on any line.
+var anyHunkHeader = regexp.MustCompile(`(?m)^@@ -\d+(?:,\d+)? \+\d+(?:,\d+)? @@`)
+
+// DiffLineKind says whether a diff line was added, removed or kept.
+type DiffLineKind byte
+
+// Diff line kinds, spelled as their unified diff prefix.
+const (
+	DiffContext DiffLineKind = ' '
+	DiffAdded   DiffLineKind = '+'
+	DiffRemoved DiffLineKind = '-'
+)
+
+// DiffLine is one line of a hunk. OldLine is 0 for added lines and NewLine
+// is 0 for removed lines.
+type DiffLine struct {
+	Text    string
+	OldLine int
+	NewLine int
+	Kind    DiffLineKind
+}
+
+// DiffHunk is one "@@" section of a file diff.
+type DiffHunk struct {
+	Lines    []DiffLine
+	OldStart int
+	NewStart int
+}
+
+// DiffFile holds the hunks for one file. NewPath is "/dev/null" for deletions.
+type DiffFile struct {
+	OldPath string
+	NewPath string
+	Hunks   []DiffHunk
+}
+
+// Path returns the name reviewers know the file by: the new path, or the
+// old one if the file was deleted.
+func (f *DiffFile) Path() string {
+	if f.NewPath == "" || f.NewPath == "/dev/null" {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// IsUnifiedDiff reports whether text looks like a unified diff: a git diff
+// header or a hunk header at the start of a line.
+func IsUnifiedDiff(text string) bool {
+	return strings.HasPrefix(text, "diff --git ") || strings.Contains(text, "\ndiff --git ") ||
+		anyHunkHeader.MatchString(text)
+}
+
+// ParseUnifiedDiff parses git or plain unified diff output. Hunk lines are
+// numbered against both the old and new file.
+func ParseUnifiedDiff(text string) ([]DiffFile, error) {
+	var files []DiffFile
+	var file *DiffFile
+	var hunk *DiffHunk
+	oldLine, newLine := 0, 0
+	oldLeft, newLeft := 0, 0 // lines still expected in the current hunk
+
+	startFile := func() {
+		files = append(files, DiffFile{})
+		file = &files[len(files)-1]
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if hunk != nil {
+			// Inside a hunk the header counts decide where it ends, so a
+			// removed "-- comment" line is never mistaken for a file header
+			kind := DiffContext
+			if line != "" {
+				kind = DiffLineKind(line[0])
+			}
+			body := line
+			if body != "" {
+				body = body[1:]
+			}
+			switch kind {
+			case DiffAdded:
+				hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffAdded, Text: body, NewLine: newLine})
+				newLine++
+				newLeft--
+			case DiffRemoved:
+				hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffRemoved, Text: body, OldLine: oldLine})
+				oldLine++
+				oldLeft--
+			case DiffContext:
+				hunk.Lines = append(hunk.Lines, DiffLine{Kind: DiffContext, Text: body, OldLine: oldLine, NewLine: newLine})
+				oldLine++
+				newLine++
+				oldLeft--
+				newLeft--
+			case '\\':
+				continue // "\ No newline at end of file"
+			default:
+				return nil, fmt.Errorf("%w: unexpected line in hunk: %q", ErrInvalidDiff, line)
+			}
+			if oldLeft <= 0 && newLeft <= 0 {
+				hunk = nil
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			startFile()
+		case strings.HasPrefix(line, "--- "):
+			if file == nil || file.OldPath != "" || len(file.Hunks) > 0 {
+				startFile()
+			}
+			file.OldPath = diffPath(line[4:])
+		case strings.HasPrefix(line, "+++ "):
+			if file == nil {
+				startFile()
+			}
+			file.NewPath = diffPath(line[4:])
+		case strings.HasPrefix(line, "@@"):
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("%w: malformed hunk header %q", ErrInvalidDiff, line)
+			}
+			if file == nil {
+				startFile()
+			}
+			oldLine, _ = strconv.Atoi(m[1])
+			newLine, _ = strconv.Atoi(m[3])
+			oldLeft, newLeft = hunkCount(m[2]), hunkCount(m[4])
+			file.Hunks = append(file.Hunks, DiffHunk{OldStart: oldLine, NewStart: newLine})
+			hunk = &file.Hunks[len(file.Hunks)-1]
+			if oldLeft <= 0 && newLeft <= 0 {
+				hunk = nil
+			}
+		}
+		// Anything else (index, mode, rename lines, commit text) is skipped
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDiff, err)
+	}
+
+	changed := files[:0]
+	for _, f := range files {
+		if len(f.Hunks) > 0 {
+			changed = append(changed, f)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, fmt.Errorf("%w: no hunks found", ErrInvalidDiff)
+	}
+	return changed, nil
+}
+
+// hunkCount parses the optional line count of a hunk range; it defaults to 1.
+func hunkCount(s string) int {
+	if s == "" {
+		return 1
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// diffPath strips the a/ or b/ prefix and any trailing timestamp from a
+// "---" or "+++" header value.
+func diffPath(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		s = s[2:]
+	}
+	return s
+}
+
+// RenderDiffForReview formats the changed lines of each file, with up to
+// contextLines unchanged lines around them, for the model to review.
+// Each kept line is prefixed with its new-file line number so the model can
+// report issues against it; removed lines have no number.
+func RenderDiffForReview(files []DiffFile, contextLines int) string {
+	var b strings.Builder
+	for _, f := range files {
+		fmt.Fprintf(&b, "FILE: %s\n", f.Path())
+		for _, h := range f.Hunks {
+			keep := changedWithContext(h.Lines, contextLines)
+			prev := -1
+			for i, l := range h.Lines {
+				if !keep[i] {
+					continue
+				}
+				if prev >= 0 && i != prev+1 {
+					b.WriteString("     ...\n")
+				}
+				prev = i
+				if l.Kind == DiffRemoved {
+					fmt.Fprintf(&b, "     %c %s\n", l.Kind, l.Text)
+				} else {
+					fmt.Fprintf(&b, "%4d %c %s\n", l.NewLine, l.Kind, l.Text)
+				}
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// changedWithContext marks added/removed lines and the context lines within
+// n lines of them.
+func changedWithContext(lines []DiffLine, n int) []bool {
+	keep := make([]bool, len(lines))
+	for i, l := range lines {
+		if l.Kind == DiffContext {
+			continue
+		}
+		for j := i - n; j <= i+n; j++ {
+			if j >= 0 && j < len(lines) {
+				keep[j] = true
+			}
+		}
+	}
+	return keep
+}
+
+// AnchorIssuesToDiff points each issue at a new-file line of the diff.
+// Models often miscount lines, so an issue whose code snippet matches a
+// line in the diff is moved to that line (added lines first, then the
+// nearest match). Issues that match nothing and report a line outside the
+// diff get line 0, meaning "not determinable".
+func AnchorIssuesToDiff(files []DiffFile, issues []review_models.CodeIssue) {
+	for i := range issues {
+		issue := &issues[i]
+		candidates := files
+		if f := findDiffFile(files, issue.File); f != nil {
+			candidates = []DiffFile{*f}
+		} else if len(files) == 1 {
+			issue.File = files[0].Path()
+		}
+
+		if file, line, ok := matchSnippet(candidates, issue.CodeSnippet, issue.Line); ok {
+			issue.File = file
+			issue.Line = line
+			continue
+		}
+
+		if f := findDiffFile(files, issue.File); f == nil || !f.hasNewLine(issue.Line) {
+			issue.Line = 0
+		}
+	}
+}
+
+// findDiffFile returns the file whose path matches name, ignoring a/ or b/
+// prefixes and leading directories the model may have dropped.
+func findDiffFile(files []DiffFile, name string) *DiffFile {
+	name = diffPath(name)
+	if name == "" {
+		return nil
+	}
+	for i := range files {
+		path := files[i].Path()
+		if path == name || strings.HasSuffix(path, "/"+name) {
+			return &files[i]
+		}
+	}
+	return nil
+}
+
+func (f *DiffFile) hasNewLine(n int) bool {
+	if n <= 0 {
+		return false
+	}
+	for _, h := range f.Hunks {
+		for _, l := range h.Lines {
+			if l.Kind != DiffRemoved && l.NewLine == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchSnippet finds the line holding the first non-blank line of snippet.
+// Added lines win over context lines; ties go to the line nearest near.
+func matchSnippet(files []DiffFile, snippet string, near int) (file string, line int, ok bool) {
+	needle := ""
+	for _, s := range strings.Split(snippet, "\n") {
+		if needle = strings.TrimSpace(s); needle != "" {
+			break
+		}
+	}
+	if needle == "" {
+		return "", 0, false
+	}
+
+	bestRank, bestDist := 2, 0
+	for _, f := range files {
+		for _, h := range f.Hunks {
+			for _, l := range h.Lines {
+				if l.Kind == DiffRemoved || !strings.Contains(l.Text, needle) {
+					continue
+				}
+				rank := 1
+				if l.Kind == DiffAdded {
+					rank = 0
+				}
+				dist := l.NewLine - near
+				if dist < 0 {
+					dist = -dist
+				}
+				if rank < bestRank || (rank == bestRank && dist < bestDist) {
+					bestRank, bestDist = rank, dist
+					file, line, ok = f.Path(), l.NewLine, true
+				}
+			}
+		}
+	}
+	return file, line, ok
+}
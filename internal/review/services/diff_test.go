@@ -0,0 +1,180 @@
+package review_services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	review_errors "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/errors"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleDiff = `diff --git a/handlers/user.go b/handlers/user.go
+index 3b18e51..a9c4f2d 100644
+--- a/handlers/user.go
++++ b/handlers/user.go
+@@ -10,7 +10,8 @@ func GetUser(w http.ResponseWriter, r *http.Request) {
+ 	id := r.URL.Query().Get("id")
+ 	if id == "" {
+ 		http.Error(w, "missing id", http.StatusBadRequest)
+-		return
+ 	}
+-	row := db.QueryRow("SELECT name FROM users WHERE id = $1", id)
++	query := "SELECT name FROM users WHERE id = " + id
++	row := db.QueryRow(query)
++	log.Printf("lookup %s", id)
+ 	var name string
+@@ -40,3 +41,4 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
+ 	if err != nil {
+ 		return
+ 	}
++	w.WriteHeader(http.StatusNoContent)
+diff --git a/schema.sql b/schema.sql
+--- a/schema.sql
++++ b/schema.sql
+@@ -1,2 +1,2 @@
+--- users table
++-- user accounts
+ CREATE TABLE users (id SERIAL PRIMARY KEY);
+\ No newline at end of file
+`
+
+func TestParseUnifiedDiff_NewFileLineNumbers(t *testing.T) {
+	files, err := ParseUnifiedDiff(sampleDiff)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	user := files[0]
+	assert.Equal(t, "handlers/user.go", user.Path())
+	require.Len(t, user.Hunks, 2)
+
+	added := map[int]string{}
+	for _, h := range user.Hunks {
+		for _, l := range h.Lines {
+			if l.Kind == DiffAdded {
+				added[l.NewLine] = strings.TrimSpace(l.Text)
+			}
+		}
+	}
+	assert.Equal(t, map[int]string{
+		14: `query := "SELECT name FROM users WHERE id = " + id`,
+		15: `row := db.QueryRow(query)`,
+		16: `log.Printf("lookup %s", id)`,
+		44: `w.WriteHeader(http.StatusNoContent)`,
+	}, added)
+
+	removed := user.Hunks[0].Lines[3]
+	assert.Equal(t, DiffRemoved, removed.Kind)
+	assert.Equal(t, 13, removed.OldLine)
+	assert.Zero(t, removed.NewLine)
+
+	// A removed "-- comment" inside a hunk must not start a new file
+	schema := files[1]
+	assert.Equal(t, "schema.sql", schema.Path())
+	require.Len(t, schema.Hunks, 1)
+	require.Len(t, schema.Hunks[0].Lines, 3)
+	assert.Equal(t, DiffRemoved, schema.Hunks[0].Lines[0].Kind)
+	assert.Equal(t, "-- users table", schema.Hunks[0].Lines[0].Text)
+	assert.Equal(t, 1, schema.Hunks[0].Lines[1].NewLine)
+}
+
+func TestParseUnifiedDiff_Invalid(t *testing.T) {
+	_, err := ParseUnifiedDiff("func main() {}\n")
+	assert.ErrorIs(t, err, ErrInvalidDiff)
+
+	_, err = ParseUnifiedDiff("--- a/x\n+++ b/x\n@@ -1 +1 @@\n?oops\n")
+	assert.ErrorIs(t, err, ErrInvalidDiff)
+}
+
+func TestIsUnifiedDiff(t *testing.T) {
+	assert.True(t, IsUnifiedDiff(sampleDiff))
+	assert.True(t, IsUnifiedDiff("@@ -1,2 +1,3 @@\n a\n+b\n"))
+	assert.False(t, IsUnifiedDiff("package main\n\nfunc main() {\n\tx := a - b\n}\n"))
+	assert.False(t, IsUnifiedDiff("The meeting notes -- see below @@ not a diff"))
+}
+
+func TestRenderDiffForReview_KeepsChangesWithContext(t *testing.T) {
+	files, err := ParseUnifiedDiff(sampleDiff)
+	require.NoError(t, err)
+
+	out := RenderDiffForReview(files, 1)
+	assert.Contains(t, out, "FILE: handlers/user.go")
+	assert.Contains(t, out, "  14 + \tquery := \"SELECT name FROM users WHERE id = \" + id")
+	assert.Contains(t, out, "     - \t\treturn")
+	assert.Contains(t, out, "  44 + \tw.WriteHeader(http.StatusNoContent)")
+	// Two lines above the first change are beyond one line of context
+	assert.NotContains(t, out, `r.URL.Query().Get("id")`)
+	assert.Contains(t, out, "FILE: schema.sql")
+}
+
+func TestAnchorIssuesToDiff(t *testing.T) {
+	files, err := ParseUnifiedDiff(sampleDiff)
+	require.NoError(t, err)
+
+	issues := []review_models.CodeIssue{
+		// Model miscounted the line but quoted the code
+		{File: "handlers/user.go", Line: 12, CodeSnippet: `query := "SELECT name FROM users WHERE id = " + id`},
+		// Correct line, no snippet
+		{File: "b/handlers/user.go", Line: 44},
+		// Line outside the diff and nothing to match
+		{File: "handlers/user.go", Line: 300},
+		// File omitted, snippet identifies it
+		{File: "unknown", CodeSnippet: "-- user accounts"},
+	}
+	AnchorIssuesToDiff(files, issues)
+
+	assert.Equal(t, "handlers/user.go", issues[0].File)
+	assert.Equal(t, 14, issues[0].Line)
+	assert.Equal(t, 44, issues[1].Line)
+	assert.Equal(t, 0, issues[2].Line)
+	assert.Equal(t, "schema.sql", issues[3].File)
+	assert.Equal(t, 1, issues[3].Line)
+}
+
+func TestCriticalService_AnalyzeCriticalDiff(t *testing.T) {
+	var prompt string
+	ollama := &recordingOllama{
+		resp: `{"overall_grade":"F","summary":"SQL injection","issues":[
+			{"severity":"critical","category":"security","file":"handlers/user.go","line":13,
+			 "code_snippet":"row := db.QueryRow(query)","description":"query built from input",
+			 "impact":"SQL injection","fix_suggestion":"use a placeholder"},
+			{"severity":"low","category":"maintainability","file":"handlers/user.go","line":16,
+			 "code_snippet":"","description":"noisy log","impact":"log volume","fix_suggestion":"drop it"}
+		]}`,
+		prompt: &prompt,
+	}
+	svc := NewCriticalService(ollama, &testutils.MockAnalysisRepository{}, &nopLogger{})
+
+	out, err := svc.AnalyzeCriticalDiff(context.Background(), sampleDiff)
+	require.NoError(t, err)
+	require.Len(t, out.Issues, 2)
+	assert.Equal(t, 15, out.Issues[0].Line, "snippet match corrects the model's line number")
+	assert.Equal(t, 16, out.Issues[1].Line)
+
+	assert.Contains(t, prompt, "  15 + \trow := db.QueryRow(query)")
+	assert.NotContains(t, prompt, "func DeleteUser", "hunk headings are not sent")
+}
+
+func TestCriticalService_AnalyzeCriticalDiff_InvalidDiff(t *testing.T) {
+	svc := NewCriticalService(&mockOllama{}, &testutils.MockAnalysisRepository{}, &nopLogger{})
+
+	_, err := svc.AnalyzeCriticalDiff(context.Background(), "not a diff")
+	var businessErr *review_errors.BusinessError
+	require.True(t, errors.As(err, &businessErr))
+	assert.Equal(t, "ERR_INVALID_DIFF", businessErr.Code)
+}
+
+// recordingOllama returns a fixed response and records the prompt it saw.
+type recordingOllama struct {
+	prompt *string
+	resp   string
+}
+
+func (m *recordingOllama) Generate(ctx context.Context, prompt string) (string, error) {
+	*m.prompt = prompt
+	return m.resp, nil
+}
@@ -0,0 +1,100 @@
+package review_services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// GradeRule assigns Grade when the issue count at any severity reaches its
+// threshold. Zero thresholds are ignored.
+type GradeRule struct {
+	Grade    string `json:"grade"`
+	Critical int    `json:"critical,omitempty"`
+	High     int    `json:"high,omitempty"`
+	Medium   int    `json:"medium,omitempty"`
+}
+
+// GradePolicy maps issue counts by severity to a letter grade, replacing the
+// model's own grade so results are consistent between runs. Rules are
+// checked in order and the first match wins; PassGrade applies when none do.
+type GradePolicy struct {
+	PassGrade string      `json:"pass_grade"`
+	Rules     []GradeRule `json:"rules"`
+}
+
+// DefaultGradePolicy is the conservative rubric: any critical -> F,
+// 2+ high -> D, 1 high or 3+ medium -> C, 1-2 medium -> B, else A.
+func DefaultGradePolicy() *GradePolicy {
+	return &GradePolicy{
+		PassGrade: "A",
+		Rules: []GradeRule{
+			{Grade: "F", Critical: 1},
+			{Grade: "D", High: 2},
+			{Grade: "C", High: 1, Medium: 3},
+			{Grade: "B", Medium: 1},
+		},
+	}
+}
+
+// ParseGradePolicy decodes and validates a JSON grade policy, e.g.
+//
+//	{"pass_grade":"A","rules":[{"grade":"F","critical":1,"high":1},{"grade":"C","medium":1}]}
+func ParseGradePolicy(data []byte) (*GradePolicy, error) {
+	var policy GradePolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid grade policy: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// Validate checks that every rule names a grade and can match.
+func (p *GradePolicy) Validate() error {
+	if strings.TrimSpace(p.PassGrade) == "" {
+		return fmt.Errorf("invalid grade policy: pass_grade is required")
+	}
+	for i, rule := range p.Rules {
+		if strings.TrimSpace(rule.Grade) == "" {
+			return fmt.Errorf("invalid grade policy: rule %d has no grade", i)
+		}
+		if rule.Critical < 0 || rule.High < 0 || rule.Medium < 0 {
+			return fmt.Errorf("invalid grade policy: rule %d has a negative threshold", i)
+		}
+		if rule.Critical == 0 && rule.High == 0 && rule.Medium == 0 {
+			return fmt.Errorf("invalid grade policy: rule %d has no thresholds", i)
+		}
+	}
+	return nil
+}
+
+// GradeFromIssues returns the letter grade for issues under this policy.
+// Low and info issues never affect the grade.
+func (p *GradePolicy) GradeFromIssues(issues []review_models.CodeIssue) string {
+	var crit, high, med int
+	for _, it := range issues {
+		switch strings.ToLower(it.Severity) {
+		case "critical":
+			crit++
+		case "high":
+			high++
+		case "medium":
+			med++
+		}
+	}
+
+	for _, rule := range p.Rules {
+		if reached(crit, rule.Critical) || reached(high, rule.High) || reached(med, rule.Medium) {
+			return rule.Grade
+		}
+	}
+	return p.PassGrade
+}
+
+func reached(count, threshold int) bool {
+	return threshold > 0 && count >= threshold
+}
@@ -0,0 +1,75 @@
+package review_services
+
+import (
+	"testing"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func issuesWith(severities ...string) []review_models.CodeIssue {
+	issues := make([]review_models.CodeIssue, len(severities))
+	for i, s := range severities {
+		issues[i] = review_models.CodeIssue{Severity: s}
+	}
+	return issues
+}
+
+func TestDefaultGradePolicy(t *testing.T) {
+	policy := DefaultGradePolicy()
+	require.NoError(t, policy.Validate())
+
+	tests := []struct {
+		name   string
+		issues []review_models.CodeIssue
+		want   string
+	}{
+		{"no issues", nil, "A"},
+		{"only low", issuesWith("low", "info"), "A"},
+		{"one medium", issuesWith("medium"), "B"},
+		{"two medium", issuesWith("medium", "Medium"), "B"},
+		{"three medium", issuesWith("medium", "medium", "medium"), "C"},
+		{"one high", issuesWith("high", "low"), "C"},
+		{"two high", issuesWith("high", "HIGH"), "D"},
+		{"any critical", issuesWith("critical", "medium"), "F"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.GradeFromIssues(tt.issues))
+		})
+	}
+}
+
+func TestParseGradePolicy_Strict(t *testing.T) {
+	policy, err := ParseGradePolicy([]byte(`{
+		"pass_grade": "A",
+		"rules": [
+			{"grade": "F", "critical": 1, "high": 1},
+			{"grade": "C", "medium": 2},
+			{"grade": "B", "medium": 1}
+		]
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "F", policy.GradeFromIssues(issuesWith("high")), "a single high issue fails")
+	assert.Equal(t, "F", policy.GradeFromIssues(issuesWith("critical")))
+	assert.Equal(t, "C", policy.GradeFromIssues(issuesWith("medium", "medium")))
+	assert.Equal(t, "B", policy.GradeFromIssues(issuesWith("medium", "low")))
+	assert.Equal(t, "A", policy.GradeFromIssues(nil))
+}
+
+func TestParseGradePolicy_Invalid(t *testing.T) {
+	for name, raw := range map[string]string{
+		"not json":          `strict`,
+		"no pass grade":     `{"rules":[{"grade":"F","critical":1}]}`,
+		"rule without name": `{"pass_grade":"A","rules":[{"critical":1}]}`,
+		"never matches":     `{"pass_grade":"A","rules":[{"grade":"F"}]}`,
+		"negative":          `{"pass_grade":"A","rules":[{"grade":"F","high":-1}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseGradePolicy([]byte(raw))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -64,6 +64,10 @@ type CriticalAnalyzer interface {
 	// Returns CriticalModeOutput with categorized issues or an error.
 	// Issues must include severity, file location, and suggested fixes.
 	AnalyzeCritical(ctx context.Context, code string) (*review_models.CriticalModeOutput, error)
+
+	// AnalyzeCriticalDiff evaluates only the changes in a unified diff.
+	// Issue lines refer to the new version of each file.
+	AnalyzeCriticalDiff(ctx context.Context, diff string) (*review_models.CriticalModeOutput, error)
 }
 
 // ====================================================================================
@@ -0,0 +1,182 @@
+package review_services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// issueSeverities lists severities from most to least severe.
+var issueSeverities = []string{"critical", "high", "medium", "low"}
+
+// severityRank orders severities for ranking; unknown values sort last.
+func severityRank(severity string) int {
+	for i, s := range issueSeverities {
+		if strings.EqualFold(severity, s) {
+			return len(issueSeverities) - i
+		}
+	}
+	return 0
+}
+
+// issueKey identifies issues that are the same finding reported for
+// different files: same category and description, ignoring case, spacing
+// and trailing punctuation.
+func issueKey(issue review_models.AnalysisIssue) string {
+	desc := strings.Join(strings.Fields(strings.ToLower(issue.Description)), " ")
+	return strings.ToLower(strings.TrimSpace(issue.Category)) + "|" + strings.TrimRight(desc, ".!;: ")
+}
+
+// AggregateSessionIssues combines the issues of a multi-file analysis into a
+// session-level review. Identical issues reported for several files are
+// merged into one issue listing every location and keep the highest
+// severity reported. An issue is a cross-file concern when its locations or
+// related files span more than one file. Issues are ranked by severity, then
+// by how many places they occur. files gives the order of the per-file
+// breakdown; every file analyzed appears even if it has no issues.
+func AggregateSessionIssues(files []string, issues []review_models.AnalysisIssue) *review_models.SessionReview {
+	var aggregated []*review_models.AggregatedIssue
+	byKey := make(map[string]*review_models.AggregatedIssue)
+	for _, issue := range issues {
+		if strings.TrimSpace(issue.Description) == "" {
+			continue
+		}
+		key := issueKey(issue)
+		agg, ok := byKey[key]
+		if !ok {
+			agg = &review_models.AggregatedIssue{
+				Severity:    strings.ToLower(issue.Severity),
+				Category:    issue.Category,
+				Description: strings.TrimSpace(issue.Description),
+			}
+			byKey[key] = agg
+			aggregated = append(aggregated, agg)
+		}
+		if severityRank(issue.Severity) > severityRank(agg.Severity) {
+			agg.Severity = strings.ToLower(issue.Severity)
+		}
+		if agg.Suggestion == "" {
+			agg.Suggestion = issue.Suggestion
+		}
+		addLocation(agg, review_models.IssueLocation{File: issue.File, Line: issue.Line})
+		for _, related := range issue.RelatedFiles {
+			addLocation(agg, review_models.IssueLocation{File: related})
+		}
+	}
+
+	review := &review_models.SessionReview{
+		BySeverity: make(map[string]int),
+		Issues:     make([]review_models.AggregatedIssue, 0, len(aggregated)),
+	}
+	for _, agg := range aggregated {
+		agg.CrossFile = len(locationFiles(agg.Locations)) > 1
+		review.Issues = append(review.Issues, *agg)
+	}
+	sort.SliceStable(review.Issues, func(i, j int) bool {
+		a, b := review.Issues[i], review.Issues[j]
+		if ra, rb := severityRank(a.Severity), severityRank(b.Severity); ra != rb {
+			return ra > rb
+		}
+		return len(a.Locations) > len(b.Locations)
+	})
+
+	breakdown := make(map[string]*review_models.FileIssueBreakdown)
+	var order []string
+	fileEntry := func(file string) *review_models.FileIssueBreakdown {
+		if entry, ok := breakdown[file]; ok {
+			return entry
+		}
+		entry := &review_models.FileIssueBreakdown{File: file, BySeverity: make(map[string]int)}
+		breakdown[file] = entry
+		order = append(order, file)
+		return entry
+	}
+	for _, file := range files {
+		fileEntry(file)
+	}
+
+	for _, issue := range review.Issues {
+		review.TotalIssues++
+		review.BySeverity[issue.Severity]++
+		if issue.CrossFile {
+			review.CrossFileConcerns++
+		}
+		for _, file := range locationFiles(issue.Locations) {
+			entry := fileEntry(file)
+			entry.IssueCount++
+			entry.BySeverity[issue.Severity]++
+		}
+	}
+	for _, file := range order {
+		review.Files = append(review.Files, *breakdown[file])
+	}
+
+	review.Summary = sessionReviewSummary(review, len(order))
+	return review
+}
+
+// addLocation appends loc unless the issue already has it. A whole-file
+// location is redundant once a line in that file is known, and vice versa.
+func addLocation(agg *review_models.AggregatedIssue, loc review_models.IssueLocation) {
+	if loc.File == "" {
+		return
+	}
+	for i, existing := range agg.Locations {
+		if existing.File != loc.File {
+			continue
+		}
+		if existing.Line == loc.Line || loc.Line == 0 {
+			return
+		}
+		if existing.Line == 0 {
+			agg.Locations[i].Line = loc.Line
+			return
+		}
+	}
+	agg.Locations = append(agg.Locations, loc)
+}
+
+// locationFiles returns the distinct files among locs in order.
+func locationFiles(locs []review_models.IssueLocation) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, loc := range locs {
+		if !seen[loc.File] {
+			seen[loc.File] = true
+			files = append(files, loc.File)
+		}
+	}
+	return files
+}
+
+// sessionReviewSummary describes the review in one sentence, e.g.
+// "3 unique issues across 2 files (1 critical, 2 high); 1 cross-file concern".
+func sessionReviewSummary(review *review_models.SessionReview, fileCount int) string {
+	if review.TotalIssues == 0 {
+		return "No issues found across " + countNoun(fileCount, "file")
+	}
+
+	var counts []string
+	for _, severity := range issueSeverities {
+		if n := review.BySeverity[severity]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, severity))
+		}
+	}
+	summary := countNoun(review.TotalIssues, "unique issue") + " across " + countNoun(fileCount, "file")
+	if len(counts) > 0 {
+		summary += " (" + strings.Join(counts, ", ") + ")"
+	}
+	if review.CrossFileConcerns > 0 {
+		summary += "; " + countNoun(review.CrossFileConcerns, "cross-file concern")
+	}
+	return summary
+}
+
+func countNoun(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
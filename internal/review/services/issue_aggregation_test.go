@@ -0,0 +1,80 @@
+package review_services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+func TestAggregateSessionIssues_MergesIdenticalIssues(t *testing.T) {
+	issues := []review_models.AnalysisIssue{
+		{File: "users.go", Line: 12, Severity: "medium", Category: "error-handling", Description: "Error from db.Query is ignored", Suggestion: "Check the error"},
+		{File: "orders.go", Line: 30, Severity: "low", Category: "style", Description: "Exported function lacks a doc comment"},
+		{File: "orders.go", Line: 48, Severity: "high", Category: "Error-Handling", Description: "error from  db.query is ignored."},
+	}
+
+	review := AggregateSessionIssues([]string{"users.go", "orders.go"}, issues)
+
+	require.Len(t, review.Issues, 2)
+	merged := review.Issues[0]
+	assert.Equal(t, "high", merged.Severity, "merged issue keeps the highest severity")
+	assert.Equal(t, "Error from db.Query is ignored", merged.Description)
+	assert.Equal(t, "Check the error", merged.Suggestion)
+	assert.Equal(t, []review_models.IssueLocation{{File: "users.go", Line: 12}, {File: "orders.go", Line: 48}}, merged.Locations)
+	assert.True(t, merged.CrossFile)
+	assert.False(t, review.Issues[1].CrossFile)
+
+	assert.Equal(t, 2, review.TotalIssues)
+	assert.Equal(t, 1, review.CrossFileConcerns)
+	assert.Equal(t, map[string]int{"high": 1, "low": 1}, review.BySeverity)
+	assert.Equal(t, "2 unique issues across 2 files (1 high, 1 low); 1 cross-file concern", review.Summary)
+
+	require.Len(t, review.Files, 2)
+	assert.Equal(t, review_models.FileIssueBreakdown{File: "users.go", IssueCount: 1, BySeverity: map[string]int{"high": 1}}, review.Files[0])
+	assert.Equal(t, review_models.FileIssueBreakdown{File: "orders.go", IssueCount: 2, BySeverity: map[string]int{"high": 1, "low": 1}}, review.Files[1])
+}
+
+func TestAggregateSessionIssues_RanksBySeverityThenSpread(t *testing.T) {
+	issues := []review_models.AnalysisIssue{
+		{File: "a.go", Severity: "low", Category: "style", Description: "naming"},
+		{File: "a.go", Severity: "medium", Category: "perf", Description: "allocation in loop"},
+		{File: "a.go", Severity: "critical", Category: "security", Description: "SQL injection"},
+		{File: "b.go", Severity: "medium", Category: "bug", Description: "nil map write"},
+		{File: "c.go", Severity: "medium", Category: "bug", Description: "nil map write"},
+	}
+
+	review := AggregateSessionIssues([]string{"a.go", "b.go", "c.go"}, issues)
+
+	var order []string
+	for _, issue := range review.Issues {
+		order = append(order, issue.Description)
+	}
+	assert.Equal(t, []string{"SQL injection", "nil map write", "allocation in loop", "naming"}, order)
+}
+
+func TestAggregateSessionIssues_RelatedFilesMakeCrossFileConcern(t *testing.T) {
+	issues := []review_models.AnalysisIssue{{
+		File: "memory_store.go", Line: 20, Severity: "high", Category: "architecture",
+		Description:  "MemoryStore.Get returns a value, but Store.Get declares a pointer",
+		RelatedFiles: []string{"store.go", "memory_store.go"},
+	}}
+
+	review := AggregateSessionIssues([]string{"store.go", "memory_store.go", "main.go"}, issues)
+
+	require.Len(t, review.Issues, 1)
+	assert.True(t, review.Issues[0].CrossFile)
+	assert.Equal(t, []review_models.IssueLocation{{File: "memory_store.go", Line: 20}, {File: "store.go"}}, review.Issues[0].Locations)
+	assert.Equal(t, 1, review.CrossFileConcerns)
+	assert.Equal(t, 0, review.Files[2].IssueCount, "files without issues are still listed")
+}
+
+func TestAggregateSessionIssues_NoIssues(t *testing.T) {
+	review := AggregateSessionIssues([]string{"a.go"}, nil)
+
+	assert.Empty(t, review.Issues)
+	assert.NotNil(t, review.Issues)
+	assert.Equal(t, "No issues found across 1 file", review.Summary)
+}
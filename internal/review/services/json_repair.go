@@ -0,0 +1,155 @@
+package review_services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// RepairJSON fixes the usual ways model output falls short of valid JSON:
+// a markdown code fence or prose around the value, trailing commas, raw
+// newlines inside strings, a missing closing bracket, and a response cut off
+// mid-object, which is closed after its last complete value. It returns an
+// error when the result still isn't valid JSON.
+func RepairJSON(text string) (string, error) {
+	text = stripCodeFence(text)
+	start := strings.IndexAny(text, "{[")
+	if start == -1 {
+		return "", errors.New("no JSON object or array in AI output")
+	}
+
+	var out []byte
+	var closers []byte // Expected closing brackets, innermost last
+	inString, escaped := false, false
+
+	// cutOut and cutClosers remember the state just before the last comma,
+	// the most recent point where everything written is a complete value
+	cutOut, cutClosers := -1, []byte(nil)
+
+	for i := start; i < len(text); i++ {
+		ch := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			case ch == '\n':
+				out = append(out, '\\', 'n')
+				continue
+			case ch == '\r' || ch == '\t':
+				out = append(out, ' ')
+				continue
+			}
+			out = append(out, ch)
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+			out = append(out, ch)
+		case '{':
+			closers = append(closers, '}')
+			out = append(out, ch)
+		case '[':
+			closers = append(closers, ']')
+			out = append(out, ch)
+		case '}', ']':
+			depth := strings.LastIndexByte(string(closers), ch)
+			if depth == -1 {
+				continue // Stray closer
+			}
+			// Close anything the model left open inside this container
+			for len(closers) > depth {
+				out = append(trimTrailingComma(out), closers[len(closers)-1])
+				closers = closers[:len(closers)-1]
+			}
+			if len(closers) == 0 {
+				return validJSON(out) // Ignore anything after the value
+			}
+		case ',':
+			cutOut, cutClosers = len(out), append([]byte(nil), closers...)
+			out = append(out, ch)
+		default:
+			out = append(out, ch)
+		}
+	}
+
+	// Truncated: close what is open, or failing that drop the incomplete
+	// trailing member and close from the last comma
+	if repaired, err := validJSON(closeJSON(out, closers, inString, escaped)); err == nil {
+		return repaired, nil
+	}
+	if cutOut == -1 {
+		return "", errors.New("AI output is not repairable JSON")
+	}
+	return validJSON(closeJSON(out[:cutOut], cutClosers, false, false))
+}
+
+// stripCodeFence removes a markdown fence opened before the JSON value
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	fence := strings.Index(text, "```")
+	if fence == -1 || fence > strings.IndexAny(text, "{[") {
+		return text
+	}
+	text = text[fence+3:]
+	if newline := strings.IndexByte(text, '\n'); newline != -1 {
+		text = text[newline+1:] // Language tag
+	}
+	text = strings.TrimSpace(text)
+	return strings.TrimSuffix(text, "```")
+}
+
+func closeJSON(out, closers []byte, inString, escaped bool) []byte {
+	out = append([]byte(nil), out...)
+	if inString {
+		if escaped {
+			out = out[:len(out)-1]
+		}
+		out = append(out, '"')
+	}
+	out = trimTrailingComma(out)
+	if len(out) > 0 && out[len(out)-1] == ':' {
+		out = append(out, "null"...)
+	}
+	for i := len(closers) - 1; i >= 0; i-- {
+		out = append(trimTrailingComma(out), closers[i])
+	}
+	return out
+}
+
+func trimTrailingComma(out []byte) []byte {
+	trimmed := strings.TrimRight(string(out), " \t\r\n")
+	return []byte(strings.TrimSuffix(trimmed, ","))
+}
+
+func validJSON(out []byte) (string, error) {
+	if !json.Valid(out) {
+		return "", errors.New("AI output is not repairable JSON")
+	}
+	return string(out), nil
+}
+
+// unmarshalAIOutput decodes jsonStr, the JSON extracted from raw AI output,
+// into a T. When that fails it retries with RepairJSON(raw); repaired
+// reports whether the repair was needed. The original decode error is
+// returned if repair doesn't help.
+func unmarshalAIOutput[T any](jsonStr, raw string) (output *T, repaired bool, err error) {
+	output = new(T)
+	if err = json.Unmarshal([]byte(jsonStr), output); err == nil {
+		return output, false, nil
+	}
+	fixed, repairErr := RepairJSON(raw)
+	if repairErr != nil {
+		return nil, false, err
+	}
+	output = new(T)
+	if json.Unmarshal([]byte(fixed), output) != nil {
+		return nil, false, err
+	}
+	return output, true, nil
+}
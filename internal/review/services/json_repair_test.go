@@ -0,0 +1,82 @@
+package review_services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepairJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"code fence", "```json\n{\"summary\": \"ok\"}\n```", `{"summary": "ok"}`},
+		{"prose around value", "Here is the analysis:\n{\"summary\": \"ok\"}\nLet me know!", `{"summary": "ok"}`},
+		{"trailing commas", `{"functions": [{"name": "a",}, ], "summary": "ok",}`, `{"functions": [{"name": "a"}], "summary": "ok"}`},
+		{"raw newline in string", "{\"summary\": \"line one\nline two\"}", `{"summary": "line one\nline two"}`},
+		{"missing inner closer", `{"functions": [{"name": "a"}}`, `{"functions": [{"name": "a"}]}`},
+		{"truncated in string", `{"summary": "ok", "functions": [{"name": "hand`, `{"summary": "ok", "functions": [{"name": "hand"}]}`},
+		{"truncated after colon", `{"summary": "ok", "grade":`, `{"summary": "ok", "grade":null}`},
+		{"truncated mid key", `{"summary": "ok", "gra`, `{"summary": "ok"}`},
+		{"truncated mid literal", `{"summary": "ok", "done": tr`, `{"summary": "ok"}`},
+		{"fenced and truncated", "```json\n{\"issues\": [{\"line\": 3},\n", `{"issues": [{"line": 3}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RepairJSON(tt.in)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, got)
+		})
+	}
+}
+
+func TestRepairJSON_Unrecoverable(t *testing.T) {
+	for _, in := range []string{"", "I could not analyze this code.", `{"summary" "ok"}`} {
+		_, err := RepairJSON(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestUnmarshalAIOutput(t *testing.T) {
+	raw := "```json\n{\"summary\": \"Parses config\", \"functions\": [{\"name\": \"Load\", \"description\": \"reads env\",},],}\n```"
+	jsonStr, err := ExtractJSON(raw)
+	require.NoError(t, err)
+
+	output, repaired, err := unmarshalAIOutput[review_models.SkimModeOutput](jsonStr, raw)
+	require.NoError(t, err)
+	assert.True(t, repaired)
+	assert.Equal(t, "Parses config", output.Summary)
+	require.Len(t, output.Functions, 1)
+	assert.Equal(t, "Load", output.Functions[0].Name)
+
+	valid := `{"summary": "ok"}`
+	_, repaired, err = unmarshalAIOutput[review_models.SkimModeOutput](valid, valid)
+	require.NoError(t, err)
+	assert.False(t, repaired, "valid output is not reported as repaired")
+
+	_, _, err = unmarshalAIOutput[review_models.SkimModeOutput]("not json", "not json")
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr, "the original decode error is returned")
+}
+
+func TestCriticalService_RepairsTruncatedOutput(t *testing.T) {
+	raw := `{"summary": "Two problems", "overall_grade": "C", "issues": [` +
+		`{"severity": "high", "category": "security", "line": 4, "description": "SQL built by concatenation"},` +
+		`{"severity": "low", "category": "style", "line": 9, "descrip`
+	svc := NewCriticalService(&mockOllama{resp: raw}, &testutils.MockAnalysisRepository{}, &nopLogger{})
+
+	output, err := svc.AnalyzeCritical(context.Background(), "package main")
+	require.NoError(t, err)
+	assert.Equal(t, "Two problems", output.Summary)
+	require.Len(t, output.Issues, 2)
+	assert.Equal(t, "SQL built by concatenation", output.Issues[0].Description)
+	assert.Equal(t, 9, output.Issues[1].Line, "complete fields of the cut-off issue are kept")
+	assert.Empty(t, output.Issues[1].Description)
+}
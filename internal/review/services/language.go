@@ -0,0 +1,192 @@
+package review_services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+)
+
+// LanguageUnknown is reported when no language can be determined.
+const LanguageUnknown = "unknown"
+
+// minLanguageConfidence is the confidence below which a keyword guess is
+// reported as unknown.
+const minLanguageConfidence = 0.5
+
+// DetectedLanguage is the result of DetectLanguage. Confidence is in [0, 1].
+type DetectedLanguage struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// languageExtensions maps file extensions to language names.
+var languageExtensions = map[string]string{
+	".go":   "Go",
+	".py":   "Python",
+	".js":   "JavaScript",
+	".mjs":  "JavaScript",
+	".cjs":  "JavaScript",
+	".jsx":  "JavaScript",
+	".ts":   "TypeScript",
+	".tsx":  "TypeScript",
+	".java": "Java",
+	".rs":   "Rust",
+	".rb":   "Ruby",
+	".php":  "PHP",
+	".cs":   "C#",
+	".c":    "C",
+	".h":    "C",
+	".cpp":  "C++",
+	".cc":   "C++",
+	".hpp":  "C++",
+	".sql":  "SQL",
+	".sh":   "Shell",
+	".bash": "Shell",
+}
+
+// SupportedLanguage returns the canonical name of lang if it is one of the
+// languages DetectLanguage can report, matching case-insensitively. Only
+// these names are ever put into a prompt, so a client-supplied hint cannot
+// smuggle instructions to the model.
+func SupportedLanguage(lang string) (string, bool) {
+	lang = strings.TrimSpace(lang)
+	for _, name := range languageExtensions {
+		if strings.EqualFold(name, lang) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// languageSignature is a pattern typical of a language and how strongly it
+// points to it.
+type languageSignature struct {
+	pattern *regexp.Regexp
+	weight  int
+}
+
+// languageSignatures holds keyword heuristics per language. Patterns anchor
+// on syntax rather than words so prose rarely matches.
+var languageSignatures = map[string][]languageSignature{
+	"Go": {
+		{regexp.MustCompile(`(?m)^package \w+\s*$`), 4},
+		{regexp.MustCompile(`(?m)^func (\(\w+ \*?\w+\) )?\w+\(`), 3},
+		{regexp.MustCompile(`:= `), 2},
+		{regexp.MustCompile(`if err != nil`), 3},
+		{regexp.MustCompile(`(?m)^import \($`), 2},
+		{regexp.MustCompile(`\bfmt\.\w+\(`), 1},
+		{regexp.MustCompile(`(?m)^type \w+ (struct|interface) \{`), 3},
+	},
+	"Python": {
+		{regexp.MustCompile(`(?m)^\s*def \w+\(.*\)( -> [\w\[\], .]+)?:\s*$`), 4},
+		{regexp.MustCompile(`(?m)^\s*class \w+(\(.*\))?:\s*$`), 3},
+		{regexp.MustCompile(`(?m)^(from [\w.]+ )?import [\w., ]+$`), 2},
+		{regexp.MustCompile(`\bself\.\w+`), 2},
+		{regexp.MustCompile(`(?m)^\s*(elif|except|finally)\b.*:\s*$`), 2},
+		{regexp.MustCompile(`__name__ == ['"]__main__['"]`), 3},
+		{regexp.MustCompile(`\bNone\b|\bTrue\b|\bFalse\b`), 1},
+	},
+	"JavaScript": {
+		{regexp.MustCompile(`\b(const|let|var) \w+ = `), 2},
+		{regexp.MustCompile(`\bfunction\s*\w*\s*\(`), 2},
+		{regexp.MustCompile(`=>\s*[{(]?`), 2},
+		{regexp.MustCompile(`\brequire\(['"][\w@/.-]+['"]\)`), 3},
+		{regexp.MustCompile(`(?m)^import .* from ['"][\w@/.-]+['"];?$`), 3},
+		{regexp.MustCompile(`\bconsole\.\w+\(`), 3},
+		{regexp.MustCompile(`(?m)^(module\.)?exports\b|^export (default )?`), 2},
+		{regexp.MustCompile(`===|!==`), 2},
+	},
+	"Java": {
+		{regexp.MustCompile(`\b(public|private|protected) (static )?(final )?(class|void|[\w<>\[\]]+) \w+`), 3},
+		{regexp.MustCompile(`\bSystem\.out\.print`), 3},
+		{regexp.MustCompile(`(?m)^import java\.`), 4},
+		{regexp.MustCompile(`(?m)^package [\w.]+;$`), 4},
+	},
+	"Rust": {
+		{regexp.MustCompile(`\bfn \w+(<.*>)?\(`), 3},
+		{regexp.MustCompile(`\blet mut \w+`), 3},
+		{regexp.MustCompile(`(?m)^use [\w:]+(::\{.*\})?;$`), 3},
+		{regexp.MustCompile(`\bimpl\b.*\{`), 2},
+		{regexp.MustCompile(`println!\(`), 3},
+	},
+	"Ruby": {
+		{regexp.MustCompile(`(?m)^\s*def \w+[?!]?(\(.*\))?\s*$`), 3},
+		{regexp.MustCompile(`(?m)^\s*end\s*$`), 2},
+		{regexp.MustCompile(`\brequire ['"][\w/]+['"]`), 3},
+		{regexp.MustCompile(`\bputs\b`), 2},
+	},
+	"PHP": {
+		{regexp.MustCompile(`<\?php`), 6},
+		{regexp.MustCompile(`\$\w+\s*=`), 2},
+		{regexp.MustCompile(`\becho\b`), 1},
+	},
+	"SQL": {
+		{regexp.MustCompile(`(?i)\bSELECT\b[\s\S]+\bFROM\b`), 3},
+		{regexp.MustCompile(`(?i)\b(INSERT INTO|CREATE TABLE|ALTER TABLE|UPDATE \w+ SET)\b`), 3},
+	},
+	"Shell": {
+		{regexp.MustCompile(`^#!/(usr/)?bin/(env )?(ba|z)?sh`), 6},
+		{regexp.MustCompile(`(?m)^\s*(fi|done|esac)\s*$`), 2},
+		{regexp.MustCompile(`\$\{?\w+\}?`), 1},
+	},
+}
+
+// DetectLanguage guesses the programming language of code. A recognised
+// filename extension wins; otherwise keyword heuristics are scored and the
+// best match returned. Inputs that match nothing convincingly (such as prose)
+// return LanguageUnknown with low confidence; detection never fails.
+func DetectLanguage(code, filename string) DetectedLanguage {
+	if lang, ok := languageExtensions[strings.ToLower(filepath.Ext(filename))]; ok {
+		return DetectedLanguage{Name: lang, Confidence: 0.95}
+	}
+	if IsUnifiedDiff(code) {
+		if files, err := ParseUnifiedDiff(code); err == nil {
+			if lang, ok := languageExtensions[strings.ToLower(filepath.Ext(files[0].Path()))]; ok {
+				return DetectedLanguage{Name: lang, Confidence: 0.9}
+			}
+		}
+	}
+
+	best, bestScore, total := LanguageUnknown, 0, 0
+	for lang, signatures := range languageSignatures {
+		score := 0
+		for _, sig := range signatures {
+			if sig.pattern.MatchString(code) {
+				score += sig.weight
+			}
+		}
+		total += score
+		if score > bestScore || (score == bestScore && score > 0 && lang < best) {
+			best, bestScore = lang, score
+		}
+	}
+	if bestScore == 0 {
+		return DetectedLanguage{Name: LanguageUnknown}
+	}
+
+	// Share of all matched evidence, damped until there is enough of it
+	confidence := float64(bestScore) / float64(total)
+	if bestScore < 6 {
+		confidence *= float64(bestScore) / 6
+	}
+	if confidence < minLanguageConfidence {
+		return DetectedLanguage{Name: LanguageUnknown, Confidence: confidence}
+	}
+	return DetectedLanguage{Name: best, Confidence: confidence}
+}
+
+// withLanguageHint appends the language set on ctx to a prompt so the model
+// judges the code by that language's idioms. Unknown and unsupported
+// languages add nothing.
+func withLanguageHint(ctx context.Context, prompt string) string {
+	lang, _ := ctx.Value(reviewcontext.LanguageContextKey).(string)
+	lang, ok := SupportedLanguage(lang)
+	if !ok {
+		return prompt
+	}
+	return prompt + fmt.Sprintf("\n\nLANGUAGE: The code is %s. Use %s terminology and judge it by %s idioms and conventions.", lang, lang, lang)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
@@ -29,10 +30,20 @@ type OllamaTagsResponse struct {
 	Models []OllamaModel `json:"models"`
 }
 
+// DefaultModelCacheTTL is how long the model list is served from memory
+// before Ollama is queried again.
+const DefaultModelCacheTTL = 60 * time.Second
+
 // ModelService queries Ollama for available models
 type ModelService struct {
 	logger         logger.Interface
 	ollamaEndpoint string
+	cacheTTL       time.Duration
+	now            func() time.Time
+
+	mu       sync.Mutex
+	cached   []ModelInfo
+	cachedAt time.Time
 }
 
 // NewModelService creates a ModelService instance
@@ -43,9 +54,19 @@ func NewModelService(logger logger.Interface, ollamaEndpoint string) *ModelServi
 	return &ModelService{
 		logger:         logger,
 		ollamaEndpoint: ollamaEndpoint,
+		cacheTTL:       DefaultModelCacheTTL,
+		now:            time.Now,
 	}
 }
 
+// SetCacheTTL changes how long the model list is cached. Zero or less
+// disables caching.
+func (s *ModelService) SetCacheTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cacheTTL = ttl
+}
+
 // ListAvailableModels queries Ollama HTTP API and returns available models
 func (s *ModelService) ListAvailableModels(ctx context.Context) ([]ModelInfo, error) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -125,9 +146,36 @@ func (s *ModelService) fallbackModels() []ModelInfo {
 	}
 }
 
-// ListAvailableModelsJSON returns models as JSON (for API handler)
-func (s *ModelService) ListAvailableModelsJSON(ctx context.Context) ([]byte, error) {
+// CachedModels returns the model list, querying Ollama only when the cached
+// list is older than the cache TTL or force is set. If the refresh fails the
+// last list Ollama returned is served instead of the fallback. Concurrent
+// callers wait for a single refresh rather than each querying Ollama.
+func (s *ModelService) CachedModels(ctx context.Context, force bool) ([]ModelInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !force && s.cached != nil && s.now().Sub(s.cachedAt) < s.cacheTTL {
+		return s.cached, nil
+	}
+
 	models, err := s.ListAvailableModels(ctx)
+	if err != nil {
+		if s.cached != nil {
+			s.logger.Warn("Serving cached models after Ollama refresh failed",
+				"error", err.Error(), "age", s.now().Sub(s.cachedAt).String())
+			return s.cached, nil
+		}
+		return models, err
+	}
+
+	s.cached, s.cachedAt = models, s.now()
+	return models, nil
+}
+
+// ListAvailableModelsJSON returns models as JSON (for API handler).
+// force bypasses the cache.
+func (s *ModelService) ListAvailableModelsJSON(ctx context.Context, force bool) ([]byte, error) {
+	models, err := s.CachedModels(ctx, force)
 	if err != nil {
 		s.logger.Warn("Using fallback models due to error", "error", err.Error())
 	}
@@ -0,0 +1,122 @@
+package review_services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOllamaTags serves /api/tags from models, or a 500 while failing is set.
+type fakeOllamaTags struct {
+	calls   atomic.Int32
+	failing atomic.Bool
+	models  []string
+}
+
+func (f *fakeOllamaTags) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	f.calls.Add(1)
+	if f.failing.Load() {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var resp OllamaTagsResponse
+	for _, name := range f.models {
+		resp.Models = append(resp.Models, OllamaModel{Name: name})
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func newCachedModelService(t *testing.T, ollama *fakeOllamaTags) (*ModelService, *time.Time) {
+	t.Helper()
+	server := httptest.NewServer(ollama)
+	t.Cleanup(server.Close)
+
+	clock := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+	svc := NewModelService(&nopLogger{}, server.URL)
+	svc.now = func() time.Time { return clock }
+	return svc, &clock
+}
+
+func modelNames(models []ModelInfo) []string {
+	names := make([]string, 0, len(models))
+	for _, m := range models {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+func TestModelService_CachedModels_TTL(t *testing.T) {
+	ollama := &fakeOllamaTags{models: []string{"mistral:7b-instruct", "codellama:13b"}}
+	svc, clock := newCachedModelService(t, ollama)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		models, err := svc.CachedModels(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mistral:7b-instruct", "codellama:13b"}, modelNames(models))
+	}
+	assert.Equal(t, int32(1), ollama.calls.Load(), "Ollama is queried once within the TTL")
+
+	*clock = clock.Add(DefaultModelCacheTTL - time.Second)
+	_, err := svc.CachedModels(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), ollama.calls.Load())
+
+	*clock = clock.Add(2 * time.Second)
+	_, err = svc.CachedModels(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), ollama.calls.Load(), "Ollama is queried again after expiry")
+}
+
+func TestModelService_CachedModels_Force(t *testing.T) {
+	ollama := &fakeOllamaTags{models: []string{"mistral:7b-instruct"}}
+	svc, _ := newCachedModelService(t, ollama)
+	ctx := context.Background()
+
+	_, err := svc.CachedModels(ctx, false)
+	require.NoError(t, err)
+
+	ollama.models = []string{"mistral:7b-instruct", "qwen2.5-coder:7b"}
+	models, err := svc.CachedModels(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), ollama.calls.Load())
+	assert.Equal(t, []string{"mistral:7b-instruct", "qwen2.5-coder:7b"}, modelNames(models))
+}
+
+func TestModelService_CachedModels_StaleOnError(t *testing.T) {
+	ollama := &fakeOllamaTags{models: []string{"mistral:7b-instruct", "deepseek-coder:6.7b"}}
+	svc, clock := newCachedModelService(t, ollama)
+	ctx := context.Background()
+
+	_, err := svc.CachedModels(ctx, false)
+	require.NoError(t, err)
+
+	ollama.failing.Store(true)
+	*clock = clock.Add(2 * DefaultModelCacheTTL)
+	models, err := svc.CachedModels(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), ollama.calls.Load(), "refresh was attempted")
+	assert.Equal(t, []string{"mistral:7b-instruct", "deepseek-coder:6.7b"}, modelNames(models),
+		"last known list is served instead of the fallback")
+
+	data, err := svc.ListAvailableModelsJSON(ctx, true)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "deepseek-coder:6.7b")
+}
+
+func TestModelService_CachedModels_FallbackWithoutCache(t *testing.T) {
+	ollama := &fakeOllamaTags{}
+	ollama.failing.Store(true)
+	svc, _ := newCachedModelService(t, ollama)
+
+	models, err := svc.CachedModels(context.Background(), false)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"mistral:7b-instruct"}, modelNames(models))
+}
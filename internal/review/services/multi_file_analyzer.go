@@ -46,9 +46,12 @@ type AnalyzeResult struct {
 	SharedAbstractions   []review_models.SharedAbstraction
 	ArchitecturePatterns []review_models.ArchitecturePattern
 	Recommendations      []string
-	DurationMs           int64
-	InputTokens          int
-	OutputTokens         int
+	Issues               []review_models.AnalysisIssue
+	// SessionReview aggregates Issues across all files (see AggregateSessionIssues)
+	SessionReview *review_models.SessionReview
+	DurationMs    int64
+	InputTokens   int
+	OutputTokens  int
 }
 
 // Analyze performs cross-file analysis using AI
@@ -81,6 +84,13 @@ func (m *MultiFileAnalyzer) Analyze(ctx context.Context, req *AnalyzeRequest) (*
 		}
 	}
 
+	// Cross-file aggregation pass over the per-file issues
+	paths := make([]string, 0, len(req.Files))
+	for _, file := range req.Files {
+		paths = append(paths, file.Path)
+	}
+	result.SessionReview = AggregateSessionIssues(paths, result.Issues)
+
 	// Add timing and token metrics
 	result.DurationMs = time.Since(startTime).Milliseconds()
 	result.InputTokens = aiResp.InputTokens
@@ -129,8 +139,11 @@ func (m *MultiFileAnalyzer) buildCombinedPrompt(files []FileContent, readingMode
 	sb.WriteString("  \"dependencies\": [{\"from_file\": \"path/a.go\", \"to_file\": \"path/b.go\", \"import_type\": \"import\", \"symbols\": [\"TypeName\"]}],\n")
 	sb.WriteString("  \"shared_abstractions\": [{\"name\": \"Interface\", \"type\": \"interface\", \"files\": [\"a.go\", \"b.go\"], \"description\": \"Purpose\"}],\n")
 	sb.WriteString("  \"architecture_patterns\": [{\"pattern\": \"MVC\", \"confidence\": 0.85, \"files\": [\"all\"], \"description\": \"Pattern details\"}],\n")
-	sb.WriteString("  \"recommendations\": [\"Suggestion 1\", \"Suggestion 2\"]\n")
+	sb.WriteString("  \"recommendations\": [\"Suggestion 1\", \"Suggestion 2\"],\n")
+	sb.WriteString("  \"issues\": [{\"file\": \"path/b.go\", \"line\": 42, \"severity\": \"high\", \"category\": \"architecture\", \"description\": \"Problem\", \"suggestion\": \"Fix\", \"related_files\": [\"path/a.go\"]}]\n")
 	sb.WriteString("}\n")
+	sb.WriteString("Report each issue once per file it occurs in, using the same description each time. ")
+	sb.WriteString("For problems spanning files, such as an interface defined in one file and violated in another, set related_files to the other files involved.\n")
 
 	return sb.String()
 }
@@ -154,6 +167,7 @@ func (m *MultiFileAnalyzer) parseAIResponse(content string) (*AnalyzeResult, err
 		SharedAbstractions   []review_models.SharedAbstraction   `json:"shared_abstractions"`
 		ArchitecturePatterns []review_models.ArchitecturePattern `json:"architecture_patterns"`
 		Recommendations      []string                            `json:"recommendations"`
+		Issues               []review_models.AnalysisIssue       `json:"issues"`
 	}
 
 	if err := json.Unmarshal([]byte(jsonContent), &parsed); err != nil {
@@ -166,6 +180,7 @@ func (m *MultiFileAnalyzer) parseAIResponse(content string) (*AnalyzeResult, err
 		SharedAbstractions:   parsed.SharedAbstractions,
 		ArchitecturePatterns: parsed.ArchitecturePatterns,
 		Recommendations:      parsed.Recommendations,
+		Issues:               parsed.Issues,
 	}
 
 	// Ensure slices are not nil
@@ -181,6 +196,9 @@ func (m *MultiFileAnalyzer) parseAIResponse(content string) (*AnalyzeResult, err
 	if result.Recommendations == nil {
 		result.Recommendations = []string{}
 	}
+	if result.Issues == nil {
+		result.Issues = []review_models.AnalysisIssue{}
+	}
 
 	return result, nil
 }
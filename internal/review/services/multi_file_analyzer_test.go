@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
 )
 
 // mockAIProvider implements ai.Provider for testing
@@ -398,3 +399,31 @@ func TestMultiFileAnalyzer_RealWorldStructure(t *testing.T) {
 	assert.Equal(t, "Layered Architecture", result.ArchitecturePatterns[0].Pattern)
 	assert.Greater(t, result.ArchitecturePatterns[0].Confidence, 0.9)
 }
+
+func TestMultiFileAnalyzer_Analyze_AggregatesIssuesAcrossFiles(t *testing.T) {
+	mockAI := &mockAIProvider{responseContent: `{
+		"summary": "Two repositories sharing a database handle",
+		"issues": [
+			{"file": "users.go", "line": 14, "severity": "high", "category": "error-handling", "description": "rows.Close error is ignored"},
+			{"file": "orders.go", "line": 27, "severity": "high", "category": "error-handling", "description": "rows.Close error is ignored"}
+		]
+	}`}
+	analyzer := NewMultiFileAnalyzer(mockAI, "test-model")
+
+	result, err := analyzer.Analyze(context.Background(), &AnalyzeRequest{
+		Files:       []FileContent{{Path: "users.go", Content: "package repo"}, {Path: "orders.go", Content: "package repo"}},
+		ReadingMode: "critical",
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, result.Issues, 2, "raw per-file issues are kept")
+	require.NotNil(t, result.SessionReview)
+	require.Len(t, result.SessionReview.Issues, 1, "the shared issue is reported once at session level")
+	issue := result.SessionReview.Issues[0]
+	assert.Equal(t, "rows.Close error is ignored", issue.Description)
+	assert.ElementsMatch(t, []review_models.IssueLocation{{File: "users.go", Line: 14}, {File: "orders.go", Line: 27}}, issue.Locations)
+	assert.True(t, issue.CrossFile)
+	require.Len(t, result.SessionReview.Files, 2)
+	assert.Equal(t, 1, result.SessionReview.Files[0].IssueCount)
+	assert.Equal(t, 1, result.SessionReview.Files[1].IssueCount)
+}
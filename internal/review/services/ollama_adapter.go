@@ -7,7 +7,6 @@ import (
 	"os"
 
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
-	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai/providers"
 	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
 )
 
@@ -15,15 +14,15 @@ const (
 	defaultOllamaModel = "mistral:7b-instruct" // Fallback if context empty
 )
 
-// OllamaClientAdapter implements OllamaClientInterface by wrapping providers.OllamaClient
-// This adapter bridges the gap between the complex ai.Request/Response interface
+// OllamaClientAdapter implements OllamaClientInterface by wrapping an Ollama
+// provider (a providers.OllamaClient or providers.OllamaPool). This adapter bridges the gap between the complex ai.Request/Response interface
 // and the simpler string-based interface used by review services.
 type OllamaClientAdapter struct {
-	client *providers.OllamaClient
+	client ai.Provider
 }
 
-// NewOllamaClientAdapter creates a new adapter wrapping an OllamaClient
-func NewOllamaClientAdapter(client *providers.OllamaClient) *OllamaClientAdapter {
+// NewOllamaClientAdapter creates a new adapter wrapping an Ollama provider
+func NewOllamaClientAdapter(client ai.Provider) *OllamaClientAdapter {
 	return &OllamaClientAdapter{
 		client: client,
 	}
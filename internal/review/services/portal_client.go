@@ -1,18 +1,21 @@
 package review_services
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"time"
 )
 
 // PortalClient handles communication with the Portal service's AI Factory API
 type PortalClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL      string
+	serviceToken string
+	httpClient   *http.Client
 }
 
 // NewPortalClient creates a new Portal API client
@@ -25,6 +28,22 @@ func NewPortalClient(portalURL string) *PortalClient {
 	}
 }
 
+// SetServiceToken sets the shared secret Portal requires before it returns
+// decrypted API keys. Without it resolved configs carry no key.
+func (c *PortalClient) SetServiceToken(token string) {
+	c.serviceToken = token
+}
+
+// PortalError is a non-200 response from Portal's API
+type PortalError struct {
+	Body       string
+	StatusCode int
+}
+
+func (e *PortalError) Error() string {
+	return fmt.Sprintf("Portal API returned %d: %s", e.StatusCode, e.Body)
+}
+
 // LLMConfig represents an AI model configuration from Portal's AI Factory
 type LLMConfig struct {
 	ID          string  `json:"id"`
@@ -32,27 +51,27 @@ type LLMConfig struct {
 	Provider    string  `json:"provider"`
 	ModelName   string  `json:"model_name"`
 	APIEndpoint string  `json:"api_endpoint,omitempty"`
-	APIKey      string  `json:"api_key,omitempty"` // Decrypted by Portal
+	APIKey      string  `json:"api_key,omitempty"` // Decrypted by Portal; only sent with the service token
+	HasAPIKey   bool    `json:"has_api_key"`
 	IsDefault   bool    `json:"is_default"`
 	MaxTokens   int     `json:"max_tokens"`
 	Temperature float64 `json:"temperature"`
-}
-
-// AppPreferencesResponse is the response from Portal's app preferences endpoint
-type AppPreferencesResponse struct {
-	Review    *LLMConfig `json:"review"`
-	Logs      *LLMConfig `json:"logs"`
-	Analytics *LLMConfig `json:"analytics"`
+	Source      string  `json:"source,omitempty"` // app_preference, user_default or system_default
 }
 
 // GetEffectiveConfigForApp fetches the user's effective LLM configuration for a specific app
-// This respects the user's AI Factory settings: app-specific preference > default > system default
+// Portal resolves app-specific preference > default > system default. With a
+// service token the internal route is used, which also decrypts the API key
 func (c *PortalClient) GetEffectiveConfigForApp(ctx context.Context, sessionToken, appName string) (*LLMConfig, error) {
 	// Build request URL
-	url := fmt.Sprintf("%s/api/portal/app-llm-preferences", c.baseURL)
+	path := "/api/portal/app-llm-preferences/%s/resolved"
+	if c.serviceToken != "" {
+		path = "/api/portal/internal/app-llm-preferences/%s/resolved"
+	}
+	url := c.baseURL + fmt.Sprintf(path, neturl.PathEscape(appName))
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -62,6 +81,9 @@ func (c *PortalClient) GetEffectiveConfigForApp(ctx context.Context, sessionToke
 		Name:  "session_token",
 		Value: sessionToken,
 	})
+	if c.serviceToken != "" {
+		req.Header.Set("X-Service-Token", c.serviceToken)
+	}
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
@@ -73,31 +95,99 @@ func (c *PortalClient) GetEffectiveConfigForApp(ctx context.Context, sessionToke
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Portal API returned %d: %s", resp.StatusCode, string(body))
+		return nil, &PortalError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Parse response
-	var prefsResp AppPreferencesResponse
-	if err := json.NewDecoder(resp.Body).Decode(&prefsResp); err != nil {
+	var config LLMConfig
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Extract config for requested app
-	var config *LLMConfig
-	switch appName {
-	case "review":
-		config = prefsResp.Review
-	case "logs":
-		config = prefsResp.Logs
-	case "analytics":
-		config = prefsResp.Analytics
-	default:
-		return nil, fmt.Errorf("unknown app name: %s", appName)
+	return &config, nil
+}
+
+// BudgetStatus is the user's monthly AI budget from Portal
+type BudgetStatus struct {
+	MonthlyLimitUSD *float64 `json:"monthly_limit_usd"` // nil means unlimited
+	SpentUSD        float64  `json:"spent_usd"`
+	Exceeded        bool     `json:"exceeded"`
+}
+
+// GetBudgetStatus fetches the user's monthly AI budget and spend so far
+func (c *PortalClient) GetBudgetStatus(ctx context.Context, sessionToken string) (*BudgetStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/api/portal/llm/budget", http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Portal API: %w", err)
 	}
+	defer resp.Body.Close()
 
-	if config == nil {
-		return nil, fmt.Errorf("no LLM configuration found for app: %s. Please configure a model in AI Factory", appName)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &PortalError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	return config, nil
+	var status BudgetStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &status, nil
+}
+
+// GetAppPreferences decodes the user's saved settings for an app into dst.
+// Portal returns {} when nothing has been saved.
+func (c *PortalClient) GetAppPreferences(ctx context.Context, sessionToken, appName string, dst interface{}) error {
+	url := fmt.Sprintf("%s/api/portal/app-preferences/%s", c.baseURL, neturl.PathEscape(appName))
+	return c.doPreferences(ctx, http.MethodGet, url, sessionToken, http.NoBody, dst)
+}
+
+// SetAppPreferences replaces the user's saved settings for an app
+func (c *PortalClient) SetAppPreferences(ctx context.Context, sessionToken, appName string, preferences interface{}) error {
+	body, err := json.Marshal(preferences)
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %w", err)
+	}
+	url := fmt.Sprintf("%s/api/portal/app-preferences/%s", c.baseURL, neturl.PathEscape(appName))
+	return c.doPreferences(ctx, http.MethodPut, url, sessionToken, bytes.NewReader(body), nil)
+}
+
+func (c *PortalClient) doPreferences(ctx context.Context, method, url, sessionToken string, body io.Reader, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.AddCookie(&http.Cookie{
+		Name:  "session_token",
+		Value: sessionToken,
+	})
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Portal API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return &PortalError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	if dst == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
 }
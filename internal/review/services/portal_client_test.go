@@ -0,0 +1,94 @@
+package review_services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortalClient_GetEffectiveConfigForApp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/portal/app-llm-preferences/review/resolved", r.URL.Path)
+		cookie, err := r.Cookie("session_token")
+		require.NoError(t, err)
+		assert.Equal(t, "token-123", cookie.Value)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"cfg-1","user_id":7,"provider":"anthropic","model_name":"claude-3-5-sonnet",` +
+			`"api_key":"sk-ant-123","max_tokens":4096,"temperature":0.7,"source":"app_preference"}`))
+	}))
+	defer server.Close()
+
+	config, err := NewPortalClient(server.URL).GetEffectiveConfigForApp(context.Background(), "token-123", "review")
+
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic", config.Provider)
+	assert.Equal(t, "claude-3-5-sonnet", config.ModelName)
+	assert.Equal(t, "sk-ant-123", config.APIKey)
+	assert.Equal(t, "app_preference", config.Source)
+}
+
+func TestPortalClient_GetEffectiveConfigForApp_ServiceToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/portal/internal/app-llm-preferences/review/resolved", r.URL.Path)
+		assert.Equal(t, "svc-secret", r.Header.Get("X-Service-Token"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"cfg-1","provider":"anthropic","api_key":"sk-ant-123","has_api_key":true}`))
+	}))
+	defer server.Close()
+
+	client := NewPortalClient(server.URL)
+	client.SetServiceToken("svc-secret")
+	config, err := client.GetEffectiveConfigForApp(context.Background(), "token-123", "review")
+
+	require.NoError(t, err)
+	assert.Equal(t, "sk-ant-123", config.APIKey)
+	assert.True(t, config.HasAPIKey)
+}
+
+func TestPortalClient_GetEffectiveConfigForAppError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := NewPortalClient(server.URL).GetEffectiveConfigForApp(context.Background(), "expired", "review")
+
+	assert.ErrorContains(t, err, "401")
+}
+
+func TestPortalPreferenceStore(t *testing.T) {
+	saved := []byte(`{}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/portal/app-preferences/review", r.URL.Path)
+		cookie, err := r.Cookie("session_token")
+		require.NoError(t, err)
+		assert.Equal(t, "token-123", cookie.Value)
+
+		if r.Method == http.MethodPut {
+			saved, _ = io.ReadAll(r.Body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(saved)
+	}))
+	defer server.Close()
+	store := NewPortalPreferenceStore(server.URL)
+	ctx := context.Background()
+
+	prefs, err := store.GetPreferences(ctx, "token-123")
+	require.NoError(t, err)
+	assert.Equal(t, review_models.ReviewPreferences{}, *prefs)
+
+	want := review_models.ReviewPreferences{DefaultMode: "critical", UserMode: "expert", Model: "llama3"}
+	require.NoError(t, store.SavePreferences(ctx, "token-123", &want))
+	prefs, err = store.GetPreferences(ctx, "token-123")
+	require.NoError(t, err)
+	assert.Equal(t, want, *prefs)
+}
@@ -0,0 +1,35 @@
+package review_services
+
+import (
+	"context"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// reviewPreferencesApp is the app name Review's settings are stored under
+const reviewPreferencesApp = "review"
+
+// PortalPreferenceStore keeps review preferences in Portal's per-app
+// settings, so they follow the user across devices and sessions.
+type PortalPreferenceStore struct {
+	portal *PortalClient
+}
+
+// NewPortalPreferenceStore creates a store backed by the Portal at portalURL
+func NewPortalPreferenceStore(portalURL string) *PortalPreferenceStore {
+	return &PortalPreferenceStore{portal: NewPortalClient(portalURL)}
+}
+
+// GetPreferences returns the user's saved preferences, empty if none
+func (s *PortalPreferenceStore) GetPreferences(ctx context.Context, sessionToken string) (*review_models.ReviewPreferences, error) {
+	var prefs review_models.ReviewPreferences
+	if err := s.portal.GetAppPreferences(ctx, sessionToken, reviewPreferencesApp, &prefs); err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// SavePreferences replaces the user's saved preferences
+func (s *PortalPreferenceStore) SavePreferences(ctx context.Context, sessionToken string, prefs *review_models.ReviewPreferences) error {
+	return s.portal.SetAppPreferences(ctx, sessionToken, reviewPreferencesApp, prefs)
+}
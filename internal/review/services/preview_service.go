@@ -2,7 +2,6 @@ package review_services
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"time"
 
@@ -50,9 +49,19 @@ func (s *PreviewService) AnalyzePreview(ctx context.Context, code, userMode, out
 	s.logger.Info("AnalyzePreview called", "correlation_id", correlationID, "code_length", len(code), "user_mode", userMode, "output_mode", outputMode)
 
 	// Build prompt using template with user/output modes
-	prompt := BuildPreviewPrompt(code, userMode, outputMode)
+	prompt := withLanguageHint(ctx, BuildPreviewPrompt(code, userMode, outputMode))
+	injection := flagPromptInjection(ctx, s.logger, span, "PreviewService", code)
 	span.SetAttributes(attribute.Int("prompt_length", len(prompt)))
 
+	estimate, sizeErr := checkPromptSize(ctx, prompt)
+	span.SetAttributes(attribute.Int("prompt_tokens_est", estimate.PromptTokens))
+	if sizeErr != nil {
+		s.logger.Warn("PreviewService: input too large for model", "correlation_id", correlationID, "error", sizeErr)
+		span.RecordError(sizeErr)
+		span.SetAttributes(attribute.Bool("error", true))
+		return nil, sizeErr
+	}
+
 	start := time.Now()
 	rawOutput, err := s.ollamaClient.Generate(ctx, prompt)
 	duration := time.Since(start)
@@ -91,8 +100,8 @@ func (s *PreviewService) AnalyzePreview(ctx context.Context, code, userMode, out
 	}
 
 	// Parse JSON response
-	var output review_models.PreviewModeOutput
-	if parseErr := json.Unmarshal([]byte(jsonStr), &output); parseErr != nil {
+	output, repaired, parseErr := unmarshalAIOutput[review_models.PreviewModeOutput](jsonStr, rawOutput)
+	if parseErr != nil {
 		s.logger.Error("PreviewService: failed to parse AI output", "correlation_id", correlationID, "error", parseErr)
 		parseErrWrapped := &review_errors.InfrastructureError{
 			Code:       "ERR_AI_RESPONSE_INVALID",
@@ -105,6 +114,11 @@ func (s *PreviewService) AnalyzePreview(ctx context.Context, code, userMode, out
 		return nil, parseErrWrapped
 	}
 
+	span.SetAttributes(attribute.Bool("json_repaired", repaired))
+	if repaired {
+		s.logger.Warn("PreviewService: AI output needed JSON repair", "correlation_id", correlationID)
+	}
+
 	// Validate output structure
 	if output.Summary == "" {
 		output.Summary = "No summary provided by AI"
@@ -118,5 +132,7 @@ func (s *PreviewService) AnalyzePreview(ctx context.Context, code, userMode, out
 	)
 
 	s.logger.Info("PreviewService: analysis completed successfully", "correlation_id", correlationID, "bounded_contexts_count", len(output.BoundedContexts))
-	return &output, nil
+	output.TokenEstimate = estimate
+	output.InjectionWarning = injection
+	return output, nil
 }
@@ -4,6 +4,7 @@ package review_services
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -31,6 +32,12 @@ const (
 	ErrModelUsedRequired  = "model_used is required"
 )
 
+// Rollback errors
+var (
+	ErrPromptVersionNotFound  = errors.New("prompt version not found")
+	ErrPromptVersionForbidden = errors.New("prompt version belongs to another user")
+)
+
 // Variable extraction regex pattern
 var variablePattern = regexp.MustCompile(`\{\{([^}]+)\}\}`)
 
@@ -139,6 +146,34 @@ func (s *PromptTemplateService) FactoryReset(ctx context.Context, userID int, mo
 	return nil
 }
 
+// GetVersions returns the saved versions of a user's custom prompt, newest first.
+func (s *PromptTemplateService) GetVersions(ctx context.Context, userID int, mode, userLevel, outputMode string) ([]*review_models.PromptTemplateVersion, error) {
+	return s.repo.ListVersions(ctx, userID, mode, userLevel, outputMode)
+}
+
+// RollbackToVersion makes an earlier version of the user's custom prompt
+// active again. The rollback is itself saved as a new version, so it shows
+// up in the history and can be undone the same way. Users may only restore
+// their own versions.
+func (s *PromptTemplateService) RollbackToVersion(ctx context.Context, userID int, versionID int64) (*review_models.PromptTemplate, error) {
+	version, err := s.repo.FindVersion(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching prompt version: %w", err)
+	}
+	if version == nil {
+		return nil, ErrPromptVersionNotFound
+	}
+	if version.UserID != userID {
+		return nil, ErrPromptVersionForbidden
+	}
+
+	restored, err := s.repo.RestoreVersion(ctx, version)
+	if err != nil {
+		return nil, fmt.Errorf("error restoring prompt version: %w", err)
+	}
+	return restored, nil
+}
+
 // RenderPrompt substitutes all template variables with their actual values.
 // Returns an error if any template variable is missing from the variables map.
 func (s *PromptTemplateService) RenderPrompt(template *review_models.PromptTemplate, variables map[string]string) (string, error) {
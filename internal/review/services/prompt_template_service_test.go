@@ -3,12 +3,14 @@ package review_services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/review/repositories"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockPromptTemplateRepository is a mock implementation for testing
@@ -66,6 +68,30 @@ func (m *MockPromptTemplateRepository) UpdateExecutionRating(ctx context.Context
 	return args.Error(0)
 }
 
+func (m *MockPromptTemplateRepository) FindVersion(ctx context.Context, versionID int64) (*review_models.PromptTemplateVersion, error) {
+	args := m.Called(ctx, versionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*review_models.PromptTemplateVersion), args.Error(1)
+}
+
+func (m *MockPromptTemplateRepository) ListVersions(ctx context.Context, userID int, mode, userLevel, outputMode string) ([]*review_models.PromptTemplateVersion, error) {
+	args := m.Called(ctx, userID, mode, userLevel, outputMode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*review_models.PromptTemplateVersion), args.Error(1)
+}
+
+func (m *MockPromptTemplateRepository) RestoreVersion(ctx context.Context, version *review_models.PromptTemplateVersion) (*review_models.PromptTemplate, error) {
+	args := m.Called(ctx, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*review_models.PromptTemplate), args.Error(1)
+}
+
 // Test: GetEffectivePrompt returns user custom over system default
 func TestPromptTemplateService_GetEffectivePrompt_UserCustom(t *testing.T) {
 	repo := new(MockPromptTemplateRepository)
@@ -422,3 +448,134 @@ func TestPromptTemplateService_ExtractVariables(t *testing.T) {
 		})
 	}
 }
+
+// memoryPromptRepo keeps one user's custom prompts and their version
+// history the way the database does: every save appends a version row.
+type memoryPromptRepo struct {
+	MockPromptTemplateRepository
+	active   map[string]*review_models.PromptTemplate
+	versions []*review_models.PromptTemplateVersion
+}
+
+func newMemoryPromptRepo() *memoryPromptRepo {
+	return &memoryPromptRepo{active: make(map[string]*review_models.PromptTemplate)}
+}
+
+func (r *memoryPromptRepo) FindByUserAndMode(_ context.Context, userID int, mode, userLevel, outputMode string) (*review_models.PromptTemplate, error) {
+	return r.active[fmt.Sprintf("%d/%s/%s/%s", userID, mode, userLevel, outputMode)], nil
+}
+
+func (r *memoryPromptRepo) Upsert(_ context.Context, template *review_models.PromptTemplate) (*review_models.PromptTemplate, error) {
+	return r.save(template, nil), nil
+}
+
+func (r *memoryPromptRepo) FindVersion(_ context.Context, versionID int64) (*review_models.PromptTemplateVersion, error) {
+	for _, v := range r.versions {
+		if v.ID == versionID {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryPromptRepo) RestoreVersion(_ context.Context, version *review_models.PromptTemplateVersion) (*review_models.PromptTemplate, error) {
+	userID := version.UserID
+	return r.save(&review_models.PromptTemplate{
+		UserID:     &userID,
+		Mode:       version.Mode,
+		UserLevel:  version.UserLevel,
+		OutputMode: version.OutputMode,
+		PromptText: version.PromptText,
+		Variables:  version.Variables,
+	}, &version.ID), nil
+}
+
+func (r *memoryPromptRepo) save(template *review_models.PromptTemplate, restoredFrom *int64) *review_models.PromptTemplate {
+	key := fmt.Sprintf("%d/%s/%s/%s", *template.UserID, template.Mode, template.UserLevel, template.OutputMode)
+	saved := *template
+	saved.Version = 1
+	if existing := r.active[key]; existing != nil {
+		saved.ID = existing.ID
+		saved.Version = existing.Version + 1
+	}
+	r.active[key] = &saved
+
+	r.versions = append(r.versions, &review_models.PromptTemplateVersion{
+		ID:           int64(len(r.versions) + 1),
+		TemplateID:   saved.ID,
+		UserID:       *saved.UserID,
+		Mode:         saved.Mode,
+		UserLevel:    saved.UserLevel,
+		OutputMode:   saved.OutputMode,
+		PromptText:   saved.PromptText,
+		Variables:    saved.Variables,
+		Version:      saved.Version,
+		RestoredFrom: restoredFrom,
+	})
+	return &saved
+}
+
+// Test: RollbackToVersion restores an earlier version and records the rollback
+func TestPromptTemplateService_RollbackToVersion(t *testing.T) {
+	repo := newMemoryPromptRepo()
+	service := NewPromptTemplateService(repo)
+	ctx := context.Background()
+
+	texts := []string{
+		"First prompt for {{code}}",
+		"Second prompt for {{code}}",
+		"Third prompt for {{code}}",
+	}
+	for _, text := range texts {
+		_, err := service.SaveCustomPrompt(ctx, 1, "preview", "beginner", "quick", text)
+		require.NoError(t, err)
+	}
+	require.Len(t, repo.versions, 3)
+	second := repo.versions[1]
+
+	restored, err := service.RollbackToVersion(ctx, 1, second.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, texts[1], restored.PromptText)
+	assert.Equal(t, 4, restored.Version, "rollback is saved as a new version")
+
+	active, err := service.GetEffectivePrompt(ctx, 1, "preview", "beginner", "quick")
+	require.NoError(t, err)
+	assert.Equal(t, texts[1], active.PromptText)
+
+	require.Len(t, repo.versions, 4, "rollback writes a history row")
+	latest := repo.versions[3]
+	assert.Equal(t, texts[1], latest.PromptText)
+	require.NotNil(t, latest.RestoredFrom)
+	assert.Equal(t, second.ID, *latest.RestoredFrom)
+}
+
+// Test: RollbackToVersion refuses another user's version
+func TestPromptTemplateService_RollbackToVersion_OtherUser(t *testing.T) {
+	repo := newMemoryPromptRepo()
+	service := NewPromptTemplateService(repo)
+	ctx := context.Background()
+
+	_, err := service.SaveCustomPrompt(ctx, 1, "preview", "beginner", "quick", "Owner's prompt for {{code}}")
+	require.NoError(t, err)
+
+	restored, err := service.RollbackToVersion(ctx, 2, repo.versions[0].ID)
+
+	assert.ErrorIs(t, err, ErrPromptVersionForbidden)
+	assert.Nil(t, restored)
+	assert.Len(t, repo.versions, 1, "nothing was restored")
+}
+
+// Test: RollbackToVersion reports unknown versions
+func TestPromptTemplateService_RollbackToVersion_NotFound(t *testing.T) {
+	repo := new(MockPromptTemplateRepository)
+	service := NewPromptTemplateService(repo)
+
+	repo.On("FindVersion", mock.Anything, int64(99)).Return(nil, nil)
+
+	restored, err := service.RollbackToVersion(context.Background(), 1, 99)
+
+	assert.ErrorIs(t, err, ErrPromptVersionNotFound)
+	assert.Nil(t, restored)
+	repo.AssertNotCalled(t, "RestoreVersion", mock.Anything, mock.Anything)
+}
@@ -74,6 +74,8 @@ Analyze this code and return ONLY this JSON structure:
 CODE TO ANALYZE:
 %s
 
+%s
+
 CRITICAL RULES:
 - Your ENTIRE response must be valid JSON
 - Do NOT write "Based on the code" or any explanatory text
@@ -81,7 +83,7 @@ CRITICAL RULES:
 - Do NOT add comments or explanations
 - START with { and END with }
 - file_tree items MUST have name, type, path, and description fields
-- Adjust description complexity based on tone guidance above`, toneGuidance, reasoningSection, code)
+- Adjust description complexity based on tone guidance above`, toneGuidance, reasoningSection, fenceUntrusted("CODE", code), untrustedInputNotice)
 }
 
 // BuildSkimPrompt creates a prompt for Skim Mode analysis
@@ -159,6 +161,8 @@ Analyze this code and return ONLY this JSON structure:
 CODE TO ANALYZE:
 %s
 
+%s
+
 CRITICAL RULES:
 - Your ENTIRE response must be valid JSON
 - Do NOT write "The code appears to" or any explanatory text
@@ -167,7 +171,7 @@ CRITICAL RULES:
 - START with { and END with }
 - For functions: include name, full signature, and brief description
 - For interfaces: describe purpose and list method signatures
-- Adjust description complexity based on tone guidance above`, toneGuidance, reasoningSection, code)
+- Adjust description complexity based on tone guidance above`, toneGuidance, reasoningSection, fenceUntrusted("CODE", code), untrustedInputNotice)
 }
 
 // BuildScanPrompt creates a prompt for Scan Mode analysis
@@ -214,7 +218,7 @@ TONE GUIDANCE: %s
 Find patterns matching this query and return ONLY this JSON structure:
 
 {
-  "query": "%s",
+  "query": %s,
   "matches": [
     {
       "file": "handler.go",
@@ -228,11 +232,14 @@ Find patterns matching this query and return ONLY this JSON structure:
   "summary": "Found 3 matches for query in the codebase"%s
 }
 
-QUERY: "%s"
+QUERY:
+%s
 
 CODE TO ANALYZE:
 %s
 
+%s
+
 CRITICAL RULES:
 - Your ENTIRE response must be valid JSON
 - Do NOT write "Based on the query" or any explanatory text
@@ -240,7 +247,7 @@ CRITICAL RULES:
 - Do NOT add comments or explanations
 - START with { and END with }
 - Relevance score 0.0-1.0 (1.0 = perfect match)
-- Adjust reason explanations based on tone guidance above`, toneGuidance, query, reasoningSection, query, code)
+- Adjust reason explanations based on tone guidance above`, toneGuidance, untrustedJSONString(query), reasoningSection, untrustedLine("QUERY", query), fenceUntrusted("CODE", code), untrustedInputNotice)
 }
 
 // BuildDetailedPrompt creates a prompt for Detailed Mode analysis
@@ -321,11 +328,14 @@ Analyze this code and return ONLY this JSON structure:
   "summary": "Binary search implementation"%s
 }
 
-FILE: %s
+FILE:
+%s
 
 CODE TO ANALYZE:
 %s
 
+%s
+
 CRITICAL RULES:
 - Your ENTIRE response must be valid JSON
 - Do NOT write "This code" or any explanatory text
@@ -333,7 +343,7 @@ CRITICAL RULES:
 - Do NOT add comments or explanations
 - START with { and END with }
 - line_explanations is the PRIMARY OUTPUT - explain EVERY significant line
-- Adjust explanation depth and language based on tone guidance above`, toneGuidance, reasoningSection, filename, code)
+- Adjust explanation depth and language based on tone guidance above`, toneGuidance, reasoningSection, untrustedLine("FILE", filename), fenceUntrusted("CODE", code), untrustedInputNotice)
 }
 
 // BuildCriticalPrompt creates a prompt for Critical Mode analysis
@@ -344,6 +354,8 @@ func BuildCriticalPrompt(code string) string {
 CODE:
 %s
 
+%s
+
 You MUST respond with ONLY a valid JSON object (no markdown, no explanation text). Use EXACTLY this structure:
 
 {
@@ -389,5 +401,63 @@ IMPORTANT:
 - Return ONLY the JSON object (no json code fences, no explanatory text)
 - Focus on SECURITY and CORRECTNESS
 - If no issues found, return empty issues array
-- Be precise and actionable`, code)
+- Be precise and actionable`, fenceUntrusted("CODE", code), untrustedInputNotice)
+}
+
+// BuildCriticalDiffPrompt creates the Critical Mode prompt for a change set.
+// diff is the output of RenderDiffForReview: changed lines plus a little
+// context, each kept line prefixed with its new-file line number.
+func BuildCriticalDiffPrompt(diff string) string {
+	return fmt.Sprintf(`Review this CODE CHANGE in CRITICAL mode - identify quality issues introduced by the change.
+
+Lines marked "+" were added, "-" were removed and " " are unchanged context.
+The number before the marker is the line number in the NEW file; removed lines have none.
+"..." separates non-adjacent parts of a file.
+
+CHANGES:
+%s
+
+%s
+
+You MUST respond with ONLY a valid JSON object (no markdown, no explanation text). Use EXACTLY this structure:
+
+{
+  "overall_grade": "B",
+  "summary": "The change introduces 1 high severity issue in request handling",
+  "issues": [
+    {
+      "severity": "high",
+      "category": "security",
+      "file": "handlers/user.go",
+      "line": 42,
+      "code_snippet": "db.Query(query + userInput)",
+      "description": "SQL injection vulnerability - user input not parameterized",
+      "impact": "Attacker can execute arbitrary SQL commands",
+      "fix_suggestion": "Use parameterized query: db.Query(query, userInput)"
+    }
+  ]
+}
+
+ISSUE OBJECT FIELDS (all required):
+- severity: "critical" | "high" | "medium" | "low"
+- category: "security" | "performance" | "maintainability" | "reliability" | "testing"
+- file: the path shown after "FILE:"
+- line: the NEW-file line number shown at the start of the line, or 0 for problems caused by a removal
+- code_snippet: The problematic line, copied exactly
+- description: What's wrong
+- impact: What harm this causes
+- fix_suggestion: How to fix it
+
+GRADING CRITERIA (for the change, not the whole file):
+A = No critical/high issues, excellent quality
+B = Minor issues, good quality
+C = Some concerning issues, acceptable
+D = Multiple serious issues, needs work
+F = Critical issues present, unsafe
+
+IMPORTANT:
+- Return ONLY the JSON object (no json code fences, no explanatory text)
+- Only report issues in added lines or caused by the change; context lines are for understanding
+- If no issues found, return empty issues array
+- Be precise and actionable`, fenceUntrusted("CHANGES", diff), untrustedInputNotice)
 }
@@ -2,7 +2,6 @@ package review_services
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"time"
 
@@ -66,9 +65,19 @@ func (s *ScanService) AnalyzeScan(ctx context.Context, query, code, userMode, ou
 	}
 
 	// Build prompt using template with user/output modes
-	prompt := BuildScanPrompt(code, query, userMode, outputMode)
+	prompt := withLanguageHint(ctx, BuildScanPrompt(code, query, userMode, outputMode))
+	injection := flagPromptInjection(ctx, s.logger, span, "ScanService", code, query)
 	span.SetAttributes(attribute.Int("prompt_length", len(prompt)))
 
+	estimate, sizeErr := checkPromptSize(ctx, prompt)
+	span.SetAttributes(attribute.Int("prompt_tokens_est", estimate.PromptTokens))
+	if sizeErr != nil {
+		s.logger.Warn("Scan input too large for model", "correlation_id", correlationID, "error", sizeErr)
+		span.RecordError(sizeErr)
+		span.SetAttributes(attribute.Bool("error", true))
+		return nil, sizeErr
+	}
+
 	start := time.Now()
 	rawOutput, aiErr := s.ollamaClient.Generate(ctx, prompt)
 	duration := time.Since(start)
@@ -106,8 +115,7 @@ func (s *ScanService) AnalyzeScan(ctx context.Context, query, code, userMode, ou
 		return nil, extractErrWrapped
 	}
 
-	var output review_models.ScanModeOutput
-	unmarshalErr := json.Unmarshal([]byte(jsonStr), &output)
+	output, repaired, unmarshalErr := unmarshalAIOutput[review_models.ScanModeOutput](jsonStr, rawOutput)
 	if unmarshalErr != nil {
 		s.logger.Error("Failed to unmarshal scan analysis output", "correlation_id", correlationID, "error", unmarshalErr)
 		parseErr := &review_errors.InfrastructureError{
@@ -121,14 +129,21 @@ func (s *ScanService) AnalyzeScan(ctx context.Context, query, code, userMode, ou
 		return nil, parseErr
 	}
 
+	if repaired {
+		s.logger.Warn("Scan analysis output needed JSON repair", "correlation_id", correlationID)
+	}
+
 	span.SetAttributes(
 		attribute.Bool("error", false),
 		attribute.Bool("success", true),
+		attribute.Bool("json_repaired", repaired),
 		attribute.Int("matches_count", len(output.Matches)),
 	)
 
 	s.logger.Info("AnalyzeScan completed", "correlation_id", correlationID, "summary", output.Summary, "matches_count", len(output.Matches))
-	return &output, nil
+	output.TokenEstimate = estimate
+	output.InjectionWarning = injection
+	return output, nil
 }
 
 // BusinessError represents a business logic error (invalid input, quota exceeded, etc.)
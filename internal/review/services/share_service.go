@@ -0,0 +1,188 @@
+package review_services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// Share link lifetimes
+const (
+	DefaultShareTTL = 7 * 24 * time.Hour
+	MaxShareTTL     = 30 * 24 * time.Hour
+)
+
+// Share errors
+var (
+	ErrShareTokenInvalid = errors.New("share token is invalid")
+	ErrShareExpired      = errors.New("share link has expired")
+	ErrShareRevoked      = errors.New("share link has been revoked")
+	ErrShareNotFound     = errors.New("share not found")
+	ErrAnalysisNotFound  = errors.New("analysis not found")
+)
+
+// ShareRepository stores share links so they can be revoked.
+type ShareRepository interface {
+	CreateShare(ctx context.Context, share *review_models.AnalysisShare) error
+	GetShare(ctx context.Context, id string) (*review_models.AnalysisShare, error)
+	RevokeShare(ctx context.Context, userID int64, id string) (bool, error)
+}
+
+// AnalysisDetailReader loads a stored result with its session.
+type AnalysisDetailReader interface {
+	GetAnalysisDetail(ctx context.Context, analysisID int64) (*review_models.AnalysisDetail, error)
+}
+
+// ShareService mints and resolves read-only share links for analysis
+// results. A token is "shareID.analysisID.expiry.signature", signed with
+// HMAC-SHA256, so forged or altered tokens are rejected without a lookup;
+// the stored share is then checked for revocation.
+type ShareService struct {
+	shares   ShareRepository
+	analyses AnalysisDetailReader
+	secret   []byte
+	now      func() time.Time
+}
+
+// NewShareService creates a ShareService signing tokens with secret.
+func NewShareService(shares ShareRepository, analyses AnalysisDetailReader, secret []byte) *ShareService {
+	return &ShareService{shares: shares, analyses: analyses, secret: secret, now: time.Now}
+}
+
+// Create shares one of the user's analyses for ttl, clamped to MaxShareTTL;
+// zero means DefaultShareTTL. It returns ErrAnalysisNotFound if the analysis
+// doesn't exist or belongs to someone else.
+func (s *ShareService) Create(ctx context.Context, userID, analysisID int64, ttl time.Duration) (*review_models.AnalysisShare, string, error) {
+	switch {
+	case ttl <= 0:
+		ttl = DefaultShareTTL
+	case ttl > MaxShareTTL:
+		ttl = MaxShareTTL
+	}
+
+	detail, err := s.analyses.GetAnalysisDetail(ctx, analysisID)
+	if err != nil {
+		return nil, "", err
+	}
+	if detail == nil || detail.UserID != userID {
+		return nil, "", ErrAnalysisNotFound
+	}
+
+	id, err := newShareID()
+	if err != nil {
+		return nil, "", err
+	}
+	share := &review_models.AnalysisShare{
+		ID:         id,
+		AnalysisID: analysisID,
+		UserID:     userID,
+		// Whole seconds, as carried in the token
+		ExpiresAt: s.now().Add(ttl).UTC().Truncate(time.Second),
+	}
+	if err := s.shares.CreateShare(ctx, share); err != nil {
+		return nil, "", err
+	}
+	return share, s.sign(share), nil
+}
+
+// Resolve validates token and returns the shared analysis. It returns
+// ErrShareTokenInvalid for a malformed or tampered token, ErrShareExpired or
+// ErrShareRevoked when the link no longer works, and ErrAnalysisNotFound if
+// the analysis has since been deleted.
+func (s *ShareService) Resolve(ctx context.Context, token string) (*review_models.AnalysisDetail, error) {
+	claims, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if !s.now().Before(claims.ExpiresAt) {
+		return nil, ErrShareExpired
+	}
+
+	share, err := s.shares.GetShare(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if share == nil || share.AnalysisID != claims.AnalysisID {
+		return nil, ErrShareTokenInvalid
+	}
+	if share.RevokedAt != nil {
+		return nil, ErrShareRevoked
+	}
+
+	detail, err := s.analyses.GetAnalysisDetail(ctx, share.AnalysisID)
+	if err != nil {
+		return nil, err
+	}
+	if detail == nil {
+		return nil, ErrAnalysisNotFound
+	}
+	return detail, nil
+}
+
+// Revoke disables one of the user's share links.
+func (s *ShareService) Revoke(ctx context.Context, userID int64, shareID string) error {
+	found, err := s.shares.RevokeShare(ctx, userID, shareID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+func (s *ShareService) sign(share *review_models.AnalysisShare) string {
+	payload := fmt.Sprintf("%s.%d.%d", share.ID, share.AnalysisID, share.ExpiresAt.Unix())
+	return payload + "." + s.signature(payload)
+}
+
+// verify checks the token's signature and returns the share it names.
+func (s *ShareService) verify(token string) (*review_models.AnalysisShare, error) {
+	payload, signature, ok := cutLast(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signature(payload))) {
+		return nil, ErrShareTokenInvalid
+	}
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return nil, ErrShareTokenInvalid
+	}
+	analysisID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrShareTokenInvalid
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, ErrShareTokenInvalid
+	}
+	return &review_models.AnalysisShare{ID: parts[0], AnalysisID: analysisID, ExpiresAt: time.Unix(expires, 0).UTC()}, nil
+}
+
+func (s *ShareService) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("share:" + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+func newShareID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate share id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,159 @@
+package review_services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryShares stores shares and analyses in memory.
+type memoryShares struct {
+	shares   map[string]*review_models.AnalysisShare
+	analyses map[int64]*review_models.AnalysisDetail
+}
+
+func newMemoryShares(analyses ...*review_models.AnalysisDetail) *memoryShares {
+	m := &memoryShares{shares: map[string]*review_models.AnalysisShare{}, analyses: map[int64]*review_models.AnalysisDetail{}}
+	for _, a := range analyses {
+		m.analyses[a.ID] = a
+	}
+	return m
+}
+
+func (m *memoryShares) CreateShare(_ context.Context, share *review_models.AnalysisShare) error {
+	stored := *share
+	m.shares[share.ID] = &stored
+	return nil
+}
+
+func (m *memoryShares) GetShare(_ context.Context, id string) (*review_models.AnalysisShare, error) {
+	return m.shares[id], nil
+}
+
+func (m *memoryShares) RevokeShare(_ context.Context, userID int64, id string) (bool, error) {
+	share, ok := m.shares[id]
+	if !ok || share.UserID != userID {
+		return false, nil
+	}
+	if share.RevokedAt == nil {
+		now := time.Now()
+		share.RevokedAt = &now
+	}
+	return true, nil
+}
+
+func (m *memoryShares) GetAnalysisDetail(_ context.Context, id int64) (*review_models.AnalysisDetail, error) {
+	return m.analyses[id], nil
+}
+
+func newTestShareService(t *testing.T) (*ShareService, *memoryShares, *time.Time) {
+	t.Helper()
+	repo := newMemoryShares(&review_models.AnalysisDetail{
+		ID:             11,
+		UserID:         7,
+		AnalysisResult: review_models.AnalysisResult{ReviewID: 3, Mode: review_models.CriticalMode},
+	})
+	now := time.Date(2025, 11, 22, 10, 0, 0, 0, time.UTC)
+	svc := NewShareService(repo, repo, []byte("test-secret"))
+	svc.now = func() time.Time { return now }
+	return svc, repo, &now
+}
+
+func TestShareService_ValidTokenResolves(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestShareService(t)
+
+	share, token, err := svc.Create(ctx, 7, 11, 0)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 11, 29, 10, 0, 0, 0, time.UTC), share.ExpiresAt, "defaults to a week")
+	assert.True(t, strings.HasPrefix(token, share.ID+".11."))
+
+	detail, err := svc.Resolve(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), detail.ID)
+}
+
+func TestShareService_ExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	svc, _, now := newTestShareService(t)
+
+	share, token, err := svc.Create(ctx, 7, 11, time.Hour)
+	require.NoError(t, err)
+
+	*now = share.ExpiresAt
+	_, err = svc.Resolve(ctx, token)
+	assert.ErrorIs(t, err, ErrShareExpired)
+}
+
+func TestShareService_TTLIsCapped(t *testing.T) {
+	svc, _, now := newTestShareService(t)
+
+	share, _, err := svc.Create(context.Background(), 7, 11, 365*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(MaxShareTTL), share.ExpiresAt)
+}
+
+func TestShareService_TamperedToken(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestShareService(t)
+	share, token, err := svc.Create(ctx, 7, 11, time.Hour)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 4)
+	tampered := []string{
+		strings.Join([]string{parts[0], "12", parts[2], parts[3]}, "."),                               // Another analysis
+		strings.Join([]string{parts[0], parts[1], "9999999999", parts[3]}, "."),                       // Later expiry
+		strings.Join([]string{parts[0], parts[1], parts[2], strings.Repeat("0", len(parts[3]))}, "."), // Forged signature
+		strings.Join([]string{parts[0], parts[1], parts[2]}, "."),                                     // No signature
+		"not-a-token",
+		"",
+	}
+	for _, bad := range tampered {
+		_, err := svc.Resolve(ctx, bad)
+		assert.ErrorIs(t, err, ErrShareTokenInvalid, bad)
+	}
+
+	// A token signed with another secret
+	other := NewShareService(newMemoryShares(), nil, []byte("other-secret"))
+	_, err = svc.Resolve(ctx, other.sign(share))
+	assert.ErrorIs(t, err, ErrShareTokenInvalid)
+}
+
+func TestShareService_RevokedToken(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestShareService(t)
+	share, token, err := svc.Create(ctx, 7, 11, time.Hour)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.Revoke(ctx, 8, share.ID), ErrShareNotFound, "only the owner can revoke")
+	_, err = svc.Resolve(ctx, token)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Revoke(ctx, 7, share.ID))
+	_, err = svc.Resolve(ctx, token)
+	assert.ErrorIs(t, err, ErrShareRevoked)
+}
+
+func TestShareService_OnlyOwnAnalyses(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := newTestShareService(t)
+
+	_, _, err := svc.Create(ctx, 8, 11, 0)
+	assert.ErrorIs(t, err, ErrAnalysisNotFound)
+	_, _, err = svc.Create(ctx, 7, 99, 0)
+	assert.ErrorIs(t, err, ErrAnalysisNotFound)
+	assert.Empty(t, repo.shares)
+
+	// The analysis is deleted after it was shared
+	_, token, err := svc.Create(ctx, 7, 11, 0)
+	require.NoError(t, err)
+	delete(repo.analyses, 11)
+	_, err = svc.Resolve(ctx, token)
+	assert.ErrorIs(t, err, ErrAnalysisNotFound)
+}
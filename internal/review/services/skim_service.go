@@ -2,7 +2,6 @@ package review_services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
@@ -53,9 +52,19 @@ func (s *SkimService) AnalyzeSkim(ctx context.Context, code, userMode, outputMod
 	s.logger.Info("AnalyzeSkim called", "correlation_id", correlationID, "code_length", len(code), "user_mode", userMode, "output_mode", outputMode)
 
 	// Build prompt using template with user/output modes
-	prompt := BuildSkimPrompt(code, userMode, outputMode)
+	prompt := withLanguageHint(ctx, BuildSkimPrompt(code, userMode, outputMode))
+	injection := flagPromptInjection(ctx, s.logger, span, "SkimService", code)
 	span.SetAttributes(attribute.Int("prompt_length", len(prompt)))
 
+	estimate, sizeErr := checkPromptSize(ctx, prompt)
+	span.SetAttributes(attribute.Int("prompt_tokens_est", estimate.PromptTokens))
+	if sizeErr != nil {
+		s.logger.Warn("SkimService: input too large for model", "correlation_id", correlationID, "error", sizeErr)
+		span.RecordError(sizeErr)
+		span.SetAttributes(attribute.Bool("error", true))
+		return nil, sizeErr
+	}
+
 	start := time.Now()
 	rawOutput, err := s.ollamaClient.Generate(ctx, prompt)
 	duration := time.Since(start)
@@ -78,7 +87,7 @@ func (s *SkimService) AnalyzeSkim(ctx context.Context, code, userMode, outputMod
 	}
 	s.logger.Info("SkimService: AI call succeeded", "correlation_id", correlationID, "duration_ms", duration.Milliseconds())
 
-	output, parseErr := s.parseSkimOutput(rawOutput)
+	output, repaired, parseErr := s.parseSkimOutput(rawOutput)
 	if parseErr != nil {
 		s.logger.Error("SkimService: failed to parse AI output", "correlation_id", correlationID, "error", parseErr)
 		parseErrWrapped := &review_errors.InfrastructureError{
@@ -92,28 +101,36 @@ func (s *SkimService) AnalyzeSkim(ctx context.Context, code, userMode, outputMod
 		return nil, parseErrWrapped
 	}
 
+	if repaired {
+		s.logger.Warn("SkimService: AI output needed JSON repair", "correlation_id", correlationID)
+	}
+
 	span.SetAttributes(
 		attribute.Bool("error", false),
 		attribute.Bool("success", true),
+		attribute.Bool("json_repaired", repaired),
 		attribute.Int("functions_count", len(output.Functions)),
 		attribute.Int("interfaces_count", len(output.Interfaces)),
 	)
 
+	output.TokenEstimate = estimate
+	output.InjectionWarning = injection
 	s.logger.Info("SkimService: analysis completed", "correlation_id", correlationID, "functions_count", len(output.Functions))
 	return output, nil
 }
 
-// Fix parseSkimOutput to handle errors properly
-func (s *SkimService) parseSkimOutput(raw string) (*review_models.SkimModeOutput, error) {
+// parseSkimOutput decodes the AI response, repairing malformed JSON when
+// needed; repaired reports whether it was
+func (s *SkimService) parseSkimOutput(raw string) (output *review_models.SkimModeOutput, repaired bool, err error) {
 	// Extract JSON from response (handles cases where AI adds extra text)
 	jsonStr, extractErr := ExtractJSON(raw)
 	if extractErr != nil {
-		return nil, fmt.Errorf("failed to extract JSON: %w", extractErr)
+		return nil, false, fmt.Errorf("failed to extract JSON: %w", extractErr)
 	}
 
-	var output review_models.SkimModeOutput
-	if err := json.Unmarshal([]byte(jsonStr), &output); err != nil {
-		return nil, fmt.Errorf("failed to parse skim output: %w", err)
+	output, repaired, err = unmarshalAIOutput[review_models.SkimModeOutput](jsonStr, raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse skim output: %w", err)
 	}
-	return &output, nil
+	return output, repaired, nil
 }
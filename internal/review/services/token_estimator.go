@@ -0,0 +1,120 @@
+package review_services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_errors "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/errors"
+	review_models "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/models"
+)
+
+// ErrCodeInputTooLarge is the BusinessError code for prompts that would
+// overflow the model's context window
+const ErrCodeInputTooLarge = "ERR_INPUT_TOO_LARGE"
+
+// charsPerToken is the usual ratio of characters to tokens for code and
+// English prose across BPE tokenizers
+const charsPerToken = 4
+
+// responseReserve is kept free in the context window for the model's answer
+const responseReserve = 2048
+
+// contextWindows maps model name prefixes to context window sizes in tokens.
+// The longest matching prefix wins, so specific variants can override their
+// family. Ollama tags ("llama3.1:8b") match on the family before the colon.
+var contextWindows = map[string]int{
+	// Anthropic
+	"claude": 200000,
+	// OpenAI
+	"gpt-4o":      128000,
+	"gpt-4-turbo": 128000,
+	"gpt-4-32k":   32768,
+	"gpt-4":       8192,
+	"gpt-3.5":     16385,
+	// Hosted OpenAI-compatible
+	"deepseek-chat":  64000,
+	"deepseek-coder": 64000,
+	"codestral":      32000,
+	"mistral-large":  128000,
+	"gemini":         1000000,
+	// Ollama
+	"deepseek-coder:": 16384,
+	"mistral:":        32768,
+	"mixtral:":        32768,
+	"llama3.1:":       131072,
+	"llama3.2:":       131072,
+	"llama3:":         8192,
+	"codellama:":      16384,
+	"qwen2.5-coder:":  32768,
+	"phi3:":           4096,
+	"gemma2:":         8192,
+}
+
+// DefaultContextWindow is assumed for named models missing from the table
+const DefaultContextWindow = 8192
+
+// TokenEstimator checks prompts against a model's context window before they
+// are sent, so oversized input fails with a clear error instead of being
+// truncated or rejected by the model.
+type TokenEstimator struct {
+	windows       map[string]int
+	defaultWindow int
+	reserve       int
+}
+
+// NewTokenEstimator creates a TokenEstimator with the built-in model table
+func NewTokenEstimator() *TokenEstimator {
+	return &TokenEstimator{windows: contextWindows, defaultWindow: DefaultContextWindow, reserve: responseReserve}
+}
+
+// defaultTokenEstimator is used by the analyzers
+var defaultTokenEstimator = NewTokenEstimator()
+
+// Estimate returns the approximate number of tokens in text
+func (e *TokenEstimator) Estimate(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// MaxPromptTokens returns how many prompt tokens model accepts, leaving room
+// for the response
+func (e *TokenEstimator) MaxPromptTokens(model string) int {
+	name := strings.ToLower(model)
+	window, matched := e.defaultWindow, 0
+	for prefix, size := range e.windows {
+		if len(prefix) > matched && strings.HasPrefix(name, prefix) {
+			window, matched = size, len(prefix)
+		}
+	}
+	return window - e.reserve
+}
+
+// Check estimates prompt's size for model. It returns a BusinessError with
+// ErrCodeInputTooLarge when the prompt doesn't fit. An empty model (the
+// user's configured default, which isn't known here) is never rejected.
+func (e *TokenEstimator) Check(model, prompt string) (*review_models.TokenEstimate, error) {
+	estimate := &review_models.TokenEstimate{Model: model, PromptTokens: e.Estimate(prompt)}
+	if model == "" {
+		return estimate, nil
+	}
+
+	estimate.MaxTokens = e.MaxPromptTokens(model)
+	if estimate.PromptTokens > estimate.MaxTokens {
+		return estimate, &review_errors.BusinessError{
+			Code: ErrCodeInputTooLarge,
+			Message: fmt.Sprintf("input too large for model %s (est %d tokens, max %d); analyze a smaller selection or choose a model with a larger context window",
+				model, estimate.PromptTokens, estimate.MaxTokens),
+			HTTPStatus: http.StatusRequestEntityTooLarge,
+		}
+	}
+	return estimate, nil
+}
+
+// checkPromptSize checks prompt against the model selected for the request
+func checkPromptSize(ctx context.Context, prompt string) (*review_models.TokenEstimate, error) {
+	model, _ := ctx.Value(reviewcontext.ModelContextKey).(string)
+	return defaultTokenEstimator.Check(model, prompt)
+}
@@ -0,0 +1,90 @@
+package review_services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	review_errors "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/errors"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// largeInput is about 25k tokens: too big for phi3, fine for Claude
+var largeInput = strings.Repeat("func handler(w http.ResponseWriter) { }\n", 2500)
+
+func TestTokenEstimator_RejectsLargeInputForSmallModel(t *testing.T) {
+	estimate, err := NewTokenEstimator().Check("phi3:mini", largeInput)
+
+	var businessErr *review_errors.BusinessError
+	require.True(t, errors.As(err, &businessErr))
+	assert.Equal(t, ErrCodeInputTooLarge, businessErr.Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, businessErr.StatusCode())
+	assert.Contains(t, businessErr.Message, "input too large for model phi3:mini (est 25000 tokens, max 2048)")
+	assert.Equal(t, 25000, estimate.PromptTokens)
+}
+
+func TestTokenEstimator_AcceptsLargeInputForLargeModel(t *testing.T) {
+	estimate, err := NewTokenEstimator().Check("claude-3-5-sonnet-20241022", largeInput)
+
+	require.NoError(t, err)
+	assert.Equal(t, 25000, estimate.PromptTokens)
+	assert.Equal(t, 200000-responseReserve, estimate.MaxTokens)
+}
+
+func TestTokenEstimator_MaxPromptTokens(t *testing.T) {
+	e := NewTokenEstimator()
+	tests := []struct {
+		model  string
+		window int
+	}{
+		{"deepseek-coder:6.7b", 16384},
+		{"deepseek-coder", 64000},
+		{"llama3.1:8b", 131072},
+		{"llama3:8b", 8192},
+		{"GPT-4o", 128000},
+		{"gpt-4-32k", 32768},
+		{"some-new-model", DefaultContextWindow},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.window-responseReserve, e.MaxPromptTokens(tt.model), tt.model)
+	}
+}
+
+func TestTokenEstimator_UnknownDefaultModelNotRejected(t *testing.T) {
+	estimate, err := NewTokenEstimator().Check("", largeInput)
+
+	require.NoError(t, err)
+	assert.Zero(t, estimate.MaxTokens)
+	assert.Equal(t, 25000, estimate.PromptTokens)
+}
+
+func TestPreviewService_RejectsOversizedInputBeforeAICall(t *testing.T) {
+	ollama := &testutils.MockOllamaClient{GenerateError: "AI must not be called"}
+	svc := NewPreviewService(ollama, &testutils.MockLogger{})
+	ctx := context.WithValue(context.Background(), reviewcontext.ModelContextKey, "phi3:mini")
+
+	_, err := svc.AnalyzePreview(ctx, largeInput, "intermediate", "quick")
+
+	var businessErr *review_errors.BusinessError
+	require.True(t, errors.As(err, &businessErr), "got %v", err)
+	assert.Equal(t, ErrCodeInputTooLarge, businessErr.Code)
+}
+
+func TestPreviewService_ReportsTokenEstimate(t *testing.T) {
+	ollama := &testutils.MockOllamaClient{GenerateResponse: `{"summary":"ok","bounded_contexts":[],"tech_stack":[],"file_tree":[]}`}
+	svc := NewPreviewService(ollama, &testutils.MockLogger{})
+	ctx := context.WithValue(context.Background(), reviewcontext.ModelContextKey, "mistral:7b-instruct")
+
+	out, err := svc.AnalyzePreview(ctx, "package main\nfunc main() {}", "intermediate", "quick")
+
+	require.NoError(t, err)
+	require.NotNil(t, out.TokenEstimate)
+	assert.Equal(t, "mistral:7b-instruct", out.TokenEstimate.Model)
+	assert.Positive(t, out.TokenEstimate.PromptTokens)
+	assert.Equal(t, 32768-responseReserve, out.TokenEstimate.MaxTokens)
+}
@@ -2,12 +2,19 @@ package review_services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
 	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai/providers"
 	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/review/retry"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/shared/logger"
 )
 
 // UnifiedAIClient implements OllamaClientInterface by routing to the appropriate
@@ -15,13 +22,43 @@ import (
 // This client queries the Portal service's AI Factory API to get the user's configured model.
 type UnifiedAIClient struct {
 	portalClient *PortalClient
+	usage        ai.UsageRecorder
+	usageLog     logger.Interface
+	newProvider  func(config *LLMConfig, model string) (ai.Provider, error)
+	retry        retry.Strategy
+	maxAttempts  int
 }
 
 // NewUnifiedAIClient creates a new unified AI client that fetches configs from Portal's AI Factory
 func NewUnifiedAIClient(portalURL string) *UnifiedAIClient {
-	return &UnifiedAIClient{
+	c := &UnifiedAIClient{
 		portalClient: NewPortalClient(portalURL),
 	}
+	c.newProvider = c.createProvider
+	return c
+}
+
+// SetPortalServiceToken lets the client fetch decrypted API keys from
+// Portal; see PortalClient.SetServiceToken
+func (c *UnifiedAIClient) SetPortalServiceToken(token string) {
+	c.portalClient.SetServiceToken(token)
+}
+
+// SetUsageRecorder records tokens, latency and cost of every call;
+// recording failures are logged to log
+func (c *UnifiedAIClient) SetUsageRecorder(recorder ai.UsageRecorder, log logger.Interface) {
+	c.usage = recorder
+	c.usageLog = log
+}
+
+// SetRetryPolicy retries transient failures (429, 5xx, network errors) with
+// jittered exponential backoff. cfg.MaxRetries counts attempts, as in
+// retry.Strategy. Retries happen within one Generate call, so a circuit
+// breaker wrapping this client sees a single outcome per call and, when
+// open, rejects calls before any attempt is made.
+func (c *UnifiedAIClient) SetRetryPolicy(cfg retry.Config) {
+	c.retry = retry.NewRetryStrategy(&cfg)
+	c.maxAttempts = cfg.MaxRetries
 }
 
 // Generate implements OllamaClientInterface.Generate
@@ -31,28 +68,105 @@ func (c *UnifiedAIClient) Generate(ctx context.Context, prompt string) (string,
 		return "", fmt.Errorf("prompt cannot be empty")
 	}
 
-	// Get session token from context
-	// The token is set by RedisSessionAuthMiddleware as "session_token" in Gin context
-	// Handlers should pass it through to context using reviewcontext.SessionTokenKey
+	sessionToken, err := sessionTokenFrom(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Refuse the call once the monthly budget is spent
+	if err := c.checkBudget(ctx, sessionToken); err != nil {
+		return "", err
+	}
+
+	return c.generateWithRetry(ctx, sessionToken, prompt)
+}
+
+// sessionTokenFrom returns the session token in ctx.
+// The token is set by RedisSessionAuthMiddleware as "session_token" in Gin context
+// Handlers should pass it through to context using reviewcontext.SessionTokenKey
+func sessionTokenFrom(ctx context.Context) (string, error) {
 	sessionToken, ok := ctx.Value(reviewcontext.SessionTokenKey).(string)
 	if !ok || sessionToken == "" {
 		return "", fmt.Errorf("no session token in context - user must be authenticated. Please ensure RedisSessionAuthMiddleware is active and session token is passed to context")
 	}
+	return sessionToken, nil
+}
 
-	// Get user's AI configuration from Portal's AI Factory
+// ResolveModel returns the provider and model Generate would call under ctx:
+// the user's effective Review config from Portal, with any model override in
+// the context applied
+func (c *UnifiedAIClient) ResolveModel(ctx context.Context) (provider, model string, err error) {
+	sessionToken, err := sessionTokenFrom(ctx)
+	if err != nil {
+		return "", "", err
+	}
 	config, err := c.portalClient.GetEffectiveConfigForApp(ctx, sessionToken, "review")
 	if err != nil {
-		return "", fmt.Errorf("failed to get AI configuration from Portal: %w. Please configure an AI model in AI Factory (/llm-config)", err)
+		return "", "", fmt.Errorf("failed to get AI configuration from Portal: %w", err)
 	}
+	return config.Provider, modelFor(ctx, config), nil
+}
 
-	// Allow model override from context (for advanced users selecting different models)
-	model := config.ModelName
+// modelFor returns the context's model override, if any, else the config's model.
+// The override lets advanced users select different models.
+func modelFor(ctx context.Context, config *LLMConfig) string {
 	if contextModel, ok := ctx.Value(reviewcontext.ModelContextKey).(string); ok && contextModel != "" {
-		model = contextModel
+		return contextModel
 	}
+	return config.ModelName
+}
+
+// generateWithRetry makes attempts until one succeeds, an error is not
+// transient, the retry policy is used up, or the next backoff would pass the
+// context deadline. Output already streamed to the caller is never repeated,
+// so a stream that failed midway is not retried.
+func (c *UnifiedAIClient) generateWithRetry(ctx context.Context, sessionToken, prompt string) (string, error) {
+	streamed := false
+	if onChunk := reviewcontext.ChunkFuncFrom(ctx); onChunk != nil {
+		ctx = reviewcontext.WithChunkFunc(ctx, func(chunk string) error {
+			streamed = true
+			return onChunk(chunk)
+		})
+	}
+
+	for attempt := 1; ; attempt++ {
+		content, err := c.generateOnce(ctx, sessionToken, prompt)
+		if err == nil || c.retry == nil || attempt >= c.maxAttempts || streamed || !isRetryable(ctx, err) {
+			return content, err
+		}
+
+		delay := c.retry.CalculateDelay(attempt)
+		var providerErr *ai.ProviderError
+		if errors.As(err, &providerErr) && providerErr.RetryAfter > delay {
+			delay = providerErr.RetryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return "", err
+		}
+		log.Printf("WARN: AI call failed (attempt %d of %d), retrying in %s: %v", attempt, c.maxAttempts, delay.Round(time.Millisecond), err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return "", err
+		}
+	}
+}
+
+// generateOnce resolves the user's AI configuration and makes one call
+func (c *UnifiedAIClient) generateOnce(ctx context.Context, sessionToken, prompt string) (string, error) {
+	// Get user's AI configuration from Portal's AI Factory
+	config, err := c.portalClient.GetEffectiveConfigForApp(ctx, sessionToken, "review")
+	if err != nil {
+		return "", fmt.Errorf("failed to get AI configuration from Portal: %w. Please configure an AI model in AI Factory (/llm-config)", err)
+	}
+
+	model := modelFor(ctx, config)
 
 	// Instantiate the appropriate provider based on configuration
-	provider, err := c.createProvider(config, model)
+	provider, err := c.newProvider(config, model)
 	if err != nil {
 		return "", fmt.Errorf("failed to create AI provider: %w", err)
 	}
@@ -65,8 +179,10 @@ func (c *UnifiedAIClient) Generate(ctx context.Context, prompt string) (string,
 		MaxTokens:   config.MaxTokens,
 	}
 
-	// Call the provider
-	resp, err := provider.Generate(ctx, req)
+	// Call the provider, streaming when the caller asked for chunks
+	start := time.Now()
+	resp, err := generateWithProvider(ctx, provider, req)
+	c.recordUsage(ctx, config, model, resp, err, time.Since(start))
 	if err != nil {
 		return "", fmt.Errorf("%s generation failed: %w", config.Provider, err)
 	}
@@ -78,6 +194,101 @@ func (c *UnifiedAIClient) Generate(ctx context.Context, prompt string) (string,
 	return resp.Content, nil
 }
 
+// isRetryable reports whether err is transient: a rate limit or server error
+// from Portal or the provider, or a network failure. Validation errors and
+// the caller giving up are final.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var providerErr *ai.ProviderError
+	if errors.As(err, &providerErr) {
+		return retryableStatus(providerErr.StatusCode)
+	}
+	var portalErr *PortalError
+	if errors.As(err, &portalErr) {
+		return retryableStatus(portalErr.StatusCode)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// checkBudget returns ai.ErrBudgetExceeded when the user is over their
+// monthly budget. Portal caches the status, so this is cheap per call. The
+// check fails open: if Portal can't report the budget the call proceeds.
+func (c *UnifiedAIClient) checkBudget(ctx context.Context, sessionToken string) error {
+	status, err := c.portalClient.GetBudgetStatus(ctx, sessionToken)
+	if err != nil {
+		log.Printf("WARN: failed to check AI budget, allowing call: %v", err)
+		return nil
+	}
+	if !status.Exceeded {
+		return nil
+	}
+	limit := 0.0
+	if status.MonthlyLimitUSD != nil {
+		limit = *status.MonthlyLimitUSD
+	}
+	return fmt.Errorf("%w: $%.2f spent of $%.2f this month", ai.ErrBudgetExceeded, status.SpentUSD, limit)
+}
+
+// recordUsage stores one usage row for the call. Recording is best-effort;
+// a failure is logged and never fails the call.
+func (c *UnifiedAIClient) recordUsage(ctx context.Context, config *LLMConfig, model string, resp *ai.Response, callErr error, latency time.Duration) {
+	if c.usage == nil {
+		return
+	}
+	record := &ai.UsageRecord{
+		UserID:   config.UserID,
+		AppName:  "review",
+		Provider: config.Provider,
+		Model:    model,
+		Latency:  latency,
+		Success:  callErr == nil && resp != nil,
+	}
+	if resp != nil {
+		record.InputTokens = resp.InputTokens
+		record.OutputTokens = resp.OutputTokens
+		record.CostUSD = resp.CostUSD
+	}
+	if callErr != nil {
+		record.Error = callErr.Error()
+	} else if resp == nil {
+		record.Error = "nil response"
+	}
+
+	// The caller's context may already be cancelled
+	if err := c.usage.Record(context.WithoutCancel(ctx), record); err != nil {
+		c.usageLog.Warn("failed to record LLM usage", "error", err, "user_id", record.UserID, "model", model)
+	}
+}
+
+// generateWithProvider streams to the context's ChunkFunc when one is set.
+// Providers without streaming support deliver their whole response as a
+// single chunk.
+func generateWithProvider(ctx context.Context, provider ai.Provider, req *ai.Request) (*ai.Response, error) {
+	onChunk := reviewcontext.ChunkFuncFrom(ctx)
+	if onChunk == nil {
+		return provider.Generate(ctx, req)
+	}
+	if streaming, ok := provider.(ai.StreamingProvider); ok {
+		return streaming.GenerateStream(ctx, req, onChunk)
+	}
+
+	resp, err := provider.Generate(ctx, req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if err := onChunk(resp.Content); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // createProvider instantiates the correct AI provider based on LLM configuration
 func (c *UnifiedAIClient) createProvider(config *LLMConfig, model string) (ai.Provider, error) {
 	providerLower := strings.ToLower(strings.TrimSpace(config.Provider))
@@ -105,11 +316,34 @@ func (c *UnifiedAIClient) createProvider(config *LLMConfig, model string) (ai.Pr
 		}
 		return providers.NewOpenAIClient(config.APIKey, model), nil
 
+	case "deepseek", "mistral", "google", "openai_compatible":
+		// Chat-completions APIs; the configured endpoint overrides the default
+		endpoint := config.APIEndpoint
+		if endpoint == "" {
+			endpoint = openAICompatibleEndpoints[providerLower]
+		}
+		if endpoint == "" {
+			return nil, fmt.Errorf("%s endpoint not configured in AI Factory", config.Provider)
+		}
+		if config.APIKey == "" && providerLower != "openai_compatible" {
+			return nil, fmt.Errorf("%s API key not configured in AI Factory", config.Provider)
+		}
+		return providers.NewOpenAICompatibleClient(providerLower, endpoint, config.APIKey, model), nil
+
 	default:
-		return nil, fmt.Errorf("unsupported provider: %s. Supported providers: ollama, anthropic, openai", config.Provider)
+		return nil, fmt.Errorf("unsupported provider: %s. Supported providers: ollama, anthropic, openai, deepseek, mistral, google, openai_compatible", config.Provider)
 	}
 }
 
+// openAICompatibleEndpoints are the default chat-completions base URLs of
+// hosted providers. openai_compatible (e.g. a local vLLM server) has none and
+// must be configured with an endpoint.
+var openAICompatibleEndpoints = map[string]string{
+	"deepseek": "https://api.deepseek.com",
+	"mistral":  "https://api.mistral.ai",
+	"google":   "https://generativelanguage.googleapis.com/v1beta/openai",
+}
+
 // GetModelCapabilities returns the capabilities of a model (local vs API features)
 func (c *UnifiedAIClient) GetModelCapabilities(provider string) ModelCapabilities {
 	providerLower := strings.ToLower(strings.TrimSpace(provider))
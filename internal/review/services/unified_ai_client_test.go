@@ -0,0 +1,249 @@
+package review_services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/ai/providers"
+	reviewcontext "github.com/mikejsmith1985/devsmith-modular-platform/internal/review/context"
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/review/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAIProvider returns a fixed response or error
+type fakeAIProvider struct {
+	resp  *ai.Response
+	err   error
+	calls int
+}
+
+func (p *fakeAIProvider) Generate(ctx context.Context, req *ai.Request) (*ai.Response, error) {
+	p.calls++
+	return p.resp, p.err
+}
+
+func (p *fakeAIProvider) HealthCheck(ctx context.Context) error { return nil }
+
+func (p *fakeAIProvider) GetModelInfo() *ai.ModelInfo { return &ai.ModelInfo{} }
+
+// usageRecords collects usage records in memory
+type usageRecords []*ai.UsageRecord
+
+func (r *usageRecords) Record(ctx context.Context, record *ai.UsageRecord) error {
+	*r = append(*r, record)
+	return nil
+}
+
+const unlimitedBudget = `{"monthly_limit_usd":null,"spent_usd":3.5,"exceeded":false}`
+
+func newUsageTestClient(t *testing.T, provider ai.Provider) (*UnifiedAIClient, *usageRecords) {
+	t.Helper()
+	return newBudgetTestClient(t, provider, unlimitedBudget)
+}
+
+// newBudgetTestClient serves the given budget status from a stand-in Portal
+func newBudgetTestClient(t *testing.T, provider ai.Provider, budget string) (*UnifiedAIClient, *usageRecords) {
+	t.Helper()
+	portal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/portal/llm/budget" {
+			_, _ = w.Write([]byte(budget))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"cfg-1","user_id":42,"provider":"openai","model_name":"gpt-4o","api_key":"sk-test"}`))
+	}))
+	t.Cleanup(portal.Close)
+
+	records := &usageRecords{}
+	client := NewUnifiedAIClient(portal.URL)
+	client.newProvider = func(config *LLMConfig, model string) (ai.Provider, error) { return provider, nil }
+	client.SetUsageRecorder(records, &nopLogger{})
+	return client, records
+}
+
+func TestUnifiedAIClient_RecordsUsageWithCost(t *testing.T) {
+	client, records := newUsageTestClient(t, &fakeAIProvider{resp: &ai.Response{
+		Content:      "looks good",
+		InputTokens:  1000,
+		OutputTokens: 500,
+		CostUSD:      0.0125,
+	}})
+	ctx := context.WithValue(context.Background(), reviewcontext.SessionTokenKey, "token")
+
+	out, err := client.Generate(ctx, "review this")
+	require.NoError(t, err)
+	assert.Equal(t, "looks good", out)
+
+	require.Len(t, *records, 1)
+	record := (*records)[0]
+	assert.Equal(t, 42, record.UserID)
+	assert.Equal(t, "review", record.AppName)
+	assert.Equal(t, "openai", record.Provider)
+	assert.Equal(t, "gpt-4o", record.Model)
+	assert.Equal(t, 1000, record.InputTokens)
+	assert.Equal(t, 500, record.OutputTokens)
+	assert.InDelta(t, 0.0125, record.CostUSD, 1e-9, "the provider's estimate")
+	assert.True(t, record.Success)
+	assert.Empty(t, record.Error)
+}
+
+func TestUnifiedAIClient_RecordsFailedCalls(t *testing.T) {
+	client, records := newUsageTestClient(t, &fakeAIProvider{err: errors.New("rate limited")})
+	ctx := context.WithValue(context.Background(), reviewcontext.SessionTokenKey, "token")
+
+	_, err := client.Generate(ctx, "review this")
+	require.Error(t, err)
+
+	require.Len(t, *records, 1)
+	assert.False(t, (*records)[0].Success)
+	assert.Equal(t, "rate limited", (*records)[0].Error)
+	assert.Zero(t, (*records)[0].CostUSD)
+}
+
+func TestUnifiedAIClient_BudgetGuard(t *testing.T) {
+	ctx := context.WithValue(context.Background(), reviewcontext.SessionTokenKey, "token")
+
+	near := &fakeAIProvider{resp: &ai.Response{Content: "ok"}}
+	client, _ := newBudgetTestClient(t, near, `{"monthly_limit_usd":10,"spent_usd":9.99,"exceeded":false}`)
+	_, err := client.Generate(ctx, "review this")
+	require.NoError(t, err, "near the limit the call goes through")
+	assert.Equal(t, 1, near.calls)
+
+	over := &fakeAIProvider{resp: &ai.Response{Content: "ok"}}
+	client, records := newBudgetTestClient(t, over, `{"monthly_limit_usd":10,"spent_usd":10.01,"exceeded":true}`)
+	_, err = client.Generate(ctx, "review this")
+	require.ErrorIs(t, err, ai.ErrBudgetExceeded)
+	assert.Contains(t, err.Error(), "$10.01 spent of $10.00")
+	assert.Zero(t, over.calls, "the model is not called")
+	assert.Empty(t, *records)
+}
+
+func TestUnifiedAIClient_BudgetCheckFailsOpen(t *testing.T) {
+	ctx := context.WithValue(context.Background(), reviewcontext.SessionTokenKey, "token")
+	provider := &fakeAIProvider{resp: &ai.Response{Content: "ok"}}
+	client, _ := newBudgetTestClient(t, provider, `not json`)
+
+	_, err := client.Generate(ctx, "review this")
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestUnifiedAIClient_ResolveModel(t *testing.T) {
+	client, _ := newUsageTestClient(t, &fakeAIProvider{})
+	ctx := context.WithValue(context.Background(), reviewcontext.SessionTokenKey, "token")
+
+	provider, model, err := client.ResolveModel(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "openai", provider)
+	assert.Equal(t, "gpt-4o", model)
+
+	provider, model, err = client.ResolveModel(context.WithValue(ctx, reviewcontext.ModelContextKey, "gpt-4o-mini"))
+	require.NoError(t, err)
+	assert.Equal(t, "openai", provider)
+	assert.Equal(t, "gpt-4o-mini", model, "the request's override wins")
+
+	_, _, err = client.ResolveModel(context.Background())
+	assert.Error(t, err, "no session")
+}
+
+// newRetryTestClient routes calls to a chat-completions server answering
+// with statuses in turn, then 200, and retries with millisecond backoff
+func newRetryTestClient(t *testing.T, statuses ...int) (*UnifiedAIClient, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			_, _ = w.Write([]byte(`{"error":{"message":"try again"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	t.Cleanup(model.Close)
+
+	client, _ := newUsageTestClient(t, providers.NewOpenAICompatibleClient("vllm", model.URL, "", "gpt-4o"))
+	client.SetRetryPolicy(retry.Config{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+	return client, &calls
+}
+
+func TestUnifiedAIClient_RetriesTransientFailures(t *testing.T) {
+	client, calls := newRetryTestClient(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	ctx := context.WithValue(context.Background(), reviewcontext.SessionTokenKey, "token")
+
+	out, err := client.Generate(ctx, "review this")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", out)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestUnifiedAIClient_DoesNotRetryValidationErrors(t *testing.T) {
+	client, calls := newRetryTestClient(t, http.StatusBadRequest)
+	ctx := context.WithValue(context.Background(), reviewcontext.SessionTokenKey, "token")
+
+	_, err := client.Generate(ctx, "review this")
+	var providerErr *ai.ProviderError
+	require.ErrorAs(t, err, &providerErr)
+	assert.Equal(t, http.StatusBadRequest, providerErr.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestUnifiedAIClient_StopsRetryingAfterMaxAttempts(t *testing.T) {
+	client, calls := newRetryTestClient(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	ctx := context.WithValue(context.Background(), reviewcontext.SessionTokenKey, "token")
+
+	_, err := client.Generate(ctx, "review this")
+	require.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestUnifiedAIClient_RetryRespectsDeadline(t *testing.T) {
+	client, calls := newRetryTestClient(t, http.StatusServiceUnavailable)
+	client.SetRetryPolicy(retry.Config{MaxRetries: 3, InitialDelay: time.Minute, MaxDelay: time.Minute})
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), reviewcontext.SessionTokenKey, "token"), time.Second)
+	defer cancel()
+
+	_, err := client.Generate(ctx, "review this")
+	require.Error(t, err, "a backoff past the deadline is not attempted")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIsRetryable(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, isRetryable(ctx, &ai.ProviderError{StatusCode: http.StatusTooManyRequests}))
+	assert.True(t, isRetryable(ctx, &ai.ProviderError{StatusCode: http.StatusBadGateway}))
+	assert.True(t, isRetryable(ctx, &PortalError{StatusCode: http.StatusServiceUnavailable}))
+	assert.False(t, isRetryable(ctx, &PortalError{StatusCode: http.StatusNotFound}))
+	assert.False(t, isRetryable(ctx, &ai.ProviderError{StatusCode: http.StatusUnauthorized}))
+	assert.False(t, isRetryable(ctx, errors.New("prompt cannot be empty")))
+	assert.False(t, isRetryable(ctx, context.DeadlineExceeded))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, isRetryable(canceled, &ai.ProviderError{StatusCode: http.StatusBadGateway}))
+}
+
+func TestUnifiedAIClient_CreatesOpenAICompatibleProviders(t *testing.T) {
+	client := NewUnifiedAIClient("http://portal")
+
+	provider, err := client.createProvider(&LLMConfig{Provider: "DeepSeek", APIKey: "sk-test"}, "deepseek-chat")
+	require.NoError(t, err)
+	assert.Equal(t, "deepseek", provider.GetModelInfo().Provider)
+
+	provider, err = client.createProvider(&LLMConfig{Provider: "openai_compatible", APIEndpoint: "http://vllm:8000/v1"}, "qwen2.5-coder")
+	require.NoError(t, err)
+	assert.Equal(t, "qwen2.5-coder", provider.GetModelInfo().Model)
+
+	_, err = client.createProvider(&LLMConfig{Provider: "openai_compatible"}, "qwen2.5-coder")
+	assert.ErrorContains(t, err, "endpoint not configured")
+
+	_, err = client.createProvider(&LLMConfig{Provider: "mistral"}, "codestral-latest")
+	assert.ErrorContains(t, err, "API key not configured")
+}
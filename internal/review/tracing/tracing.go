@@ -3,60 +3,17 @@ package tracing
 
 import (
 	"context"
-	"fmt"
-	"time"
 
+	"github.com/mikejsmith1985/devsmith-modular-platform/internal/tracing"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
-	"go.opentelemetry.io/otel/sdk/resource"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
 // InitTracer initializes OpenTelemetry tracing with OTLP HTTP exporter.
 // Exports traces to Jaeger-compatible backend via OTLP.
 func InitTracer(serviceName, endpoint string) (func(context.Context) error, error) {
-	ctx := context.Background()
-
-	// Create OTLP HTTP exporter (works with Jaeger 1.35+)
-	exporter, err := otlptracehttp.New(ctx,
-		otlptracehttp.WithEndpoint(endpoint),
-		otlptracehttp.WithInsecure(), // Use HTTP (not HTTPS) for local development
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
-	}
-
-	// Create resource with service information
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceNameKey.String(serviceName),
-			semconv.ServiceVersionKey.String("1.0.0"),
-			attribute.String("environment", "development"),
-		),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create resource: %w", err)
-	}
-
-	// Create trace provider
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.AlwaysSample()), // Sample all traces in development
-	)
-
-	// Register as global tracer provider
-	otel.SetTracerProvider(tp)
-
-	// Return cleanup function
-	return func(ctx context.Context) error {
-		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
-		return tp.Shutdown(ctx)
-	}, nil
+	return tracing.InitTracer(serviceName, endpoint)
 }
 
 // StartSpan is a helper to start a span with common attributes.
@@ -0,0 +1,67 @@
+// Package server runs the HTTP servers of DevSmith services with graceful
+// shutdown.
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DefaultShutdownTimeout is how long in-flight requests get to complete
+// once shutdown starts
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Run serves srv until the process receives SIGINT or SIGTERM, then shuts it
+// down gracefully as described for Serve.
+func Run(srv *http.Server, timeout time.Duration, cleanup ...func()) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return Serve(ctx, srv, nil, timeout, cleanup...)
+}
+
+// Serve serves srv on ln, or on srv.Addr if ln is nil, until ctx is done. It
+// then stops accepting connections, waits up to timeout for in-flight
+// requests to complete, and runs cleanup in order.
+//
+// Cleanup runs after the server has drained, so background jobs, WebSocket
+// hubs and buffers are only stopped once no request can feed them. It also
+// runs when the server fails to start.
+func Serve(ctx context.Context, srv *http.Server, ln net.Listener, timeout time.Duration, cleanup ...func()) error {
+	defer func() {
+		for _, fn := range cleanup {
+			fn()
+		}
+	}()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		var err error
+		if ln != nil {
+			err = srv.Serve(ln)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		serveErr <- err
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down: waiting up to %s for in-flight requests", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
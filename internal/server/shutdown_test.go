@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowServer answers /slow once release is closed and reports each request
+// it starts on started
+func slowServer(started chan<- struct{}, release <-chan struct{}) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		_, _ = io.WriteString(w, "done")
+	})
+	return &http.Server{Handler: mux, ReadHeaderTimeout: time.Second}
+}
+
+func TestServe_InFlightRequestCompletesDuringShutdown(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	srv := slowServer(started, release)
+
+	var mu sync.Mutex
+	var order []string
+	record := func(step string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, step)
+		}
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- Serve(ctx, srv, ln, 5*time.Second, record("jobs"), record("hub"))
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	stop()
+
+	// Shutdown waits for the request, and cleanup waits for shutdown
+	select {
+	case err := <-served:
+		t.Fatalf("Serve returned with a request in flight: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	mu.Lock()
+	assert.Empty(t, order, "cleanup must wait for in-flight requests")
+	mu.Unlock()
+
+	close(release)
+	res := <-responses
+	require.NoError(t, res.err)
+	assert.Equal(t, "done", res.body)
+
+	require.NoError(t, <-served)
+	assert.Equal(t, []string{"jobs", "hub"}, order)
+
+	// No new connections are accepted after shutdown
+	_, err = http.Get("http://" + ln.Addr().String() + "/slow")
+	assert.Error(t, err)
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	defer close(release)
+	srv := slowServer(started, release)
+
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	cleaned := false
+	go func() {
+		served <- Serve(ctx, srv, ln, 20*time.Millisecond, func() { cleaned = true })
+	}()
+
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	stop()
+
+	assert.ErrorIs(t, <-served, context.DeadlineExceeded)
+	assert.True(t, cleaned, "cleanup runs even when requests are cut off")
+}
+
+func TestServe_ListenError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	cleaned := false
+	srv := &http.Server{Addr: ln.Addr().String(), ReadHeaderTimeout: time.Second}
+	err = Serve(context.Background(), srv, nil, time.Second, func() { cleaned = true })
+
+	assert.Error(t, err, "the address is already in use")
+	assert.True(t, cleaned)
+}
@@ -6,15 +6,47 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// DefaultTouchInterval is how stale a session's LastAccessedAt may get
+// before Get records a new access
+const DefaultTouchInterval = 60 * time.Second
+
+// touchScript returns a session and, at most once per touch interval,
+// records the access and refreshes the TTL. The marker key in KEYS[2]
+// expires with the interval, so concurrent Gets agree on a single writer.
+//
+// KEYS[1] session key, KEYS[2] touch marker key
+// ARGV[1] access time (RFC3339), ARGV[2] touch interval ms, ARGV[3] TTL ms
+var touchScript = redis.NewScript(`
+local data = redis.call('GET', KEYS[1])
+if not data then
+	return false
+end
+if not redis.call('SET', KEYS[2], '1', 'NX', 'PX', ARGV[2]) then
+	return data
+end
+-- Rewrite the field in place rather than round-tripping the JSON, which
+-- can change empty objects into arrays. It is the first match, since it
+-- precedes metadata, and quotes inside string values are escaped.
+data = string.gsub(data, '"last_accessed_at":"[^"]*"', '"last_accessed_at":"' .. ARGV[1] .. '"', 1)
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], data, 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], data)
+end
+return data
+`)
+
 // RedisStore manages session storage in Redis
 type RedisStore struct {
-	client *redis.Client
-	ttl    time.Duration
+	client        *redis.Client
+	ttl           time.Duration
+	touchInterval time.Duration
 }
 
 // Session represents a user session
@@ -26,6 +58,10 @@ type Session struct {
 	CreatedAt      time.Time              `json:"created_at"`
 	LastAccessedAt time.Time              `json:"last_accessed_at"`
 	Metadata       map[string]interface{} `json:"metadata"`
+	// Set only for GitHub Apps with expiring user tokens; OAuth App tokens
+	// do not expire and have no refresh token
+	GitHubRefreshToken   string    `json:"github_refresh_token,omitempty"`
+	GitHubTokenExpiresAt time.Time `json:"github_token_expires_at,omitempty"`
 }
 
 // NewRedisStore creates a new Redis session store
@@ -47,7 +83,13 @@ func NewRedisStore(addr string, ttl time.Duration) (*RedisStore, error) {
 		return nil, fmt.Errorf("redis ping failed: %w", err)
 	}
 
-	return &RedisStore{client: client, ttl: ttl}, nil
+	return &RedisStore{client: client, ttl: ttl, touchInterval: DefaultTouchInterval}, nil
+}
+
+// SetTouchInterval sets how often Get records a session's last access and
+// refreshes its TTL
+func (s *RedisStore) SetTouchInterval(interval time.Duration) {
+	s.touchInterval = interval
 }
 
 // GenerateSessionID creates a cryptographically secure session ID
@@ -79,17 +121,27 @@ func (s *RedisStore) Create(ctx context.Context, session *Session) (string, erro
 	}
 
 	key := fmt.Sprintf("session:%s", session.SessionID)
-	if err := s.client.Set(ctx, key, data, s.ttl).Err(); err != nil {
+	touchKey := fmt.Sprintf("session_touch:%s", session.SessionID)
+	// The session was just accessed, so the first touch is due an interval from now
+	if _, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, data, s.ttl)
+		pipe.Set(ctx, touchKey, "1", s.touchWindow())
+		pipe.SAdd(ctx, userSessionsKey(session.UserID), session.SessionID)
+		return nil
+	}); err != nil {
 		return "", fmt.Errorf("redis set: %w", err)
 	}
 
 	return session.SessionID, nil
 }
 
-// Get retrieves a session from Redis
+// Get retrieves a session from Redis. At most once per touch interval it
+// also updates LastAccessedAt and refreshes the TTL, atomically, so busy
+// sessions don't cost a write per request.
 func (s *RedisStore) Get(ctx context.Context, sessionID string) (*Session, error) {
-	key := fmt.Sprintf("session:%s", sessionID)
-	data, err := s.client.Get(ctx, key).Bytes()
+	keys := []string{fmt.Sprintf("session:%s", sessionID), fmt.Sprintf("session_touch:%s", sessionID)}
+	data, err := touchScript.Run(ctx, s.client, keys,
+		time.Now().Format(time.RFC3339Nano), s.touchWindow().Milliseconds(), s.ttl.Milliseconds()).Text()
 	if err == redis.Nil {
 		return nil, nil // Session not found
 	}
@@ -98,19 +150,22 @@ func (s *RedisStore) Get(ctx context.Context, sessionID string) (*Session, error
 	}
 
 	var session Session
-	if err := json.Unmarshal(data, &session); err != nil {
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
 		return nil, fmt.Errorf("unmarshal session: %w", err)
 	}
 
-	// Update last accessed time
-	session.LastAccessedAt = time.Now()
-	if err := s.Update(ctx, &session); err != nil {
-		// Log but don't fail - session still valid
-	}
-
 	return &session, nil
 }
 
+// touchWindow is the touch interval as a valid expiry; a zero interval
+// touches on every Get
+func (s *RedisStore) touchWindow() time.Duration {
+	if s.touchInterval < time.Millisecond {
+		return time.Millisecond
+	}
+	return s.touchInterval
+}
+
 // Update refreshes a session in Redis
 func (s *RedisStore) Update(ctx context.Context, session *Session) error {
 	data, err := json.Marshal(session)
@@ -129,13 +184,84 @@ func (s *RedisStore) Update(ctx context.Context, session *Session) error {
 // Delete removes a session from Redis
 func (s *RedisStore) Delete(ctx context.Context, sessionID string) error {
 	key := fmt.Sprintf("session:%s", sessionID)
-	if err := s.client.Del(ctx, key).Err(); err != nil {
+
+	// The owner is needed to drop the session from their index
+	data, err := s.client.Get(ctx, key).Bytes()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("redis get: %w", err)
+	}
+	var owner Session
+	found := err == nil
+	if found {
+		if err := json.Unmarshal(data, &owner); err != nil {
+			return fmt.Errorf("unmarshal session: %w", err)
+		}
+	}
+
+	if _, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key, fmt.Sprintf("session_touch:%s", sessionID))
+		if found {
+			pipe.SRem(ctx, userSessionsKey(owner.UserID), sessionID)
+		}
+		return nil
+	}); err != nil {
 		return fmt.Errorf("redis del: %w", err)
 	}
 
 	return nil
 }
 
+// ListByUser returns the user's active sessions, most recently used first.
+// Sessions that expired since they were indexed are dropped from the index.
+func (s *RedisStore) ListByUser(ctx context.Context, userID int) ([]*Session, error) {
+	indexKey := userSessionsKey(userID)
+	sessionIDs, err := s.client.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis smembers: %w", err)
+	}
+	if len(sessionIDs) == 0 {
+		return []*Session{}, nil
+	}
+
+	keys := make([]string, len(sessionIDs))
+	for i, sessionID := range sessionIDs {
+		keys[i] = fmt.Sprintf("session:%s", sessionID)
+	}
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis mget: %w", err)
+	}
+
+	sessions := make([]*Session, 0, len(values))
+	var expired []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, sessionIDs[i])
+			continue
+		}
+		var session Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			return nil, fmt.Errorf("unmarshal session: %w", err)
+		}
+		sessions = append(sessions, &session)
+	}
+	if len(expired) > 0 {
+		// Best effort; the next read retries
+		_ = s.client.SRem(ctx, indexKey, expired...).Err()
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastAccessedAt.After(sessions[j].LastAccessedAt)
+	})
+	return sessions, nil
+}
+
+// userSessionsKey is the set of a user's session IDs
+func userSessionsKey(userID int) string {
+	return fmt.Sprintf("user_sessions:%d", userID)
+}
+
 // Close closes the Redis connection
 func (s *RedisStore) Close() error {
 	return s.client.Close()
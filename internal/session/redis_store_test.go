@@ -2,9 +2,11 @@ package session
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -113,3 +115,159 @@ func TestRedisStore_NonexistentSession(t *testing.T) {
 	require.NoError(t, err)
 	assert.Nil(t, retrieved, "Should return nil for nonexistent session")
 }
+
+// newMiniredisStore returns a store backed by an in-process Redis
+func newMiniredisStore(t *testing.T, ttl time.Duration) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	store, err := NewRedisStore(mr.Addr(), ttl)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store, mr
+}
+
+// TestRedisStore_GetThrottlesTouch verifies rapid Gets don't each write, but
+// a Get after the touch interval does
+func TestRedisStore_GetThrottlesTouch(t *testing.T) {
+	ttl := time.Hour
+	store, mr := newMiniredisStore(t, ttl)
+	ctx := context.Background()
+
+	sessionID, err := store.Create(ctx, &Session{
+		UserID:   42,
+		Metadata: map[string]interface{}{"user_agent": "test"},
+	})
+	require.NoError(t, err)
+	key := "session:" + sessionID
+	created, err := mr.Get(key)
+	require.NoError(t, err)
+
+	mr.FastForward(10 * time.Second)
+	for i := 0; i < 5; i++ {
+		sess, err := store.Get(ctx, sessionID)
+		require.NoError(t, err)
+		require.NotNil(t, sess)
+	}
+	stored, err := mr.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, created, stored, "Gets within the interval don't write")
+	assert.Equal(t, ttl-10*time.Second, mr.TTL(key), "Gets within the interval don't refresh the TTL")
+
+	before := time.Now()
+	mr.FastForward(DefaultTouchInterval)
+	sess, err := store.Get(ctx, sessionID)
+	require.NoError(t, err)
+	require.NotNil(t, sess)
+	assert.False(t, sess.LastAccessedAt.Before(before), "Get after the interval records the access")
+	assert.Equal(t, ttl, mr.TTL(key), "Get after the interval refreshes the TTL")
+	assert.Equal(t, 42, sess.UserID)
+	assert.Equal(t, "test", sess.Metadata["user_agent"])
+
+	reread, err := store.Get(ctx, sessionID)
+	require.NoError(t, err)
+	assert.True(t, reread.LastAccessedAt.Equal(sess.LastAccessedAt), "the touch was persisted")
+}
+
+// TestRedisStore_GetConcurrentTouch verifies concurrent Gets all succeed
+// while the touch happens once
+func TestRedisStore_GetConcurrentTouch(t *testing.T) {
+	store, mr := newMiniredisStore(t, time.Hour)
+	ctx := context.Background()
+
+	sessionID, err := store.Create(ctx, &Session{UserID: 7, Metadata: map[string]interface{}{}})
+	require.NoError(t, err)
+	mr.FastForward(DefaultTouchInterval)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Get(ctx, sessionID)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	// Only the first Get in the window touched, so another Get leaves the
+	// stored session alone
+	stored, err := mr.Get("session:" + sessionID)
+	require.NoError(t, err)
+	_, err = store.Get(ctx, sessionID)
+	require.NoError(t, err)
+	after, err := mr.Get("session:" + sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, stored, after)
+	assert.Contains(t, after, `"metadata":{}`, "the touch leaves the rest of the session alone")
+}
+
+// TestRedisStore_GetMissingSession verifies a missing session is nil, not an error
+func TestRedisStore_GetMissingSession(t *testing.T) {
+	store, _ := newMiniredisStore(t, time.Hour)
+
+	sess, err := store.Get(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.Nil(t, sess)
+}
+
+// TestRedisStore_ListByUser verifies a user's sessions are listed and
+// revoked sessions and other users' sessions are not
+func TestRedisStore_ListByUser(t *testing.T) {
+	store, _ := newMiniredisStore(t, time.Hour)
+	ctx := context.Background()
+
+	var ids []string
+	for _, agent := range []string{"laptop", "phone", "tablet"} {
+		id, err := store.Create(ctx, &Session{UserID: 1, Metadata: map[string]interface{}{"user_agent": agent}})
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	_, err := store.Create(ctx, &Session{UserID: 2})
+	require.NoError(t, err)
+
+	sessions, err := store.ListByUser(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+	assert.ElementsMatch(t, ids, []string{sessions[0].SessionID, sessions[1].SessionID, sessions[2].SessionID})
+
+	require.NoError(t, store.Delete(ctx, ids[1]))
+	sessions, err = store.ListByUser(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.NotContains(t, []string{sessions[0].SessionID, sessions[1].SessionID}, ids[1])
+
+	none, err := store.ListByUser(ctx, 3)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+// TestRedisStore_ListByUserDropsExpired verifies expired sessions are
+// skipped and pruned from the index
+func TestRedisStore_ListByUserDropsExpired(t *testing.T) {
+	store, mr := newMiniredisStore(t, time.Hour)
+	ctx := context.Background()
+
+	expiring, err := store.Create(ctx, &Session{UserID: 1})
+	require.NoError(t, err)
+	mr.FastForward(30 * time.Minute)
+	live, err := store.Create(ctx, &Session{UserID: 1})
+	require.NoError(t, err)
+	mr.FastForward(45 * time.Minute)
+
+	sessions, err := store.ListByUser(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, live, sessions[0].SessionID)
+
+	members, err := mr.SMembers("user_sessions:1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{live}, members, "expired session %s is pruned", expiring)
+
+	// Deleting an already expired session is not an error
+	require.NoError(t, store.Delete(ctx, expiring))
+}
@@ -1,5 +1,7 @@
 package logger
 
+import "io"
+
 const (
 	// DefaultBatchSize is the default number of logs to batch before sending.
 	// Recommended for most services. Higher values reduce network traffic,
@@ -14,6 +16,14 @@ const (
 
 	// DefaultLogLevel is the default log level.
 	DefaultLogLevel = "info"
+
+	// FormatText writes stdout logs as "[level] service: message" lines,
+	// followed by a metadata line when fields are present. This is the default.
+	FormatText = "text"
+
+	// FormatJSON writes stdout logs as one JSON object per line, in the same
+	// shape sent to the Logs service.
+	FormatJSON = "json"
 )
 
 // Config represents the configuration for the logger.
@@ -57,4 +67,11 @@ type Config struct {
 	// If true and the service is unavailable, logs will fall back to stdout.
 	// Should typically be true to avoid losing logs on service failure.
 	EnableStdout bool
+
+	// Format is the stdout output format: FormatText (default) or FormatJSON.
+	// Case-insensitive. Use JSON where a log collector parses stdout.
+	Format string
+
+	// Output is where stdout logs are written. Defaults to os.Stdout.
+	Output io.Writer
 }
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -27,6 +28,12 @@ type Logger struct {
 	logToStdout     bool
 	enableStdout    bool
 	closed          bool
+	format          string
+
+	// output receives stdout logs; outMu keeps concurrent flushes from
+	// interleaving lines.
+	output io.Writer
+	outMu  sync.Mutex
 
 	// batchBuffer holds logs pending to be sent.
 	batchBuffer []*LogEntry
@@ -51,11 +58,25 @@ func NewLogger(config *Config) (*Logger, error) {
 		return nil, fmt.Errorf("service name is required")
 	}
 
-	logLevel := config.LogLevel
+	logLevel := strings.ToLower(strings.TrimSpace(config.LogLevel))
 	if logLevel == "" {
 		logLevel = DefaultLogLevel
 	}
 
+	format := strings.ToLower(strings.TrimSpace(config.Format))
+	switch format {
+	case "":
+		format = FormatText
+	case FormatText, FormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown log format %q (want %q or %q)", config.Format, FormatText, FormatJSON)
+	}
+
+	output := config.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
 	batchSize := config.BatchSize
 	if batchSize <= 0 {
 		batchSize = DefaultBatchSize
@@ -74,6 +95,8 @@ func NewLogger(config *Config) (*Logger, error) {
 		batchTimeoutSec: batchTimeoutSec,
 		logToStdout:     config.LogToStdout,
 		enableStdout:    config.EnableStdout,
+		format:          format,
+		output:          output,
 		batchBuffer:     make([]*LogEntry, 0, batchSize),
 		done:            make(chan struct{}),
 		httpClient: &http.Client{
@@ -122,25 +145,36 @@ func (l *Logger) Panic(msg string, keyvals ...interface{}) {
 
 // WithContext returns a logger with context-extracted values.
 func (l *Logger) WithContext(ctx context.Context) Interface {
-	// Create a new logger instance that shares the same components but has additional context fields
-	contextFields := make(map[string]interface{})
+	// Create a wrapper logger with context fields
+	return &loggerWithFields{
+		logger:        l,
+		contextFields: extractContextFields(ctx, make(map[string]interface{})),
+	}
+}
 
-	// Extract known fields from context
+// CorrelationID returns the correlation ID carried by ctx: the value under
+// CorrelationIDKey, or else under the plain "correlation_id" key that Gin
+// handlers set with c.Set (a *gin.Context is itself a context.Context).
+func CorrelationID(ctx context.Context) interface{} {
 	if correlationID := ctx.Value(CorrelationIDKey); correlationID != nil {
-		contextFields["correlation_id"] = correlationID
+		return correlationID
+	}
+	return ctx.Value(string(CorrelationIDKey))
+}
+
+// extractContextFields adds the correlation, user and request IDs found in
+// ctx to fields and returns it.
+func extractContextFields(ctx context.Context, fields map[string]interface{}) map[string]interface{} {
+	if correlationID := CorrelationID(ctx); correlationID != nil {
+		fields["correlation_id"] = correlationID
 	}
 	if userID := ctx.Value(UserIDKey); userID != nil {
-		contextFields["user_id"] = userID
+		fields["user_id"] = userID
 	}
 	if requestID := ctx.Value(RequestIDKey); requestID != nil {
-		contextFields["request_id"] = requestID
-	}
-
-	// Create a wrapper logger with context fields
-	return &loggerWithFields{
-		logger:        l,
-		contextFields: contextFields,
+		fields["request_id"] = requestID
 	}
+	return fields
 }
 
 // WithFields returns a logger with additional structured fields.
@@ -213,7 +247,12 @@ func (l *Logger) log(level, msg string, keyvals ...interface{}) {
 	for i := 0; i < len(keyvals); i += 2 {
 		if i+1 < len(keyvals) {
 			key := fmt.Sprintf("%v", keyvals[i])
-			metadata[key] = keyvals[i+1]
+			value := keyvals[i+1]
+			// Errors marshal to {}; keep their message
+			if err, ok := value.(error); ok && err != nil {
+				value = err.Error()
+			}
+			metadata[key] = value
 		}
 	}
 
@@ -270,8 +309,9 @@ func (l *Logger) sendBatch(ctx context.Context, logs []*LogEntry) error {
 		return nil
 	}
 
-	// Log to stdout if enabled
-	if l.logToStdout || l.enableStdout {
+	// Log to stdout if enabled; with no logging service EnableStdout alone
+	// is enough, as stdout is then the only sink
+	if l.logToStdout || (l.enableStdout && l.logURL == "") {
 		for _, entry := range logs {
 			l.logToStdoutEntry(entry)
 		}
@@ -297,8 +337,8 @@ func (l *Logger) sendBatch(ctx context.Context, logs []*LogEntry) error {
 
 	resp, err := l.httpClient.Do(req)
 	if err != nil {
-		// Fallback to stdout on error
-		if l.enableStdout {
+		// Fallback to stdout on error, unless already written there
+		if l.enableStdout && !l.logToStdout {
 			for _, entry := range logs {
 				l.logToStdoutEntry(entry)
 			}
@@ -316,15 +356,28 @@ func (l *Logger) sendBatch(ctx context.Context, logs []*LogEntry) error {
 	return nil
 }
 
-// logToStdoutEntry logs a single entry to stdout.
+// logToStdoutEntry writes a single entry to the stdout sink in the
+// configured format.
 func (l *Logger) logToStdoutEntry(entry *LogEntry) {
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+
+	if l.format == FormatJSON {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			line, _ = json.Marshal(map[string]string{"level": entry.Level, "service": entry.Service, "message": entry.Message}) //nolint:errcheck // Plain strings always marshal
+		}
+		_, _ = fmt.Fprintf(l.output, "%s\n", line) //nolint:errcheck // Stdout write errors are non-critical
+		return
+	}
+
 	prefix := fmt.Sprintf("[%s] %s", entry.Level, entry.Service)
-	_, _ = fmt.Fprintf(os.Stdout, "%s: %s\n", prefix, entry.Message) //nolint:errcheck // Stdout write errors are non-critical
+	_, _ = fmt.Fprintf(l.output, "%s: %s\n", prefix, entry.Message) //nolint:errcheck // Stdout write errors are non-critical
 
 	// Include metadata if present
 	if len(entry.Metadata) > 0 {
-		metaJSON, _ := json.Marshal(entry.Metadata)                         //nolint:errcheck // Marshal errors are non-critical for logging
-		_, _ = fmt.Fprintf(os.Stdout, "  metadata: %s\n", string(metaJSON)) //nolint:errcheck // Stdout write errors are non-critical
+		metaJSON, _ := json.Marshal(entry.Metadata)                        //nolint:errcheck // Marshal errors are non-critical for logging
+		_, _ = fmt.Fprintf(l.output, "  metadata: %s\n", string(metaJSON)) //nolint:errcheck // Stdout write errors are non-critical
 	}
 }
 
@@ -461,20 +514,9 @@ func (lf *loggerWithFields) WithContext(ctx context.Context) Interface {
 		newFields[k] = v
 	}
 
-	// Extract additional fields from context
-	if correlationID := ctx.Value(CorrelationIDKey); correlationID != nil {
-		newFields["correlation_id"] = correlationID
-	}
-	if userID := ctx.Value(UserIDKey); userID != nil {
-		newFields["user_id"] = userID
-	}
-	if requestID := ctx.Value(RequestIDKey); requestID != nil {
-		newFields["request_id"] = requestID
-	}
-
 	return &loggerWithFields{
 		logger:        lf.logger,
-		contextFields: newFields,
+		contextFields: extractContextFields(ctx, newFields),
 	}
 }
 
@@ -1,9 +1,14 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -1070,3 +1075,101 @@ func TestLogger_WithFields_ChainingWorks(t *testing.T) {
 	// BEHAVIORAL: Chaining should work and message should be logged
 	assert.NotNil(t, loggerWithFields, "Chaining should return logger")
 }
+
+// lockedBuffer is an io.Writer safe to read while the logger flushes.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newOutputLogger logs to stdout-style output captured in a buffer.
+func newOutputLogger(t *testing.T, level, format string) (*Logger, *lockedBuffer) {
+	t.Helper()
+	out := &lockedBuffer{}
+	logger, err := NewLogger(&Config{
+		ServiceName: "test-service",
+		LogLevel:    level,
+		LogToStdout: true,
+		Format:      format,
+		Output:      out,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = logger.Close() })
+	return logger, out
+}
+
+func TestLogger_Output_FiltersByLevel(t *testing.T) {
+	logger, out := newOutputLogger(t, "WARN", FormatText)
+
+	logger.Debug("debug message")
+	logger.Info("info message")
+	logger.Warn("warning message")
+	logger.Error("error message")
+	require.NoError(t, logger.Flush(context.Background()))
+
+	assert.NotContains(t, out.String(), "debug message")
+	assert.NotContains(t, out.String(), "info message")
+	assert.Contains(t, out.String(), "[warn] test-service: warning message")
+	assert.Contains(t, out.String(), "[error] test-service: error message")
+}
+
+func TestLogger_Output_JSONFormat(t *testing.T) {
+	logger, out := newOutputLogger(t, "info", FormatJSON)
+
+	logger.Info("user created", "user_id", 42, "error", errors.New("smtp down"))
+	logger.Warn("slow request")
+	require.NoError(t, logger.Flush(context.Background()))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2, "one JSON object per line")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "test-service", entry["service"])
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "user created", entry["message"])
+	assert.NotEmpty(t, entry["created_at"])
+	metadata, ok := entry["metadata"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, float64(42), metadata["user_id"])
+	assert.Equal(t, "smtp down", metadata["error"], "errors are logged by message")
+}
+
+func TestLogger_Output_IncludesCorrelationIDFromContext(t *testing.T) {
+	logger, out := newOutputLogger(t, "info", FormatJSON)
+
+	ctx := context.WithValue(context.Background(), CorrelationIDKey, "req-123")
+	logger.WithContext(ctx).Info("from typed key")
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("correlation_id", "gin-456")
+	logger.WithFields("step", "parse").WithContext(c).Info("from gin context")
+	require.NoError(t, logger.Flush(context.Background()))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	for i, want := range []string{"req-123", "gin-456"} {
+		var entry struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &entry))
+		assert.Equal(t, want, entry.Metadata["correlation_id"])
+	}
+}
+
+func TestNewLogger_RejectsUnknownFormat(t *testing.T) {
+	_, err := NewLogger(&Config{ServiceName: "test-service", Format: "xml"})
+	require.Error(t, err)
+}
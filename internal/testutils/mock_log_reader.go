@@ -72,6 +72,21 @@ func (m *MockLogReader) FindTopMessages(ctx context.Context, service, level stri
 	return nil, args.Error(1)
 }
 
+// FindTopIssues provides a mocked implementation with logging.
+// It simulates the behavior of the actual LogReader method.
+func (m *MockLogReader) FindTopIssues(ctx context.Context, q analytics_models.TopIssuesQuery) ([]analytics_models.IssueItem, int, error) {
+	log.Printf("MockLogReader.FindTopIssues called with query=%+v", q)
+	args := m.Called(ctx, q)
+	if err := args.Error(2); err != nil {
+		return nil, 0, err
+	}
+	result, ok := args.Get(0).([]analytics_models.IssueItem)
+	if !ok && args.Get(0) != nil {
+		return nil, 0, fmt.Errorf("unexpected type for issues: %T", args.Get(0))
+	}
+	return result, args.Int(1), nil
+}
+
 // FindAggregations provides a mocked implementation with logging.
 // It simulates the behavior of the actual LogReader method.
 func (m *MockLogReader) FindAggregations(ctx context.Context, service, level string, startTime, endTime time.Time) ([]*analytics_models.Aggregation, error) {
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	callerSpanID  = "00f067aa0ba902b7"
+)
+
+// useRecorder installs a tracer provider that records ended spans
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousTP, previousProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousTP)
+		otel.SetTextMapPropagator(previousProp)
+		_ = tp.Shutdown(t.Context())
+	})
+	return recorder
+}
+
+func TestMiddleware_ContinuesCallerTrace(t *testing.T) {
+	recorder := useRecorder(t)
+	gin.SetMode(gin.TestMode)
+
+	var handlerSpan trace.SpanContext
+	router := gin.New()
+	router.Use(Middleware("devsmith-logs"))
+	router.POST("/api/logs/batch", func(c *gin.Context) {
+		// Child spans made from the request context join the request's span
+		_, child := otel.Tracer("test").Start(c.Request.Context(), "logs.batch.insert")
+		child.End()
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/logs/batch", http.NoBody)
+	req.Header.Set("traceparent", "00-"+callerTraceID+"-"+callerSpanID+"-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+
+	assert.Equal(t, "POST /api/logs/batch", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, callerTraceID, server.SpanContext().TraceID().String())
+	assert.Equal(t, callerSpanID, server.Parent().SpanID().String(), "the caller's span is the parent")
+	assert.True(t, server.Parent().IsRemote())
+	assert.Equal(t, server.SpanContext(), handlerSpan)
+
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+	assert.Equal(t, callerTraceID, child.SpanContext().TraceID().String())
+
+	// The response carries the server span for the caller to correlate
+	assert.Equal(t, "00-"+callerTraceID+"-"+server.SpanContext().SpanID().String()+"-01", w.Header().Get("traceparent"))
+}
+
+func TestMiddleware_StartsNewTraceAndRecordsFailures(t *testing.T) {
+	recorder := useRecorder(t)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware("devsmith-analytics"))
+	router.GET("/api/analytics/trends", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/analytics/trends?window=1h", http.NoBody))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.True(t, span.SpanContext().IsValid())
+	assert.False(t, span.Parent().IsValid(), "no traceparent starts a new trace")
+	assert.Equal(t, codes.Error, span.Status().Code)
+
+	attrs := map[string]string{}
+	for _, kv := range span.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	assert.Equal(t, "/api/analytics/trends", attrs["http.route"])
+	assert.Equal(t, "/api/analytics/trends?window=1h", attrs["http.target"])
+	assert.Equal(t, "500", attrs["http.status_code"])
+}
@@ -0,0 +1,475 @@
+// Package logclient is a Go client for the DevSmith Logs batch ingestion API.
+//
+// A Client buffers log entries and sends them to POST /api/logs/batch when
+// the buffer reaches the batch size or the flush interval elapses:
+//
+//	client, err := logclient.New(logclient.Config{
+//		BaseURL:     os.Getenv("DEVSMITH_API_URL"),
+//		APIKey:      os.Getenv("DEVSMITH_API_KEY"),
+//		ProjectSlug: "my-project",
+//		ServiceName: "api-server",
+//		OnError:     func(err error) { metrics.LogDeliveryFailures.Inc() },
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close(context.Background())
+//
+//	client.Info("User logged in", map[string]interface{}{"user_id": 123})
+//
+// Large batches can be gzipped to save bandwidth by setting GzipThreshold,
+// e.g. 8 << 10 to compress request bodies of 8 KiB or more.
+//
+// The *Context variants attach the correlation ID stored in ctx, so the
+// entries of one request can be queried together:
+//
+//	ctx = logclient.WithCorrelationID(ctx, r.Header.Get("X-Correlation-ID"))
+//	client.InfoContext(ctx, "Order placed", nil)
+package logclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client defaults
+const (
+	DefaultBaseURL       = "http://localhost:3000"
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = 5 * time.Second
+	DefaultMaxRetries    = 3
+	DefaultRetryBackoff  = time.Second
+	DefaultHTTPTimeout   = 10 * time.Second
+
+	// MaxBatchSize is the most entries the batch endpoint accepts per request.
+	MaxBatchSize = 1000
+
+	// BatchPath is the ingestion endpoint, relative to BaseURL.
+	BatchPath = "/api/logs/batch"
+)
+
+// Log levels accepted by the batch endpoint.
+const (
+	LevelDebug = "DEBUG"
+	LevelInfo  = "INFO"
+	LevelWarn  = "WARN"
+	LevelError = "ERROR"
+)
+
+// Configuration errors returned by New.
+var (
+	ErrMissingAPIKey      = errors.New("logclient: APIKey is required")
+	ErrMissingProjectSlug = errors.New("logclient: ProjectSlug is required")
+)
+
+// LogEntry is one log line in a batch.
+type LogEntry struct {
+	Timestamp      string                 `json:"timestamp"` // RFC 3339
+	Level          string                 `json:"level"`
+	Message        string                 `json:"message"`
+	ServiceName    string                 `json:"service_name,omitempty"`
+	Context        map[string]interface{} `json:"context,omitempty"`
+	IdempotencyKey string                 `json:"idempotency_key,omitempty"`
+	CorrelationID  string                 `json:"correlation_id,omitempty"`
+}
+
+// batchRequest mirrors the server's BatchLogRequest.
+type batchRequest struct {
+	ProjectSlug    string     `json:"project_slug"`
+	IdempotencyKey string     `json:"idempotency_key"`
+	Logs           []LogEntry `json:"logs"`
+}
+
+// DeliveryError reports a batch that could not be delivered after retries.
+// Entries holds the lost batch so callers can persist or resend it.
+type DeliveryError struct {
+	Err        error
+	Entries    []LogEntry
+	StatusCode int // Last HTTP status; 0 if the request never got a response
+}
+
+func (e *DeliveryError) Error() string {
+	return fmt.Sprintf("logclient: failed to deliver %d log entries: %v", len(e.Entries), e.Err)
+}
+
+func (e *DeliveryError) Unwrap() error { return e.Err }
+
+// Config configures a Client. Zero values fall back to the defaults above.
+type Config struct {
+	HTTPClient    *http.Client // Injectable for tests and custom transports
+	OnError       func(error)  // Called with a *DeliveryError for every lost batch; defaults to log.Printf
+	BaseURL       string
+	APIKey        string // Sent as X-API-Key
+	ProjectSlug   string
+	ServiceName   string // Set on every entry as service_name
+	BatchSize     int    // Entries per request, capped at MaxBatchSize
+	FlushInterval time.Duration
+	MaxRetries    int // Retries after the first attempt; negative disables retries
+	RetryBackoff  time.Duration
+	GzipThreshold int // Request bodies of at least this many bytes are gzipped; 0 never compresses
+}
+
+// Client buffers log entries and ships them to the batch ingestion API.
+// It is safe for concurrent use.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+	buffer     []LogEntry
+	flushNow   chan struct{}
+	done       chan struct{}
+	stopped    chan struct{}
+	loopCtx    context.Context
+	cancelLoop context.CancelFunc
+	closeOnce  sync.Once
+	mu         sync.Mutex
+	sendMu     sync.Mutex // Keeps batches in order
+	closed     bool
+}
+
+// New creates a Client and starts its background flusher. Call Close to
+// flush what is left and stop it.
+func New(cfg Config) (*Client, error) {
+	if cfg.APIKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+	if cfg.ProjectSlug == "" {
+		return nil, ErrMissingProjectSlug
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.BatchSize > MaxBatchSize {
+		cfg.BatchSize = MaxBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(err error) { log.Printf("%v", err) }
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultHTTPTimeout}
+	}
+
+	loopCtx, cancel := context.WithCancel(context.Background())
+	c := &Client{
+		cfg:        cfg,
+		httpClient: httpClient,
+		buffer:     make([]LogEntry, 0, cfg.BatchSize),
+		flushNow:   make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+		loopCtx:    loopCtx,
+		cancelLoop: cancel,
+	}
+	go c.run()
+	return c, nil
+}
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying id. Entries logged with
+// that context through the *Context methods are tagged with it.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID stored in ctx, or "".
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// Debug buffers a DEBUG entry.
+func (c *Client) Debug(message string, fields map[string]interface{}) {
+	c.Log(LevelDebug, message, fields)
+}
+
+// Info buffers an INFO entry.
+func (c *Client) Info(message string, fields map[string]interface{}) {
+	c.Log(LevelInfo, message, fields)
+}
+
+// Warn buffers a WARN entry.
+func (c *Client) Warn(message string, fields map[string]interface{}) {
+	c.Log(LevelWarn, message, fields)
+}
+
+// Error buffers an ERROR entry.
+func (c *Client) Error(message string, fields map[string]interface{}) {
+	c.Log(LevelError, message, fields)
+}
+
+// DebugContext buffers a DEBUG entry tagged with ctx's correlation ID.
+func (c *Client) DebugContext(ctx context.Context, message string, fields map[string]interface{}) {
+	c.LogContext(ctx, LevelDebug, message, fields)
+}
+
+// InfoContext buffers an INFO entry tagged with ctx's correlation ID.
+func (c *Client) InfoContext(ctx context.Context, message string, fields map[string]interface{}) {
+	c.LogContext(ctx, LevelInfo, message, fields)
+}
+
+// WarnContext buffers a WARN entry tagged with ctx's correlation ID.
+func (c *Client) WarnContext(ctx context.Context, message string, fields map[string]interface{}) {
+	c.LogContext(ctx, LevelWarn, message, fields)
+}
+
+// ErrorContext buffers an ERROR entry tagged with ctx's correlation ID.
+func (c *Client) ErrorContext(ctx context.Context, message string, fields map[string]interface{}) {
+	c.LogContext(ctx, LevelError, message, fields)
+}
+
+// Log buffers an entry at level. It never blocks on the network: a full
+// batch is handed to the background flusher. Entries logged after Close are
+// discarded.
+func (c *Client) Log(level, message string, fields map[string]interface{}) {
+	c.enqueue(c.newEntry(level, message, fields))
+}
+
+// LogContext is Log with the correlation ID stored in ctx by
+// WithCorrelationID. ctx is not used for cancellation: entries are sent later
+// by the background flusher.
+func (c *Client) LogContext(ctx context.Context, level, message string, fields map[string]interface{}) {
+	entry := c.newEntry(level, message, fields)
+	entry.CorrelationID = CorrelationIDFromContext(ctx)
+	c.enqueue(entry)
+}
+
+func (c *Client) newEntry(level, message string, fields map[string]interface{}) LogEntry {
+	return LogEntry{
+		Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+		Level:       level,
+		Message:     message,
+		ServiceName: c.cfg.ServiceName,
+		Context:     fields,
+	}
+}
+
+// enqueue buffers entry and wakes the flusher once a batch is full.
+func (c *Client) enqueue(entry LogEntry) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.buffer = append(c.buffer, entry)
+	full := len(c.buffer) >= c.cfg.BatchSize
+	c.mu.Unlock()
+
+	if full {
+		select {
+		case c.flushNow <- struct{}{}:
+		default: // A flush is already pending
+		}
+	}
+}
+
+// Flush sends everything buffered so far, in batches of at most BatchSize.
+// It returns the first delivery error; every lost batch is also reported to
+// OnError.
+func (c *Client) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	pending := c.buffer
+	c.buffer = make([]LogEntry, 0, c.cfg.BatchSize)
+	c.mu.Unlock()
+
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	var firstErr error
+	for start := 0; start < len(pending); start += c.cfg.BatchSize {
+		end := start + c.cfg.BatchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		if err := c.send(ctx, pending[start:end]); err != nil {
+			c.cfg.OnError(err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Close stops the background flusher and flushes the remaining entries.
+// ctx bounds the whole shutdown, including a flush already in progress.
+// Calling Close again is a no-op.
+func (c *Client) Close(ctx context.Context) error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.closed = true
+		c.mu.Unlock()
+
+		close(c.done)
+		select {
+		case <-c.stopped:
+		case <-ctx.Done():
+			c.cancelLoop()
+			<-c.stopped
+		}
+		c.cancelLoop()
+
+		err = c.Flush(ctx)
+	})
+	return err
+}
+
+// run flushes on the interval and whenever Log fills a batch.
+func (c *Client) run() {
+	defer close(c.stopped)
+	ticker := time.NewTicker(c.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		case <-c.flushNow:
+		}
+		_ = c.Flush(c.loopCtx) //nolint:errcheck // Reported through OnError
+	}
+}
+
+// send posts one batch, retrying network errors, 429 and 5xx responses.
+// A 429 waits for its Retry-After when given. All attempts share one
+// idempotency key, so a retry of a batch the server did store is a no-op.
+func (c *Client) send(ctx context.Context, entries []LogEntry) error {
+	key, err := newBatchKey()
+	if err != nil {
+		return &DeliveryError{Entries: entries, Err: err}
+	}
+	body, err := json.Marshal(batchRequest{ProjectSlug: c.cfg.ProjectSlug, IdempotencyKey: key, Logs: entries})
+	if err != nil {
+		return &DeliveryError{Entries: entries, Err: fmt.Errorf("marshal batch: %w", err)}
+	}
+	gzipped := c.cfg.GzipThreshold > 0 && len(body) >= c.cfg.GzipThreshold
+	if gzipped {
+		if body, err = gzipBody(body); err != nil {
+			return &DeliveryError{Entries: entries, Err: err}
+		}
+	}
+
+	var status int
+	for attempt := 0; ; attempt++ {
+		var wait time.Duration
+		var retry bool
+		status, wait, retry, err = c.post(ctx, body, gzipped)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= c.cfg.MaxRetries {
+			break
+		}
+
+		if wait <= 0 {
+			wait = time.Duration(attempt+1) * c.cfg.RetryBackoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &DeliveryError{Entries: entries, StatusCode: status, Err: ctx.Err()}
+		case <-timer.C:
+		}
+	}
+	return &DeliveryError{Entries: entries, StatusCode: status, Err: err}
+}
+
+// post makes one request. wait is the server's Retry-After, if any.
+func (c *Client) post(ctx context.Context, body []byte, gzipped bool) (status int, wait time.Duration, retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+BatchPath, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", c.cfg.APIKey)
+	if gzipped {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, ctx.Err() == nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain so the connection can be reused; the message is only for errors
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512)) //nolint:errcheck // Best effort
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp.StatusCode, 0, false, nil
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")), true,
+			fmt.Errorf("rate limited: %s", strings.TrimSpace(string(msg)))
+	case resp.StatusCode >= 500:
+		return resp.StatusCode, 0, true, fmt.Errorf("server error %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	default:
+		return resp.StatusCode, 0, false, fmt.Errorf("rejected with %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+}
+
+// gzipBody compresses a marshaled batch.
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, fmt.Errorf("gzip batch: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("gzip batch: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// parseRetryAfter reads a Retry-After header in seconds or HTTP-date form.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func newBatchKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate idempotency key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,266 @@
+package logclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logs_middleware "github.com/mikejsmith1985/devsmith-modular-platform/internal/logs/middleware"
+)
+
+// batchServer records batches and answers with the next scripted status.
+type batchServer struct {
+	*httptest.Server
+	statuses   []int
+	retryAfter string
+	batches    []batchRequest
+	times      []time.Time
+	mu         sync.Mutex
+}
+
+func newBatchServer(t *testing.T, statuses ...int) *batchServer {
+	s := &batchServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, BatchPath, r.URL.Path)
+		assert.Equal(t, "test-key", r.Header.Get("X-API-Key"))
+
+		var batch batchRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.batches = append(s.batches, batch)
+		s.times = append(s.times, time.Now())
+		status := http.StatusCreated
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		if status == http.StatusTooManyRequests && s.retryAfter != "" {
+			w.Header().Set("Retry-After", s.retryAfter)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *batchServer) received() []batchRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]batchRequest(nil), s.batches...)
+}
+
+func newTestClient(t *testing.T, url string, batchSize int, onError func(error)) *Client {
+	client, err := New(Config{
+		BaseURL:       url,
+		APIKey:        "test-key",
+		ProjectSlug:   "my-project",
+		ServiceName:   "api-server",
+		BatchSize:     batchSize,
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+		HTTPClient:    &http.Client{Timeout: 2 * time.Second},
+		OnError:       onError,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_FlushesFullBatches(t *testing.T) {
+	server := newBatchServer(t)
+	client := newTestClient(t, server.URL, 2, nil)
+
+	client.Info("one", map[string]interface{}{"user_id": 1})
+	client.Warn("two", nil)
+
+	// A full batch is sent without waiting for the interval
+	require.Eventually(t, func() bool { return len(server.received()) == 1 }, 2*time.Second, 5*time.Millisecond)
+	batch := server.received()[0]
+	assert.Equal(t, "my-project", batch.ProjectSlug)
+	require.Len(t, batch.Logs, 2)
+	assert.Equal(t, LevelInfo, batch.Logs[0].Level)
+	assert.Equal(t, "one", batch.Logs[0].Message)
+	assert.Equal(t, "api-server", batch.Logs[0].ServiceName)
+	assert.Equal(t, float64(1), batch.Logs[0].Context["user_id"])
+	assert.Equal(t, LevelWarn, batch.Logs[1].Level)
+
+	// Close flushes the partial batch left over
+	client.Error("three", nil)
+	require.NoError(t, client.Close(context.Background()))
+	batches := server.received()
+	require.Len(t, batches, 2)
+	require.Len(t, batches[1].Logs, 1)
+	assert.Equal(t, "three", batches[1].Logs[0].Message)
+
+	// Entries after Close are dropped rather than buffered forever
+	client.Info("late", nil)
+	require.NoError(t, client.Close(context.Background()))
+	assert.Len(t, server.received(), 2)
+}
+
+func TestClient_FlushSplitsIntoBatchSize(t *testing.T) {
+	server := newBatchServer(t)
+	client := newTestClient(t, server.URL, 1000, nil)
+	defer client.Close(context.Background())
+	client.cfg.BatchSize = 3 // Smaller than the buffered entries, without triggering a size flush
+
+	for i := 0; i < 7; i++ {
+		client.Debug("entry", nil)
+	}
+	require.NoError(t, client.Flush(context.Background()))
+
+	var sizes []int
+	for _, b := range server.received() {
+		sizes = append(sizes, len(b.Logs))
+	}
+	assert.Equal(t, []int{3, 3, 1}, sizes)
+}
+
+func TestClient_AttachesCorrelationIDFromContext(t *testing.T) {
+	server := newBatchServer(t)
+	client := newTestClient(t, server.URL, 10, nil)
+	defer client.Close(context.Background())
+
+	ctx := WithCorrelationID(context.Background(), "req-42")
+	client.InfoContext(ctx, "order placed", nil)
+	client.ErrorContext(ctx, "payment declined", nil)
+	client.WarnContext(context.Background(), "no id", nil)
+	client.Info("plain", nil)
+	require.NoError(t, client.Flush(context.Background()))
+
+	batches := server.received()
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0].Logs, 4)
+	assert.Equal(t, "req-42", batches[0].Logs[0].CorrelationID)
+	assert.Equal(t, "req-42", batches[0].Logs[1].CorrelationID)
+	assert.Equal(t, LevelError, batches[0].Logs[1].Level)
+	assert.Empty(t, batches[0].Logs[2].CorrelationID)
+	assert.Empty(t, batches[0].Logs[3].CorrelationID)
+}
+
+func TestClient_RetriesServerErrorsWithSameIdempotencyKey(t *testing.T) {
+	server := newBatchServer(t, http.StatusServiceUnavailable, http.StatusBadGateway)
+	client := newTestClient(t, server.URL, 10, nil)
+	defer client.Close(context.Background())
+
+	client.Info("retry me", nil)
+	require.NoError(t, client.Flush(context.Background()))
+
+	batches := server.received()
+	require.Len(t, batches, 3)
+	assert.NotEmpty(t, batches[0].IdempotencyKey)
+	assert.Equal(t, batches[0].IdempotencyKey, batches[2].IdempotencyKey)
+}
+
+func TestClient_HonorsRetryAfterOn429(t *testing.T) {
+	server := newBatchServer(t, http.StatusTooManyRequests)
+	server.retryAfter = "1"
+	client := newTestClient(t, server.URL, 10, nil)
+	defer client.Close(context.Background())
+
+	client.Info("throttled", nil)
+	require.NoError(t, client.Flush(context.Background()))
+
+	require.Len(t, server.times, 2)
+	assert.GreaterOrEqual(t, server.times[1].Sub(server.times[0]), time.Second)
+}
+
+func TestClient_ReportsDeliveryErrors(t *testing.T) {
+	server := newBatchServer(t, http.StatusBadRequest)
+	var reported []error
+	client := newTestClient(t, server.URL, 10, func(err error) { reported = append(reported, err) })
+	defer client.Close(context.Background())
+
+	client.Info("bad", nil)
+	err := client.Flush(context.Background())
+
+	var deliveryErr *DeliveryError
+	require.True(t, errors.As(err, &deliveryErr))
+	assert.Equal(t, http.StatusBadRequest, deliveryErr.StatusCode)
+	require.Len(t, deliveryErr.Entries, 1)
+	assert.Equal(t, "bad", deliveryErr.Entries[0].Message)
+	assert.Len(t, server.received(), 1, "4xx responses are not retried")
+	assert.Equal(t, []error{err}, reported)
+}
+
+func TestClient_FlushHonorsContext(t *testing.T) {
+	server := newBatchServer(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	client := newTestClient(t, server.URL, 10, func(error) {})
+	defer client.Close(context.Background())
+	client.cfg.RetryBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client.Info("slow", nil)
+
+	err := client.Flush(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestClient_GzipsBatchesOverThreshold(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var mu sync.Mutex
+	var encodings []string
+	var batches []batchRequest
+	router := gin.New()
+	router.POST(BatchPath, func(c *gin.Context) {
+		mu.Lock()
+		encodings = append(encodings, c.GetHeader("Content-Encoding"))
+		mu.Unlock()
+	}, logs_middleware.DecompressGzip(logs_middleware.DefaultMaxDecompressedBytes), func(c *gin.Context) {
+		var batch batchRequest
+		if err := c.ShouldBindJSON(&batch); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		mu.Lock()
+		batches = append(batches, batch)
+		mu.Unlock()
+		c.Status(http.StatusCreated)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, 1000, nil)
+	defer client.Close(context.Background())
+	client.cfg.GzipThreshold = 4 << 10
+
+	client.Info("small", nil)
+	require.NoError(t, client.Flush(context.Background()))
+	for i := 0; i < 200; i++ {
+		client.Info("request served", map[string]interface{}{"path": "/api/orders", "status": 200})
+	}
+	require.NoError(t, client.Flush(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"", "gzip"}, encodings)
+	require.Len(t, batches, 2)
+	assert.Len(t, batches[1].Logs, 200, "the gzipped batch is ingested in full")
+}
+
+func TestNew_RequiresKeyAndProject(t *testing.T) {
+	_, err := New(Config{ProjectSlug: "p"})
+	assert.Equal(t, ErrMissingAPIKey, err)
+
+	_, err = New(Config{APIKey: "k"})
+	assert.Equal(t, ErrMissingProjectSlug, err)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+
+	at := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	assert.InDelta(t, 10*time.Second, parseRetryAfter(at), float64(2*time.Second))
+}
@@ -519,6 +519,9 @@ func runTestMigrations(db *sql.DB) error {
 			metadata JSONB,
 			context JSONB,
 			tags TEXT[],
+			correlation_id TEXT,
+			issue_type VARCHAR(50),
+			fingerprint VARCHAR(16),
 			created_at TIMESTAMP DEFAULT NOW()
 		)
 	`)
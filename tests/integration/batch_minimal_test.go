@@ -199,6 +199,9 @@ func runMinimalMigrations(db *sql.DB) error {
 			metadata JSONB,
 			context JSONB,
 			tags TEXT[],
+			correlation_id TEXT,
+			issue_type VARCHAR(50),
+			fingerprint VARCHAR(16),
 			timestamp TIMESTAMP NOT NULL DEFAULT NOW(),
 			created_at TIMESTAMP DEFAULT NOW()
 		)